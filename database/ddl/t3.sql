@@ -1,9 +1,21 @@
+-- Reference DDL of the registry. schema_server --migrate creates and upgrades the same
+-- tables from the versioned scripts in t3-amqp/db/migrations, add changes there too.
+
 -- Create the database
 CREATE SCHEMA s1;
 
 -- Create the enum type for the schema 'type' column
 CREATE TYPE s1.schema_type AS ENUM ('avro', 'json', 'protobuf', 'xsd', 'thrift', 'confluent');
 
+-- Tenants isolate the schemas of the teams sharing a deployment
+CREATE TABLE s1.tenant (
+                           id      VARCHAR(64)  PRIMARY KEY,
+                           name    VARCHAR(255) NOT NULL DEFAULT '',
+                           created timestamp    NOT NULL
+);
+
+INSERT INTO s1.tenant (id, name, created) VALUES ('default', 'Default tenant', now() AT TIME ZONE 'utc');
+
 -- Create the schema table
 CREATE TABLE s1.schema (
                            id SERIAL PRIMARY KEY,
@@ -12,9 +24,185 @@ CREATE TABLE s1.schema (
                            version VARCHAR(15) NOT NULL,
                            schema_data JSONB NOT NULL,
                            created     timestamp,
-                           modified    timestamp
+                           modified    timestamp,
+                           status       VARCHAR(16) NOT NULL DEFAULT 'active',
+                           deprecate_at timestamp,
+                           retire_at    timestamp,
+                           fingerprint  VARCHAR(64),
+                           deleted_at   timestamp,
+                           version_key  TEXT NOT NULL DEFAULT '',
+                           namespace    VARCHAR(255) GENERATED ALWAYS AS (split_part(name, '.', 1)) STORED,
+                           search_vector tsvector GENERATED ALWAYS AS
+                               (jsonb_to_tsvector('simple', schema_data, '["key", "string"]')) STORED,
+                           tenant_id    VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES s1.tenant (id)
 );
 
+-- Deleted schemas are kept for auditing, only live ones have to be unique within a tenant
+CREATE UNIQUE INDEX unique_name_type_version ON s1.schema (tenant_id, name, type, version)
+    WHERE deleted_at IS NULL;
+
 ALTER TABLE s1.schema
-    ADD CONSTRAINT unique_name_type_version
-        UNIQUE (name, type, version);
\ No newline at end of file
+    ADD CONSTRAINT schema_status_check
+        CHECK (status IN ('active', 'deprecated', 'retired'));
+
+-- Byte-ordered semantic version key, compared with COLLATE "C" when sorting by version
+CREATE INDEX schema_version_key_idx ON s1.schema (name, type, version_key COLLATE "C");
+
+-- SHA-256 of the canonical form, used to find duplicate schemas regardless of formatting
+CREATE INDEX schema_fingerprint_idx ON s1.schema (type, fingerprint);
+
+-- Namespace of the name, the part before the first dot, API keys can be limited to some
+CREATE INDEX schema_namespace_idx ON s1.schema (namespace);
+
+-- Search matches names through trigrams and the keys and strings of documents by full text
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX schema_name_trgm_idx ON s1.schema USING gin (name gin_trgm_ops);
+CREATE INDEX schema_search_idx ON s1.schema USING gin (search_vector);
+
+-- Alternative names that resolve to a canonical schema name
+CREATE TABLE s1.schema_alias (
+                                 alias     VARCHAR(255) NOT NULL,
+                                 name      VARCHAR(255) NOT NULL,
+                                 created   timestamp    NOT NULL,
+                                 tenant_id VARCHAR(64)  NOT NULL DEFAULT 'default' REFERENCES s1.tenant (id),
+                                 PRIMARY KEY (tenant_id, alias)
+);
+
+CREATE INDEX schema_alias_name_idx ON s1.schema_alias (name);
+
+-- Schemas referenced by the $ref of a JSON schema, a schema cannot be deleted while live
+-- schemas reference it
+CREATE TABLE s1.schema_reference (
+                                     schema_id     INTEGER NOT NULL REFERENCES s1.schema (id) ON DELETE CASCADE,
+                                     referenced_id INTEGER NOT NULL REFERENCES s1.schema (id) ON DELETE CASCADE,
+                                     PRIMARY KEY (schema_id, referenced_id)
+);
+
+CREATE INDEX schema_reference_referenced_idx ON s1.schema_reference (referenced_id);
+
+-- Audit trail of schema mutations
+CREATE TABLE s1.audit_log (
+                              id          SERIAL PRIMARY KEY,
+                              actor       VARCHAR(255) NOT NULL,
+                              action      VARCHAR(32)  NOT NULL,
+                              schema_id   INTEGER,
+                              schema_name VARCHAR(255),
+                              source_ip   VARCHAR(64),
+                              before      JSONB,
+                              after       JSONB,
+                              created     timestamp    NOT NULL,
+                              tenant_id   VARCHAR(64)  NOT NULL DEFAULT 'default'
+);
+
+CREATE INDEX audit_log_created_idx ON s1.audit_log (created);
+CREATE INDEX audit_log_schema_idx ON s1.audit_log (schema_name, created);
+CREATE INDEX audit_log_actor_idx ON s1.audit_log (actor, created);
+CREATE INDEX audit_log_tenant_idx ON s1.audit_log (tenant_id, created);
+
+-- API keys of REST callers, only the SHA-256 of each secret is stored
+CREATE TABLE s1.api_key (
+                            id          SERIAL PRIMARY KEY,
+                            name        VARCHAR(255) NOT NULL,
+                            prefix      VARCHAR(32)  NOT NULL UNIQUE,
+                            scope       VARCHAR(16)  NOT NULL CHECK (scope IN ('read', 'read-write', 'admin')),
+                            secret_hash CHAR(64)     NOT NULL,
+                            created     timestamp    NOT NULL,
+                            revoked_at  timestamp,
+                            namespaces  TEXT[]       NOT NULL DEFAULT '{}',
+                            tenant_id   VARCHAR(64)  REFERENCES s1.tenant (id)
+);
+
+-- Topic test scenarios, settings beyond topic, schema, count, rate and mode live in options
+CREATE TABLE s1.test_scenario (
+                                  id             SERIAL PRIMARY KEY,
+                                  name           VARCHAR(255) NOT NULL UNIQUE,
+                                  topic          VARCHAR(255) NOT NULL,
+                                  schema_name    VARCHAR(255) NOT NULL,
+                                  schema_type    VARCHAR(32)  NOT NULL,
+                                  schema_version VARCHAR(15)  NOT NULL,
+                                  message_count  INTEGER      NOT NULL CHECK (message_count > 0),
+                                  rate           INTEGER      NOT NULL DEFAULT 0 CHECK (rate >= 0),
+                                  mode           VARCHAR(16)  NOT NULL CHECK (mode IN ('valid', 'invalid')),
+                                  options        JSONB        NOT NULL DEFAULT '{}',
+                                  created        timestamp    NOT NULL,
+                                  updated        timestamp    NOT NULL
+);
+
+-- Outcome of every scenario run and load test, scenario_id is cleared when the scenario is deleted
+CREATE TABLE s1.test_run (
+                             id             SERIAL PRIMARY KEY,
+                             scenario_id    INTEGER      REFERENCES s1.test_scenario (id) ON DELETE SET NULL,
+                             kind           VARCHAR(16)  NOT NULL DEFAULT 'scenario' CHECK (kind IN ('scenario', 'load')),
+                             scenario_name  VARCHAR(255) NOT NULL DEFAULT '',
+                             topic          VARCHAR(255) NOT NULL DEFAULT '',
+                             schema_name    VARCHAR(255) NOT NULL DEFAULT '',
+                             run_id         VARCHAR(64)  NOT NULL UNIQUE,
+                             status         VARCHAR(16)  NOT NULL CHECK (status IN ('running', 'passed', 'failed', 'error')),
+                             started        timestamp    NOT NULL,
+                             finished       timestamp,
+                             messages_sent  INTEGER      NOT NULL DEFAULT 0,
+                             messages_valid INTEGER      NOT NULL DEFAULT 0,
+                             failures       INTEGER      NOT NULL DEFAULT 0,
+                             latency_p50_ns BIGINT,
+                             latency_p95_ns BIGINT,
+                             latency_p99_ns BIGINT,
+                             throughput     DOUBLE PRECISION,
+                             confirm_p50_ns BIGINT,
+                             confirm_p95_ns BIGINT,
+                             confirm_p99_ns BIGINT
+);
+
+CREATE INDEX test_run_scenario_idx ON s1.test_run (scenario_id, started);
+CREATE INDEX test_run_topic_idx ON s1.test_run (topic, started);
+
+-- Outcome of each instance of a test run, details holds assertions, ack and throttling stats
+CREATE TABLE s1.test_result (
+                                id             SERIAL PRIMARY KEY,
+                                test_run_id    INTEGER      NOT NULL REFERENCES s1.test_run (id) ON DELETE CASCADE,
+                                instance       INTEGER      NOT NULL,
+                                temp_queue     VARCHAR(255) NOT NULL,
+                                params         JSONB        NOT NULL DEFAULT '{}',
+                                started        timestamp    NOT NULL,
+                                duration_ns    BIGINT       NOT NULL DEFAULT 0,
+                                messages_sent  INTEGER      NOT NULL DEFAULT 0,
+                                messages_valid INTEGER      NOT NULL DEFAULT 0,
+                                failures       INTEGER      NOT NULL DEFAULT 0,
+                                redelivered    INTEGER      NOT NULL DEFAULT 0,
+                                latency_p50_ns BIGINT,
+                                latency_p95_ns BIGINT,
+                                latency_p99_ns BIGINT,
+                                confirm_p50_ns BIGINT,
+                                confirm_p95_ns BIGINT,
+                                confirm_p99_ns BIGINT,
+                                error          TEXT,
+                                details        JSONB,
+                                UNIQUE (test_run_id, instance)
+);
+
+-- Messages captured from a topic in the order they were received, for replay
+CREATE TABLE s1.capture (
+                            id            SERIAL PRIMARY KEY,
+                            topic         VARCHAR(255) NOT NULL,
+                            status        VARCHAR(16)  NOT NULL CHECK (status IN ('running', 'finished', 'error')),
+                            started       timestamp    NOT NULL,
+                            finished      timestamp,
+                            message_count INTEGER      NOT NULL DEFAULT 0,
+                            error         TEXT
+);
+
+CREATE INDEX capture_topic_idx ON s1.capture (topic, started);
+
+CREATE TABLE s1.captured_message (
+                                     id             BIGSERIAL PRIMARY KEY,
+                                     capture_id     INTEGER      NOT NULL REFERENCES s1.capture (id) ON DELETE CASCADE,
+                                     seq            INTEGER      NOT NULL,
+                                     received       timestamp    NOT NULL,
+                                     exchange       VARCHAR(255) NOT NULL,
+                                     routing_key    VARCHAR(255) NOT NULL,
+                                     content_type   VARCHAR(255) NOT NULL DEFAULT '',
+                                     message_id     VARCHAR(255) NOT NULL DEFAULT '',
+                                     correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+                                     headers        JSONB,
+                                     body           BYTEA        NOT NULL,
+                                     UNIQUE (capture_id, seq)
+);
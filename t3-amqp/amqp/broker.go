@@ -0,0 +1,111 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"sync/atomic"
+	"t3-amqp/broker"
+	"time"
+)
+
+// Broker implements broker.Broker on an AMQP connection. Topics are routing keys on the
+// exchange, a subscription reads from an exclusive queue the broker names and deletes
+// once the subscription ends.
+type Broker struct {
+	conn     *Conn
+	exchange string
+	send     sendFunc
+}
+
+// NewBroker creates a broker publishing to exchange on conn with publisher confirms
+func NewBroker(conn *Conn, exchange string) *Broker {
+	if exchange == "" {
+		exchange = DefaultExchange
+	}
+	return &Broker{conn: conn, exchange: exchange, send: confirmedSender(conn)}
+}
+
+// DeclareTopic does nothing, routing keys need not be declared
+func (b *Broker) DeclareTopic(ctx context.Context, topic string) error {
+	return nil
+}
+
+// Publish sends msg to the exchange with topic as routing key and waits for the broker's
+// confirm
+func (b *Broker) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	return b.send(ctx, b.exchange, topic, publishing(msg))
+}
+
+// Subscribe binds an exclusive queue to topic and calls handle for every message routed
+// to it, acknowledging them automatically
+func (b *Broker) Subscribe(ctx context.Context, topic string, handle func(broker.Message)) (func() error, error) {
+	ch, err := b.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err == nil {
+		err = ch.QueueBind(q.Name, topic, b.exchange, false, nil)
+	}
+	var deliveries <-chan amqp091.Delivery
+	if err == nil {
+		deliveries, err = ch.ConsumeWithContext(ctx, q.Name, "", true, true, false, false, nil)
+	}
+	if err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("error subscribing to %s: %w", topic, err)
+	}
+
+	var stopped atomic.Bool
+	done := make(chan error, 1)
+	go func() {
+		for d := range deliveries {
+			handle(message(d))
+		}
+		if stopped.Load() || ctx.Err() != nil {
+			done <- nil
+			return
+		}
+		done <- fmt.Errorf("subscription to %s was closed by the broker", topic)
+	}()
+	return func() error {
+		stopped.Store(true)
+		ch.Close()
+		return <-done
+	}, nil
+}
+
+// Close closes the connection
+func (b *Broker) Close() error {
+	return b.conn.Close()
+}
+
+// publishing builds the persistent AMQP message of msg
+func publishing(msg broker.Message) amqp091.Publishing {
+	headers := make(amqp091.Table, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	return amqp091.Publishing{
+		ContentType:  msg.ContentType,
+		MessageId:    msg.ID,
+		DeliveryMode: amqp091.Persistent,
+		Timestamp:    time.Now().UTC(),
+		Headers:      headers,
+		Body:         msg.Body,
+	}
+}
+
+// message converts delivery d, header values other than strings are formatted as text
+func message(d amqp091.Delivery) broker.Message {
+	headers := make(map[string]string, len(d.Headers))
+	for k, v := range d.Headers {
+		if s, ok := v.(string); ok {
+			headers[k] = s
+			continue
+		}
+		headers[k] = fmt.Sprint(v)
+	}
+	return broker.Message{ID: d.MessageId, ContentType: d.ContentType, Headers: headers, Body: d.Body}
+}
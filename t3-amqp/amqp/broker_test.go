@@ -0,0 +1,27 @@
+package amqp
+
+import (
+	"t3-amqp/broker"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrokerMessageConversion(t *testing.T) {
+	msg := broker.Message{
+		ID: "m1", ContentType: "application/json", Body: []byte(`{"id":1}`),
+		Headers: map[string]string{HeaderSchemaName: "orders", HeaderPublished: "1700000000000000000"},
+	}
+	p := publishing(msg)
+	assert.Equal(t, "m1", p.MessageId)
+	assert.Equal(t, amqp091.Persistent, p.DeliveryMode)
+	assert.Equal(t, "orders", p.Headers[HeaderSchemaName])
+	assert.NoError(t, p.Headers.Validate())
+
+	d := amqp091.Delivery{
+		MessageId: p.MessageId, ContentType: p.ContentType, Body: p.Body,
+		Headers: amqp091.Table{HeaderSchemaName: "orders", HeaderPublished: int64(1700000000000000000)},
+	}
+	assert.Equal(t, msg, message(d), "header values come back as text")
+}
@@ -0,0 +1,241 @@
+package amqp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// HeaderReplayOf marks replayed messages with the ID of the capture they come from
+const HeaderReplayOf = "x-t3-replay-of"
+
+// captureBatch is the number of captured messages stored in one transaction
+const captureBatch = 100
+
+// Capturer records the messages routed to a topic and replays them later, to reproduce
+// what a consumer saw. A capture reads from a temporary queue bound to the topic, so it
+// only sees messages published while it runs and takes none away from other consumers.
+type Capturer struct {
+	conn      *Conn
+	exchange  string
+	store     db.CaptureStore
+	resources scenario.Resources
+	journal   *scenario.Journal
+	// sender opens the confirm channel replayed messages are published on
+	sender func() (sendFunc, func(), error)
+}
+
+// NewCapturer creates a capturer binding to exchange on conn and storing messages in
+// store. Temporary queues are tracked in journal until they are deleted, a nil journal is
+// kept in memory only.
+func NewCapturer(conn *Conn, exchange string, store db.CaptureStore, journal *scenario.Journal) *Capturer {
+	if exchange == "" {
+		exchange = DefaultExchange
+	}
+	if journal == nil {
+		// Without a path the journal is never read or written
+		journal, _ = scenario.OpenJournal("")
+	}
+	return &Capturer{
+		conn:      conn,
+		exchange:  exchange,
+		store:     store,
+		resources: NewTempResources(conn),
+		journal:   journal,
+		sender:    func() (sendFunc, func(), error) { return channelSender(conn) },
+	}
+}
+
+// Capture stores the messages routed to topic in the capture with id until max messages
+// were received, or until ctx is done when max is 0. Cancelling ctx ends a capture
+// normally, the messages received until then are kept.
+func (c *Capturer) Capture(ctx context.Context, id int, topic string, max int) error {
+	queue := fmt.Sprintf("t3.capture.%d", id)
+	var captureErr error
+	err := scenario.WithTempQueue(ctx, c.resources, c.journal, queue, func() {
+		captureErr = c.capture(ctx, id, queue, topic, max)
+	})
+	if err != nil {
+		return err
+	}
+	return captureErr
+}
+
+func (c *Capturer) capture(ctx context.Context, id int, queue, topic string, max int) error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return err
+	}
+	err = ch.QueueBind(queue, topic, c.exchange, false, nil)
+	ch.Close()
+	if err != nil {
+		return fmt.Errorf("error binding %s to %s: %w", queue, topic, err)
+	}
+
+	consumeCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	var batch []db.CapturedMessage
+	var storeErr error
+	seq := 0
+	auto := scenario.AckStrategy{Mode: scenario.AckAuto}
+	_, err = Consume(consumeCtx, c.conn, queue, auto, max, func(d amqp091.Delivery) {
+		if storeErr != nil {
+			return
+		}
+		batch = append(batch, capturedMessage(seq, time.Now().UTC(), d))
+		seq++
+		if len(batch) >= captureBatch {
+			if storeErr = c.store.AddCapturedMessages(id, batch); storeErr != nil {
+				stop()
+			}
+			batch = nil
+		}
+	})
+	if storeErr != nil {
+		return fmt.Errorf("error storing captured messages: %w", storeErr)
+	}
+	if len(batch) > 0 {
+		if err := c.store.AddCapturedMessages(id, batch); err != nil {
+			return fmt.Errorf("error storing captured messages: %w", err)
+		}
+	}
+	return err
+}
+
+// Replay republishes the messages of the capture with id in the order they were received,
+// to their original routing key or to topic when it is set. Speed scales the gaps between
+// the messages as they were received, 2 replays twice as fast and 0 publishes without
+// waiting. Each message waits for the broker's confirm. It returns how many messages
+// were published.
+func (c *Capturer) Replay(ctx context.Context, id int, topic string, speed float64) (int, error) {
+	messages, err := c.store.CapturedMessages(id)
+	if err != nil {
+		return 0, err
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	send, release, err := c.sender()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	started := time.Now()
+	first := messages[0].Received
+	for i, captured := range messages {
+		if speed > 0 {
+			due := started.Add(time.Duration(float64(captured.Received.Sub(first)) / speed))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return i, ctx.Err()
+				}
+			}
+		}
+
+		msg, err := replayMessage(id, captured)
+		if err != nil {
+			return i, err
+		}
+		key := captured.RoutingKey
+		if topic != "" {
+			key = topic
+		}
+		if err := send(ctx, captured.Exchange, key, msg); err != nil {
+			metrics.AMQPPublished.WithLabelValues("failed").Inc()
+			return i, fmt.Errorf("error replaying message %d: %w", captured.Seq, err)
+		}
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+	}
+	return len(messages), nil
+}
+
+// capturedMessage records delivery d as the message at seq received at received
+func capturedMessage(seq int, received time.Time, d amqp091.Delivery) db.CapturedMessage {
+	msg := db.CapturedMessage{
+		Seq:           seq,
+		Received:      received,
+		Exchange:      d.Exchange,
+		RoutingKey:    d.RoutingKey,
+		ContentType:   d.ContentType,
+		MessageID:     d.MessageId,
+		CorrelationID: d.CorrelationId,
+		Body:          d.Body,
+	}
+	if len(d.Headers) > 0 {
+		// Header values JSON cannot represent are left out rather than losing the message
+		if headers, err := json.Marshal(d.Headers); err == nil {
+			msg.Headers = headers
+		}
+	}
+	return msg
+}
+
+// replayMessage rebuilds the persistent message of captured, marked as a replay of the
+// capture with id
+func replayMessage(id int, captured db.CapturedMessage) (amqp091.Publishing, error) {
+	headers := amqp091.Table{}
+	if len(captured.Headers) > 0 {
+		var err error
+		if headers, err = tableFromJSON(captured.Headers); err != nil {
+			return amqp091.Publishing{}, fmt.Errorf("error decoding headers of message %d: %w", captured.Seq, err)
+		}
+	}
+	headers[HeaderReplayOf] = int64(id)
+	return amqp091.Publishing{
+		ContentType:   captured.ContentType,
+		MessageId:     captured.MessageID,
+		CorrelationId: captured.CorrelationID,
+		DeliveryMode:  amqp091.Persistent,
+		Timestamp:     time.Now().UTC(),
+		Headers:       headers,
+		Body:          captured.Body,
+	}, nil
+}
+
+// tableFromJSON decodes headers stored as a JSON object into an AMQP table. Whole numbers
+// become int64, as the publish time headers of test messages are.
+func tableFromJSON(data []byte) (amqp091.Table, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var headers map[string]interface{}
+	if err := dec.Decode(&headers); err != nil {
+		return nil, err
+	}
+	return tableValue(headers).(amqp091.Table), nil
+}
+
+// tableValue converts a decoded JSON value into one an AMQP table can hold
+func tableValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		table := make(amqp091.Table, len(v))
+		for key, value := range v {
+			table[key] = tableValue(value)
+		}
+		return table
+	case []interface{}:
+		for i, value := range v {
+			v[i] = tableValue(value)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+package amqp
+
+import (
+	"context"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapturedMessageRoundTrip(t *testing.T) {
+	published := time.Now().UnixNano()
+	d := amqp091.Delivery{
+		Exchange: "t3.topics", RoutingKey: "orders.created", ContentType: "application/json", MessageId: "m1",
+		CorrelationId: "c1", Body: []byte(`{"id":1}`),
+		Headers: amqp091.Table{
+			HeaderSchemaName: "orders", HeaderPublished: published, "x-ratio": 0.5,
+			"x-nested": amqp091.Table{"attempts": int32(2)},
+		},
+	}
+	captured := capturedMessage(3, time.Now(), d)
+	assert.Equal(t, 3, captured.Seq)
+	assert.Equal(t, "orders.created", captured.RoutingKey)
+
+	msg, err := replayMessage(7, captured)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, d.Body, msg.Body)
+	assert.Equal(t, "m1", msg.MessageId)
+	assert.Equal(t, "c1", msg.CorrelationId)
+	assert.Equal(t, "orders", msg.Headers[HeaderSchemaName])
+	assert.Equal(t, published, msg.Headers[HeaderPublished], "whole numbers come back as int64")
+	assert.Equal(t, 0.5, msg.Headers["x-ratio"])
+	assert.Equal(t, amqp091.Table{"attempts": int64(2)}, msg.Headers["x-nested"])
+	assert.Equal(t, int64(7), msg.Headers[HeaderReplayOf])
+	assert.NoError(t, msg.Headers.Validate())
+}
+
+func TestCapturerReplay(t *testing.T) {
+	store := db.NewMemoryStore()
+	capture, err := store.CreateCapture(db.Capture{Topic: "orders.created", Status: db.CaptureFinished})
+	if !assert.NoError(t, err) {
+		return
+	}
+	received := time.Now()
+	var messages []db.CapturedMessage
+	for i, gap := range []time.Duration{0, 40 * time.Millisecond, 80 * time.Millisecond} {
+		messages = append(messages, db.CapturedMessage{
+			Seq: i, Received: received.Add(gap), Exchange: "t3.topics", RoutingKey: "orders.created",
+			Body: []byte{byte('a' + i)},
+		})
+	}
+	assert.NoError(t, store.AddCapturedMessages(capture.ID, messages))
+
+	type sent struct {
+		key  string
+		body string
+		at   time.Time
+	}
+	var out []sent
+	released := false
+	c := &Capturer{store: store, sender: func() (sendFunc, func(), error) {
+		send := func(_ context.Context, _, key string, msg amqp091.Publishing) error {
+			out = append(out, sent{key, string(msg.Body), time.Now()})
+			return nil
+		}
+		return send, func() { released = true }, nil
+	}}
+
+	n, err := c.Replay(context.Background(), capture.ID, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.True(t, released)
+	if assert.Len(t, out, 3) {
+		assert.Equal(t, "abc", out[0].body+out[1].body+out[2].body, "original order")
+		assert.Equal(t, "orders.created", out[0].key)
+		assert.GreaterOrEqual(t, out[2].at.Sub(out[0].at), 40*time.Millisecond, "gaps are halved at speed 2")
+	}
+
+	out = nil
+	n, err = c.Replay(context.Background(), capture.ID, "orders.replayed", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	if assert.Len(t, out, 3) {
+		assert.Equal(t, "orders.replayed", out[0].key)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err = c.Replay(ctx, capture.ID, "", 1)
+	assert.Error(t, err)
+	assert.Equal(t, 1, n, "the first message is due immediately")
+
+	_, err = c.Replay(context.Background(), 99, "", 1)
+	assert.ErrorIs(t, err, db.ErrCaptureNotFound)
+}
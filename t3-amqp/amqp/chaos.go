@@ -0,0 +1,121 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"math/rand"
+	"t3-amqp/scenario"
+	"time"
+)
+
+type heldMessage struct {
+	exchange, key string
+	msg           amqp091.Publishing
+}
+
+// chaosSender publishes the messages of a scenario with chaos options. It publishes on
+// a connection of its own, so dropping it leaves the connection shared by the server
+// alone, and duplicates and reorders messages at random.
+type chaosSender struct {
+	options scenario.ChaosOptions
+	rng     *rand.Rand
+	// open connects a new sender, close drops its connection
+	open  func() (sendFunc, func(), error)
+	send  sendFunc
+	close func()
+
+	held  []heldMessage
+	stats scenario.ChaosStats
+}
+
+func newChaosSender(options scenario.ChaosOptions, open func() (sendFunc, func(), error)) (*chaosSender, error) {
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	send, close, err := open()
+	if err != nil {
+		return nil, err
+	}
+	return &chaosSender{options: options, rng: rand.New(rand.NewSource(seed)), open: open, send: send, close: close}, nil
+}
+
+// dialSender opens a dedicated connection with the configuration of conn and a confirm
+// channel on it, closing both drops the connection
+func dialSender(conn *Conn) (sendFunc, func(), error) {
+	dedicated, err := Dial(conn.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	send, release, err := channelSender(dedicated)
+	if err != nil {
+		dedicated.Close()
+		return nil, nil, err
+	}
+	return send, func() {
+		release()
+		dedicated.Close()
+	}, nil
+}
+
+// Send publishes msg, or holds it back until the reorder window is full
+func (c *chaosSender) Send(ctx context.Context, exchange, key string, msg amqp091.Publishing) error {
+	if c.options.ReorderWindow <= 1 {
+		return c.publish(ctx, exchange, key, msg)
+	}
+	c.held = append(c.held, heldMessage{exchange: exchange, key: key, msg: msg})
+	if len(c.held) < c.options.ReorderWindow {
+		return nil
+	}
+	return c.Flush(ctx)
+}
+
+// Flush publishes the messages held back in random order
+func (c *chaosSender) Flush(ctx context.Context) error {
+	held := c.held
+	c.held = nil
+	for i, j := range c.rng.Perm(len(held)) {
+		if i != j {
+			c.stats.Reordered++
+		}
+		if err := c.publish(ctx, held[j].exchange, held[j].key, held[j].msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *chaosSender) publish(ctx context.Context, exchange, key string, msg amqp091.Publishing) error {
+	if c.rng.Float64() < c.options.DropRate {
+		if err := c.reconnect(); err != nil {
+			return fmt.Errorf("error reconnecting after dropping the connection: %w", err)
+		}
+	}
+	if err := c.send(ctx, exchange, key, msg); err != nil {
+		return err
+	}
+	if c.rng.Float64() < c.options.DuplicateRate {
+		c.stats.Duplicates++
+		return c.send(ctx, exchange, key, msg)
+	}
+	return nil
+}
+
+// reconnect drops the connection and opens a new one
+func (c *chaosSender) reconnect() error {
+	c.close()
+	c.stats.Drops++
+	send, close, err := c.open()
+	if err != nil {
+		c.close = func() {}
+		return err
+	}
+	c.send, c.close = send, close
+	return nil
+}
+
+// Close closes the connection, messages still held back are not published
+func (c *chaosSender) Close() {
+	c.close()
+}
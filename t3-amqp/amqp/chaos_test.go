@@ -0,0 +1,76 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"t3-amqp/scenario"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConnections opens senders recording what they publish, counting opens and closes
+type fakeConnections struct {
+	opened, closed int
+	fail           bool
+	published      []string
+}
+
+func (f *fakeConnections) open() (sendFunc, func(), error) {
+	if f.fail {
+		return nil, nil, errors.New("connection refused")
+	}
+	f.opened++
+	send := func(ctx context.Context, exchange, key string, msg amqp091.Publishing) error {
+		f.published = append(f.published, msg.MessageId)
+		return nil
+	}
+	return send, func() { f.closed++ }, nil
+}
+
+func TestChaosSenderDropsAndDuplicates(t *testing.T) {
+	conns := &fakeConnections{}
+	c, err := newChaosSender(scenario.ChaosOptions{DropRate: 1, DuplicateRate: 1, Seed: 1}, conns.open)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, id := range []string{"a", "b"} {
+		assert.NoError(t, c.Send(context.Background(), "t3", "orders", amqp091.Publishing{MessageId: id}))
+	}
+	c.Close()
+
+	assert.Equal(t, []string{"a", "a", "b", "b"}, conns.published)
+	assert.Equal(t, scenario.ChaosStats{Drops: 2, Duplicates: 2}, c.stats)
+	assert.Equal(t, 3, conns.opened, "every drop reconnects")
+	assert.Equal(t, 3, conns.closed)
+
+	conns = &fakeConnections{}
+	c, _ = newChaosSender(scenario.ChaosOptions{DropRate: 1, Seed: 1}, conns.open)
+	conns.fail = true
+	assert.Error(t, c.Send(context.Background(), "t3", "orders", amqp091.Publishing{MessageId: "a"}))
+	assert.Empty(t, conns.published)
+}
+
+func TestChaosSenderReorders(t *testing.T) {
+	conns := &fakeConnections{}
+	c, err := newChaosSender(scenario.ChaosOptions{ReorderWindow: 4, Seed: 7}, conns.open)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, c.Send(context.Background(), "t3", "orders", amqp091.Publishing{MessageId: id}))
+	}
+	assert.Len(t, conns.published, 4, "messages are held until the window is full")
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, conns.published)
+
+	assert.NoError(t, c.Flush(context.Background()))
+	assert.Equal(t, "e", conns.published[4])
+	reordered := 0
+	for i, id := range []string{"a", "b", "c", "d"} {
+		if conns.published[i] != id {
+			reordered++
+		}
+	}
+	assert.Equal(t, reordered, c.stats.Reordered)
+}
@@ -0,0 +1,97 @@
+package amqp
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"time"
+)
+
+const (
+	DefaultHeartbeat         = 10 * time.Second
+	DefaultConnectionTimeout = 30 * time.Second
+	DefaultExchange          = "amq.topic"
+)
+
+// ExchangeConfig describes an exchange declared on startup
+type ExchangeConfig struct {
+	Name    string `mapstructure:"name"`
+	Kind    string `mapstructure:"kind"`
+	Durable bool   `mapstructure:"durable"`
+}
+
+// BindingConfig binds a queue to an exchange with a routing key pattern
+type BindingConfig struct {
+	Exchange   string `mapstructure:"exchange"`
+	RoutingKey string `mapstructure:"routing_key"`
+}
+
+// QueueConfig describes a queue and its bindings declared on startup
+type QueueConfig struct {
+	Name     string          `mapstructure:"name"`
+	Durable  bool            `mapstructure:"durable"`
+	Bindings []BindingConfig `mapstructure:"bindings"`
+}
+
+// Config struct to hold broker connection info and tuning. URL is a full amqp(s):// URI
+// whose query options override the separate tuning fields.
+type Config struct {
+	// Kind selects the backend topic tests run on, broker.KindAMQP or broker.KindKafka
+	Kind              string        `mapstructure:"kind"`
+	URL               string        `mapstructure:"url"`
+	Heartbeat         time.Duration `mapstructure:"heartbeat"`
+	ConnectionTimeout time.Duration `mapstructure:"connection_timeout"`
+	FrameSize         int           `mapstructure:"frame_size"`
+	ChannelMax        uint16        `mapstructure:"channel_max"`
+	Management        struct {
+		URL          string        `mapstructure:"url"`
+		User         string        `mapstructure:"user"`
+		Password     string        `mapstructure:"password"`
+		PollInterval time.Duration `mapstructure:"poll_interval"`
+	} `mapstructure:"management"`
+	// Exchange receives messages sent through the publisher, topics are its routing keys
+	Exchange  string           `mapstructure:"exchange"`
+	Exchanges []ExchangeConfig `mapstructure:"exchanges"`
+	Queues    []QueueConfig    `mapstructure:"queues"`
+	// Headers is the convention test messages are stamped with and consumed messages
+	// name their schema in, whichever kind of broker they run on
+	Headers broker.Headers `mapstructure:"headers"`
+}
+
+// LoadConfig reads the broker section of the already loaded configuration, BROKER_*
+// environment variables override the file
+func LoadConfig() (*Config, error) {
+	if err := db.BindEnv("broker", Config{}); err != nil {
+		return nil, err
+	}
+	// UnmarshalKey would only see the file, environment overrides need the full settings
+	var settings struct {
+		Broker Config `mapstructure:"broker"`
+	}
+	if err := viper.Unmarshal(&settings); err != nil {
+		return nil, fmt.Errorf("unable to decode broker config: %w", err)
+	}
+	config := settings.Broker
+	if err := config.applyURI(); err != nil {
+		return nil, err
+	}
+	config.applyDefaults()
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.Heartbeat <= 0 {
+		c.Heartbeat = DefaultHeartbeat
+	}
+	if c.ConnectionTimeout <= 0 {
+		c.ConnectionTimeout = DefaultConnectionTimeout
+	}
+	if c.Exchange == "" {
+		c.Exchange = DefaultExchange
+	}
+	if c.Kind == "" {
+		c.Kind = broker.KindAMQP
+	}
+	c.Headers = c.Headers.WithDefaults()
+}
@@ -0,0 +1,165 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"t3-amqp/metrics"
+	"t3-amqp/redact"
+	"time"
+)
+
+// DropEvent records the broker connection closing underneath a run
+type DropEvent struct {
+	At              time.Time `json:"at"`
+	Reason          string    `json:"reason"`
+	MissedHeartbeat bool      `json:"missedHeartbeat"`
+}
+
+// Conn is a broker connection that keeps track of why it was dropped
+type Conn struct {
+	*amqp091.Connection
+	config Config
+
+	mu       sync.Mutex
+	drops    []DropEvent
+	channels atomic.Int64
+}
+
+// ConnState is the broker connection as /health/details reports it
+type ConnState struct {
+	Up               bool        `json:"up"`
+	Channels         int64       `json:"channels"`
+	MissedHeartbeats int         `json:"missedHeartbeats"`
+	Drops            []DropEvent `json:"drops,omitempty"`
+}
+
+// Dial connects to the broker applying the heartbeat, timeout, frame size and channel
+// limits from config
+func Dial(config Config) (*Conn, error) {
+	if err := config.applyURI(); err != nil {
+		return nil, err
+	}
+	config.applyDefaults()
+
+	conn, err := amqp091.DialConfig(
+		config.URL, amqp091.Config{
+			Heartbeat:  config.Heartbeat,
+			ChannelMax: config.ChannelMax,
+			FrameSize:  config.FrameSize,
+			Dial:       amqp091.DefaultDial(config.ConnectionTimeout),
+		},
+	)
+	if err != nil {
+		return nil, redact.Error(fmt.Errorf("unable to connect to broker: %w", err))
+	}
+
+	c := &Conn{Connection: conn, config: config}
+	metrics.AMQPConnections.Inc()
+	go c.watch(conn.NotifyClose(make(chan *amqp091.Error, 1)))
+	return c, nil
+}
+
+func (c *Conn) watch(closed chan *amqp091.Error) {
+	err, ok := <-closed
+	metrics.AMQPConnections.Dec()
+	if !ok || err == nil {
+		// Closed by us
+		return
+	}
+
+	event := DropEvent{
+		At:              time.Now().UTC(),
+		Reason:          redact.String(err.Error()),
+		MissedHeartbeat: isMissedHeartbeat(err),
+	}
+	if event.MissedHeartbeat {
+		log.Printf("broker connection dropped after missed heartbeats (interval %s): %s", c.config.Heartbeat, event.Reason)
+	} else {
+		log.Printf("broker connection dropped: %s", event.Reason)
+	}
+
+	c.mu.Lock()
+	c.drops = append(c.drops, event)
+	c.mu.Unlock()
+}
+
+// isMissedHeartbeat reports whether the connection was closed because the read deadline
+// kept alive by broker heartbeats expired
+func isMissedHeartbeat(err *amqp091.Error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return strings.Contains(err.Reason, "i/o timeout")
+}
+
+// Channel opens a channel on the connection, it counts in metrics.AMQPChannels until it
+// is closed by either side
+func (c *Conn) Channel() (*amqp091.Channel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	c.trackChannel(ch.NotifyClose(make(chan *amqp091.Error, 1)))
+	return ch, nil
+}
+
+// trackChannel counts a channel as open until closed is closed or delivers its error
+func (c *Conn) trackChannel(closed chan *amqp091.Error) {
+	c.channels.Add(1)
+	metrics.AMQPChannels.Inc()
+	go func() {
+		<-closed
+		c.channels.Add(-1)
+		metrics.AMQPChannels.Dec()
+	}()
+}
+
+// Ready returns an error once the connection is closed, nil while it is open
+func (c *Conn) Ready(context.Context) error {
+	if c.IsClosed() {
+		return errors.New("broker connection is closed")
+	}
+	return nil
+}
+
+// Drops returns every unexpected close observed on this connection
+func (c *Conn) Drops() []DropEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]DropEvent(nil), c.drops...)
+}
+
+// MissedHeartbeats returns how many drops were caused by missed heartbeats
+func (c *Conn) MissedHeartbeats() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, d := range c.drops {
+		if d.MissedHeartbeat {
+			n++
+		}
+	}
+	return n
+}
+
+// State reports whether the connection is open along with its open channels and drops.
+// A nil Conn, a broker that was never reached, is down.
+func (c *Conn) State() ConnState {
+	if c == nil {
+		return ConnState{}
+	}
+	return ConnState{
+		Up:               !c.IsClosed(),
+		Channels:         c.channels.Load(),
+		MissedHeartbeats: c.MissedHeartbeats(),
+		Drops:            c.Drops(),
+	}
+}
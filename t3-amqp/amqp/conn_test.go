@@ -0,0 +1,58 @@
+package amqp
+
+import (
+	"t3-amqp/metrics"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMissedHeartbeat(t *testing.T) {
+	assert.True(
+		t, isMissedHeartbeat(
+			&amqp091.Error{Code: amqp091.FrameError, Reason: "read tcp 127.0.0.1:5672: i/o timeout"},
+		),
+	)
+	assert.False(t, isMissedHeartbeat(&amqp091.Error{Code: 320, Reason: "CONNECTION_FORCED"}))
+}
+
+func TestWatchRecordsDrops(t *testing.T) {
+	c := &Conn{config: Config{Heartbeat: time.Second}}
+	closed := make(chan *amqp091.Error, 1)
+	closed <- &amqp091.Error{Code: amqp091.FrameError, Reason: "i/o timeout"}
+	c.watch(closed)
+
+	assert.Len(t, c.Drops(), 1)
+	assert.Equal(t, 1, c.MissedHeartbeats())
+}
+
+func TestTrackChannelCountsOpenChannels(t *testing.T) {
+	c := &Conn{}
+	before := testutil.ToFloat64(metrics.AMQPChannels)
+	first, second := make(chan *amqp091.Error, 1), make(chan *amqp091.Error, 1)
+	c.trackChannel(first)
+	c.trackChannel(second)
+	assert.Equal(t, int64(2), c.channels.Load())
+	assert.Equal(t, before+2, testutil.ToFloat64(metrics.AMQPChannels))
+
+	// Closed by us, then by the broker
+	close(first)
+	second <- &amqp091.Error{Code: amqp091.ChannelError, Reason: "PRECONDITION_FAILED"}
+	assert.Eventually(t, func() bool { return c.channels.Load() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, before, testutil.ToFloat64(metrics.AMQPChannels))
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	viper.Set("broker", map[string]interface{}{"url": "amqp://localhost", "heartbeat": "5s", "channel_max": 64})
+	defer viper.Reset()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, config.Heartbeat)
+	assert.Equal(t, DefaultConnectionTimeout, config.ConnectionTimeout)
+	assert.Equal(t, uint16(64), config.ChannelMax)
+}
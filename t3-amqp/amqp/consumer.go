@@ -0,0 +1,249 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"math/rand"
+	"slices"
+	"sort"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"time"
+)
+
+type pendingAck struct {
+	tag uint64
+	due time.Time
+}
+
+// ackTracker applies an ack strategy to delivery tags and keeps the unacked depth
+type ackTracker struct {
+	strategy scenario.AckStrategy
+	ack      func(tag uint64, multiple bool) error
+	reject   func(tag uint64, requeue bool) error
+	// delay holds acknowledgements back when chaos options delay them
+	delay func() time.Duration
+
+	handledCount int
+	unacked      int
+	maxUnacked   int
+	acked        int
+	nacked       int
+	rejected     int
+	lastTag      uint64
+	pending      []pendingAck
+}
+
+func newAckTracker(
+	strategy scenario.AckStrategy, ack func(tag uint64, multiple bool) error,
+	reject func(tag uint64, requeue bool) error,
+) *ackTracker {
+	if strategy.Mode == "" {
+		strategy.Mode = scenario.AckManual
+	}
+	return &ackTracker{strategy: strategy, ack: ack, reject: reject}
+}
+
+// handled records that the message with tag has been processed at now
+func (t *ackTracker) handled(tag uint64, now time.Time) error {
+	n := t.handledCount
+	t.handledCount++
+	if t.strategy.Mode == scenario.AckAuto {
+		t.acked++
+		return nil
+	}
+
+	t.unacked++
+	if t.unacked > t.maxUnacked {
+		t.maxUnacked = t.unacked
+	}
+	t.lastTag = tag
+
+	switch t.strategy.Mode {
+	case scenario.AckBatch:
+		if t.unacked >= t.strategy.BatchSize {
+			return t.ackUpTo(tag)
+		}
+		return nil
+	case scenario.AckDelayed:
+		t.schedule(tag, now.Add(t.strategy.Delay))
+		return nil
+	case scenario.AckNack, scenario.AckReject:
+		if t.strategy.Fails(n) {
+			return t.fail(tag, t.strategy.Mode == scenario.AckNack)
+		}
+	}
+
+	if t.delay != nil {
+		t.schedule(tag, now)
+		return nil
+	}
+	if err := t.ack(tag, false); err != nil {
+		return err
+	}
+	t.unacked--
+	t.acked++
+	return nil
+}
+
+// fail settles the message with tag negatively, requeueing it or not
+func (t *ackTracker) fail(tag uint64, requeue bool) error {
+	if err := t.reject(tag, requeue); err != nil {
+		return err
+	}
+	t.unacked--
+	if requeue {
+		t.nacked++
+	} else {
+		t.rejected++
+	}
+	return nil
+}
+
+// schedule acknowledges the message with tag at due, later when acks are delayed. The
+// pending acks are kept in the order they are due.
+func (t *ackTracker) schedule(tag uint64, due time.Time) {
+	if t.delay != nil {
+		due = due.Add(t.delay())
+	}
+	i := sort.Search(len(t.pending), func(i int) bool { return t.pending[i].due.After(due) })
+	t.pending = slices.Insert(t.pending, i, pendingAck{tag: tag, due: due})
+}
+
+func (t *ackTracker) ackUpTo(tag uint64) error {
+	if err := t.ack(tag, true); err != nil {
+		return err
+	}
+	t.acked += t.unacked
+	t.unacked = 0
+	return nil
+}
+
+// tick acknowledges delayed messages that are due at now
+func (t *ackTracker) tick(now time.Time) error {
+	for len(t.pending) > 0 && !t.pending[0].due.After(now) {
+		if err := t.ack(t.pending[0].tag, false); err != nil {
+			return err
+		}
+		t.pending = t.pending[1:]
+		t.unacked--
+		t.acked++
+	}
+	return nil
+}
+
+// fill copies the acknowledgement counts into stats
+func (t *ackTracker) fill(stats *scenario.AckStats) {
+	stats.Acked, stats.Nacked, stats.Rejected, stats.MaxUnacked = t.acked, t.nacked, t.rejected, t.maxUnacked
+}
+
+// flush acknowledges everything still outstanding, such as a partial batch
+func (t *ackTracker) flush() error {
+	if t.unacked == 0 {
+		return nil
+	}
+	t.pending = nil
+	return t.ackUpTo(t.lastTag)
+}
+
+// Consume reads up to max messages from queue (all until ctx is done when max is 0),
+// passing each to handle and acknowledging them according to strategy. Consumers of a
+// consumer group each call Consume, competing for the messages of the queue.
+func Consume(
+	ctx context.Context, conn *Conn, queue string, strategy scenario.AckStrategy, max int,
+	handle func(amqp091.Delivery),
+) (scenario.AckStats, error) {
+	return consume(ctx, conn, queue, strategy, 0, max, handle)
+}
+
+// consume is Consume holding each acknowledgement back for a random time up to ackDelay
+func consume(
+	ctx context.Context, conn *Conn, queue string, strategy scenario.AckStrategy, ackDelay time.Duration, max int,
+	handle func(amqp091.Delivery),
+) (scenario.AckStats, error) {
+	stats := scenario.AckStats{Mode: strategy.Mode, Prefetch: strategy.Prefetch}
+	if err := strategy.Validate(); err != nil {
+		return stats, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return stats, err
+	}
+	defer ch.Close()
+
+	if strategy.Prefetch > 0 {
+		if err := ch.Qos(strategy.Prefetch, 0, false); err != nil {
+			return stats, fmt.Errorf("error setting prefetch: %w", err)
+		}
+	}
+	deliveries, err := ch.ConsumeWithContext(
+		ctx, queue, "", strategy.Mode == scenario.AckAuto, false, false, false, nil,
+	)
+	if err != nil {
+		return stats, fmt.Errorf("error consuming from %s: %w", queue, err)
+	}
+
+	tracker := newAckTracker(strategy, ch.Ack, ch.Reject)
+	stats.Mode = tracker.strategy.Mode
+	tickEvery := 10 * time.Millisecond
+	if strategy.Mode == scenario.AckDelayed && strategy.Delay/4 < tickEvery {
+		tickEvery = strategy.Delay / 4
+	}
+	if ackDelay > 0 {
+		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+		tracker.delay = func() time.Duration { return time.Duration(rng.Int63n(int64(ackDelay))) }
+		if ackDelay/4 < tickEvery {
+			tickEvery = ackDelay/4 + time.Millisecond
+		}
+	}
+	ticker := time.NewTicker(tickEvery)
+	defer ticker.Stop()
+
+	started := time.Now()
+	defer func() {
+		if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+			stats.Throughput = float64(stats.Consumed) / elapsed
+		}
+	}()
+
+	for max == 0 || stats.Consumed < max {
+		select {
+		case <-ctx.Done():
+			err = tracker.flush()
+			tracker.fill(&stats)
+			return stats, err
+		case now := <-ticker.C:
+			if err := tracker.tick(now); err != nil {
+				return stats, err
+			}
+		case d, ok := <-deliveries:
+			if !ok {
+				tracker.fill(&stats)
+				return stats, fmt.Errorf("consumer on %s was closed by the broker", queue)
+			}
+			handle(d)
+			stats.Consumed++
+			metrics.AMQPConsumed.Inc()
+			if err := tracker.handled(d.DeliveryTag, time.Now()); err != nil {
+				return stats, fmt.Errorf("error acknowledging message: %w", err)
+			}
+		}
+	}
+
+	// Let delayed acks run their course before settling the rest
+	for len(tracker.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			tracker.pending = nil
+		case now := <-ticker.C:
+			if err := tracker.tick(now); err != nil {
+				return stats, err
+			}
+		}
+	}
+	err = tracker.flush()
+	tracker.fill(&stats)
+	return stats, err
+}
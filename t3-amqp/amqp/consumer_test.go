@@ -0,0 +1,115 @@
+package amqp
+
+import (
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ackCall struct {
+	tag      uint64
+	multiple bool
+}
+
+func recordAcks(calls *[]ackCall) func(uint64, bool) error {
+	return func(tag uint64, multiple bool) error {
+		*calls = append(*calls, ackCall{tag, multiple})
+		return nil
+	}
+}
+
+func TestAckTrackerBatch(t *testing.T) {
+	var calls []ackCall
+	tr := newAckTracker(scenario.AckStrategy{Mode: scenario.AckBatch, BatchSize: 3}, recordAcks(&calls), nil)
+	now := time.Now()
+	for tag := uint64(1); tag <= 7; tag++ {
+		assert.NoError(t, tr.handled(tag, now))
+	}
+	assert.Equal(t, []ackCall{{3, true}, {6, true}}, calls)
+	assert.Equal(t, 3, tr.maxUnacked)
+
+	assert.NoError(t, tr.flush())
+	assert.Equal(t, ackCall{7, true}, calls[2])
+	assert.Equal(t, 7, tr.acked)
+}
+
+func TestAckTrackerDelayed(t *testing.T) {
+	var calls []ackCall
+	tr := newAckTracker(scenario.AckStrategy{Mode: scenario.AckDelayed, Delay: time.Second}, recordAcks(&calls), nil)
+	now := time.Now()
+	assert.NoError(t, tr.handled(1, now))
+	assert.NoError(t, tr.handled(2, now.Add(500*time.Millisecond)))
+	assert.Equal(t, 2, tr.maxUnacked)
+
+	assert.NoError(t, tr.tick(now.Add(time.Second)))
+	assert.Equal(t, []ackCall{{1, false}}, calls)
+	assert.NoError(t, tr.tick(now.Add(2*time.Second)))
+	assert.Equal(t, 2, tr.acked)
+	assert.Zero(t, tr.unacked)
+}
+
+func TestAckTrackerManualAndAuto(t *testing.T) {
+	var calls []ackCall
+	tr := newAckTracker(scenario.AckStrategy{}, recordAcks(&calls), nil)
+	assert.NoError(t, tr.handled(1, time.Now()))
+	assert.Equal(t, []ackCall{{1, false}}, calls)
+	assert.Equal(t, 1, tr.maxUnacked)
+
+	calls = nil
+	tr = newAckTracker(scenario.AckStrategy{Mode: scenario.AckAuto}, recordAcks(&calls), nil)
+	assert.NoError(t, tr.handled(1, time.Now()))
+	assert.Empty(t, calls)
+	assert.Zero(t, tr.maxUnacked)
+}
+
+func TestAckTrackerNackAndReject(t *testing.T) {
+	var calls []ackCall
+	var rejected []uint64
+	var requeued []bool
+	reject := func(tag uint64, requeue bool) error {
+		rejected, requeued = append(rejected, tag), append(requeued, requeue)
+		return nil
+	}
+	tr := newAckTracker(scenario.AckStrategy{Mode: scenario.AckNack, FailRate: 0.5}, recordAcks(&calls), reject)
+	for tag := uint64(1); tag <= 4; tag++ {
+		assert.NoError(t, tr.handled(tag, time.Now()))
+	}
+	assert.Equal(t, []ackCall{{1, false}, {3, false}}, calls)
+	assert.Equal(t, []uint64{2, 4}, rejected)
+	assert.Equal(t, []bool{true, true}, requeued, "nacked messages are requeued")
+
+	var stats scenario.AckStats
+	tr.fill(&stats)
+	assert.Equal(t, 2, stats.Acked)
+	assert.Equal(t, 2, stats.Nacked)
+	assert.Zero(t, tr.unacked)
+
+	calls, rejected, requeued = nil, nil, nil
+	tr = newAckTracker(scenario.AckStrategy{Mode: scenario.AckReject, FailRate: 1}, recordAcks(&calls), reject)
+	assert.NoError(t, tr.handled(1, time.Now()))
+	assert.Empty(t, calls)
+	assert.Equal(t, []bool{false}, requeued, "rejected messages are not requeued")
+	assert.Equal(t, 1, tr.rejected)
+}
+
+func TestAckTrackerChaosDelay(t *testing.T) {
+	var calls []ackCall
+	tr := newAckTracker(scenario.AckStrategy{}, recordAcks(&calls), nil)
+	delays := []time.Duration{300 * time.Millisecond, 100 * time.Millisecond}
+	tr.delay = func() time.Duration {
+		d := delays[0]
+		delays = delays[1:]
+		return d
+	}
+	now := time.Now()
+	assert.NoError(t, tr.handled(1, now))
+	assert.NoError(t, tr.handled(2, now))
+	assert.Empty(t, calls, "acks are held back")
+
+	assert.NoError(t, tr.tick(now.Add(200*time.Millisecond)))
+	assert.Equal(t, []ackCall{{2, false}}, calls, "acks go out in the order they are due")
+	assert.NoError(t, tr.tick(now.Add(time.Second)))
+	assert.Equal(t, 2, tr.acked)
+}
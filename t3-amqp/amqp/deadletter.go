@@ -0,0 +1,260 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"time"
+)
+
+const (
+	// DefaultDeadLetterMessages is how many messages an inspection reads when it sets no max
+	DefaultDeadLetterMessages = 100
+	// MaxDeadLetterMessages bounds one inspection, its messages are held unacked until it ends
+	MaxDeadLetterMessages = 10000
+	// HeaderRequeuedFrom marks requeued messages with the dead letter queue they come from
+	HeaderRequeuedFrom = "x-t3-requeued-from"
+)
+
+var ErrQueueNotFound = db.NewError(db.ErrNotFound, "queue not found")
+
+// DeadLetterRequest inspects up to Max messages of the dead letter queue Queue. With
+// Requeue the messages that conform to their schema by now are published to the topic
+// they were dead lettered from and removed from the queue.
+type DeadLetterRequest struct {
+	Queue   string `json:"queue"`
+	Max     int    `json:"max,omitempty"`
+	Requeue bool   `json:"requeue,omitempty"`
+}
+
+// Validate checks that the request names a queue and reads a bounded number of messages
+func (r DeadLetterRequest) Validate() error {
+	if r.Queue == "" {
+		return fmt.Errorf("queue is required")
+	}
+	if r.Max < 0 || r.Max > MaxDeadLetterMessages {
+		return fmt.Errorf("max must be between 0 and %d", MaxDeadLetterMessages)
+	}
+	return nil
+}
+
+// DeadLetter is an inspected message. Exchange and RoutingKey are where it was published
+// before it was dead lettered for Reason, Error is empty when it conforms to its schema.
+type DeadLetter struct {
+	MessageID  string             `json:"messageId,omitempty"`
+	Exchange   string             `json:"exchange"`
+	RoutingKey string             `json:"routingKey"`
+	Reason     string             `json:"reason,omitempty"`
+	Schema     scenario.SchemaRef `json:"schema"`
+	Error      string             `json:"error,omitempty"`
+	Requeued   bool               `json:"requeued"`
+}
+
+// DeadLetterReport summarizes an inspected dead letter queue. Reasons counts the
+// messages by why the broker dead lettered them and Errors by why they fail validation.
+type DeadLetterReport struct {
+	Queue      string         `json:"queue"`
+	Inspected  int            `json:"inspected"`
+	Valid      int            `json:"valid"`
+	Invalid    int            `json:"invalid"`
+	Unresolved int            `json:"unresolved"`
+	Requeued   int            `json:"requeued"`
+	Reasons    map[string]int `json:"reasons"`
+	Errors     map[string]int `json:"errors"`
+	Messages   []DeadLetter   `json:"messages"`
+}
+
+func (r *DeadLetterReport) record(letter DeadLetter, resolved bool) {
+	r.Inspected++
+	if letter.Reason != "" {
+		r.Reasons[letter.Reason]++
+	}
+	switch {
+	case letter.Error == "":
+		r.Valid++
+	case resolved:
+		r.Invalid++
+		r.Errors[letter.Error]++
+	default:
+		r.Unresolved++
+		r.Errors[letter.Error]++
+	}
+	if len(r.Messages) < MaxReportedFailures {
+		r.Messages = append(r.Messages, letter)
+	}
+}
+
+// deadLetterChannel is the part of a channel an inspection reads the queue through
+type deadLetterChannel interface {
+	Get(queue string, autoAck bool) (amqp091.Delivery, bool, error)
+	Ack(tag uint64, multiple bool) error
+	Close() error
+}
+
+// DeadLetters inspects dead letter queues, validating their messages against the
+// registry and optionally requeueing the ones that conform
+type DeadLetters struct {
+	store   db.SchemaStore
+	headers broker.Headers
+	channel func() (deadLetterChannel, error)
+	// sender opens the confirm channel requeued messages are published on
+	sender func() (sendFunc, func(), error)
+}
+
+// NewDeadLetters creates an inspector reading queues on conn and looking schemas up in store
+func NewDeadLetters(conn *Conn, store db.SchemaStore) *DeadLetters {
+	return &DeadLetters{
+		store:   store,
+		headers: broker.DefaultHeaders,
+		channel: func() (deadLetterChannel, error) { return conn.Channel() },
+		sender:  func() (sendFunc, func(), error) { return channelSender(conn) },
+	}
+}
+
+// WithHeaders resolves the schema of dead lettered messages from headers
+func (l *DeadLetters) WithHeaders(headers broker.Headers) *DeadLetters {
+	l.headers = headers.WithDefaults()
+	return l
+}
+
+// Inspect reads the messages of the queue of req and validates each against the schema
+// named in its headers. Messages are held unacknowledged while the queue is read, so
+// none is seen twice, and all but the requeued ones return to the queue when the
+// inspection ends.
+func (l *DeadLetters) Inspect(ctx context.Context, req DeadLetterRequest) (*DeadLetterReport, error) {
+	max := req.Max
+	if max == 0 {
+		max = DefaultDeadLetterMessages
+	}
+	ch, err := l.channel()
+	if err != nil {
+		return nil, err
+	}
+	// Closing the channel returns the messages that were not acknowledged to the queue
+	defer ch.Close()
+
+	var send sendFunc
+	if req.Requeue {
+		var release func()
+		if send, release, err = l.sender(); err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	report := &DeadLetterReport{
+		Queue: req.Queue, Reasons: map[string]int{}, Errors: map[string]int{}, Messages: []DeadLetter{},
+	}
+	validators := broker.NewSchemaValidators(l.store)
+	for report.Inspected < max && ctx.Err() == nil {
+		d, ok, err := ch.Get(req.Queue, false)
+		var amqpErr *amqp091.Error
+		if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound {
+			return nil, fmt.Errorf("error reading %s: %w", req.Queue, ErrQueueNotFound)
+		}
+		if err != nil {
+			return report, fmt.Errorf("error reading %s: %w", req.Queue, err)
+		}
+		if !ok {
+			break
+		}
+		metrics.AMQPConsumed.Inc()
+
+		letter, resolved, dead := l.inspect(validators, d)
+		if req.Requeue && dead && letter.Error == "" {
+			if err := send(ctx, letter.Exchange, letter.RoutingKey, requeueMessage(req.Queue, d)); err != nil {
+				metrics.AMQPPublished.WithLabelValues("failed").Inc()
+				return report, fmt.Errorf("error requeueing message %s: %w", letter.MessageID, err)
+			}
+			metrics.AMQPPublished.WithLabelValues("ok").Inc()
+			if err := ch.Ack(d.DeliveryTag, false); err != nil {
+				return report, fmt.Errorf("error acknowledging requeued message: %w", err)
+			}
+			letter.Requeued = true
+			report.Requeued++
+		}
+		report.record(letter, resolved)
+	}
+	return report, nil
+}
+
+// inspect validates the dead lettered delivery d. resolved reports whether its schema is
+// registered and dead whether d carries the x-death history naming where it came from.
+func (l *DeadLetters) inspect(
+	validators *broker.SchemaValidators, d amqp091.Delivery,
+) (letter DeadLetter, resolved, dead bool) {
+	stamp := l.headers.Read(deliveryHeader(d))
+	letter = DeadLetter{MessageID: d.MessageId, Schema: stamp.SchemaRef}
+	letter.Exchange, letter.RoutingKey, letter.Reason, dead = deadLetterOrigin(d)
+
+	schema, err := validateStamp(validators, l.headers, stamp, d)
+	if schema != nil {
+		letter.Schema = scenario.SchemaRef{Name: schema.Name, Type: schema.Type, Version: schema.Version}
+	}
+	if err != nil {
+		letter.Error = err.Error()
+	}
+	return letter, schema != nil, dead
+}
+
+// deadLetterOrigin returns the exchange and routing key d was published to before the
+// broker dead lettered it the first time and why. ok is false when d has no x-death
+// history, it then names where d was published to the dead letter queue.
+func deadLetterOrigin(d amqp091.Delivery) (exchange, key, reason string, ok bool) {
+	deaths, _ := d.Headers["x-death"].([]interface{})
+	if len(deaths) == 0 {
+		return d.Exchange, d.RoutingKey, "", false
+	}
+
+	// x-death lists the most recent death first, the first one is also in the x-first-death headers
+	first, _ := deaths[len(deaths)-1].(amqp091.Table)
+	queue, hasFirst := d.Headers["x-first-death-queue"].(string)
+	for _, entry := range deaths {
+		if table, isTable := entry.(amqp091.Table); isTable && hasFirst && table["queue"] == queue {
+			first = table
+			break
+		}
+	}
+	exchange, _ = first["exchange"].(string)
+	reason, _ = first["reason"].(string)
+	keys, _ := first["routing-keys"].([]interface{})
+	if len(keys) > 0 {
+		key, _ = keys[0].(string)
+	} else {
+		key = d.RoutingKey
+	}
+	return exchange, key, reason, true
+}
+
+// requeueMessage rebuilds the persistent message of d to publish it again, marked as
+// requeued from queue. The x-death history is kept so repeated failures stay visible.
+func requeueMessage(queue string, d amqp091.Delivery) amqp091.Publishing {
+	headers := amqp091.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	headers[HeaderRequeuedFrom] = queue
+	timestamp := d.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now().UTC()
+	}
+	return amqp091.Publishing{
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		MessageId:       d.MessageId,
+		CorrelationId:   d.CorrelationId,
+		ReplyTo:         d.ReplyTo,
+		Type:            d.Type,
+		AppId:           d.AppId,
+		Priority:        d.Priority,
+		DeliveryMode:    amqp091.Persistent,
+		Timestamp:       timestamp,
+		Headers:         headers,
+		Body:            d.Body,
+	}
+}
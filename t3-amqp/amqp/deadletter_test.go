@@ -0,0 +1,121 @@
+package amqp
+
+import (
+	"context"
+	"t3-amqp/db"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeQueue hands out its messages like basic.get and records which were acknowledged
+type fakeQueue struct {
+	messages []amqp091.Delivery
+	acked    []uint64
+	closed   bool
+}
+
+func (q *fakeQueue) Get(queue string, _ bool) (amqp091.Delivery, bool, error) {
+	if queue != "orders.dlq" {
+		return amqp091.Delivery{}, false, &amqp091.Error{Code: amqp091.NotFound, Reason: "NOT_FOUND"}
+	}
+	if len(q.messages) == 0 {
+		return amqp091.Delivery{}, false, nil
+	}
+	d := q.messages[0]
+	q.messages = q.messages[1:]
+	return d, true, nil
+}
+
+func (q *fakeQueue) Ack(tag uint64, _ bool) error {
+	q.acked = append(q.acked, tag)
+	return nil
+}
+
+func (q *fakeQueue) Close() error {
+	q.closed = true
+	return nil
+}
+
+func deadLettered(tag uint64, reason, body string) amqp091.Delivery {
+	return amqp091.Delivery{
+		DeliveryTag: tag, MessageId: body, Exchange: "dlx", RoutingKey: "orders.created",
+		ContentType: "application/json", Body: []byte(body),
+		Headers: amqp091.Table{
+			HeaderSchemaName: "orders", HeaderSchemaType: "json", HeaderSchemaVersion: "1.0.0",
+			"x-first-death-queue": "orders",
+			"x-death": []interface{}{
+				amqp091.Table{"queue": "orders.retry", "reason": "expired", "exchange": "retry", "count": int64(1)},
+				amqp091.Table{
+					"queue": "orders", "reason": reason, "exchange": "t3.topics", "count": int64(1),
+					"routing-keys": []interface{}{"orders.created"},
+				},
+			},
+		},
+	}
+}
+
+func TestDeadLettersInspectAndRequeue(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{
+		Name: "orders", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`,
+	})
+	assert.NoError(t, err)
+
+	queue := &fakeQueue{messages: []amqp091.Delivery{
+		deadLettered(1, "rejected", `{"id":1}`),
+		deadLettered(2, "rejected", `{"id":"x"}`),
+		deadLettered(3, "maxlen", `{"id":"y"}`),
+		{DeliveryTag: 4, RoutingKey: "orders.dlq", Body: []byte(`{}`)},
+	}}
+	type sent struct{ exchange, key string }
+	var out []sent
+	var published []amqp091.Publishing
+	l := &DeadLetters{
+		store:   store,
+		channel: func() (deadLetterChannel, error) { return queue, nil },
+		sender: func() (sendFunc, func(), error) {
+			send := func(_ context.Context, exchange, key string, msg amqp091.Publishing) error {
+				out = append(out, sent{exchange, key})
+				published = append(published, msg)
+				return nil
+			}
+			return send, func() {}, nil
+		},
+	}
+
+	report, err := l.Inspect(context.Background(), DeadLetterRequest{Queue: "orders.dlq", Requeue: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, queue.closed, "unacknowledged messages return to the queue")
+	assert.Equal(t, 4, report.Inspected)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, 2, report.Invalid)
+	assert.Equal(t, 1, report.Unresolved)
+	assert.Equal(t, 1, report.Requeued)
+	assert.Equal(t, map[string]int{"rejected": 2, "maxlen": 1}, report.Reasons)
+	assert.Equal(t, 1, report.Errors["message names no schema"])
+	assert.Len(t, report.Messages, 4)
+
+	assert.Equal(t, []uint64{1}, queue.acked)
+	assert.Equal(t, []sent{{"t3.topics", "orders.created"}}, out, "requeued to the first death's topic")
+	if assert.Len(t, published, 1) {
+		assert.Equal(t, "orders.dlq", published[0].Headers[HeaderRequeuedFrom])
+		assert.Equal(t, `{"id":1}`, string(published[0].Body))
+	}
+	assert.True(t, report.Messages[0].Requeued)
+	assert.Equal(t, "rejected", report.Messages[0].Reason)
+
+	_, err = l.Inspect(context.Background(), DeadLetterRequest{Queue: "missing"})
+	assert.ErrorIs(t, err, ErrQueueNotFound)
+}
+
+func TestDeadLetterRequestValidate(t *testing.T) {
+	assert.NoError(t, DeadLetterRequest{Queue: "orders.dlq"}.Validate())
+	assert.Error(t, DeadLetterRequest{}.Validate())
+	assert.Error(t, DeadLetterRequest{Queue: "orders.dlq", Max: -1}.Validate())
+	assert.Error(t, DeadLetterRequest{Queue: "orders.dlq", Max: MaxDeadLetterMessages + 1}.Validate())
+}
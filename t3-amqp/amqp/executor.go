@@ -0,0 +1,293 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"sync"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+	"time"
+)
+
+// DefaultDrainTimeout bounds how long an executor waits for published messages to come
+// back once publishing finished
+const DefaultDrainTimeout = broker.DefaultDrainTimeout
+
+// Executor runs scenario instances against the broker. It binds the instance's temporary
+// queue to the scenario topic, publishes generated messages at the scenario rate and
+// consumes them back, with one consumer or the competing consumers of the scenario's
+// group, validating every message against the schema. The temporary queue
+// is declared and deleted by the engine, see scenario.Engine.WithCleanup.
+type Executor struct {
+	conn      *Conn
+	publisher *Publisher
+	drain     time.Duration
+	// chaos opens the dedicated connection scenarios with chaos options publish on
+	chaos func() (sendFunc, func(), error)
+}
+
+// NewExecutor creates an executor publishing with publisher and consuming on conn
+func NewExecutor(conn *Conn, publisher *Publisher) *Executor {
+	return &Executor{
+		conn: conn, publisher: publisher, drain: DefaultDrainTimeout,
+		chaos: func() (sendFunc, func(), error) { return dialSender(conn) },
+	}
+}
+
+// Execute runs inst. Valid scenarios fail for every message that does not validate,
+// invalid ones for every message the schema accepts, and all for messages that were
+// published but not consumed back before the drain timeout, the only failures of
+// latency scenarios. Chaos options drop, duplicate and reorder published messages and
+// delay the consumer's acknowledgements, duplicates are only counted once.
+func (e *Executor) Execute(ctx context.Context, inst scenario.Instance) (scenario.Result, error) {
+	s := inst.Scenario
+	var result scenario.Result
+
+	schema, err := e.publisher.schema(s.Schema)
+	if err != nil {
+		return result, err
+	}
+	if _, err := validate.DefaultCache.Validator(schema); err != nil {
+		return result, fmt.Errorf("error compiling schema %s/%s/%s: %w", schema.Name, schema.Type, schema.Version, err)
+	}
+	next, err := broker.ScenarioGenerator(schema, s, time.Now().UnixNano())
+	if err != nil {
+		return result, err
+	}
+
+	result.Assertions = scenario.CheckRoutingAssertions(ctx, NewProber(e.conn, 0), s)
+
+	ch, err := e.conn.Channel()
+	if err != nil {
+		return result, err
+	}
+	// Latency probes may come back on the reply topic of a mirror instead. It reaches the
+	// queue through the instance's exchange, when it has one, so deleting the exchange
+	// also removes its binding to the shared exchange.
+	reply := s.ReplyTopic()
+	source, key := e.publisher.exchange, reply
+	if inst.TempExchange != "" {
+		err = ch.ExchangeBind(inst.TempExchange, reply, e.publisher.exchange, false, nil)
+		source, key = inst.TempExchange, ""
+	}
+	if err == nil {
+		err = ch.QueueBind(inst.TempQueue, key, source, false, nil)
+	}
+	ch.Close()
+	if err != nil {
+		return result, fmt.Errorf("error binding %s to %s: %w", inst.TempQueue, reply, err)
+	}
+
+	send := sendFunc(e.publisher.send)
+	var chaos *chaosSender
+	var ackDelay time.Duration
+	if s.Chaos != nil {
+		if chaos, err = newChaosSender(*s.Chaos, e.chaos); err != nil {
+			return result, fmt.Errorf("error connecting the chaos publisher: %w", err)
+		}
+		defer chaos.Close()
+		send, ackDelay = chaos.Send, s.Chaos.AckDelay
+	}
+
+	strategy := scenario.AckStrategy{}
+	if s.Ack != nil {
+		strategy = *s.Ack
+	}
+	consumers := 1
+	if s.Group != nil {
+		consumers = s.Group.Consumers
+	}
+	// Consumed messages are validated against the schema they name, which is the
+	// scenario's unless another producer publishes to the topic
+	validators := broker.NewSchemaValidators(db.ForTenant(e.publisher.store, s.Schema.Tenant)).WithFallback(schema)
+	tally := newScenarioTally(inst, validators, e.publisher.headers)
+	consumeCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	// The consumers of a group share the tally and keep their own stats
+	var mu sync.Mutex
+	stats := make([]scenario.ConsumerStats, consumers)
+	errs := make([]error, consumers)
+	var wg sync.WaitGroup
+	for i := range stats {
+		consumerStrategy := strategy
+		if s.Group != nil {
+			consumerStrategy = s.Group.Strategy(i, strategy)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ackStats, err := consume(
+				consumeCtx, e.conn, inst.TempQueue, consumerStrategy, ackDelay, 0, func(d amqp091.Delivery) {
+					if d.Redelivered {
+						stats[i].Redelivered++
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					if tally.record(d) == s.MessageCount {
+						stop()
+					}
+				},
+			)
+			stats[i].Consumer, stats[i].AckStats = i, ackStats
+			// Consumers are stopped once every message is back or the drain timed out
+			if consumeCtx.Err() == nil {
+				errs[i] = err
+			}
+		}()
+	}
+	consumed := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(consumed)
+	}()
+
+	sent, publishErr := e.publish(ctx, inst, schema, next, send)
+	if chaos != nil {
+		if publishErr == nil {
+			publishErr = chaos.Flush(ctx)
+		}
+		result.Chaos = &chaos.stats
+	}
+	if publishErr != nil {
+		stop()
+	}
+	timer := time.NewTimer(e.drain)
+	defer timer.Stop()
+	select {
+	case <-consumed:
+	case <-timer.C:
+		stop()
+		<-consumed
+	}
+	err = errors.Join(errs...)
+
+	tally.fill(&result, sent)
+	if s.Group != nil {
+		result.Consumers = stats
+	} else {
+		result.Ack = &stats[0].AckStats
+	}
+	if publishErr != nil {
+		return result, publishErr
+	}
+	return result, err
+}
+
+// publish sends the messages of inst with send at the scenario rate and returns how many
+// the broker confirmed
+func (e *Executor) publish(
+	ctx context.Context, inst scenario.Instance, schema db.Schema, next broker.Generator, send sendFunc,
+) (int, error) {
+	s := inst.Scenario
+	var tick <-chan time.Time
+	if s.Rate > 0 {
+		if interval := time.Second / time.Duration(s.Rate); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+	}
+
+	for i := 0; i < s.MessageCount; i++ {
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return i, ctx.Err()
+			}
+		}
+
+		data, violation, err := next()
+		if err != nil {
+			return i, fmt.Errorf("error generating message %d: %w", i, err)
+		}
+		msg := e.publisher.message(ctx, schema, data)
+		msg.MessageId = fmt.Sprintf("%s-%d", inst.TempQueue, i)
+		msg.Headers[HeaderInstance] = inst.TempQueue
+		msg.Headers[HeaderPublished] = time.Now().UnixNano()
+		if violation != nil {
+			msg.Headers[HeaderViolation] = violation.String()
+		}
+		if err := send(ctx, e.publisher.exchange, s.Topic, msg); err != nil {
+			metrics.AMQPPublished.WithLabelValues("failed").Inc()
+			return i, fmt.Errorf("error publishing message %d: %w", i, err)
+		}
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+	}
+	return s.MessageCount, nil
+}
+
+// scenarioTally counts the messages of one instance consumed from its temporary queue.
+// Other messages published to the topic in the meantime are ignored.
+type scenarioTally struct {
+	instance   string
+	s          scenario.Scenario
+	validators *broker.SchemaValidators
+	headers    broker.Headers
+
+	received   int
+	valid      int
+	seen       map[string]bool
+	deliveries []scenario.Delivery
+	latencies  []time.Duration
+}
+
+func newScenarioTally(
+	inst scenario.Instance, validators *broker.SchemaValidators, headers broker.Headers,
+) *scenarioTally {
+	return &scenarioTally{
+		instance: inst.TempQueue, s: inst.Scenario, validators: validators, headers: headers, seen: map[string]bool{},
+	}
+}
+
+// record counts d if it belongs to the instance and returns how many messages were
+// received. Messages a consumer requeued are only counted the first time they arrive.
+func (t *scenarioTally) record(d amqp091.Delivery) int {
+	if instance, _ := d.Headers[HeaderInstance].(string); instance != t.instance {
+		return t.received
+	}
+	t.deliveries = append(t.deliveries, TraceDelivery(t.instance, d))
+	if d.MessageId != "" {
+		if t.seen[d.MessageId] {
+			return t.received
+		}
+		t.seen[d.MessageId] = true
+	}
+	t.received++
+	if published, ok := d.Headers[HeaderPublished].(int64); ok {
+		latency := time.Since(time.Unix(0, published))
+		t.latencies = append(t.latencies, latency)
+		if t.s.Mode == scenario.ModeLatency {
+			metrics.ProbeLatency.WithLabelValues(t.s.Topic).Observe(latency.Seconds())
+		}
+	}
+	if _, err := validateStamped(t.validators, t.headers, d); err == nil {
+		t.valid++
+	}
+	return t.received
+}
+
+// fill sets the message counts, latency and redelivery assertions of result once sent
+// messages were published
+func (t *scenarioTally) fill(result *scenario.Result, sent int) {
+	result.MessagesSent = sent
+	result.MessagesValid = t.valid
+	result.Redelivered = scenario.CountRedelivered(t.deliveries)
+	result.Latency = scenario.NewLatency(t.latencies)
+	result.Failures = max(sent-t.received, 0)
+	switch t.s.Mode {
+	case scenario.ModeInvalid:
+		result.Failures += t.valid
+	case scenario.ModeLatency:
+		metrics.ProbesLost.WithLabelValues(t.s.Topic).Add(float64(result.Failures))
+	default:
+		result.Failures += t.received - t.valid
+	}
+	result.Assertions = append(result.Assertions, scenario.CheckRedeliveryAssertions(t.s, t.deliveries)...)
+}
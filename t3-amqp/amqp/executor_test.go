@@ -0,0 +1,151 @@
+package amqp
+
+import (
+	"context"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorPublishesAtRate(t *testing.T) {
+	p, out := testPublisher(t)
+	e := &Executor{publisher: p}
+	schemas, err := p.store.Filter(db.QueryArgs{Name: "orders"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	inst := scenario.Instance{
+		TempQueue: "t3.run1.0",
+		Scenario: scenario.Scenario{
+			Topic: "orders.created", MessageCount: 5, Rate: 50, Mode: scenario.ModeInvalid,
+			Schema: scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"},
+		},
+	}
+	next, err := broker.ScenarioGenerator(schemas[0], inst.Scenario, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	started := time.Now()
+	sent, err := e.publish(context.Background(), inst, schemas[0], next, p.send)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, sent)
+	assert.GreaterOrEqual(t, time.Since(started), 80*time.Millisecond, "5 messages at 50/s take at least 4 intervals")
+	if assert.Len(t, *out, 5) {
+		msg := (*out)[0].msg
+		assert.Equal(t, "orders.created", (*out)[0].key)
+		assert.Equal(t, "t3.run1.0", msg.Headers[HeaderInstance])
+		assert.Equal(t, "t3.run1.0-0", msg.MessageId)
+		assert.NotEmpty(t, msg.Headers[HeaderViolation])
+		assert.IsType(t, int64(0), msg.Headers[HeaderPublished])
+	}
+}
+
+func TestScenarioTallyCountsFailures(t *testing.T) {
+	fallback := db.Schema{Type: "json", SchemaData: `{"type":"object","required":["id"]}`}
+	ours := amqp091.Table{HeaderInstance: "t3.run1.0", HeaderPublished: time.Now().Add(-time.Second).UnixNano()}
+	deliveries := []amqp091.Delivery{
+		{Headers: ours, Body: []byte(`{"id":1}`)},
+		{Headers: ours, Body: []byte(`{}`), Redelivered: true},
+		{Headers: amqp091.Table{HeaderInstance: "t3.run2.0"}, Body: []byte(`{}`)},
+		{Body: []byte(`{"id":2}`)},
+	}
+
+	for _, mode := range []string{scenario.ModeValid, scenario.ModeInvalid} {
+		inst := scenario.Instance{TempQueue: "t3.run1.0", Scenario: scenario.Scenario{Mode: mode, MessageCount: 3}}
+		validators := broker.NewSchemaValidators(db.NewMemoryStore()).WithFallback(fallback)
+		tally := newScenarioTally(inst, validators, broker.DefaultHeaders)
+		for _, d := range deliveries {
+			tally.record(d)
+		}
+		assert.Equal(t, 2, tally.received, "messages of other instances are ignored")
+
+		var result scenario.Result
+		tally.fill(&result, 3)
+		assert.Equal(t, 3, result.MessagesSent)
+		assert.Equal(t, 1, result.MessagesValid)
+		assert.Equal(t, 1, result.Redelivered)
+		if assert.NotNil(t, result.Latency) {
+			assert.Equal(t, 2, result.Latency.Samples)
+			assert.GreaterOrEqual(t, result.Latency.P50, time.Second)
+		}
+		assert.Equal(t, 2, result.Failures, "%s: one missing and one unexpected message", mode)
+	}
+}
+
+func TestScenarioTallyCountsRequeuedMessagesOnce(t *testing.T) {
+	fallback := db.Schema{Type: "json", SchemaData: `true`}
+	ours := amqp091.Table{HeaderInstance: "t3.run1.0"}
+	inst := scenario.Instance{TempQueue: "t3.run1.0", Scenario: scenario.Scenario{MessageCount: 2}}
+	validators := broker.NewSchemaValidators(db.NewMemoryStore()).WithFallback(fallback)
+	tally := newScenarioTally(inst, validators, broker.DefaultHeaders)
+	tally.record(amqp091.Delivery{MessageId: "t3.run1.0-0", Headers: ours, Body: []byte(`{}`)})
+	tally.record(amqp091.Delivery{MessageId: "t3.run1.0-0", Headers: ours, Body: []byte(`{}`), Redelivered: true})
+	assert.Equal(t, 2, tally.record(amqp091.Delivery{MessageId: "t3.run1.0-1", Headers: ours, Body: []byte(`{}`)}))
+
+	var result scenario.Result
+	tally.fill(&result, 2)
+	assert.Equal(t, 2, result.MessagesValid)
+	assert.Equal(t, 1, result.Redelivered)
+	assert.Zero(t, result.Failures)
+}
+
+func TestScenarioTallyLatencyCountsLostProbes(t *testing.T) {
+	lost := metrics.ProbesLost.WithLabelValues("probes")
+	before := testutil.ToFloat64(lost)
+
+	// Probes only fail when they do not come back, whatever the schema says of them
+	fallback := db.Schema{Type: "json", SchemaData: `false`}
+	probe := amqp091.Table{HeaderInstance: "t3.run1.0", HeaderPublished: time.Now().UnixNano()}
+	inst := scenario.Instance{
+		TempQueue: "t3.run1.0", Scenario: scenario.Scenario{Topic: "probes", Mode: scenario.ModeLatency},
+	}
+	validators := broker.NewSchemaValidators(db.NewMemoryStore()).WithFallback(fallback)
+	tally := newScenarioTally(inst, validators, broker.DefaultHeaders)
+	tally.record(amqp091.Delivery{Headers: probe, Body: []byte(`{}`)})
+	tally.record(amqp091.Delivery{Headers: probe, Body: []byte(`{}`)})
+
+	var result scenario.Result
+	tally.fill(&result, 3)
+	assert.Equal(t, 1, result.Failures)
+	assert.Equal(t, before+1, testutil.ToFloat64(lost))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.ProbeLatency), "latency is observed per topic")
+}
+
+func TestScenarioTallyResolvesSchemaFromHeaders(t *testing.T) {
+	p, _ := testPublisher(t)
+	p.WithHeaders(broker.Headers{SchemaName: "schema-name", SchemaID: "schema-id", ContentType: "content-type"})
+	schemas, err := p.store.Filter(db.QueryArgs{Name: "orders"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg := p.message(context.Background(), schemas[0], []byte(`{"id":1}`))
+	assert.Equal(t, "orders", msg.Headers["schema-name"])
+	assert.Equal(t, "1.0.0", msg.Headers[HeaderSchemaVersion], "unset headers keep the default")
+	assert.Equal(t, "application/json", msg.Headers["content-type"])
+	assert.NotContains(t, msg.Headers, HeaderSchemaName)
+
+	// The fallback would reject every message, the stamped schema accepts the valid one
+	fallback := db.Schema{Type: "json", SchemaData: `false`}
+	validators := broker.NewSchemaValidators(p.store).WithFallback(fallback)
+	inst := scenario.Instance{TempQueue: "t3.run1.0", Scenario: scenario.Scenario{MessageCount: 3}}
+	tally := newScenarioTally(inst, validators, p.headers)
+	stamped := amqp091.Table{HeaderInstance: "t3.run1.0", "schema-id": msg.Headers["schema-id"]}
+	idOnly := amqp091.Table{HeaderInstance: "t3.run1.0", "schema-id": int64(schemas[0].ID)}
+	for _, d := range []amqp091.Delivery{
+		{Headers: stamped, Body: []byte(`{"id":1}`)},
+		{Headers: idOnly, Body: []byte(`{"id":"x"}`)},
+		{Headers: idOnly, Body: []byte(`{"id":2}`)},
+	} {
+		tally.record(d)
+	}
+	assert.Equal(t, 2, tally.valid)
+}
@@ -0,0 +1,288 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"strconv"
+	"sync"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// LoadTester runs load tests against the broker. Every publisher keeps its own confirm
+// channel to measure how long the broker takes to confirm a message, and a loopback
+// consumer reads the messages back from a temporary queue bound to the topic to measure
+// their end to end latency.
+type LoadTester struct {
+	conn      *Conn
+	publisher *Publisher
+	resources scenario.Resources
+	journal   *scenario.Journal
+	drain     time.Duration
+	// sender opens the confirm channel of one publisher
+	sender func() (sendFunc, func(), error)
+}
+
+// NewLoadTester creates a load tester publishing to the exchange of publisher and
+// consuming on conn. Temporary queues are tracked in journal until they are deleted, a
+// nil journal is kept in memory only.
+func NewLoadTester(conn *Conn, publisher *Publisher, journal *scenario.Journal) *LoadTester {
+	if journal == nil {
+		// Without a path the journal is never read or written
+		journal, _ = scenario.OpenJournal("")
+	}
+	return &LoadTester{
+		conn:      conn,
+		publisher: publisher,
+		resources: NewTempResources(conn),
+		journal:   journal,
+		drain:     DefaultDrainTimeout,
+		sender:    func() (sendFunc, func(), error) { return channelSender(conn) },
+	}
+}
+
+// Run executes test and reports the result of every publisher. Nacked messages fail the
+// run, as do confirmed messages that were not consumed back before the drain timeout.
+// Errors outside a single publisher, such as a schema that cannot be found, are
+// reported as one more result.
+func (l *LoadTester) Run(ctx context.Context, runID string, test scenario.LoadTest) scenario.Report {
+	report := scenario.Report{RunID: runID, Started: time.Now().UTC(), Instances: max(test.Concurrency, 1)}
+	queue := scenario.TempQueueName(runID, 0)
+
+	var runErr error
+	err := scenario.WithTempQueue(ctx, l.resources, l.journal, queue, func() {
+		runErr = l.run(ctx, runID, queue, test, &report)
+	})
+	if err = errors.Join(err, runErr); err != nil {
+		report.Results = append(report.Results, scenario.Result{
+			Instance: scenario.Instance{RunID: runID, Index: len(report.Results), TempQueue: queue},
+			Started:  report.Started,
+			Error:    err.Error(),
+		})
+	}
+
+	report.Duration = time.Since(report.Started)
+	for _, r := range report.Results {
+		if r.Passed() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// run binds queue to the topic of test, publishes and consumes the messages back and fills
+// in report
+func (l *LoadTester) run(
+	ctx context.Context, runID, queue string, test scenario.LoadTest, report *scenario.Report,
+) error {
+	s := test.Scenario()
+	schema, err := l.publisher.schema(s.Schema)
+	if err != nil {
+		return err
+	}
+	next, err := broker.ScenarioGenerator(schema, s, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+
+	ch, err := l.conn.Channel()
+	if err != nil {
+		return err
+	}
+	err = ch.QueueBind(queue, s.Topic, l.publisher.exchange, false, nil)
+	ch.Close()
+	if err != nil {
+		return fmt.Errorf("error binding %s to %s: %w", queue, s.Topic, err)
+	}
+
+	tally := newLoadTally(queue)
+	consumeCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	consumed := make(chan error, 1)
+	go func() {
+		auto := scenario.AckStrategy{Mode: scenario.AckAuto}
+		_, err := Consume(consumeCtx, l.conn, queue, auto, 0, func(d amqp091.Delivery) {
+			if tally.record(d) {
+				stop()
+			}
+		})
+		consumed <- err
+	}()
+
+	publishCtx, cancel := context.WithTimeout(ctx, test.Duration)
+	defer cancel()
+	var tokens <-chan time.Time
+	if s.Rate > 0 {
+		if interval := time.Second / time.Duration(s.Rate); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tokens = ticker.C
+		}
+	}
+
+	params := map[string]string{
+		"rate":        strconv.Itoa(test.Rate),
+		"concurrency": strconv.Itoa(report.Instances),
+		"duration":    test.Duration.String(),
+	}
+	results := make([]scenario.Result, report.Instances)
+	confirms := make([][]time.Duration, report.Instances)
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inst := scenario.Instance{RunID: runID, Index: i, Params: params, TempQueue: queue}
+			results[i], confirms[i] = l.publish(ctx, publishCtx, inst, s.Topic, schema, next, tokens)
+		}()
+	}
+	wg.Wait()
+	publishing := time.Since(started)
+
+	var all []time.Duration
+	for i, r := range results {
+		report.MessagesSent += r.MessagesSent
+		report.Failures += r.Failures
+		all = append(all, confirms[i]...)
+	}
+	report.Results = results
+	report.ConfirmLatency = scenario.NewLatency(all)
+	if publishing > 0 {
+		report.Throughput = float64(report.MessagesSent) / publishing.Seconds()
+	}
+
+	if tally.expect(report.MessagesSent) {
+		stop()
+	}
+	timer := time.NewTimer(l.drain)
+	defer timer.Stop()
+	select {
+	case err = <-consumed:
+	case <-timer.C:
+		stop()
+		err = <-consumed
+	}
+	if consumeCtx.Err() != nil && ctx.Err() == nil {
+		// The consumer is stopped once every message is back or the drain timed out
+		err = nil
+	}
+
+	received, latencies := tally.result()
+	report.Failures += max(report.MessagesSent-received, 0)
+	report.Latency = scenario.NewLatency(latencies)
+	return err
+}
+
+// publish sends messages for one publisher until publishCtx is done, taking a token from
+// tokens before each message when the test is rate limited. The result counts the
+// messages the broker confirmed, which are returned with how long each confirm took.
+func (l *LoadTester) publish(
+	ctx, publishCtx context.Context, inst scenario.Instance, topic string, schema db.Schema, next broker.Generator,
+	tokens <-chan time.Time,
+) (scenario.Result, []time.Duration) {
+	result := scenario.Result{Instance: inst, Started: time.Now().UTC()}
+	var confirms []time.Duration
+	defer func() { result.Duration = time.Since(result.Started) }()
+
+	send, release, err := l.sender()
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	defer release()
+
+	for i := 0; ; i++ {
+		if tokens != nil {
+			select {
+			case <-tokens:
+			case <-publishCtx.Done():
+			}
+		}
+		if publishCtx.Err() != nil {
+			break
+		}
+
+		data, _, err := next()
+		if err != nil {
+			result.Error = fmt.Sprintf("error generating message %d: %v", i, err)
+			break
+		}
+		msg := l.publisher.message(publishCtx, schema, data)
+		msg.MessageId = fmt.Sprintf("%s-%d-%d", inst.TempQueue, inst.Index, i)
+		msg.Headers[HeaderInstance] = inst.TempQueue
+		published := time.Now()
+		msg.Headers[HeaderPublished] = published.UnixNano()
+
+		// A message in flight when the duration ends still waits for its confirm
+		err = send(ctx, l.publisher.exchange, topic, msg)
+		if errors.Is(err, ErrNacked) {
+			metrics.AMQPPublished.WithLabelValues("failed").Inc()
+			result.Failures++
+			continue
+		}
+		if err != nil {
+			metrics.AMQPPublished.WithLabelValues("failed").Inc()
+			result.Error = fmt.Sprintf("error publishing message %d: %v", i, err)
+			break
+		}
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+		result.MessagesSent++
+		confirms = append(confirms, time.Since(published))
+	}
+	result.ConfirmLatency = scenario.NewLatency(confirms)
+	return result, confirms
+}
+
+// loadTally counts the messages of a load test consumed back from its temporary queue
+// while the publishers are still running
+type loadTally struct {
+	queue string
+
+	mu        sync.Mutex
+	received  int
+	expected  int
+	latencies []time.Duration
+}
+
+func newLoadTally(queue string) *loadTally {
+	return &loadTally{queue: queue, expected: -1}
+}
+
+// record counts d if it belongs to the load test and reports whether every published
+// message is back
+func (t *loadTally) record(d amqp091.Delivery) bool {
+	if queue, _ := d.Headers[HeaderInstance].(string); queue != t.queue {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.received++
+	if published, ok := d.Headers[HeaderPublished].(int64); ok {
+		t.latencies = append(t.latencies, time.Since(time.Unix(0, published)))
+	}
+	return t.received == t.expected
+}
+
+// expect sets how many messages were published once publishing finished and reports
+// whether all of them are already back
+func (t *loadTally) expect(sent int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expected = sent
+	return t.received >= sent
+}
+
+// result returns how many messages were received and their end to end latencies
+func (t *loadTally) result() (int, []time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.received, t.latencies
+}
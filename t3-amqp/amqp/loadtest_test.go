@@ -0,0 +1,86 @@
+package amqp
+
+import (
+	"context"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTesterPublish(t *testing.T) {
+	p, _ := testPublisher(t)
+	schemas, err := p.store.Filter(db.QueryArgs{Name: "orders"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	test := scenario.LoadTest{
+		Topic: "orders.created", Schema: scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"},
+		Duration: time.Second,
+	}
+	next, err := broker.ScenarioGenerator(schemas[0], test.Scenario(), 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []amqp091.Publishing
+	released := false
+	l := &LoadTester{publisher: p, sender: func() (sendFunc, func(), error) {
+		send := func(_ context.Context, _, _ string, msg amqp091.Publishing) error {
+			out = append(out, msg)
+			if len(out)%3 == 0 {
+				return ErrNacked
+			}
+			return nil
+		}
+		return send, func() { released = true }, nil
+	}}
+
+	publishCtx, cancel := context.WithCancel(context.Background())
+	tokens := make(chan time.Time)
+	go func() {
+		for i := 0; i < 6; i++ {
+			tokens <- time.Now()
+		}
+		cancel()
+	}()
+	inst := scenario.Instance{RunID: "run1", Index: 2, TempQueue: "t3.run1.0"}
+	result, confirms := l.publish(context.Background(), publishCtx, inst, test.Topic, schemas[0], next, tokens)
+
+	assert.Empty(t, result.Error)
+	assert.Len(t, out, 6, "one message per token")
+	assert.Equal(t, 4, result.MessagesSent)
+	assert.Equal(t, 2, result.Failures, "nacked messages fail")
+	assert.Len(t, confirms, 4)
+	if assert.NotNil(t, result.ConfirmLatency) {
+		assert.Equal(t, 4, result.ConfirmLatency.Samples)
+	}
+	assert.True(t, released)
+	if assert.NotEmpty(t, out) {
+		assert.Equal(t, "t3.run1.0-2-0", out[0].MessageId)
+		assert.Equal(t, "t3.run1.0", out[0].Headers[HeaderInstance])
+		assert.IsType(t, int64(0), out[0].Headers[HeaderPublished])
+	}
+}
+
+func TestLoadTally(t *testing.T) {
+	tally := newLoadTally("t3.run1.0")
+	ours := amqp091.Delivery{
+		Headers: amqp091.Table{HeaderInstance: "t3.run1.0", HeaderPublished: time.Now().Add(-time.Second).UnixNano()},
+	}
+	assert.False(t, tally.record(ours), "nothing is expected while publishing")
+	assert.False(t, tally.record(amqp091.Delivery{Headers: amqp091.Table{HeaderInstance: "t3.run2.0"}}))
+	assert.False(t, tally.expect(2))
+	assert.True(t, tally.record(ours), "the last expected message completes the test")
+
+	received, latencies := tally.result()
+	assert.Equal(t, 2, received)
+	if assert.Len(t, latencies, 2) {
+		assert.GreaterOrEqual(t, latencies[0], time.Second)
+	}
+	assert.True(t, newLoadTally("q").expect(0), "a test that sent nothing has nothing to wait for")
+}
@@ -0,0 +1,187 @@
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"t3-amqp/redact"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// DefaultFlowPollInterval is how often the management API is polled during a run
+const DefaultFlowPollInterval = 2 * time.Second
+
+// ManagementClient talks to the RabbitMQ management HTTP API
+type ManagementClient struct {
+	baseURL  string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewManagementClient creates a client for the management API at baseURL
+func NewManagementClient(baseURL, user, password string) *ManagementClient {
+	return &ManagementClient{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NodeAlarms is the resource alarm state of one broker node
+type NodeAlarms struct {
+	Name          string `json:"name"`
+	MemAlarm      bool   `json:"mem_alarm"`
+	DiskFreeAlarm bool   `json:"disk_free_alarm"`
+}
+
+// ConnectionState is the flow state of one client connection
+type ConnectionState struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+func (m *ManagementClient) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(m.user, m.password)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return redact.Error(fmt.Errorf("error calling management api: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("management api %s returned %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Alarms returns the memory and disk alarm state of every node
+func (m *ManagementClient) Alarms(ctx context.Context) ([]NodeAlarms, error) {
+	var nodes []NodeAlarms
+	err := m.get(ctx, "/api/nodes?columns=name,mem_alarm,disk_free_alarm", &nodes)
+	return nodes, err
+}
+
+// ConnectionStates returns the state (running, flow, blocking, blocked) of every connection
+func (m *ManagementClient) ConnectionStates(ctx context.Context) ([]ConnectionState, error) {
+	var conns []ConnectionState
+	err := m.get(ctx, "/api/connections?columns="+url.QueryEscape("name,state"), &conns)
+	return conns, err
+}
+
+// throttleReasons reduces one poll of the management API to the reasons publishers are
+// being held back
+func throttleReasons(nodes []NodeAlarms, conns []ConnectionState) []string {
+	reasons := map[string]bool{}
+	for _, n := range nodes {
+		if n.MemAlarm {
+			reasons["memory alarm on "+n.Name] = true
+		}
+		if n.DiskFreeAlarm {
+			reasons["disk alarm on "+n.Name] = true
+		}
+	}
+	for _, c := range conns {
+		switch c.State {
+		case "flow", "blocking", "blocked":
+			reasons["connection "+c.State] = true
+		}
+	}
+
+	out := make([]string, 0, len(reasons))
+	for r := range reasons {
+		out = append(out, r)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// FlowMonitor polls the management API while a run is in progress and records the
+// intervals during which the broker throttled publishers
+type FlowMonitor struct {
+	mgmt     *ManagementClient
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	open      map[string]time.Time
+	intervals []scenario.ThrottleInterval
+}
+
+// NewFlowMonitor creates a monitor polling every interval
+func NewFlowMonitor(mgmt *ManagementClient, interval time.Duration) *FlowMonitor {
+	if interval <= 0 {
+		interval = DefaultFlowPollInterval
+	}
+	return &FlowMonitor{mgmt: mgmt, interval: interval, now: time.Now, open: map[string]time.Time{}}
+}
+
+// Run polls until ctx is done, then closes any interval still open
+func (f *FlowMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		f.poll(ctx)
+		select {
+		case <-ctx.Done():
+			f.observe(nil)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *FlowMonitor) poll(ctx context.Context) {
+	nodes, err := f.mgmt.Alarms(ctx)
+	if err != nil {
+		return
+	}
+	conns, err := f.mgmt.ConnectionStates(ctx)
+	if err != nil {
+		return
+	}
+	f.observe(throttleReasons(nodes, conns))
+}
+
+// observe opens intervals for new reasons and closes those that cleared
+func (f *FlowMonitor) observe(reasons []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now().UTC()
+	active := map[string]bool{}
+	for _, r := range reasons {
+		active[r] = true
+		if _, ok := f.open[r]; !ok {
+			f.open[r] = now
+		}
+	}
+	for r, start := range f.open {
+		if !active[r] {
+			f.intervals = append(f.intervals, scenario.ThrottleInterval{Start: start, End: now, Reason: r})
+			delete(f.open, r)
+		}
+	}
+}
+
+// Intervals returns the throttled intervals observed so far, ordered by start time
+func (f *FlowMonitor) Intervals() []scenario.ThrottleInterval {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := append([]scenario.ThrottleInterval(nil), f.intervals...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
@@ -0,0 +1,56 @@
+package amqp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagementClientReadsAlarmsAndStates(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "guest", user)
+			assert.Equal(t, "secret", pass)
+			switch r.URL.Path {
+			case "/api/nodes":
+				_, _ = w.Write([]byte(`[{"name":"rabbit@a","mem_alarm":true,"disk_free_alarm":false}]`))
+			case "/api/connections":
+				_, _ = w.Write([]byte(`[{"name":"c1","state":"flow"},{"name":"c2","state":"running"}]`))
+			}
+		}),
+	)
+	defer server.Close()
+
+	mgmt := NewManagementClient(server.URL, "guest", "secret")
+	nodes, err := mgmt.Alarms(context.Background())
+	assert.NoError(t, err)
+	conns, err := mgmt.ConnectionStates(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"connection flow", "memory alarm on rabbit@a"}, throttleReasons(nodes, conns))
+}
+
+func TestFlowMonitorRecordsIntervals(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	f := NewFlowMonitor(nil, time.Second)
+	f.now = func() time.Time { return now }
+
+	f.observe([]string{"connection flow"})
+	now = now.Add(5 * time.Second)
+	f.observe([]string{"connection flow", "disk alarm on rabbit@a"})
+	now = now.Add(5 * time.Second)
+	f.observe(nil)
+
+	intervals := f.Intervals()
+	assert.Len(t, intervals, 2)
+	assert.Equal(t, "connection flow", intervals[0].Reason)
+	assert.Equal(t, start, intervals[0].Start)
+	assert.Equal(t, start.Add(10*time.Second), intervals[0].End)
+	assert.Equal(t, "disk alarm on rabbit@a", intervals[1].Reason)
+}
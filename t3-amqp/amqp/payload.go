@@ -0,0 +1,49 @@
+package amqp
+
+import (
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+// Publishing wraps a payload for schemaType with the matching content type
+func Publishing(schemaType string, body []byte) amqp091.Publishing {
+	return amqp091.Publishing{ContentType: validate.ContentType(schemaType), Body: body}
+}
+
+// validateStamped validates d against the schema its headers name according to headers,
+// returning the schema when it could be resolved
+func validateStamped(
+	validators *broker.SchemaValidators, headers broker.Headers, d amqp091.Delivery,
+) (*db.Schema, error) {
+	return validateStamp(validators, headers, headers.Read(deliveryHeader(d)), d)
+}
+
+// validateStamp validates d against the schema stamp names. The content type of d must
+// be the one of the schema type, messages without one are validated as is.
+func validateStamp(
+	validators *broker.SchemaValidators, headers broker.Headers, stamp broker.Stamp, d amqp091.Delivery,
+) (*db.Schema, error) {
+	schema, validator, err := validators.For(stamp)
+	if err != nil {
+		return schema, err
+	}
+	contentType := headers.ContentTypeOf(d.ContentType, deliveryHeader(d))
+	return schema, broker.ValidateContent(validator, schema.Type, contentType, d.Body)
+}
+
+// deliveryHeader looks the headers of d up as text, the way other brokers carry them
+func deliveryHeader(d amqp091.Delivery) func(name string) string {
+	return func(name string) string {
+		switch v := d.Headers[name].(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package amqp
+
+import (
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStampedChecksContentType(t *testing.T) {
+	assert.Equal(t, "application/xml", Publishing("xsd", nil).ContentType)
+
+	fallback := db.Schema{
+		Name: "orders", Type: "xsd", Version: "1.0.0",
+		SchemaData: `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">` +
+			`<xs:element name="order" type="xs:positiveInteger"/></xs:schema>`,
+	}
+	validators := broker.NewSchemaValidators(db.NewMemoryStore()).WithFallback(fallback)
+	deliver := func(contentType, body string) error {
+		d := amqp091.Delivery{ContentType: contentType, Body: []byte(body)}
+		_, err := validateStamped(validators, broker.DefaultHeaders, d)
+		return err
+	}
+
+	assert.NoError(t, deliver("text/xml; charset=utf-8", "<order>1</order>"))
+	assert.NoError(t, deliver("", "<order>1</order>"))
+	assert.ErrorContains(t, deliver("application/json", "<order>1</order>"), "expected application/xml")
+	assert.Error(t, deliver("application/xml", "<order>none</order>"))
+}
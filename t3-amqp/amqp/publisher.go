@@ -0,0 +1,213 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"t3-amqp/tracing"
+	"t3-amqp/validate"
+	"time"
+)
+
+// Headers carrying the schema a message was published under, see the broker package
+const (
+	HeaderSchemaName    = broker.HeaderSchemaName
+	HeaderSchemaType    = broker.HeaderSchemaType
+	HeaderSchemaVersion = broker.HeaderSchemaVersion
+	HeaderSchemaID      = broker.HeaderSchemaID
+	HeaderViolation     = broker.HeaderViolation
+	HeaderInstance      = broker.HeaderInstance
+	HeaderPublished     = broker.HeaderPublished
+)
+
+// system names RabbitMQ in the messaging attributes of spans
+const system = "rabbitmq"
+
+var (
+	ErrInvalidPayload = broker.ErrInvalidPayload
+	ErrSchemaRetired  = broker.ErrSchemaRetired
+	ErrNacked         = errors.New("broker nacked message")
+)
+
+// Publisher sends test messages to topics after validating them against the registry
+type Publisher struct {
+	store    db.SchemaStore
+	exchange string
+	headers  broker.Headers
+	send     func(ctx context.Context, exchange, key string, msg amqp091.Publishing) error
+}
+
+// NewPublisher creates a publisher that sends to exchange on conn with publisher confirms
+func NewPublisher(conn *Conn, store db.SchemaStore, exchange string) *Publisher {
+	if exchange == "" {
+		exchange = DefaultExchange
+	}
+	return &Publisher{store: store, exchange: exchange, headers: broker.DefaultHeaders, send: confirmedSender(conn)}
+}
+
+// WithHeaders stamps published messages according to headers, scenarios run by an
+// executor on the publisher also resolve the schema of consumed messages from them
+func (p *Publisher) WithHeaders(headers broker.Headers) *Publisher {
+	p.headers = headers.WithDefaults()
+	return p
+}
+
+// Publish validates payload against the schema named by ref and publishes it to the
+// exchange with topic as routing key. The schema is recorded in the message headers so
+// consumers can validate what they receive.
+func (p *Publisher) Publish(ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte) error {
+	ctx, span := tracing.StartPublish(ctx, system, topic)
+	defer span.End()
+
+	err := p.publish(ctx, topic, ref, payload)
+	tracing.RecordError(span, err)
+	switch {
+	case err == nil:
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+	case errors.Is(err, ErrInvalidPayload), errors.Is(err, ErrSchemaRetired), errors.Is(err, db.ErrSchemaNotFound):
+		metrics.AMQPPublished.WithLabelValues("rejected").Inc()
+	default:
+		metrics.AMQPPublished.WithLabelValues("failed").Inc()
+	}
+	return err
+}
+
+// PublishInvalid publishes payload like Publish but without validating it, for messages
+// that deliberately break the schema. The rule broken is recorded in the x-t3-violation
+// header so consumers and verifications can tell them from real failures.
+func (p *Publisher) PublishInvalid(
+	ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte, violation string,
+) error {
+	ctx, span := tracing.StartPublish(ctx, system, topic)
+	defer span.End()
+
+	schema, err := p.schema(ref)
+	if err == nil {
+		msg := p.message(ctx, schema, payload)
+		msg.Headers[HeaderViolation] = violation
+		err = p.send(ctx, p.exchange, topic, msg)
+	}
+	tracing.RecordError(span, err)
+	switch {
+	case err == nil:
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+	case errors.Is(err, ErrSchemaRetired), errors.Is(err, db.ErrSchemaNotFound):
+		metrics.AMQPPublished.WithLabelValues("rejected").Inc()
+	default:
+		metrics.AMQPPublished.WithLabelValues("failed").Inc()
+	}
+	return err
+}
+
+func (p *Publisher) publish(ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte) error {
+	schema, err := p.schema(ref)
+	if err != nil {
+		return err
+	}
+
+	validator, err := validate.DefaultCache.Validator(schema)
+	if err != nil {
+		return fmt.Errorf("error compiling schema %s/%s/%s: %w", ref.Name, ref.Type, ref.Version, err)
+	}
+	if err := validator.Validate(payload); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return p.send(ctx, p.exchange, topic, p.message(ctx, schema, payload))
+}
+
+// schema looks up the active schema named by ref
+func (p *Publisher) schema(ref scenario.SchemaRef) (db.Schema, error) {
+	return broker.ActiveSchema(p.store, ref)
+}
+
+// message builds the persistent message for payload, naming schema and the trace of
+// ctx in its headers
+func (p *Publisher) message(ctx context.Context, schema db.Schema, payload []byte) amqp091.Publishing {
+	msg := Publishing(schema.Type, payload)
+	msg.DeliveryMode = amqp091.Persistent
+	msg.Timestamp = time.Now().UTC()
+	msg.Headers = amqp091.Table{}
+	for k, v := range p.headers.Stamp(schema, msg.ContentType) {
+		msg.Headers[k] = v
+	}
+	injectTrace(ctx, msg.Headers)
+	return msg
+}
+
+// injectTrace writes the trace context of ctx into AMQP headers
+func injectTrace(ctx context.Context, headers amqp091.Table) {
+	carrier := map[string]string{}
+	tracing.Inject(ctx, carrier)
+	for k, v := range carrier {
+		headers[k] = v
+	}
+}
+
+// confirmedSender publishes on a fresh channel and waits for the broker's confirm
+func confirmedSender(conn *Conn) func(context.Context, string, string, amqp091.Publishing) error {
+	return func(ctx context.Context, exchange, key string, msg amqp091.Publishing) error {
+		ch, err := conn.Channel()
+		if err != nil {
+			return err
+		}
+		defer ch.Close()
+
+		if err := ch.Confirm(false); err != nil {
+			return fmt.Errorf("error enabling publisher confirms: %w", err)
+		}
+		confirms := ch.NotifyPublish(make(chan amqp091.Confirmation, 1))
+
+		if err := ch.PublishWithContext(ctx, exchange, key, false, false, msg); err != nil {
+			return fmt.Errorf("error publishing message: %w", err)
+		}
+		return awaitConfirm(ctx, confirms)
+	}
+}
+
+// sendFunc publishes msg to exchange with routing key and waits for the broker's confirm
+type sendFunc func(ctx context.Context, exchange, key string, msg amqp091.Publishing) error
+
+// channelSender publishes on a single channel in confirm mode and waits for the broker's
+// confirm of each message. Unlike confirmedSender it is not safe for concurrent use, but
+// does not open a channel per message. release closes the channel.
+func channelSender(conn *Conn) (send sendFunc, release func(), err error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("error enabling publisher confirms: %w", err)
+	}
+	confirms := ch.NotifyPublish(make(chan amqp091.Confirmation, 1))
+
+	send = func(ctx context.Context, exchange, key string, msg amqp091.Publishing) error {
+		if err := ch.PublishWithContext(ctx, exchange, key, false, false, msg); err != nil {
+			return fmt.Errorf("error publishing message: %w", err)
+		}
+		return awaitConfirm(ctx, confirms)
+	}
+	return send, func() { ch.Close() }, nil
+}
+
+// awaitConfirm waits for the confirm of the message published last, ErrNacked when the
+// broker rejected it
+func awaitConfirm(ctx context.Context, confirms <-chan amqp091.Confirmation) error {
+	select {
+	case c, ok := <-confirms:
+		if !ok {
+			return fmt.Errorf("channel closed while waiting for publish confirm")
+		}
+		if !c.Ack {
+			return ErrNacked
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for publish confirm: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,106 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type sent struct {
+	exchange, key string
+	msg           amqp091.Publishing
+}
+
+func testPublisher(t *testing.T) (*Publisher, *[]sent) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{
+		Name: "orders", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`,
+	})
+	assert.NoError(t, err)
+
+	var out []sent
+	p := &Publisher{store: store, exchange: "t3.topics", send: func(_ context.Context, exchange, key string, msg amqp091.Publishing) error {
+		out = append(out, sent{exchange, key, msg})
+		return nil
+	}}
+	return p, &out
+}
+
+func TestPublishValidatesAgainstSchema(t *testing.T) {
+	p, out := testPublisher(t)
+	ref := scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"}
+
+	assert.NoError(t, p.Publish(context.Background(), "orders.created", ref, []byte(`{"id":1}`)))
+	assert.Len(t, *out, 1)
+	assert.Equal(t, "t3.topics", (*out)[0].exchange)
+	assert.Equal(t, "orders.created", (*out)[0].key)
+	assert.Equal(t, "application/json", (*out)[0].msg.ContentType)
+	assert.Equal(t, "1.0.0", (*out)[0].msg.Headers[HeaderSchemaVersion])
+
+	err := p.Publish(context.Background(), "orders.created", ref, []byte(`{"id":"x"}`))
+	assert.True(t, errors.Is(err, ErrInvalidPayload))
+
+	ref.Version = "2.0.0"
+	err = p.Publish(context.Background(), "orders.created", ref, []byte(`{"id":1}`))
+	assert.True(t, errors.Is(err, db.ErrSchemaNotFound))
+	assert.Len(t, *out, 1)
+}
+
+func TestPublishInvalidSkipsValidation(t *testing.T) {
+	p, out := testPublisher(t)
+	ref := scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"}
+
+	err := p.PublishInvalid(context.Background(), "orders.created", ref, []byte(`{"id":"x"}`), "type at \"/id\"")
+	assert.NoError(t, err)
+	if assert.Len(t, *out, 1) {
+		assert.Equal(t, `{"id":"x"}`, string((*out)[0].msg.Body))
+		assert.Equal(t, "type at \"/id\"", (*out)[0].msg.Headers[HeaderViolation])
+		assert.Equal(t, "orders", (*out)[0].msg.Headers[HeaderSchemaName])
+	}
+
+	ref.Version = "2.0.0"
+	err = p.PublishInvalid(context.Background(), "orders.created", ref, []byte(`{}`), "required")
+	assert.True(t, errors.Is(err, db.ErrSchemaNotFound))
+}
+
+type recordingDeclarer struct {
+	calls []string
+}
+
+func (d *recordingDeclarer) ExchangeDeclare(name, kind string, _, _, _, _ bool, _ amqp091.Table) error {
+	d.calls = append(d.calls, "exchange "+name+" "+kind)
+	return nil
+}
+
+func (d *recordingDeclarer) QueueDeclare(name string, _, _, _, _ bool, _ amqp091.Table) (amqp091.Queue, error) {
+	d.calls = append(d.calls, "queue "+name)
+	return amqp091.Queue{Name: name}, nil
+}
+
+func (d *recordingDeclarer) QueueBind(name, key, exchange string, _ bool, _ amqp091.Table) error {
+	d.calls = append(d.calls, "bind "+name+" "+exchange+" "+key)
+	return nil
+}
+
+func TestDeclareTopology(t *testing.T) {
+	config := Config{
+		Exchanges: []ExchangeConfig{{Name: "t3.topics"}, {Name: "t3.direct", Kind: "direct"}},
+		Queues: []QueueConfig{
+			{Name: "t3.test", Bindings: []BindingConfig{{Exchange: "t3.topics", RoutingKey: "orders.#"}}},
+		},
+	}
+
+	d := &recordingDeclarer{}
+	assert.NoError(t, declareTopology(d, config))
+	assert.Equal(
+		t, []string{
+			"exchange t3.topics topic", "exchange t3.direct direct", "queue t3.test", "bind t3.test t3.topics orders.#",
+		}, d.calls,
+	)
+}
@@ -0,0 +1,55 @@
+package amqp
+
+import (
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"t3-amqp/scenario"
+)
+
+// TraceDelivery extracts the redelivery history of a message consumed from queue out of
+// its redelivered flag and the x-death and x-delivery-count headers
+func TraceDelivery(queue string, d amqp091.Delivery) scenario.Delivery {
+	trace := scenario.Delivery{
+		MessageID:     d.MessageId,
+		Queue:         queue,
+		Redelivered:   d.Redelivered,
+		DeliveryCount: headerInt(d.Headers["x-delivery-count"]),
+	}
+
+	deaths, _ := d.Headers["x-death"].([]interface{})
+	for _, entry := range deaths {
+		table, ok := entry.(amqp091.Table)
+		if !ok {
+			continue
+		}
+		death := scenario.Death{Count: headerInt(table["count"])}
+		death.Queue, _ = table["queue"].(string)
+		death.Reason, _ = table["reason"].(string)
+		death.Exchange, _ = table["exchange"].(string)
+		trace.Deaths = append(trace.Deaths, death)
+	}
+	return trace
+}
+
+// headerInt reads an integer header whatever width the broker encoded it with
+func headerInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int8:
+		return int(n)
+	case int16:
+		return int(n)
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case uint8:
+		return int(n)
+	case uint16:
+		return int(n)
+	case uint32:
+		return int(n)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,34 @@
+package amqp
+
+import (
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceDeliveryReadsXDeath(t *testing.T) {
+	d := amqp091.Delivery{
+		MessageId:   "m-1",
+		Redelivered: true,
+		Headers: amqp091.Table{
+			"x-death": []interface{}{
+				amqp091.Table{"queue": "orders.wait", "reason": "expired", "exchange": "orders.retry", "count": int64(3)},
+				amqp091.Table{"queue": "orders", "reason": "rejected", "exchange": "orders", "count": int64(3)},
+			},
+		},
+	}
+
+	trace := TraceDelivery("orders.parked", d)
+	assert.Equal(t, "m-1", trace.MessageID)
+	assert.Equal(t, "orders.parked", trace.Queue)
+	assert.Len(t, trace.Deaths, 2)
+	assert.Equal(t, 3, trace.Retries("orders"))
+	assert.Equal(t, "rejected", trace.Deaths[1].Reason)
+}
+
+func TestTraceDeliveryReadsQuorumDeliveryCount(t *testing.T) {
+	trace := TraceDelivery("orders", amqp091.Delivery{Headers: amqp091.Table{"x-delivery-count": int32(2)}})
+	assert.Equal(t, 2, trace.Retries("orders"))
+	assert.Empty(t, trace.Deaths)
+}
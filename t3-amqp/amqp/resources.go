@@ -0,0 +1,71 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// TempQueueExpiry makes the broker drop a temporary queue that has been unused this long,
+// a last line of defence if neither the run nor the startup sweep could delete it
+const TempQueueExpiry = time.Hour
+
+// TempResources declares and deletes the temporary queues and exchanges used by scenario
+// instances
+type TempResources struct {
+	conn *Conn
+}
+
+// NewTempResources creates a TempResources using conn
+func NewTempResources(conn *Conn) *TempResources {
+	return &TempResources{conn: conn}
+}
+
+// DeclareTemp declares a non durable queue that the broker expires if it is left behind,
+// or a non durable fanout exchange that the broker deletes once its queue is gone
+func (r *TempResources) DeclareTemp(ctx context.Context, res scenario.Resource) error {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	switch res.Kind {
+	case scenario.KindQueue:
+		_, err = ch.QueueDeclare(
+			res.Name, false, false, false, false,
+			amqp091.Table{"x-expires": TempQueueExpiry.Milliseconds()},
+		)
+	case scenario.KindExchange:
+		err = ch.ExchangeDeclare(res.Name, amqp091.ExchangeFanout, false, true, false, false, nil)
+	default:
+		err = fmt.Errorf("unknown resource kind %q", res.Kind)
+	}
+	return err
+}
+
+// DeleteTemp deletes the queue or exchange, one that no longer exists counts as deleted
+func (r *TempResources) DeleteTemp(ctx context.Context, res scenario.Resource) error {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	switch res.Kind {
+	case scenario.KindQueue:
+		_, err = ch.QueueDelete(res.Name, false, false, false)
+	case scenario.KindExchange:
+		err = ch.ExchangeDelete(res.Name, false, false)
+	default:
+		return fmt.Errorf("unknown resource kind %q", res.Kind)
+	}
+	var amqpErr *amqp091.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound {
+		return nil
+	}
+	return err
+}
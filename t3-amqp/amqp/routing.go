@@ -0,0 +1,73 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"time"
+)
+
+// DefaultProbeTimeout bounds how long a routing probe waits for the broker's confirm
+const DefaultProbeTimeout = 5 * time.Second
+
+// Prober checks routing by publishing mandatory messages with publisher confirms
+type Prober struct {
+	conn    *Conn
+	timeout time.Duration
+}
+
+// NewProber creates a routing prober on conn
+func NewProber(conn *Conn, timeout time.Duration) *Prober {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	return &Prober{conn: conn, timeout: timeout}
+}
+
+// ProbeRouting publishes a mandatory probe to exchange with routingKey. The broker sends
+// basic.return before the confirm when no queue is bound, so once the confirm arrives
+// any return has already been delivered.
+func (p *Prober) ProbeRouting(ctx context.Context, exchange, routingKey string) (bool, error) {
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+
+	if err := ch.Confirm(false); err != nil {
+		return false, fmt.Errorf("error enabling publisher confirms: %w", err)
+	}
+	returns := ch.NotifyReturn(make(chan amqp091.Return, 1))
+	confirms := ch.NotifyPublish(make(chan amqp091.Confirmation, 1))
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	err = ch.PublishWithContext(
+		ctx, exchange, routingKey, true, false, amqp091.Publishing{
+			Headers:     amqp091.Table{"x-t3-probe": true},
+			ContentType: "application/json",
+			Body:        []byte(`{}`),
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("error publishing probe: %w", err)
+	}
+
+	select {
+	case <-returns:
+		return false, nil
+	case c := <-confirms:
+		if !c.Ack {
+			return false, fmt.Errorf("broker nacked probe")
+		}
+		select {
+		case <-returns:
+			return false, nil
+		default:
+			return true, nil
+		}
+	case <-ctx.Done():
+		return false, fmt.Errorf("waiting for probe confirm: %w", ctx.Err())
+	}
+}
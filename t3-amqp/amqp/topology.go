@@ -0,0 +1,46 @@
+package amqp
+
+import (
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+)
+
+// declarer is the part of a channel needed to declare topology
+type declarer interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp091.Table) error
+}
+
+// DeclareTopology declares the exchanges and queues listed in config and binds the queues
+func DeclareTopology(conn *Conn, config Config) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	return declareTopology(ch, config)
+}
+
+func declareTopology(ch declarer, config Config) error {
+	for _, e := range config.Exchanges {
+		kind := e.Kind
+		if kind == "" {
+			kind = amqp091.ExchangeTopic
+		}
+		if err := ch.ExchangeDeclare(e.Name, kind, e.Durable, false, false, false, nil); err != nil {
+			return fmt.Errorf("error declaring exchange %s: %w", e.Name, err)
+		}
+	}
+	for _, q := range config.Queues {
+		if _, err := ch.QueueDeclare(q.Name, q.Durable, false, false, false, nil); err != nil {
+			return fmt.Errorf("error declaring queue %s: %w", q.Name, err)
+		}
+		for _, b := range q.Bindings {
+			if err := ch.QueueBind(q.Name, b.RoutingKey, b.Exchange, false, nil); err != nil {
+				return fmt.Errorf("error binding queue %s to %s: %w", q.Name, b.Exchange, err)
+			}
+		}
+	}
+	return nil
+}
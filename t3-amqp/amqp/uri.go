@@ -0,0 +1,138 @@
+package amqp
+
+import (
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uriOptions are the query parameters accepted in a broker URI. heartbeat and
+// connection_timeout take a Go duration or, as in the RabbitMQ URI spec, an integer in
+// seconds and milliseconds respectively.
+var uriOptions = map[string]bool{
+	"heartbeat":              true,
+	"connection_timeout":     true,
+	"channel_max":            true,
+	"frame_size":             true,
+	"certfile":               true,
+	"keyfile":                true,
+	"cacertfile":             true,
+	"server_name_indication": true,
+	"auth_mechanism":         true,
+}
+
+// parseURIDuration reads a duration option, a bare integer is in unit
+func parseURIDuration(name, value string, unit time.Duration) (time.Duration, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 0 {
+			return 0, fmt.Errorf("%s must not be negative, got %d", name, n)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a duration like 10s or an integer, got %q", name, value)
+	}
+	return d, nil
+}
+
+// applyURI validates URL and moves its tuning options into the config, so a full
+// amqp(s):// URI can carry vhost, heartbeat and TLS settings. Options in the URI win over
+// the separate config fields. Errors never include the password.
+func (c *Config) applyURI() error {
+	if c.URL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid broker URI: %w", urlError(err))
+	}
+	shown := u.Redacted()
+	if u.Scheme != "amqp" && u.Scheme != "amqps" {
+		return fmt.Errorf("invalid broker URI %s: scheme must be amqp or amqps, got %q", shown, u.Scheme)
+	}
+
+	q := u.Query()
+	var unknown []string
+	for name := range q {
+		if !uriOptions[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		supported := make([]string, 0, len(uriOptions))
+		for name := range uriOptions {
+			supported = append(supported, name)
+		}
+		sort.Strings(supported)
+		return fmt.Errorf(
+			"invalid broker URI %s: unknown option %s, supported options are %s", shown,
+			strings.Join(unknown, ", "), strings.Join(supported, ", "),
+		)
+	}
+
+	if v := q.Get("heartbeat"); v != "" {
+		if c.Heartbeat, err = parseURIDuration("heartbeat", v, time.Second); err != nil {
+			return fmt.Errorf("invalid broker URI %s: %w", shown, err)
+		}
+	}
+	if v := q.Get("connection_timeout"); v != "" {
+		if c.ConnectionTimeout, err = parseURIDuration("connection_timeout", v, time.Millisecond); err != nil {
+			return fmt.Errorf("invalid broker URI %s: %w", shown, err)
+		}
+	}
+	if v := q.Get("channel_max"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid broker URI %s: channel_max must be an integer up to 65535, got %q", shown, v)
+		}
+		c.ChannelMax = uint16(n)
+	}
+	if v := q.Get("frame_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid broker URI %s: frame_size must be a positive integer, got %q", shown, v)
+		}
+		c.FrameSize = n
+	}
+
+	if (q.Get("certfile") == "") != (q.Get("keyfile") == "") {
+		return fmt.Errorf("invalid broker URI %s: certfile and keyfile must be given together", shown)
+	}
+	for _, name := range []string{"certfile", "keyfile", "cacertfile"} {
+		if path := q.Get(name); path != "" {
+			if u.Scheme != "amqps" {
+				return fmt.Errorf("invalid broker URI %s: %s requires the amqps scheme", shown, name)
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("invalid broker URI %s: %s: %w", shown, name, err)
+			}
+		}
+	}
+
+	// The tuning options now live in the config, the rest is left for the client library
+	for _, name := range []string{"heartbeat", "connection_timeout", "channel_max", "frame_size"} {
+		q.Del(name)
+	}
+	u.RawQuery = q.Encode()
+	if _, err := amqp091.ParseURI(u.String()); err != nil {
+		return fmt.Errorf("invalid broker URI %s: %w", shown, err)
+	}
+	c.URL = u.String()
+	return nil
+}
+
+// urlError strips the URL from a net/url error since it may contain the password
+func urlError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		return ue.Err
+	}
+	return err
+}
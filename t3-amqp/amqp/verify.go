@@ -0,0 +1,275 @@
+package amqp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"sync"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"t3-amqp/tracing"
+	"time"
+)
+
+const (
+	// DefaultVerifyDuration bounds a verification that sets neither a duration nor a max
+	DefaultVerifyDuration = time.Minute
+	// MaxReportedFailures bounds the failing messages kept in a report, the counts stay exact
+	MaxReportedFailures = 100
+	// MaxVerifications is how many verifications are remembered, the oldest are dropped first
+	MaxVerifications = 50
+)
+
+// Subscription selects the messages a verification consumes. Without a schema every
+// message is validated against the schema named in its headers.
+type Subscription struct {
+	Exchange string              `json:"exchange"`
+	Topic    string              `json:"topic"`
+	Schema   *scenario.SchemaRef `json:"schema,omitempty"`
+	Max      int                 `json:"max,omitempty"`
+	Duration time.Duration       `json:"durationNs,omitempty"`
+}
+
+// Validate checks that the subscription can be bound and is complete
+func (s Subscription) Validate() error {
+	if s.Exchange == "" || s.Topic == "" {
+		return fmt.Errorf("exchange and topic are required")
+	}
+	if s.Max < 0 || s.Duration < 0 {
+		return fmt.Errorf("max and durationNs must not be negative")
+	}
+	if s.Schema != nil && (s.Schema.Name == "" || s.Schema.Type == "" || s.Schema.Version == "") {
+		return fmt.Errorf("schema needs name, type and version")
+	}
+	return nil
+}
+
+// MessageFailure describes a consumed message that did not conform
+type MessageFailure struct {
+	RoutingKey string             `json:"routingKey"`
+	MessageID  string             `json:"messageId,omitempty"`
+	Schema     scenario.SchemaRef `json:"schema"`
+	Error      string             `json:"error"`
+}
+
+// VerificationReport counts how many consumed messages conformed to the registry.
+// Unresolved messages named no schema or one that is not registered.
+type VerificationReport struct {
+	ID           string           `json:"id"`
+	Subscription Subscription     `json:"subscription"`
+	Started      time.Time        `json:"started"`
+	Finished     *time.Time       `json:"finished,omitempty"`
+	Received     int              `json:"received"`
+	Valid        int              `json:"valid"`
+	Invalid      int              `json:"invalid"`
+	Unresolved   int              `json:"unresolved"`
+	Failures     []MessageFailure `json:"failures"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// Verification is a running or finished verification whose report may be read at any time
+type Verification struct {
+	mu     sync.Mutex
+	report VerificationReport
+}
+
+// Report returns a snapshot of the verification's report
+func (v *Verification) Report() VerificationReport {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	report := v.report
+	report.Failures = append([]MessageFailure{}, v.report.Failures...)
+	return report
+}
+
+// record counts a consumed message, failure is nil when it conformed
+func (v *Verification) record(failure *MessageFailure, resolved bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.report.Received++
+	switch {
+	case failure == nil:
+		v.report.Valid++
+		metrics.AMQPVerified.WithLabelValues("valid").Inc()
+		return
+	case resolved:
+		v.report.Invalid++
+		metrics.AMQPVerified.WithLabelValues("invalid").Inc()
+	default:
+		v.report.Unresolved++
+		metrics.AMQPVerified.WithLabelValues("unresolved").Inc()
+	}
+	if len(v.report.Failures) < MaxReportedFailures {
+		v.report.Failures = append(v.report.Failures, *failure)
+	}
+}
+
+func (v *Verification) finish(err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now().UTC()
+	v.report.Finished = &now
+	if err != nil {
+		v.report.Error = err.Error()
+	}
+}
+
+// Verifier validates consumed messages against the schemas stored in the registry
+type Verifier struct {
+	store   db.SchemaStore
+	headers broker.Headers
+}
+
+// NewVerifier creates a verifier that looks schemas up in store
+func NewVerifier(store db.SchemaStore) *Verifier {
+	return &Verifier{store: store, headers: broker.DefaultHeaders}
+}
+
+// WithHeaders resolves the schema of consumed messages from the headers of that convention
+func (v *Verifier) WithHeaders(headers broker.Headers) *Verifier {
+	v.headers = headers.WithDefaults()
+	return v
+}
+
+// Verify binds a temporary queue to the subscription's exchange and topic and validates
+// every message it receives until max messages were consumed, the duration elapsed or
+// ctx is done. The report is updated in place so a running verification can be observed.
+func (v *Verifier) Verify(ctx context.Context, conn *Conn, sub Subscription, run *Verification) error {
+	duration := sub.Duration
+	if duration <= 0 && sub.Max == 0 {
+		duration = DefaultVerifyDuration
+	}
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err == nil {
+		err = ch.QueueBind(q.Name, sub.Topic, sub.Exchange, false, nil)
+	}
+	ch.Close()
+	if err != nil {
+		return fmt.Errorf("error binding verification queue to %s: %w", sub.Exchange, err)
+	}
+
+	validators := broker.NewSchemaValidators(v.store)
+	_, err = Consume(
+		ctx, conn, q.Name, scenario.AckStrategy{Mode: scenario.AckAuto}, sub.Max, func(d amqp091.Delivery) {
+			_, span := tracing.StartReceive(ctx, system, d.RoutingKey, message(d).Headers)
+			failure, resolved := v.check(sub, validators, d)
+			if failure != nil {
+				tracing.RecordError(span, errors.New(failure.Error))
+			}
+			span.End()
+			run.record(failure, resolved)
+		},
+	)
+	if ctx.Err() != nil {
+		// Running out of time is how an open ended verification ends
+		return nil
+	}
+	return err
+}
+
+// check validates one delivery, a nil failure means it conformed. resolved reports
+// whether the message's schema was found in the registry.
+func (v *Verifier) check(
+	sub Subscription, validators *broker.SchemaValidators, d amqp091.Delivery,
+) (failure *MessageFailure, resolved bool) {
+	stamp := v.headers.Read(deliveryHeader(d))
+	if sub.Schema != nil {
+		stamp = broker.Stamp{SchemaRef: *sub.Schema}
+	}
+	failure = &MessageFailure{RoutingKey: d.RoutingKey, MessageID: d.MessageId, Schema: stamp.SchemaRef}
+
+	schema, err := validateStamp(validators, v.headers, stamp, d)
+	if schema != nil {
+		// Messages stamped with an ID only are reported with the schema it identifies
+		failure.Schema = scenario.SchemaRef{Name: schema.Name, Type: schema.Type, Version: schema.Version}
+	}
+	if err != nil {
+		failure.Error = err.Error()
+		return failure, schema != nil
+	}
+	return nil, true
+}
+
+// Verifications starts verifications in the background and remembers their reports
+type Verifications struct {
+	verifier *Verifier
+	conn     *Conn
+
+	mu    sync.Mutex
+	runs  map[string]*Verification
+	order []string
+}
+
+// NewVerifications creates a registry running verifications on conn
+func NewVerifications(verifier *Verifier, conn *Conn) *Verifications {
+	return &Verifications{verifier: verifier, conn: conn, runs: map[string]*Verification{}}
+}
+
+// Start launches a verification of sub, it stops when ctx is done at the latest
+func (r *Verifications) Start(ctx context.Context, sub Subscription) *Verification {
+	run := &Verification{
+		report: VerificationReport{
+			ID: newVerificationID(), Subscription: sub, Started: time.Now().UTC(), Failures: []MessageFailure{},
+		},
+	}
+
+	r.mu.Lock()
+	r.runs[run.report.ID] = run
+	r.order = append(r.order, run.report.ID)
+	if len(r.order) > MaxVerifications {
+		delete(r.runs, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.mu.Unlock()
+
+	go func() {
+		run.finish(r.verifier.Verify(ctx, r.conn, sub, run))
+	}()
+	return run
+}
+
+// Get returns the verification with id
+func (r *Verifications) Get(id string) (*Verification, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[id]
+	return run, ok
+}
+
+// List returns the reports of every remembered verification, newest first
+func (r *Verifications) List() []VerificationReport {
+	r.mu.Lock()
+	runs := make([]*Verification, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		runs = append(runs, r.runs[r.order[i]])
+	}
+	r.mu.Unlock()
+
+	reports := make([]VerificationReport, 0, len(runs))
+	for _, run := range runs {
+		reports = append(reports, run.Report())
+	}
+	return reports
+}
+
+func newVerificationID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,56 @@
+package amqp
+
+import (
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifierCountsConformingMessages(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{
+		Name: "orders", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`,
+	})
+	assert.NoError(t, err)
+
+	headers := amqp091.Table{HeaderSchemaName: "orders", HeaderSchemaType: "json", HeaderSchemaVersion: "1.0.0"}
+	deliveries := []amqp091.Delivery{
+		{Headers: headers, ContentType: "application/json", Body: []byte(`{"id":1}`)},
+		{Headers: headers, ContentType: "application/json", Body: []byte(`{"id":"x"}`)},
+		{Headers: headers, ContentType: "text/plain", Body: []byte(`{"id":2}`)},
+		{Body: []byte(`{"id":3}`)},
+		{Headers: amqp091.Table{HeaderSchemaName: "orders", HeaderSchemaType: "json", HeaderSchemaVersion: "9"}, Body: []byte(`{}`)},
+	}
+
+	v := NewVerifier(store)
+	run := &Verification{}
+	validators := broker.NewSchemaValidators(store)
+	for _, d := range deliveries {
+		run.record(v.check(Subscription{Exchange: "t3", Topic: "#"}, validators, d))
+	}
+
+	report := run.Report()
+	assert.Equal(t, 5, report.Received)
+	assert.Equal(t, 1, report.Valid)
+	assert.Equal(t, 2, report.Invalid)
+	assert.Equal(t, 2, report.Unresolved)
+	assert.Len(t, report.Failures, 4)
+
+	// A subscription schema overrides the headers
+	fixed := &scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"}
+	failure, resolved := v.check(Subscription{Schema: fixed}, validators, deliveries[3])
+	assert.Nil(t, failure)
+	assert.True(t, resolved)
+}
+
+func TestSubscriptionValidate(t *testing.T) {
+	assert.NoError(t, Subscription{Exchange: "t3", Topic: "orders.#"}.Validate())
+	assert.Error(t, Subscription{Topic: "orders.#"}.Validate())
+	assert.Error(t, Subscription{Exchange: "t3", Topic: "#", Max: -1}.Validate())
+	assert.Error(t, Subscription{Exchange: "t3", Topic: "#", Schema: &scenario.SchemaRef{Name: "orders"}}.Validate())
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"t3-amqp/auth/oidc"
+	"t3-amqp/db"
+)
+
+// Scopes an API key can hold, each one allows everything the previous ones do
+const (
+	ScopeRead      = "read"
+	ScopeReadWrite = "read-write"
+	ScopeAdmin     = "admin"
+)
+
+var scopeRank = map[string]int{ScopeRead: 1, ScopeReadWrite: 2, ScopeAdmin: 3}
+
+// roleScopes maps the roles bearer tokens grant to scopes
+var roleScopes = map[string]string{
+	oidc.RoleReader: ScopeRead,
+	oidc.RoleWriter: ScopeReadWrite,
+	oidc.RoleAdmin:  ScopeAdmin,
+}
+
+var (
+	ErrMissingKey   = errors.New("api key required")
+	ErrInvalidKey   = errors.New("invalid api key")
+	ErrInvalidScope = errors.New("scope must be read, read-write or admin")
+)
+
+// keyPrefix starts every generated key so leaked keys are easy to recognise
+const keyPrefix = "t3_"
+
+// AdminKeyName names the bootstrap key from the configuration
+const AdminKeyName = "admin"
+
+// ValidScope reports whether scope is one of the known scopes
+func ValidScope(scope string) bool {
+	return scopeRank[scope] > 0
+}
+
+// Allows reports whether a key holding scope may act with the required scope
+func Allows(scope, required string) bool {
+	return ValidScope(scope) && scopeRank[scope] >= scopeRank[required]
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Generate creates a key named name with scope. It returns the key to hand to the
+// caller, which is never stored, and the record to store.
+func Generate(name, scope string) (string, db.APIKey, error) {
+	if !ValidScope(scope) {
+		return "", db.APIKey{}, ErrInvalidScope
+	}
+	prefix, err := randomHex(6)
+	if err != nil {
+		return "", db.APIKey{}, fmt.Errorf("error generating api key: %w", err)
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", db.APIKey{}, fmt.Errorf("error generating api key: %w", err)
+	}
+	record := db.APIKey{Name: name, Prefix: prefix, Scope: scope, SecretHash: hashSecret(secret)}
+	return keyPrefix + prefix + "_" + secret, record, nil
+}
+
+// Principal is the caller a request was authenticated as
+type Principal struct {
+	// ID names the caller in audit entries and quotas, key:<prefix> for API keys and
+	// jwt:<subject> for bearer tokens
+	ID    string
+	Scope string
+	// Namespaces limits the caller to schemas in these namespaces, empty allows all
+	Namespaces []string
+	// Tenant binds the caller to one tenant, empty lets it choose one
+	Tenant string
+}
+
+// Authenticator checks presented API keys against the stored ones and bearer tokens
+// against the OIDC issuer
+type Authenticator struct {
+	enabled  bool
+	adminKey string
+	keys     db.APIKeyStore
+	tokens   *oidc.Verifier
+}
+
+// NewAuthenticator creates an authenticator looking keys up in keys. adminKey, when not
+// empty, is accepted as a bootstrap key with the admin scope. JWTs are verified with
+// tokens, nil turns them away.
+func NewAuthenticator(enabled bool, adminKey string, keys db.APIKeyStore, tokens *oidc.Verifier) *Authenticator {
+	return &Authenticator{enabled: enabled, adminKey: adminKey, keys: keys, tokens: tokens}
+}
+
+// Enabled reports whether requests must present a key
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate returns the caller presenting credentials. Secrets are compared in
+// constant time, ErrMissingKey, ErrInvalidKey and oidc.ErrInvalidToken tell the caller to
+// authenticate, other errors come from the key store or the OIDC issuer.
+func (a *Authenticator) Authenticate(ctx context.Context, presented string) (*Principal, error) {
+	if presented == "" {
+		return nil, ErrMissingKey
+	}
+	if a.adminKey != "" &&
+		subtle.ConstantTimeCompare([]byte(hashSecret(presented)), []byte(hashSecret(a.adminKey))) == 1 {
+		return &Principal{ID: "key:" + AdminKeyName, Scope: ScopeAdmin}, nil
+	}
+
+	// API keys hold no dots, a JWT is three dot separated parts
+	if strings.Count(presented, ".") == 2 {
+		if a.tokens == nil {
+			return nil, ErrInvalidKey
+		}
+		claims, err := a.tokens.Verify(ctx, presented)
+		if err != nil {
+			return nil, err
+		}
+		return &Principal{
+			ID: "jwt:" + claims.Subject, Scope: roleScopes[claims.Role], Namespaces: claims.Namespaces,
+			Tenant: claims.Tenant,
+		}, nil
+	}
+
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(presented, keyPrefix), "_")
+	if !ok || !strings.HasPrefix(presented, keyPrefix) || prefix == "" || secret == "" {
+		return nil, ErrInvalidKey
+	}
+	key, err := a.keys.APIKeyByPrefix(prefix)
+	if errors.Is(err, db.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 || key.RevokedAt != nil {
+		return nil, ErrInvalidKey
+	}
+	return &Principal{ID: "key:" + key.Prefix, Scope: key.Scope, Namespaces: key.Namespaces, Tenant: key.Tenant}, nil
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/auth/oidc"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllows(t *testing.T) {
+	assert.True(t, Allows(ScopeRead, ScopeRead))
+	assert.False(t, Allows(ScopeRead, ScopeReadWrite))
+	assert.True(t, Allows(ScopeReadWrite, ScopeRead))
+	assert.False(t, Allows(ScopeReadWrite, ScopeAdmin))
+	assert.True(t, Allows(ScopeAdmin, ScopeReadWrite))
+	assert.False(t, Allows("root", ScopeRead))
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryStore()
+	a := NewAuthenticator(true, "bootstrap-secret", store, nil)
+
+	key, record, err := Generate("ci", ScopeReadWrite)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "t3_"+record.Prefix+"_"))
+	assert.NotContains(t, record.SecretHash, strings.TrimPrefix(key, "t3_"+record.Prefix+"_"))
+	created, err := store.CreateAPIKey(record)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	found, err := a.Authenticate(ctx, key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "key:"+created.Prefix, found.ID)
+	assert.Equal(t, ScopeReadWrite, found.Scope)
+
+	_, err = a.Authenticate(ctx, "")
+	assert.True(t, errors.Is(err, ErrMissingKey))
+	_, err = a.Authenticate(ctx, key+"0")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = a.Authenticate(ctx, "t3_000000000000_abc")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = a.Authenticate(ctx, "not-a-key")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = a.Authenticate(ctx, "eyJhbGciOiJSUzI1NiJ9.e30.c2ln")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	admin, err := a.Authenticate(ctx, "bootstrap-secret")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeAdmin, admin.Scope)
+
+	assert.NoError(t, store.RevokeAPIKey(created.ID))
+	_, err = a.Authenticate(ctx, key)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	_, _, err = Generate("ci", "owner")
+	assert.True(t, errors.Is(err, ErrInvalidScope))
+}
+
+func TestAuthenticateBearerToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(t, err) {
+		return
+	}
+	jwks := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+			_, _ = fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":"k1","n":%q,"e":"AQAB"}]}`, n)
+		}),
+	)
+	defer jwks.Close()
+	tokens, err := oidc.NewVerifier(oidc.Config{Issuer: "https://idp.example", JWKSURL: jwks.URL, Audience: "t3"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a := NewAuthenticator(true, "", db.NewMemoryStore(), tokens)
+
+	token := jwt.NewWithClaims(
+		jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss": "https://idp.example", "aud": "t3", "sub": "alice", "roles": []string{"writer"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		},
+	)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+
+	principal, err := a.Authenticate(context.Background(), signed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "jwt:alice", principal.ID)
+	assert.Equal(t, ScopeReadWrite, principal.Scope)
+
+	_, err = a.Authenticate(context.Background(), signed+"x")
+	assert.True(t, errors.Is(err, oidc.ErrInvalidToken))
+}
@@ -0,0 +1,218 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// keysTTL is how long fetched keys are trusted before the set is fetched again
+	keysTTL = time.Hour
+	// minRefresh spaces out fetches triggered by unknown key ids or failures
+	minRefresh = time.Minute
+)
+
+// jwk is a single JSON Web Key, only the members needed for RSA and EC signature keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet caches the signing keys of the issuer, refetching them when they get old or a
+// token names a key id it does not know, which is how providers roll their keys
+type keySet struct {
+	client     *http.Client
+	issuer     string
+	url        string
+	minRefresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]interface{}
+	fetched time.Time
+	checked time.Time
+	err     error
+}
+
+func newKeySet(client *http.Client, issuer, url string) *keySet {
+	return &keySet{client: client, issuer: issuer, url: url, minRefresh: minRefresh}
+}
+
+// key returns the public key with kid, a token without kid may use the only key of a set
+// holding one. Errors wrapping ErrKeysUnavailable mean the keys could not be fetched.
+// The keys are fetched without holding the lock, so a slow provider does not hold up
+// tokens signed with keys already known.
+func (s *keySet) key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	key, ok := s.find(kid)
+	if ok && time.Since(s.fetched) < keysTTL {
+		s.mu.Unlock()
+		return key, nil
+	}
+	refresh := time.Since(s.checked) >= s.minRefresh
+	if refresh {
+		s.checked = time.Now()
+	}
+	url := s.url
+	s.mu.Unlock()
+
+	if refresh {
+		keys, url, err := s.fetch(ctx, url)
+		s.mu.Lock()
+		s.err = err
+		if err == nil {
+			s.keys, s.url, s.fetched = keys, url, time.Now()
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok = s.find(kid)
+	switch {
+	case ok:
+		// A stale key beats none while the provider is unreachable
+		return key, nil
+	case s.keys == nil && s.err != nil:
+		return nil, s.err
+	default:
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+}
+
+func (s *keySet) find(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// fetch fetches the key set at url, locating it through the issuer's discovery document
+// when url is empty, and returns the keys together with the url they came from
+func (s *keySet) fetch(ctx context.Context, url string) (map[string]interface{}, string, error) {
+	if url == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		err := s.get(ctx, strings.TrimSuffix(s.issuer, "/")+"/.well-known/openid-configuration", &discovery)
+		if err != nil {
+			return nil, "", err
+		}
+		if discovery.JWKSURI == "" {
+			return nil, "", fmt.Errorf("%w: discovery document has no jwks_uri", ErrKeysUnavailable)
+		}
+		url = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := s.get(ctx, url, &set); err != nil {
+		return nil, "", err
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: key %q: %w", ErrKeysUnavailable, k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, url, nil
+}
+
+func (s *keySet) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrKeysUnavailable, err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrKeysUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s answered %s", ErrKeysUnavailable, url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: error decoding %s: %w", ErrKeysUnavailable, url, err)
+	}
+	return nil
+}
+
+// publicKey decodes an RSA or EC key, other key types are skipped with a nil key
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("rsa exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
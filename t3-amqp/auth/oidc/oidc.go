@@ -0,0 +1,190 @@
+// Package oidc verifies JWT bearer tokens issued by an OpenID Connect provider and maps
+// their role claim to the roles the registry enforces
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Roles a token can grant, each one allows everything the previous ones do
+const (
+	RoleReader = "reader"
+	RoleWriter = "writer"
+	RoleAdmin  = "admin"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid bearer token")
+	ErrKeysUnavailable  = errors.New("identity provider keys unavailable")
+	ErrAudienceRequired = errors.New("oidc audience is required with an issuer")
+)
+
+// clockSkew is the leeway allowed between the provider's clock and ours
+const clockSkew = 30 * time.Second
+
+// Config struct to hold the OIDC settings, tokens are only accepted when Issuer is set
+type Config struct {
+	// Issuer must match the iss claim, its discovery document locates the keys unless
+	// JWKSURL is set
+	Issuer  string `mapstructure:"issuer"`
+	JWKSURL string `mapstructure:"jwks_url"`
+	// Audience must be in the aud claim, it is required so tokens the provider issued
+	// for other applications are not accepted
+	Audience string `mapstructure:"audience"`
+	// RolesClaim names the claim listing the caller's roles, dots descend into nested
+	// objects as in realm_access.roles. Defaults to roles.
+	RolesClaim string `mapstructure:"roles_claim"`
+	// NamespacesClaim names the claim listing the schema namespaces the caller is limited
+	// to. Callers are not limited when it is unset or the token lacks the claim.
+	NamespacesClaim string `mapstructure:"namespaces_claim"`
+	// TenantClaim names the claim binding the caller to a tenant. Callers choose their
+	// tenant themselves when it is unset or the token lacks the claim.
+	TenantClaim string `mapstructure:"tenant_claim"`
+	// Roles lists the claim values granting each role. A role without values is granted
+	// by a claim value equal to its name.
+	Roles struct {
+		Reader []string `mapstructure:"reader"`
+		Writer []string `mapstructure:"writer"`
+		Admin  []string `mapstructure:"admin"`
+	} `mapstructure:"roles"`
+}
+
+// Claims are what the registry takes from a verified token
+type Claims struct {
+	Subject string
+	// Role is the highest role granted, empty when the token grants none
+	Role string
+	// Namespaces limits the caller to some schema namespaces, empty allows all
+	Namespaces []string
+	// Tenant binds the caller to a tenant, empty leaves the choice to the caller
+	Tenant string
+}
+
+// Verifier checks the signature and claims of bearer tokens
+type Verifier struct {
+	config Config
+	keys   *keySet
+	parser *jwt.Parser
+}
+
+// NewVerifier creates a verifier for tokens issued by config.Issuer to config.Audience,
+// fetching the keys with client or a default client when nil. It fails with
+// ErrAudienceRequired when an issuer is set without an audience.
+func NewVerifier(config Config, client *http.Client) (*Verifier, error) {
+	if config.Issuer != "" && config.Audience == "" {
+		return nil, ErrAudienceRequired
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(config.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithAudience(config.Audience),
+	}
+
+	return &Verifier{
+		config: config,
+		keys:   newKeySet(client, config.Issuer, config.JWKSURL),
+		parser: jwt.NewParser(options...),
+	}, nil
+}
+
+// Verify checks token and returns its claims. Rejected tokens wrap ErrInvalidToken,
+// ErrKeysUnavailable means the token could not be checked.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(
+		token, claims, func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return v.keys.key(ctx, kid)
+		},
+	)
+	if errors.Is(err, ErrKeysUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidToken)
+	}
+	verified := &Claims{Subject: subject, Role: v.role(claims)}
+	if v.config.NamespacesClaim != "" {
+		verified.Namespaces = claimValues(claims, v.config.NamespacesClaim)
+	}
+	if tenants := claimValues(claims, v.config.TenantClaim); v.config.TenantClaim != "" && len(tenants) > 0 {
+		verified.Tenant = tenants[0]
+	}
+	return verified, nil
+}
+
+// role returns the highest role the values of the roles claim grant
+func (v *Verifier) role(claims jwt.MapClaims) string {
+	values := claimValues(claims, v.config.RolesClaim)
+	grants := func(role string, configured []string) bool {
+		if len(configured) == 0 {
+			configured = []string{role}
+		}
+		for _, value := range values {
+			if slices.Contains(configured, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case grants(RoleAdmin, v.config.Roles.Admin):
+		return RoleAdmin
+	case grants(RoleWriter, v.config.Roles.Writer):
+		return RoleWriter
+	case grants(RoleReader, v.config.Roles.Reader):
+		return RoleReader
+	default:
+		return ""
+	}
+}
+
+// claimValues reads the claim at the dotted path as a list of strings, accepting a JSON
+// array or a space separated string like the scope claim
+func claimValues(claims map[string]interface{}, path string) []string {
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
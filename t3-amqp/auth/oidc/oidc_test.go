@@ -0,0 +1,261 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// issuer serves a discovery document and the JWKS holding its current keys
+type issuer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []jwk
+	down bool
+	// hold, when set, delays answering for the keys until it is closed
+	hold chan struct{}
+}
+
+func newIssuer(t *testing.T) *issuer {
+	iss := &issuer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{"issuer": iss.URL, "jwks_uri": iss.URL + "/keys"})
+		},
+	)
+	mux.HandleFunc(
+		"/keys", func(w http.ResponseWriter, r *http.Request) {
+			iss.mu.Lock()
+			hold := iss.hold
+			iss.mu.Unlock()
+			if hold != nil {
+				<-hold
+			}
+			iss.mu.Lock()
+			defer iss.mu.Unlock()
+			if iss.down {
+				http.Error(w, "unavailable", http.StatusBadGateway)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string][]jwk{"keys": iss.keys})
+		},
+	)
+	iss.Server = httptest.NewServer(mux)
+	t.Cleanup(iss.Close)
+	return iss
+}
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func (iss *issuer) addRSA(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.keys = append(
+		iss.keys, jwk{Kty: "RSA", Kid: kid, Use: "sig", N: encodeInt(key.N), E: encodeInt(big.NewInt(int64(key.E)))},
+	)
+	return key
+}
+
+func (iss *issuer) addEC(t *testing.T, kid string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	iss.keys = append(iss.keys, jwk{Kty: "EC", Kid: kid, Crv: "P-256", X: encodeInt(key.X), Y: encodeInt(key.Y)})
+	return key
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestVerify(t *testing.T) {
+	iss := newIssuer(t)
+	rsaKey := iss.addRSA(t, "rsa-1")
+	ecKey := iss.addEC(t, "ec-1")
+	v, err := NewVerifier(Config{Issuer: iss.URL, Audience: "t3"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"iss": iss.URL, "aud": "t3", "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+		for k, value := range extra {
+			c[k] = value
+		}
+		return c
+	}
+	rs256 := func(c jwt.MapClaims) string {
+		return sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, c)
+	}
+
+	got, err := v.Verify(ctx, rs256(claims(jwt.MapClaims{"roles": []string{"reader", "writer"}})))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "alice", got.Subject)
+	assert.Equal(t, RoleWriter, got.Role)
+
+	got, err = v.Verify(ctx, sign(t, jwt.SigningMethodES256, "ec-1", ecKey, claims(jwt.MapClaims{"roles": "admin"})))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, RoleAdmin, got.Role)
+
+	got, err = v.Verify(ctx, rs256(claims(nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, "", got.Role)
+
+	rejected := map[string]string{
+		"expired":  rs256(claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"no exp":   rs256(jwt.MapClaims{"iss": iss.URL, "aud": "t3", "sub": "alice"}),
+		"issuer":   rs256(claims(jwt.MapClaims{"iss": "https://evil.example"})),
+		"audience": rs256(claims(jwt.MapClaims{"aud": "other"})),
+		"no sub":   rs256(claims(jwt.MapClaims{"sub": ""})),
+		"kid":      sign(t, jwt.SigningMethodRS256, "ec-1", rsaKey, claims(nil)),
+		"hmac":     sign(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), claims(nil)),
+		"garbage":  "a.b.c",
+	}
+	for name, token := range rejected {
+		_, err := v.Verify(ctx, token)
+		assert.True(t, errors.Is(err, ErrInvalidToken), "%s: %v", name, err)
+	}
+}
+
+func TestVerifyRoleMapping(t *testing.T) {
+	iss := newIssuer(t)
+	key := iss.addRSA(t, "rsa-1")
+	config := Config{Issuer: iss.URL, JWKSURL: iss.URL + "/keys", Audience: "t3", RolesClaim: "realm_access.roles"}
+	config.Roles.Writer = []string{"t3-writers"}
+	v, err := NewVerifier(config, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token := func(roles ...string) string {
+		return sign(
+			t, jwt.SigningMethodRS256, "rsa-1", key, jwt.MapClaims{
+				"iss": iss.URL, "aud": "t3", "sub": "ci", "exp": time.Now().Add(time.Hour).Unix(),
+				"realm_access": map[string]interface{}{"roles": roles},
+			},
+		)
+	}
+
+	got, err := v.Verify(context.Background(), token("offline_access", "t3-writers"))
+	assert.NoError(t, err)
+	assert.Equal(t, RoleWriter, got.Role)
+
+	// Configured values replace the role name, unconfigured roles still match by name
+	got, err = v.Verify(context.Background(), token("writer", "reader"))
+	assert.NoError(t, err)
+	assert.Equal(t, RoleReader, got.Role)
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	iss := newIssuer(t)
+	oldKey := iss.addRSA(t, "old")
+	v, err := NewVerifier(Config{Issuer: iss.URL, Audience: "t3"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.keys.minRefresh = 0
+	ctx := context.Background()
+	claims := jwt.MapClaims{"iss": iss.URL, "aud": "t3", "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	assert.NoError(t, err)
+
+	newKey := iss.addRSA(t, "new")
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	assert.NoError(t, err)
+
+	// Known keys keep working while the provider is down
+	iss.mu.Lock()
+	iss.down = true
+	iss.mu.Unlock()
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	assert.NoError(t, err)
+}
+
+func TestVerifyKeysUnavailable(t *testing.T) {
+	iss := newIssuer(t)
+	key := iss.addRSA(t, "rsa-1")
+	iss.down = true
+	v, err := NewVerifier(Config{Issuer: iss.URL, Audience: "t3"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	claims := jwt.MapClaims{"iss": iss.URL, "aud": "t3", "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", key, claims))
+	assert.True(t, errors.Is(err, ErrKeysUnavailable), "%v", err)
+	assert.False(t, errors.Is(err, ErrInvalidToken))
+}
+
+func TestVerifierRequiresAudience(t *testing.T) {
+	_, err := NewVerifier(Config{Issuer: "https://id.example"}, nil)
+	assert.ErrorIs(t, err, ErrAudienceRequired)
+}
+
+func TestVerifyDoesNotWaitForRefresh(t *testing.T) {
+	iss := newIssuer(t)
+	key := iss.addRSA(t, "known")
+	v, err := NewVerifier(Config{Issuer: iss.URL, Audience: "t3"}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.keys.minRefresh = 0
+	ctx := context.Background()
+	claims := jwt.MapClaims{"iss": iss.URL, "aud": "t3", "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	_, err = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, "known", key, claims))
+	assert.NoError(t, err)
+
+	// A token naming an unknown key refreshes the set while the provider is slow to answer
+	hold := make(chan struct{})
+	iss.mu.Lock()
+	iss.hold = hold
+	iss.mu.Unlock()
+	refreshed := make(chan struct{})
+	go func() {
+		defer close(refreshed)
+		_, _ = v.Verify(ctx, sign(t, jwt.SigningMethodRS256, "unknown", key, claims))
+	}()
+
+	verified := make(chan error, 1)
+	go func() {
+		_, err := v.Verify(ctx, sign(t, jwt.SigningMethodRS256, "known", key, claims))
+		verified <- err
+	}()
+	select {
+	case err := <-verified:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Error("verifying with a known key waited for the refresh")
+	}
+	close(hold)
+	<-refreshed
+}
@@ -0,0 +1,164 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sort"
+	"sync"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+	"time"
+)
+
+// Case is a single benchmarked operation
+type Case struct {
+	Name string
+	Op   func(ctx context.Context) error
+}
+
+// Result is the outcome of running a Case, stable across releases so results can be diffed
+type Result struct {
+	Name        string  `json:"name"`
+	Ops         int     `json:"ops"`
+	Errors      int     `json:"errors"`
+	Concurrency int     `json:"concurrency"`
+	DurationMs  float64 `json:"durationMs"`
+	OpsPerSec   float64 `json:"opsPerSec"`
+	P50Us       float64 `json:"p50Us"`
+	P95Us       float64 `json:"p95Us"`
+	P99Us       float64 `json:"p99Us"`
+	MaxUs       float64 `json:"maxUs"`
+}
+
+// Report is the full output of a bench run
+type Report struct {
+	Target    string    `json:"target"`
+	Started   time.Time `json:"started"`
+	GoVersion string    `json:"goVersion"`
+	Results   []Result  `json:"results"`
+}
+
+// Options control how many operations each case performs
+type Options struct {
+	Ops         int
+	Concurrency int
+}
+
+// Run executes every case and collects its latency distribution
+func Run(ctx context.Context, target string, cases []Case, opts Options) Report {
+	if opts.Ops <= 0 {
+		opts.Ops = 1000
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	report := Report{Target: target, Started: time.Now().UTC(), GoVersion: runtime.Version()}
+	for _, c := range cases {
+		report.Results = append(report.Results, runCase(ctx, c, opts))
+	}
+	return report
+}
+
+func runCase(ctx context.Context, c Case, opts Options) Result {
+	latencies := make([]time.Duration, opts.Ops)
+	work := make(chan int)
+	var errCount int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				opStart := time.Now()
+				err := c.Op(ctx)
+				latencies[i] = time.Since(opStart)
+				if err != nil {
+					mu.Lock()
+					errCount++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < opts.Ops; i++ {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return Result{
+		Name:        c.Name,
+		Ops:         opts.Ops,
+		Errors:      errCount,
+		Concurrency: opts.Concurrency,
+		DurationMs:  float64(elapsed) / float64(time.Millisecond),
+		OpsPerSec:   float64(opts.Ops) / elapsed.Seconds(),
+		P50Us:       micros(Percentile(latencies, 50)),
+		P95Us:       micros(Percentile(latencies, 95)),
+		P99Us:       micros(Percentile(latencies, 99)),
+		MaxUs:       micros(latencies[len(latencies)-1]),
+	}
+}
+
+// Percentile returns the p-th percentile of sorted latencies
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p / 100)
+	return sorted[idx]
+}
+
+func micros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// LookupCase fetches a schema by name/type/version from the registry at baseURL
+func LookupCase(client *http.Client, baseURL, name, schemaType, version string) Case {
+	q := url.Values{"name": {name}, "type": {schemaType}, "version": {version}}
+	target := baseURL + "/schema?" + q.Encode()
+
+	return Case{
+		Name: "registry_lookup",
+		Op: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			_, _ = io.Copy(io.Discard, resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
+
+// ValidationCase validates payload against a schema through the shared validator cache
+func ValidationCase(schemaType, schemaData string, payload []byte) Case {
+	return Case{
+		Name: "validation_" + schemaType,
+		Op: func(ctx context.Context) error {
+			v, err := validate.DefaultCache.Validator(db.Schema{Type: schemaType, SchemaData: schemaData})
+			if err != nil {
+				return err
+			}
+			return v.Validate(payload)
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const benchJSONSchema = `{"type": "object", "properties": {"example": {"type": "string"}}, "required": ["example"]}`
+
+func TestRunCollectsResults(t *testing.T) {
+	calls := 0
+	c := Case{
+		Name: "noop",
+		Op: func(ctx context.Context) error {
+			calls++
+			return nil
+		},
+	}
+
+	report := Run(context.Background(), "local", []Case{c}, Options{Ops: 50, Concurrency: 1})
+	assert.Len(t, report.Results, 1)
+	assert.Equal(t, 50, calls)
+	assert.Equal(t, 50, report.Results[0].Ops)
+	assert.Zero(t, report.Results[0].Errors)
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(5), Percentile(sorted, 50))
+	assert.Equal(t, time.Duration(10), Percentile(sorted, 100))
+	assert.Zero(t, Percentile(nil, 50))
+}
+
+func BenchmarkValidationJSON(b *testing.B) {
+	payload := []byte(`{"example": "value"}`)
+	for i := 0; i < b.N; i++ {
+		v, err := validate.DefaultCache.Validator(db.Schema{Type: "json", SchemaData: benchJSONSchema})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := v.Validate(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompileJSON(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := validate.Compile("json", benchJSONSchema); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRegistryLookup(b *testing.B) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"Name":"bench"}]`))
+		}),
+	)
+	defer server.Close()
+
+	c := LookupCase(server.Client(), server.URL, "bench", "json", "1.0.0")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.Op(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirStoreRoundTrip(t *testing.T) {
+	store, err := NewDirStore(t.TempDir())
+	assert.NoError(t, err)
+
+	err = store.Put(context.Background(), "sha256/abc", strings.NewReader("<xs:schema/>"))
+	assert.NoError(t, err)
+
+	r, err := store.Open(context.Background(), "sha256/abc")
+	assert.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "<xs:schema/>", string(data))
+}
+
+func TestDirStoreRejectsBadKeys(t *testing.T) {
+	store, err := NewDirStore(t.TempDir())
+	assert.NoError(t, err)
+
+	assert.Error(t, store.Put(context.Background(), "../escape", strings.NewReader("x")))
+	_, err = store.Open(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
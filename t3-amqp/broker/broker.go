@@ -0,0 +1,97 @@
+// Package broker is the messaging layer topic tests run on. Broker is implemented for
+// AMQP by package amqp, for Kafka by package kafka, for MQTT by package mqtt and for NATS
+// by package nats, the publisher and scenario executor here work with any of them.
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+)
+
+// Broker kinds selected by the kind setting of the broker config
+const (
+	KindAMQP  = "amqp"
+	KindKafka = "kafka"
+	KindMQTT  = "mqtt"
+	KindNATS  = "nats"
+)
+
+// Headers carrying the schema a message was published under
+const (
+	HeaderSchemaName    = "x-t3-schema-name"
+	HeaderSchemaType    = "x-t3-schema-type"
+	HeaderSchemaVersion = "x-t3-schema-version"
+	// HeaderSchemaID holds the registry ID of the schema
+	HeaderSchemaID = "x-t3-schema-id"
+	// HeaderViolation marks a deliberately invalid message with the rule it breaks
+	HeaderViolation = "x-t3-violation"
+	// HeaderInstance tags scenario messages with the temporary queue of their instance
+	HeaderInstance = "x-t3-instance"
+	// HeaderPublished holds the publish time of scenario messages in Unix nanoseconds, the
+	// AMQP timestamp property only has second precision
+	HeaderPublished = "x-t3-published"
+)
+
+var (
+	ErrInvalidPayload = errors.New("payload does not match schema")
+	ErrSchemaRetired  = errors.New("schema has been retired")
+)
+
+// Message is a message as every broker can carry it, header values are strings
+type Message struct {
+	ID          string
+	ContentType string
+	Headers     map[string]string
+	Body        []byte
+	// QoS and Retain override the MQTT quality of service and retain flag of published
+	// messages and report them for received ones, other brokers ignore them
+	QoS    *byte
+	Retain bool
+}
+
+// Broker publishes to and subscribes on topics. A topic is a routing key on the exchange
+// for AMQP, a topic for Kafka and MQTT and a subject for NATS. MQTT subscriptions also
+// take topic filters.
+type Broker interface {
+	// DeclareTopic makes sure topic exists, brokers that route by key need not do anything
+	DeclareTopic(ctx context.Context, topic string) error
+	// Publish sends msg to topic and returns once the broker accepted it
+	Publish(ctx context.Context, topic string, msg Message) error
+	// Subscribe calls handle for every message published to topic from now on until ctx is
+	// done or stop is called. It returns once the subscription is in place, handle is
+	// called from a single goroutine. stop waits for handle to return and reports the
+	// error that ended the subscription early, if any.
+	Subscribe(ctx context.Context, topic string, handle func(Message)) (stop func() error, err error)
+	Close() error
+}
+
+// AckSubscriber is implemented by brokers whose subscribers acknowledge messages
+// themselves. Scenarios with an ack strategy run on those only.
+type AckSubscriber interface {
+	// SubscribeAck is Subscribe acknowledging messages the way ack asks for once handle
+	// returned. stop also reports the first acknowledgement that failed.
+	SubscribeAck(
+		ctx context.Context, topic string, ack scenario.AckStrategy, handle func(Message),
+	) (stop func() error, err error)
+}
+
+// ValidateContent checks that a message carries the content type of schemaType and that
+// its body is valid against v. Messages without a content type are validated as is.
+func ValidateContent(v validate.Validator, schemaType, contentType string, body []byte) error {
+	if contentType != "" {
+		mt, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return fmt.Errorf("invalid content type %q: %w", contentType, err)
+		}
+		want := validate.ContentType(schemaType)
+		if !strings.EqualFold(mt, want) && !(want == "application/xml" && strings.EqualFold(mt, "text/xml")) {
+			return fmt.Errorf("content type %q does not match %s schema, expected %s", contentType, schemaType, want)
+		}
+	}
+	return v.Validate(body)
+}
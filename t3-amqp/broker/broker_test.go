@@ -0,0 +1,230 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loopback delivers published messages to the subscribers of their topic
+type loopback struct {
+	mu          sync.Mutex
+	published   []Message
+	subscribers map[string][]chan Message
+	declared    []string
+}
+
+func (b *loopback) DeclareTopic(_ context.Context, topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.declared = append(b.declared, topic)
+	return nil
+}
+
+func (b *loopback) Publish(_ context.Context, topic string, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, msg)
+	for _, sub := range b.subscribers[topic] {
+		sub <- msg
+	}
+	return nil
+}
+
+func (b *loopback) Subscribe(ctx context.Context, topic string, handle func(Message)) (func() error, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers == nil {
+		b.subscribers = map[string][]chan Message{}
+	}
+	messages := make(chan Message, 100)
+	b.subscribers[topic] = append(b.subscribers[topic], messages)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case m := <-messages:
+				handle(m)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return nil
+	}, nil
+}
+
+func (b *loopback) Close() error {
+	return nil
+}
+
+// ackLoopback is a loopback whose subscribers acknowledge messages
+type ackLoopback struct {
+	loopback
+	strategies []scenario.AckStrategy
+}
+
+func (b *ackLoopback) SubscribeAck(
+	ctx context.Context, topic string, ack scenario.AckStrategy, handle func(Message),
+) (func() error, error) {
+	b.mu.Lock()
+	b.strategies = append(b.strategies, ack)
+	b.mu.Unlock()
+	return b.Subscribe(ctx, topic, handle)
+}
+
+func testStore(t *testing.T) *db.MemoryStore {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{
+		Name: "orders", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`,
+	})
+	assert.NoError(t, err)
+	return store
+}
+
+func TestPublisher(t *testing.T) {
+	b := &loopback{}
+	p := NewPublisher(b, testStore(t))
+	ref := scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"}
+
+	assert.NoError(t, p.Publish(context.Background(), "orders.created", ref, []byte(`{"id":1}`)))
+	err := p.Publish(context.Background(), "orders.created", ref, []byte(`{"id":"x"}`))
+	assert.True(t, errors.Is(err, ErrInvalidPayload))
+	assert.NoError(t, p.PublishInvalid(context.Background(), "orders.created", ref, []byte(`{}`), "required"))
+
+	ref.Version = "2.0.0"
+	err = p.Publish(context.Background(), "orders.created", ref, []byte(`{"id":1}`))
+	assert.True(t, errors.Is(err, db.ErrSchemaNotFound))
+
+	if assert.Len(t, b.published, 2) {
+		assert.Equal(t, "application/json", b.published[0].ContentType)
+		assert.Equal(t, "1.0.0", b.published[0].Headers[HeaderSchemaVersion])
+		assert.Equal(t, "required", b.published[1].Headers[HeaderViolation])
+	}
+}
+
+func TestExecutor(t *testing.T) {
+	b := &loopback{}
+	e := NewExecutor(b, testStore(t))
+	inst := scenario.Instance{
+		TempQueue: "t3.run1.0",
+		Scenario: scenario.Scenario{
+			Topic: "orders.created", MessageCount: 5, Rate: 100,
+			Schema: scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"},
+		},
+	}
+
+	result, err := e.Execute(context.Background(), inst)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders.created"}, b.declared)
+	assert.Equal(t, 5, result.MessagesSent)
+	assert.Equal(t, 5, result.MessagesValid)
+	assert.Zero(t, result.Failures)
+	if assert.NotNil(t, result.Latency) {
+		assert.Equal(t, 5, result.Latency.Samples)
+	}
+	if assert.Len(t, b.published, 5) {
+		assert.Equal(t, "t3.run1.0-0", b.published[0].ID)
+		assert.Equal(t, "t3.run1.0", b.published[0].Headers[HeaderInstance])
+	}
+
+	inst.Scenario.Mode = scenario.ModeInvalid
+	result, err = e.Execute(context.Background(), inst)
+	assert.NoError(t, err)
+	assert.Zero(t, result.MessagesValid)
+	assert.Zero(t, result.Failures, "invalid scenarios pass when no message validates")
+
+	qos := 2
+	inst.Scenario.MQTT = &scenario.MQTTOptions{QoS: &qos, Retain: true}
+	_, err = e.Execute(context.Background(), inst)
+	assert.NoError(t, err)
+	if last := b.published[len(b.published)-1]; assert.NotNil(t, last.QoS) {
+		assert.Equal(t, byte(2), *last.QoS)
+		assert.True(t, last.Retain)
+	}
+
+	inst.Scenario.Ack = &scenario.AckStrategy{Mode: scenario.AckAuto}
+	_, err = e.Execute(context.Background(), inst)
+	assert.ErrorIs(t, err, ErrNoAcks)
+
+	inst.Scenario.Assertions = []scenario.Assertion{{}}
+	_, err = e.Execute(context.Background(), inst)
+	assert.ErrorIs(t, err, ErrNeedsAMQP)
+}
+
+func TestExecutorAckStrategy(t *testing.T) {
+	b := &ackLoopback{}
+	ack := scenario.AckStrategy{Mode: scenario.AckBatch, BatchSize: 2}
+	inst := scenario.Instance{
+		TempQueue: "t3.run1.0",
+		Scenario: scenario.Scenario{
+			Topic: "orders.created", MessageCount: 3, Ack: &ack,
+			Schema: scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"},
+		},
+	}
+
+	result, err := NewExecutor(b, testStore(t)).Execute(context.Background(), inst)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.MessagesValid)
+	assert.Equal(t, []scenario.AckStrategy{ack}, b.strategies)
+}
+
+func TestTallyCountsMissingMessages(t *testing.T) {
+	schemas, err := testStore(t).Filter(db.QueryArgs{Name: "orders"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tally := newTally("t3.run1.0", NewSchemaValidators(testStore(t)), DefaultHeaders)
+	other := NewMessage(schemas[0], []byte(`{"id":1}`))
+	other.Headers[HeaderInstance] = "t3.run2.0"
+	assert.Zero(t, tally.record(other), "messages of other instances are ignored")
+
+	var result scenario.Result
+	tally.fill(&result, scenario.ModeValid, 3)
+	assert.Equal(t, 3, result.Failures)
+	assert.Nil(t, result.Latency)
+}
+
+func TestStampResolve(t *testing.T) {
+	store := testStore(t)
+	schemas, err := store.Filter(db.QueryArgs{Name: "orders"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	id := schemas[0].ID
+	headers := Headers{SchemaID: "schema-id"}
+	stamped := headers.Message(schemas[0], []byte(`{"id":1}`)).Headers
+	assert.Equal(t, "orders", stamped[HeaderSchemaName])
+	assert.NotEmpty(t, stamped["schema-id"])
+
+	read := func(h map[string]string) Stamp { return headers.Read(func(name string) string { return h[name] }) }
+	ref := scenario.SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"}
+	assert.Equal(t, Stamp{SchemaRef: ref, ID: id}, read(stamped))
+
+	resolved, err := read(map[string]string{"schema-id": stamped["schema-id"]}).Resolve(store)
+	assert.NoError(t, err)
+	assert.Equal(t, id, resolved.ID, "an ID alone is enough")
+
+	// An ID from another registry loses against the name
+	resolved, err = Stamp{SchemaRef: ref, ID: id + 100}.Resolve(store)
+	assert.NoError(t, err)
+	assert.Equal(t, id, resolved.ID)
+
+	_, err = Stamp{ID: id + 100}.Resolve(store)
+	assert.ErrorIs(t, err, db.ErrSchemaNotFound)
+	_, err = Stamp{}.Resolve(store)
+	assert.ErrorIs(t, err, ErrNoSchema)
+}
@@ -0,0 +1,261 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+	"time"
+)
+
+// DefaultDrainTimeout bounds how long an executor waits for published messages to come
+// back once publishing finished
+const DefaultDrainTimeout = 30 * time.Second
+
+var (
+	// ErrNeedsAMQP rejects scenarios using features that rely on AMQP queues
+	ErrNeedsAMQP = errors.New("assertions need an AMQP broker")
+	// ErrNoAcks rejects scenarios with an ack strategy on brokers without acknowledgements
+	ErrNoAcks = errors.New("the broker does not support ack strategies")
+	// ErrNoGroups rejects scenarios with a consumer group, which compete for an AMQP queue
+	ErrNoGroups = errors.New("consumer groups need an AMQP broker")
+	// ErrNoChaos rejects scenarios with chaos options, which are injected by the AMQP client
+	ErrNoChaos = errors.New("chaos options need an AMQP broker")
+)
+
+// Executor runs scenario instances on any Broker. It subscribes to the scenario topic,
+// publishes generated messages at the scenario rate and validates every message of the
+// instance it receives back. Messages are told apart by the x-t3-instance header, which
+// holds the temporary queue name of the instance even where no queue is declared.
+type Executor struct {
+	broker  Broker
+	store   db.SchemaStore
+	headers Headers
+	drain   time.Duration
+}
+
+// NewExecutor creates an executor publishing and subscribing through b
+func NewExecutor(b Broker, store db.SchemaStore) *Executor {
+	return &Executor{broker: b, store: store, headers: DefaultHeaders, drain: DefaultDrainTimeout}
+}
+
+// WithHeaders stamps published messages according to headers and resolves the schema of
+// received ones from them
+func (e *Executor) WithHeaders(headers Headers) *Executor {
+	e.headers = headers.WithDefaults()
+	return e
+}
+
+// Execute runs inst. Valid scenarios fail for every message that does not validate,
+// invalid ones for every message the schema accepts, and all for messages that were
+// published but not received back before the drain timeout, the only failures of
+// latency scenarios.
+func (e *Executor) Execute(ctx context.Context, inst scenario.Instance) (scenario.Result, error) {
+	s := inst.Scenario
+	var result scenario.Result
+	if len(s.Assertions) > 0 {
+		return result, ErrNeedsAMQP
+	}
+	if s.Group != nil {
+		return result, ErrNoGroups
+	}
+	if s.Chaos != nil {
+		return result, ErrNoChaos
+	}
+	subscribe := e.broker.Subscribe
+	if s.Ack != nil {
+		acker, ok := e.broker.(AckSubscriber)
+		if !ok {
+			return result, ErrNoAcks
+		}
+		subscribe = func(ctx context.Context, topic string, handle func(Message)) (func() error, error) {
+			return acker.SubscribeAck(ctx, topic, *s.Ack, handle)
+		}
+	}
+
+	schema, err := ActiveSchema(e.store, s.Schema)
+	if err != nil {
+		return result, err
+	}
+	if _, err := validate.DefaultCache.Validator(schema); err != nil {
+		return result, fmt.Errorf("error compiling schema %s/%s/%s: %w", schema.Name, schema.Type, schema.Version, err)
+	}
+	next, err := ScenarioGenerator(schema, s, time.Now().UnixNano())
+	if err != nil {
+		return result, err
+	}
+	if err := e.broker.DeclareTopic(ctx, s.Topic); err != nil {
+		return result, fmt.Errorf("error declaring topic %s: %w", s.Topic, err)
+	}
+
+	subscription := s.Topic
+	switch {
+	case s.Probe != nil && s.Probe.ReplyTopic != "":
+		// Latency probes come back on the reply topic of a mirror
+		subscription = s.Probe.ReplyTopic
+		if err := e.broker.DeclareTopic(ctx, subscription); err != nil {
+			return result, fmt.Errorf("error declaring topic %s: %w", subscription, err)
+		}
+	case s.MQTT != nil && s.MQTT.Subscription != "":
+		subscription = s.MQTT.Subscription
+	}
+	// Received messages are validated against the schema they name, which is the
+	// scenario's unless another producer publishes to the topic
+	validators := NewSchemaValidators(db.ForTenant(e.store, s.Schema.Tenant)).WithFallback(schema)
+	t := newTally(inst.TempQueue, validators, e.headers)
+	if s.Mode == scenario.ModeLatency {
+		t.probes = s.Topic
+	}
+	complete := make(chan struct{})
+	var once sync.Once
+	stop, err := subscribe(ctx, subscription, func(m Message) {
+		if t.record(m) == s.MessageCount {
+			once.Do(func() { close(complete) })
+		}
+	})
+	if err != nil {
+		return result, fmt.Errorf("error subscribing to %s: %w", subscription, err)
+	}
+
+	sent, publishErr := e.publish(ctx, inst, schema, next)
+	if publishErr == nil {
+		timer := time.NewTimer(e.drain)
+		select {
+		case <-complete:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+		timer.Stop()
+	}
+	// stop waits for the handler, so the tally is no longer written to below
+	err = stop()
+
+	t.fill(&result, s.Mode, sent)
+	if publishErr != nil {
+		return result, publishErr
+	}
+	return result, err
+}
+
+// publish sends the messages of inst at the scenario rate and returns how many the
+// broker accepted
+func (e *Executor) publish(ctx context.Context, inst scenario.Instance, schema db.Schema, next Generator) (int, error) {
+	s := inst.Scenario
+	var tick <-chan time.Time
+	if s.Rate > 0 {
+		if interval := time.Second / time.Duration(s.Rate); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+	}
+
+	for i := 0; i < s.MessageCount; i++ {
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return i, ctx.Err()
+			}
+		}
+
+		data, violation, err := next()
+		if err != nil {
+			return i, fmt.Errorf("error generating message %d: %w", i, err)
+		}
+		msg := e.headers.Message(schema, data)
+		msg.ID = fmt.Sprintf("%s-%d", inst.TempQueue, i)
+		msg.Headers[HeaderInstance] = inst.TempQueue
+		msg.Headers[HeaderPublished] = strconv.FormatInt(time.Now().UnixNano(), 10)
+		if violation != nil {
+			msg.Headers[HeaderViolation] = violation.String()
+		}
+		if s.MQTT != nil {
+			if s.MQTT.QoS != nil {
+				qos := byte(*s.MQTT.QoS)
+				msg.QoS = &qos
+			}
+			msg.Retain = s.MQTT.Retain
+		}
+		if err := e.broker.Publish(ctx, s.Topic, msg); err != nil {
+			metrics.AMQPPublished.WithLabelValues("failed").Inc()
+			return i, fmt.Errorf("error publishing message %d: %w", i, err)
+		}
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+	}
+	return s.MessageCount, nil
+}
+
+// tally counts the messages of one instance received back from the topic. Other
+// messages published to the topic in the meantime are ignored.
+type tally struct {
+	instance   string
+	validators *SchemaValidators
+	headers    Headers
+	// probes is the topic probe latency is recorded for, empty outside latency scenarios
+	probes string
+
+	received  int
+	valid     int
+	seen      map[string]bool
+	latencies []time.Duration
+}
+
+func newTally(instance string, validators *SchemaValidators, headers Headers) *tally {
+	return &tally{instance: instance, validators: validators, headers: headers, seen: map[string]bool{}}
+}
+
+// record counts m if it belongs to the instance and returns how many messages were
+// received. Messages redelivered after a nack are only counted the first time.
+func (t *tally) record(m Message) int {
+	if m.Headers[HeaderInstance] != t.instance {
+		return t.received
+	}
+	metrics.AMQPConsumed.Inc()
+	if m.ID != "" {
+		if t.seen[m.ID] {
+			return t.received
+		}
+		t.seen[m.ID] = true
+	}
+	t.received++
+	if published, err := strconv.ParseInt(m.Headers[HeaderPublished], 10, 64); err == nil {
+		latency := time.Since(time.Unix(0, published))
+		t.latencies = append(t.latencies, latency)
+		if t.probes != "" {
+			metrics.ProbeLatency.WithLabelValues(t.probes).Observe(latency.Seconds())
+		}
+	}
+	header := func(name string) string { return m.Headers[name] }
+	schema, validator, err := t.validators.For(t.headers.Read(header))
+	if err == nil {
+		contentType := t.headers.ContentTypeOf(m.ContentType, header)
+		if ValidateContent(validator, schema.Type, contentType, m.Body) == nil {
+			t.valid++
+		}
+	}
+	return t.received
+}
+
+// fill sets the message counts and latency of result once sent messages were published
+func (t *tally) fill(result *scenario.Result, mode string, sent int) {
+	result.MessagesSent = sent
+	result.MessagesValid = t.valid
+	result.Latency = scenario.NewLatency(t.latencies)
+	result.Failures = max(sent-t.received, 0)
+	switch mode {
+	case scenario.ModeInvalid:
+		result.Failures += t.valid
+	case scenario.ModeLatency:
+		if t.probes != "" {
+			metrics.ProbesLost.WithLabelValues(t.probes).Add(float64(result.Failures))
+		}
+	default:
+		result.Failures += t.received - t.valid
+	}
+}
@@ -0,0 +1,89 @@
+package broker
+
+import (
+	"fmt"
+	"t3-amqp/db"
+	"t3-amqp/payload"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+)
+
+// Generator produces the next test message and for invalid messages the rule it breaks
+type Generator func() ([]byte, *payload.Violation, error)
+
+// NewGenerator returns the generator of mode for a JSON schema, an empty mode is
+// scenario.ModeValid. Invalid payloads the schema still accepts, which mutations under
+// anyOf or oneOf can produce, are skipped.
+func NewGenerator(schema db.Schema, mode string, seed int64) (Generator, error) {
+	if mode != scenario.ModeInvalid {
+		gen, err := payload.JSONSchema([]byte(schema.SchemaData), seed)
+		if err != nil {
+			return nil, err
+		}
+		next := 0
+		return func() ([]byte, *payload.Violation, error) {
+			data, err := gen.Generate(next)
+			next++
+			return data, nil, err
+		}, nil
+	}
+
+	fuzzer, err := payload.JSONSchemaFuzzer([]byte(schema.SchemaData), seed)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := validate.DefaultCache.Validator(schema)
+	if err != nil {
+		return nil, err
+	}
+	// Skipped payloads use up their index so the messages depend only on the seed
+	next := 0
+	return func() ([]byte, *payload.Violation, error) {
+		for attempt := 0; attempt < 100; attempt++ {
+			data, violation, err := fuzzer.Fuzz(next)
+			next++
+			if err != nil {
+				return nil, nil, err
+			}
+			if validator.Validate(data) != nil {
+				return data, &violation, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("%w: mutations keep producing valid payloads", payload.ErrCannotGenerate)
+	}, nil
+}
+
+// ScenarioGenerator returns the generator for the messages of s. Valid scenarios may pad
+// payloads to the scenario's size distribution and draw them from a pre-generated pool.
+func ScenarioGenerator(schema db.Schema, s scenario.Scenario, seed int64) (Generator, error) {
+	if schema.Type != "json" {
+		return nil, fmt.Errorf("payloads can only be generated for json schemas, not %s", schema.Type)
+	}
+	if s.Mode == scenario.ModeInvalid || (s.PayloadSize == nil && s.PayloadPool == 0) {
+		return NewGenerator(schema, s.Mode, seed)
+	}
+
+	gen, err := payload.JSONSchema([]byte(schema.SchemaData), seed)
+	if err != nil {
+		return nil, err
+	}
+	if s.PayloadSize != nil {
+		dist, err := s.PayloadSize.Distribution()
+		if err != nil {
+			return nil, err
+		}
+		pad, err := payload.PaddingFor([]byte(schema.SchemaData))
+		if err != nil {
+			return nil, err
+		}
+		gen = payload.Sized(gen, dist, pad, seed)
+	}
+	source, err := payload.NewSource(gen, s.PayloadPool)
+	if err != nil {
+		return nil, err
+	}
+	return func() ([]byte, *payload.Violation, error) {
+		data, err := source.Next()
+		return data, nil, err
+	}, nil
+}
@@ -0,0 +1,184 @@
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+)
+
+// ErrNoSchema is reported for consumed messages whose headers name no schema
+var ErrNoSchema = errors.New("message names no schema")
+
+// Headers is the convention naming the headers test messages are stamped with. Unset
+// schema headers use the x-t3-schema-* defaults. ContentType also copies the content type
+// into a header for consumers that do not read the message property, unset it is not.
+type Headers struct {
+	SchemaName    string `mapstructure:"schema_name"`
+	SchemaType    string `mapstructure:"schema_type"`
+	SchemaVersion string `mapstructure:"schema_version"`
+	SchemaID      string `mapstructure:"schema_id"`
+	ContentType   string `mapstructure:"content_type"`
+}
+
+// DefaultHeaders is the x-t3-schema-* convention
+var DefaultHeaders = Headers{}.WithDefaults()
+
+// WithDefaults fills in unset schema headers
+func (h Headers) WithDefaults() Headers {
+	if h.SchemaName == "" {
+		h.SchemaName = HeaderSchemaName
+	}
+	if h.SchemaType == "" {
+		h.SchemaType = HeaderSchemaType
+	}
+	if h.SchemaVersion == "" {
+		h.SchemaVersion = HeaderSchemaVersion
+	}
+	if h.SchemaID == "" {
+		h.SchemaID = HeaderSchemaID
+	}
+	return h
+}
+
+// Stamp returns the headers naming schema, which publishes messages of contentType
+func (h Headers) Stamp(schema db.Schema, contentType string) map[string]string {
+	h = h.WithDefaults()
+	headers := map[string]string{
+		h.SchemaName:    schema.Name,
+		h.SchemaType:    schema.Type,
+		h.SchemaVersion: schema.Version,
+		h.SchemaID:      strconv.Itoa(schema.ID),
+	}
+	if h.ContentType != "" && contentType != "" {
+		headers[h.ContentType] = contentType
+	}
+	return headers
+}
+
+// Message builds the message for payload, naming schema in its headers
+func (h Headers) Message(schema db.Schema, payload []byte) Message {
+	contentType := validate.ContentType(schema.Type)
+	return Message{ContentType: contentType, Headers: h.Stamp(schema, contentType), Body: payload}
+}
+
+// Read returns the schema a consumed message names, header looks its headers up
+func (h Headers) Read(header func(name string) string) Stamp {
+	h = h.WithDefaults()
+	stamp := Stamp{
+		SchemaRef: scenario.SchemaRef{
+			Name: header(h.SchemaName), Type: header(h.SchemaType), Version: header(h.SchemaVersion),
+		},
+	}
+	stamp.ID, _ = strconv.Atoi(header(h.SchemaID))
+	return stamp
+}
+
+// ContentTypeOf returns the content type of a consumed message, its property or, when
+// that is empty, the content type header of the convention
+func (h Headers) ContentTypeOf(property string, header func(name string) string) string {
+	if property == "" && h.ContentType != "" {
+		return header(h.ContentType)
+	}
+	return property
+}
+
+// Stamp is the schema a message names in its headers. ID is 0 when the message carries
+// no schema ID, which messages stamped by other producers often do not.
+type Stamp struct {
+	scenario.SchemaRef
+	ID int `json:"id,omitempty"`
+}
+
+// Named reports whether the stamp names a schema by ID or by name, type and version
+func (s Stamp) Named() bool {
+	return s.ID > 0 || s.complete()
+}
+
+func (s Stamp) complete() bool {
+	return s.Name != "" && s.Type != "" && s.Version != ""
+}
+
+// Resolve looks the stamped schema up in store. The ID is tried first, when the message
+// also names a schema that the ID does not identify, such as an ID assigned by another
+// registry, the name, type and version win.
+func (s Stamp) Resolve(store db.SchemaStore) (db.Schema, error) {
+	if !s.Named() {
+		return db.Schema{}, ErrNoSchema
+	}
+	if s.ID > 0 {
+		schema, err := store.GetByID(s.ID)
+		switch {
+		case err == nil && (!s.complete() || s.sameAs(*schema)):
+			return *schema, nil
+		case err != nil && !errors.Is(err, db.ErrNotFound):
+			return db.Schema{}, fmt.Errorf("error retrieving schema %d: %w", s.ID, err)
+		case !s.complete():
+			return db.Schema{}, fmt.Errorf("schema %d: %w", s.ID, db.ErrSchemaNotFound)
+		}
+	}
+
+	schemas, err := store.Filter(db.QueryArgs{Name: s.Name, Type: s.Type, Version: s.Version})
+	if err != nil {
+		return db.Schema{}, fmt.Errorf("error retrieving schema %s/%s/%s: %w", s.Name, s.Type, s.Version, err)
+	}
+	if len(schemas) == 0 {
+		return db.Schema{}, fmt.Errorf("schema %s/%s/%s: %w", s.Name, s.Type, s.Version, db.ErrSchemaNotFound)
+	}
+	return schemas[0], nil
+}
+
+func (s Stamp) sameAs(schema db.Schema) bool {
+	return s.Name == schema.Name && s.Type == schema.Type && s.Version == schema.Version
+}
+
+// SchemaValidators validates consumed messages against the schema stamped in their
+// headers, remembering the schemas it resolved. Messages naming no schema are validated
+// against the fallback schema when there is one.
+type SchemaValidators struct {
+	store    db.SchemaStore
+	fallback *db.Schema
+	resolved map[Stamp]db.Schema
+}
+
+// NewSchemaValidators creates validators resolving schemas in store
+func NewSchemaValidators(store db.SchemaStore) *SchemaValidators {
+	return &SchemaValidators{store: store, resolved: map[Stamp]db.Schema{}}
+}
+
+// WithFallback validates messages that name no schema against schema
+func (v *SchemaValidators) WithFallback(schema db.Schema) *SchemaValidators {
+	v.fallback = &schema
+	return v
+}
+
+// For returns the schema stamp names and its validator. The schema is nil when it could
+// not be resolved, a schema that fails to compile is returned with the error.
+func (v *SchemaValidators) For(stamp Stamp) (*db.Schema, validate.Validator, error) {
+	var schema db.Schema
+	switch {
+	case !stamp.Named() && v.fallback != nil:
+		schema = *v.fallback
+	default:
+		// Failures are not remembered, the schema may be registered while messages arrive
+		resolved, ok := v.resolved[stamp]
+		if !ok {
+			var err error
+			if resolved, err = stamp.Resolve(v.store); err != nil {
+				return nil, nil, err
+			}
+			v.resolved[stamp] = resolved
+		}
+		schema = resolved
+	}
+
+	validator, err := validate.DefaultCache.Validator(schema)
+	if err != nil {
+		return &schema, nil, fmt.Errorf(
+			"error compiling schema %s/%s/%s: %w", schema.Name, schema.Type, schema.Version, err,
+		)
+	}
+	return &schema, validator, nil
+}
@@ -0,0 +1,114 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/scenario"
+	"t3-amqp/tracing"
+	"t3-amqp/validate"
+)
+
+// Publisher sends test messages to the topics of any Broker after validating them
+// against the registry
+type Publisher struct {
+	store   db.SchemaStore
+	broker  Broker
+	headers Headers
+}
+
+// NewPublisher creates a publisher sending through b
+func NewPublisher(b Broker, store db.SchemaStore) *Publisher {
+	return &Publisher{store: store, broker: b, headers: DefaultHeaders}
+}
+
+// WithHeaders stamps published messages according to headers
+func (p *Publisher) WithHeaders(headers Headers) *Publisher {
+	p.headers = headers.WithDefaults()
+	return p
+}
+
+// Publish validates payload against the schema named by ref and publishes it to topic.
+// The schema is recorded in the message headers so consumers can validate what they
+// receive.
+func (p *Publisher) Publish(ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte) error {
+	ctx, span := tracing.StartPublish(ctx, "", topic)
+	defer span.End()
+
+	schema, err := ActiveSchema(p.store, ref)
+	if err == nil {
+		err = p.publish(ctx, topic, schema, payload)
+	}
+	countPublished(err)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// PublishInvalid publishes payload like Publish but without validating it, the rule it
+// breaks is recorded in the x-t3-violation header
+func (p *Publisher) PublishInvalid(
+	ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte, violation string,
+) error {
+	ctx, span := tracing.StartPublish(ctx, "", topic)
+	defer span.End()
+
+	schema, err := ActiveSchema(p.store, ref)
+	if err == nil {
+		msg := p.headers.Message(schema, payload)
+		msg.Headers[HeaderViolation] = violation
+		tracing.Inject(ctx, msg.Headers)
+		err = p.broker.Publish(ctx, topic, msg)
+	}
+	countPublished(err)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (p *Publisher) publish(ctx context.Context, topic string, schema db.Schema, payload []byte) error {
+	validator, err := validate.DefaultCache.Validator(schema)
+	if err != nil {
+		return fmt.Errorf("error compiling schema %s/%s/%s: %w", schema.Name, schema.Type, schema.Version, err)
+	}
+	if err := validator.Validate(payload); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	msg := p.headers.Message(schema, payload)
+	tracing.Inject(ctx, msg.Headers)
+	return p.broker.Publish(ctx, topic, msg)
+}
+
+// countPublished records the outcome of a publish in the published messages metric
+func countPublished(err error) {
+	switch {
+	case err == nil:
+		metrics.AMQPPublished.WithLabelValues("ok").Inc()
+	case errors.Is(err, ErrInvalidPayload), errors.Is(err, ErrSchemaRetired), errors.Is(err, db.ErrSchemaNotFound):
+		metrics.AMQPPublished.WithLabelValues("rejected").Inc()
+	default:
+		metrics.AMQPPublished.WithLabelValues("failed").Inc()
+	}
+}
+
+// ActiveSchema looks up the schema named by ref in the tenant of ref in store,
+// ErrSchemaRetired when it has been retired
+func ActiveSchema(store db.SchemaStore, ref scenario.SchemaRef) (db.Schema, error) {
+	args := db.QueryArgs{Name: ref.Name, Type: ref.Type, Version: ref.Version}
+	schemas, err := db.ForTenant(store, ref.Tenant).Filter(args)
+	if err != nil {
+		return db.Schema{}, fmt.Errorf("error retrieving schema %s/%s/%s: %w", ref.Name, ref.Type, ref.Version, err)
+	}
+	if len(schemas) == 0 {
+		return db.Schema{}, fmt.Errorf("schema %s/%s/%s: %w", ref.Name, ref.Type, ref.Version, db.ErrSchemaNotFound)
+	}
+	if schemas[0].Status == db.StatusRetired {
+		return db.Schema{}, fmt.Errorf("schema %s/%s/%s: %w", ref.Name, ref.Type, ref.Version, ErrSchemaRetired)
+	}
+	return schemas[0], nil
+}
+
+// NewMessage builds the message for payload, naming schema in the x-t3-schema-* headers
+func NewMessage(schema db.Schema, payload []byte) Message {
+	return DefaultHeaders.Message(schema, payload)
+}
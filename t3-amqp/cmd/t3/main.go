@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"t3-amqp/bench"
+	"time"
+)
+
+const usage = `usage: t3 <command> [flags]
+
+commands:
+  bench      run the performance harness against a registry and print JSON results
+  generate   generate random messages for a JSON schema, printing them one per line or
+             publishing them to a topic, -invalid makes each break one schema rule
+  capture    start recording the messages published to a topic and print the capture
+  replay     republish the messages of a capture in their original order and timing,
+             -speed scales the timing and 0 replays as fast as the broker confirms
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "bench":
+		os.Exit(runBench(os.Args[2:]))
+	case "generate":
+		os.Exit(runGenerate(os.Args[2:]))
+	case "capture":
+		os.Exit(runCapture(os.Args[2:]))
+	case "replay":
+		os.Exit(runReplay(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of the schema registry")
+	name := fs.String("name", "", "schema name used for lookups and validation")
+	schemaType := fs.String("type", "json", "schema type")
+	version := fs.String("version", "", "schema version")
+	payloadFile := fs.String("payload", "", "file containing a payload to validate")
+	ops := fs.Int("ops", 1000, "operations per case")
+	concurrency := fs.Int("concurrency", 8, "concurrent workers per case")
+	out := fs.String("out", "", "write results to this file instead of stdout")
+	_ = fs.Parse(args)
+
+	if *name == "" || *version == "" {
+		fmt.Fprintln(os.Stderr, "bench: -name and -version are required")
+		return 2
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	cases := []bench.Case{bench.LookupCase(client, *target, *name, *schemaType, *version)}
+
+	if *payloadFile != "" {
+		payload, err := os.ReadFile(*payloadFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			return 1
+		}
+		schemaData, err := fetchSchemaData(client, *target, *name, *schemaType, *version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			return 1
+		}
+		cases = append(cases, bench.ValidationCase(*schemaType, schemaData, payload))
+	}
+
+	report := bench.Run(
+		context.Background(), *target, cases, bench.Options{Ops: *ops, Concurrency: *concurrency},
+	)
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runGenerate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of the schema registry")
+	name := fs.String("name", "", "schema name")
+	version := fs.String("version", "", "schema version")
+	count := fs.Int("count", 1, "number of messages")
+	topic := fs.String("topic", "", "publish the messages to this topic instead of printing them")
+	invalid := fs.Bool("invalid", false, "generate messages that each break one rule of the schema")
+	seed := fs.Int64("seed", 0, "seed reproducing an earlier run, random when unset")
+	key := fs.String("key", os.Getenv("T3_API_KEY"), "API key or bearer token, defaults to T3_API_KEY")
+	_ = fs.Parse(args)
+
+	if *name == "" || *version == "" {
+		fmt.Fprintln(os.Stderr, "generate: -name and -version are required")
+		return 2
+	}
+
+	req := map[string]interface{}{"name": *name, "type": "json", "version": *version, "count": *count}
+	if *topic != "" {
+		req["topic"] = *topic
+	}
+	if *invalid {
+		req["mode"] = "invalid"
+	}
+	fs.Visit(
+		func(f *flag.Flag) {
+			if f.Name == "seed" {
+				req["seed"] = *seed
+			}
+		},
+	)
+	body, err := json.Marshal(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "generate: %v\n", err)
+		return 1
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, *target+"/generate", bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "generate: %v\n", err)
+		return 1
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if *key != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+*key)
+	}
+	// Publishing waits for a broker confirm per message, so large runs take a while
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "generate: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		problem, _ := io.ReadAll(resp.Body)
+		fmt.Fprintf(os.Stderr, "generate: unexpected status %d: %s\n", resp.StatusCode, bytes.TrimSpace(problem))
+		return 1
+	}
+
+	var result struct {
+		Seed      int64             `json:"seed"`
+		Published int               `json:"published"`
+		Messages  []json.RawMessage `json:"messages"`
+		// Violations are printed next to their message as {"payload":...,"violation":...}
+		Violations []json.RawMessage `json:"violations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		fmt.Fprintf(os.Stderr, "generate: %v\n", err)
+		return 1
+	}
+	for i, message := range result.Messages {
+		if i < len(result.Violations) {
+			fmt.Printf("{\"payload\":%s,\"violation\":%s}\n", message, result.Violations[i])
+			continue
+		}
+		fmt.Println(string(message))
+	}
+	if *topic != "" {
+		fmt.Fprintf(os.Stderr, "published %d messages to %s\n", result.Published, *topic)
+	}
+	fmt.Fprintf(os.Stderr, "seed %d\n", result.Seed)
+	return 0
+}
+
+func runCapture(args []string) int {
+	fs := flag.NewFlagSet("capture", flag.ExitOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of the schema registry")
+	topic := fs.String("topic", "", "topic to capture")
+	maxMessages := fs.Int("max", 0, "stop after this many messages, the server limit when unset")
+	duration := fs.Duration("duration", 0, "stop after this long, the server limit when unset")
+	key := fs.String("key", os.Getenv("T3_API_KEY"), "API key or bearer token, defaults to T3_API_KEY")
+	_ = fs.Parse(args)
+
+	if *topic == "" {
+		fmt.Fprintln(os.Stderr, "capture: -topic is required")
+		return 2
+	}
+	req := map[string]interface{}{"topic": *topic, "maxMessages": *maxMessages, "durationNs": *duration}
+	client := &http.Client{Timeout: 10 * time.Second}
+	body, err := postJSON(client, *target+"/captures", *key, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "capture: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(bytes.TrimSpace(body)))
+	return 0
+}
+
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	target := fs.String("target", "http://localhost:8080", "base URL of the schema registry")
+	id := fs.Int("capture", 0, "ID of the capture to replay")
+	topic := fs.String("topic", "", "publish to this topic instead of the original routing keys")
+	speed := fs.Float64("speed", 1, "speed factor of the original timing, 0 replays without waiting")
+	key := fs.String("key", os.Getenv("T3_API_KEY"), "API key or bearer token, defaults to T3_API_KEY")
+	_ = fs.Parse(args)
+
+	if *id <= 0 {
+		fmt.Fprintln(os.Stderr, "replay: -capture is required")
+		return 2
+	}
+	req := map[string]interface{}{"speed": *speed}
+	if *topic != "" {
+		req["topic"] = *topic
+	}
+	// The server answers once every message is replayed, which takes as long as the capture
+	// divided by the speed
+	client := &http.Client{Timeout: 2 * time.Hour}
+	body, err := postJSON(client, fmt.Sprintf("%s/captures/%d/replay", *target, *id), *key, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+
+	var result struct {
+		Published int `json:"published"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "replayed %d messages of capture %d\n", result.Published, *id)
+	return 0
+}
+
+// postJSON posts req to endpoint and returns the body of a 200 or 202 response
+func postJSON(client *http.Client, endpoint, key string, req interface{}) ([]byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
+
+func fetchSchemaData(client *http.Client, target, name, schemaType, version string) (string, error) {
+	q := url.Values{"name": {name}, "type": {schemaType}, "version": {version}}
+	resp, err := client.Get(target + "/schema?" + q.Encode())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("schema %s/%s/%s not found", name, schemaType, version)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching schema: unexpected status %d", resp.StatusCode)
+	}
+
+	// name, type and version identify exactly one schema, which is answered on its own
+	var schema struct{ SchemaData string }
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		return "", err
+	}
+	return schema.SchemaData, nil
+}
@@ -0,0 +1,136 @@
+// Command t3ctl manages schemas and topic test scenarios of a registry through its REST
+// API. The registry address and API key default to T3_TARGET and T3_API_KEY.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"t3-amqp/t3client"
+	"time"
+)
+
+func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// api talks to the registry, through the generated client where it covers an endpoint
+type api struct {
+	target string
+	key    string
+	http   *http.Client
+}
+
+func newRootCmd() *cobra.Command {
+	a := &api{http: &http.Client{Timeout: 30 * time.Second}}
+	root := &cobra.Command{
+		Use:          "t3ctl",
+		Short:        "Manage schemas and topic test scenarios of a schema registry",
+		SilenceUsage: true,
+	}
+	target := os.Getenv("T3_TARGET")
+	if target == "" {
+		target = "http://localhost:8080"
+	}
+	root.PersistentFlags().StringVar(&a.target, "target", target, "base URL of the registry, defaults to T3_TARGET")
+	root.PersistentFlags().StringVar(
+		&a.key, "key", os.Getenv("T3_API_KEY"), "API key or bearer token, defaults to T3_API_KEY",
+	)
+
+	root.AddCommand(
+		newSchemaCmd(a), newScenarioCmd(a), newPublishCmd(a), newConsumeCmd(a), newValidateCmd(a),
+	)
+	return root
+}
+
+// client returns the generated client sending the API key with every request
+func (a *api) client() (*t3client.ClientWithResponses, error) {
+	return t3client.NewClientWithResponses(
+		strings.TrimSuffix(a.target, "/"),
+		t3client.WithHTTPClient(a.http),
+		t3client.WithRequestEditorFn(
+			func(_ context.Context, req *http.Request) error {
+				a.authorize(req)
+				return nil
+			},
+		),
+	)
+}
+
+func (a *api) authorize(req *http.Request) {
+	if a.key != "" {
+		req.Header.Set("Authorization", "Bearer "+a.key)
+	}
+}
+
+// do sends req as JSON to path and decodes the 2xx response into resp unless it is nil
+func (a *api) do(ctx context.Context, method, path string, req, resp interface{}) error {
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(a.target, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	a.authorize(httpReq)
+
+	httpResp, err := a.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return statusError(httpResp.StatusCode, data)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// statusError describes an unexpected response, using the detail of problem documents
+func statusError(status int, body []byte) error {
+	var problem t3client.Problem
+	if json.Unmarshal(body, &problem) == nil && problem.Title != "" {
+		if problem.Detail != nil && *problem.Detail != "" {
+			return fmt.Errorf("%d %s: %s", status, problem.Title, *problem.Detail)
+		}
+		return fmt.Errorf("%d %s", status, problem.Title)
+	}
+	return fmt.Errorf("unexpected status %d: %s", status, bytes.TrimSpace(body))
+}
+
+// printJSON writes v to w as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// readInput reads the file at path, - reads standard input
+func readInput(cmd *cobra.Command, path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// t3ctl runs the command line args against server and returns what it printed
+func t3ctl(server *httptest.Server, stdin string, args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetArgs(append([]string{"--target", server.URL}, args...))
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSchemaCommands(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	mux.HandleFunc("/schemas", rest.GetAllSchemasHandler(store))
+	mux.HandleFunc("/schemas/search", rest.SearchSchemasHandler(store))
+	server := httptest.NewServer(rest.ErrorMiddleware(mux))
+	defer server.Close()
+
+	schema := `{"type":"object","required":["id"],"properties":{"id":{"type":"integer"}}}`
+	out, err := t3ctl(server, schema, "schema", "register", "--name", "orders", "--version", "1.0.0", "-f", "-")
+	if !assert.NoError(t, err) {
+		return
+	}
+	id := strings.TrimSpace(out)
+
+	_, err = t3ctl(server, schema, "schema", "register", "--name", "orders", "--version", "1.0.0", "-f", "-")
+	assert.ErrorContains(t, err, "already registered with id "+id)
+
+	out, err = t3ctl(server, "", "schema", "get", id)
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"Version": "1.0.0"`)
+	fingerprint, err := validate.SchemaFingerprint("json", schema)
+	assert.NoError(t, err)
+	out, err = t3ctl(server, "", "schema", "get", "--fingerprint", fingerprint)
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"Fingerprint": "`+fingerprint+`"`)
+	out, err = t3ctl(server, "", "schema", "get", id, "--format", "canonical")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"SchemaData": "{\"properties\":{\"id\":{\"type\":\"integer\"}},\"required\":[\"id\"],\"type\":\"object\"}"`)
+
+	out, err = t3ctl(server, "", "schema", "list")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "orders")
+	assert.True(t, strings.HasPrefix(out, "ID"), "lists print a table")
+
+	out, err = t3ctl(server, "", "schema", "search", "id", "--data")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "orders")
+	assert.Contains(t, out, "data")
+
+	out, err = t3ctl(server, `{"id":1}`, "validate", "--schema", "orders:json:1.0.0", "-f", "-")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"valid": true`)
+	out, err = t3ctl(server, `{"id":"x"}`, "validate", "--schema", "orders:json:latest", "-f", "-")
+	assert.Error(t, err)
+	assert.Contains(t, out, `"pointer": "/id"`)
+
+	_, err = t3ctl(server, "", "schema", "delete", id)
+	assert.NoError(t, err)
+	_, err = t3ctl(server, "", "schema", "get", id)
+	assert.ErrorContains(t, err, "404")
+
+	_, err = t3ctl(server, "", "schema", "delete")
+	assert.Error(t, err, "delete needs an id or a name and version")
+}
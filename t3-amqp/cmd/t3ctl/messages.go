@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+	"time"
+)
+
+// payload turns the contents of a payload file into the JSON the API expects, XML
+// documents for xsd schemas travel as a JSON string
+func payload(schemaType string, data []byte) (json.RawMessage, error) {
+	if schemaType == "xsd" {
+		return json.Marshal(string(data))
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("payload is not valid JSON")
+	}
+	return data, nil
+}
+
+func newPublishCmd(a *api) *cobra.Command {
+	var ref schemaRef
+	var topic, file string
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Validate a payload against a schema and publish it to a topic",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if topic == "" || ref.name == "" || ref.version == "" || file == "" {
+				return fmt.Errorf("--topic, --name, --version and --file are required")
+			}
+			data, err := readInput(cmd, file)
+			if err != nil {
+				return err
+			}
+			body, err := payload(ref.schemaType, data)
+			if err != nil {
+				return err
+			}
+			req := map[string]interface{}{
+				"topic": topic, "name": ref.name, "type": ref.schemaType, "version": ref.version, "payload": body,
+			}
+			var resp json.RawMessage
+			if err := a.do(cmd.Context(), http.MethodPost, "/publish", req, &resp); err != nil {
+				return err
+			}
+			return printJSON(cmd.OutOrStdout(), resp)
+		},
+	}
+	ref.register(cmd, "json")
+	cmd.Flags().StringVar(&topic, "topic", "", "topic to publish to")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "file holding the payload, - reads standard input")
+	return cmd
+}
+
+func newConsumeCmd(a *api) *cobra.Command {
+	var ref schemaRef
+	var exchange, topic string
+	var maxMessages int
+	var duration, poll time.Duration
+	cmd := &cobra.Command{
+		Use:   "consume",
+		Short: "Consume a topic, validating every message, and print the report",
+		Long: "Consume a topic, validating every message against the schema named in its headers " +
+			"or the one given, and print the report once max messages were received or the " +
+			"duration passed. t3ctl exits with an error when a message did not validate.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if topic == "" {
+				return fmt.Errorf("--topic is required")
+			}
+			if maxMessages <= 0 && duration <= 0 {
+				return fmt.Errorf("--max or --duration is required")
+			}
+			req := map[string]interface{}{
+				"exchange": exchange, "topic": topic, "max": maxMessages, "durationNs": duration,
+			}
+			if ref.name != "" {
+				req["schema"] = map[string]string{"name": ref.name, "type": ref.schemaType, "version": ref.version}
+			}
+
+			var report struct {
+				ID       string     `json:"id"`
+				Finished *time.Time `json:"finished"`
+				Received int        `json:"received"`
+				Invalid  int        `json:"invalid"`
+				Error    string     `json:"error"`
+			}
+			var raw json.RawMessage
+			if err := a.do(cmd.Context(), http.MethodPost, "/verify", req, &raw); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(raw, &report); err != nil {
+				return err
+			}
+			for report.Finished == nil {
+				select {
+				case <-time.After(poll):
+				case <-cmd.Context().Done():
+					return cmd.Context().Err()
+				}
+				if err := a.do(cmd.Context(), http.MethodGet, "/verify/"+report.ID, nil, &raw); err != nil {
+					return err
+				}
+				if err := json.Unmarshal(raw, &report); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "received %d messages, %d invalid\n", report.Received, report.Invalid)
+			}
+			if err := printJSON(cmd.OutOrStdout(), raw); err != nil {
+				return err
+			}
+			switch {
+			case report.Error != "":
+				return fmt.Errorf("verification %s failed: %s", report.ID, report.Error)
+			case report.Invalid > 0:
+				return fmt.Errorf("%d of %d messages did not validate", report.Invalid, report.Received)
+			}
+			return nil
+		},
+	}
+	ref.register(cmd, "json")
+	cmd.Flags().StringVar(&exchange, "exchange", "t3.topics", "exchange the topic is bound on")
+	cmd.Flags().StringVar(&topic, "topic", "", "topic, a routing key pattern on the exchange")
+	cmd.Flags().IntVar(&maxMessages, "max", 0, "stop after this many messages")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "stop after this long")
+	cmd.Flags().DurationVar(&poll, "poll", 2*time.Second, "interval the report is polled at")
+	return cmd
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runStatus holds the fields of a test run t3ctl looks at, runs are printed as received
+type runStatus struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+func newScenarioCmd(a *api) *cobra.Command {
+	cmd := &cobra.Command{Use: "scenario", Short: "List and run topic test scenarios"}
+	cmd.AddCommand(newScenarioListCmd(a), newScenarioRunCmd(a))
+	return cmd
+}
+
+func newScenarioListCmd(a *api) *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the stored scenarios as a table",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var scenarios []struct {
+				ID           int    `json:"id"`
+				Name         string `json:"name"`
+				Topic        string `json:"topic"`
+				MessageCount int    `json:"messageCount"`
+				Mode         string `json:"mode"`
+				Schema       struct {
+					Name    string `json:"name"`
+					Type    string `json:"type"`
+					Version string `json:"version"`
+				} `json:"schema"`
+			}
+			var raw json.RawMessage
+			if err := a.do(cmd.Context(), http.MethodGet, "/scenarios", nil, &raw); err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), raw)
+			}
+			if err := json.Unmarshal(raw, &scenarios); err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tNAME\tTOPIC\tSCHEMA\tMESSAGES\tMODE")
+			for _, s := range scenarios {
+				fmt.Fprintf(
+					tw, "%d\t%s\t%s\t%s/%s/%s\t%d\t%s\n",
+					s.ID, s.Name, s.Topic, s.Schema.Name, s.Schema.Type, s.Schema.Version, s.MessageCount, s.Mode,
+				)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON instead of a table")
+	return cmd
+}
+
+func newScenarioRunCmd(a *api) *cobra.Command {
+	var wait bool
+	var poll time.Duration
+	cmd := &cobra.Command{
+		Use:   "run <id>",
+		Short: "Start a run of a stored scenario and print it",
+		Long: "Start a run of a stored scenario and print it. With --wait the run is polled until " +
+			"it finished and t3ctl exits with an error when it failed.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid scenario id %q", args[0])
+			}
+			var raw json.RawMessage
+			err = a.do(cmd.Context(), http.MethodPost, fmt.Sprintf("/scenarios/%d/run", id), nil, &raw)
+			if err != nil {
+				return err
+			}
+			if wait {
+				if raw, err = a.waitForRun(cmd.Context(), raw, poll); err != nil {
+					return err
+				}
+			}
+			if err := printJSON(cmd.OutOrStdout(), raw); err != nil {
+				return err
+			}
+
+			var run runStatus
+			if err := json.Unmarshal(raw, &run); err != nil {
+				return err
+			}
+			if wait && run.Status != "passed" {
+				return fmt.Errorf("run %d %s", run.ID, run.Status)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&wait, "wait", false, "wait for the run to finish")
+	cmd.Flags().DurationVar(&poll, "poll", 2*time.Second, "interval the run is polled at with --wait")
+	return cmd
+}
+
+// waitForRun polls the run in raw until it no longer runs and returns its final state
+func (a *api) waitForRun(ctx context.Context, raw json.RawMessage, poll time.Duration) (json.RawMessage, error) {
+	var run runStatus
+	if err := json.Unmarshal(raw, &run); err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for run.Status == "running" {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err := a.do(ctx, http.MethodGet, fmt.Sprintf("/runs/%d", run.ID), nil, &raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &run); err != nil {
+			return nil, err
+		}
+	}
+	return raw, nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"net/http"
+	"strconv"
+	"strings"
+	"t3-amqp/t3client"
+	"text/tabwriter"
+)
+
+// schemaRef holds the flags naming a schema
+type schemaRef struct {
+	name, schemaType, version string
+}
+
+func (s *schemaRef) register(cmd *cobra.Command, defaultType string) {
+	cmd.Flags().StringVar(&s.name, "name", "", "schema name")
+	cmd.Flags().StringVar(&s.schemaType, "type", defaultType, "schema type, json, avro, xsd or protobuf")
+	cmd.Flags().StringVar(&s.version, "version", "", "schema version, latest for the newest active one")
+}
+
+func newSchemaCmd(a *api) *cobra.Command {
+	cmd := &cobra.Command{Use: "schema", Short: "Get, list, search, register and delete schemas"}
+	cmd.AddCommand(
+		newSchemaGetCmd(a), newSchemaListCmd(a), newSchemaSearchCmd(a), newSchemaRegisterCmd(a), newSchemaDeleteCmd(a),
+	)
+	return cmd
+}
+
+func newSchemaGetCmd(a *api) *cobra.Command {
+	var ref schemaRef
+	var format, fingerprint string
+	cmd := &cobra.Command{
+		Use:   "get [id]",
+		Short: "Print a schema by ID, or the schemas matching name, type, version and fingerprint",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				id, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid schema id %q", args[0])
+				}
+				params := &t3client.GetSchemaByIdParams{}
+				if format != "" {
+					params.Format = (*t3client.GetSchemaByIdParamsFormat)(&format)
+				}
+				resp, err := client.GetSchemaByIdWithResponse(cmd.Context(), id, params)
+				if err != nil {
+					return err
+				}
+				if resp.JSON200 == nil {
+					return statusError(resp.StatusCode(), resp.Body)
+				}
+				return printJSON(cmd.OutOrStdout(), resp.JSON200)
+			}
+
+			if ref.name == "" && fingerprint == "" {
+				return fmt.Errorf("an id, --name or --fingerprint is required")
+			}
+			params := &t3client.GetSchemasParams{}
+			if ref.name != "" {
+				params.Name = &ref.name
+			}
+			if fingerprint != "" {
+				params.Fingerprint = &fingerprint
+			}
+			if ref.schemaType != "" {
+				params.Type = &ref.schemaType
+			}
+			if ref.version != "" {
+				params.Version = &ref.version
+			}
+			resp, err := client.GetSchemasWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			if resp.JSON200 == nil {
+				return statusError(resp.StatusCode(), resp.Body)
+			}
+			return printJSON(cmd.OutOrStdout(), resp.JSON200)
+		},
+	}
+	ref.register(cmd, "")
+	cmd.Flags().StringVar(&format, "format", "", "format the schema data of a schema by ID, pretty or canonical")
+	cmd.Flags().StringVar(&fingerprint, "fingerprint", "", "SHA-256 or Rabin fingerprint of the schema content")
+	return cmd
+}
+
+func newSchemaListCmd(a *api) *cobra.Command {
+	var limit, offset int
+	var sort string
+	var asJSON, deleted bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List schemas as a table",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+			params := &t3client.ListSchemasParams{Limit: &limit, Offset: &offset}
+			if sort != "" {
+				params.Sort = &sort
+			}
+			if deleted {
+				params.IncludeDeleted = &deleted
+			}
+			resp, err := client.ListSchemasWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			if resp.JSON200 == nil {
+				return statusError(resp.StatusCode(), resp.Body)
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), resp.JSON200)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tNAME\tTYPE\tVERSION\tSTATUS\tMODIFIED")
+			for _, s := range *resp.JSON200 {
+				fmt.Fprintf(
+					tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+					s.ID, s.Name, s.Type, s.Version, s.Status, s.Modified.Format("2006-01-02 15:04"),
+				)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 100, "page size")
+	cmd.Flags().IntVar(&offset, "offset", 0, "schemas to skip")
+	cmd.Flags().StringVar(&sort, "sort", "", "comma separated columns, a leading - sorts descending")
+	cmd.Flags().BoolVar(&deleted, "include-deleted", false, "also list soft-deleted schemas")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON instead of a table")
+	return cmd
+}
+
+func newSchemaSearchCmd(a *api) *cobra.Command {
+	var schemaType string
+	var limit int
+	var inData, asJSON bool
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Search schemas by name, and with --data by the field names in their documents",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+			params := &t3client.SearchSchemasParams{Q: args[0], Data: &inData, Limit: &limit}
+			if schemaType != "" {
+				params.Type = &schemaType
+			}
+			resp, err := client.SearchSchemasWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			if resp.JSON200 == nil {
+				return statusError(resp.StatusCode(), resp.Body)
+			}
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), resp.JSON200)
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tNAME\tTYPE\tVERSION\tSTATUS\tMATCHED")
+			for _, h := range *resp.JSON200 {
+				matched := make([]string, len(h.Matched))
+				for i, m := range h.Matched {
+					matched[i] = string(m)
+				}
+				fmt.Fprintf(
+					tw, "%d\t%s\t%s\t%s\t%s\t%s\n", h.Id, h.Name, h.Type, h.Version, h.Status, strings.Join(matched, ","),
+				)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&schemaType, "type", "", "only search schemas of this type")
+	cmd.Flags().BoolVar(&inData, "data", false, "also search inside schema documents")
+	cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of hits")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print JSON instead of a table")
+	return cmd
+}
+
+func newSchemaRegisterCmd(a *api) *cobra.Command {
+	var ref schemaRef
+	var file string
+	cmd := &cobra.Command{
+		Use:   "register",
+		Short: "Register a schema document and print its ID",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if ref.name == "" || ref.version == "" || file == "" {
+				return fmt.Errorf("--name, --version and --file are required")
+			}
+			data, err := readInput(cmd, file)
+			if err != nil {
+				return err
+			}
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.CreateSchemaWithResponse(cmd.Context(), t3client.SchemaRequest{
+				Name: ref.name, Type: ref.schemaType, Version: ref.version, SchemaData: string(data),
+			})
+			if err != nil {
+				return err
+			}
+			switch {
+			case resp.JSON201 != nil:
+				fmt.Fprintln(cmd.OutOrStdout(), resp.JSON201.Id)
+				return nil
+			case resp.JSON409 != nil:
+				return fmt.Errorf(
+					"%s/%s/%s is already registered with id %d", ref.name, ref.schemaType, ref.version, resp.JSON409.Id,
+				)
+			default:
+				return statusError(resp.StatusCode(), resp.Body)
+			}
+		},
+	}
+	ref.register(cmd, "json")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "file holding the schema document, - reads standard input")
+	return cmd
+}
+
+func newSchemaDeleteCmd(a *api) *cobra.Command {
+	var ref schemaRef
+	cmd := &cobra.Command{
+		Use:   "delete [id]",
+		Short: "Delete a schema by ID or by name, type and version",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := &t3client.DeleteSchemaParams{}
+			switch {
+			case len(args) == 1:
+				id, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid schema id %q", args[0])
+				}
+				params.Id = &id
+			case ref.name != "" && ref.version != "":
+				params.Name, params.Type, params.Version = &ref.name, &ref.schemaType, &ref.version
+			default:
+				return fmt.Errorf("an id or --name and --version are required")
+			}
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.DeleteSchemaWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNoContent {
+				return statusError(resp.StatusCode(), resp.Body)
+			}
+			return nil
+		},
+	}
+	ref.register(cmd, "json")
+	return cmd
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"strings"
+	"t3-amqp/t3client"
+	"t3-amqp/validate"
+)
+
+// validation is the report validate prints
+type validation struct {
+	Schema   string             `json:"schema"`
+	Valid    bool               `json:"valid"`
+	Problems []validate.Problem `json:"problems,omitempty"`
+}
+
+func newValidateCmd(a *api) *cobra.Command {
+	var spec, schemaFile, schemaType, file string
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a payload file against a schema without a broker",
+		Long: "Validate a payload file against a registered schema, given as --schema name:type:version, " +
+			"or against a schema document read from --schema-file. Validation happens locally and " +
+			"every problem is reported with the JSON pointer of the failing value, t3ctl exits with " +
+			"an error when the payload is invalid.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if file == "" || (spec == "") == (schemaFile == "") {
+				return fmt.Errorf("--file and either --schema or --schema-file are required")
+			}
+			var schemaData string
+			if spec != "" {
+				ref, err := parseSchemaSpec(spec)
+				if err != nil {
+					return err
+				}
+				schemaType = ref.schemaType
+				if schemaData, err = a.fetchSchema(cmd.Context(), ref); err != nil {
+					return err
+				}
+			} else {
+				data, err := readInput(cmd, schemaFile)
+				if err != nil {
+					return err
+				}
+				spec, schemaData = schemaFile, string(data)
+			}
+			data, err := readInput(cmd, file)
+			if err != nil {
+				return err
+			}
+
+			validator, err := validate.Compile(schemaType, schemaData)
+			if err != nil {
+				return err
+			}
+			problems := validate.Problems(validator.Validate(data))
+			report := validation{Schema: spec, Valid: len(problems) == 0, Problems: problems}
+			if err := printJSON(cmd.OutOrStdout(), report); err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("payload does not match %s, %d problems", spec, len(problems))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&spec, "schema", "", "registered schema as name:type:version, version may be latest")
+	cmd.Flags().StringVar(&schemaFile, "schema-file", "", "file holding the schema document instead of --schema")
+	cmd.Flags().StringVar(&schemaType, "type", "json", "type of --schema-file, json, avro or xsd")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "file holding the payload, - reads standard input")
+	return cmd
+}
+
+// parseSchemaSpec splits a name:type:version reference
+func parseSchemaSpec(spec string) (schemaRef, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return schemaRef{}, fmt.Errorf("invalid schema %q, want name:type:version", spec)
+	}
+	return schemaRef{name: parts[0], schemaType: parts[1], version: parts[2]}, nil
+}
+
+// fetchSchema returns the schema document registered as ref
+func (a *api) fetchSchema(ctx context.Context, ref schemaRef) (string, error) {
+	client, err := a.client()
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.GetSchemasWithResponse(ctx, &t3client.GetSchemasParams{
+		Name: &ref.name, Type: &ref.schemaType, Version: &ref.version,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.JSON200 == nil {
+		return "", statusError(resp.StatusCode(), resp.Body)
+	}
+	schema, err := resp.JSON200.AsSchema()
+	if err != nil {
+		return "", fmt.Errorf("unexpected response for %s/%s/%s: %w", ref.name, ref.schemaType, ref.version, err)
+	}
+	return schema.SchemaData, nil
+}
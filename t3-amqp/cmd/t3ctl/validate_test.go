@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCommandOffline(t *testing.T) {
+	// Nothing listens here, a schema file needs no registry
+	server := &httptest.Server{URL: "http://127.0.0.1:1"}
+
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "order.json")
+	err := os.WriteFile(schema, []byte(`{
+		"type": "object",
+		"required": ["id", "lines"],
+		"properties": {
+			"id": {"type": "integer"},
+			"lines": {"type": "array", "items": {"type": "object", "required": ["sku"]}}
+		}
+	}`), 0o600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out, err := t3ctl(server, `{"id": 1, "lines": [{"sku": "a"}]}`, "validate", "--schema-file", schema, "-f", "-")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"valid": true`)
+
+	out, err = t3ctl(server, `{"id": "x", "lines": [{}]}`, "validate", "--schema-file", schema, "-f", "-")
+	assert.ErrorContains(t, err, "2 problems")
+	var report validation
+	if !assert.NoError(t, json.Unmarshal([]byte(out), &report)) {
+		return
+	}
+	assert.False(t, report.Valid)
+	var pointers []string
+	for _, p := range report.Problems {
+		pointers = append(pointers, p.Pointer)
+	}
+	assert.ElementsMatch(t, []string{"/id", "/lines/0"}, pointers)
+
+	avro := filepath.Join(dir, "order.avsc")
+	err = os.WriteFile(avro, []byte(`{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`), 0o600)
+	if !assert.NoError(t, err) {
+		return
+	}
+	out, err = t3ctl(server, `{"id": "x"}`, "validate", "--schema-file", avro, "--type", "avro", "-f", "-")
+	assert.Error(t, err)
+	report = validation{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &report))
+	if assert.Len(t, report.Problems, 1) {
+		assert.Empty(t, report.Problems[0].Pointer, "avro problems have no pointer")
+		assert.Contains(t, report.Problems[0].Message, "does not match avro schema")
+	}
+
+	_, err = t3ctl(server, "{}", "validate", "--schema", "orders:json", "-f", "-")
+	assert.ErrorContains(t, err, "name:type:version")
+	_, err = t3ctl(server, "{}", "validate", "-f", "-")
+	assert.Error(t, err, "a schema is required")
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+var (
+	ErrAliasNotFound = NewError(ErrNotFound, "alias not found")
+	ErrAliasConflict = NewError(ErrConflict, "alias collides with an existing schema name or alias")
+)
+
+// canonicalName resolves the @name argument through the aliases of the @tenant argument
+// so every lookup by name also accepts an alias
+const canonicalName = "COALESCE((SELECT a.name FROM s1.schema_alias a " +
+	"WHERE a.tenant_id = @tenant AND a.alias = @name), @name)"
+
+// ResolveSchemaName returns the canonical schema name for name in tenant, which is name
+// itself when it is not an alias
+func ResolveSchemaName(pool *pgxpool.Pool, tenant, name string) (string, error) {
+	var canonical string
+	args := pgx.NamedArgs{"name": name, "tenant": tenantOrDefault(tenant)}
+	err := pool.QueryRow(context.Background(), "SELECT "+canonicalName, cached(pool, args)...).Scan(&canonical)
+	if err != nil {
+		return "", fmt.Errorf("error resolving schema name: %w", err)
+	}
+	return canonical, nil
+}
+
+// CreateAlias points alias at the schema of tenant called name. Aliases of aliases are
+// resolved to the canonical name, and an alias may not shadow an existing schema name.
+func CreateAlias(pool *pgxpool.Pool, tenant, alias, name string) (*Alias, error) {
+	tenant = tenantOrDefault(tenant)
+	canonical, err := ResolveSchemaName(pool, tenant, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var exists, shadows bool
+	err = pool.QueryRow(
+		context.Background(),
+		`SELECT EXISTS (SELECT 1 FROM s1.schema WHERE tenant_id = @tenant AND name = @name AND `+liveSchema+`),
+		        EXISTS (SELECT 1 FROM s1.schema WHERE tenant_id = @tenant AND name = @alias AND `+liveSchema+`)`,
+		pgx.NamedArgs{"name": canonical, "alias": alias, "tenant": tenant},
+	).Scan(&exists, &shadows)
+	if err != nil {
+		return nil, fmt.Errorf("error checking alias target: %w", err)
+	}
+	if !exists {
+		return nil, ErrSchemaNotFound
+	}
+	if shadows || alias == canonical {
+		return nil, ErrAliasConflict
+	}
+
+	created := Alias{Alias: alias, Name: canonical, Created: time.Now().UTC()}
+	_, err = pool.Exec(
+		context.Background(),
+		`INSERT INTO s1.schema_alias (alias, name, created, tenant_id) VALUES (@alias, @name, @created, @tenant)`,
+		pgx.NamedArgs{"alias": created.Alias, "name": created.Name, "created": created.Created, "tenant": tenant},
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrAliasConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating alias: %w", err)
+	}
+	return &created, nil
+}
+
+// DeleteAlias removes alias of tenant, the schema it points at is untouched
+func DeleteAlias(pool *pgxpool.Pool, tenant, alias string) error {
+	tag, err := pool.Exec(
+		context.Background(), `DELETE FROM s1.schema_alias WHERE tenant_id = @tenant AND alias = @alias`,
+		pgx.NamedArgs{"alias": alias, "tenant": tenantOrDefault(tenant)},
+	)
+	if err != nil {
+		return fmt.Errorf("error deleting alias: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAliasNotFound
+	}
+	return nil
+}
+
+// ListAliases returns all aliases of tenant, optionally only those pointing at name,
+// ordered by alias
+func ListAliases(pool *pgxpool.Pool, tenant, name string) ([]Alias, error) {
+	query := `SELECT alias, name, created FROM s1.schema_alias WHERE tenant_id = @tenant`
+	args := pgx.NamedArgs{"tenant": tenantOrDefault(tenant)}
+	if name != "" {
+		query += ` AND name = ` + canonicalName
+		args["name"] = name
+	}
+	query += ` ORDER BY alias`
+
+	rows, err := pool.Query(context.Background(), query, args)
+	if err != nil {
+		return nil, fmt.Errorf("error querying aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []Alias{}
+	for rows.Next() {
+		var a Alias
+		if err := rows.Scan(&a.Alias, &a.Name, &a.Created); err != nil {
+			return nil, fmt.Errorf("error scanning alias: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+
+	return aliases, rows.Err()
+}
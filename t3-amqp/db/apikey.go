@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound = NewError(ErrNotFound, "api key not found")
+	ErrAPIKeyConflict = NewError(ErrConflict, "an api key with this prefix already exists")
+)
+
+// APIKeyStore is implemented by stores that hold API keys
+type APIKeyStore interface {
+	CreateAPIKey(key APIKey) (*APIKey, error)
+	// APIKeyByPrefix returns the key with prefix, revoked keys included
+	APIKeyByPrefix(prefix string) (*APIKey, error)
+	APIKeys() ([]APIKey, error)
+	RevokeAPIKey(id int) error
+}
+
+const apiKeyColumns = "id, name, prefix, scope, secret_hash, created, revoked_at, namespaces, COALESCE(tenant_id, '')"
+
+func scanAPIKey(row pgx.Row) (APIKey, error) {
+	var key APIKey
+	err := row.Scan(
+		&key.ID, &key.Name, &key.Prefix, &key.Scope, &key.SecretHash, &key.Created, &key.RevokedAt,
+		&key.Namespaces, &key.Tenant,
+	)
+	return key, err
+}
+
+// CreateAPIKey stores key and returns it with its ID and creation time
+func CreateAPIKey(pool *pgxpool.Pool, key APIKey) (*APIKey, error) {
+	args := pgx.NamedArgs{
+		"name":        key.Name,
+		"prefix":      key.Prefix,
+		"scope":       key.Scope,
+		"secret_hash": key.SecretHash,
+		"created":     time.Now().UTC(),
+		"namespaces":  append([]string{}, key.Namespaces...),
+		"tenant":      key.Tenant,
+	}
+
+	query := `INSERT INTO s1.api_key (name, prefix, scope, secret_hash, created, namespaces, tenant_id)
+			VALUES (@name, @prefix, @scope, @secret_hash, @created, @namespaces, NULLIF(@tenant, ''))
+			RETURNING ` + apiKeyColumns
+	created, err := scanAPIKey(pool.QueryRow(context.Background(), query, args))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrAPIKeyConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating api key: %w", err)
+	}
+	return &created, nil
+}
+
+// GetAPIKeyByPrefix retrieves the key with prefix, revoked or not
+func GetAPIKeyByPrefix(pool *pgxpool.Pool, prefix string) (*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM s1.api_key WHERE prefix = @prefix`
+	key, err := scanAPIKey(pool.QueryRow(context.Background(), query, pgx.NamedArgs{"prefix": prefix}))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting api key: %w", err)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys retrieves every key, revoked ones included, ordered by ID
+func ListAPIKeys(pool *pgxpool.Pool) ([]APIKey, error) {
+	rows, err := pool.Query(context.Background(), `SELECT `+apiKeyColumns+` FROM s1.api_key ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	keys, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (APIKey, error) { return scanAPIKey(row) })
+	if err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops the key with id from authenticating, ErrAPIKeyNotFound when no
+// unrevoked key has the ID
+func RevokeAPIKey(pool *pgxpool.Pool, id int) error {
+	tag, err := pool.Exec(
+		context.Background(), `UPDATE s1.api_key SET revoked_at = @now WHERE id = @id AND revoked_at IS NULL`,
+		pgx.NamedArgs{"id": id, "now": time.Now().UTC()},
+	)
+	if err != nil {
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
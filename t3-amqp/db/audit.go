@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
+	"time"
+)
+
+const (
+	AuditActionInsert    = "insert"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionRestore   = "restore"
+	AuditActionDeprecate = "deprecate"
+	AuditActionRetire    = "retire"
+	AuditActionAlias     = "alias"
+	AuditActionUnalias   = "unalias"
+
+	DefaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// Snapshot returns the audit snapshot of schema, nil for a nil schema
+func Snapshot(schema *Schema) *SchemaSnapshot {
+	if schema == nil {
+		return nil
+	}
+	snapshot := &SchemaSnapshot{
+		ID: schema.ID, Name: schema.Name, Type: schema.Type, Version: schema.Version, Status: schema.Status,
+		Fingerprint: schema.Fingerprint, DeprecateAt: schema.DeprecateAt, RetireAt: schema.RetireAt,
+		DeletedAt: schema.DeletedAt,
+	}
+	if json.Valid([]byte(schema.SchemaData)) {
+		snapshot.SchemaData = json.RawMessage(schema.SchemaData)
+	} else if schema.SchemaData != "" {
+		snapshot.SchemaData, _ = json.Marshal(schema.SchemaData)
+	}
+	return snapshot
+}
+
+// RecordAudit inserts an entry into the s1.audit_log table
+func RecordAudit(pool *pgxpool.Pool, entry AuditEntry) error {
+	args := pgx.NamedArgs{
+		"actor":       entry.Actor,
+		"action":      entry.Action,
+		"schema_id":   entry.SchemaID,
+		"schema_name": entry.SchemaName,
+		"source_ip":   entry.SourceIP,
+		"before":      entry.Before,
+		"after":       entry.After,
+		"tenant":      tenantOrDefault(entry.Tenant),
+		"created":     time.Now().UTC(),
+	}
+
+	query := `INSERT INTO s1.audit_log (actor, action, schema_id, schema_name, source_ip, before, after, tenant_id,
+			                           created)
+			VALUES (@actor, @action, NULLIF(@schema_id, 0), @schema_name, NULLIF(@source_ip, ''), @before, @after,
+			        @tenant, @created)`
+	_, err := pool.Exec(context.Background(), query, args)
+	if err != nil {
+		return fmt.Errorf("error recording audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries retrieves the audit entries of the filter's tenant matching the
+// optional filter, newest first. It also returns the total number of matching entries
+// for pagination.
+func ListAuditEntries(pool *pgxpool.Pool, filter AuditFilter) ([]AuditEntry, int, error) {
+	conditions := []string{"tenant_id = @tenant"}
+	args := pgx.NamedArgs{"tenant": tenantOrDefault(filter.Tenant)}
+
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = @actor")
+		args["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = @action")
+		args["action"] = filter.Action
+	}
+	if filter.SchemaName != "" {
+		conditions = append(conditions, "schema_name = @schema_name")
+		args["schema_name"] = filter.SchemaName
+	}
+	if filter.SchemaID != 0 {
+		conditions = append(conditions, "schema_id = @schema_id")
+		args["schema_id"] = filter.SchemaID
+	}
+	if filter.SourceIP != "" {
+		conditions = append(conditions, "source_ip = @source_ip")
+		args["source_ip"] = filter.SourceIP
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created >= @from")
+		args["from"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created < @to")
+		args["to"] = filter.To
+	}
+	if len(filter.Namespaces) > 0 {
+		conditions = append(conditions, "split_part(schema_name, '.', 1) = ANY(@namespaces)")
+		args["namespaces"] = filter.Namespaces
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	err := pool.QueryRow(context.Background(), "SELECT count(*) FROM s1.audit_log"+where, args).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting audit entries: %w", err)
+	}
+
+	args["limit"] = AuditLimit(filter.Limit)
+	args["offset"] = max(filter.Offset, 0)
+	query := `SELECT id, actor, action, COALESCE(schema_id, 0), COALESCE(schema_name, ''), COALESCE(source_ip, ''),
+		       before, after, tenant_id, created
+		FROM s1.audit_log` + where + ` ORDER BY created DESC, id DESC LIMIT @limit OFFSET @offset`
+
+	rows, err := pool.Query(context.Background(), query, args)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		err := rows.Scan(
+			&entry.ID, &entry.Actor, &entry.Action, &entry.SchemaID, &entry.SchemaName, &entry.SourceIP,
+			&entry.Before, &entry.After, &entry.Tenant, &entry.Created,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// AuditLimit is the number of audit entries a page with limit holds: DefaultAuditLimit
+// for a non-positive limit and at most 1000
+func AuditLimit(limit int) int {
+	return clampLimit(limit, DefaultAuditLimit, maxAuditLimit)
+}
+
+// clampLimit applies a default to a non-positive limit and caps it at max
+func clampLimit(limit, def, maxLimit int) int {
+	if limit <= 0 {
+		return def
+	}
+	return min(limit, maxLimit)
+}
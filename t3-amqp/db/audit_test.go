@@ -0,0 +1,18 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	assert.Nil(t, Snapshot(nil))
+
+	snapshot := Snapshot(&Schema{ID: 3, Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`})
+	assert.Equal(t, 3, snapshot.ID)
+	assert.JSONEq(t, `{"type":"object"}`, string(snapshot.SchemaData), "JSON documents are kept as they are")
+
+	snapshot = Snapshot(&Schema{Name: "orders", Type: "protobuf", SchemaData: `syntax = "proto3";`})
+	assert.Equal(t, `"syntax = \"proto3\";"`, string(snapshot.SchemaData), "other documents become JSON strings")
+}
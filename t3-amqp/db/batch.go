@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+// BatchInserter is implemented by stores that register many schemas at once. The batch
+// is all or nothing, IDs are returned in the order of params.
+type BatchInserter interface {
+	InsertBatch(params []QueryArgs) ([]int, error)
+}
+
+// BatchInsertSchemas inserts every schema of params in one transaction, sending all
+// inserts to the database in a single round trip. Nothing is written when one of them
+// fails, a version that is already registered fails the batch with ErrAlreadyExists.
+func BatchInsertSchemas(pool *pgxpool.Pool, params []QueryArgs) ([]int, error) {
+	if len(params) == 0 {
+		return []int{}, nil
+	}
+	for _, p := range params {
+		if err := CheckSchemaSize(p); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+	batch := &pgx.Batch{}
+	for _, p := range params {
+		batch.Queue(insertSchemaQuery, insertSchemaArgs(p, now))
+	}
+
+	ids := make([]int, len(params))
+	results := tx.SendBatch(ctx, batch)
+	for i, p := range params {
+		err := results.QueryRow().Scan(&ids[i])
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			results.Close()
+			return nil, fmt.Errorf("error inserting schema %s/%s/%s: %w", p.Name, p.Type, p.Version, ErrAlreadyExists)
+		}
+		if err != nil {
+			results.Close()
+			return nil, fmt.Errorf("error inserting schema %s/%s/%s: %w", p.Name, p.Type, p.Version, err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return nil, fmt.Errorf("error inserting schemas: %w", err)
+	}
+	// Linked once the whole batch is in, so schemas may reference others of the batch
+	for i, p := range params {
+		if err := linkReferences(ctx, tx, ids[i], p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing batch insert: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// BlobRef is stored in schema_data in place of a document too large for it, the
+// document itself lives in the blob store under Ref
+type BlobRef struct {
+	Ref    string `json:"$blob"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BlobOpener reads the documents BlobRefs point to
+type BlobOpener interface {
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+var (
+	blobsMu sync.RWMutex
+	blobs   BlobOpener
+)
+
+// SetBlobStore sets the store the schema_data of spilled documents is read from. Until it
+// is set reading a spilled document fails.
+func SetBlobStore(store BlobOpener) {
+	blobsMu.Lock()
+	defer blobsMu.Unlock()
+	blobs = store
+}
+
+// NewBlobRef returns the schema_data standing in for schemaData stored under key
+func NewBlobRef(key, schemaData string) string {
+	sum := sha256.Sum256([]byte(schemaData))
+	ref, _ := json.Marshal(BlobRef{Ref: key, Size: int64(len(schemaData)), SHA256: hex.EncodeToString(sum[:])})
+	return string(ref)
+}
+
+// parseBlobRef returns the BlobRef schemaData holds, if it is one. A BlobRef has exactly
+// its three members, so documents that merely mention $blob are not mistaken for one.
+func parseBlobRef(schemaData string) (BlobRef, bool) {
+	if len(schemaData) > 1024 || !strings.Contains(schemaData, `"$blob"`) {
+		return BlobRef{}, false
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(schemaData), &members); err != nil || len(members) != 3 {
+		return BlobRef{}, false
+	}
+	var ref BlobRef
+	if err := json.Unmarshal([]byte(schemaData), &ref); err != nil || ref.Ref == "" || ref.SHA256 == "" {
+		return BlobRef{}, false
+	}
+	return ref, true
+}
+
+// ResolveSchemaData returns the document schemaData stands for: the document a BlobRef
+// points to, or schemaData itself. The blob is checked against the size and hash of the
+// reference.
+func ResolveSchemaData(schemaData string) (string, error) {
+	ref, ok := parseBlobRef(schemaData)
+	if !ok {
+		return schemaData, nil
+	}
+
+	blobsMu.RLock()
+	store := blobs
+	blobsMu.RUnlock()
+	if store == nil {
+		return "", fmt.Errorf("error reading schema document %s: no blob store is configured", ref.Ref)
+	}
+
+	r, err := store.Open(context.Background(), ref.Ref)
+	if err != nil {
+		return "", fmt.Errorf("error reading schema document %s: %w", ref.Ref, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, ref.Size+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading schema document %s: %w", ref.Ref, err)
+	}
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != ref.Size || hex.EncodeToString(sum[:]) != ref.SHA256 {
+		return "", fmt.Errorf("error reading schema document %s: the blob does not match its reference", ref.Ref)
+	}
+	return string(data), nil
+}
+
+// resolveSchema replaces a BlobRef in the schema_data of schema with its document
+func resolveSchema(schema *Schema) error {
+	data, err := ResolveSchemaData(schema.SchemaData)
+	if err != nil {
+		return err
+	}
+	schema.SchemaData = data
+	return nil
+}
+
+// resolveSchemas resolves the schema_data of every one of schemas in place
+func resolveSchemas(schemas []Schema) error {
+	for i := range schemas {
+		if err := resolveSchema(&schemas[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
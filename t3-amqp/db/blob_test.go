@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapBlobs map[string]string
+
+func (b mapBlobs) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(b[key])), nil
+}
+
+func TestResolveSchemaData(t *testing.T) {
+	t.Cleanup(func() { SetBlobStore(nil) })
+	document := `{"type":"object"}`
+	ref := NewBlobRef("sha256/doc", document)
+
+	SetBlobStore(nil)
+	_, err := ResolveSchemaData(ref)
+	assert.Error(t, err, "spilled documents cannot be read without a blob store")
+
+	SetBlobStore(mapBlobs{"sha256/doc": document, "sha256/other": `{"type":"array"}`})
+	data, err := ResolveSchemaData(ref)
+	assert.NoError(t, err)
+	assert.Equal(t, document, data)
+
+	_, err = ResolveSchemaData(NewBlobRef("sha256/other", document))
+	assert.Error(t, err, "a blob that does not match its reference is refused")
+
+	// Documents are returned as they are, even when they mention $blob
+	for _, data := range []string{document, `{"$blob":"x","title":"t"}`, `"<xs:schema/>"`} {
+		resolved, err := ResolveSchemaData(data)
+		assert.NoError(t, err)
+		assert.Equal(t, data, resolved)
+	}
+
+	// Stores read references back in whatever member order the column keeps them
+	var parsed BlobRef
+	assert.NoError(t, json.Unmarshal([]byte(ref), &parsed))
+	reordered := fmt.Sprintf(`{"size": %d, "$blob": %q, "sha256": %q}`, parsed.Size, parsed.Ref, parsed.SHA256)
+	store := NewMemoryStore()
+	id, err := store.Insert(QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: reordered})
+	assert.NoError(t, err)
+	schema, err := store.GetByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, document, schema.SchemaData)
+	}
+}
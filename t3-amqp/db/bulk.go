@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
+	"time"
+)
+
+var ErrEmptyFilter = NewError(ErrValidation, "at least one filter is required")
+
+// bulkFilterConditions builds the WHERE clause for a bulk filter. An empty filter is
+// rejected so a bulk operation can never target the whole registry by accident.
+func bulkFilterConditions(filter BulkFilter) (string, pgx.NamedArgs, error) {
+	var conditions []string
+	args := pgx.NamedArgs{}
+
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, "starts_with(name, @name_prefix)")
+		args["name_prefix"] = filter.NamePrefix
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = @type")
+		args["type"] = filter.Type
+	}
+	if !filter.OlderThan.IsZero() {
+		conditions = append(conditions, "modified < @older_than")
+		args["older_than"] = filter.OlderThan
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = @status")
+		args["status"] = filter.Status
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, ErrEmptyFilter
+	}
+	conditions = append(conditions, "tenant_id = @tenant", liveSchema)
+	args["tenant"] = tenantOrDefault(filter.Tenant)
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// FindSchemasForBulk returns the schemas matched by filter, ordered by ID
+func FindSchemasForBulk(pool *pgxpool.Pool, filter BulkFilter) ([]Schema, error) {
+	where, args, err := bulkFilterConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + schemaColumns + " FROM s1.schema" +
+		where + " ORDER BY id"
+	rows, err := pool.Query(context.Background(), query, args)
+	if err != nil {
+		return nil, fmt.Errorf("error querying schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []Schema{}
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// DeleteSchemasByIds soft-deletes the live schemas with the given IDs in one transaction
+// and returns how many were deleted. Nothing is deleted with ErrSchemaReferenced when a
+// live schema that is not deleted with them references one of them.
+func DeleteSchemasByIds(pool *pgxpool.Pool, ids []int) (int64, error) {
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var referenced bool
+	if err := tx.QueryRow(ctx, referencedQuery, pgx.NamedArgs{"ids": ids}).Scan(&referenced); err != nil {
+		return 0, fmt.Errorf("error checking schema references: %w", err)
+	}
+	if referenced {
+		return 0, fmt.Errorf("error deleting schemas: %w", ErrSchemaReferenced)
+	}
+
+	now := time.Now().UTC()
+	tag, err := tx.Exec(
+		ctx, `UPDATE s1.schema SET deleted_at = @now, modified = @now WHERE id = ANY(@ids) AND `+liveSchema,
+		pgx.NamedArgs{"ids": ids, "now": now},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting schemas: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("error committing bulk delete: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkFilterConditions(t *testing.T) {
+	_, _, err := bulkFilterConditions(BulkFilter{Tenant: "acme"})
+	assert.ErrorIs(t, err, ErrEmptyFilter, "the tenant alone does not narrow a bulk operation")
+
+	where, args, err := bulkFilterConditions(BulkFilter{Status: StatusRetired, Type: "json"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, where, "status = @status")
+	assert.Contains(t, where, "type = @type")
+	assert.Equal(t, StatusRetired, args["status"])
+	assert.Equal(t, DefaultTenant, args["tenant"])
+}
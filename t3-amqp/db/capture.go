@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Capture statuses, a capture is running until it stopped recording messages
+const (
+	CaptureRunning  = "running"
+	CaptureFinished = "finished"
+	CaptureError    = "error"
+)
+
+var ErrCaptureNotFound = NewError(ErrNotFound, "capture not found")
+
+// CaptureStore is implemented by stores that keep messages captured from topics
+type CaptureStore interface {
+	CreateCapture(c Capture) (*Capture, error)
+	// AddCapturedMessages appends messages to the capture with id and counts them
+	AddCapturedMessages(id int, messages []CapturedMessage) error
+	// FinishCapture stores the status, end time and error of a capture that ended
+	FinishCapture(c Capture) error
+	CaptureByID(id int) (*Capture, error)
+	// Captures returns the captures of tenant without their messages, newest first, those
+	// of every tenant when it is empty
+	Captures(tenant string) ([]Capture, error)
+	// CapturedMessages returns the messages of the capture with id ordered by Seq
+	CapturedMessages(id int) ([]CapturedMessage, error)
+	// DeleteCapture removes the capture together with its messages
+	DeleteCapture(id int) error
+}
+
+const captureColumns = "id, topic, status, started, finished, message_count, COALESCE(error, ''), tenant_id"
+
+const capturedMessageColumns = "seq, received, exchange, routing_key, content_type, message_id, correlation_id, " +
+	"headers, body"
+
+func scanCapture(row pgx.Row) (Capture, error) {
+	var c Capture
+	err := row.Scan(&c.ID, &c.Topic, &c.Status, &c.Started, &c.Finished, &c.Messages, &c.Error, &c.Tenant)
+	return c, err
+}
+
+// CreateCapture records the start of capture c in its tenant
+func CreateCapture(pool *pgxpool.Pool, c Capture) (*Capture, error) {
+	query := `INSERT INTO s1.capture (topic, status, started, tenant_id) VALUES (@topic, @status, @started, @tenant)
+			RETURNING ` + captureColumns
+	args := pgx.NamedArgs{
+		"topic": c.Topic, "status": c.Status, "started": c.Started, "tenant": tenantOrDefault(c.Tenant),
+	}
+	created, err := scanCapture(pool.QueryRow(context.Background(), query, args))
+	if err != nil {
+		return nil, fmt.Errorf("error creating capture: %w", err)
+	}
+	return &created, nil
+}
+
+// AddCapturedMessages stores messages of the capture with id and adds them to its count
+// in one transaction
+func AddCapturedMessages(pool *pgxpool.Pool, id int, messages []CapturedMessage) error {
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(
+		ctx, `UPDATE s1.capture SET message_count = message_count + @count WHERE id = @id`,
+		pgx.NamedArgs{"id": id, "count": len(messages)},
+	)
+	if err != nil {
+		return fmt.Errorf("error counting captured messages: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCaptureNotFound
+	}
+
+	for _, msg := range messages {
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO s1.captured_message (capture_id, seq, received, exchange, routing_key, content_type,
+				message_id, correlation_id, headers, body)
+			VALUES (@capture_id, @seq, @received, @exchange, @routing_key, @content_type,
+				@message_id, @correlation_id, @headers, @body)`,
+			pgx.NamedArgs{
+				"capture_id":     id,
+				"seq":            msg.Seq,
+				"received":       msg.Received,
+				"exchange":       msg.Exchange,
+				"routing_key":    msg.RoutingKey,
+				"content_type":   msg.ContentType,
+				"message_id":     msg.MessageID,
+				"correlation_id": msg.CorrelationID,
+				"headers":        nullableJSON(msg.Headers),
+				"body":           msg.Body,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("error storing captured message %d: %w", msg.Seq, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing captured messages: %w", err)
+	}
+	return nil
+}
+
+// FinishCapture stores the status, end time and error of the capture with c.ID
+func FinishCapture(pool *pgxpool.Pool, c Capture) error {
+	tag, err := pool.Exec(
+		context.Background(),
+		`UPDATE s1.capture SET status = @status, finished = @finished, error = NULLIF(@error, '') WHERE id = @id`,
+		pgx.NamedArgs{"id": c.ID, "status": c.Status, "finished": c.Finished, "error": c.Error},
+	)
+	if err != nil {
+		return fmt.Errorf("error finishing capture: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCaptureNotFound
+	}
+	return nil
+}
+
+// GetCapture retrieves the capture with id
+func GetCapture(pool *pgxpool.Pool, id int) (*Capture, error) {
+	query := `SELECT ` + captureColumns + ` FROM s1.capture WHERE id = @id`
+	c, err := scanCapture(pool.QueryRow(context.Background(), query, pgx.NamedArgs{"id": id}))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCaptureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting capture: %w", err)
+	}
+	return &c, nil
+}
+
+// ListCaptures retrieves the captures of tenant, newest first, every capture when tenant
+// is empty
+func ListCaptures(pool *pgxpool.Pool, tenant string) ([]Capture, error) {
+	rows, err := pool.Query(
+		context.Background(),
+		`SELECT `+captureColumns+` FROM s1.capture WHERE @tenant = '' OR tenant_id = @tenant
+			ORDER BY started DESC, id DESC`,
+		pgx.NamedArgs{"tenant": tenant},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing captures: %w", err)
+	}
+	captures, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Capture, error) { return scanCapture(row) })
+	if err != nil {
+		return nil, fmt.Errorf("error listing captures: %w", err)
+	}
+	return captures, nil
+}
+
+// ListCapturedMessages retrieves the messages of the capture with id in the order they
+// were received, ErrCaptureNotFound when there is no such capture
+func ListCapturedMessages(pool *pgxpool.Pool, id int) ([]CapturedMessage, error) {
+	if _, err := GetCapture(pool, id); err != nil {
+		return nil, err
+	}
+	rows, err := pool.Query(
+		context.Background(),
+		`SELECT `+capturedMessageColumns+` FROM s1.captured_message WHERE capture_id = @id ORDER BY seq`,
+		pgx.NamedArgs{"id": id},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing captured messages: %w", err)
+	}
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (CapturedMessage, error) {
+		var msg CapturedMessage
+		err := row.Scan(
+			&msg.Seq, &msg.Received, &msg.Exchange, &msg.RoutingKey, &msg.ContentType, &msg.MessageID,
+			&msg.CorrelationID, &msg.Headers, &msg.Body,
+		)
+		return msg, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing captured messages: %w", err)
+	}
+	return messages, nil
+}
+
+// DeleteCapture removes the capture with id, its messages are deleted with it
+func DeleteCapture(pool *pgxpool.Pool, id int) error {
+	tag, err := pool.Exec(context.Background(), `DELETE FROM s1.capture WHERE id = @id`, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return fmt.Errorf("error deleting capture: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCaptureNotFound
+	}
+	return nil
+}
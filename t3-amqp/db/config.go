@@ -0,0 +1,123 @@
+package db
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"reflect"
+	"strings"
+)
+
+// Store kinds selectable with --store
+const (
+	StorePostgres = "postgres"
+	StoreMemory   = "memory"
+)
+
+// defaults apply when a setting is in neither the flags, the environment nor the file
+var defaults = map[string]interface{}{
+	"db.host":                 "localhost",
+	"db.port":                 5432,
+	"db.sslmode":              "disable",
+	"db.statement_cache":      512,
+	"db.connect_backoff":      "1s",
+	"db.connect_max_backoff":  "30s",
+	"db.ping_interval":        "15s",
+	"store.kind":              StorePostgres,
+	"store.snapshot_interval": "30s",
+	"server.addr":             "localhost:8080",
+	"server.mode":             "normal",
+	"server.shutdown_timeout": "30s",
+}
+
+// flags are the command line overrides, keyed by configuration key. The type of value
+// is the type of the flag, string when it is nil.
+var flags = []struct {
+	key, name, usage string
+	value            interface{}
+}{
+	{"config_path", "config", "path to the configuration file, overrides CONFIG_PATH", nil},
+	{"db.host", "db-host", "database host", nil},
+	{"db.port", "db-port", "database port", 0},
+	{"db.user", "db-user", "database user", nil},
+	{"db.password", "db-password", "database password", nil},
+	{"db.dbname", "db-name", "database name", nil},
+	{"db.sslmode", "db-sslmode", "database sslmode", nil},
+	{"db.migrate", "migrate", "apply pending database migrations before serving", false},
+	{"store.kind", "store", "schema store, postgres or memory", nil},
+	{"store.snapshot_file", "snapshot", "file the memory store is restored from and snapshotted to", nil},
+	{"store.seed", "seed", "fixture file or directory of schemas and scenarios loaded at startup", nil},
+	{"server.addr", "addr", "address the HTTP server listens on", nil},
+	{"server.grpc_addr", "grpc-addr", "address the gRPC server listens on, off when empty", nil},
+	{"server.mode", "mode", "initial server mode", nil},
+	{"server.tls.cert_file", "tls-cert", "TLS certificate file, serves HTTPS when set with --tls-key", nil},
+	{"server.tls.key_file", "tls-key", "TLS private key file", nil},
+	{"server.tls.client_ca_file", "tls-client-ca", "CA file verifying client certificates for mutual TLS", nil},
+	{"broker.url", "broker-url", "AMQP broker URI", nil},
+}
+
+// RegisterFlags defines the command line overrides on fs and binds them to their keys.
+// Flags take precedence over environment variables, which override the configuration
+// file, which overrides the defaults.
+func RegisterFlags(fs *pflag.FlagSet) error {
+	for _, f := range flags {
+		switch value := f.value.(type) {
+		case int:
+			fs.Int(f.name, value, f.usage)
+		case bool:
+			fs.Bool(f.name, value, f.usage)
+		default:
+			fs.String(f.name, "", f.usage)
+		}
+		if err := viper.BindPFlag(f.key, fs.Lookup(f.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BindEnv binds every key of the mapstructure tagged struct v below prefix to an
+// environment variable named after the key path, so db.host is read from DB_HOST
+func BindEnv(prefix string, v interface{}) error {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Struct && ft.String() != "time.Time" {
+			if err := BindEnv(key, reflect.New(ft).Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+		env := strings.ToUpper(strings.NewReplacer(".", "_").Replace(key))
+		if err := viper.BindEnv(key, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyDefaults() error {
+	for key, value := range defaults {
+		viper.SetDefault(key, value)
+	}
+	if err := viper.BindEnv("config_path", "CONFIG_PATH"); err != nil {
+		return err
+	}
+	// DB_NAME is the conventional spelling, DB_DBNAME follows the key
+	if err := BindEnv("", Config{}); err != nil {
+		return err
+	}
+	return viper.BindEnv("db.dbname", "DB_DBNAME", "DB_NAME")
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte("db:\n  host: filehost\n  user: fileuser\n  dbname: filedb\n"), 0o600)
+	assert.NoError(t, err)
+	t.Setenv("CONFIG_PATH", path)
+	t.Setenv("DB_USER", "envuser")
+	t.Setenv("DB_HOST", "envhost")
+	t.Setenv("DB_NAME", "envdb")
+	t.Setenv("REDACT_FIELDS", "ssn,iban")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	assert.NoError(t, RegisterFlags(fs))
+	assert.NoError(t, fs.Parse([]string{"--db-host=flaghost", "--migrate"}))
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "flaghost", config.DB.Host)
+	assert.Equal(t, "envuser", config.DB.User)
+	assert.Equal(t, "envdb", config.DB.DBName)
+	assert.Equal(t, 5432, config.DB.Port)
+	assert.Equal(t, "localhost:8080", config.Server.Addr)
+	assert.Equal(t, []string{"ssn", "iban"}, config.Redact.Fields)
+	assert.True(t, config.DB.Migrate)
+}
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("DB_PORT", "6543")
+	t.Setenv("SERVER_ADDR", ":8080")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", config.DB.Host)
+	assert.Equal(t, 6543, config.DB.Port)
+	assert.Equal(t, ":8080", config.Server.Addr)
+	assert.Equal(t, 30*time.Second, config.Server.ShutdownTimeout)
+}
@@ -2,12 +2,22 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/viper"
+	"golang.org/x/sync/singleflight"
 	"log"
 	"strings"
+	"sync"
+	"t3-amqp/auth/oidc"
+	"t3-amqp/metrics"
+	"t3-amqp/quota"
+	"t3-amqp/redact"
+	"t3-amqp/tlsconfig"
 	"time"
 )
 
@@ -20,31 +30,141 @@ type Config struct {
 		Password string `mapstructure:"password"`
 		DBName   string `mapstructure:"dbname"`
 		SSLMode  string `mapstructure:"sslmode"`
+		// Migrate applies pending migrations at startup
+		Migrate bool `mapstructure:"migrate"`
+		// StatementCache is how many prepared statements each connection keeps for the hot
+		// read paths, 0 disables them for poolers like PgBouncer in transaction mode
+		StatementCache int `mapstructure:"statement_cache"`
+		// ConnectRetries is how often the first connection is retried while the database is
+		// not up yet, waiting ConnectBackoff doubled per attempt up to ConnectMaxBackoff
+		ConnectRetries    int           `mapstructure:"connect_retries"`
+		ConnectBackoff    time.Duration `mapstructure:"connect_backoff"`
+		ConnectMaxBackoff time.Duration `mapstructure:"connect_max_backoff"`
+		// PingInterval is how often the database is pinged in the background once connected
+		PingInterval time.Duration `mapstructure:"ping_interval"`
 	} `mapstructure:"db"`
+	Store struct {
+		// Kind is StorePostgres or StoreMemory, which keeps the registry in process
+		Kind string `mapstructure:"kind"`
+		// SnapshotFile persists the memory store, which is restored from it at startup and
+		// written to it every SnapshotInterval and on shutdown. Empty keeps nothing.
+		SnapshotFile     string        `mapstructure:"snapshot_file"`
+		SnapshotInterval time.Duration `mapstructure:"snapshot_interval"`
+		// Seed is a fixture file or directory loaded at startup, see LoadFixtures
+		Seed string `mapstructure:"seed"`
+	} `mapstructure:"store"`
+	Server struct {
+		Addr string `mapstructure:"addr"`
+		// GRPCAddr serves the gRPC API next to the HTTP server, empty leaves it off
+		GRPCAddr   string `mapstructure:"grpc_addr"`
+		Mode       string `mapstructure:"mode"`
+		RetryAfter int    `mapstructure:"retry_after"`
+		// ShutdownTimeout bounds how long in-flight requests may drain on shutdown
+		ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+		// TLS serves HTTPS once a certificate is configured, with mutual TLS given a client CA
+		TLS tlsconfig.Config `mapstructure:"tls"`
+	} `mapstructure:"server"`
+	Quota quota.Config `mapstructure:"quota"`
+	Auth  struct {
+		// Enabled requires credentials on every request except health checks and metrics
+		Enabled bool `mapstructure:"enabled"`
+		// AdminKey is a bootstrap key with the admin scope, used to create the first keys
+		AdminKey string `mapstructure:"admin_key"`
+		// OIDC accepts JWT bearer tokens from an OpenID Connect issuer besides API keys
+		OIDC oidc.Config `mapstructure:"oidc"`
+	} `mapstructure:"auth"`
+	Scenarios struct {
+		Workers int    `mapstructure:"workers"`
+		Journal string `mapstructure:"journal"`
+		// ScheduleInterval is how often scenarios with a schedule are checked for being due
+		ScheduleInterval time.Duration `mapstructure:"schedule_interval"`
+		// Canary runs the stored latency scenario with ID Scenario every Interval, off when 0
+		Canary struct {
+			Scenario int           `mapstructure:"scenario"`
+			Interval time.Duration `mapstructure:"interval"`
+		} `mapstructure:"canary"`
+	} `mapstructure:"scenarios"`
+	LoadTests struct {
+		// MaxDuration and MaxConcurrency bound what a single load test may ask for
+		MaxDuration    time.Duration `mapstructure:"max_duration"`
+		MaxConcurrency int           `mapstructure:"max_concurrency"`
+	} `mapstructure:"load_tests"`
+	Captures struct {
+		// MaxDuration and MaxMessages bound what a single capture may record
+		MaxDuration time.Duration `mapstructure:"max_duration"`
+		MaxMessages int           `mapstructure:"max_messages"`
+	} `mapstructure:"captures"`
+	Limits struct {
+		SchemaBytes     int64 `mapstructure:"schema_bytes"`
+		ValidationBytes int64 `mapstructure:"validation_bytes"`
+		DefaultBytes    int64 `mapstructure:"default_bytes"`
+		// MaxBytes caps every request body, SchemaDataBytes every stored schema_data
+		MaxBytes        int64 `mapstructure:"max_bytes"`
+		SchemaDataBytes int64 `mapstructure:"schema_data_bytes"`
+	} `mapstructure:"limits"`
+	Upload struct {
+		MaxBytes    int64  `mapstructure:"max_bytes"`
+		InlineBytes int64  `mapstructure:"inline_bytes"`
+		BlobDir     string `mapstructure:"blob_dir"`
+	} `mapstructure:"upload"`
+	Validation struct {
+		Workers   int `mapstructure:"workers"`
+		QueueSize int `mapstructure:"queue_size"`
+	} `mapstructure:"validation"`
+	Lifecycle struct {
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"lifecycle"`
+	Redact struct {
+		Fields []string `mapstructure:"fields"`
+	} `mapstructure:"redact"`
+	// Sync registers the schema files of a Git repository, off while Repo is empty. The
+	// repository is pulled into Dir and synced every Interval, or only on request when 0.
+	Sync struct {
+		Repo     string        `mapstructure:"repo"`
+		Branch   string        `mapstructure:"branch"`
+		Path     string        `mapstructure:"path"`
+		Dir      string        `mapstructure:"dir"`
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"sync"`
+	// Snapshot writes every schema below Path of Dir, off while Dir is empty. With Repo, Dir
+	// is a checkout of it and each snapshot is committed as Author and pushed with Push.
+	// Snapshots are taken every Interval, or only on request when 0.
+	Snapshot struct {
+		Dir      string        `mapstructure:"dir"`
+		Repo     string        `mapstructure:"repo"`
+		Branch   string        `mapstructure:"branch"`
+		Path     string        `mapstructure:"path"`
+		Push     bool          `mapstructure:"push"`
+		Author   string        `mapstructure:"author"`
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"snapshot"`
 }
 
-// LoadConfig loads configuration from the config.yaml file
+// lookups deduplicates concurrent reads of the same schema so a burst of validation
+// requests results in one database query
+var lookups singleflight.Group
+
+// statementCaches holds the pools opened by ConnectDB with a statement cache, see cached
+var statementCaches sync.Map
+
+// LoadConfig loads the configuration from the defaults, the file named by CONFIG_PATH
+// or --config when set, environment variables and the flags bound by RegisterFlags
 func LoadConfig() (*Config, error) {
 	var config Config
 
-	err := viper.BindEnv("CONFIG_PATH")
-	if err != nil {
+	if err := applyDefaults(); err != nil {
 		return nil, err
 	}
 
-	// Get the config path from the environment variable
-	configPath := viper.GetString("CONFIG_PATH")
-	if configPath == "" {
-		return nil, fmt.Errorf("CONFIG_PATH environment variable is not set")
-	}
-
-	viper.SetConfigFile(configPath)
-	err = viper.ReadInConfig()
-	if err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+	// Without a file the configuration comes from the environment and flags alone
+	if configPath := viper.GetString("config_path"); configPath != "" {
+		viper.SetConfigFile(configPath)
+		if err := viper.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
 	}
 
-	err = viper.Unmarshal(&config)
+	err := viper.Unmarshal(&config)
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode into struct: %w", err)
 	}
@@ -52,63 +172,167 @@ func LoadConfig() (*Config, error) {
 	return &config, nil
 }
 
-// ConnectDB creates a connection pool to the PostgreSQL database
-func ConnectDB(config *Config) (*pgxpool.Pool, error) {
+// ConnectDB creates a connection pool to the PostgreSQL database and waits for the
+// database to answer, retrying as configured by the connect settings. Queries are traced
+// by tracers besides the query metrics.
+func ConnectDB(config *Config, tracers ...pgx.QueryTracer) (*pgxpool.Pool, error) {
+	// Make sure the password never shows up in logs or wrapped errors
+	redact.AddSecret(config.DB.Password)
+
 	connStr := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		config.DB.User, config.DB.Password, config.DB.Host, config.DB.Port, config.DB.DBName,
 		config.DB.SSLMode,
 	)
 
-	pool, err := pgxpool.New(context.Background(), connStr)
+	poolConfig, err := pgxpool.ParseConfig(connStr)
 	if err != nil {
-		return nil, fmt.Errorf("unable to connect to database: %w", err)
+		return nil, redact.Error(fmt.Errorf("invalid database configuration: %w", err))
+	}
+	poolConfig.ConnConfig.Tracer = multitracer.New(append([]pgx.QueryTracer{metrics.QueryTracer{}}, tracers...)...)
+
+	// Only the hot read paths ask for prepared statements, see cached. Everything else
+	// caches just the statement description so ad-hoc queries like sorted pages and bulk
+	// filters never evict them.
+	poolConfig.ConnConfig.StatementCacheCapacity = config.DB.StatementCache
+	poolConfig.ConnConfig.DescriptionCacheCapacity = config.DB.StatementCache
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheDescribe
+	if config.DB.StatementCache <= 0 {
+		poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
 	}
 
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, redact.Error(fmt.Errorf("unable to connect to database: %w", err))
+	}
+	err = waitForDB(
+		context.Background(), pool, config.DB.ConnectRetries, config.DB.ConnectBackoff, config.DB.ConnectMaxBackoff,
+	)
+	if err != nil {
+		pool.Close()
+		return nil, redact.Error(fmt.Errorf("unable to connect to database: %w", err))
+	}
+
+	if config.DB.StatementCache > 0 {
+		statementCaches.Store(pool, true)
+	}
 	return pool, nil
 }
 
-// InsertSchema inserts a new schema into the s1.schema table
+// InsertSchema inserts a new schema into the s1.schema table together with the schemas it
+// references. It returns ErrAlreadyExists when a live schema already has the name, type
+// and version.
 func InsertSchema(pool *pgxpool.Pool, params QueryArgs) (int, error) {
-
-	created := time.Now().UTC()
-	modified := created
-
-	args := pgx.NamedArgs{
-		"name":        params.Name,
-		"type":        params.Type,
-		"version":     params.Version,
-		"schema_data": params.SchemaData,
-		"created":     created,
-		"modified":    modified,
+	if err := CheckSchemaSize(params); err != nil {
+		return 0, err
 	}
-
-	query := `INSERT INTO s1.schema (name, type, version, schema_data, created, modified) 
-			VALUES (@name, @type, @version, @schema_data, @created, @modified) RETURNING id`
 	var id int
-	err := pool.QueryRow(context.Background(), query, args).Scan(&id)
+	ctx := context.Background()
+	err := pgx.BeginFunc(
+		ctx, pool, func(tx pgx.Tx) error {
+			err := tx.QueryRow(ctx, insertSchemaQuery, insertSchemaArgs(params, time.Now().UTC())).Scan(&id)
+			if err != nil {
+				return err
+			}
+			return linkReferences(ctx, tx, id, params)
+		},
+	)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return 0, fmt.Errorf("error inserting schema %s/%s/%s: %w", params.Name, params.Type, params.Version, ErrAlreadyExists)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("error inserting schema: %w", err)
 	}
 	return id, nil
 }
 
-// GetSchemaById retrieves a schema by its ID from the s1.schema table
+// insertSchemaQuery inserts one schema with the arguments of insertSchemaArgs. New
+// versions registered under an alias belong to the canonical schema.
+const insertSchemaQuery = `
+	INSERT INTO s1.schema (name, type, version, schema_data, fingerprint, rabin_fingerprint, version_key, created,
+	                       modified, tenant_id)
+	VALUES (` + canonicalName + `, @type, @version, @schema_data, NULLIF(@fingerprint, ''), NULLIF(@rabin_fingerprint, ''),
+	        @version_key, @now, @now, @tenant)
+	RETURNING id`
+
+func insertSchemaArgs(params QueryArgs, now time.Time) pgx.NamedArgs {
+	return pgx.NamedArgs{
+		"name":              params.Name,
+		"type":              params.Type,
+		"version":           params.Version,
+		"schema_data":       params.SchemaData,
+		"fingerprint":       params.Fingerprint,
+		"rabin_fingerprint": params.RabinFingerprint,
+		"version_key":       VersionKey(params.Version),
+		"now":               now,
+		"tenant":            tenantOrDefault(params.Tenant),
+	}
+}
+
+// schemaColumns is the column list read by scanSchema
+const schemaColumns = "id, name, type, version, schema_data, created, modified, status, deprecate_at, retire_at, " +
+	"COALESCE(fingerprint, ''), deleted_at, namespace, tenant_id, COALESCE(rabin_fingerprint, '')"
+
+// liveSchema is the condition excluding soft-deleted schemas
+const liveSchema = "deleted_at IS NULL"
+
+// cached returns the query arguments for args running the query as a prepared statement
+// kept on the connection, used by the lookups behind every validation. Without a
+// statement cache the query runs in the pool's default mode.
+func cached(pool *pgxpool.Pool, args pgx.NamedArgs) []interface{} {
+	if _, ok := statementCaches.Load(pool); !ok {
+		return []interface{}{args}
+	}
+	return []interface{}{pgx.QueryExecModeCacheStatement, args}
+}
+
+// scanSchema reads a row selected with schemaColumns, with spilled documents read back
+// from the blob store
+func scanSchema(row pgx.Row) (Schema, error) {
+	var schema Schema
+	err := row.Scan(
+		&schema.ID, &schema.Name, &schema.Type, &schema.Version, &schema.SchemaData,
+		&schema.Created, &schema.Modified, &schema.Status, &schema.DeprecateAt, &schema.RetireAt,
+		&schema.Fingerprint, &schema.DeletedAt, &schema.Namespace, &schema.Tenant, &schema.RabinFingerprint,
+	)
+	if err != nil {
+		return schema, err
+	}
+	return schema, resolveSchema(&schema)
+}
+
+// GetSchemaById retrieves a live schema by its ID from the s1.schema table. Concurrent
+// lookups of the same ID share a single query.
 func GetSchemaById(pool *pgxpool.Pool, id int) (*Schema, error) {
+	v, err, _ := lookups.Do(
+		fmt.Sprintf("%p/id/%d", pool, id), func() (interface{}, error) {
+			return getSchemaById(pool, id)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every caller gets its own copy so nobody can mutate a shared result
+	schema := *v.(*Schema)
+	return &schema, nil
+}
+
+func getSchemaById(pool *pgxpool.Pool, id int) (*Schema, error) {
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	query := `
-		SELECT id, name, type, version, schema_data, created, modified 
-		FROM s1.schema 
-		WHERE id = @id`
+	query := "SELECT " + schemaColumns + " FROM s1.schema WHERE id = @id AND " + liveSchema
 
-	row := pool.QueryRow(context.Background(), query, args)
+	row := pool.QueryRow(context.Background(), query, cached(pool, args)...)
 
-	var schema Schema
-	err := row.Scan(&schema.ID, &schema.Name, &schema.Type, &schema.Version, &schema.SchemaData)
+	schema, err := scanSchema(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error getting schema %d: %w", id, ErrSchemaNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting schema: %w", err)
 	}
@@ -116,13 +340,46 @@ func GetSchemaById(pool *pgxpool.Pool, id int) (*Schema, error) {
 	return &schema, nil
 }
 
-// GetSchemaFilterParams retrieves schemas by optional name, type, and version from the s1.schema table
+// GetSchemasByIds retrieves all live schemas whose ID is in ids, ordered by ID. IDs that
+// do not exist or were deleted are silently skipped.
+func GetSchemasByIds(pool *pgxpool.Pool, ids []int) ([]Schema, error) {
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	query := "SELECT " + schemaColumns + " FROM s1.schema WHERE id = ANY(@ids) AND " + liveSchema +
+		" ORDER BY id"
+
+	rows, err := pool.Query(context.Background(), query, cached(pool, args)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying schemas: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []Schema{}
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+// GetSchemaFilterParams retrieves the schemas of the tenant of params by optional name,
+// type, version and fingerprints from the s1.schema table. Soft-deleted schemas are
+// skipped unless params.IncludeDeleted.
 func GetSchemaFilterParams(pool *pgxpool.Pool, params QueryArgs) ([]Schema, error) {
-	var conditions []string
-	args := pgx.NamedArgs{}
+	conditions := []string{"tenant_id = @tenant"}
+	args := pgx.NamedArgs{"tenant": tenantOrDefault(params.Tenant)}
 
+	if !params.IncludeDeleted {
+		conditions = append(conditions, liveSchema)
+	}
 	if params.Name != "" {
-		conditions = append(conditions, "name = @name")
+		conditions = append(conditions, "name = "+canonicalName)
 		args["name"] = params.Name
 	}
 	if params.Type != "" {
@@ -133,13 +390,19 @@ func GetSchemaFilterParams(pool *pgxpool.Pool, params QueryArgs) ([]Schema, erro
 		conditions = append(conditions, "version = @version")
 		args["version"] = params.Version
 	}
-
-	query := "SELECT id, name, type, version, schema_data, created, modified FROM s1.schema"
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if params.Fingerprint != "" {
+		conditions = append(conditions, "fingerprint = @fingerprint")
+		args["fingerprint"] = params.Fingerprint
+	}
+	if params.RabinFingerprint != "" {
+		conditions = append(conditions, "rabin_fingerprint = @rabin_fingerprint")
+		args["rabin_fingerprint"] = params.RabinFingerprint
 	}
 
-	rows, err := pool.Query(context.Background(), query, args)
+	query := "SELECT " + schemaColumns + " FROM s1.schema WHERE " + strings.Join(conditions, " AND ") + " ORDER BY id"
+
+	// The handful of filter combinations are all worth a prepared statement
+	rows, err := pool.Query(context.Background(), query, cached(pool, args)...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying schemas: %w", err)
 	}
@@ -147,11 +410,7 @@ func GetSchemaFilterParams(pool *pgxpool.Pool, params QueryArgs) ([]Schema, erro
 
 	var schemas []Schema
 	for rows.Next() {
-		var schema Schema
-		err := rows.Scan(
-			&schema.ID, &schema.Name, &schema.Type, &schema.Version, &schema.SchemaData,
-			&schema.Created, &schema.Modified,
-		)
+		schema, err := scanSchema(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning schema: %w", err)
 		}
@@ -161,95 +420,266 @@ func GetSchemaFilterParams(pool *pgxpool.Pool, params QueryArgs) ([]Schema, erro
 	return schemas, nil
 }
 
-// UpdateSchema updates an existing schema in the s1.schema table
-func UpdateSchema(pool *pgxpool.Pool, params QueryArgs) ([]Schema, error) {
-	// Retrieve the existing schema
-	existingSchemas, err := GetSchemaFilterParams(
-		pool, QueryArgs{Name: params.Name, Type: params.Type, Version: params.Version},
+// UpdateSchemaData replaces the schema_data of an existing live schema, named directly or
+// through an alias, and the schemas it references. It returns ErrSchemaNotFound rather
+// than registering a missing version, see UpsertSchema for that.
+func UpdateSchemaData(pool *pgxpool.Pool, params QueryArgs) ([]Schema, error) {
+	if err := CheckSchemaSize(params); err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE s1.schema
+		SET schema_data = @schema_data, fingerprint = NULLIF(@fingerprint, ''),
+		    rabin_fingerprint = NULLIF(@rabin_fingerprint, ''), modified = @now
+		WHERE tenant_id = @tenant AND name = ` + canonicalName + ` AND type = @type AND version = @version
+		  AND ` + liveSchema + `
+		RETURNING ` + schemaColumns
+
+	var schema Schema
+	ctx := context.Background()
+	err := pgx.BeginFunc(
+		ctx, pool, func(tx pgx.Tx) error {
+			var err error
+			schema, err = scanSchema(tx.QueryRow(ctx, query, insertSchemaArgs(params, time.Now().UTC())))
+			if err != nil {
+				return err
+			}
+			return linkReferences(ctx, tx, schema.ID, params)
+		},
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return []Schema{}, fmt.Errorf(
+			"error updating schema %s/%s/%s: %w", params.Name, params.Type, params.Version, ErrSchemaNotFound,
+		)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error retrieving existing schema: %w", err)
+		return nil, fmt.Errorf("error updating schema: %w", err)
 	}
+	return []Schema{schema}, nil
+}
 
-	// If the schema does not exist return an error
-	if len(existingSchemas) == 0 {
-		return []Schema{}, fmt.Errorf("schema not found")
+// UpsertSchema registers the version of params or replaces its schema_data when it is
+// already live, atomically through the unique index on live versions, and records the
+// schemas it references. created reports whether a new row was inserted.
+func UpsertSchema(pool *pgxpool.Pool, params QueryArgs) (schema *Schema, created bool, err error) {
+	if err := CheckSchemaSize(params); err != nil {
+		return nil, false, err
 	}
-
-	// Check if any argument except schema_data has changed
-	if existingSchemas[0].Name != params.Name || existingSchemas[0].Type != params.Type || existingSchemas[0].Version != params.Version {
-		// Perform an insert instead of an update
-		_, err := InsertSchema(pool, params)
-		if err != nil {
-			return nil, fmt.Errorf("error inserting schema: %w", err)
-		}
-		return GetSchemaFilterParams(pool, params)
+	// xmax is only set on rows the statement updated
+	query := `
+		INSERT INTO s1.schema (name, type, version, schema_data, fingerprint, rabin_fingerprint, version_key, created,
+		                       modified, tenant_id)
+		VALUES (` + canonicalName + `, @type, @version, @schema_data, NULLIF(@fingerprint, ''),
+		        NULLIF(@rabin_fingerprint, ''), @version_key, @now, @now, @tenant)
+		ON CONFLICT (tenant_id, name, type, version) WHERE ` + liveSchema + ` DO UPDATE
+		SET schema_data = EXCLUDED.schema_data, fingerprint = EXCLUDED.fingerprint,
+		    rabin_fingerprint = EXCLUDED.rabin_fingerprint, modified = EXCLUDED.modified
+		RETURNING ` + schemaColumns + `, xmax = 0`
+
+	var upserted Schema
+	ctx := context.Background()
+	err = pgx.BeginFunc(
+		ctx, pool, func(tx pgx.Tx) error {
+			err := tx.QueryRow(ctx, query, insertSchemaArgs(params, time.Now().UTC())).Scan(
+				&upserted.ID, &upserted.Name, &upserted.Type, &upserted.Version, &upserted.SchemaData,
+				&upserted.Created, &upserted.Modified, &upserted.Status, &upserted.DeprecateAt, &upserted.RetireAt,
+				&upserted.Fingerprint, &upserted.DeletedAt, &upserted.Namespace, &upserted.Tenant,
+				&upserted.RabinFingerprint, &created,
+			)
+			if err != nil {
+				return err
+			}
+			return linkReferences(ctx, tx, upserted.ID, params)
+		},
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("error upserting schema: %w", err)
 	}
+	return &upserted, created, resolveSchema(&upserted)
+}
 
-	// Update the modified timestamp
-	modified := time.Now().UTC()
-
-	// Proceed with the update for schema_data
+// DeleteSchema soft-deletes a schema by setting its deleted_at, returning
+// ErrSchemaNotFound when no live schema has the ID and ErrSchemaReferenced while live
+// schemas reference it. The row stays for auditing and can be brought back with
+// RestoreSchema.
+func DeleteSchema(pool *pgxpool.Pool, id int) error {
+	now := time.Now().UTC()
 	args := pgx.NamedArgs{
-		"name":        params.Name,
-		"type":        params.Type,
-		"version":     params.Version,
-		"schema_data": params.SchemaData,
-		"modified":    modified,
+		"id":  id,
+		"ids": []int{id},
+		"now": now,
+	}
+
+	var referenced bool
+	if err := pool.QueryRow(context.Background(), referencedQuery, args).Scan(&referenced); err != nil {
+		return fmt.Errorf("error checking schema references: %w", err)
+	}
+	if referenced {
+		return fmt.Errorf("error deleting schema %d: %w", id, ErrSchemaReferenced)
 	}
 
 	query := `
 		UPDATE s1.schema
-		SET schema_data = @schema_data, modified = @modified
-		WHERE name = @name AND type = @type AND version = @version`
+		SET deleted_at = @now, modified = @now
+		WHERE id = @id AND ` + liveSchema
 
-	_, err = pool.Exec(context.Background(), query, args)
+	tag, err := pool.Exec(context.Background(), query, args)
 	if err != nil {
-		return nil, fmt.Errorf("error updating schema: %w", err)
+		return fmt.Errorf("error deleting schema: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSchemaNotFound
 	}
+	return nil
+}
 
-	return GetSchemaFilterParams(pool, params)
+// SchemaOwnedBy reports whether the schema with id, deleted or not, belongs to tenant
+func SchemaOwnedBy(pool *pgxpool.Pool, tenant string, id int) (bool, error) {
+	var owned bool
+	err := pool.QueryRow(
+		context.Background(), `SELECT EXISTS (SELECT 1 FROM s1.schema WHERE id = @id AND tenant_id = @tenant)`,
+		cached(pool, pgx.NamedArgs{"id": id, "tenant": tenantOrDefault(tenant)})...,
+	).Scan(&owned)
+	if err != nil {
+		return false, fmt.Errorf("error checking schema tenant: %w", err)
+	}
+	return owned, nil
 }
 
-// DeleteSchema deletes a schema from the s1.schema table
-func DeleteSchema(pool *pgxpool.Pool, id int) error {
+// GetLatestSchema returns the latest live version of the schema of tenant with name and
+// type, the newest release that is not retired by semantic version, see LatestIndex
+func GetLatestSchema(pool *pgxpool.Pool, tenant, name, schemaType string) (*Schema, error) {
+	schemas, err := GetSchemaFilterParams(pool, QueryArgs{Name: name, Type: schemaType, Tenant: tenant})
+	if err != nil {
+		return nil, err
+	}
+	return latestSchema(schemas, name, schemaType)
+}
+
+// RestoreSchema undoes the soft delete of a schema. It returns ErrSchemaNotFound when
+// no deleted schema has the ID and ErrAlreadyExists when a live schema has taken its
+// name, type and version in the meantime.
+func RestoreSchema(pool *pgxpool.Pool, id int) (*Schema, error) {
 	args := pgx.NamedArgs{
-		"id": id,
+		"id":       id,
+		"modified": time.Now().UTC(),
 	}
 
 	query := `
-		DELETE FROM s1.schema 
-		WHERE id = @id`
+		UPDATE s1.schema
+		SET deleted_at = NULL, modified = @modified
+		WHERE id = @id AND deleted_at IS NOT NULL
+		RETURNING ` + schemaColumns
+
+	schema, err := scanSchema(pool.QueryRow(context.Background(), query, args))
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("error restoring schema %d: %w", id, ErrSchemaNotFound)
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		return nil, fmt.Errorf("error restoring schema %d: %w", id, ErrAlreadyExists)
+	case err != nil:
+		return nil, fmt.Errorf("error restoring schema: %w", err)
+	}
+	return &schema, nil
+}
 
-	_, err := pool.Exec(context.Background(), query, args)
+// GetAllSchemas retrieves every live schema in the s1.schema table
+func GetAllSchemas(pool *pgxpool.Pool) ([]Schema, error) {
+	var schemas []Schema
+	err := StreamSchemas(
+		pool, ListOptions{}, func(schema Schema) error {
+			schemas = append(schemas, schema)
+			return nil
+		},
+	)
 	if err != nil {
-		return fmt.Errorf("error deleting schema: %w", err)
+		return nil, err
 	}
-	return nil
+	return schemas, nil
 }
 
-func GetAllSchemas(pool *pgxpool.Pool) ([]Schema, error) {
-	query := `SELECT id, name, type, version, schema_data, created, modified FROM s1.schema`
-	rows, err := pool.Query(context.Background(), query)
+// GetSchemaPage retrieves one page of schemas ordered as opts asks, together with the
+// total number of schemas for pagination
+func GetSchemaPage(pool *pgxpool.Pool, opts ListOptions) ([]Schema, int, error) {
+	total, err := CountSchemas(pool, opts.Tenant, opts.IncludeDeleted)
 	if err != nil {
-		return nil, fmt.Errorf("error querying schemas: %w", err)
+		return nil, 0, err
+	}
+
+	schemas := []Schema{}
+	err = StreamSchemas(
+		pool, opts, func(schema Schema) error {
+			schemas = append(schemas, schema)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return schemas, total, nil
+}
+
+// StreamSchemas calls fn for each schema in the s1.schema table as it is scanned, so
+// callers can process the registry without holding it all in memory. opts pages and
+// orders the scan, by default every live schema is read by ID. Iteration stops at the
+// first error returned by fn.
+func StreamSchemas(pool *pgxpool.Pool, opts ListOptions, fn func(Schema) error) error {
+	order, err := orderBy(opts.Sort)
+	if err != nil {
+		return err
+	}
+
+	args := pgx.NamedArgs{"offset": max(opts.Offset, 0), "tenant": tenantOrDefault(opts.Tenant)}
+	conditions := []string{"tenant_id = @tenant"}
+	if !opts.IncludeDeleted {
+		conditions = append(conditions, liveSchema)
+	}
+	if len(opts.Namespaces) > 0 {
+		conditions = append(conditions, "namespace = ANY(@namespaces)")
+		args["namespaces"] = opts.Namespaces
+	}
+	query := "SELECT " + schemaColumns + " FROM s1.schema WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + order
+	if opts.Limit > 0 {
+		query += " LIMIT @limit"
+		args["limit"] = min(opts.Limit, MaxSchemaLimit)
+	}
+	query += " OFFSET @offset"
+
+	rows, err := pool.Query(context.Background(), query, args)
+	if err != nil {
+		return fmt.Errorf("error querying schemas: %w", err)
 	}
 	defer rows.Close()
 
-	var schemas []Schema
 	for rows.Next() {
-		var schema Schema
-		err := rows.Scan(
-			&schema.ID, &schema.Name, &schema.Type, &schema.Version, &schema.SchemaData,
-			&schema.Created, &schema.Modified,
-		)
+		schema, err := scanSchema(rows)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning schema: %w", err)
+			return fmt.Errorf("error scanning schema: %w", err)
+		}
+		if err := fn(schema); err != nil {
+			return err
 		}
-		schemas = append(schemas, schema)
 	}
 
-	return schemas, nil
+	return rows.Err()
+}
+
+// CountSchemas returns the number of live schemas of tenant in the s1.schema table,
+// counting soft-deleted ones too with includeDeleted
+func CountSchemas(pool *pgxpool.Pool, tenant string, includeDeleted bool) (int, error) {
+	query := "SELECT count(*) FROM s1.schema WHERE tenant_id = @tenant"
+	if !includeDeleted {
+		query += " AND " + liveSchema
+	}
+
+	var count int
+	err := pool.QueryRow(context.Background(), query, pgx.NamedArgs{"tenant": tenantOrDefault(tenant)}).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting schemas: %w", err)
+	}
+	return count, nil
 }
 
 func main() {
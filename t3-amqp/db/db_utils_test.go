@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -130,8 +131,8 @@ func UpdateNonExistentSchema(t *testing.T) {
 		Version:    "1.0.1",
 		SchemaData: `{"type": "object", "properties": {"example": {"type": "string"}}}`,
 	}
-	_, err := UpdateSchema(pool, newSchema)
-	assert.Error(t, err, "UpdateSchema should return an error for a non-existent schema")
+	_, err := UpdateSchemaData(pool, newSchema)
+	assert.ErrorIs(t, err, ErrSchemaNotFound, "UpdateSchemaData should not insert a non-existent schema")
 }
 
 func DeleteNonExistentSchema(t *testing.T) {
@@ -159,8 +160,8 @@ func TestUpdateSchema(t *testing.T) {
 	// Update the schema
 	newSchema.Name = "test_schema"
 	newSchema.SchemaData = `{"type": "avro", "properties": {"example": {"type": "number"}}}`
-	_, err = UpdateSchema(pool, newSchema)
-	assert.NoError(t, err, "UpdateSchema should not return an error")
+	_, err = UpdateSchemaData(pool, newSchema)
+	assert.NoError(t, err, "UpdateSchemaData should not return an error")
 
 	updatedSchema, err := GetSchemaById(pool, id)
 	assert.NoError(t, err)
@@ -171,7 +172,7 @@ func TestUpdateSchema(t *testing.T) {
 	)
 }
 
-func InsertInsteadOfUpdateWhenNameTypeOrVersionChanged(t *testing.T) {
+func UpsertInsertsWhenNameTypeOrVersionChanged(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
@@ -192,8 +193,9 @@ func InsertInsteadOfUpdateWhenNameTypeOrVersionChanged(t *testing.T) {
 		Version:    "1.0.1",
 		SchemaData: `{"type": "object", "properties": {"example": {"type": "number"}}}`,
 	}
-	_, err = UpdateSchema(pool, updatedSchema)
-	assert.NoError(t, err, "UpdateSchema should not return an error")
+	_, created, err := UpsertSchema(pool, updatedSchema)
+	assert.NoError(t, err, "UpsertSchema should not return an error")
+	assert.True(t, created, "UpsertSchema should insert the new version")
 
 	// Verify that the original schema still exists
 	originalRetrievedSchema, err := GetSchemaById(pool, id)
@@ -301,3 +303,59 @@ func TestGetAllSchemas(t *testing.T) {
 		)
 	}
 }
+
+func TestGetSchemasByIds(t *testing.T) {
+	pool := setupTestDB(t)
+	defer pool.Close()
+
+	var ids []int
+	for _, version := range []string{"1.0.1", "1.0.2"} {
+		id, err := InsertSchema(
+			pool, QueryArgs{
+				Name:       "test_schema_batch",
+				Type:       "json",
+				Version:    version,
+				SchemaData: `{"type": "object"}`,
+			},
+		)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	schemas, err := GetSchemasByIds(pool, append(ids, 999999))
+	assert.NoError(t, err, "GetSchemasByIds should not return an error")
+	assert.Len(t, schemas, 2, "Unknown ids should be skipped")
+	assert.Equal(t, ids[0], schemas[0].ID)
+	assert.Equal(t, ids[1], schemas[1].ID)
+}
+
+func TestBatchInsertSchemas(t *testing.T) {
+	pool := setupTestDB(t)
+	defer pool.Close()
+
+	var params []QueryArgs
+	for i := 0; i < 200; i++ {
+		params = append(
+			params, QueryArgs{
+				Name: "test_schema_bulk", Type: "json", Version: fmt.Sprintf("1.0.%d", i), SchemaData: `{}`,
+			},
+		)
+	}
+	ids, err := BatchInsertSchemas(pool, params)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ids, len(params))
+
+	schemas, err := GetSchemasByIds(pool, ids)
+	assert.NoError(t, err)
+	assert.Len(t, schemas, len(params))
+
+	// A version that is already registered rolls the whole batch back
+	again := []QueryArgs{{Name: "test_schema_bulk", Type: "json", Version: "2.0.0"}, params[0]}
+	_, err = BatchInsertSchemas(pool, again)
+	assert.True(t, errors.Is(err, ErrAlreadyExists))
+	schemas, err = GetSchemaFilterParams(pool, QueryArgs{Name: "test_schema_bulk", Version: "2.0.0"})
+	assert.NoError(t, err)
+	assert.Empty(t, schemas)
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net"
+	"strings"
+)
+
+// Error categories callers map to responses. Every error this package defines belongs to
+// one of them, so errors.Is(err, ErrNotFound) holds for ErrSchemaNotFound and
+// ErrAliasNotFound alike.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrForbidden   = errors.New("forbidden")
+	ErrValidation  = errors.New("invalid request")
+	ErrUnavailable = errors.New("database unavailable")
+	ErrTooLarge    = errors.New("too large")
+)
+
+// Error is a specific error in one of the categories, Message is safe to show clients
+type Error struct {
+	Kind    error
+	Message string
+}
+
+// NewError returns an error with message in the category kind
+func NewError(kind error, message string) error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// unavailable marks err with ErrUnavailable when it means the database could not be
+// reached or refused to serve the query, other errors are returned as they are
+func unavailable(err error) error {
+	if err == nil || errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &connectErr), errors.As(err, &netErr), pgconn.Timeout(err):
+	case errors.As(err, &pgErr) && isUnavailableCode(pgErr.Code):
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// isUnavailableCode reports whether a SQLSTATE means the server cannot take queries:
+// connection exceptions, too many connections and shutdowns
+func isUnavailableCode(code string) bool {
+	return strings.HasPrefix(code, "08") || code == "53300" || code == "57P01" || code == "57P02" ||
+		code == "57P03"
+}
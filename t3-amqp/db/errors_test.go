@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCategories(t *testing.T) {
+	assert.True(t, errors.Is(ErrSchemaNotFound, ErrNotFound))
+	assert.True(t, errors.Is(ErrAliasNotFound, ErrNotFound))
+	assert.True(t, errors.Is(ErrAlreadyExists, ErrConflict))
+	assert.True(t, errors.Is(ErrAliasConflict, ErrConflict))
+	assert.True(t, errors.Is(ErrInvalidSort, ErrValidation))
+	assert.True(t, errors.Is(ErrEmptyFilter, ErrValidation))
+	assert.False(t, errors.Is(ErrSchemaNotFound, ErrConflict))
+
+	wrapped := fmt.Errorf("error getting schema: %w", ErrSchemaNotFound)
+	assert.True(t, errors.Is(wrapped, ErrNotFound))
+	assert.Equal(t, "error getting schema: schema not found", wrapped.Error())
+
+	_, err := NewMemoryStore().GetByID(1)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestUnavailable(t *testing.T) {
+	assert.NoError(t, unavailable(nil))
+	assert.Equal(t, ErrSchemaNotFound, unavailable(ErrSchemaNotFound))
+
+	err := unavailable(fmt.Errorf("error counting schemas: %w", &pgconn.PgError{Code: "57P01"}))
+	assert.True(t, errors.Is(err, ErrUnavailable))
+	assert.False(t, errors.Is(unavailable(&pgconn.PgError{Code: "23505"}), ErrUnavailable))
+
+	err = unavailable(&pgconn.ConnectError{Config: &pgconn.Config{}})
+	assert.True(t, errors.Is(err, ErrUnavailable))
+	assert.False(t, errors.Is(unavailable(context.Canceled), ErrUnavailable))
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Fixtures are the schemas and scenarios of a fixture file
+type Fixtures struct {
+	Schemas   []FixtureSchema `json:"schemas"`
+	Scenarios []TestScenario  `json:"scenarios"`
+}
+
+// FixtureSchema is a schema version to register. SchemaData holds the document either
+// as a string or inline, a YAML fixture may write a JSON schema as YAML.
+type FixtureSchema struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Version    string          `json:"version"`
+	SchemaData json.RawMessage `json:"schemaData"`
+}
+
+// FixtureStore is a store fixtures can be loaded into
+type FixtureStore interface {
+	SchemaStore
+	Upserter
+	ScenarioStore
+}
+
+// fixtureExtensions are the files a fixture directory is read from
+var fixtureExtensions = []string{".json", ".yaml", ".yml"}
+
+// LoadFixtures loads the fixture file at path, or every fixture file in the directory
+// at path, into the database
+func LoadFixtures(ctx context.Context, pool *pgxpool.Pool, path string) error {
+	return LoadFixturesInto(ctx, NewPostgresStore(pool), path)
+}
+
+// LoadFixturesInto loads the fixtures at path into store. Loading is idempotent: schema
+// versions that exist are updated to the fixture's document and scenarios are matched
+// by name. Documents are stored as given, without validation.
+func LoadFixturesInto(ctx context.Context, store FixtureStore, path string) error {
+	files, err := fixtureFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fixtures, err := ReadFixtures(file)
+		if err != nil {
+			return err
+		}
+		if err := fixtures.apply(ctx, store); err != nil {
+			return fmt.Errorf("error loading fixtures from %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// fixtureFiles returns path, or the fixture files in it by name when it is a directory
+func fixtureFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && slices.Contains(fixtureExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// ReadFixtures parses the fixture file at path, YAML unless it ends in .json. Unknown
+// fields are rejected so typos do not silently drop data.
+func ReadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures: %w", err)
+	}
+	if strings.ToLower(filepath.Ext(path)) != ".json" {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error parsing fixtures %s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("error parsing fixtures %s: %w", path, err)
+		}
+	}
+
+	var fixtures Fixtures
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("error parsing fixtures %s: %w", path, err)
+	}
+	return &fixtures, nil
+}
+
+// document returns the schema document of s
+func (s FixtureSchema) document() (string, error) {
+	var text string
+	if err := json.Unmarshal(s.SchemaData, &text); err == nil {
+		return text, nil
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, s.SchemaData); err != nil {
+		return "", err
+	}
+	return compact.String(), nil
+}
+
+func (f *Fixtures) apply(ctx context.Context, store FixtureStore) error {
+	for _, s := range f.Schemas {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if s.Name == "" || s.Type == "" || s.Version == "" || len(s.SchemaData) == 0 {
+			return fmt.Errorf("%w: schemas need a name, type, version and schemaData", ErrValidation)
+		}
+		data, err := s.document()
+		if err != nil {
+			return fmt.Errorf("%w: schemaData of %s %s: %v", ErrValidation, s.Name, s.Version, err)
+		}
+		_, _, err = store.Upsert(QueryArgs{Name: s.Name, Type: s.Type, Version: s.Version, SchemaData: data})
+		if err != nil {
+			return fmt.Errorf("error loading schema %s %s: %w", s.Name, s.Version, err)
+		}
+	}
+
+	existing, err := store.Scenarios(DefaultTenant)
+	if err != nil {
+		return err
+	}
+	for _, scenario := range f.Scenarios {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if scenario.Name == "" {
+			return fmt.Errorf("%w: scenarios need a name", ErrValidation)
+		}
+		if len(scenario.Options) == 0 {
+			scenario.Options = json.RawMessage(`{}`)
+		}
+		i := slices.IndexFunc(existing, func(s TestScenario) bool { return s.Name == scenario.Name })
+		if i >= 0 {
+			scenario.ID = existing[i].ID
+			_, err = store.UpdateScenario(scenario)
+		} else {
+			_, err = store.CreateScenario(scenario)
+		}
+		if err != nil {
+			return fmt.Errorf("error loading scenario %s: %w", scenario.Name, err)
+		}
+	}
+	return nil
+}
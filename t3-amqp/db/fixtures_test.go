@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFixturesInto(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 2; i++ {
+		if !assert.NoError(t, LoadFixturesInto(context.Background(), store, "testdata/fixtures"), "load %d", i) {
+			return
+		}
+	}
+
+	schemas, err := store.Filter(QueryArgs{Name: "orders"})
+	assert.NoError(t, err)
+	if assert.Len(t, schemas, 2, "loading twice updates instead of duplicating") {
+		assert.JSONEq(
+			t, `{"type":"object","properties":{"id":{"type":"string"}},"required":["id"]}`, schemas[0].SchemaData,
+		)
+		assert.Equal(t, "1.1.0", schemas[1].Version)
+	}
+	scenarios, err := store.Scenarios("")
+	assert.NoError(t, err)
+	if assert.Len(t, scenarios, 1) {
+		assert.Equal(t, "orders.created", scenarios[0].Topic)
+		assert.JSONEq(t, `{}`, string(scenarios[0].Options))
+	}
+}
+
+func TestReadFixturesRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "typo.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("schemas:\n  - name: orders\n    verison: 1.0.0\n"), 0o600))
+	_, err := ReadFixtures(path)
+	assert.ErrorContains(t, err, "verison")
+
+	path = filepath.Join(t.TempDir(), "incomplete.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"schemas":[{"name":"orders"}]}`), 0o600))
+	err = LoadFixturesInto(context.Background(), NewMemoryStore(), path)
+	assert.True(t, errors.Is(err, ErrValidation))
+}
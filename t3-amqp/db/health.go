@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultPingInterval is how often Health pings the database when no interval is set
+const DefaultPingInterval = 15 * time.Second
+
+// pingTimeout bounds a single ping so a hanging connection counts as down
+const pingTimeout = 5 * time.Second
+
+// Pinger is the part of the connection pool Health watches, *pgxpool.Pool implements it
+type Pinger interface {
+	Ping(ctx context.Context) error
+	// Reset closes every connection so the next acquire dials a fresh one
+	Reset()
+}
+
+// HealthState is the database reachability reported by the health endpoints
+type HealthState struct {
+	Up bool `json:"up"`
+	// Since is when the database last went up or down
+	Since    time.Time `json:"since"`
+	Checked  time.Time `json:"checked"`
+	Failures int       `json:"consecutiveFailures"`
+	// Reconnects counts how often the database came back after being down
+	Reconnects int    `json:"reconnects"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Health pings the database in the background and tracks whether it is reachable. When
+// a ping fails the pool is reset, so connections broken by a database restart are
+// dropped instead of failing requests one by one once it is back.
+type Health struct {
+	pool Pinger
+
+	mu    sync.RWMutex
+	state HealthState
+}
+
+// NewHealth watches pool, which is assumed up until the first ping says otherwise
+func NewHealth(pool Pinger) *Health {
+	now := time.Now().UTC()
+	return &Health{pool: pool, state: HealthState{Up: true, Since: now, Checked: now}}
+}
+
+// Run pings every interval until ctx is done
+func (h *Health) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPingInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Check(ctx)
+		}
+	}
+}
+
+// Check pings the database now and returns the updated state
+func (h *Health) Check(ctx context.Context) HealthState {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	err := h.pool.Ping(pingCtx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now().UTC()
+	h.state.Checked = now
+	switch {
+	case err != nil:
+		if h.state.Up {
+			log.Printf("Database went down: %v", err)
+			h.state.Up, h.state.Since = false, now
+		}
+		h.state.Failures++
+		h.state.Error = err.Error()
+		h.pool.Reset()
+	case !h.state.Up:
+		log.Printf("Database is back after %d failed pings", h.state.Failures)
+		h.state.Up, h.state.Since = true, now
+		h.state.Failures, h.state.Error = 0, ""
+		h.state.Reconnects++
+	}
+	return h.state
+}
+
+// State returns the state of the last check
+func (h *Health) State() HealthState {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.state
+}
+
+// Ready pings the database, returning why it is down when it is
+func (h *Health) Ready(ctx context.Context) error {
+	if state := h.Check(ctx); !state.Up {
+		return fmt.Errorf("database is down since %s: %s", state.Since.Format(time.RFC3339), state.Error)
+	}
+	return nil
+}
+
+// waitForDB pings pool until it answers, retrying up to retries times with a backoff
+// doubling from backoff to maxBackoff, so the registry can start before the database
+func waitForDB(ctx context.Context, pool Pinger, retries int, backoff, maxBackoff time.Duration) error {
+	for attempt := 0; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := pool.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt >= retries {
+			return fmt.Errorf("database not reachable after %d attempts: %w", attempt+1, err)
+		}
+
+		wait := connectBackoff(attempt, backoff, maxBackoff)
+		log.Printf("Database not reachable, retrying in %s: %v", wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// connectBackoff is the wait after the failed attempt n, counting from 0
+func connectBackoff(n int, backoff, maxBackoff time.Duration) time.Duration {
+	wait := backoff
+	for i := 0; i < n && (maxBackoff <= 0 || wait < maxBackoff); i++ {
+		wait *= 2
+	}
+	if maxBackoff > 0 && wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
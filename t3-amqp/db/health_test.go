@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePinger fails the pings listed in errs in order, then succeeds
+type fakePinger struct {
+	mu     sync.Mutex
+	errs   []error
+	pings  int
+	resets int
+}
+
+func (p *fakePinger) Ping(context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pings++
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func (p *fakePinger) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resets++
+}
+
+func TestHealthTracksOutages(t *testing.T) {
+	refused := errors.New("connection refused")
+	pinger := &fakePinger{errs: []error{refused, refused}}
+	health := NewHealth(pinger)
+	assert.True(t, health.State().Up)
+
+	state := health.Check(context.Background())
+	assert.False(t, state.Up)
+	assert.Equal(t, 1, state.Failures)
+	assert.Equal(t, "connection refused", state.Error)
+	down := state.Since
+
+	state = health.Check(context.Background())
+	assert.Equal(t, 2, state.Failures)
+	assert.Equal(t, down, state.Since, "the outage started with the first failed ping")
+	assert.Equal(t, 2, pinger.resets, "broken connections are dropped")
+
+	state = health.Check(context.Background())
+	assert.True(t, state.Up)
+	assert.Equal(t, 0, state.Failures)
+	assert.Empty(t, state.Error)
+	assert.Equal(t, 1, state.Reconnects)
+	assert.Equal(t, state, health.State())
+}
+
+func TestWaitForDB(t *testing.T) {
+	refused := errors.New("connection refused")
+	pinger := &fakePinger{errs: []error{refused, refused}}
+	assert.NoError(t, waitForDB(context.Background(), pinger, 3, time.Millisecond, 2*time.Millisecond))
+	assert.Equal(t, 3, pinger.pings)
+
+	pinger = &fakePinger{errs: []error{refused, refused}}
+	err := waitForDB(context.Background(), pinger, 1, time.Millisecond, 0)
+	assert.ErrorIs(t, err, refused)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pinger = &fakePinger{errs: []error{refused}}
+	assert.ErrorIs(t, waitForDB(ctx, pinger, 5, time.Hour, 0), context.Canceled)
+}
+
+func TestConnectBackoff(t *testing.T) {
+	for n, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		assert.Equal(t, want, connectBackoff(n, time.Second, 5*time.Second), "attempt %d", n)
+	}
+	assert.Equal(t, 8*time.Second, connectBackoff(3, time.Second, 0), "no cap")
+}
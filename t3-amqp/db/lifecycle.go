@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+const (
+	StatusActive     = "active"
+	StatusDeprecated = "deprecated"
+	StatusRetired    = "retired"
+)
+
+// ValidStatus reports whether status is one of the lifecycle statuses of a schema
+func ValidStatus(status string) bool {
+	return status == StatusActive || status == StatusDeprecated || status == StatusRetired
+}
+
+var (
+	ErrSchemaNotFound  = NewError(ErrNotFound, "schema not found")
+	ErrAlreadyExists   = NewError(ErrConflict, "a schema with this name, type and version already exists")
+	ErrInvalidSchedule = NewError(ErrValidation, "retire_at must not be before deprecate_at")
+)
+
+// ScheduleSchemaLifecycle sets when the schema identified by params is deprecated and
+// retired. A nil timestamp clears that part of the schedule. The status itself only
+// changes once AdvanceSchemaLifecycle finds the timestamp due.
+func ScheduleSchemaLifecycle(pool *pgxpool.Pool, params QueryArgs, deprecateAt, retireAt *time.Time) (*Schema, error) {
+	if deprecateAt != nil && retireAt != nil && retireAt.Before(*deprecateAt) {
+		return nil, ErrInvalidSchedule
+	}
+
+	args := pgx.NamedArgs{
+		"name":         params.Name,
+		"type":         params.Type,
+		"version":      params.Version,
+		"deprecate_at": deprecateAt,
+		"retire_at":    retireAt,
+		"modified":     time.Now().UTC(),
+		"tenant":       tenantOrDefault(params.Tenant),
+	}
+
+	query := `
+		UPDATE s1.schema
+		SET deprecate_at = @deprecate_at, retire_at = @retire_at, modified = @modified
+		WHERE tenant_id = @tenant AND name = ` + canonicalName + ` AND type = @type AND version = @version
+		  AND ` + liveSchema + `
+		RETURNING ` + schemaColumns
+
+	schema, err := scanSchema(pool.QueryRow(context.Background(), query, args))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSchemaNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error scheduling schema lifecycle: %w", err)
+	}
+	return &schema, nil
+}
+
+// AdvanceSchemaLifecycle moves every schema whose deprecate_at or retire_at is due at now
+// into its next status and returns the schemas that changed
+func AdvanceSchemaLifecycle(pool *pgxpool.Pool, now time.Time) ([]Schema, error) {
+	args := pgx.NamedArgs{
+		"now": now,
+	}
+
+	query := `
+		UPDATE s1.schema
+		SET status = CASE WHEN retire_at <= @now THEN 'retired' ELSE 'deprecated' END, modified = @now
+		WHERE status <> 'retired' AND ` + liveSchema + `
+		  AND (retire_at <= @now OR (status = 'active' AND deprecate_at <= @now))
+		RETURNING ` + schemaColumns
+
+	rows, err := pool.Query(context.Background(), query, args)
+	if err != nil {
+		return nil, fmt.Errorf("error advancing schema lifecycle: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []Schema{}
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
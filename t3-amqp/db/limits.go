@@ -0,0 +1,44 @@
+package db
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultMaxSchemaBytes is the largest schema_data stored unless configured otherwise,
+// larger documents belong in the blob store
+const DefaultMaxSchemaBytes = 1 << 20
+
+var maxSchemaBytes atomic.Int64
+
+func init() {
+	maxSchemaBytes.Store(DefaultMaxSchemaBytes)
+}
+
+// SetMaxSchemaBytes sets the largest schema_data the stores accept, zero or less
+// restores DefaultMaxSchemaBytes
+func SetMaxSchemaBytes(n int64) {
+	if n <= 0 {
+		n = DefaultMaxSchemaBytes
+	}
+	maxSchemaBytes.Store(n)
+}
+
+// MaxSchemaBytes returns the largest schema_data the stores accept
+func MaxSchemaBytes() int64 {
+	return maxSchemaBytes.Load()
+}
+
+// CheckSchemaSize returns an error in the ErrTooLarge category when the schema_data of
+// params is over MaxSchemaBytes, so oversized documents never reach the database
+func CheckSchemaSize(params QueryArgs) error {
+	if limit := MaxSchemaBytes(); int64(len(params.SchemaData)) > limit {
+		return NewError(
+			ErrTooLarge, fmt.Sprintf(
+				"schema %s/%s/%s is %d bytes, over the %d byte limit for schema_data",
+				params.Name, params.Type, params.Version, len(params.SchemaData), limit,
+			),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchemaSize(t *testing.T) {
+	SetMaxSchemaBytes(16)
+	defer SetMaxSchemaBytes(0)
+
+	large := QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: strings.Repeat(" ", 14) + "{}{}"}
+	err := CheckSchemaSize(large)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.EqualError(t, err, "schema orders/json/1.0.0 is 18 bytes, over the 16 byte limit for schema_data")
+
+	store := NewMemoryStore()
+	_, err = store.Insert(large)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, err = store.InsertBatch([]QueryArgs{large})
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, _, err = store.Upsert(large)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = store.Insert(QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: "{}"})
+	assert.NoError(t, err)
+	_, err = store.Update(large)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	schemas, err := store.Filter(QueryArgs{Name: "orders"})
+	assert.NoError(t, err)
+	if assert.Len(t, schemas, 1) {
+		assert.Equal(t, "{}", schemas[0].SchemaData, "nothing oversized is stored")
+	}
+
+	SetMaxSchemaBytes(0)
+	assert.Equal(t, int64(DefaultMaxSchemaBytes), MaxSchemaBytes())
+	assert.NoError(t, CheckSchemaSize(large))
+}
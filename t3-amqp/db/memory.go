@@ -0,0 +1,811 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process SchemaStore for tests and ephemeral environments started
+// with --store=memory, see SnapshotEvery to keep it across restarts. It follows the
+// Postgres store's semantics but knows nothing about aliases or lifecycle schedules.
+type MemoryStore struct {
+	*memoryState
+	// tenant whose schemas the store reads and writes, see ForTenant
+	tenant string
+}
+
+// memoryState is shared by the tenant views of a MemoryStore
+type memoryState struct {
+	mu      sync.RWMutex
+	nextID  int
+	schemas map[int]Schema
+	audit   []AuditEntry
+	apiKeys []APIKey
+
+	scenarios      []TestScenario
+	runs           []TestRun
+	nextScenarioID int
+	nextRunID      int
+
+	captures         []Capture
+	capturedMessages map[int][]CapturedMessage
+	nextCaptureID    int
+
+	webhooks      []Webhook
+	nextWebhookID int
+
+	tenants []Tenant
+}
+
+// NewMemoryStore creates an empty store with only the default tenant
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		memoryState: &memoryState{
+			nextID: 1, schemas: map[int]Schema{},
+			tenants: []Tenant{{ID: DefaultTenant, Name: "Default tenant", Created: time.Now().UTC()}},
+		},
+	}
+}
+
+// Ping and Reset let Health watch the store, which is always reachable
+func (m *MemoryStore) Ping(context.Context) error {
+	return nil
+}
+
+func (m *MemoryStore) Reset() {}
+
+func (m *MemoryStore) ForTenant(tenant string) SchemaStore {
+	return m.WithTenant(tenant)
+}
+
+// WithTenant returns a view of the store on the schemas of tenant, sharing everything else
+func (m *MemoryStore) WithTenant(tenant string) *MemoryStore {
+	return &MemoryStore{memoryState: m.memoryState, tenant: tenant}
+}
+
+func matches(s Schema, params QueryArgs) bool {
+	return (params.IncludeDeleted || s.DeletedAt == nil) &&
+		(params.Name == "" || s.Name == params.Name) &&
+		(params.Type == "" || s.Type == params.Type) &&
+		(params.Version == "" || s.Version == params.Version) &&
+		(params.Fingerprint == "" || s.Fingerprint == params.Fingerprint) &&
+		(params.RabinFingerprint == "" || s.RabinFingerprint == params.RabinFingerprint)
+}
+
+// compareColumn orders two schemas by one of the sortColumns
+func compareColumn(a, b Schema, column string) int {
+	switch column {
+	case "id":
+		return cmp.Compare(a.ID, b.ID)
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "type":
+		return strings.Compare(a.Type, b.Type)
+	case "version":
+		return CompareVersions(a.Version, b.Version)
+	case "created":
+		return a.Created.Compare(b.Created)
+	case "modified":
+		return a.Modified.Compare(b.Modified)
+	case "status":
+		return strings.Compare(a.Status, b.Status)
+	}
+	return 0
+}
+
+// sortedLocked returns the schemas of the store's tenant matching params ordered by ID
+func (m *MemoryStore) sortedLocked(params QueryArgs) []Schema {
+	tenant := tenantOrDefault(m.tenant)
+	var schemas []Schema
+	for _, s := range m.schemas {
+		if s.Tenant == tenant && matches(s, params) {
+			schemas = append(schemas, s)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].ID < schemas[j].ID })
+	return schemas
+}
+
+func (m *MemoryStore) Insert(params QueryArgs) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkNewLocked(params); err != nil {
+		return 0, err
+	}
+	return m.insertLocked(params), nil
+}
+
+// InsertBatch inserts all of params or, when one of them is already registered, none
+func (m *MemoryStore) InsertBatch(params []QueryArgs) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := map[QueryArgs]bool{}
+	for _, p := range params {
+		key := QueryArgs{Name: p.Name, Type: p.Type, Version: p.Version}
+		if seen[key] {
+			return nil, fmt.Errorf("error inserting schema %s/%s/%s: %w", p.Name, p.Type, p.Version, ErrAlreadyExists)
+		}
+		seen[key] = true
+		if err := m.checkNewLocked(p); err != nil {
+			return nil, err
+		}
+	}
+	ids := make([]int, len(params))
+	for i, p := range params {
+		ids[i] = m.insertLocked(p)
+	}
+	return ids, nil
+}
+
+// checkNewLocked returns ErrAlreadyExists when a live schema has the name, type and
+// version of params, and an ErrTooLarge error when its schema_data is over the limit
+func (m *MemoryStore) checkNewLocked(params QueryArgs) error {
+	if err := CheckSchemaSize(params); err != nil {
+		return err
+	}
+	key := QueryArgs{Name: params.Name, Type: params.Type, Version: params.Version}
+	if len(m.sortedLocked(key)) > 0 {
+		return fmt.Errorf("error inserting schema %s/%s/%s: %w", params.Name, params.Type, params.Version, ErrAlreadyExists)
+	}
+	return nil
+}
+
+func (m *MemoryStore) insertLocked(params QueryArgs) int {
+	now := time.Now().UTC()
+	id := m.nextID
+	m.nextID++
+	m.schemas[id] = Schema{
+		ID: id, Name: params.Name, Type: params.Type, Version: params.Version, SchemaData: params.SchemaData,
+		Created: now, Modified: now, Status: StatusActive, Fingerprint: params.Fingerprint,
+		RabinFingerprint: params.RabinFingerprint, Namespace: Namespace(params.Name), Tenant: tenantOrDefault(m.tenant),
+	}
+	return id
+}
+
+// ownedLocked returns the schema with id when it belongs to the store's tenant
+func (m *MemoryStore) ownedLocked(id int) (Schema, bool) {
+	s, ok := m.schemas[id]
+	return s, ok && s.Tenant == tenantOrDefault(m.tenant)
+}
+
+func (m *MemoryStore) GetByID(id int) (*Schema, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.ownedLocked(id)
+	if !ok || s.DeletedAt != nil {
+		return nil, fmt.Errorf("error getting schema: %w", ErrSchemaNotFound)
+	}
+	if err := resolveSchema(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (m *MemoryStore) GetByIDs(ids []int) ([]Schema, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	schemas := []Schema{}
+	for _, s := range m.sortedLocked(QueryArgs{}) {
+		if wanted[s.ID] {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas, resolveSchemas(schemas)
+}
+
+func (m *MemoryStore) Filter(params QueryArgs) ([]Schema, error) {
+	m.mu.RLock()
+	schemas := m.sortedLocked(params)
+	m.mu.RUnlock()
+	return schemas, resolveSchemas(schemas)
+}
+
+func (m *MemoryStore) Latest(name, schemaType string) (*Schema, error) {
+	schemas, _ := m.Filter(QueryArgs{Name: name, Type: schemaType})
+	return latestSchema(schemas, name, schemaType)
+}
+
+func (m *MemoryStore) Update(params QueryArgs) ([]Schema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := CheckSchemaSize(params); err != nil {
+		return nil, err
+	}
+	s, ok := m.updateLocked(params)
+	if !ok {
+		return []Schema{}, ErrSchemaNotFound
+	}
+	return []Schema{s}, resolveSchema(&s)
+}
+
+func (m *MemoryStore) Upsert(params QueryArgs) (*Schema, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := CheckSchemaSize(params); err != nil {
+		return nil, false, err
+	}
+	if s, ok := m.updateLocked(params); ok {
+		return &s, false, resolveSchema(&s)
+	}
+	s := m.schemas[m.insertLocked(params)]
+	return &s, true, resolveSchema(&s)
+}
+
+// updateLocked replaces the data of the live version of params, reporting whether there
+// is one
+func (m *MemoryStore) updateLocked(params QueryArgs) (Schema, bool) {
+	existing := m.sortedLocked(QueryArgs{Name: params.Name, Type: params.Type, Version: params.Version})
+	if len(existing) == 0 {
+		return Schema{}, false
+	}
+
+	s := existing[0]
+	s.SchemaData = params.SchemaData
+	s.Fingerprint, s.RabinFingerprint = params.Fingerprint, params.RabinFingerprint
+	s.Modified = time.Now().UTC()
+	m.schemas[s.ID] = s
+	return s, true
+}
+
+func (m *MemoryStore) Delete(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.ownedLocked(id)
+	if !ok || s.DeletedAt != nil {
+		return ErrSchemaNotFound
+	}
+	if len(m.dependentsLocked(s)) > 0 {
+		return fmt.Errorf("error deleting schema %d: %w", id, ErrSchemaReferenced)
+	}
+	now := time.Now().UTC()
+	s.DeletedAt, s.Modified = &now, now
+	m.schemas[id] = s
+	return nil
+}
+
+func (m *MemoryStore) Restore(id int) (*Schema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.ownedLocked(id)
+	if !ok || s.DeletedAt == nil {
+		return nil, fmt.Errorf("error restoring schema %d: %w", id, ErrSchemaNotFound)
+	}
+	if len(m.sortedLocked(QueryArgs{Name: s.Name, Type: s.Type, Version: s.Version})) > 0 {
+		return nil, fmt.Errorf("error restoring schema %d: %w", id, ErrAlreadyExists)
+	}
+	s.DeletedAt, s.Modified = nil, time.Now().UTC()
+	m.schemas[id] = s
+	return &s, resolveSchema(&s)
+}
+
+// References and Dependents derive the dependency graph from the live schemas rather
+// than keeping it next to them
+func (m *MemoryStore) References(id int) ([]Schema, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.ownedLocked(id)
+	if !ok || s.DeletedAt != nil {
+		return nil, fmt.Errorf("error getting schema %d: %w", id, ErrSchemaNotFound)
+	}
+	schemas := []Schema{}
+	for _, ref := range referencesOf(s) {
+		schemas = append(schemas, m.sortedLocked(QueryArgs{Name: ref.Name, Type: ref.Type, Version: ref.Version})...)
+	}
+	slices.SortFunc(schemas, func(a, b Schema) int { return cmp.Compare(a.ID, b.ID) })
+	return schemas, resolveSchemas(schemas)
+}
+
+func (m *MemoryStore) Dependents(id int) ([]Schema, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.ownedLocked(id)
+	if !ok || s.DeletedAt != nil {
+		return nil, fmt.Errorf("error getting schema %d: %w", id, ErrSchemaNotFound)
+	}
+	schemas := m.dependentsLocked(s)
+	return schemas, resolveSchemas(schemas)
+}
+
+// dependentsLocked returns the live schemas of the store's tenant referencing s
+func (m *MemoryStore) dependentsLocked(s Schema) []Schema {
+	ref := SchemaRef{Name: s.Name, Type: s.Type, Version: s.Version}
+	dependents := []Schema{}
+	for _, d := range m.sortedLocked(QueryArgs{}) {
+		if d.ID != s.ID && slices.Contains(referencesOf(d), ref) {
+			dependents = append(dependents, d)
+		}
+	}
+	return dependents
+}
+
+// referencesOf returns the references of s, reading a spilled document back from the blob
+// store. A document that cannot be read references nothing.
+func referencesOf(s Schema) []SchemaRef {
+	data, err := ResolveSchemaData(s.SchemaData)
+	if err != nil {
+		return nil
+	}
+	return SchemaReferences(s.Type, data)
+}
+
+func (m *MemoryStore) List(opts ListOptions, fn func(Schema) error) error {
+	columns, desc, err := parseSort(opts.Sort)
+	if err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	schemas := m.sortedLocked(QueryArgs{IncludeDeleted: opts.IncludeDeleted})
+	m.mu.RUnlock()
+	if len(opts.Namespaces) > 0 {
+		schemas = slices.DeleteFunc(
+			schemas, func(s Schema) bool { return !slices.Contains(opts.Namespaces, s.Namespace) },
+		)
+	}
+
+	// Stable on top of the ID order, matching the id tiebreaker of the Postgres store
+	sort.SliceStable(schemas, func(i, j int) bool {
+		for k, column := range columns {
+			if c := compareColumn(schemas[i], schemas[j], column); c != 0 {
+				return (c < 0) != desc[k]
+			}
+		}
+		return false
+	})
+
+	schemas = schemas[min(max(opts.Offset, 0), len(schemas)):]
+	if opts.Limit > 0 {
+		schemas = schemas[:min(opts.Limit, MaxSchemaLimit, len(schemas))]
+	}
+	for _, s := range schemas {
+		if err := resolveSchema(&s); err != nil {
+			return err
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) Count(includeDeleted bool) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.sortedLocked(QueryArgs{IncludeDeleted: includeDeleted})), nil
+}
+
+func (m *MemoryStore) RecordAudit(entry AuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry.Tenant == "" {
+		entry.Tenant = tenantOrDefault(m.tenant)
+	}
+	entry.ID = len(m.audit) + 1
+	entry.Created = time.Now().UTC()
+	m.audit = append(m.audit, entry)
+	return nil
+}
+
+// AuditEntries returns the recorded audit trail, oldest first
+func (m *MemoryStore) AuditEntries() []AuditEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]AuditEntry(nil), m.audit...)
+}
+
+func (m *MemoryStore) CreateAPIKey(key APIKey) (*APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, k := range m.apiKeys {
+		if k.Prefix == key.Prefix {
+			return nil, ErrAPIKeyConflict
+		}
+	}
+	key.ID = len(m.apiKeys) + 1
+	key.Created = time.Now().UTC()
+	key.RevokedAt = nil
+	m.apiKeys = append(m.apiKeys, key)
+	return &key, nil
+}
+
+func (m *MemoryStore) APIKeyByPrefix(prefix string) (*APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, k := range m.apiKeys {
+		if k.Prefix == prefix {
+			return &k, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func (m *MemoryStore) APIKeys() ([]APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]APIKey{}, m.apiKeys...), nil
+}
+
+func (m *MemoryStore) RevokeAPIKey(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.apiKeys {
+		if m.apiKeys[i].ID == id && m.apiKeys[i].RevokedAt == nil {
+			now := time.Now().UTC()
+			m.apiKeys[i].RevokedAt = &now
+			return nil
+		}
+	}
+	return ErrAPIKeyNotFound
+}
+
+func (m *MemoryStore) CreateScenario(s TestScenario) (*TestScenario, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.Tenant = tenantOrDefault(s.Tenant)
+	for _, existing := range m.scenarios {
+		if existing.Tenant == s.Tenant && existing.Name == s.Name {
+			return nil, ErrScenarioConflict
+		}
+	}
+	m.nextScenarioID++
+	s.ID = m.nextScenarioID
+	s.Created = time.Now().UTC()
+	s.Updated = s.Created
+	m.scenarios = append(m.scenarios, s)
+	return &s, nil
+}
+
+func (m *MemoryStore) ScenarioByID(id int) (*TestScenario, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, s := range m.scenarios {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, ErrScenarioNotFound
+}
+
+func (m *MemoryStore) Scenarios(tenant string) ([]TestScenario, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.DeleteFunc(
+		slices.Clone(m.scenarios), func(s TestScenario) bool { return tenant != "" && s.Tenant != tenant },
+	), nil
+}
+
+func (m *MemoryStore) UpdateScenario(s TestScenario) (*TestScenario, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s.Tenant = tenantOrDefault(s.Tenant)
+	i := slices.IndexFunc(
+		m.scenarios, func(existing TestScenario) bool { return existing.ID == s.ID && existing.Tenant == s.Tenant },
+	)
+	if i < 0 {
+		return nil, ErrScenarioNotFound
+	}
+	for _, existing := range m.scenarios {
+		if existing.Tenant == s.Tenant && existing.Name == s.Name && existing.ID != s.ID {
+			return nil, ErrScenarioConflict
+		}
+	}
+	s.Created = m.scenarios[i].Created
+	s.Updated = time.Now().UTC()
+	m.scenarios[i] = s
+	return &s, nil
+}
+
+func (m *MemoryStore) DeleteScenario(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.scenarios, func(s TestScenario) bool { return s.ID == id })
+	if i < 0 {
+		return ErrScenarioNotFound
+	}
+	m.scenarios = slices.Delete(m.scenarios, i, i+1)
+	for i := range m.runs {
+		if m.runs[i].ScenarioID != nil && *m.runs[i].ScenarioID == id {
+			m.runs[i].ScenarioID = nil
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CreateRun(run TestRun) (*TestRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if run.ScenarioID != nil &&
+		!slices.ContainsFunc(m.scenarios, func(s TestScenario) bool { return s.ID == *run.ScenarioID }) {
+		return nil, ErrScenarioNotFound
+	}
+	if run.Kind == "" {
+		run.Kind = RunKindScenario
+	}
+	run.Tenant = tenantOrDefault(run.Tenant)
+	m.nextRunID++
+	run.ID = m.nextRunID
+	run.Results = nil
+	m.runs = append(m.runs, run)
+	return &run, nil
+}
+
+func (m *MemoryStore) FinishRun(run TestRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.runs {
+		if m.runs[i].ID == run.ID {
+			stored := &m.runs[i]
+			stored.Status, stored.Finished = run.Status, run.Finished
+			stored.MessagesSent, stored.MessagesValid, stored.Failures = run.MessagesSent, run.MessagesValid, run.Failures
+			stored.LatencyP50, stored.LatencyP95, stored.LatencyP99 = run.LatencyP50, run.LatencyP95, run.LatencyP99
+			stored.Throughput = run.Throughput
+			stored.ConfirmP50, stored.ConfirmP95, stored.ConfirmP99 = run.ConfirmP50, run.ConfirmP95, run.ConfirmP99
+			stored.Results = slices.Clone(run.Results)
+			return nil
+		}
+	}
+	return ErrRunNotFound
+}
+
+func (m *MemoryStore) RunByID(id int) (*TestRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, run := range m.runs {
+		if run.ID == id {
+			run.Results = slices.Clone(run.Results)
+			return &run, nil
+		}
+	}
+	return nil, ErrRunNotFound
+}
+
+func (m *MemoryStore) Runs(filter RunFilter) ([]TestRun, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matched []TestRun
+	for _, run := range m.runs {
+		if filter.Tenant != "" && run.Tenant != filter.Tenant ||
+			filter.Kind != "" && run.Kind != filter.Kind ||
+			filter.ScenarioID != 0 && (run.ScenarioID == nil || *run.ScenarioID != filter.ScenarioID) ||
+			filter.Topic != "" && run.Topic != filter.Topic ||
+			filter.Status != "" && run.Status != filter.Status ||
+			!filter.From.IsZero() && run.Started.Before(filter.From) ||
+			!filter.To.IsZero() && !run.Started.Before(filter.To) ||
+			!NamespaceAllowed(filter.Namespaces, run.SchemaName) {
+			continue
+		}
+		run.Results = nil
+		matched = append(matched, run)
+	}
+	slices.SortStableFunc(matched, func(a, b TestRun) int {
+		return cmp.Or(b.Started.Compare(a.Started), cmp.Compare(b.ID, a.ID))
+	})
+
+	start := min(max(filter.Offset, 0), len(matched))
+	end := min(start+clampLimit(filter.Limit, DefaultRunLimit, maxRunLimit), len(matched))
+	return append([]TestRun{}, matched[start:end]...), len(matched), nil
+}
+
+func (m *MemoryStore) CreateCapture(c Capture) (*Capture, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextCaptureID++
+	c.ID = m.nextCaptureID
+	c.Messages = 0
+	c.Tenant = tenantOrDefault(c.Tenant)
+	m.captures = append(m.captures, c)
+	return &c, nil
+}
+
+func (m *MemoryStore) AddCapturedMessages(id int, messages []CapturedMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.captures, func(c Capture) bool { return c.ID == id })
+	if i < 0 {
+		return ErrCaptureNotFound
+	}
+	m.captures[i].Messages += len(messages)
+	if m.capturedMessages == nil {
+		m.capturedMessages = map[int][]CapturedMessage{}
+	}
+	m.capturedMessages[id] = append(m.capturedMessages[id], messages...)
+	return nil
+}
+
+func (m *MemoryStore) FinishCapture(c Capture) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.captures, func(existing Capture) bool { return existing.ID == c.ID })
+	if i < 0 {
+		return ErrCaptureNotFound
+	}
+	m.captures[i].Status, m.captures[i].Finished, m.captures[i].Error = c.Status, c.Finished, c.Error
+	return nil
+}
+
+func (m *MemoryStore) CaptureByID(id int) (*Capture, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, c := range m.captures {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, ErrCaptureNotFound
+}
+
+func (m *MemoryStore) Captures(tenant string) ([]Capture, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	captures := slices.DeleteFunc(
+		slices.Clone(m.captures), func(c Capture) bool { return tenant != "" && c.Tenant != tenant },
+	)
+	slices.SortStableFunc(captures, func(a, b Capture) int {
+		return cmp.Or(b.Started.Compare(a.Started), cmp.Compare(b.ID, a.ID))
+	})
+	return captures, nil
+}
+
+func (m *MemoryStore) CapturedMessages(id int) ([]CapturedMessage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !slices.ContainsFunc(m.captures, func(c Capture) bool { return c.ID == id }) {
+		return nil, ErrCaptureNotFound
+	}
+	messages := slices.Clone(m.capturedMessages[id])
+	slices.SortStableFunc(messages, func(a, b CapturedMessage) int { return cmp.Compare(a.Seq, b.Seq) })
+	return messages, nil
+}
+
+func (m *MemoryStore) DeleteCapture(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.captures, func(c Capture) bool { return c.ID == id })
+	if i < 0 {
+		return ErrCaptureNotFound
+	}
+	m.captures = slices.Delete(m.captures, i, i+1)
+	delete(m.capturedMessages, id)
+	return nil
+}
+
+func (m *MemoryStore) CreateWebhook(hook Webhook) (*Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextWebhookID++
+	hook.ID = m.nextWebhookID
+	hook.Events = slices.Clone(hook.Events)
+	hook.Created = time.Now().UTC()
+	hook.Tenant = tenantOrDefault(hook.Tenant)
+	m.webhooks = append(m.webhooks, hook)
+	return &hook, nil
+}
+
+func (m *MemoryStore) Webhooks(tenant string) ([]Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.DeleteFunc(
+		slices.Clone(m.webhooks), func(hook Webhook) bool { return tenant != "" && hook.Tenant != tenant },
+	), nil
+}
+
+func (m *MemoryStore) DeleteWebhook(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.webhooks, func(hook Webhook) bool { return hook.ID == id })
+	if i < 0 {
+		return ErrWebhookNotFound
+	}
+	m.webhooks = slices.Delete(m.webhooks, i, i+1)
+	return nil
+}
+
+func (m *MemoryStore) RecordWebhookDelivery(id int, at time.Time, status int, deliveryErr string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.webhooks, func(hook Webhook) bool { return hook.ID == id })
+	if i < 0 {
+		return ErrWebhookNotFound
+	}
+	m.webhooks[i].LastDelivery, m.webhooks[i].LastStatus, m.webhooks[i].LastError = &at, status, deliveryErr
+	return nil
+}
+
+func (m *MemoryStore) CreateTenant(tenant Tenant) (*Tenant, error) {
+	if !ValidTenantID(tenant.ID) {
+		return nil, ErrInvalidTenant
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.ContainsFunc(m.tenants, func(t Tenant) bool { return t.ID == tenant.ID }) {
+		return nil, ErrTenantConflict
+	}
+	tenant.Created = time.Now().UTC()
+	m.tenants = append(m.tenants, tenant)
+	return &tenant, nil
+}
+
+func (m *MemoryStore) TenantByID(id string) (*Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := slices.IndexFunc(m.tenants, func(t Tenant) bool { return t.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("error getting tenant %s: %w", id, ErrTenantNotFound)
+	}
+	tenant := m.tenants[i]
+	return &tenant, nil
+}
+
+func (m *MemoryStore) Tenants() ([]Tenant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tenants := slices.Clone(m.tenants)
+	slices.SortFunc(tenants, func(a, b Tenant) int { return strings.Compare(a.ID, b.ID) })
+	return tenants, nil
+}
+
+func (m *MemoryStore) DeleteTenant(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.tenants, func(t Tenant) bool { return t.ID == id })
+	if i < 0 {
+		return ErrTenantNotFound
+	}
+	inUse := id == DefaultTenant || slices.ContainsFunc(m.apiKeys, func(k APIKey) bool { return k.Tenant == id }) ||
+		slices.ContainsFunc(m.scenarios, func(s TestScenario) bool { return s.Tenant == id }) ||
+		slices.ContainsFunc(m.webhooks, func(hook Webhook) bool { return hook.Tenant == id })
+	for _, s := range m.schemas {
+		inUse = inUse || s.Tenant == id
+	}
+	if inUse {
+		return ErrTenantInUse
+	}
+	m.tenants = slices.Delete(m.tenants, i, i+1)
+	return nil
+}
@@ -0,0 +1,388 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreCRUD(t *testing.T) {
+	store := NewMemoryStore()
+	args := QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+
+	id, err := store.Insert(args)
+	assert.NoError(t, err)
+	_, err = store.Insert(args)
+	assert.True(t, errors.Is(err, ErrAlreadyExists))
+
+	schema, err := store.GetByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusActive, schema.Status)
+
+	args.SchemaData = `{"type":"array"}`
+	updated, err := store.Update(args)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"array"}`, updated[0].SchemaData)
+
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, store.Delete(id))
+	assert.True(t, errors.Is(store.Delete(id), ErrSchemaNotFound))
+	_, err = store.GetByID(id)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+	_, err = store.Update(args)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+}
+
+func TestMemoryStoreUpsert(t *testing.T) {
+	store := NewMemoryStore()
+	args := QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+
+	schema, created, err := store.Upsert(args)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	args.SchemaData = `{"type":"array"}`
+	updated, created, err := store.Upsert(args)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, schema.ID, updated.ID)
+	assert.Equal(t, `{"type":"array"}`, updated.SchemaData)
+
+	assert.NoError(t, store.Delete(schema.ID))
+	restored, created, err := store.Upsert(args)
+	assert.NoError(t, err)
+	assert.True(t, created, "deleted versions are registered again")
+	assert.NotEqual(t, schema.ID, restored.ID)
+}
+
+func TestMemoryStoreInsertBatch(t *testing.T) {
+	store := NewMemoryStore()
+	var params []QueryArgs
+	for i := 0; i < 300; i++ {
+		params = append(params, QueryArgs{Name: "orders", Type: "json", Version: fmt.Sprintf("1.0.%d", i)})
+	}
+	ids, err := store.InsertBatch(params)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 300)
+	schema, err := store.GetByID(ids[299])
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.299", schema.Version)
+
+	// Nothing is inserted when one entry is already registered or repeated
+	for _, batch := range [][]QueryArgs{
+		{{Name: "orders", Type: "json", Version: "2.0.0"}, params[0]},
+		{{Name: "orders", Type: "json", Version: "2.0.0"}, {Name: "orders", Type: "json", Version: "2.0.0"}},
+	} {
+		_, err = store.InsertBatch(batch)
+		assert.True(t, errors.Is(err, ErrAlreadyExists))
+	}
+	count, _ := store.Count(false)
+	assert.Equal(t, 300, count)
+}
+
+func TestMemoryStoreFilterAndList(t *testing.T) {
+	store := NewMemoryStore()
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		_, err := store.Insert(QueryArgs{Name: "orders", Type: "json", Version: version})
+		assert.NoError(t, err)
+	}
+	_, err := store.Insert(QueryArgs{Name: "invoices", Type: "avro", Version: "1.0.0"})
+	assert.NoError(t, err)
+
+	orders, err := store.Filter(QueryArgs{Name: "orders"})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+	assert.Less(t, orders[0].ID, orders[1].ID)
+
+	byIDs, err := store.GetByIDs([]int{3, 1, 99})
+	assert.NoError(t, err)
+	assert.Len(t, byIDs, 2)
+
+	var names []string
+	err = store.List(ListOptions{}, func(s Schema) error {
+		names = append(names, s.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders", "orders", "invoices"}, names)
+
+	stop := errors.New("stop")
+	assert.Equal(t, stop, store.List(ListOptions{}, func(Schema) error { return stop }))
+}
+
+func TestMemoryStoreListPagesAndSorts(t *testing.T) {
+	store := NewMemoryStore()
+	for _, name := range []string{"orders", "invoices", "orders", "accounts"} {
+		_, err := store.Insert(QueryArgs{Name: name, Type: "json", Version: fmt.Sprint(store.nextID)})
+		assert.NoError(t, err)
+	}
+
+	list := func(opts ListOptions) []int {
+		var ids []int
+		assert.NoError(t, store.List(opts, func(s Schema) error {
+			ids = append(ids, s.ID)
+			return nil
+		}))
+		return ids
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 4}, list(ListOptions{}))
+	assert.Equal(t, []int{2, 3}, list(ListOptions{Limit: 2, Offset: 1}))
+	assert.Empty(t, list(ListOptions{Offset: 10}))
+	assert.Equal(t, []int{4, 2, 1, 3}, list(ListOptions{Sort: []string{"name"}}))
+	assert.Equal(t, []int{1, 3, 2, 4}, list(ListOptions{Sort: []string{"-name"}}))
+	assert.Equal(t, []int{3, 1}, list(ListOptions{Sort: []string{"-name", "-id"}, Limit: 2}))
+
+	err := store.List(ListOptions{Sort: []string{"schema_data"}}, func(Schema) error { return nil })
+	assert.True(t, errors.Is(err, ErrInvalidSort))
+}
+
+func TestMemoryStoreSoftDeleteAndRestore(t *testing.T) {
+	store := NewMemoryStore()
+	args := QueryArgs{Name: "orders", Type: "json", Version: "1.0.0"}
+	id, err := store.Insert(args)
+	assert.NoError(t, err)
+
+	_, err = store.Restore(id)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound), "only deleted schemas can be restored")
+	assert.NoError(t, store.Delete(id))
+
+	live, err := store.Filter(args)
+	assert.NoError(t, err)
+	assert.Empty(t, live)
+	args.IncludeDeleted = true
+	all, err := store.Filter(args)
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.NotNil(t, all[0].DeletedAt)
+
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	count, err = store.Count(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	restored, err := store.Restore(id)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	_, err = store.GetByID(id)
+	assert.NoError(t, err)
+
+	// A schema re-registered after the delete blocks restoring the old row
+	assert.NoError(t, store.Delete(id))
+	_, err = store.Insert(QueryArgs{Name: "orders", Type: "json", Version: "1.0.0"})
+	assert.NoError(t, err)
+	_, err = store.Restore(id)
+	assert.True(t, errors.Is(err, ErrAlreadyExists))
+}
+
+func TestMemoryStoreScenarios(t *testing.T) {
+	store := NewMemoryStore()
+	orders := TestScenario{
+		Name: "orders-smoke", Topic: "orders.created", SchemaName: "orders", SchemaType: "json",
+		SchemaVersion: "1.0.0", MessageCount: 10, Mode: "valid",
+	}
+	created, err := store.CreateScenario(orders)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, created.ID)
+	_, err = store.CreateScenario(orders)
+	assert.True(t, errors.Is(err, ErrScenarioConflict))
+
+	other, err := store.CreateScenario(TestScenario{Name: "orders-load", Topic: "orders.created", MessageCount: 1})
+	assert.NoError(t, err)
+	other.Name = "orders-smoke"
+	_, err = store.UpdateScenario(*other)
+	assert.True(t, errors.Is(err, ErrScenarioConflict), "an update cannot take another scenario's name")
+
+	created.Rate = 50
+	updated, err := store.UpdateScenario(*created)
+	assert.NoError(t, err)
+	assert.Equal(t, created.Created, updated.Created)
+	got, err := store.ScenarioByID(created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, got.Rate)
+
+	run, err := store.CreateRun(TestRun{ScenarioID: &created.ID, RunID: "a", Status: RunRunning})
+	assert.NoError(t, err)
+	missing := 99
+	_, err = store.CreateRun(TestRun{ScenarioID: &missing, RunID: "b", Status: RunRunning})
+	assert.True(t, errors.Is(err, ErrScenarioNotFound))
+
+	assert.NoError(t, store.DeleteScenario(created.ID))
+	assert.True(t, errors.Is(store.DeleteScenario(created.ID), ErrScenarioNotFound))
+	kept, err := store.RunByID(run.ID)
+	if assert.NoError(t, err, "runs outlive their scenario") {
+		assert.Nil(t, kept.ScenarioID)
+	}
+	scenarios, err := store.Scenarios("")
+	assert.NoError(t, err)
+	assert.Len(t, scenarios, 1)
+}
+
+func TestMemoryStoreRuns(t *testing.T) {
+	store := NewMemoryStore()
+	scenario, err := store.CreateScenario(TestScenario{Name: "orders-smoke", Topic: "orders.created", MessageCount: 1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, run := range []TestRun{
+		{ScenarioID: &scenario.ID, Topic: "orders.created", SchemaName: "orders"},
+		{Topic: "orders.created", SchemaName: "orders"},
+		{Kind: RunKindLoad, Topic: "team-a.events", SchemaName: "team-a.events"},
+	} {
+		run.RunID, run.Status, run.Started = fmt.Sprint(i), RunRunning, started.Add(time.Duration(i)*time.Hour)
+		_, err := store.CreateRun(run)
+		assert.NoError(t, err)
+	}
+
+	finished := time.Now().UTC()
+	assert.NoError(t, store.FinishRun(TestRun{
+		ID: 1, Status: RunFailed, Finished: &finished, MessagesSent: 10, MessagesValid: 9, Failures: 1,
+		LatencyP99: 5 * time.Millisecond, Results: []TestResult{{Instance: 0, MessagesSent: 10, Failures: 1}},
+	}))
+	assert.True(t, errors.Is(store.FinishRun(TestRun{ID: 99}), ErrRunNotFound))
+	run, err := store.RunByID(1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, RunFailed, run.Status)
+		assert.Equal(t, 5*time.Millisecond, run.LatencyP99)
+		assert.Len(t, run.Results, 1)
+	}
+	_, err = store.RunByID(99)
+	assert.True(t, errors.Is(err, ErrRunNotFound))
+
+	tests := []struct {
+		name   string
+		filter RunFilter
+		want   []string
+		total  int
+	}{
+		{"all newest first", RunFilter{}, []string{"2", "1", "0"}, 3},
+		{"scenario", RunFilter{ScenarioID: scenario.ID}, []string{"0"}, 1},
+		{"kind", RunFilter{Kind: RunKindScenario}, []string{"1", "0"}, 2},
+		{"topic", RunFilter{Topic: "orders.created"}, []string{"1", "0"}, 2},
+		{"status", RunFilter{Status: RunRunning}, []string{"2", "1"}, 2},
+		{"time range", RunFilter{From: started.Add(time.Hour), To: started.Add(2 * time.Hour)}, []string{"1"}, 1},
+		{"namespaces", RunFilter{Namespaces: []string{"team-a"}}, []string{"2"}, 1},
+		{"page", RunFilter{Limit: 1, Offset: 1}, []string{"1"}, 3},
+		{"past the end", RunFilter{Offset: 5}, []string{}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, total, err := store.Runs(tt.filter)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := []string{}
+			for _, run := range runs {
+				ids = append(ids, run.RunID)
+				assert.Nil(t, run.Results, "results are only returned for a single run")
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestMemoryStoreCaptures(t *testing.T) {
+	store := NewMemoryStore()
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	first, err := store.CreateCapture(Capture{Topic: "orders.created", Status: CaptureRunning, Started: started})
+	if !assert.NoError(t, err) {
+		return
+	}
+	second, err := store.CreateCapture(
+		Capture{Topic: "orders.created", Status: CaptureRunning, Started: started.Add(time.Hour)},
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.AddCapturedMessages(first.ID, []CapturedMessage{{Seq: 1, Body: []byte("b")}}))
+	assert.NoError(t, store.AddCapturedMessages(first.ID, []CapturedMessage{{Seq: 0, Body: []byte("a")}}))
+	assert.True(t, errors.Is(store.AddCapturedMessages(99, nil), ErrCaptureNotFound))
+
+	finished := started.Add(time.Minute)
+	assert.NoError(t, store.FinishCapture(Capture{ID: first.ID, Status: CaptureFinished, Finished: &finished}))
+	got, err := store.CaptureByID(first.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, CaptureFinished, got.Status)
+		assert.Equal(t, 2, got.Messages)
+	}
+
+	messages, err := store.CapturedMessages(first.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "a", string(messages[0].Body), "messages are returned in received order")
+	}
+	captures, err := store.Captures("")
+	assert.NoError(t, err)
+	if assert.Len(t, captures, 2) {
+		assert.Equal(t, second.ID, captures[0].ID, "newest first")
+	}
+
+	assert.NoError(t, store.DeleteCapture(first.ID))
+	_, err = store.CapturedMessages(first.ID)
+	assert.True(t, errors.Is(err, ErrCaptureNotFound))
+	assert.True(t, errors.Is(store.DeleteCapture(first.ID), ErrCaptureNotFound))
+}
+
+func TestOrderBy(t *testing.T) {
+	order, err := orderBy(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", order)
+
+	order, err = orderBy([]string{"name", "-modified"})
+	assert.NoError(t, err)
+	assert.Equal(t, "name, modified DESC, id", order)
+
+	order, err = orderBy([]string{"-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "id DESC", order)
+
+	order, err = orderBy([]string{"-version"})
+	assert.NoError(t, err)
+	assert.Equal(t, `version_key COLLATE "C" DESC, id`, order)
+
+	_, err = orderBy([]string{"name; DROP TABLE s1.schema"})
+	assert.True(t, errors.Is(err, ErrInvalidSort))
+}
+
+func TestMemoryStoreSearch(t *testing.T) {
+	store := NewMemoryStore()
+	for _, args := range []QueryArgs{
+		{Name: "team-a.orders", Type: "json", Version: "1.0.0", SchemaData: `{"properties":{"order_id":{}}}`},
+		{Name: "team-b.orders", Type: "json", Version: "1.0.0", SchemaData: `"message Order { int64 order_id = 1; }"`},
+	} {
+		_, err := store.Insert(args)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	results, err := store.Search(SearchQuery{Text: "order id", InData: true})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2, "every word has to start a word of the document")
+
+	results, err = store.Search(SearchQuery{Text: "orders", Namespaces: []string{"team-b"}})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "team-b.orders", results[0].Schema.Name)
+		assert.True(t, results[0].NameMatched)
+	}
+
+	restricted := RestrictNamespaces(store, []string{"team-a"}).(Searcher)
+	results, err = restricted.Search(SearchQuery{Text: "order_id", InData: true})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "team-a.orders", results[0].Schema.Name)
+		assert.True(t, results[0].DataMatched)
+	}
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLock is the advisory lock key held while migrating, so replicas starting
+// together apply each migration once
+const migrationLock = 0x7433_6d69_6772
+
+// Migration is one versioned SQL script from the migrations directory, named
+// <version>_<name>.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations returns the embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles)
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := map[int]string{}
+	for _, file := range files {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s is not named <version>_<name>.sql", file)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies the embedded migrations the database has not seen yet, each in its
+// own transaction, and records them in public.t3_schema_migrations. It returns the
+// versions it applied.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) ([]int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring migration connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLock); err != nil {
+		return nil, fmt.Errorf("error locking migrations: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLock)
+
+	_, err = conn.Exec(
+		ctx, `CREATE TABLE IF NOT EXISTS public.t3_schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name    TEXT      NOT NULL,
+			applied timestamp NOT NULL
+		)`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating migrations table: %w", err)
+	}
+
+	done, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []int
+	for _, m := range migrations {
+		if done[m.Version] {
+			continue
+		}
+		err := pgx.BeginFunc(
+			ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, m.SQL); err != nil {
+					return err
+				}
+				_, err := tx.Exec(
+					ctx, "INSERT INTO public.t3_schema_migrations (version, name, applied) VALUES ($1, $2, $3)",
+					m.Version, m.Name, time.Now().UTC(),
+				)
+				return err
+			},
+		)
+		if err != nil {
+			return ran, fmt.Errorf("error applying migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied database migration %04d_%s", m.Version, m.Name)
+		ran = append(ran, m.Version)
+	}
+
+	if err := backfillVersionKeys(ctx, conn.Conn()); err != nil {
+		return ran, err
+	}
+	return ran, nil
+}
+
+// backfillVersionKeys computes the version_key of rows written before it existed
+// querier is a pool or a single connection
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// appliedMigrations returns the versions recorded in public.t3_schema_migrations
+func appliedMigrations(ctx context.Context, q querier) (map[int]bool, error) {
+	rows, err := q.Query(ctx, "SELECT version FROM public.t3_schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %w", err)
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %w", err)
+	}
+	done := map[int]bool{}
+	for _, version := range applied {
+		done[version] = true
+	}
+	return done, nil
+}
+
+// PendingMigrations returns the embedded migrations the database has not applied yet.
+// Before the first migration every one of them is pending.
+func PendingMigrations(ctx context.Context, pool *pgxpool.Pool) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	err = pool.QueryRow(ctx, "SELECT to_regclass('public.t3_schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %w", err)
+	}
+	if !exists {
+		return migrations, nil
+	}
+	done, err := appliedMigrations(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if !done[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// MigrationsApplied returns an error naming the oldest pending migration unless the
+// database is up to date
+func MigrationsApplied(ctx context.Context, pool *pgxpool.Pool) error {
+	pending, err := PendingMigrations(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations pending, starting with %04d_%s", len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+func backfillVersionKeys(ctx context.Context, conn *pgx.Conn) error {
+	rows, err := conn.Query(ctx, "SELECT id, version FROM s1.schema WHERE version_key = ''")
+	if err != nil {
+		return fmt.Errorf("error reading versions to backfill: %w", err)
+	}
+	type row struct {
+		ID      int
+		Version string
+	}
+	pending, err := pgx.CollectRows(rows, pgx.RowToStructByPos[row])
+	if err != nil {
+		return fmt.Errorf("error reading versions to backfill: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, r := range pending {
+		batch.Queue("UPDATE s1.schema SET version_key = $1 WHERE id = $2", VersionKey(r.Version), r.ID)
+	}
+	if err := conn.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("error backfilling version keys: %w", err)
+	}
+	return nil
+}
+
+// FingerprintFunc computes the fingerprints stored with schema_data, see QueryArgs
+type FingerprintFunc func(schemaType, schemaData string) (fingerprint, rabin string, err error)
+
+// BackfillFingerprints fills in the fingerprints of schemas registered before every type
+// got one, including soft-deleted ones. Schemas fingerprints fails for are logged and
+// left alone. It returns the number of schemas it updated.
+func BackfillFingerprints(ctx context.Context, pool *pgxpool.Pool, fingerprints FingerprintFunc) (int, error) {
+	rows, err := pool.Query(
+		ctx, `SELECT id, type, schema_data FROM s1.schema
+		      WHERE fingerprint IS NULL OR (type = 'avro' AND rabin_fingerprint IS NULL)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error reading schemas to fingerprint: %w", err)
+	}
+	type row struct {
+		ID         int
+		Type       string
+		SchemaData string
+	}
+	pending, err := pgx.CollectRows(rows, pgx.RowToStructByPos[row])
+	if err != nil {
+		return 0, fmt.Errorf("error reading schemas to fingerprint: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, r := range pending {
+		fingerprint, rabin, err := fingerprints(r.Type, r.SchemaData)
+		if err != nil {
+			log.Printf("Failed to fingerprint schema %d: %v", r.ID, err)
+			continue
+		}
+		batch.Queue(
+			"UPDATE s1.schema SET fingerprint = $1, rabin_fingerprint = NULLIF($2, '') WHERE id = $3",
+			fingerprint, rabin, r.ID,
+		)
+	}
+	if batch.Len() == 0 {
+		return 0, nil
+	}
+	if err := pool.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("error backfilling fingerprints: %w", err)
+	}
+	return batch.Len(), nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions are consecutive")
+		assert.NotEmpty(t, m.SQL)
+	}
+	assert.Equal(t, "baseline", migrations[0].Name)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(
+		fstest.MapFS{
+			"migrations/0002_second.sql": {Data: []byte("SELECT 2")},
+			"migrations/0001_first.sql":  {Data: []byte("SELECT 1")},
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{{1, "first", "SELECT 1"}, {2, "second", "SELECT 2"}}, migrations)
+
+	_, err = loadMigrations(fstest.MapFS{"migrations/first.sql": {Data: []byte("SELECT 1")}})
+	assert.Error(t, err)
+
+	_, err = loadMigrations(
+		fstest.MapFS{
+			"migrations/0001_first.sql": {Data: []byte("SELECT 1")},
+			"migrations/1_again.sql":    {Data: []byte("SELECT 1")},
+		},
+	)
+	assert.ErrorContains(t, err, "share version 1")
+}
@@ -0,0 +1,63 @@
+-- Baseline of the registry tables. Every statement tolerates objects that already exist
+-- so databases created from database/ddl/t3.sql can adopt migrations.
+CREATE SCHEMA IF NOT EXISTS s1;
+
+DO $$
+BEGIN
+    CREATE TYPE s1.schema_type AS ENUM ('avro', 'json', 'protobuf', 'xsd', 'thrift', 'confluent');
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS s1.schema (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255)   NOT NULL,
+    type        s1.schema_type NOT NULL,
+    version     VARCHAR(15)    NOT NULL,
+    schema_data JSONB          NOT NULL,
+    created     timestamp,
+    modified    timestamp
+);
+
+ALTER TABLE s1.schema
+    ADD COLUMN IF NOT EXISTS status       VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS deprecate_at timestamp,
+    ADD COLUMN IF NOT EXISTS retire_at    timestamp,
+    ADD COLUMN IF NOT EXISTS fingerprint  VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS deleted_at   timestamp,
+    ADD COLUMN IF NOT EXISTS version_key  TEXT NOT NULL DEFAULT '';
+
+-- Older databases enforced uniqueness over deleted rows too
+ALTER TABLE s1.schema DROP CONSTRAINT IF EXISTS unique_name_type_version;
+CREATE UNIQUE INDEX IF NOT EXISTS unique_name_type_version ON s1.schema (name, type, version)
+    WHERE deleted_at IS NULL;
+
+DO $$
+BEGIN
+    ALTER TABLE s1.schema
+        ADD CONSTRAINT schema_status_check CHECK (status IN ('active', 'deprecated', 'retired'));
+EXCEPTION
+    WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE INDEX IF NOT EXISTS schema_version_key_idx ON s1.schema (name, type, version_key COLLATE "C");
+CREATE INDEX IF NOT EXISTS schema_fingerprint_idx ON s1.schema (type, fingerprint);
+
+CREATE TABLE IF NOT EXISTS s1.schema_alias (
+    alias   VARCHAR(255) PRIMARY KEY,
+    name    VARCHAR(255) NOT NULL,
+    created timestamp    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS schema_alias_name_idx ON s1.schema_alias (name);
+
+CREATE TABLE IF NOT EXISTS s1.audit_log (
+    id          SERIAL PRIMARY KEY,
+    actor       VARCHAR(255) NOT NULL,
+    action      VARCHAR(32)  NOT NULL,
+    schema_id   INTEGER,
+    schema_name VARCHAR(255),
+    created     timestamp    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_idx ON s1.audit_log (created);
@@ -0,0 +1,10 @@
+-- API keys authenticating REST callers. Only the SHA-256 of the secret is stored.
+CREATE TABLE IF NOT EXISTS s1.api_key (
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    prefix      VARCHAR(32)  NOT NULL UNIQUE,
+    scope       VARCHAR(16)  NOT NULL CHECK (scope IN ('read', 'read-write', 'admin')),
+    secret_hash CHAR(64)     NOT NULL,
+    created     timestamp    NOT NULL,
+    revoked_at  timestamp
+);
@@ -0,0 +1,9 @@
+-- Schemas belong to the namespace before the first dot of their name, team-a.orders to
+-- team-a. API keys may be restricted to a list of namespaces, empty allows all of them.
+ALTER TABLE s1.schema
+    ADD COLUMN IF NOT EXISTS namespace VARCHAR(255) GENERATED ALWAYS AS (split_part(name, '.', 1)) STORED;
+
+CREATE INDEX IF NOT EXISTS schema_namespace_idx ON s1.schema (namespace);
+
+ALTER TABLE s1.api_key
+    ADD COLUMN IF NOT EXISTS namespaces TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,30 @@
+-- Topic test scenarios and the outcome of every time they were run. Settings beyond the
+-- topic, schema, count, rate and mode are kept together in options.
+CREATE TABLE IF NOT EXISTS s1.test_scenario (
+    id             SERIAL PRIMARY KEY,
+    name           VARCHAR(255) NOT NULL UNIQUE,
+    topic          VARCHAR(255) NOT NULL,
+    schema_name    VARCHAR(255) NOT NULL,
+    schema_type    VARCHAR(32)  NOT NULL,
+    schema_version VARCHAR(15)  NOT NULL,
+    message_count  INTEGER      NOT NULL CHECK (message_count > 0),
+    rate           INTEGER      NOT NULL DEFAULT 0 CHECK (rate >= 0),
+    mode           VARCHAR(16)  NOT NULL CHECK (mode IN ('valid', 'invalid')),
+    options        JSONB        NOT NULL DEFAULT '{}',
+    created        timestamp    NOT NULL,
+    updated        timestamp    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS s1.test_scenario_run (
+    id            SERIAL PRIMARY KEY,
+    scenario_id   INTEGER     NOT NULL REFERENCES s1.test_scenario (id) ON DELETE CASCADE,
+    run_id        VARCHAR(64) NOT NULL UNIQUE,
+    status        VARCHAR(16) NOT NULL CHECK (status IN ('running', 'passed', 'failed', 'error')),
+    started       timestamp   NOT NULL,
+    finished      timestamp,
+    messages_sent INTEGER     NOT NULL DEFAULT 0,
+    failures      INTEGER     NOT NULL DEFAULT 0,
+    report        JSONB
+);
+
+CREATE INDEX IF NOT EXISTS test_scenario_run_scenario_idx ON s1.test_scenario_run (scenario_id, started);
@@ -0,0 +1,47 @@
+-- Scenario runs become test runs that outlive their scenario and keep the topic and
+-- schema they ran against, so topic health can be tracked over time. The outcome of each
+-- instance moves from the report column to s1.test_result, runs recorded before this
+-- migration keep their counts but not their per-instance results.
+ALTER TABLE s1.test_scenario_run RENAME TO test_run;
+ALTER INDEX s1.test_scenario_run_scenario_idx RENAME TO test_run_scenario_idx;
+
+ALTER TABLE s1.test_run
+    DROP CONSTRAINT test_scenario_run_scenario_id_fkey,
+    ALTER COLUMN scenario_id DROP NOT NULL,
+    ADD CONSTRAINT test_run_scenario_id_fkey
+        FOREIGN KEY (scenario_id) REFERENCES s1.test_scenario (id) ON DELETE SET NULL,
+    ADD COLUMN scenario_name  VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN topic          VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN schema_name    VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN messages_valid INTEGER      NOT NULL DEFAULT 0,
+    ADD COLUMN latency_p50_ns BIGINT,
+    ADD COLUMN latency_p95_ns BIGINT,
+    ADD COLUMN latency_p99_ns BIGINT,
+    DROP COLUMN report;
+
+UPDATE s1.test_run r
+SET scenario_name = s.name, topic = s.topic, schema_name = s.schema_name
+FROM s1.test_scenario s
+WHERE s.id = r.scenario_id;
+
+CREATE INDEX IF NOT EXISTS test_run_topic_idx ON s1.test_run (topic, started);
+
+CREATE TABLE IF NOT EXISTS s1.test_result (
+    id             SERIAL PRIMARY KEY,
+    test_run_id    INTEGER      NOT NULL REFERENCES s1.test_run (id) ON DELETE CASCADE,
+    instance       INTEGER      NOT NULL,
+    temp_queue     VARCHAR(255) NOT NULL,
+    params         JSONB        NOT NULL DEFAULT '{}',
+    started        timestamp    NOT NULL,
+    duration_ns    BIGINT       NOT NULL DEFAULT 0,
+    messages_sent  INTEGER      NOT NULL DEFAULT 0,
+    messages_valid INTEGER      NOT NULL DEFAULT 0,
+    failures       INTEGER      NOT NULL DEFAULT 0,
+    redelivered    INTEGER      NOT NULL DEFAULT 0,
+    latency_p50_ns BIGINT,
+    latency_p95_ns BIGINT,
+    latency_p99_ns BIGINT,
+    error          TEXT,
+    details        JSONB,
+    UNIQUE (test_run_id, instance)
+);
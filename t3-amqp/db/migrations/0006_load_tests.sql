@@ -0,0 +1,13 @@
+-- Load tests are recorded as test runs without a scenario. They also report how fast the
+-- broker confirmed messages and the throughput they reached.
+ALTER TABLE s1.test_run
+    ADD COLUMN kind           VARCHAR(16) NOT NULL DEFAULT 'scenario' CHECK (kind IN ('scenario', 'load')),
+    ADD COLUMN throughput     DOUBLE PRECISION,
+    ADD COLUMN confirm_p50_ns BIGINT,
+    ADD COLUMN confirm_p95_ns BIGINT,
+    ADD COLUMN confirm_p99_ns BIGINT;
+
+ALTER TABLE s1.test_result
+    ADD COLUMN confirm_p50_ns BIGINT,
+    ADD COLUMN confirm_p95_ns BIGINT,
+    ADD COLUMN confirm_p99_ns BIGINT;
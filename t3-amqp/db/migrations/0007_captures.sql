@@ -0,0 +1,29 @@
+-- Messages captured from a topic, kept in the order they were received so they can be
+-- replayed to reproduce consumer bugs. Headers are stored as JSON, which keeps strings,
+-- numbers and booleans but not the AMQP types of other header values.
+CREATE TABLE IF NOT EXISTS s1.capture (
+    id            SERIAL PRIMARY KEY,
+    topic         VARCHAR(255) NOT NULL,
+    status        VARCHAR(16)  NOT NULL CHECK (status IN ('running', 'finished', 'error')),
+    started       timestamp    NOT NULL,
+    finished      timestamp,
+    message_count INTEGER      NOT NULL DEFAULT 0,
+    error         TEXT
+);
+
+CREATE INDEX IF NOT EXISTS capture_topic_idx ON s1.capture (topic, started);
+
+CREATE TABLE IF NOT EXISTS s1.captured_message (
+    id             BIGSERIAL PRIMARY KEY,
+    capture_id     INTEGER      NOT NULL REFERENCES s1.capture (id) ON DELETE CASCADE,
+    seq            INTEGER      NOT NULL,
+    received       timestamp    NOT NULL,
+    exchange       VARCHAR(255) NOT NULL,
+    routing_key    VARCHAR(255) NOT NULL,
+    content_type   VARCHAR(255) NOT NULL DEFAULT '',
+    message_id     VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    headers        JSONB,
+    body           BYTEA        NOT NULL,
+    UNIQUE (capture_id, seq)
+);
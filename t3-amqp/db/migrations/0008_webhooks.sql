@@ -0,0 +1,12 @@
+-- HTTP endpoints told about schema changes. The secret signs the callbacks, so it is kept
+-- as is rather than hashed. The last_ columns describe the latest delivery attempt.
+CREATE TABLE IF NOT EXISTS s1.webhook (
+    id            SERIAL PRIMARY KEY,
+    url           TEXT      NOT NULL,
+    secret        TEXT      NOT NULL,
+    events        TEXT[]    NOT NULL DEFAULT '{}',
+    created       timestamp NOT NULL,
+    last_delivery timestamp,
+    last_status   INTEGER   NOT NULL DEFAULT 0,
+    last_error    TEXT
+);
@@ -0,0 +1,11 @@
+-- Schema search matches names by substring and similarity through trigrams, and the keys
+-- and strings of schema documents, where field and record names live, by full text
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS schema_name_trgm_idx ON s1.schema USING gin (name gin_trgm_ops);
+
+ALTER TABLE s1.schema
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (jsonb_to_tsvector('simple', schema_data, '["key", "string"]')) STORED;
+
+CREATE INDEX IF NOT EXISTS schema_search_idx ON s1.schema USING gin (search_vector);
@@ -0,0 +1,9 @@
+-- Audit entries keep the schema before and after the change and the address the request
+-- came from, so every mutation can be traced to a caller and reviewed
+ALTER TABLE s1.audit_log
+    ADD COLUMN IF NOT EXISTS source_ip VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS before    JSONB,
+    ADD COLUMN IF NOT EXISTS after     JSONB;
+
+CREATE INDEX IF NOT EXISTS audit_log_schema_idx ON s1.audit_log (schema_name, created);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON s1.audit_log (actor, created);
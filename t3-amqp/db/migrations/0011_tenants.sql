@@ -0,0 +1,32 @@
+-- Tenants isolate the schemas of the teams sharing a deployment. Everything registered
+-- before belongs to the default tenant, names only have to be unique within a tenant.
+CREATE TABLE IF NOT EXISTS s1.tenant (
+                                         id      VARCHAR(64)  PRIMARY KEY,
+                                         name    VARCHAR(255) NOT NULL DEFAULT '',
+                                         created timestamp    NOT NULL
+);
+
+INSERT INTO s1.tenant (id, name, created)
+VALUES ('default', 'Default tenant', now() AT TIME ZONE 'utc')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE s1.schema
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES s1.tenant (id);
+
+DROP INDEX IF EXISTS s1.unique_name_type_version;
+CREATE UNIQUE INDEX unique_name_type_version ON s1.schema (tenant_id, name, type, version)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE s1.schema_alias
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES s1.tenant (id);
+ALTER TABLE s1.schema_alias DROP CONSTRAINT IF EXISTS schema_alias_pkey;
+ALTER TABLE s1.schema_alias ADD PRIMARY KEY (tenant_id, alias);
+
+ALTER TABLE s1.audit_log
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS audit_log_tenant_idx ON s1.audit_log (tenant_id, created);
+
+-- Keys bound to a tenant only act in it, keys without one choose it with X-Tenant
+ALTER TABLE s1.api_key
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) REFERENCES s1.tenant (id);
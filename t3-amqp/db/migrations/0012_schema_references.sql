@@ -0,0 +1,9 @@
+-- JSON schemas may $ref other registered schemas as t3://name/type/version. Every edge
+-- of that dependency graph is kept so a schema cannot be deleted while others use it.
+CREATE TABLE IF NOT EXISTS s1.schema_reference (
+    schema_id     INTEGER NOT NULL REFERENCES s1.schema (id) ON DELETE CASCADE,
+    referenced_id INTEGER NOT NULL REFERENCES s1.schema (id) ON DELETE CASCADE,
+    PRIMARY KEY (schema_id, referenced_id)
+);
+
+CREATE INDEX IF NOT EXISTS schema_reference_referenced_idx ON s1.schema_reference (referenced_id);
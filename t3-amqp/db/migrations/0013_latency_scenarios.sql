@@ -0,0 +1,4 @@
+-- Latency scenarios publish probes and measure how long they take to come back
+ALTER TABLE s1.test_scenario DROP CONSTRAINT IF EXISTS test_scenario_mode_check;
+ALTER TABLE s1.test_scenario
+    ADD CONSTRAINT test_scenario_mode_check CHECK (mode IN ('valid', 'invalid', 'latency'));
@@ -0,0 +1,9 @@
+-- fingerprint now holds the SHA-256 of the canonical content of every schema type, not
+-- only Avro ones, and Avro schemas also keep their CRC-64-AVRO Rabin fingerprint. Rows
+-- written before are filled in by BackfillFingerprints on startup.
+ALTER TABLE s1.schema
+    ADD COLUMN IF NOT EXISTS rabin_fingerprint VARCHAR(16);
+
+-- Lookups by fingerprint do not know the schema type
+CREATE INDEX IF NOT EXISTS schema_content_fingerprint_idx ON s1.schema (tenant_id, fingerprint);
+CREATE INDEX IF NOT EXISTS schema_rabin_fingerprint_idx ON s1.schema (tenant_id, rabin_fingerprint);
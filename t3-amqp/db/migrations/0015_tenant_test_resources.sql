@@ -0,0 +1,17 @@
+-- Scenarios, test runs, captures and webhooks belong to a tenant like schemas do, so a
+-- tenant only sees, runs and is notified about its own. Everything recorded before
+-- belongs to the default tenant, scenario names only have to be unique within a tenant.
+ALTER TABLE s1.test_scenario
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES s1.tenant (id);
+ALTER TABLE s1.test_scenario DROP CONSTRAINT IF EXISTS test_scenario_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS test_scenario_tenant_name_idx ON s1.test_scenario (tenant_id, name);
+
+ALTER TABLE s1.test_run
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS test_run_tenant_idx ON s1.test_run (tenant_id, started);
+
+ALTER TABLE s1.capture
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE s1.webhook
+    ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES s1.tenant (id);
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrNamespaceDenied = NewError(ErrForbidden, "schema namespace not allowed")
+
+// Namespace returns the namespace of a schema name, the part before the first dot as in
+// the namespace column. team-a.orders is in team-a, a name without dots is its own.
+func Namespace(name string) string {
+	namespace, _, _ := strings.Cut(name, ".")
+	return namespace
+}
+
+// NamespaceAllowed reports whether namespaces grant access to the schema name, an empty
+// list grants every namespace
+func NamespaceAllowed(namespaces []string, name string) bool {
+	return len(namespaces) == 0 || slices.Contains(namespaces, Namespace(name))
+}
+
+// CheckNamespace returns ErrNamespaceDenied unless namespaces grant access to name and,
+// when store resolves aliases, to the schema name it is an alias of
+func CheckNamespace(store SchemaStore, namespaces []string, name string) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	if !NamespaceAllowed(namespaces, name) {
+		return fmt.Errorf("error writing schema %s: %w", name, ErrNamespaceDenied)
+	}
+	resolver, ok := store.(NameResolver)
+	if !ok {
+		return nil
+	}
+	canonical, err := resolver.ResolveName(name)
+	if err != nil {
+		return err
+	}
+	if !NamespaceAllowed(namespaces, canonical) {
+		return fmt.Errorf("error writing schema %s: %w", canonical, ErrNamespaceDenied)
+	}
+	return nil
+}
+
+// NameResolver is implemented by stores that resolve aliases to canonical schema names
+type NameResolver interface {
+	ResolveName(name string) (string, error)
+}
+
+// NamespacedStore restricts a SchemaStore to the schemas in a set of namespaces. Writes
+// anywhere else fail with ErrNamespaceDenied and reads do not see other namespaces.
+type NamespacedStore struct {
+	store      SchemaStore
+	namespaces []string
+}
+
+// RestrictNamespaces returns store limited to namespaces, an empty list returns store
+func RestrictNamespaces(store SchemaStore, namespaces []string) SchemaStore {
+	if len(namespaces) == 0 {
+		return store
+	}
+	return &NamespacedStore{store: store, namespaces: namespaces}
+}
+
+func (s *NamespacedStore) visible(schema Schema) bool {
+	return NamespaceAllowed(s.namespaces, schema.Name)
+}
+
+func (s *NamespacedStore) visibleOnly(schemas []Schema) []Schema {
+	return slices.DeleteFunc(schemas, func(schema Schema) bool { return !s.visible(schema) })
+}
+
+func (s *NamespacedStore) Insert(params QueryArgs) (int, error) {
+	if err := CheckNamespace(s.store, s.namespaces, params.Name); err != nil {
+		return 0, err
+	}
+	return s.store.Insert(params)
+}
+
+func (s *NamespacedStore) InsertBatch(params []QueryArgs) ([]int, error) {
+	for _, p := range params {
+		if err := CheckNamespace(s.store, s.namespaces, p.Name); err != nil {
+			return nil, err
+		}
+	}
+	inserter, ok := s.store.(BatchInserter)
+	if !ok {
+		return nil, fmt.Errorf("error inserting schemas: %T cannot insert batches", s.store)
+	}
+	return inserter.InsertBatch(params)
+}
+
+func (s *NamespacedStore) GetByID(id int) (*Schema, error) {
+	schema, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.visible(*schema) {
+		return nil, fmt.Errorf("error getting schema: %w", ErrSchemaNotFound)
+	}
+	return schema, nil
+}
+
+func (s *NamespacedStore) GetByIDs(ids []int) ([]Schema, error) {
+	schemas, err := s.store.GetByIDs(ids)
+	return s.visibleOnly(schemas), err
+}
+
+func (s *NamespacedStore) Filter(params QueryArgs) ([]Schema, error) {
+	schemas, err := s.store.Filter(params)
+	return s.visibleOnly(schemas), err
+}
+
+func (s *NamespacedStore) Latest(name, schemaType string) (*Schema, error) {
+	schema, err := s.store.Latest(name, schemaType)
+	if err != nil {
+		return nil, err
+	}
+	if !s.visible(*schema) {
+		return nil, fmt.Errorf("error getting latest schema: %w", ErrSchemaNotFound)
+	}
+	return schema, nil
+}
+
+func (s *NamespacedStore) Update(params QueryArgs) ([]Schema, error) {
+	if err := CheckNamespace(s.store, s.namespaces, params.Name); err != nil {
+		return nil, err
+	}
+	return s.store.Update(params)
+}
+
+func (s *NamespacedStore) Upsert(params QueryArgs) (*Schema, bool, error) {
+	if err := CheckNamespace(s.store, s.namespaces, params.Name); err != nil {
+		return nil, false, err
+	}
+	upserter, ok := s.store.(Upserter)
+	if !ok {
+		return nil, false, fmt.Errorf("error upserting schema: %T cannot upsert", s.store)
+	}
+	return upserter.Upsert(params)
+}
+
+func (s *NamespacedStore) Delete(id int) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.store.Delete(id)
+}
+
+func (s *NamespacedStore) References(id int) ([]Schema, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	references, ok := s.store.(ReferenceStore)
+	if !ok {
+		return []Schema{}, nil
+	}
+	schemas, err := references.References(id)
+	return s.visibleOnly(schemas), err
+}
+
+func (s *NamespacedStore) Dependents(id int) ([]Schema, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	references, ok := s.store.(ReferenceStore)
+	if !ok {
+		return []Schema{}, nil
+	}
+	schemas, err := references.Dependents(id)
+	return s.visibleOnly(schemas), err
+}
+
+// errFound stops the listing in Restore once the schema turned up
+var errFound = errors.New("found")
+
+func (s *NamespacedStore) Restore(id int) (*Schema, error) {
+	err := s.List(
+		ListOptions{IncludeDeleted: true}, func(schema Schema) error {
+			if schema.ID == id {
+				return errFound
+			}
+			return nil
+		},
+	)
+	if err == nil {
+		return nil, fmt.Errorf("error restoring schema %d: %w", id, ErrSchemaNotFound)
+	}
+	if !errors.Is(err, errFound) {
+		return nil, err
+	}
+	return s.store.Restore(id)
+}
+
+func (s *NamespacedStore) List(opts ListOptions, fn func(Schema) error) error {
+	if len(opts.Namespaces) == 0 {
+		opts.Namespaces = s.namespaces
+	} else {
+		opts.Namespaces = slices.DeleteFunc(
+			slices.Clone(opts.Namespaces), func(namespace string) bool {
+				return !slices.Contains(s.namespaces, namespace)
+			},
+		)
+		if len(opts.Namespaces) == 0 {
+			return nil
+		}
+	}
+	return s.store.List(opts, fn)
+}
+
+func (s *NamespacedStore) Count(includeDeleted bool) (int, error) {
+	count := 0
+	err := s.List(
+		ListOptions{IncludeDeleted: includeDeleted}, func(Schema) error {
+			count++
+			return nil
+		},
+	)
+	return count, err
+}
+
+func (s *NamespacedStore) RecordAudit(entry AuditEntry) error {
+	recorder, ok := s.store.(AuditRecorder)
+	if !ok {
+		return nil
+	}
+	return recorder.RecordAudit(entry)
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	assert.Equal(t, "team-a", Namespace("team-a.orders"))
+	assert.Equal(t, "team-a", Namespace("team-a.billing.invoices"))
+	assert.Equal(t, "orders", Namespace("orders"))
+
+	assert.True(t, NamespaceAllowed(nil, "team-b.orders"))
+	assert.True(t, NamespaceAllowed([]string{"team-a"}, "team-a.orders"))
+	assert.False(t, NamespaceAllowed([]string{"team-a"}, "team-ab.orders"))
+	assert.False(t, NamespaceAllowed([]string{"team-a"}, "team-b.orders"))
+}
+
+func TestNamespacedStore(t *testing.T) {
+	memory := NewMemoryStore()
+	own, err := memory.Insert(QueryArgs{Name: "team-a.orders", Type: "json", Version: "1.0.0"})
+	assert.NoError(t, err)
+	other, err := memory.Insert(QueryArgs{Name: "team-b.orders", Type: "json", Version: "1.0.0"})
+	assert.NoError(t, err)
+
+	store := RestrictNamespaces(memory, []string{"team-a"})
+	assert.Same(t, memory, RestrictNamespaces(memory, nil))
+
+	_, err = store.Insert(QueryArgs{Name: "team-a.invoices", Type: "json", Version: "1.0.0"})
+	assert.NoError(t, err)
+	_, err = store.Insert(QueryArgs{Name: "team-b.invoices", Type: "json", Version: "1.0.0"})
+	assert.True(t, errors.Is(err, ErrNamespaceDenied))
+	assert.True(t, errors.Is(err, ErrForbidden))
+	_, err = store.Update(QueryArgs{Name: "team-b.orders", Type: "json", Version: "1.0.0"})
+	assert.True(t, errors.Is(err, ErrNamespaceDenied))
+
+	schema, err := store.GetByID(own)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", schema.Namespace)
+	_, err = store.GetByID(other)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+	_, err = store.Latest("team-b.orders", "json")
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+
+	byIDs, err := store.GetByIDs([]int{own, other})
+	assert.NoError(t, err)
+	assert.Len(t, byIDs, 1)
+	filtered, err := store.Filter(QueryArgs{Type: "json"})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 2)
+
+	var names []string
+	err = store.List(
+		ListOptions{Limit: 1, Offset: 1}, func(s Schema) error {
+			names = append(names, s.Name)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team-a.invoices"}, names)
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.True(t, errors.Is(store.Delete(other), ErrSchemaNotFound))
+	assert.NoError(t, memory.Delete(other))
+	_, err = store.Restore(other)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+	assert.NoError(t, store.Delete(own))
+	_, err = store.Restore(own)
+	assert.NoError(t, err)
+}
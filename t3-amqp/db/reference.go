@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"slices"
+	"strings"
+)
+
+// RefScheme starts the $ref of a JSON schema pointing at another registered schema,
+// t3://name/type/version optionally followed by a #fragment into it
+const RefScheme = "t3://"
+
+var ErrSchemaReferenced = NewError(ErrConflict, "schema is referenced by other schemas")
+
+// SchemaRef names the registered schema a t3:// reference points at
+type SchemaRef struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+func (r SchemaRef) String() string {
+	return RefScheme + r.Name + "/" + r.Type + "/" + r.Version
+}
+
+// ParseSchemaRef parses a t3://name/type/version reference, a fragment is ignored
+func ParseSchemaRef(ref string) (SchemaRef, error) {
+	path, ok := strings.CutPrefix(ref, RefScheme)
+	if !ok {
+		return SchemaRef{}, fmt.Errorf("%q is not a %s reference", ref, RefScheme)
+	}
+	path, _, _ = strings.Cut(path, "#")
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 || slices.Contains(parts, "") {
+		return SchemaRef{}, fmt.Errorf("reference %q is not of the form %sname/type/version", ref, RefScheme)
+	}
+	return SchemaRef{Name: parts[0], Type: parts[1], Version: parts[2]}, nil
+}
+
+// SchemaReferences returns the distinct registered schemas schemaData references through
+// the $ref keyword, ordered by reference. Only JSON schemas reference others, malformed
+// documents and references are skipped.
+func SchemaReferences(schemaType, schemaData string) []SchemaRef {
+	if schemaType != "json" || !strings.Contains(schemaData, RefScheme) {
+		return nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(schemaData), &doc); err != nil {
+		return nil
+	}
+
+	var refs []SchemaRef
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if s, ok := value.(string); ok && key == "$ref" {
+					if ref, err := ParseSchemaRef(s); err == nil && !slices.Contains(refs, ref) {
+						refs = append(refs, ref)
+					}
+					continue
+				}
+				walk(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+	walk(doc)
+	slices.SortFunc(refs, func(a, b SchemaRef) int { return strings.Compare(a.String(), b.String()) })
+	return refs
+}
+
+// ReferenceStore is implemented by stores that keep the dependency graph of schemas
+// referencing others. Their Delete fails with ErrSchemaReferenced while a live schema
+// references the one being deleted.
+type ReferenceStore interface {
+	// References returns the live schemas the schema with id references
+	References(id int) ([]Schema, error)
+	// Dependents returns the live schemas referencing the schema with id
+	Dependents(id int) ([]Schema, error)
+}
+
+// ResolveReference returns the live schema of store ref points at
+func ResolveReference(store SchemaStore, ref SchemaRef) (*Schema, error) {
+	schemas, err := store.Filter(QueryArgs{Name: ref.Name, Type: ref.Type, Version: ref.Version})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("error resolving %s: %w", ref, ErrSchemaNotFound)
+	}
+	return &schemas[0], nil
+}
+
+// linkReferences replaces the edges of the schema with id by the live schemas of the
+// tenant of params that its schema_data references, named directly or through an alias.
+// References to schemas that are not registered are not recorded.
+func linkReferences(ctx context.Context, tx pgx.Tx, id int, params QueryArgs) error {
+	if _, err := tx.Exec(
+		ctx, `DELETE FROM s1.schema_reference WHERE schema_id = @id`, pgx.NamedArgs{"id": id},
+	); err != nil {
+		return fmt.Errorf("error replacing schema references: %w", err)
+	}
+	data, err := ResolveSchemaData(params.SchemaData)
+	if err != nil {
+		return err
+	}
+	refs := SchemaReferences(params.Type, data)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	args := pgx.NamedArgs{"id": id, "tenant": tenantOrDefault(params.Tenant)}
+	var names, types, versions []string
+	for _, ref := range refs {
+		names, types, versions = append(names, ref.Name), append(types, ref.Type), append(versions, ref.Version)
+	}
+	args["names"], args["types"], args["versions"] = names, types, versions
+	_, err = tx.Exec(
+		ctx, `
+		INSERT INTO s1.schema_reference (schema_id, referenced_id)
+		SELECT @id, s.id
+		FROM unnest(@names::text[], @types::text[], @versions::text[]) AS r(name, type, version)
+		JOIN s1.schema s
+		  ON s.name = COALESCE((SELECT a.name FROM s1.schema_alias a WHERE a.tenant_id = @tenant AND a.alias = r.name),
+		                       r.name)
+		 AND s.type::text = r.type AND s.version = r.version
+		WHERE s.tenant_id = @tenant AND s.`+liveSchema+` AND s.id <> @id
+		ON CONFLICT DO NOTHING`,
+		args,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording schema references: %w", err)
+	}
+	return nil
+}
+
+// referencedQuery is true when a live schema outside @ids references one of @ids
+const referencedQuery = `
+	SELECT EXISTS (
+		SELECT 1 FROM s1.schema_reference r JOIN s1.schema s ON s.id = r.schema_id
+		WHERE r.referenced_id = ANY(@ids) AND s.id <> ALL(@ids) AND s.` + liveSchema + `)`
+
+// GetSchemaReferences returns the live schemas the schema with id references, ordered by ID
+func GetSchemaReferences(pool *pgxpool.Pool, id int) ([]Schema, error) {
+	return queryReferenceEdges(
+		pool, `SELECT `+schemaColumns+` FROM s1.schema WHERE `+liveSchema+` AND id IN
+		(SELECT referenced_id FROM s1.schema_reference WHERE schema_id = @id) ORDER BY id`, id,
+	)
+}
+
+// GetSchemaDependents returns the live schemas referencing the schema with id, ordered by ID
+func GetSchemaDependents(pool *pgxpool.Pool, id int) ([]Schema, error) {
+	return queryReferenceEdges(
+		pool, `SELECT `+schemaColumns+` FROM s1.schema WHERE `+liveSchema+` AND id IN
+		(SELECT schema_id FROM s1.schema_reference WHERE referenced_id = @id) ORDER BY id`, id,
+	)
+}
+
+func queryReferenceEdges(pool *pgxpool.Pool, query string, id int) ([]Schema, error) {
+	rows, err := pool.Query(context.Background(), query, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("error querying schema references: %w", err)
+	}
+	defer rows.Close()
+
+	schemas := []Schema{}
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, rows.Err()
+}
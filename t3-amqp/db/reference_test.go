@@ -0,0 +1,76 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchemaRef(t *testing.T) {
+	ref, err := ParseSchemaRef("t3://common.address/json/1.0.0#/definitions/street")
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaRef{Name: "common.address", Type: "json", Version: "1.0.0"}, ref)
+	assert.Equal(t, "t3://common.address/json/1.0.0", ref.String())
+
+	for _, bad := range []string{"http://example.com/a/json/1", "t3://address/json", "t3://address//1", "t3://a/b/c/d"} {
+		_, err := ParseSchemaRef(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSchemaReferences(t *testing.T) {
+	data := `{
+		"type": "object",
+		"properties": {
+			"billing": {"$ref": "t3://address/json/1.0.0"},
+			"shipping": {"$ref": "t3://address/json/1.0.0#/definitions/line"},
+			"items": {"type": "array", "items": {"$ref": "t3://item/json/2.0.0"}},
+			"local": {"$ref": "#/definitions/local"},
+			"broken": {"$ref": "t3://nope"}
+		}
+	}`
+	assert.Equal(
+		t, []SchemaRef{{Name: "address", Type: "json", Version: "1.0.0"}, {Name: "item", Type: "json", Version: "2.0.0"}},
+		SchemaReferences("json", data),
+	)
+	assert.Empty(t, SchemaReferences("avro", data))
+	assert.Empty(t, SchemaReferences("json", `{"type":"object"}`))
+}
+
+func TestMemoryStoreReferences(t *testing.T) {
+	store := NewMemoryStore()
+	address, err := store.Insert(QueryArgs{Name: "address", Type: "json", Version: "1.0.0", SchemaData: `{"type":"string"}`})
+	assert.NoError(t, err)
+	order, err := store.Insert(
+		QueryArgs{
+			Name: "order", Type: "json", Version: "1.0.0",
+			SchemaData: `{"properties":{"to":{"$ref":"t3://address/json/1.0.0"}}}`,
+		},
+	)
+	assert.NoError(t, err)
+
+	references, err := store.References(order)
+	assert.NoError(t, err)
+	if assert.Len(t, references, 1) {
+		assert.Equal(t, address, references[0].ID)
+	}
+	dependents, err := store.Dependents(address)
+	assert.NoError(t, err)
+	if assert.Len(t, dependents, 1) {
+		assert.Equal(t, order, dependents[0].ID)
+	}
+
+	err = store.Delete(address)
+	assert.True(t, errors.Is(err, ErrSchemaReferenced))
+	assert.True(t, errors.Is(err, ErrConflict))
+
+	// Once its dependents are gone the schema can be deleted
+	assert.NoError(t, store.Delete(order))
+	assert.NoError(t, store.Delete(address))
+
+	// Tenants do not see each other's dependents
+	other := store.WithTenant("acme")
+	_, err = other.Dependents(address)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+}
@@ -0,0 +1,304 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
+	"time"
+)
+
+// Test run statuses, a run is running until its results have been stored
+const (
+	RunRunning = "running"
+	RunPassed  = "passed"
+	RunFailed  = "failed"
+	RunError   = "error"
+
+	// RunKindScenario runs execute a stored scenario, RunKindLoad runs are load tests
+	RunKindScenario = "scenario"
+	RunKindLoad     = "load"
+
+	DefaultRunLimit = 100
+	maxRunLimit     = 1000
+)
+
+var ErrRunNotFound = NewError(ErrNotFound, "test run not found")
+
+// RunStore is implemented by stores that keep the outcome of topic test runs
+type RunStore interface {
+	// CreateRun records the start of a run, ErrScenarioNotFound when it refers to a
+	// scenario that does not exist
+	CreateRun(run TestRun) (*TestRun, error)
+	// FinishRun stores the status, counts, latency and results of a run that ended
+	FinishRun(run TestRun) error
+	// RunByID returns the run with its results
+	RunByID(id int) (*TestRun, error)
+	// Runs returns the runs matching filter without their results, newest first, and
+	// the total number of matching runs
+	Runs(filter RunFilter) ([]TestRun, int, error)
+}
+
+const runColumns = "id, kind, scenario_id, scenario_name, topic, schema_name, run_id, status, started, " +
+	"finished, messages_sent, messages_valid, failures, COALESCE(latency_p50_ns, 0), COALESCE(latency_p95_ns, 0), " +
+	"COALESCE(latency_p99_ns, 0), COALESCE(throughput, 0), COALESCE(confirm_p50_ns, 0), " +
+	"COALESCE(confirm_p95_ns, 0), COALESCE(confirm_p99_ns, 0), tenant_id"
+
+const resultColumns = "instance, temp_queue, params, started, duration_ns, messages_sent, messages_valid, " +
+	"failures, redelivered, COALESCE(latency_p50_ns, 0), COALESCE(latency_p95_ns, 0), " +
+	"COALESCE(latency_p99_ns, 0), COALESCE(confirm_p50_ns, 0), COALESCE(confirm_p95_ns, 0), " +
+	"COALESCE(confirm_p99_ns, 0), COALESCE(error, ''), details"
+
+func scanRun(row pgx.Row) (TestRun, error) {
+	var run TestRun
+	var p50, p95, p99, c50, c95, c99 int64
+	err := row.Scan(
+		&run.ID, &run.Kind, &run.ScenarioID, &run.ScenarioName, &run.Topic, &run.SchemaName, &run.RunID,
+		&run.Status, &run.Started, &run.Finished, &run.MessagesSent, &run.MessagesValid, &run.Failures,
+		&p50, &p95, &p99, &run.Throughput, &c50, &c95, &c99, &run.Tenant,
+	)
+	run.LatencyP50, run.LatencyP95, run.LatencyP99 = time.Duration(p50), time.Duration(p95), time.Duration(p99)
+	run.ConfirmP50, run.ConfirmP95, run.ConfirmP99 = time.Duration(c50), time.Duration(c95), time.Duration(c99)
+	return run, err
+}
+
+func scanResult(row pgx.Row) (TestResult, error) {
+	var result TestResult
+	var duration, p50, p95, p99, c50, c95, c99 int64
+	err := row.Scan(
+		&result.Instance, &result.TempQueue, &result.Params, &result.Started, &duration, &result.MessagesSent,
+		&result.MessagesValid, &result.Failures, &result.Redelivered, &p50, &p95, &p99, &c50, &c95, &c99,
+		&result.Error, &result.Details,
+	)
+	result.Duration = time.Duration(duration)
+	result.LatencyP50, result.LatencyP95, result.LatencyP99 = time.Duration(p50), time.Duration(p95), time.Duration(p99)
+	result.ConfirmP50, result.ConfirmP95, result.ConfirmP99 = time.Duration(c50), time.Duration(c95), time.Duration(c99)
+	return result, err
+}
+
+// CreateRun records the start of run, ErrScenarioNotFound when its scenario does not exist.
+// A run without a kind is a scenario run.
+func CreateRun(pool *pgxpool.Pool, run TestRun) (*TestRun, error) {
+	if run.Kind == "" {
+		run.Kind = RunKindScenario
+	}
+	args := pgx.NamedArgs{
+		"kind":          run.Kind,
+		"scenario_id":   run.ScenarioID,
+		"scenario_name": run.ScenarioName,
+		"topic":         run.Topic,
+		"schema_name":   run.SchemaName,
+		"run_id":        run.RunID,
+		"status":        run.Status,
+		"started":       run.Started,
+		"tenant":        tenantOrDefault(run.Tenant),
+	}
+	query := `INSERT INTO s1.test_run (kind, scenario_id, scenario_name, topic, schema_name, run_id, status, started,
+				tenant_id)
+			VALUES (@kind, @scenario_id, @scenario_name, @topic, @schema_name, @run_id, @status, @started, @tenant)
+			RETURNING ` + runColumns
+	created, err := scanRun(pool.QueryRow(context.Background(), query, args))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return nil, ErrScenarioNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating test run: %w", err)
+	}
+	return &created, nil
+}
+
+// FinishRun stores the outcome of the run with run.ID and its results in one transaction
+func FinishRun(pool *pgxpool.Pool, run TestRun) error {
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	args := pgx.NamedArgs{
+		"id":             run.ID,
+		"status":         run.Status,
+		"finished":       run.Finished,
+		"messages_sent":  run.MessagesSent,
+		"messages_valid": run.MessagesValid,
+		"failures":       run.Failures,
+		"p50":            int64(run.LatencyP50),
+		"p95":            int64(run.LatencyP95),
+		"p99":            int64(run.LatencyP99),
+		"throughput":     run.Throughput,
+		"c50":            int64(run.ConfirmP50),
+		"c95":            int64(run.ConfirmP95),
+		"c99":            int64(run.ConfirmP99),
+	}
+	tag, err := tx.Exec(
+		ctx,
+		`UPDATE s1.test_run SET status = @status, finished = @finished, messages_sent = @messages_sent,
+			messages_valid = @messages_valid, failures = @failures, latency_p50_ns = NULLIF(@p50, 0),
+			latency_p95_ns = NULLIF(@p95, 0), latency_p99_ns = NULLIF(@p99, 0), throughput = NULLIF(@throughput, 0),
+			confirm_p50_ns = NULLIF(@c50, 0), confirm_p95_ns = NULLIF(@c95, 0), confirm_p99_ns = NULLIF(@c99, 0)
+		WHERE id = @id`,
+		args,
+	)
+	if err != nil {
+		return fmt.Errorf("error finishing test run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRunNotFound
+	}
+
+	for _, result := range run.Results {
+		params, err := json.Marshal(result.Params)
+		if err != nil {
+			return err
+		}
+		if result.Params == nil {
+			params = []byte(`{}`)
+		}
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO s1.test_result (test_run_id, instance, temp_queue, params, started, duration_ns,
+				messages_sent, messages_valid, failures, redelivered, latency_p50_ns, latency_p95_ns,
+				latency_p99_ns, confirm_p50_ns, confirm_p95_ns, confirm_p99_ns, error, details)
+			VALUES (@test_run_id, @instance, @temp_queue, @params, @started, @duration_ns,
+				@messages_sent, @messages_valid, @failures, @redelivered, NULLIF(@p50, 0), NULLIF(@p95, 0),
+				NULLIF(@p99, 0), NULLIF(@c50, 0), NULLIF(@c95, 0), NULLIF(@c99, 0), NULLIF(@error, ''), @details)`,
+			pgx.NamedArgs{
+				"test_run_id":    run.ID,
+				"instance":       result.Instance,
+				"temp_queue":     result.TempQueue,
+				"params":         string(params),
+				"started":        result.Started,
+				"duration_ns":    int64(result.Duration),
+				"messages_sent":  result.MessagesSent,
+				"messages_valid": result.MessagesValid,
+				"failures":       result.Failures,
+				"redelivered":    result.Redelivered,
+				"p50":            int64(result.LatencyP50),
+				"p95":            int64(result.LatencyP95),
+				"p99":            int64(result.LatencyP99),
+				"c50":            int64(result.ConfirmP50),
+				"c95":            int64(result.ConfirmP95),
+				"c99":            int64(result.ConfirmP99),
+				"error":          result.Error,
+				"details":        nullableJSON(result.Details),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("error storing result of instance %d: %w", result.Instance, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing test run: %w", err)
+	}
+	return nil
+}
+
+// GetRun retrieves the run with id together with its results ordered by instance
+func GetRun(pool *pgxpool.Pool, id int) (*TestRun, error) {
+	ctx := context.Background()
+	args := pgx.NamedArgs{"id": id}
+	run, err := scanRun(pool.QueryRow(ctx, `SELECT `+runColumns+` FROM s1.test_run WHERE id = @id`, args))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting test run: %w", err)
+	}
+
+	rows, err := pool.Query(
+		ctx, `SELECT `+resultColumns+` FROM s1.test_result WHERE test_run_id = @id ORDER BY instance`, args,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting test results: %w", err)
+	}
+	run.Results, err = pgx.CollectRows(
+		rows, func(row pgx.CollectableRow) (TestResult, error) { return scanResult(row) },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting test results: %w", err)
+	}
+	return &run, nil
+}
+
+// ListRuns retrieves the runs matching filter, newest first. It also returns the total
+// number of matching runs for pagination.
+func ListRuns(pool *pgxpool.Pool, filter RunFilter) ([]TestRun, int, error) {
+	var conditions []string
+	args := pgx.NamedArgs{}
+
+	if filter.Tenant != "" {
+		conditions = append(conditions, "tenant_id = @tenant")
+		args["tenant"] = filter.Tenant
+	}
+	if filter.Kind != "" {
+		conditions = append(conditions, "kind = @kind")
+		args["kind"] = filter.Kind
+	}
+	if filter.ScenarioID != 0 {
+		conditions = append(conditions, "scenario_id = @scenario_id")
+		args["scenario_id"] = filter.ScenarioID
+	}
+	if filter.Topic != "" {
+		conditions = append(conditions, "topic = @topic")
+		args["topic"] = filter.Topic
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = @status")
+		args["status"] = filter.Status
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "started >= @from")
+		args["from"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "started < @to")
+		args["to"] = filter.To
+	}
+	if len(filter.Namespaces) > 0 {
+		conditions = append(conditions, "split_part(schema_name, '.', 1) = ANY(@namespaces)")
+		args["namespaces"] = filter.Namespaces
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := pool.QueryRow(context.Background(), "SELECT count(*) FROM s1.test_run"+where, args).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting test runs: %w", err)
+	}
+
+	args["limit"] = clampLimit(filter.Limit, DefaultRunLimit, maxRunLimit)
+	args["offset"] = max(filter.Offset, 0)
+	rows, err := pool.Query(
+		context.Background(),
+		`SELECT `+runColumns+` FROM s1.test_run`+where+` ORDER BY started DESC, id DESC LIMIT @limit OFFSET @offset`,
+		args,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing test runs: %w", err)
+	}
+	runs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (TestRun, error) { return scanRun(row) })
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing test runs: %w", err)
+	}
+	return runs, total, nil
+}
+
+// nullableJSON passes empty JSON to Postgres as NULL
+func nullableJSON(data json.RawMessage) *string {
+	if len(data) == 0 {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+var (
+	ErrScenarioNotFound = NewError(ErrNotFound, "scenario not found")
+	ErrScenarioConflict = NewError(ErrConflict, "a scenario with this name already exists")
+)
+
+// ScenarioStore is implemented by stores that hold topic test scenarios
+type ScenarioStore interface {
+	CreateScenario(s TestScenario) (*TestScenario, error)
+	ScenarioByID(id int) (*TestScenario, error)
+	// Scenarios returns the scenarios of tenant, of every tenant when it is empty
+	Scenarios(tenant string) ([]TestScenario, error)
+	UpdateScenario(s TestScenario) (*TestScenario, error)
+	// DeleteScenario removes the scenario, its runs are kept without a scenario ID
+	DeleteScenario(id int) error
+}
+
+const scenarioColumns = "id, name, topic, schema_name, schema_type, schema_version, message_count, rate, mode, " +
+	"options, created, updated, tenant_id"
+
+func scanScenario(row pgx.Row) (TestScenario, error) {
+	var s TestScenario
+	err := row.Scan(
+		&s.ID, &s.Name, &s.Topic, &s.SchemaName, &s.SchemaType, &s.SchemaVersion, &s.MessageCount, &s.Rate,
+		&s.Mode, &s.Options, &s.Created, &s.Updated, &s.Tenant,
+	)
+	return s, err
+}
+
+func scenarioArgs(s TestScenario) pgx.NamedArgs {
+	options := s.Options
+	if len(options) == 0 {
+		options = json.RawMessage(`{}`)
+	}
+	return pgx.NamedArgs{
+		"id":             s.ID,
+		"name":           s.Name,
+		"topic":          s.Topic,
+		"schema_name":    s.SchemaName,
+		"schema_type":    s.SchemaType,
+		"schema_version": s.SchemaVersion,
+		"message_count":  s.MessageCount,
+		"rate":           s.Rate,
+		"mode":           s.Mode,
+		"options":        string(options),
+		"now":            time.Now().UTC(),
+		"tenant":         tenantOrDefault(s.Tenant),
+	}
+}
+
+// CreateScenario stores s in its tenant and returns it with its ID and timestamps,
+// ErrScenarioConflict when the tenant already has a scenario with the name
+func CreateScenario(pool *pgxpool.Pool, s TestScenario) (*TestScenario, error) {
+	query := `INSERT INTO s1.test_scenario (name, topic, schema_name, schema_type, schema_version, message_count,
+				rate, mode, options, created, updated, tenant_id)
+			VALUES (@name, @topic, @schema_name, @schema_type, @schema_version, @message_count,
+				@rate, @mode, @options, @now, @now, @tenant)
+			RETURNING ` + scenarioColumns
+	created, err := scanScenario(pool.QueryRow(context.Background(), query, scenarioArgs(s)))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrScenarioConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating scenario: %w", err)
+	}
+	return &created, nil
+}
+
+// GetScenario retrieves the scenario with id
+func GetScenario(pool *pgxpool.Pool, id int) (*TestScenario, error) {
+	query := `SELECT ` + scenarioColumns + ` FROM s1.test_scenario WHERE id = @id`
+	s, err := scanScenario(pool.QueryRow(context.Background(), query, pgx.NamedArgs{"id": id}))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrScenarioNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting scenario: %w", err)
+	}
+	return &s, nil
+}
+
+// ListScenarios retrieves the scenarios of tenant ordered by ID, every scenario when
+// tenant is empty
+func ListScenarios(pool *pgxpool.Pool, tenant string) ([]TestScenario, error) {
+	rows, err := pool.Query(
+		context.Background(),
+		`SELECT `+scenarioColumns+` FROM s1.test_scenario WHERE @tenant = '' OR tenant_id = @tenant ORDER BY id`,
+		pgx.NamedArgs{"tenant": tenant},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing scenarios: %w", err)
+	}
+	scenarios, err := pgx.CollectRows(
+		rows, func(row pgx.CollectableRow) (TestScenario, error) { return scanScenario(row) },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing scenarios: %w", err)
+	}
+	return scenarios, nil
+}
+
+// UpdateScenario replaces the definition of the scenario with s.ID in the tenant of s,
+// keeping its creation time
+func UpdateScenario(pool *pgxpool.Pool, s TestScenario) (*TestScenario, error) {
+	query := `UPDATE s1.test_scenario SET name = @name, topic = @topic, schema_name = @schema_name,
+				schema_type = @schema_type, schema_version = @schema_version, message_count = @message_count,
+				rate = @rate, mode = @mode, options = @options, updated = @now
+			WHERE id = @id AND tenant_id = @tenant
+			RETURNING ` + scenarioColumns
+	updated, err := scanScenario(pool.QueryRow(context.Background(), query, scenarioArgs(s)))
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, ErrScenarioNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		return nil, ErrScenarioConflict
+	case err != nil:
+		return nil, fmt.Errorf("error updating scenario: %w", err)
+	}
+	return &updated, nil
+}
+
+// DeleteScenario removes the scenario with id, its runs are kept without a scenario ID
+func DeleteScenario(pool *pgxpool.Pool, id int) error {
+	tag, err := pool.Exec(context.Background(), `DELETE FROM s1.test_scenario WHERE id = @id`, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return fmt.Errorf("error deleting scenario: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrScenarioNotFound
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultSearchLimit is how many results a search returns without a limit
+	DefaultSearchLimit = 20
+	// MaxSearchLimit caps the limit a search may ask for
+	MaxSearchLimit = 100
+)
+
+// SearchQuery selects schemas whose name contains or resembles Text. With InData the
+// words of schema documents, such as field, record and message names, are searched too.
+type SearchQuery struct {
+	Text   string
+	Type   string
+	InData bool
+	Limit  int
+	// Tenant whose schemas are searched, empty is DefaultTenant
+	Tenant string
+	// Namespaces limits the search to schemas in these namespaces when not empty
+	Namespaces []string
+}
+
+// SearchResult is a schema found by a search, better matches rank higher
+type SearchResult struct {
+	Schema      Schema
+	Rank        float64
+	NameMatched bool
+	DataMatched bool
+}
+
+// Searcher is implemented by stores that search schemas by name and content
+type Searcher interface {
+	Search(query SearchQuery) ([]SearchResult, error)
+}
+
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchWords splits text into the lower cased words a search looks for in documents
+func searchWords(text string) []string {
+	return searchWord.FindAllString(strings.ToLower(text), -1)
+}
+
+// searchLimit returns the limit of query within bounds
+func searchLimit(query SearchQuery) int {
+	if query.Limit <= 0 {
+		return DefaultSearchLimit
+	}
+	return min(query.Limit, MaxSearchLimit)
+}
+
+// likePattern escapes text for a substring match with ILIKE
+func likePattern(text string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text) + "%"
+}
+
+// SearchSchemas finds live schemas whose name contains text or is similar to it through
+// the trigram index on names. With query.InData the keys and strings of schema documents
+// are matched word by word through the full text index, every word of text has to start
+// a word of the document. Results are ranked by exact name match, name similarity and
+// full text rank.
+func SearchSchemas(pool *pgxpool.Pool, query SearchQuery) ([]SearchResult, error) {
+	args := pgx.NamedArgs{
+		"text": query.Text, "pattern": likePattern(query.Text), "limit": searchLimit(query),
+		"tenant": tenantOrDefault(query.Tenant),
+	}
+	nameMatched := "(name ILIKE @pattern OR name % @text)"
+	dataMatched := "false"
+	dataRank := "0"
+	if words := searchWords(query.Text); query.InData && len(words) > 0 {
+		args["terms"] = strings.Join(words, ":* & ") + ":*"
+		dataMatched = "search_vector @@ to_tsquery('simple', @terms)"
+		dataRank = "ts_rank(search_vector, to_tsquery('simple', @terms))"
+	}
+
+	rank := "(CASE WHEN lower(name) = lower(@text) THEN 1 ELSE 0 END) + similarity(name, @text) + " + dataRank
+
+	conditions := []string{"tenant_id = @tenant", liveSchema, "(" + nameMatched + " OR " + dataMatched + ")"}
+	if query.Type != "" {
+		conditions = append(conditions, "type = @type")
+		args["type"] = query.Type
+	}
+	if len(query.Namespaces) > 0 {
+		conditions = append(conditions, "namespace = ANY(@namespaces)")
+		args["namespaces"] = query.Namespaces
+	}
+	sql := `
+		SELECT ` + schemaColumns + `, rank, name_matched, data_matched FROM (
+			SELECT *,
+			       ` + nameMatched + ` AS name_matched,
+			       ` + dataMatched + ` AS data_matched,
+			       ` + rank + ` AS rank
+			FROM s1.schema
+			WHERE ` + strings.Join(conditions, " AND ") + `
+		) matches
+		ORDER BY rank DESC, name, id
+		LIMIT @limit`
+
+	rows, err := pool.Query(context.Background(), sql, args)
+	if err != nil {
+		return nil, fmt.Errorf("error searching schemas: %w", err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		s := &result.Schema
+		err := rows.Scan(
+			&s.ID, &s.Name, &s.Type, &s.Version, &s.SchemaData, &s.Created, &s.Modified, &s.Status,
+			&s.DeprecateAt, &s.RetireAt, &s.Fingerprint, &s.DeletedAt, &s.Namespace, &s.Tenant, &s.RabinFingerprint,
+			&result.Rank, &result.NameMatched, &result.DataMatched,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning search result: %w", err)
+		}
+		if err := resolveSchema(s); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (s *PostgresStore) Search(query SearchQuery) ([]SearchResult, error) {
+	query.Tenant = s.tenant
+	results, err := SearchSchemas(s.pool, query)
+	return results, unavailable(err)
+}
+
+func (s *NamespacedStore) Search(query SearchQuery) ([]SearchResult, error) {
+	searcher, ok := s.store.(Searcher)
+	if !ok {
+		return nil, fmt.Errorf("error searching schemas: %T cannot search", s.store)
+	}
+	query.Namespaces = s.namespaces
+	results, err := searcher.Search(query)
+	return slices.DeleteFunc(results, func(r SearchResult) bool { return !s.visible(r.Schema) }), err
+}
+
+// Search matches names by substring and documents word by word like the Postgres store.
+// Ranks only order the results, they are not comparable with Postgres ranks.
+func (m *MemoryStore) Search(query SearchQuery) ([]SearchResult, error) {
+	m.mu.RLock()
+	schemas := m.sortedLocked(QueryArgs{Type: query.Type})
+	m.mu.RUnlock()
+
+	text := strings.ToLower(query.Text)
+	words := searchWords(query.Text)
+	results := []SearchResult{}
+	for _, s := range schemas {
+		if len(query.Namespaces) > 0 && !slices.Contains(query.Namespaces, s.Namespace) {
+			continue
+		}
+		if err := resolveSchema(&s); err != nil {
+			return nil, err
+		}
+		result := SearchResult{Schema: s}
+		name := strings.ToLower(s.Name)
+		switch {
+		case name == text:
+			result.NameMatched, result.Rank = true, 2
+		case strings.Contains(name, text):
+			result.NameMatched, result.Rank = true, float64(len(text))/float64(len(name))
+		}
+		if query.InData && len(words) > 0 && containsWords(searchWords(s.SchemaData), words) {
+			result.DataMatched = true
+			result.Rank += 0.1
+		}
+		if result.NameMatched || result.DataMatched {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Schema.Name < results[j].Schema.Name
+	})
+	return results[:min(len(results), searchLimit(query))], nil
+}
+
+// containsWords reports whether every one of words starts a word of document
+func containsWords(document, words []string) bool {
+	for _, w := range words {
+		if !slices.ContainsFunc(document, func(d string) bool { return strings.HasPrefix(d, w) }) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"slices"
+	"time"
+)
+
+// memorySnapshot is the file a MemoryStore is persisted to. The secrets the API never
+// returns are kept so api keys and webhooks still work after a restore.
+type memorySnapshot struct {
+	NextID           int                       `json:"nextId"`
+	Schemas          []Schema                  `json:"schemas"`
+	Audit            []AuditEntry              `json:"audit"`
+	APIKeys          []snapshotAPIKey          `json:"apiKeys"`
+	Scenarios        []TestScenario            `json:"scenarios"`
+	Runs             []TestRun                 `json:"runs"`
+	NextScenarioID   int                       `json:"nextScenarioId"`
+	NextRunID        int                       `json:"nextRunId"`
+	Captures         []Capture                 `json:"captures"`
+	CapturedMessages map[int][]CapturedMessage `json:"capturedMessages"`
+	NextCaptureID    int                       `json:"nextCaptureId"`
+	Webhooks         []snapshotWebhook         `json:"webhooks"`
+	NextWebhookID    int                       `json:"nextWebhookId"`
+	Tenants          []Tenant                  `json:"tenants"`
+}
+
+type snapshotAPIKey struct {
+	APIKey
+	SecretHash string `json:"secretHash"`
+}
+
+type snapshotWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WriteSnapshot writes the whole store, every tenant included, to w as JSON
+func (m *MemoryStore) WriteSnapshot(w io.Writer) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := memorySnapshot{
+		NextID: m.nextID, Audit: m.audit, Scenarios: m.scenarios, Runs: m.runs,
+		NextScenarioID: m.nextScenarioID, NextRunID: m.nextRunID, Captures: m.captures,
+		CapturedMessages: m.capturedMessages, NextCaptureID: m.nextCaptureID, NextWebhookID: m.nextWebhookID,
+		Tenants: m.tenants,
+	}
+	// Sorted so an unchanged store always encodes to the same bytes
+	for _, s := range m.schemas {
+		snapshot.Schemas = append(snapshot.Schemas, s)
+	}
+	slices.SortFunc(snapshot.Schemas, func(a, b Schema) int { return cmp.Compare(a.ID, b.ID) })
+	for _, key := range m.apiKeys {
+		snapshot.APIKeys = append(snapshot.APIKeys, snapshotAPIKey{APIKey: key, SecretHash: key.SecretHash})
+	}
+	for _, hook := range m.webhooks {
+		snapshot.Webhooks = append(snapshot.Webhooks, snapshotWebhook{Webhook: hook, Secret: hook.Secret})
+	}
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadMemoryStore creates a store from a snapshot written by WriteSnapshot
+func ReadMemoryStore(r io.Reader) (*MemoryStore, error) {
+	var snapshot memorySnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("error reading snapshot: %w", err)
+	}
+
+	store := NewMemoryStore()
+	state := store.memoryState
+	state.nextID = max(snapshot.NextID, 1)
+	for _, s := range snapshot.Schemas {
+		state.schemas[s.ID] = s
+	}
+	state.audit = snapshot.Audit
+	for _, key := range snapshot.APIKeys {
+		key.APIKey.SecretHash = key.SecretHash
+		state.apiKeys = append(state.apiKeys, key.APIKey)
+	}
+	state.scenarios, state.runs = snapshot.Scenarios, snapshot.Runs
+	state.nextScenarioID, state.nextRunID = snapshot.NextScenarioID, snapshot.NextRunID
+	state.captures, state.capturedMessages = snapshot.Captures, snapshot.CapturedMessages
+	state.nextCaptureID = snapshot.NextCaptureID
+	for _, hook := range snapshot.Webhooks {
+		hook.Webhook.Secret = hook.Secret
+		state.webhooks = append(state.webhooks, hook.Webhook)
+	}
+	state.nextWebhookID = snapshot.NextWebhookID
+	if len(snapshot.Tenants) > 0 {
+		state.tenants = snapshot.Tenants
+	}
+	return store, nil
+}
+
+// OpenMemoryStore restores the store snapshotted to path, an empty store when path is
+// empty or does not exist yet
+func OpenMemoryStore(path string) (*MemoryStore, error) {
+	if path == "" {
+		return NewMemoryStore(), nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewMemoryStore(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening snapshot: %w", err)
+	}
+	defer f.Close()
+	return ReadMemoryStore(f)
+}
+
+// SaveSnapshot writes the store to path, replacing the previous snapshot only once the
+// new one is complete
+func (m *MemoryStore) SaveSnapshot(path string) error {
+	var buf bytes.Buffer
+	if err := m.WriteSnapshot(&buf); err != nil {
+		return err
+	}
+	return writeSnapshotFile(path, buf.Bytes())
+}
+
+func writeSnapshotFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// SnapshotEvery saves the store to path every interval until ctx is done, skipping the
+// write while nothing changed. Failures are logged and retried at the next interval.
+func (m *MemoryStore) SnapshotEvery(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last []byte
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var buf bytes.Buffer
+			if err := m.WriteSnapshot(&buf); err != nil {
+				log.Printf("Failed to snapshot memory store: %v", err)
+				continue
+			}
+			if bytes.Equal(buf.Bytes(), last) {
+				continue
+			}
+			if err := writeSnapshotFile(path, buf.Bytes()); err != nil {
+				log.Printf("Failed to snapshot memory store: %v", err)
+				continue
+			}
+			last = buf.Bytes()
+		}
+	}
+}
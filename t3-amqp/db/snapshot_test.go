@@ -0,0 +1,59 @@
+package db
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreSnapshot(t *testing.T) {
+	store := NewMemoryStore()
+	_, err := store.CreateTenant(Tenant{ID: "acme"})
+	assert.NoError(t, err)
+	args := QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	id, err := store.Insert(args)
+	assert.NoError(t, err)
+	_, err = store.WithTenant("acme").Insert(args)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete(id))
+	_, err = store.CreateAPIKey(APIKey{Name: "ci", Prefix: "abc", Scope: "read", SecretHash: "hash"})
+	assert.NoError(t, err)
+	_, err = store.CreateWebhook(Webhook{URL: "http://example.com/hook", Secret: "s3cret"})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "store.json")
+	assert.NoError(t, store.SaveSnapshot(path))
+	restored, err := OpenMemoryStore(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var before, after bytes.Buffer
+	assert.NoError(t, store.WriteSnapshot(&before))
+	assert.NoError(t, restored.WriteSnapshot(&after))
+	assert.Equal(t, before.String(), after.String())
+
+	key, err := restored.APIKeyByPrefix("abc")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hash", key.SecretHash)
+	}
+	hooks, err := restored.Webhooks("")
+	if assert.NoError(t, err) && assert.Len(t, hooks, 1) {
+		assert.Equal(t, "s3cret", hooks[0].Secret)
+	}
+	count, err := restored.WithTenant("acme").Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	next, err := restored.Insert(QueryArgs{Name: "payments", Type: "json", Version: "1.0.0", SchemaData: `{}`})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, next, "ids continue after the restored ones")
+
+	empty, err := OpenMemoryStore(filepath.Join(t.TempDir(), "missing.json"))
+	if assert.NoError(t, err) {
+		count, err := empty.Count(true)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	}
+}
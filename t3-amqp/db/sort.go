@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxSchemaLimit caps the page size of a schema listing
+const MaxSchemaLimit = 1000
+
+var ErrInvalidSort = NewError(ErrValidation, "invalid sort")
+
+// sortColumns maps the schema columns a listing may be ordered by to their ORDER BY
+// expression. Versions are ordered semantically through their byte-ordered key.
+var sortColumns = map[string]string{
+	"id": "id", "name": "name", "type": "type", "version": `version_key COLLATE "C"`, "created": "created",
+	"modified": "modified", "status": "status",
+}
+
+// parseSort splits sort fields into column and direction, rejecting unknown columns
+func parseSort(sort []string) ([]string, []bool, error) {
+	var columns []string
+	var desc []bool
+	for _, field := range sort {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		column := strings.TrimPrefix(field, "-")
+		if _, ok := sortColumns[column]; !ok {
+			return nil, nil, fmt.Errorf("%w: unknown column %q", ErrInvalidSort, column)
+		}
+		columns = append(columns, column)
+		desc = append(desc, strings.HasPrefix(field, "-"))
+	}
+	return columns, desc, nil
+}
+
+// orderBy builds an ORDER BY list from sort fields, ending in id so pages are stable
+func orderBy(sort []string) (string, error) {
+	columns, desc, err := parseSort(sort)
+	if err != nil {
+		return "", err
+	}
+
+	var terms []string
+	hasID := false
+	for i, column := range columns {
+		hasID = hasID || column == "id"
+		term := sortColumns[column]
+		if desc[i] {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+	if !hasID {
+		terms = append(terms, "id")
+	}
+	return strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"maps"
+	"time"
+)
+
+// statsGrowthDays is how far back the daily growth series goes
+const statsGrowthDays = 30
+
+// GetSchemaStats summarizes the schemas of tenant by type, namespace and status and
+// returns the number of schemas created per day over the last 30 days. Namespaces limits
+// the summary to schemas in these namespaces when not empty.
+func GetSchemaStats(pool *pgxpool.Pool, tenant string, namespaces []string) (*SchemaStats, error) {
+	stats := &SchemaStats{
+		ByType: map[string]int{}, ByNamespace: map[string]int{}, ByStatus: map[string]int{}, Growth: []DailyCount{},
+	}
+	ctx := context.Background()
+	live := "tenant_id = @tenant AND " + liveSchema
+	liveArgs := pgx.NamedArgs{"tenant": tenantOrDefault(tenant)}
+	if len(namespaces) > 0 {
+		live += " AND namespace = ANY(@namespaces)"
+		liveArgs["namespaces"] = namespaces
+	}
+
+	err := collectCounts(
+		pool, `SELECT type::text, count(*) FROM s1.schema WHERE `+live+` GROUP BY type`, liveArgs, stats.ByType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = collectCounts(
+		pool, `SELECT namespace, count(*) FROM s1.schema WHERE `+live+` GROUP BY namespace`, liveArgs,
+		stats.ByNamespace,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = collectCounts(
+		pool, `SELECT status, count(*) FROM s1.schema WHERE `+live+` GROUP BY status`, liveArgs, stats.ByStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range stats.ByType {
+		stats.Total += n
+	}
+
+	args := pgx.NamedArgs{"since": time.Now().UTC().AddDate(0, 0, -statsGrowthDays)}
+	maps.Copy(args, liveArgs)
+	rows, err := pool.Query(
+		ctx, `SELECT date_trunc('day', created), count(*) FROM s1.schema
+		WHERE created >= @since AND `+live+` GROUP BY 1 ORDER BY 1`, args,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying schema growth: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day DailyCount
+		if err := rows.Scan(&day.Day, &day.Count); err != nil {
+			return nil, fmt.Errorf("error scanning schema growth: %w", err)
+		}
+		stats.Growth = append(stats.Growth, day)
+	}
+
+	return stats, rows.Err()
+}
+
+func collectCounts(pool *pgxpool.Pool, query string, args pgx.NamedArgs, into map[string]int) error {
+	rows, err := pool.Query(context.Background(), query, args)
+	if err != nil {
+		return fmt.Errorf("error querying schema stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return fmt.Errorf("error scanning schema stats: %w", err)
+		}
+		into[key] = count
+	}
+	return rows.Err()
+}
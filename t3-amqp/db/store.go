@@ -0,0 +1,310 @@
+package db
+
+import (
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"slices"
+	"time"
+)
+
+// SchemaStore is the schema persistence the REST layer depends on
+type SchemaStore interface {
+	Insert(params QueryArgs) (int, error)
+	GetByID(id int) (*Schema, error)
+	GetByIDs(ids []int) ([]Schema, error)
+	Filter(params QueryArgs) ([]Schema, error)
+	// Latest resolves the latest version of a schema, see LatestIndex
+	Latest(name, schemaType string) (*Schema, error)
+	// Update replaces the data of an existing version, returning ErrSchemaNotFound for
+	// versions that are not live
+	Update(params QueryArgs) ([]Schema, error)
+	// Delete soft-deletes a schema, Restore brings it back
+	Delete(id int) error
+	Restore(id int) (*Schema, error)
+	// List calls fn for the schemas selected by opts, stopping at the first error
+	List(opts ListOptions, fn func(Schema) error) error
+	Count(includeDeleted bool) (int, error)
+}
+
+// AuditRecorder is implemented by stores that keep an audit trail of mutations
+type AuditRecorder interface {
+	RecordAudit(entry AuditEntry) error
+}
+
+// Upserter is implemented by stores that register or update a version in one step
+type Upserter interface {
+	// Upsert reports whether the version was created rather than updated
+	Upsert(params QueryArgs) (*Schema, bool, error)
+}
+
+// PostgresStore is the SchemaStore backed by the s1 tables. Errors reaching the database
+// are marked with ErrUnavailable. Schemas are those of DefaultTenant unless the store
+// was scoped with ForTenant.
+type PostgresStore struct {
+	pool   *pgxpool.Pool
+	tenant string
+}
+
+// NewPostgresStore creates a store on pool
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) ForTenant(tenant string) SchemaStore {
+	return s.WithTenant(tenant)
+}
+
+// WithTenant returns the store on the same pool scoped to the schemas of tenant
+func (s *PostgresStore) WithTenant(tenant string) *PostgresStore {
+	return &PostgresStore{pool: s.pool, tenant: tenant}
+}
+
+// owns returns ErrSchemaNotFound unless the schema with id belongs to the store's tenant
+func (s *PostgresStore) owns(id int) error {
+	owned, err := SchemaOwnedBy(s.pool, s.tenant, id)
+	if err != nil {
+		return unavailable(err)
+	}
+	if !owned {
+		return fmt.Errorf("error getting schema %d: %w", id, ErrSchemaNotFound)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Insert(params QueryArgs) (int, error) {
+	params.Tenant = s.tenant
+	id, err := InsertSchema(s.pool, params)
+	return id, unavailable(err)
+}
+
+func (s *PostgresStore) InsertBatch(params []QueryArgs) ([]int, error) {
+	params = slices.Clone(params)
+	for i := range params {
+		params[i].Tenant = s.tenant
+	}
+	ids, err := BatchInsertSchemas(s.pool, params)
+	return ids, unavailable(err)
+}
+
+func (s *PostgresStore) GetByID(id int) (*Schema, error) {
+	schema, err := GetSchemaById(s.pool, id)
+	if err == nil && schema.Tenant != tenantOrDefault(s.tenant) {
+		return nil, fmt.Errorf("error getting schema %d: %w", id, ErrSchemaNotFound)
+	}
+	return schema, unavailable(err)
+}
+
+func (s *PostgresStore) GetByIDs(ids []int) ([]Schema, error) {
+	schemas, err := GetSchemasByIds(s.pool, ids)
+	tenant := tenantOrDefault(s.tenant)
+	schemas = slices.DeleteFunc(schemas, func(schema Schema) bool { return schema.Tenant != tenant })
+	return schemas, unavailable(err)
+}
+
+func (s *PostgresStore) Filter(params QueryArgs) ([]Schema, error) {
+	params.Tenant = s.tenant
+	schemas, err := GetSchemaFilterParams(s.pool, params)
+	return schemas, unavailable(err)
+}
+
+func (s *PostgresStore) Latest(name, schemaType string) (*Schema, error) {
+	schema, err := GetLatestSchema(s.pool, s.tenant, name, schemaType)
+	return schema, unavailable(err)
+}
+
+func (s *PostgresStore) ResolveName(name string) (string, error) {
+	canonical, err := ResolveSchemaName(s.pool, s.tenant, name)
+	return canonical, unavailable(err)
+}
+
+func (s *PostgresStore) Update(params QueryArgs) ([]Schema, error) {
+	params.Tenant = s.tenant
+	schemas, err := UpdateSchemaData(s.pool, params)
+	return schemas, unavailable(err)
+}
+
+func (s *PostgresStore) Upsert(params QueryArgs) (*Schema, bool, error) {
+	params.Tenant = s.tenant
+	schema, created, err := UpsertSchema(s.pool, params)
+	return schema, created, unavailable(err)
+}
+
+func (s *PostgresStore) Delete(id int) error {
+	if err := s.owns(id); err != nil {
+		return err
+	}
+	return unavailable(DeleteSchema(s.pool, id))
+}
+
+func (s *PostgresStore) Restore(id int) (*Schema, error) {
+	if err := s.owns(id); err != nil {
+		return nil, err
+	}
+	schema, err := RestoreSchema(s.pool, id)
+	return schema, unavailable(err)
+}
+
+func (s *PostgresStore) List(opts ListOptions, fn func(Schema) error) error {
+	opts.Tenant = s.tenant
+	return unavailable(StreamSchemas(s.pool, opts, fn))
+}
+
+func (s *PostgresStore) References(id int) ([]Schema, error) {
+	if err := s.owns(id); err != nil {
+		return nil, err
+	}
+	schemas, err := GetSchemaReferences(s.pool, id)
+	return schemas, unavailable(err)
+}
+
+func (s *PostgresStore) Dependents(id int) ([]Schema, error) {
+	if err := s.owns(id); err != nil {
+		return nil, err
+	}
+	schemas, err := GetSchemaDependents(s.pool, id)
+	return schemas, unavailable(err)
+}
+
+func (s *PostgresStore) Count(includeDeleted bool) (int, error) {
+	count, err := CountSchemas(s.pool, s.tenant, includeDeleted)
+	return count, unavailable(err)
+}
+
+// RecordAudit records entry in the store's tenant unless it names one
+func (s *PostgresStore) RecordAudit(entry AuditEntry) error {
+	if entry.Tenant == "" {
+		entry.Tenant = s.tenant
+	}
+	return unavailable(RecordAudit(s.pool, entry))
+}
+
+func (s *PostgresStore) CreateTenant(tenant Tenant) (*Tenant, error) {
+	created, err := CreateTenant(s.pool, tenant)
+	return created, unavailable(err)
+}
+
+func (s *PostgresStore) TenantByID(id string) (*Tenant, error) {
+	tenant, err := GetTenant(s.pool, id)
+	return tenant, unavailable(err)
+}
+
+func (s *PostgresStore) Tenants() ([]Tenant, error) {
+	tenants, err := ListTenants(s.pool)
+	return tenants, unavailable(err)
+}
+
+func (s *PostgresStore) DeleteTenant(id string) error {
+	return unavailable(DeleteTenant(s.pool, id))
+}
+
+func (s *PostgresStore) CreateAPIKey(key APIKey) (*APIKey, error) {
+	created, err := CreateAPIKey(s.pool, key)
+	return created, unavailable(err)
+}
+
+func (s *PostgresStore) APIKeyByPrefix(prefix string) (*APIKey, error) {
+	key, err := GetAPIKeyByPrefix(s.pool, prefix)
+	return key, unavailable(err)
+}
+
+func (s *PostgresStore) APIKeys() ([]APIKey, error) {
+	keys, err := ListAPIKeys(s.pool)
+	return keys, unavailable(err)
+}
+
+func (s *PostgresStore) RevokeAPIKey(id int) error {
+	return unavailable(RevokeAPIKey(s.pool, id))
+}
+
+func (s *PostgresStore) CreateScenario(scenario TestScenario) (*TestScenario, error) {
+	created, err := CreateScenario(s.pool, scenario)
+	return created, unavailable(err)
+}
+
+func (s *PostgresStore) ScenarioByID(id int) (*TestScenario, error) {
+	scenario, err := GetScenario(s.pool, id)
+	return scenario, unavailable(err)
+}
+
+func (s *PostgresStore) Scenarios(tenant string) ([]TestScenario, error) {
+	scenarios, err := ListScenarios(s.pool, tenant)
+	return scenarios, unavailable(err)
+}
+
+func (s *PostgresStore) UpdateScenario(scenario TestScenario) (*TestScenario, error) {
+	updated, err := UpdateScenario(s.pool, scenario)
+	return updated, unavailable(err)
+}
+
+func (s *PostgresStore) DeleteScenario(id int) error {
+	return unavailable(DeleteScenario(s.pool, id))
+}
+
+func (s *PostgresStore) CreateRun(run TestRun) (*TestRun, error) {
+	created, err := CreateRun(s.pool, run)
+	return created, unavailable(err)
+}
+
+func (s *PostgresStore) FinishRun(run TestRun) error {
+	return unavailable(FinishRun(s.pool, run))
+}
+
+func (s *PostgresStore) RunByID(id int) (*TestRun, error) {
+	run, err := GetRun(s.pool, id)
+	return run, unavailable(err)
+}
+
+func (s *PostgresStore) Runs(filter RunFilter) ([]TestRun, int, error) {
+	runs, total, err := ListRuns(s.pool, filter)
+	return runs, total, unavailable(err)
+}
+
+func (s *PostgresStore) CreateCapture(c Capture) (*Capture, error) {
+	created, err := CreateCapture(s.pool, c)
+	return created, unavailable(err)
+}
+
+func (s *PostgresStore) AddCapturedMessages(id int, messages []CapturedMessage) error {
+	return unavailable(AddCapturedMessages(s.pool, id, messages))
+}
+
+func (s *PostgresStore) FinishCapture(c Capture) error {
+	return unavailable(FinishCapture(s.pool, c))
+}
+
+func (s *PostgresStore) CaptureByID(id int) (*Capture, error) {
+	c, err := GetCapture(s.pool, id)
+	return c, unavailable(err)
+}
+
+func (s *PostgresStore) Captures(tenant string) ([]Capture, error) {
+	captures, err := ListCaptures(s.pool, tenant)
+	return captures, unavailable(err)
+}
+
+func (s *PostgresStore) CapturedMessages(id int) ([]CapturedMessage, error) {
+	messages, err := ListCapturedMessages(s.pool, id)
+	return messages, unavailable(err)
+}
+
+func (s *PostgresStore) DeleteCapture(id int) error {
+	return unavailable(DeleteCapture(s.pool, id))
+}
+
+func (s *PostgresStore) CreateWebhook(hook Webhook) (*Webhook, error) {
+	created, err := CreateWebhook(s.pool, hook)
+	return created, unavailable(err)
+}
+
+func (s *PostgresStore) Webhooks(tenant string) ([]Webhook, error) {
+	hooks, err := ListWebhooks(s.pool, tenant)
+	return hooks, unavailable(err)
+}
+
+func (s *PostgresStore) DeleteWebhook(id int) error {
+	return unavailable(DeleteWebhook(s.pool, id))
+}
+
+func (s *PostgresStore) RecordWebhookDelivery(id int, at time.Time, status int, deliveryErr string) error {
+	return unavailable(RecordWebhookDelivery(s.pool, id, at, status, deliveryErr))
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"regexp"
+	"time"
+)
+
+// DefaultTenant owns the schemas of callers that do not name a tenant and everything
+// registered before tenants existed
+const DefaultTenant = "default"
+
+var (
+	ErrTenantNotFound = NewError(ErrNotFound, "tenant not found")
+	ErrTenantConflict = NewError(ErrConflict, "a tenant with this id already exists")
+	ErrTenantInUse    = NewError(ErrConflict, "tenant still owns schemas, api keys, scenarios or webhooks")
+	ErrTenantDenied   = NewError(ErrForbidden, "tenant not allowed")
+	ErrInvalidTenant  = NewError(ErrValidation, "tenant ids are lower case letters, digits and dashes")
+)
+
+var tenantID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// ValidTenantID reports whether id may name a tenant
+func ValidTenantID(id string) bool {
+	return tenantID.MatchString(id)
+}
+
+// tenantOrDefault returns tenant, DefaultTenant when it is empty
+func tenantOrDefault(tenant string) string {
+	if tenant == "" {
+		return DefaultTenant
+	}
+	return tenant
+}
+
+// TenantStore is implemented by stores that hold the tenants of the registry
+type TenantStore interface {
+	CreateTenant(tenant Tenant) (*Tenant, error)
+	TenantByID(id string) (*Tenant, error)
+	Tenants() ([]Tenant, error)
+	// DeleteTenant fails with ErrTenantInUse while the tenant owns schemas, api keys,
+	// scenarios or webhooks
+	DeleteTenant(id string) error
+}
+
+// TenantScoper is implemented by stores that keep the schemas of several tenants apart.
+// ForTenant returns a view of the store that only reads and writes the schemas of tenant.
+type TenantScoper interface {
+	ForTenant(tenant string) SchemaStore
+}
+
+// ForTenant limits store to the schemas of tenant when it can tell tenants apart, other
+// stores serve a single tenant and are returned as they are
+func ForTenant(store SchemaStore, tenant string) SchemaStore {
+	if scoper, ok := store.(TenantScoper); ok {
+		return scoper.ForTenant(tenant)
+	}
+	return store
+}
+
+// CreateTenant stores tenant and returns it with its creation time
+func CreateTenant(pool *pgxpool.Pool, tenant Tenant) (*Tenant, error) {
+	if !ValidTenantID(tenant.ID) {
+		return nil, ErrInvalidTenant
+	}
+	created := Tenant{ID: tenant.ID, Name: tenant.Name, Created: time.Now().UTC()}
+	_, err := pool.Exec(
+		context.Background(), `INSERT INTO s1.tenant (id, name, created) VALUES (@id, @name, @created)`,
+		pgx.NamedArgs{"id": created.ID, "name": created.Name, "created": created.Created},
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrTenantConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating tenant: %w", err)
+	}
+	return &created, nil
+}
+
+// GetTenant retrieves the tenant with id
+func GetTenant(pool *pgxpool.Pool, id string) (*Tenant, error) {
+	var tenant Tenant
+	err := pool.QueryRow(
+		context.Background(), `SELECT id, name, created FROM s1.tenant WHERE id = @id`,
+		cached(pool, pgx.NamedArgs{"id": id})...,
+	).Scan(&tenant.ID, &tenant.Name, &tenant.Created)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error getting tenant %s: %w", id, ErrTenantNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+// ListTenants retrieves every tenant ordered by id
+func ListTenants(pool *pgxpool.Pool) ([]Tenant, error) {
+	rows, err := pool.Query(context.Background(), `SELECT id, name, created FROM s1.tenant ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tenants: %w", err)
+	}
+	tenants, err := pgx.CollectRows(
+		rows, func(row pgx.CollectableRow) (Tenant, error) {
+			var tenant Tenant
+			err := row.Scan(&tenant.ID, &tenant.Name, &tenant.Created)
+			return tenant, err
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// DeleteTenant removes the tenant with id. Schemas, deleted ones included, aliases, api
+// keys, scenarios and webhooks keep it through their foreign keys, and the default tenant is never removed.
+func DeleteTenant(pool *pgxpool.Pool, id string) error {
+	if id == DefaultTenant {
+		return ErrTenantInUse
+	}
+	tag, err := pool.Exec(context.Background(), `DELETE FROM s1.tenant WHERE id = @id`, pgx.NamedArgs{"id": id})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrTenantInUse
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting tenant: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
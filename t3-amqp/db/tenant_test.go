@@ -0,0 +1,57 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTenants(t *testing.T) {
+	store := NewMemoryStore()
+	_, err := store.CreateTenant(Tenant{ID: "acme", Name: "Acme"})
+	assert.NoError(t, err)
+	_, err = store.CreateTenant(Tenant{ID: "acme"})
+	assert.True(t, errors.Is(err, ErrTenantConflict))
+	_, err = store.CreateTenant(Tenant{ID: "Not Valid"})
+	assert.True(t, errors.Is(err, ErrInvalidTenant))
+
+	acme := ForTenant(store, "acme")
+	args := QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	defaultID, err := store.Insert(args)
+	assert.NoError(t, err)
+	acmeID, err := acme.Insert(args)
+	assert.NoError(t, err, "the same version registers in another tenant")
+
+	schemas, err := acme.Filter(QueryArgs{Name: "orders"})
+	if !assert.NoError(t, err) || !assert.Len(t, schemas, 1) {
+		return
+	}
+	assert.Equal(t, acmeID, schemas[0].ID)
+	assert.Equal(t, "acme", schemas[0].Tenant)
+
+	_, err = acme.GetByID(defaultID)
+	assert.True(t, errors.Is(err, ErrSchemaNotFound))
+	assert.True(t, errors.Is(acme.Delete(defaultID), ErrSchemaNotFound))
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.True(t, errors.Is(store.DeleteTenant("acme"), ErrTenantInUse))
+	assert.True(t, errors.Is(store.DeleteTenant(DefaultTenant), ErrTenantInUse))
+	assert.NoError(t, acme.Delete(acmeID))
+	assert.True(t, errors.Is(store.DeleteTenant("acme"), ErrTenantInUse), "deleted schemas keep their tenant")
+
+	_, err = store.CreateTenant(Tenant{ID: "empty"})
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteTenant("empty"))
+	assert.True(t, errors.Is(store.DeleteTenant("empty"), ErrTenantNotFound))
+
+	tenants, err := store.Tenants()
+	assert.NoError(t, err)
+	ids := make([]string, len(tenants))
+	for i, tenant := range tenants {
+		ids[i] = tenant.ID
+	}
+	assert.Equal(t, []string{"acme", DefaultTenant}, ids)
+}
@@ -1,20 +1,285 @@
 package db
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type QueryArgs struct {
 	Name       string
 	Type       string
 	Version    string
 	SchemaData string
+	// Fingerprint identifies schema_data independently of formatting, the hex SHA-256 of
+	// its canonical form
+	Fingerprint string
+	// RabinFingerprint is the hex CRC-64-AVRO fingerprint of Avro schemas
+	RabinFingerprint string
+	// IncludeDeleted also matches soft-deleted schemas
+	IncludeDeleted bool
+	// Tenant the schemas belong to, empty is DefaultTenant
+	Tenant string
 }
 
 type Schema struct {
-	ID         int
-	Name       string
+	ID          int
+	Name        string
+	Type        string
+	Version     string
+	SchemaData  string
+	Created     time.Time
+	Modified    time.Time
+	Status      string
+	DeprecateAt *time.Time
+	RetireAt    *time.Time
+	Fingerprint string
+	// RabinFingerprint is only set for Avro schemas
+	RabinFingerprint string
+	DeletedAt        *time.Time
+	// Namespace is derived from Name, see Namespace
+	Namespace string
+	Tenant    string
+}
+
+// ListOptions pages and orders a schema listing. Sort holds column names, a leading -
+// sorts that column descending. A Limit of 0 lists every schema after Offset.
+// Soft-deleted schemas are only listed with IncludeDeleted.
+type ListOptions struct {
+	Limit          int
+	Offset         int
+	Sort           []string
+	IncludeDeleted bool
+	// Namespaces limits the listing to schemas in these namespaces when not empty
+	Namespaces []string
+	// Tenant whose schemas are listed, empty is DefaultTenant
+	Tenant string
+}
+
+// Tenant is a team sharing the registry, its schemas are invisible to other tenants
+type Tenant struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+}
+
+type Alias struct {
+	Alias   string    `json:"alias"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+}
+
+// APIKey is a credential for the REST API. Only the SHA-256 of its secret is kept,
+// Prefix identifies the key in listings and logs without revealing it.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	SecretHash string     `json:"-"`
+	Created    time.Time  `json:"created"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	// Namespaces restricts the key to schemas in these namespaces, empty allows all
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Tenant binds the key to one tenant, empty lets it choose one with X-Tenant
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Webhook is an HTTP endpoint told about schema changes. Secret signs the callbacks and
+// is only returned when the webhook is created. Events limits the callbacks to these
+// event types, empty sends every one. The Last fields describe the latest delivery
+// attempt, LastStatus is 0 when it got no response.
+type Webhook struct {
+	ID           int        `json:"id"`
+	URL          string     `json:"url"`
+	Secret       string     `json:"-"`
+	Events       []string   `json:"events,omitempty"`
+	Created      time.Time  `json:"created"`
+	LastDelivery *time.Time `json:"lastDelivery,omitempty"`
+	LastStatus   int        `json:"lastStatus,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	// Tenant whose schema events the webhook receives, empty is DefaultTenant
+	Tenant string `json:"-"`
+}
+
+// TestScenario is a stored topic test scenario. Options holds the remaining scenario
+// settings as JSON, the store does not interpret them.
+type TestScenario struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Topic         string          `json:"topic"`
+	SchemaName    string          `json:"schemaName"`
+	SchemaType    string          `json:"schemaType"`
+	SchemaVersion string          `json:"schemaVersion"`
+	MessageCount  int             `json:"messageCount"`
+	Rate          int             `json:"rate"`
+	Mode          string          `json:"mode"`
+	Options       json.RawMessage `json:"options,omitempty"`
+	Created       time.Time       `json:"created"`
+	Updated       time.Time       `json:"updated"`
+	// Tenant owns the scenario, empty is DefaultTenant
+	Tenant string `json:"-"`
+}
+
+// TestRun is one execution of a topic test, a scenario run or a load test. ScenarioID is
+// nil for runs that were not started from a stored scenario or whose scenario has since
+// been deleted, the latency percentiles are zero until the run finished with consumed
+// messages. Throughput and the confirm percentiles are only measured by load tests.
+type TestRun struct {
+	ID            int           `json:"id"`
+	Kind          string        `json:"kind"`
+	ScenarioID    *int          `json:"scenarioId,omitempty"`
+	ScenarioName  string        `json:"scenarioName,omitempty"`
+	Topic         string        `json:"topic"`
+	SchemaName    string        `json:"schemaName"`
+	RunID         string        `json:"runId"`
+	Status        string        `json:"status"`
+	Started       time.Time     `json:"started"`
+	Finished      *time.Time    `json:"finished,omitempty"`
+	MessagesSent  int           `json:"messagesSent"`
+	MessagesValid int           `json:"messagesValid"`
+	Failures      int           `json:"failures"`
+	LatencyP50    time.Duration `json:"latencyP50Ns,omitempty"`
+	LatencyP95    time.Duration `json:"latencyP95Ns,omitempty"`
+	LatencyP99    time.Duration `json:"latencyP99Ns,omitempty"`
+	Throughput    float64       `json:"throughput,omitempty"`
+	ConfirmP50    time.Duration `json:"confirmP50Ns,omitempty"`
+	ConfirmP95    time.Duration `json:"confirmP95Ns,omitempty"`
+	ConfirmP99    time.Duration `json:"confirmP99Ns,omitempty"`
+	// Results is only filled in when a single run is retrieved
+	Results []TestResult `json:"results,omitempty"`
+	// Tenant the run was started in, empty is DefaultTenant
+	Tenant string `json:"-"`
+}
+
+// TestResult is the outcome of one instance of a test run. Details holds the remaining
+// instance stats as JSON, the store does not interpret them.
+type TestResult struct {
+	Instance      int               `json:"instance"`
+	TempQueue     string            `json:"tempQueue"`
+	Params        map[string]string `json:"params,omitempty"`
+	Started       time.Time         `json:"started"`
+	Duration      time.Duration     `json:"durationNs"`
+	MessagesSent  int               `json:"messagesSent"`
+	MessagesValid int               `json:"messagesValid"`
+	Failures      int               `json:"failures"`
+	Redelivered   int               `json:"redelivered"`
+	LatencyP50    time.Duration     `json:"latencyP50Ns,omitempty"`
+	LatencyP95    time.Duration     `json:"latencyP95Ns,omitempty"`
+	LatencyP99    time.Duration     `json:"latencyP99Ns,omitempty"`
+	ConfirmP50    time.Duration     `json:"confirmP50Ns,omitempty"`
+	ConfirmP95    time.Duration     `json:"confirmP95Ns,omitempty"`
+	ConfirmP99    time.Duration     `json:"confirmP99Ns,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	Details       json.RawMessage   `json:"details,omitempty"`
+}
+
+// RunFilter selects test runs, zero fields match every run. Namespaces restricts the
+// runs to schemas in these namespaces, empty allows all.
+type RunFilter struct {
+	Tenant     string
+	Kind       string
+	ScenarioID int
+	Topic      string
+	Status     string
+	From       time.Time
+	To         time.Time
+	Namespaces []string
+	Limit      int
+	Offset     int
+}
+
+// Capture is a recording of the messages published to a topic. It is running until the
+// capture ended, Messages counts the messages stored so far.
+type Capture struct {
+	ID       int        `json:"id"`
+	Topic    string     `json:"topic"`
+	Status   string     `json:"status"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Messages int        `json:"messages"`
+	Error    string     `json:"error,omitempty"`
+	// Tenant the capture was started in, empty is DefaultTenant
+	Tenant string `json:"-"`
+}
+
+// CapturedMessage is one message of a capture, Seq is its position in the order the
+// messages were received. Headers holds the AMQP headers as a JSON object.
+type CapturedMessage struct {
+	Seq           int             `json:"seq"`
+	Received      time.Time       `json:"received"`
+	Exchange      string          `json:"exchange"`
+	RoutingKey    string          `json:"routingKey"`
+	ContentType   string          `json:"contentType,omitempty"`
+	MessageID     string          `json:"messageId,omitempty"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	Headers       json.RawMessage `json:"headers,omitempty"`
+	Body          []byte          `json:"body"`
+}
+
+// AuditEntry records one mutation. Before and After hold the schema as it was and as it
+// became, inserts have no Before and deletes no After.
+type AuditEntry struct {
+	ID         int             `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	SchemaID   int             `json:"schemaId,omitempty"`
+	SchemaName string          `json:"schemaName,omitempty"`
+	SourceIP   string          `json:"sourceIp,omitempty"`
+	Before     *SchemaSnapshot `json:"before,omitempty"`
+	After      *SchemaSnapshot `json:"after,omitempty"`
+	Tenant     string          `json:"tenant"`
+	Created    time.Time       `json:"created"`
+}
+
+// SchemaSnapshot is the state of a schema kept in an audit entry
+type SchemaSnapshot struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Version     string          `json:"version"`
+	Status      string          `json:"status,omitempty"`
+	SchemaData  json.RawMessage `json:"schemaData,omitempty"`
+	Fingerprint string          `json:"fingerprint,omitempty"`
+	DeprecateAt *time.Time      `json:"deprecateAt,omitempty"`
+	RetireAt    *time.Time      `json:"retireAt,omitempty"`
+	DeletedAt   *time.Time      `json:"deletedAt,omitempty"`
+}
+
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	SchemaName string
+	SchemaID   int
+	SourceIP   string
+	// Tenant of the entries, empty is DefaultTenant
+	Tenant string
+	// Namespaces limits the entries to schemas in these namespaces when not empty
+	Namespaces []string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
+
+type DailyCount struct {
+	Day   time.Time `json:"day"`
+	Count int       `json:"count"`
+}
+
+type SchemaStats struct {
+	Total       int            `json:"total"`
+	ByType      map[string]int `json:"byType"`
+	ByNamespace map[string]int `json:"byNamespace"`
+	ByStatus    map[string]int `json:"byStatus"`
+	Growth      []DailyCount   `json:"growth"`
+}
+
+type BulkFilter struct {
+	NamePrefix string
 	Type       string
-	Version    string
-	SchemaData string
-	Created    time.Time
-	Modified   time.Time
+	OlderThan  time.Time
+	// Status is the lifecycle status of the schemas, e.g. retired
+	Status string
+	// Tenant of the schemas, empty is DefaultTenant
+	Tenant string
 }
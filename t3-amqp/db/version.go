@@ -0,0 +1,135 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LatestVersion is the version query value that resolves to the newest version
+const LatestVersion = "latest"
+
+// semver is a parsed major.minor.patch[-prerelease] version, build metadata is dropped
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parseSemver parses a semantic version, tolerating a leading v and missing minor or
+// patch numbers such as 1 or 1.2
+func parseSemver(version string) (semver, bool) {
+	var v semver
+	s := strings.TrimPrefix(version, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	if hasPre {
+		if pre == "" {
+			return v, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// ValidVersion reports whether version is a semantic version, tolerating a leading v and
+// missing minor or patch numbers
+func ValidVersion(version string) bool {
+	_, ok := parseSemver(version)
+	return ok
+}
+
+// VersionKey encodes version so that comparing keys byte by byte orders versions
+// semantically. Numbers are zero padded, a release sorts after its prereleases (~ is
+// above -), numeric prerelease identifiers sort below alphanumeric ones and every
+// identifier ends in a space so a shorter prefix sorts first. Versions that are not
+// semantic sort before all that are, and among themselves as plain strings.
+func VersionKey(version string) string {
+	v, ok := parseSemver(version)
+	if !ok {
+		return "0" + version
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "1%020d.%020d.%020d", v.core[0], v.core[1], v.core[2])
+	if v.prerelease == nil {
+		b.WriteString("~")
+		return b.String()
+	}
+	b.WriteString("-")
+	for _, id := range v.prerelease {
+		if n, err := strconv.Atoi(id); err == nil && n >= 0 {
+			fmt.Fprintf(&b, "0%020d ", n)
+		} else {
+			b.WriteString("1" + id + " ")
+		}
+	}
+	return b.String()
+}
+
+// CompareVersions orders two schema versions by semantic version, see VersionKey
+func CompareVersions(a, b string) int {
+	return strings.Compare(VersionKey(a), VersionKey(b))
+}
+
+// NextMajorVersion returns the release after the highest major version among versions,
+// 1.0.0 when none of them is semantic
+func NextMajorVersion(versions []string) string {
+	major := 0
+	for _, version := range versions {
+		if v, ok := parseSemver(version); ok {
+			major = max(major, v.core[0]+1)
+		}
+	}
+	return fmt.Sprintf("%d.0.0", max(major, 1))
+}
+
+// IsPrerelease reports whether version is a semantic version with a prerelease part
+func IsPrerelease(version string) bool {
+	v, ok := parseSemver(version)
+	return ok && v.prerelease != nil
+}
+
+// SortByVersion orders schemas by ascending semantic version, keeping the ID order of
+// versions that compare equal
+func SortByVersion(schemas []Schema) {
+	sort.SliceStable(schemas, func(i, j int) bool {
+		return CompareVersions(schemas[i].Version, schemas[j].Version) < 0
+	})
+}
+
+// LatestIndex returns the index of the newest release that is not retired in schemas
+// sorted by version, falling back to the newest prerelease and then to the newest
+// version when there is no such release
+func LatestIndex(schemas []Schema) int {
+	for _, release := range []bool{true, false} {
+		for i := len(schemas) - 1; i >= 0; i-- {
+			if schemas[i].Status != StatusRetired && (!release || !IsPrerelease(schemas[i].Version)) {
+				return i
+			}
+		}
+	}
+	return len(schemas) - 1
+}
+
+// latestSchema picks the latest of one schema's versions, see LatestIndex
+func latestSchema(schemas []Schema, name, schemaType string) (*Schema, error) {
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("error getting latest %s/%s: %w", name, schemaType, ErrSchemaNotFound)
+	}
+	SortByVersion(schemas)
+	latest := schemas[LatestIndex(schemas)]
+	return &latest, nil
+}
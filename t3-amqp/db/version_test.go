@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	ordered := []string{
+		"draft", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11",
+		"1.0.0-rc.1", "1.0.0", "v1.0.1", "1.2", "1.10.0", "2",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		assert.Equal(t, -1, CompareVersions(ordered[i], ordered[i+1]), "%s < %s", ordered[i], ordered[i+1])
+		assert.Equal(t, 1, CompareVersions(ordered[i+1], ordered[i]), "%s > %s", ordered[i+1], ordered[i])
+	}
+	assert.Equal(t, 0, CompareVersions("1.0.0", "v1.0.0+build.5"))
+	assert.Equal(t, -1, CompareVersions("1.0.9", "1.0.10"))
+	assert.Equal(t, -1, CompareVersions("1.0.0-a.x", "1.0.0-a-b"), "identifiers compare before separators")
+	assert.True(t, IsPrerelease("2.0.0-rc.1"))
+	assert.False(t, IsPrerelease("2.0.0"))
+	assert.False(t, IsPrerelease("draft"))
+	assert.Equal(t, "1.0.0", NextMajorVersion(nil))
+	assert.Equal(t, "1.0.0", NextMajorVersion([]string{"draft"}))
+	assert.Equal(t, "3.0.0", NextMajorVersion([]string{"1.4.0", "2.0.0-rc.1", "draft"}))
+	for _, version := range []string{"1.0.0", "v1", "1.2", "2.0.0-rc.1", "1.0.0+build.5"} {
+		assert.True(t, ValidVersion(version), version)
+	}
+	for _, version := range []string{"", "draft", LatestVersion, "1.2.3.4", "1.0.0-", "1.x"} {
+		assert.False(t, ValidVersion(version), version)
+	}
+}
+
+func TestLatestSchema(t *testing.T) {
+	schemas := []Schema{
+		{ID: 1, Version: "1.0.10", Status: StatusRetired}, {ID: 2, Version: "1.0.9"}, {ID: 3, Version: "2.0.0-rc.1"},
+	}
+	latest, err := latestSchema(schemas, "orders", "json")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, latest.ID, "retired versions and prereleases are skipped")
+
+	latest, err = latestSchema(schemas[2:], "orders", "json")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, latest.ID, "a prerelease is used when nothing was released")
+
+	_, err = latestSchema(nil, "orders", "json")
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+}
+
+func TestSortByVersion(t *testing.T) {
+	schemas := []Schema{{ID: 1, Version: "1.10.0"}, {ID: 2, Version: "1.2.0"}, {ID: 3, Version: "1.2.0-rc.1"}}
+	SortByVersion(schemas)
+	assert.Equal(t, []string{"1.2.0-rc.1", "1.2.0", "1.10.0"}, []string{schemas[0].Version, schemas[1].Version, schemas[2].Version})
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+var ErrWebhookNotFound = NewError(ErrNotFound, "webhook not found")
+
+// WebhookStore is implemented by stores that hold webhook registrations
+type WebhookStore interface {
+	CreateWebhook(hook Webhook) (*Webhook, error)
+	// Webhooks returns the webhooks of tenant ordered by ID, those of every tenant when it
+	// is empty
+	Webhooks(tenant string) ([]Webhook, error)
+	DeleteWebhook(id int) error
+	// RecordWebhookDelivery stores the outcome of a delivery attempt to the webhook with id
+	RecordWebhookDelivery(id int, at time.Time, status int, deliveryErr string) error
+}
+
+const webhookColumns = "id, url, secret, events, created, last_delivery, last_status, COALESCE(last_error, ''), " +
+	"tenant_id"
+
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var hook Webhook
+	err := row.Scan(
+		&hook.ID, &hook.URL, &hook.Secret, &hook.Events, &hook.Created, &hook.LastDelivery, &hook.LastStatus,
+		&hook.LastError, &hook.Tenant,
+	)
+	return hook, err
+}
+
+// CreateWebhook stores hook in its tenant and returns it with its ID and creation time
+func CreateWebhook(pool *pgxpool.Pool, hook Webhook) (*Webhook, error) {
+	args := pgx.NamedArgs{
+		"url":     hook.URL,
+		"secret":  hook.Secret,
+		"events":  append([]string{}, hook.Events...),
+		"created": time.Now().UTC(),
+		"tenant":  tenantOrDefault(hook.Tenant),
+	}
+
+	query := `INSERT INTO s1.webhook (url, secret, events, created, tenant_id)
+			VALUES (@url, @secret, @events, @created, @tenant)
+			RETURNING ` + webhookColumns
+	created, err := scanWebhook(pool.QueryRow(context.Background(), query, args))
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook: %w", err)
+	}
+	return &created, nil
+}
+
+// ListWebhooks retrieves the webhooks of tenant ordered by ID, every webhook when tenant
+// is empty
+func ListWebhooks(pool *pgxpool.Pool, tenant string) ([]Webhook, error) {
+	rows, err := pool.Query(
+		context.Background(),
+		`SELECT `+webhookColumns+` FROM s1.webhook WHERE @tenant = '' OR tenant_id = @tenant ORDER BY id`,
+		pgx.NamedArgs{"tenant": tenant},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	hooks, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Webhook, error) { return scanWebhook(row) })
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes the webhook with id
+func DeleteWebhook(pool *pgxpool.Pool, id int) error {
+	tag, err := pool.Exec(context.Background(), `DELETE FROM s1.webhook WHERE id = @id`, pgx.NamedArgs{"id": id})
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// RecordWebhookDelivery stores the time, response status and error of the latest delivery
+// attempt to the webhook with id
+func RecordWebhookDelivery(pool *pgxpool.Pool, id int, at time.Time, status int, deliveryErr string) error {
+	tag, err := pool.Exec(
+		context.Background(),
+		`UPDATE s1.webhook SET last_delivery = @at, last_status = @status, last_error = NULLIF(@error, '')
+			WHERE id = @id`,
+		pgx.NamedArgs{"id": id, "at": at, "status": status, "error": deliveryErr},
+	)
+	if err != nil {
+		return fmt.Errorf("error recording webhook delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// avroFields flattens an Avro schema into its fields. Record fields without a default
+// are required, union branches are merged into the field they belong to, and array
+// items and map values are fields of their own.
+func avroFields(schemaData string) (map[string]field, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(schemaData), &root); err != nil {
+		return nil, fmt.Errorf("schema is not valid json: %w", err)
+	}
+	w := &avroWalker{
+		named: map[string]map[string]interface{}{}, fields: map[string]field{}, active: map[string]bool{},
+	}
+	w.walk(root, "/", true, "")
+	return w.fields, nil
+}
+
+type avroWalker struct {
+	// named holds the named types defined so far by full name
+	named  map[string]map[string]interface{}
+	fields map[string]field
+	// active holds the named types being walked, to stop at recursive references
+	active map[string]bool
+}
+
+// walk records the field of type t at path, namespace is the enclosing one for short names
+func (w *avroWalker) walk(t interface{}, path string, required bool, namespace string) {
+	f, ok := w.fields[path]
+	if !ok {
+		f = field{Field: Field{Path: path}, constraints: map[string]interface{}{}}
+	}
+	f.Required = f.Required || required
+	f.Type = joinType(f.Type, w.typeName(t, namespace))
+	w.fields[path] = f
+
+	switch t := t.(type) {
+	case string:
+		if def, ok := w.named[qualify(t, namespace)]; ok {
+			w.walk(def, path, false, namespace)
+		}
+	case []interface{}:
+		for _, branch := range t {
+			w.walk(branch, path, false, namespace)
+		}
+	case map[string]interface{}:
+		w.walkComplex(t, path, namespace)
+	}
+}
+
+func (w *avroWalker) walkComplex(t map[string]interface{}, path, namespace string) {
+	constraints := w.fields[path].constraints
+	switch kind, _ := t["type"].(string); kind {
+	case "record", "error":
+		name := fullName(t, namespace)
+		if w.active[name] {
+			return
+		}
+		w.named[name] = t
+		w.active[name] = true
+		defer delete(w.active, name)
+
+		fields, _ := t["fields"].([]interface{})
+		for _, raw := range fields {
+			def, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			p := child(path, fmt.Sprint(def["name"]))
+			value, hasDefault := def["default"]
+			w.walk(def["type"], p, !hasDefault, namespaceOf(name))
+			if hasDefault {
+				w.fields[p].constraints["default"] = value
+			}
+		}
+	case "enum":
+		w.named[fullName(t, namespace)] = t
+		constraints["symbols"] = t["symbols"]
+	case "fixed":
+		w.named[fullName(t, namespace)] = t
+		constraints["size"] = t["size"]
+	case "array":
+		w.walk(t["items"], child(path, "*"), false, namespace)
+	case "map":
+		w.walk(t["values"], child(path, "*"), false, namespace)
+	default:
+		if logical, ok := t["logicalType"]; ok {
+			constraints["logicalType"] = logical
+		}
+	}
+}
+
+// typeName names the Avro type t, named types by their full name and unions by their
+// branches joined with |
+func (w *avroWalker) typeName(t interface{}, namespace string) string {
+	switch t := t.(type) {
+	case string:
+		if _, ok := w.named[qualify(t, namespace)]; ok {
+			return qualify(t, namespace)
+		}
+		return t
+	case []interface{}:
+		names := make([]string, 0, len(t))
+		for _, branch := range t {
+			names = append(names, w.typeName(branch, namespace))
+		}
+		return strings.Join(names, "|")
+	case map[string]interface{}:
+		switch kind, _ := t["type"].(string); kind {
+		case "record", "error", "enum", "fixed":
+			return fullName(t, namespace)
+		case "array", "map":
+			return kind
+		}
+		return w.typeName(t["type"], namespace)
+	}
+	return ""
+}
+
+// joinType adds the type of a union branch to the type of its field unless it is there
+func joinType(existing, t string) string {
+	if existing == "" {
+		return t
+	}
+	if t == "" || slices.Contains(strings.Split(existing, "|"), t) {
+		return existing
+	}
+	return existing + "|" + t
+}
+
+// fullName is the name of named type def including its namespace
+func fullName(def map[string]interface{}, namespace string) string {
+	if ns, ok := def["namespace"].(string); ok {
+		namespace = ns
+	}
+	return qualify(fmt.Sprint(def["name"]), namespace)
+}
+
+// qualify prefixes a short name with namespace, full names stay as they are
+func qualify(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func namespaceOf(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
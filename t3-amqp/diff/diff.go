@@ -0,0 +1,143 @@
+// Package diff compares two versions of a schema field by field, so reviewers see what
+// a new version changes for the payloads it accepts
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"t3-amqp/validate"
+)
+
+// Change kinds besides the constraint keywords of JSON schemas and Avro attributes
+const (
+	KindType     = "type"
+	KindRequired = "required"
+)
+
+// Field is a value of the payload a schema describes
+type Field struct {
+	// Path is the JSON pointer of the field in a payload, / for the payload itself and *
+	// for any element of an array or value of a map
+	Path     string `json:"path"`
+	Type     string `json:"type,omitempty"`
+	Required bool   `json:"required"`
+}
+
+// Change is one difference of a field present in both versions
+type Change struct {
+	Path string `json:"path"`
+	// Kind is type, required or the keyword of the constraint that changed
+	Kind string      `json:"kind"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Diff lists the fields a version adds and removes and how the fields it keeps changed,
+// each sorted by path
+type Diff struct {
+	Added   []Field  `json:"added"`
+	Removed []Field  `json:"removed"`
+	Changed []Change `json:"changed"`
+}
+
+// Empty reports whether both versions describe the same fields
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// field is a Field with the constraints compared between versions
+type field struct {
+	Field
+	constraints map[string]interface{}
+}
+
+// Compare returns the structural changes from schema_data from to schema_data to of
+// schemaType, json or avro
+func Compare(schemaType, from, to string) (*Diff, error) {
+	var flatten func(string) (map[string]field, error)
+	switch schemaType {
+	case "json":
+		flatten = jsonFields
+	case "avro":
+		flatten = avroFields
+	default:
+		return nil, fmt.Errorf("%w: %s cannot be compared", validate.ErrUnsupportedType, schemaType)
+	}
+	before, err := flatten(from)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the old schema: %w", err)
+	}
+	after, err := flatten(to)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the new schema: %w", err)
+	}
+	return compare(before, after), nil
+}
+
+func compare(before, after map[string]field) *Diff {
+	d := &Diff{Added: []Field{}, Removed: []Field{}, Changed: []Change{}}
+	for _, path := range sortedPaths(before) {
+		old := before[path]
+		now, ok := after[path]
+		if !ok {
+			d.Removed = append(d.Removed, old.Field)
+			continue
+		}
+		if old.Type != now.Type {
+			d.Changed = append(d.Changed, Change{Path: path, Kind: KindType, From: old.Type, To: now.Type})
+		}
+		if old.Required != now.Required {
+			d.Changed = append(d.Changed, Change{Path: path, Kind: KindRequired, From: old.Required, To: now.Required})
+		}
+		for _, keyword := range keywords(old.constraints, now.constraints) {
+			from, to := old.constraints[keyword], now.constraints[keyword]
+			if !reflect.DeepEqual(from, to) {
+				d.Changed = append(d.Changed, Change{Path: path, Kind: keyword, From: from, To: to})
+			}
+		}
+	}
+	for _, path := range sortedPaths(after) {
+		if _, ok := before[path]; !ok {
+			d.Added = append(d.Added, after[path].Field)
+		}
+	}
+	return d
+}
+
+func sortedPaths(fields map[string]field) []string {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// keywords returns the constraint keywords of either version, sorted
+func keywords(a, b map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, m := range []map[string]interface{}{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				out = append(out, k)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// pointerEscaper escapes property names in JSON pointers
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// child appends name to the JSON pointer path, escaping it as RFC 6901 asks
+func child(path, name string) string {
+	if path == "/" {
+		path = ""
+	}
+	return path + "/" + pointerEscaper.Replace(name)
+}
@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"errors"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareJSON(t *testing.T) {
+	from := `{
+		"type": "object",
+		"required": ["id", "lines"],
+		"properties": {
+			"id": {"type": "integer"},
+			"note": {"type": "string", "maxLength": 100},
+			"lines": {"type": "array", "items": {"$ref": "#/$defs/line"}}
+		},
+		"$defs": {"line": {"type": "object", "required": ["sku"], "properties": {"sku": {"type": "string"}}}}
+	}`
+	to := `{
+		"type": "object",
+		"required": ["id", "lines", "currency"],
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"currency": {"enum": ["EUR", "USD"]},
+			"lines": {"type": "array", "items": {"$ref": "#/$defs/line"}}
+		},
+		"$defs": {"line": {
+			"allOf": [{"type": "object", "properties": {"sku": {"type": "string"}, "qty": {"type": "integer"}}}],
+			"required": ["qty"]
+		}}
+	}`
+
+	d, err := Compare("json", from, to)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Field{
+		{Path: "/currency", Required: true},
+		{Path: "/lines/*/qty", Type: "integer", Required: true},
+	}, d.Added)
+	assert.Equal(t, []Field{{Path: "/note", Type: "string"}}, d.Removed)
+	assert.Equal(t, []Change{
+		{Path: "/id", Kind: KindType, From: "integer", To: "string"},
+		{Path: "/id", Kind: "format", From: nil, To: "uuid"},
+		{Path: "/lines/*/sku", Kind: KindRequired, From: true, To: false},
+	}, d.Changed)
+
+	d, err = Compare("json", from, from)
+	assert.NoError(t, err)
+	assert.True(t, d.Empty())
+}
+
+func TestCompareJSONRecursive(t *testing.T) {
+	schema := `{
+		"$ref": "#/$defs/node",
+		"$defs": {"node": {
+			"type": "object",
+			"properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}}
+		}}
+	}`
+	fields, err := jsonFields(schema)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.ElementsMatch(t, []string{"/", "/children", "/children/*"}, sortedPaths(fields))
+
+	_, err = Compare("json", schema, `{"$ref": "https://example.com/node.json"}`)
+	assert.ErrorContains(t, err, "only local $refs")
+}
+
+func TestCompareAvro(t *testing.T) {
+	from := `{"type": "record", "name": "Order", "namespace": "shop", "fields": [
+		{"name": "id", "type": "long"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID"]}},
+		{"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [
+			{"name": "city", "type": "string"}
+		]}], "default": null},
+		{"name": "tags", "type": {"type": "array", "items": "string"}}
+	]}`
+	to := `{"type": "record", "name": "Order", "namespace": "shop", "fields": [
+		{"name": "id", "type": "string"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID", "SHIPPED"]}},
+		{"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [
+			{"name": "city", "type": "string"},
+			{"name": "zip", "type": ["null", "string"], "default": null}
+		]}], "default": null},
+		{"name": "next", "type": ["null", "Order"], "default": null}
+	]}`
+
+	d, err := Compare("avro", from, to)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Field{
+		{Path: "/address/zip", Type: "null|string"},
+		{Path: "/next", Type: "null|shop.Order"},
+	}, d.Added)
+	assert.Equal(t, []Field{
+		{Path: "/tags", Type: "array", Required: true},
+		{Path: "/tags/*", Type: "string"},
+	}, d.Removed)
+	assert.Equal(t, []Change{
+		{Path: "/id", Kind: KindType, From: "long", To: "string"},
+		{
+			Path: "/status", Kind: "symbols",
+			From: []interface{}{"NEW", "PAID"}, To: []interface{}{"NEW", "PAID", "SHIPPED"},
+		},
+	}, d.Changed)
+}
+
+func TestCompareUnsupported(t *testing.T) {
+	_, err := Compare("xsd", "<a/>", "<b/>")
+	assert.True(t, errors.Is(err, validate.ErrUnsupportedType))
+}
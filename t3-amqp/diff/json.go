@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// structural holds the JSON schema keywords describing fields rather than constraining
+// them, along with annotations that do not change what a payload may contain
+var structural = map[string]bool{
+	"$schema": true, "$id": true, "$ref": true, "$defs": true, "definitions": true, "$comment": true,
+	"title": true, "description": true, "examples": true, "default": true,
+	"type": true, "properties": true, "required": true, "items": true, "prefixItems": true,
+	"additionalItems": true, "allOf": true,
+}
+
+// maxRefs stops following $refs that only refer to each other
+const maxRefs = 32
+
+// jsonFields flattens a JSON schema into its fields. Local $refs are followed and allOf
+// branches merged into the field they apply to. Other keywords, anyOf and oneOf among
+// them, are compared as constraints of the field.
+func jsonFields(schemaData string) (map[string]field, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(schemaData), &root); err != nil {
+		return nil, fmt.Errorf("schema is not valid json: %w", err)
+	}
+	w := &jsonWalker{root: root, fields: map[string]field{}, active: map[string]bool{}}
+	if err := w.walk(root, "/", true); err != nil {
+		return nil, err
+	}
+	return w.fields, nil
+}
+
+type jsonWalker struct {
+	root   interface{}
+	fields map[string]field
+	// active holds the $refs followed to reach the schema being walked
+	active map[string]bool
+}
+
+func (w *jsonWalker) walk(node interface{}, path string, required bool) error {
+	schema, refs, err := w.resolve(node)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	f, ok := w.fields[path]
+	if !ok {
+		f = field{Field: Field{Path: path}, constraints: map[string]interface{}{}}
+	}
+	f.Required = f.Required || required
+	if t := jsonType(schema["type"]); t != "" {
+		f.Type = t
+	}
+	for keyword, value := range schema {
+		if !structural[keyword] {
+			f.constraints[keyword] = value
+		}
+	}
+	w.fields[path] = f
+
+	// A recursive schema is compared down to where it refers to itself
+	for _, ref := range refs {
+		if w.active[ref] {
+			return nil
+		}
+	}
+	for _, ref := range refs {
+		w.active[ref] = true
+		defer delete(w.active, ref)
+	}
+
+	if branches, ok := schema["allOf"].([]interface{}); ok {
+		for _, branch := range branches {
+			if err := w.walk(branch, path, required); err != nil {
+				return err
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				names[s] = true
+			}
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, property := range properties {
+			if err := w.walk(property, child(path, name), names[name]); err != nil {
+				return err
+			}
+		}
+	}
+	// Required properties declared without a schema of their own are still fields
+	for name := range names {
+		p := child(path, name)
+		if existing, ok := w.fields[p]; ok {
+			existing.Required = true
+			w.fields[p] = existing
+			continue
+		}
+		w.fields[p] = field{Field: Field{Path: p, Required: true}, constraints: map[string]interface{}{}}
+	}
+
+	tuple, _ := schema["prefixItems"].([]interface{})
+	switch items := schema["items"].(type) {
+	case []interface{}:
+		tuple = items
+	case map[string]interface{}, bool:
+		if err := w.walk(items, child(path, "*"), false); err != nil {
+			return err
+		}
+	}
+	for i, item := range tuple {
+		if err := w.walk(item, child(path, fmt.Sprint(i)), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve follows the local $refs of node and returns the schema along with the refs
+// followed, boolean schemas have no fields
+func (w *jsonWalker) resolve(node interface{}) (map[string]interface{}, []string, error) {
+	var refs []string
+	for {
+		schema, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, nil, nil
+		}
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema, refs, nil
+		}
+		if !strings.HasPrefix(ref, "#") {
+			return nil, nil, fmt.Errorf("only local $refs can be compared, not %s", ref)
+		}
+		if len(refs) == maxRefs {
+			return nil, nil, fmt.Errorf("$ref %s does not resolve to a schema", ref)
+		}
+		target, err := lookup(w.root, strings.TrimPrefix(ref, "#"))
+		if err != nil {
+			return nil, nil, err
+		}
+		refs = append(refs, ref)
+		node = target
+	}
+}
+
+// lookup returns the value at JSON pointer in doc
+func lookup(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref #%s does not resolve", pointer)
+		}
+		if doc, ok = m[token]; !ok {
+			return nil, fmt.Errorf("$ref #%s does not resolve", pointer)
+		}
+	}
+	return doc, nil
+}
+
+// jsonType returns the type keyword as a string, several types sorted and joined by |
+func jsonType(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		var types []string
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+		sort.Strings(types)
+		return strings.Join(types, "|")
+	}
+	return ""
+}
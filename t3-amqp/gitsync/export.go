@@ -0,0 +1,86 @@
+package gitsync
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FilePath returns where Scan expects version of the schema name of schemaType, false for
+// types without a file extension
+func FilePath(name, schemaType, version string) (string, bool) {
+	for ext, t := range Extensions {
+		if t == schemaType && name != "" && version != "" && !strings.Contains(version, "/") {
+			return path.Join(strings.ReplaceAll(name, ".", "/"), version+ext), true
+		}
+	}
+	return "", false
+}
+
+// Write lays files out below root the way Scan reads them and removes the schema files
+// already there that are not among them, so root holds a snapshot of exactly files.
+// Files that are not schema files are left alone.
+func Write(root string, files []SchemaFile) error {
+	keep := map[string]bool{}
+	for _, file := range files {
+		keep[file.Path] = true
+	}
+	existing, err := Scan(root)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, file := range existing {
+		if !keep[file.Path] {
+			if err := os.Remove(filepath.Join(root, filepath.FromSlash(file.Path))); err != nil {
+				return err
+			}
+		}
+	}
+	if err := removeEmptyDirs(root); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		p := filepath.Join(root, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, []byte(file.Data), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEmptyDirs removes the directories below root left empty, deepest first
+func removeEmptyDirs(root string) error {
+	var dirs []string
+	err := filepath.WalkDir(
+		root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && p != root {
+				if strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				dirs = append(dirs, p)
+			}
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if entries, err := os.ReadDir(dirs[i]); err == nil && len(entries) == 0 {
+			if err := os.Remove(dirs[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
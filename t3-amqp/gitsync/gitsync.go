@@ -0,0 +1,129 @@
+// Package gitsync keeps a local checkout of a Git repository of schema files and reads
+// the schemas out of it by their file layout, so the registry can follow a repository
+// that is the source of truth.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultBranch is checked out when no branch is configured
+const DefaultBranch = "main"
+
+// Repo is a shallow checkout of one branch of a remote repository in Dir
+type Repo struct {
+	URL    string
+	Branch string
+	Dir    string
+
+	// git commands on one checkout must not overlap
+	mu sync.Mutex
+}
+
+// NewRepo creates a checkout of branch of url in dir. Nothing is cloned until the first
+// Pull.
+func NewRepo(url, branch, dir string) *Repo {
+	if branch == "" {
+		branch = DefaultBranch
+	}
+	return &Repo{URL: url, Branch: branch, Dir: dir}
+}
+
+// Pull clones the repository on first use and afterwards fetches the branch and resets
+// the checkout to it, discarding anything changed locally. It returns the commit checked
+// out.
+func (r *Repo) Pull(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := os.Stat(filepath.Join(r.Dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(r.Dir), 0o755); err != nil {
+			return "", err
+		}
+		if _, err := git(ctx, "", "clone", "--quiet", "--depth", "1", "--branch", r.Branch, r.URL, r.Dir); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := git(ctx, r.Dir, "fetch", "--quiet", "--depth", "1", "origin", r.Branch); err != nil {
+			return "", err
+		}
+		if _, err := git(ctx, r.Dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	return git(ctx, r.Dir, "rev-parse", "HEAD")
+}
+
+// Commit commits everything changed in the checkout with message as author, given as
+// "Name <email>", and with push pushes the commit to the branch. It returns the commit
+// checked out afterwards and whether there was anything to commit.
+func (r *Repo) Commit(ctx context.Context, message, author string, push bool) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := git(ctx, r.Dir, "add", "--all"); err != nil {
+		return "", false, err
+	}
+	status, err := git(ctx, r.Dir, "status", "--porcelain")
+	if err != nil {
+		return "", false, err
+	}
+	if status != "" {
+		name, email := author, ""
+		if i := strings.Index(author, "<"); i >= 0 {
+			name, email = strings.TrimSpace(author[:i]), strings.Trim(author[i:], "<> ")
+		}
+		_, err := git(
+			ctx, r.Dir, "-c", "user.name="+name, "-c", "user.email="+email, "commit", "--quiet", "-m", message,
+		)
+		if err != nil {
+			return "", false, err
+		}
+		if push {
+			if _, err := git(ctx, r.Dir, "push", "--quiet", "origin", "HEAD:refs/heads/"+r.Branch); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	commit, err := git(ctx, r.Dir, "rev-parse", "HEAD")
+	return commit, status != "", err
+}
+
+// git runs a git command in dir and returns its trimmed output. Prompts for credentials
+// are turned off so a private repository without them fails instead of hanging.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", command(args), msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// command names the git subcommand of args, skipping the options given to git itself
+func command(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-c", "-C":
+			i++
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
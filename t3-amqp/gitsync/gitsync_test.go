@@ -0,0 +1,166 @@
+package gitsync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// commit writes files to the repository in dir and commits them
+func commit(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(data), 0o644))
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=t3", "-c", "user.email=t3@example.com", "commit", "--quiet", "-m", "schemas"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+}
+
+// remote creates a repository with a main branch to clone from
+func remote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	out, err := exec.Command("git", "init", "--quiet", "--initial-branch", "main", dir).CombinedOutput()
+	assert.NoError(t, err, string(out))
+	return dir
+}
+
+func TestRepoPull(t *testing.T) {
+	origin := remote(t)
+	commit(t, origin, map[string]string{"orders/1.0.0.json": `{"type":"object"}`})
+
+	repo := NewRepo(origin, "", filepath.Join(t.TempDir(), "checkout"))
+	first, err := repo.Pull(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, first, 40)
+	assert.FileExists(t, filepath.Join(repo.Dir, "orders", "1.0.0.json"))
+
+	commit(t, origin, map[string]string{"orders/1.1.0.json": `{"type":"object"}`})
+	assert.NoError(t, os.WriteFile(filepath.Join(repo.Dir, "orders", "1.0.0.json"), []byte("changed"), 0o644))
+	second, err := repo.Pull(context.Background())
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.FileExists(t, filepath.Join(repo.Dir, "orders", "1.1.0.json"))
+	data, err := os.ReadFile(filepath.Join(repo.Dir, "orders", "1.0.0.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"object"}`, string(data), "local changes are discarded")
+
+	_, err = NewRepo(origin, "release", filepath.Join(t.TempDir(), "checkout")).Pull(context.Background())
+	assert.ErrorContains(t, err, "git clone")
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	for name, data := range map[string]string{
+		"orders/created/1.0.0.avsc": `{"type":"string"}`,
+		"orders/1.0.0.json":         `{"type":"object"}`,
+		"invoices/2.xsd":            `<xs:schema/>`,
+		"invoices/README.md":        "docs",
+		"README.json":               "{}",
+		".github/ci/1.json":         "{}",
+		"orders/.draft.json":        "{}",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(data), 0o644))
+	}
+
+	files, err := Scan(root)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, []SchemaFile{
+			{Name: "invoices", Type: "xsd", Version: "2", Path: "invoices/2.xsd", Data: `<xs:schema/>`},
+			{Name: "orders", Type: "json", Version: "1.0.0", Path: "orders/1.0.0.json", Data: `{"type":"object"}`},
+			{
+				Name: "orders.created", Type: "avro", Version: "1.0.0", Path: "orders/created/1.0.0.avsc",
+				Data: `{"type":"string"}`,
+			},
+		}, files,
+	)
+
+	_, err = Scan(filepath.Join(root, "missing"))
+	assert.Error(t, err)
+}
+
+func TestFilePath(t *testing.T) {
+	p, ok := FilePath("orders.created", "avro", "1.0.0")
+	assert.True(t, ok)
+	assert.Equal(t, "orders/created/1.0.0.avsc", p)
+	file, ok := schemaFile(p)
+	assert.True(t, ok)
+	assert.Equal(
+		t, SchemaFile{Name: "orders.created", Type: "avro", Version: "1.0.0", Path: p}, file, "Scan reads it back",
+	)
+
+	_, ok = FilePath("orders", "csv", "1.0.0")
+	assert.False(t, ok)
+	_, ok = FilePath("orders", "json", "1/0")
+	assert.False(t, ok)
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(
+		t, Write(
+			root, []SchemaFile{
+				{Path: "orders/1.0.0.json", Data: `{"type":"object"}`},
+				{Path: "orders/created/1.avsc", Data: `"string"`},
+			},
+		),
+	)
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("docs"), 0o644))
+
+	assert.NoError(t, Write(root, []SchemaFile{{Path: "orders/1.0.0.json", Data: `{"type":"array"}`}}))
+	files, err := Scan(root)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, []SchemaFile{
+			{Name: "orders", Type: "json", Version: "1.0.0", Path: "orders/1.0.0.json", Data: `{"type":"array"}`},
+		}, files,
+	)
+	assert.NoDirExists(t, filepath.Join(root, "orders", "created"), "emptied directories are removed")
+	assert.FileExists(t, filepath.Join(root, "README.md"), "other files are kept")
+
+	assert.NoError(t, Write(filepath.Join(root, "new"), nil), "a missing root is created")
+}
+
+func TestRepoCommit(t *testing.T) {
+	origin := remote(t)
+	commit(t, origin, map[string]string{"README.md": "schemas"})
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	out, err := exec.Command("git", "clone", "--quiet", "--bare", origin, bare).CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	repo := NewRepo(bare, "main", filepath.Join(t.TempDir(), "checkout"))
+	_, err = repo.Pull(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, Write(repo.Dir, []SchemaFile{{Path: "orders/1.0.0.json", Data: "{}"}}))
+	head, changed, err := repo.Commit(context.Background(), "Snapshot", "Registry <registry@example.com>", true)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	pushed, err := git(context.Background(), bare, "rev-parse", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, head, pushed)
+	author, err := git(context.Background(), bare, "log", "-1", "--format=%an <%ae> %s", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, "Registry <registry@example.com> Snapshot", author)
+
+	again, changed, err := repo.Commit(context.Background(), "Snapshot", "Registry <registry@example.com>", true)
+	assert.NoError(t, err)
+	assert.False(t, changed, "nothing changed")
+	assert.Equal(t, head, again)
+}
@@ -0,0 +1,85 @@
+package gitsync
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extensions maps the file extensions of schema files to their schema type
+var Extensions = map[string]string{
+	".json":  "json",
+	".avsc":  "avro",
+	".xsd":   "xsd",
+	".proto": "protobuf",
+}
+
+// SchemaFile is one version of a schema read from the repository
+type SchemaFile struct {
+	Name    string
+	Type    string
+	Version string
+	// Path is relative to the scanned directory, with forward slashes
+	Path string
+	Data string
+}
+
+// Scan reads the schema files below root laid out as <name>/<version>.<ext>. Nested
+// directories make up a dotted name, so orders/created/1.0.0.avsc is version 1.0.0 of
+// the Avro schema orders.created. Files at the top level, files with other extensions
+// and hidden files and directories are skipped. Files are returned ordered by path.
+func Scan(root string) ([]SchemaFile, error) {
+	var files []SchemaFile
+	err := filepath.WalkDir(
+		root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p != root && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			file, ok := schemaFile(filepath.ToSlash(rel))
+			if !ok {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			file.Data = string(data)
+			files = append(files, file)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// schemaFile names the schema version at rel, false when rel does not follow the layout
+func schemaFile(rel string) (SchemaFile, bool) {
+	dir, base := path.Split(rel)
+	ext := path.Ext(base)
+	schemaType, ok := Extensions[ext]
+	version := strings.TrimSuffix(base, ext)
+	if !ok || dir == "" || version == "" {
+		return SchemaFile{}, false
+	}
+	name := strings.ReplaceAll(strings.TrimSuffix(dir, "/"), "/", ".")
+	return SchemaFile{Name: name, Type: schemaType, Version: version, Path: rel}, true
+}
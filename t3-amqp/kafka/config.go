@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"t3-amqp/db"
+	"time"
+)
+
+const (
+	DefaultClientID    = "t3"
+	DefaultDialTimeout = 10 * time.Second
+)
+
+// Config holds the Kafka cluster to connect to. Topics created by DeclareTopic get
+// Partitions partitions replicated ReplicationFactor times, zero values use the cluster
+// defaults.
+type Config struct {
+	Brokers           []string      `mapstructure:"brokers"`
+	ClientID          string        `mapstructure:"client_id"`
+	DialTimeout       time.Duration `mapstructure:"dial_timeout"`
+	Partitions        int32         `mapstructure:"partitions"`
+	ReplicationFactor int16         `mapstructure:"replication_factor"`
+}
+
+// LoadConfig reads the kafka section of the already loaded configuration, KAFKA_*
+// environment variables override the file
+func LoadConfig() (*Config, error) {
+	if err := db.BindEnv("kafka", Config{}); err != nil {
+		return nil, err
+	}
+	// UnmarshalKey would only see the file, environment overrides need the full settings
+	var settings struct {
+		Kafka Config `mapstructure:"kafka"`
+	}
+	if err := viper.Unmarshal(&settings); err != nil {
+		return nil, fmt.Errorf("unable to decode kafka config: %w", err)
+	}
+	config := settings.Kafka
+	config.applyDefaults()
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.ClientID == "" {
+		c.ClientID = DefaultClientID
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultDialTimeout
+	}
+}
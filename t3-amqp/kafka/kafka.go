@@ -0,0 +1,157 @@
+// Package kafka implements broker.Broker for Kafka, so schemas and scenarios can be
+// exercised against Kafka topics as well as AMQP ones
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"t3-amqp/broker"
+	"t3-amqp/redact"
+	"time"
+)
+
+// Header carrying the content type, Kafka records have no property for it
+const HeaderContentType = "content-type"
+
+// Broker publishes to and subscribes on Kafka topics. Records are keyed by message ID, so
+// messages with the same ID keep their order within a partition.
+type Broker struct {
+	config Config
+	client *kgo.Client
+}
+
+// Dial connects to the cluster in config and checks that a broker answers
+func Dial(ctx context.Context, config Config) (*Broker, error) {
+	config.applyDefaults()
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("kafka needs at least one broker address")
+	}
+	client, err := kgo.NewClient(config.options()...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create kafka client: %w", err)
+	}
+	if err := client.Ping(ctx); err != nil {
+		client.Close()
+		return nil, redact.Error(fmt.Errorf("unable to connect to kafka: %w", err))
+	}
+	return &Broker{config: config, client: client}, nil
+}
+
+func (c Config) options(opts ...kgo.Opt) []kgo.Opt {
+	return append(
+		[]kgo.Opt{kgo.SeedBrokers(c.Brokers...), kgo.ClientID(c.ClientID), kgo.DialTimeout(c.DialTimeout)},
+		opts...,
+	)
+}
+
+// DeclareTopic creates topic unless it already exists
+func (b *Broker) DeclareTopic(ctx context.Context, topic string) error {
+	req := kmsg.NewPtrCreateTopicsRequest()
+	t := kmsg.NewCreateTopicsRequestTopic()
+	t.Topic = topic
+	t.NumPartitions, t.ReplicationFactor = -1, -1
+	if b.config.Partitions > 0 {
+		t.NumPartitions = b.config.Partitions
+	}
+	if b.config.ReplicationFactor > 0 {
+		t.ReplicationFactor = b.config.ReplicationFactor
+	}
+	req.Topics = append(req.Topics, t)
+
+	resp, err := req.RequestWith(ctx, b.client)
+	if err != nil {
+		return fmt.Errorf("error creating topic %s: %w", topic, err)
+	}
+	if len(resp.Topics) != 1 {
+		return fmt.Errorf("error creating topic %s: unexpected response", topic)
+	}
+	err = kerr.ErrorForCode(resp.Topics[0].ErrorCode)
+	if err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
+		return fmt.Errorf("error creating topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Publish produces msg to topic and waits until the partition leader acknowledged it
+func (b *Broker) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	if err := b.client.ProduceSync(ctx, record(topic, msg)).FirstErr(); err != nil {
+		return fmt.Errorf("error producing to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe consumes topic with a client of its own, outside any consumer group, from
+// the records produced after the subscription started. Such records are found by their
+// timestamp, so the clocks of producers and the cluster should roughly agree.
+func (b *Broker) Subscribe(ctx context.Context, topic string, handle func(broker.Message)) (func() error, error) {
+	client, err := kgo.NewClient(b.config.options(
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AfterMilli(time.Now().UnixMilli())),
+	)...)
+	if err != nil {
+		return nil, fmt.Errorf("error subscribing to %s: %w", topic, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		for {
+			fetches := client.PollFetches(ctx)
+			if ctx.Err() != nil || fetches.IsClientClosed() {
+				done <- nil
+				return
+			}
+			for _, fetchErr := range fetches.Errors() {
+				if !kerr.IsRetriable(fetchErr.Err) {
+					done <- fmt.Errorf("error consuming %s: %w", topic, fetchErr.Err)
+					return
+				}
+			}
+			fetches.EachRecord(func(r *kgo.Record) { handle(message(r)) })
+		}
+	}()
+	return func() error {
+		cancel()
+		err := <-done
+		client.Close()
+		return err
+	}, nil
+}
+
+// Close closes the producing client
+func (b *Broker) Close() error {
+	b.client.Close()
+	return nil
+}
+
+// record builds the Kafka record of msg, the content type travels as a header
+func record(topic string, msg broker.Message) *kgo.Record {
+	r := &kgo.Record{Topic: topic, Value: msg.Body}
+	if msg.ID != "" {
+		r.Key = []byte(msg.ID)
+	}
+	if msg.ContentType != "" {
+		r.Headers = append(r.Headers, kgo.RecordHeader{Key: HeaderContentType, Value: []byte(msg.ContentType)})
+	}
+	for k, v := range msg.Headers {
+		r.Headers = append(r.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+	}
+	return r
+}
+
+// message converts record r, its key is the message ID
+func message(r *kgo.Record) broker.Message {
+	msg := broker.Message{ID: string(r.Key), Headers: make(map[string]string, len(r.Headers)), Body: r.Value}
+	for _, h := range r.Headers {
+		if h.Key == HeaderContentType {
+			msg.ContentType = string(h.Value)
+			continue
+		}
+		msg.Headers[h.Key] = string(h.Value)
+	}
+	return msg
+}
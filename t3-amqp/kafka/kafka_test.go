@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"context"
+	"t3-amqp/broker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	msg := broker.Message{
+		ID:          "t3.run1.0-3",
+		ContentType: "application/json",
+		Headers:     map[string]string{broker.HeaderInstance: "t3.run1.0", broker.HeaderSchemaVersion: "1.0.0"},
+		Body:        []byte(`{"id":1}`),
+	}
+
+	r := record("orders.created", msg)
+	assert.Equal(t, "orders.created", r.Topic)
+	assert.Equal(t, []byte("t3.run1.0-3"), r.Key)
+	assert.Len(t, r.Headers, 3)
+	assert.Equal(t, msg, message(r))
+
+	r = record("orders.created", broker.Message{Body: []byte(`{}`)})
+	assert.Nil(t, r.Key, "messages without an ID are spread over the partitions")
+	assert.Empty(t, r.Headers)
+}
+
+func TestDialNeedsBrokers(t *testing.T) {
+	_, err := Dial(context.Background(), Config{})
+	assert.Error(t, err)
+
+	var c Config
+	c.applyDefaults()
+	assert.Equal(t, DefaultClientID, c.ClientID)
+	assert.Equal(t, DefaultDialTimeout, c.DialTimeout)
+}
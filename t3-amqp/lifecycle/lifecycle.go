@@ -0,0 +1,134 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+	"t3-amqp/db"
+	"time"
+)
+
+// DefaultInterval is how often due deprecations and retirements are applied
+const DefaultInterval = time.Minute
+
+// Event describes a schema that moved into a new lifecycle status
+type Event struct {
+	Schema db.Schema `json:"schema"`
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// Notifier is told about every lifecycle transition
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Notifiers fans an event out to several notifiers and joins their errors
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogNotifier writes transitions to the standard logger
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, event Event) error {
+	log.Printf(
+		"Schema %s/%s/%s (id %d) is now %s", event.Schema.Name, event.Schema.Type, event.Schema.Version,
+		event.Schema.ID, event.Status,
+	)
+	return nil
+}
+
+// AuditNotifier records transitions in the audit log, with the schema after the transition
+type AuditNotifier struct {
+	Pool *pgxpool.Pool
+}
+
+func (a AuditNotifier) Notify(_ context.Context, event Event) error {
+	action := db.AuditActionDeprecate
+	if event.Status == db.StatusRetired {
+		action = db.AuditActionRetire
+	}
+	return db.RecordAudit(
+		a.Pool, db.AuditEntry{
+			Actor:      "system:lifecycle",
+			Action:     action,
+			SchemaID:   event.Schema.ID,
+			SchemaName: event.Schema.Name,
+			After:      db.Snapshot(&event.Schema),
+			Tenant:     event.Schema.Tenant,
+		},
+	)
+}
+
+// Scheduler periodically applies due deprecations and retirements
+type Scheduler struct {
+	advance  func(now time.Time) ([]db.Schema, error)
+	notifier Notifier
+}
+
+// NewScheduler creates a scheduler for the schemas in pool that reports transitions to notifier
+func NewScheduler(pool *pgxpool.Pool, notifier Notifier) *Scheduler {
+	return &Scheduler{
+		advance: func(now time.Time) ([]db.Schema, error) {
+			return db.AdvanceSchemaLifecycle(pool, now)
+		},
+		notifier: notifier,
+	}
+}
+
+// Run applies due transitions every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.tickAndLog(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tickAndLog(ctx)
+		}
+	}
+}
+
+func (s *Scheduler) tickAndLog(ctx context.Context) {
+	if _, err := s.Tick(ctx, time.Now().UTC()); err != nil {
+		log.Printf("Failed to apply schema lifecycle: %v", err)
+	}
+}
+
+// Tick applies every transition due at now and returns the resulting events. A failing
+// notifier is logged and does not hold back the remaining events.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) ([]Event, error) {
+	schemas, err := s.advance(now)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(schemas))
+	for _, schema := range schemas {
+		event := Event{Schema: schema, Status: schema.Status, At: now}
+		events = append(events, event)
+		if s.notifier == nil {
+			continue
+		}
+		if err := s.notifier.Notify(ctx, event); err != nil {
+			log.Printf("Failed to notify lifecycle transition of schema %d: %v", schema.ID, err)
+		}
+	}
+	return events, nil
+}
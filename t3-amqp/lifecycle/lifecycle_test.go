@@ -0,0 +1,69 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	events []Event
+	err    error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, event Event) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func TestTickNotifiesEveryTransition(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	notifier := &recordingNotifier{err: errors.New("webhook down")}
+	s := &Scheduler{
+		advance: func(at time.Time) ([]db.Schema, error) {
+			assert.Equal(t, now, at)
+			return []db.Schema{
+				{ID: 1, Name: "orders.created", Status: db.StatusDeprecated},
+				{ID: 2, Name: "orders.cancelled", Status: db.StatusRetired},
+			}, nil
+		},
+		notifier: notifier,
+	}
+
+	events, err := s.Tick(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, db.StatusDeprecated, events[0].Status)
+	assert.Equal(t, db.StatusRetired, events[1].Status)
+	assert.Equal(t, now, events[1].At)
+
+	// A failing notifier does not stop the remaining events
+	assert.Equal(t, events, notifier.events)
+}
+
+func TestTickReturnsAdvanceError(t *testing.T) {
+	s := &Scheduler{
+		advance: func(time.Time) ([]db.Schema, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	events, err := s.Tick(context.Background(), time.Now())
+	assert.Error(t, err)
+	assert.Empty(t, events)
+}
+
+func TestNotifiersJoinsErrors(t *testing.T) {
+	first := &recordingNotifier{err: errors.New("first")}
+	second := &recordingNotifier{}
+	third := &recordingNotifier{err: errors.New("third")}
+
+	err := Notifiers{first, second, third}.Notify(context.Background(), Event{Status: db.StatusRetired})
+	assert.ErrorContains(t, err, "first")
+	assert.ErrorContains(t, err, "third")
+	assert.Len(t, second.events, 1)
+}
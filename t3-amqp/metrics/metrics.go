@@ -0,0 +1,227 @@
+package metrics
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultSampleInterval is how often pool and runtime statistics are sampled
+const DefaultSampleInterval = 10 * time.Second
+
+var (
+	poolTotalConns = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "t3_db_pool_total_conns", Help: "Total connections in the database pool",
+		},
+	)
+	poolIdleConns = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "t3_db_pool_idle_conns", Help: "Idle connections in the database pool",
+		},
+	)
+	poolAcquiredConns = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "t3_db_pool_acquired_conns", Help: "Connections currently checked out of the pool",
+		},
+	)
+	poolMaxConns = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "t3_db_pool_max_conns", Help: "Maximum size of the database pool",
+		},
+	)
+	poolEmptyAcquires = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "t3_db_pool_empty_acquires",
+			Help: "Acquires that had to wait because the pool was empty",
+		},
+	)
+
+	// Panics counts handler panics caught by the recovery middleware
+	Panics = prometheus.NewCounter(
+		prometheus.CounterOpts{Name: "t3_http_panics_total", Help: "Panics recovered in HTTP handlers"},
+	)
+
+	// AMQPConnections and AMQPChannels are maintained by the broker clients
+	AMQPConnections = prometheus.NewGauge(
+		prometheus.GaugeOpts{Name: "t3_amqp_connections", Help: "Open AMQP connections"},
+	)
+	AMQPChannels = prometheus.NewGauge(
+		prometheus.GaugeOpts{Name: "t3_amqp_channels", Help: "Open AMQP channels"},
+	)
+
+	// HTTPRequests and HTTPDuration are labelled with the route pattern that served the
+	// request, so the label set stays bounded whatever paths clients send
+	HTTPRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "t3_http_requests_total", Help: "HTTP requests by route, method and status code"},
+		[]string{"handler", "method", "code"},
+	)
+	HTTPDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "t3_http_request_duration_seconds", Help: "HTTP request latency by route and method",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"handler", "method"},
+	)
+
+	// DBQueryDuration and DBQueryErrors are recorded by QueryTracer per SQL statement kind
+	DBQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "t3_db_query_duration_seconds", Help: "Database query latency by statement kind",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"operation"},
+	)
+	DBQueryErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "t3_db_query_errors_total", Help: "Failed database queries by statement kind"},
+		[]string{"operation"},
+	)
+
+	// AMQPPublished counts publish attempts by result: ok, rejected before sending, or failed
+	AMQPPublished = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "t3_amqp_published_total", Help: "Messages published by result"},
+		[]string{"result"},
+	)
+	// AMQPConsumed counts messages received by consumers
+	AMQPConsumed = prometheus.NewCounter(
+		prometheus.CounterOpts{Name: "t3_amqp_consumed_total", Help: "Messages consumed from the broker"},
+	)
+	// AMQPVerified counts consumed messages checked against the registry by result: valid,
+	// invalid or unresolved
+	AMQPVerified = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "t3_amqp_verified_total", Help: "Consumed messages validated by result"},
+		[]string{"result"},
+	)
+
+	// ProbeLatency and ProbesLost are recorded by latency scenarios per topic probes are
+	// published to, a canary running one keeps them current
+	ProbeLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "t3_probe_latency_seconds", Help: "End to end latency of broker probes by topic",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"topic"},
+	)
+	ProbesLost = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "t3_probes_lost_total", Help: "Broker probes that did not come back by topic"},
+		[]string{"topic"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		poolTotalConns, poolIdleConns, poolAcquiredConns, poolMaxConns, poolEmptyAcquires,
+		Panics, AMQPConnections, AMQPChannels, HTTPRequests, HTTPDuration, DBQueryDuration,
+		DBQueryErrors, AMQPPublished, AMQPConsumed, AMQPVerified,
+		ProbeLatency, ProbesLost,
+	)
+}
+
+// PoolStats is a point in time view of the database pool
+type PoolStats struct {
+	TotalConns       int32         `json:"totalConns"`
+	IdleConns        int32         `json:"idleConns"`
+	AcquiredConns    int32         `json:"acquiredConns"`
+	MaxConns         int32         `json:"maxConns"`
+	EmptyAcquires    int64         `json:"emptyAcquires"`
+	AcquireDuration  time.Duration `json:"acquireDurationNs"`
+	CanceledAcquires int64         `json:"canceledAcquires"`
+}
+
+// RuntimeStats is a point in time view of the Go runtime
+type RuntimeStats struct {
+	Goroutines int    `json:"goroutines"`
+	HeapAlloc  uint64 `json:"heapAllocBytes"`
+	HeapInUse  uint64 `json:"heapInUseBytes"`
+	NumGC      uint32 `json:"numGC"`
+}
+
+// Snapshot is the latest sample taken by a Sampler
+type Snapshot struct {
+	Sampled time.Time    `json:"sampled"`
+	Pool    PoolStats    `json:"pool"`
+	Runtime RuntimeStats `json:"runtime"`
+}
+
+// Sampler periodically records pool and runtime statistics into the gauges and keeps
+// the latest snapshot for the health details endpoint
+type Sampler struct {
+	pool *pgxpool.Pool
+
+	mu   sync.RWMutex
+	last Snapshot
+}
+
+// NewSampler creates a sampler for pool, which may be nil
+func NewSampler(pool *pgxpool.Pool) *Sampler {
+	return &Sampler{pool: pool}
+}
+
+// Run samples every interval until ctx is done
+func (s *Sampler) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.Sample()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sample()
+		}
+	}
+}
+
+// Sample takes a sample now and returns it
+func (s *Sampler) Sample() Snapshot {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	snapshot := Snapshot{
+		Sampled: time.Now().UTC(),
+		Runtime: RuntimeStats{
+			Goroutines: runtime.NumGoroutine(),
+			HeapAlloc:  mem.HeapAlloc,
+			HeapInUse:  mem.HeapInuse,
+			NumGC:      mem.NumGC,
+		},
+	}
+	// Without a pool, as with the memory store, only the runtime is sampled
+	if s.pool != nil {
+		stat := s.pool.Stat()
+		snapshot.Pool = PoolStats{
+			TotalConns:       stat.TotalConns(),
+			IdleConns:        stat.IdleConns(),
+			AcquiredConns:    stat.AcquiredConns(),
+			MaxConns:         stat.MaxConns(),
+			EmptyAcquires:    stat.EmptyAcquireCount(),
+			AcquireDuration:  stat.AcquireDuration(),
+			CanceledAcquires: stat.CanceledAcquireCount(),
+		}
+	}
+
+	poolTotalConns.Set(float64(snapshot.Pool.TotalConns))
+	poolIdleConns.Set(float64(snapshot.Pool.IdleConns))
+	poolAcquiredConns.Set(float64(snapshot.Pool.AcquiredConns))
+	poolMaxConns.Set(float64(snapshot.Pool.MaxConns))
+	poolEmptyAcquires.Set(float64(snapshot.Pool.EmptyAcquires))
+
+	s.mu.Lock()
+	s.last = snapshot
+	s.mu.Unlock()
+	return snapshot
+}
+
+// Last returns the most recent sample
+func (s *Sampler) Last() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last
+}
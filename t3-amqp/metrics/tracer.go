@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at        time.Time
+	operation string
+}
+
+// QueryTracer is a pgx tracer that records the duration and failures of every query
+type QueryTracer struct{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{at: time.Now(), operation: Operation(data.SQL)})
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	DBQueryDuration.WithLabelValues(start.operation).Observe(time.Since(start.at).Seconds())
+	if data.Err != nil && data.Err != pgx.ErrNoRows {
+		DBQueryErrors.WithLabelValues(start.operation).Inc()
+	}
+}
+
+// Operation classifies a SQL statement by its leading keyword, anything unusual is other
+func Operation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+	switch op := strings.ToLower(fields[0]); op {
+	case "select", "insert", "update", "delete", "with", "begin", "commit", "rollback":
+		return op
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,66 @@
+package mqtt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/spf13/viper"
+	"t3-amqp/db"
+	"time"
+)
+
+const (
+	DefaultKeepAlive      = 30 * time.Second
+	DefaultConnectTimeout = 10 * time.Second
+)
+
+// Config holds the MQTT server to connect to. URL is a mqtt://, mqtts://, ws:// or
+// wss:// address, credentials in it are used unless Username is set. QoS and Retain
+// apply to published messages that do not override them.
+type Config struct {
+	URL            string        `mapstructure:"url"`
+	ClientID       string        `mapstructure:"client_id"`
+	Username       string        `mapstructure:"username"`
+	Password       string        `mapstructure:"password"`
+	QoS            int           `mapstructure:"qos"`
+	Retain         bool          `mapstructure:"retain"`
+	KeepAlive      time.Duration `mapstructure:"keep_alive"`
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+}
+
+// LoadConfig reads the mqtt section of the already loaded configuration, MQTT_*
+// environment variables override the file
+func LoadConfig() (*Config, error) {
+	if err := db.BindEnv("mqtt", Config{}); err != nil {
+		return nil, err
+	}
+	// UnmarshalKey would only see the file, environment overrides need the full settings
+	var settings struct {
+		MQTT Config `mapstructure:"mqtt"`
+	}
+	if err := viper.Unmarshal(&settings); err != nil {
+		return nil, fmt.Errorf("unable to decode mqtt config: %w", err)
+	}
+	config := settings.MQTT
+	if config.QoS < 0 || config.QoS > 2 {
+		return nil, fmt.Errorf("mqtt qos must be 0, 1 or 2, got %d", config.QoS)
+	}
+	config.applyDefaults()
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	// Servers disconnect the older of two clients with the same ID, so every instance
+	// gets its own
+	if c.ClientID == "" {
+		suffix := make([]byte, 4)
+		_, _ = rand.Read(suffix)
+		c.ClientID = "t3-" + hex.EncodeToString(suffix)
+	}
+	if c.KeepAlive <= 0 {
+		c.KeepAlive = DefaultKeepAlive
+	}
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = DefaultConnectTimeout
+	}
+}
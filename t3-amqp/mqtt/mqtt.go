@@ -0,0 +1,245 @@
+// Package mqtt implements broker.Broker for MQTT 5 servers, so device payloads can be
+// tested against registered schemas with the same scenario engine as AMQP and Kafka
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"log"
+	"net/url"
+	"sync"
+	"t3-amqp/broker"
+	"t3-amqp/redact"
+	"t3-amqp/scenario"
+)
+
+// User property carrying the message ID, headers travel as user properties of their own
+const PropertyMessageID = "message-id"
+
+// Broker publishes to and subscribes on MQTT topics over a connection that is restored
+// when it drops. Subscriptions are made again after a reconnect, messages published
+// while the connection was down are lost. Subscriptions with overlapping filters may
+// receive a message once per filter, depending on the server.
+type Broker struct {
+	config Config
+	cm     *autopaho.ConnectionManager
+
+	// subscribing serializes SUBSCRIBE and UNSUBSCRIBE requests, mu guards the
+	// subscriptions. Incoming messages are dispatched holding mu, so network requests
+	// must not be made holding it.
+	subscribing sync.Mutex
+	mu          sync.Mutex
+	subs        map[string]map[int]func(broker.Message)
+	nextID      int
+}
+
+// Dial connects to the server in config and waits until the connection is up or ctx is
+// done
+func Dial(ctx context.Context, config Config) (*Broker, error) {
+	config.applyDefaults()
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, redact.Error(fmt.Errorf("invalid mqtt url: %w", err))
+	}
+	username, password := config.Username, config.Password
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+
+	b := &Broker{config: config, subs: map[string]map[int]func(broker.Message){}}
+	cm, err := autopaho.NewConnection(context.Background(), autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{u},
+		KeepAlive:                     uint16(config.KeepAlive.Seconds()),
+		CleanStartOnInitialConnection: true,
+		ConnectTimeout:                config.ConnectTimeout,
+		ConnectUsername:               username,
+		ConnectPassword:               []byte(password),
+		OnConnectionUp:                b.resubscribe,
+		ClientConfig: paho.ClientConfig{
+			ClientID:          config.ClientID,
+			OnPublishReceived: []func(paho.PublishReceived) (bool, error){b.dispatch},
+		},
+	})
+	if err != nil {
+		return nil, redact.Error(fmt.Errorf("unable to connect to mqtt server: %w", err))
+	}
+	if err := cm.AwaitConnection(ctx); err != nil {
+		// ctx is done, so this only stops the connection attempts
+		_ = cm.Disconnect(ctx)
+		return nil, redact.Error(fmt.Errorf("unable to connect to mqtt server %s: %w", config.URL, err))
+	}
+	b.cm = cm
+	return b, nil
+}
+
+// DeclareTopic does nothing, MQTT topics exist as soon as something is published to them
+func (b *Broker) DeclareTopic(ctx context.Context, topic string) error {
+	return nil
+}
+
+// Publish sends msg to topic with the configured QoS and retain flag unless msg overrides
+// them, and returns once the server acknowledged it as far as the QoS asks for
+func (b *Broker) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	qos := byte(b.config.QoS)
+	if msg.QoS != nil {
+		qos = *msg.QoS
+	}
+	if _, err := b.cm.Publish(ctx, publish(topic, msg, qos, b.config.Retain || msg.Retain)); err != nil {
+		return fmt.Errorf("error publishing to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe calls handle for every message published to a topic matching filter, which
+// may use the + and # wildcards. Messages are received at the QoS they were published
+// with, retained ones as soon as the subscription is made.
+func (b *Broker) Subscribe(ctx context.Context, filter string, handle func(broker.Message)) (func() error, error) {
+	if err := scenario.ValidFilter(filter); err != nil {
+		return nil, err
+	}
+	b.subscribing.Lock()
+	defer b.subscribing.Unlock()
+
+	// The handler goes in first, retained messages follow the SUBACK right away
+	b.mu.Lock()
+	handlers, subscribed := b.subs[filter]
+	if !subscribed {
+		handlers = map[int]func(broker.Message){}
+		b.subs[filter] = handlers
+	}
+	b.nextID++
+	id := b.nextID
+	handlers[id] = handle
+	b.mu.Unlock()
+	if !subscribed {
+		if _, err := b.cm.Subscribe(ctx, subscribe(filter)); err != nil {
+			b.mu.Lock()
+			delete(b.subs, filter)
+			b.mu.Unlock()
+			return nil, fmt.Errorf("error subscribing to %s: %w", filter, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		b.unsubscribe(filter, id)
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return nil
+	}, nil
+}
+
+// unsubscribe removes handler id from filter and unsubscribes once no handler is left.
+// The server drops the subscriptions with the session anyway, so failures are only logged.
+func (b *Broker) unsubscribe(filter string, id int) {
+	b.subscribing.Lock()
+	defer b.subscribing.Unlock()
+
+	b.mu.Lock()
+	delete(b.subs[filter], id)
+	last := len(b.subs[filter]) == 0
+	if last {
+		delete(b.subs, filter)
+	}
+	b.mu.Unlock()
+	if !last {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.ConnectTimeout)
+	defer cancel()
+	_, err := b.cm.Unsubscribe(ctx, &paho.Unsubscribe{Topics: []string{filter}})
+	if err != nil && !errors.Is(err, autopaho.ConnectionDownError) {
+		log.Printf("Failed to unsubscribe from %s: %v", filter, err)
+	}
+}
+
+// resubscribe restores the subscriptions after the connection came back up
+func (b *Broker) resubscribe(cm *autopaho.ConnectionManager, _ *paho.Connack) {
+	b.subscribing.Lock()
+	defer b.subscribing.Unlock()
+
+	b.mu.Lock()
+	filters := make([]string, 0, len(b.subs))
+	for filter := range b.subs {
+		filters = append(filters, filter)
+	}
+	b.mu.Unlock()
+
+	for _, filter := range filters {
+		ctx, cancel := context.WithTimeout(context.Background(), b.config.ConnectTimeout)
+		if _, err := cm.Subscribe(ctx, subscribe(filter)); err != nil {
+			log.Printf("Failed to restore subscription to %s: %v", filter, err)
+		}
+		cancel()
+	}
+}
+
+// dispatch hands a received message to the handlers of every filter its topic matches
+func (b *Broker) dispatch(pr paho.PublishReceived) (bool, error) {
+	msg := message(pr.Packet)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for filter, handlers := range b.subs {
+		if !scenario.MatchFilter(filter, pr.Packet.Topic) {
+			continue
+		}
+		for _, handle := range handlers {
+			handle(msg)
+		}
+	}
+	return true, nil
+}
+
+// Close disconnects from the server
+func (b *Broker) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.ConnectTimeout)
+	defer cancel()
+	return b.cm.Disconnect(ctx)
+}
+
+// subscribe builds the request subscribing to filter at the highest QoS, so messages
+// arrive at the QoS they were published with
+func subscribe(filter string) *paho.Subscribe {
+	return &paho.Subscribe{Subscriptions: []paho.SubscribeOptions{{Topic: filter, QoS: 2}}}
+}
+
+// publish builds the PUBLISH packet of msg, headers travel as user properties
+func publish(topic string, msg broker.Message, qos byte, retain bool) *paho.Publish {
+	props := &paho.PublishProperties{ContentType: msg.ContentType}
+	if msg.ID != "" {
+		props.User.Add(PropertyMessageID, msg.ID)
+	}
+	for k, v := range msg.Headers {
+		props.User.Add(k, v)
+	}
+	return &paho.Publish{Topic: topic, QoS: qos, Retain: retain, Properties: props, Payload: msg.Body}
+}
+
+// message converts PUBLISH packet p
+func message(p *paho.Publish) broker.Message {
+	msg := broker.Message{Headers: map[string]string{}, Body: p.Payload, Retain: p.Retain}
+	qos := p.QoS
+	msg.QoS = &qos
+	if p.Properties == nil {
+		return msg
+	}
+	msg.ContentType = p.Properties.ContentType
+	for _, prop := range p.Properties.User {
+		if prop.Key == PropertyMessageID {
+			msg.ID = prop.Value
+			continue
+		}
+		msg.Headers[prop.Key] = prop.Value
+	}
+	return msg
+}
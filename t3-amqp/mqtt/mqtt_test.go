@@ -0,0 +1,48 @@
+package mqtt
+
+import (
+	"t3-amqp/broker"
+	"testing"
+
+	"github.com/eclipse/paho.golang/paho"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishRoundTrip(t *testing.T) {
+	qos := byte(1)
+	msg := broker.Message{
+		ID:          "t3.run1.0-3",
+		ContentType: "application/json",
+		Headers:     map[string]string{broker.HeaderInstance: "t3.run1.0", broker.HeaderSchemaVersion: "1.0.0"},
+		Body:        []byte(`{"id":1}`),
+		QoS:         &qos,
+		Retain:      true,
+	}
+
+	p := publish("devices/d1/telemetry", msg, qos, true)
+	assert.Equal(t, "devices/d1/telemetry", p.Topic)
+	assert.Equal(t, "t3.run1.0-3", p.Properties.User.Get(PropertyMessageID))
+	assert.Equal(t, msg, message(p))
+
+	p = &paho.Publish{Topic: "devices/d1/telemetry", Payload: []byte(`{}`)}
+	assert.Empty(t, message(p).ContentType, "messages of MQTT 3 devices carry no properties")
+}
+
+func TestDispatchMatchesFilters(t *testing.T) {
+	b := &Broker{subs: map[string]map[int]func(broker.Message){}}
+	var wildcard, exact, other int
+	b.subs["devices/+/telemetry"] = map[int]func(broker.Message){1: func(broker.Message) { wildcard++ }}
+	b.subs["devices/d1/telemetry"] = map[int]func(broker.Message){2: func(broker.Message) { exact++ }}
+	b.subs["devices/d1/status"] = map[int]func(broker.Message){3: func(broker.Message) { other++ }}
+
+	handled, err := b.dispatch(paho.PublishReceived{Packet: &paho.Publish{Topic: "devices/d1/telemetry"}})
+	assert.NoError(t, err)
+	assert.True(t, handled)
+	handled, err = b.dispatch(paho.PublishReceived{Packet: &paho.Publish{Topic: "devices/d2/telemetry"}})
+	assert.NoError(t, err)
+	assert.True(t, handled)
+
+	assert.Equal(t, 2, wildcard)
+	assert.Equal(t, 1, exact)
+	assert.Zero(t, other)
+}
@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"github.com/nats-io/nats.go/jetstream"
+	"sync"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// acker acknowledges consumed JetStream messages following an ack strategy and keeps the
+// first acknowledgement that failed
+type acker struct {
+	strategy scenario.AckStrategy
+
+	mu      sync.Mutex
+	err     error
+	pending jetstream.Msg // last message of an incomplete batch
+	count   int
+	timers  map[*time.Timer]struct{}
+}
+
+func newAcker(strategy scenario.AckStrategy) *acker {
+	return &acker{strategy: strategy, timers: map[*time.Timer]struct{}{}}
+}
+
+// ack acknowledges m now, with its batch or after the delay, auto acks send nothing.
+// Nack and reject strategies settle the failing share of the messages negatively.
+func (a *acker) ack(m jetstream.Msg) {
+	switch a.strategy.Mode {
+	case scenario.AckAuto:
+	case scenario.AckBatch:
+		a.mu.Lock()
+		a.count++
+		a.pending = m
+		full := a.count%a.strategy.BatchSize == 0
+		if full {
+			a.pending = nil
+		}
+		a.mu.Unlock()
+		// The consumer acknowledges everything up to the last message of the batch
+		if full {
+			a.record(m.Ack())
+		}
+	case scenario.AckDelayed:
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		var t *time.Timer
+		t = time.AfterFunc(a.strategy.Delay, func() {
+			a.record(m.Ack())
+			a.mu.Lock()
+			delete(a.timers, t)
+			a.mu.Unlock()
+		})
+		a.timers[t] = struct{}{}
+	case scenario.AckNack, scenario.AckReject:
+		a.mu.Lock()
+		fail := a.strategy.Fails(a.count)
+		a.count++
+		a.mu.Unlock()
+		// Terminated messages are not redelivered, JetStream's counterpart of a reject
+		switch {
+		case !fail:
+			a.record(m.Ack())
+		case a.strategy.Mode == scenario.AckNack:
+			a.record(m.Nak())
+		default:
+			a.record(m.Term())
+		}
+	default:
+		a.record(m.Ack())
+	}
+}
+
+func (a *acker) record(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err == nil {
+		a.err = err
+	}
+}
+
+// finish acknowledges the rest of an incomplete batch, drops delayed acks that are still
+// waiting and returns the first acknowledgement that failed
+func (a *acker) finish() error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = nil
+	for t := range a.timers {
+		t.Stop()
+	}
+	a.timers = map[*time.Timer]struct{}{}
+	a.mu.Unlock()
+
+	if pending != nil {
+		a.record(pending.Ack())
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
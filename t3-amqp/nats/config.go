@@ -0,0 +1,70 @@
+package nats
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"t3-amqp/db"
+	"time"
+)
+
+const (
+	DefaultName           = "t3"
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultAckWait        = 30 * time.Second
+)
+
+// JetStreamConfig describes the streams DeclareTopic creates for subjects no stream
+// captures yet. Storage is file or memory, zero values use the server defaults.
+type JetStreamConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Storage  string        `mapstructure:"storage"`
+	Replicas int           `mapstructure:"replicas"`
+	MaxAge   time.Duration `mapstructure:"max_age"`
+	AckWait  time.Duration `mapstructure:"ack_wait"`
+}
+
+// Config holds the NATS server to connect to. URL is a nats:// or tls:// address and may
+// list several servers separated by commas, credentials in it are used as is.
+type Config struct {
+	URL            string          `mapstructure:"url"`
+	Name           string          `mapstructure:"name"`
+	Token          string          `mapstructure:"token"`
+	CredsFile      string          `mapstructure:"creds_file"`
+	ConnectTimeout time.Duration   `mapstructure:"connect_timeout"`
+	JetStream      JetStreamConfig `mapstructure:"jetstream"`
+}
+
+// LoadConfig reads the nats section of the already loaded configuration, NATS_*
+// environment variables override the file
+func LoadConfig() (*Config, error) {
+	if err := db.BindEnv("nats", Config{}); err != nil {
+		return nil, err
+	}
+	// UnmarshalKey would only see the file, environment overrides need the full settings
+	var settings struct {
+		NATS Config `mapstructure:"nats"`
+	}
+	if err := viper.Unmarshal(&settings); err != nil {
+		return nil, fmt.Errorf("unable to decode nats config: %w", err)
+	}
+	config := settings.NATS
+	switch config.JetStream.Storage {
+	case "", "file", "memory":
+	default:
+		return nil, fmt.Errorf("nats jetstream storage must be file or memory, got %q", config.JetStream.Storage)
+	}
+	config.applyDefaults()
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.Name == "" {
+		c.Name = DefaultName
+	}
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = DefaultConnectTimeout
+	}
+	if c.JetStream.AckWait <= 0 {
+		c.JetStream.AckWait = DefaultAckWait
+	}
+}
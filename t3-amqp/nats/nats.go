@@ -0,0 +1,324 @@
+// Package nats implements broker.Broker for NATS subjects, on core NATS or with the
+// messages kept in JetStream streams
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	natsio "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"regexp"
+	"sync"
+	"t3-amqp/broker"
+	"t3-amqp/redact"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// Header carrying the content type, the message ID goes in the JetStream
+// deduplication header
+const HeaderContentType = "Content-Type"
+
+// consumerInactivity is how long the server keeps a consumer nobody fetches from, so
+// consumers of crashed subscriptions go away on their own
+const consumerInactivity = 5 * time.Minute
+
+// Broker publishes to and subscribes on NATS subjects. With JetStream enabled publishes
+// wait for the stream to store the message and subscriptions read through ephemeral
+// consumers, which also makes ack strategies available. Core NATS delivers at most once
+// and has no acknowledgements.
+type Broker struct {
+	config Config
+	nc     *natsio.Conn
+	// js is nil unless JetStream is enabled
+	js jetstream.JetStream
+}
+
+// Dial connects to the servers in config, reconnecting for as long as the broker is open
+func Dial(config Config) (*Broker, error) {
+	config.applyDefaults()
+	opts := []natsio.Option{
+		natsio.Name(config.Name), natsio.Timeout(config.ConnectTimeout), natsio.MaxReconnects(-1),
+	}
+	if config.Token != "" {
+		opts = append(opts, natsio.Token(config.Token))
+	}
+	if config.CredsFile != "" {
+		opts = append(opts, natsio.UserCredentials(config.CredsFile))
+	}
+	nc, err := natsio.Connect(config.URL, opts...)
+	if err != nil {
+		return nil, redact.Error(fmt.Errorf("unable to connect to nats: %w", err))
+	}
+
+	b := &Broker{config: config, nc: nc}
+	if config.JetStream.Enabled {
+		if b.js, err = jetstream.New(nc); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("unable to use jetstream: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// DeclareTopic makes sure a stream captures the subject topic, creating one named after
+// it otherwise. Core NATS subjects need not be declared.
+func (b *Broker) DeclareTopic(ctx context.Context, topic string) error {
+	if b.js == nil {
+		return nil
+	}
+	_, err := b.js.StreamNameBySubject(ctx, topic)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("error looking up the stream of %s: %w", topic, err)
+	}
+
+	_, err = b.js.CreateStream(ctx, b.streamConfig(topic))
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("error creating stream for %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Publish sends msg to the subject topic. JetStream publishes return once the stream
+// stored the message, core NATS ones once the server received it.
+func (b *Broker) Publish(ctx context.Context, topic string, msg broker.Message) error {
+	m := natsMsg(topic, msg)
+	var err error
+	if b.js != nil {
+		_, err = b.js.PublishMsg(ctx, m)
+	} else if err = b.nc.PublishMsg(m); err == nil {
+		err = b.flush(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("error publishing to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe calls handle for every message published to the subject topic, which may
+// use the * and > wildcards on core NATS. JetStream subscriptions need a stream
+// capturing topic and do not acknowledge messages.
+func (b *Broker) Subscribe(ctx context.Context, topic string, handle func(broker.Message)) (func() error, error) {
+	if b.js != nil {
+		return b.consume(ctx, topic, scenario.AckStrategy{Mode: scenario.AckAuto}, handle)
+	}
+
+	g := &guard{handle: handle}
+	sub, err := b.nc.Subscribe(topic, func(m *natsio.Msg) { g.deliver(message(m.Header, m.Data)) })
+	if err == nil {
+		// The subscription is in place once the server processed it
+		err = b.flush(ctx)
+	}
+	if err != nil {
+		if sub != nil {
+			_ = sub.Unsubscribe()
+		}
+		return nil, fmt.Errorf("error subscribing to %s: %w", topic, err)
+	}
+	return untilDone(ctx, func() error {
+		g.stop()
+		if err := sub.Unsubscribe(); err != nil && !errors.Is(err, natsio.ErrConnectionClosed) {
+			return fmt.Errorf("error unsubscribing from %s: %w", topic, err)
+		}
+		return nil
+	}), nil
+}
+
+// SubscribeAck reads topic through an ephemeral JetStream consumer acknowledging messages
+// the way ack asks for. Prefetch limits the messages pending acknowledgement.
+func (b *Broker) SubscribeAck(
+	ctx context.Context, topic string, ack scenario.AckStrategy, handle func(broker.Message),
+) (func() error, error) {
+	if b.js == nil {
+		return nil, fmt.Errorf("%w: ack strategies need jetstream", broker.ErrNoAcks)
+	}
+	return b.consume(ctx, topic, ack, handle)
+}
+
+// consume creates an ephemeral consumer for the new messages of topic and hands them to
+// handle. The consumer is deleted when the subscription ends.
+func (b *Broker) consume(
+	ctx context.Context, topic string, ack scenario.AckStrategy, handle func(broker.Message),
+) (func() error, error) {
+	stream, err := b.js.StreamNameBySubject(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up the stream of %s: %w", topic, err)
+	}
+	consumer, err := b.js.CreateConsumer(ctx, stream, b.consumerConfig(topic, ack))
+	if err != nil {
+		return nil, fmt.Errorf("error creating consumer for %s: %w", topic, err)
+	}
+
+	g := &guard{handle: handle}
+	a := newAcker(ack)
+	cc, err := consumer.Consume(func(m jetstream.Msg) {
+		if g.deliver(message(m.Headers(), m.Data())) {
+			a.ack(m)
+		}
+	})
+	if err != nil {
+		b.deleteConsumer(stream, consumer)
+		return nil, fmt.Errorf("error consuming %s: %w", topic, err)
+	}
+	return untilDone(ctx, func() error {
+		cc.Stop()
+		<-cc.Closed()
+		g.stop()
+		err := a.finish()
+		b.deleteConsumer(stream, consumer)
+		if err != nil {
+			return fmt.Errorf("error acknowledging messages of %s: %w", topic, err)
+		}
+		return nil
+	}), nil
+}
+
+// deleteConsumer removes consumer right away, the server would only do so once it was
+// inactive for a while
+func (b *Broker) deleteConsumer(stream string, consumer jetstream.Consumer) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.ConnectTimeout)
+	defer cancel()
+	_ = b.js.DeleteConsumer(ctx, stream, consumer.CachedInfo().Name)
+}
+
+// Close drains the subscriptions and closes the connection
+func (b *Broker) Close() error {
+	return b.nc.Drain()
+}
+
+// flush waits until the server processed everything sent so far, ctx without a deadline
+// waits at most the connect timeout
+func (b *Broker) flush(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.config.ConnectTimeout)
+		defer cancel()
+	}
+	return b.nc.FlushWithContext(ctx)
+}
+
+// invalidStreamName matches the characters stream names must not contain
+var invalidStreamName = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// StreamName is the name of the stream DeclareTopic creates for topic
+func StreamName(topic string) string {
+	return "T3_" + invalidStreamName.ReplaceAllString(topic, "_")
+}
+
+func (b *Broker) streamConfig(topic string) jetstream.StreamConfig {
+	cfg := jetstream.StreamConfig{
+		Name:     StreamName(topic),
+		Subjects: []string{topic},
+		Replicas: b.config.JetStream.Replicas,
+		MaxAge:   b.config.JetStream.MaxAge,
+	}
+	if b.config.JetStream.Storage == "memory" {
+		cfg.Storage = jetstream.MemoryStorage
+	}
+	return cfg
+}
+
+// consumerConfig describes an ephemeral consumer of the messages published to topic from
+// now on. Auto acks need no acknowledgements at all, batch acks acknowledge everything
+// up to the message they are sent for.
+func (b *Broker) consumerConfig(topic string, ack scenario.AckStrategy) jetstream.ConsumerConfig {
+	cfg := jetstream.ConsumerConfig{
+		DeliverPolicy:     jetstream.DeliverNewPolicy,
+		FilterSubject:     topic,
+		AckPolicy:         jetstream.AckExplicitPolicy,
+		AckWait:           b.config.JetStream.AckWait,
+		InactiveThreshold: consumerInactivity,
+	}
+	switch ack.Mode {
+	case scenario.AckAuto:
+		cfg.AckPolicy = jetstream.AckNonePolicy
+		cfg.AckWait = 0
+		return cfg
+	case scenario.AckBatch:
+		cfg.AckPolicy = jetstream.AckAllPolicy
+	}
+	cfg.MaxAckPending = -1
+	if ack.Prefetch > 0 {
+		cfg.MaxAckPending = ack.Prefetch
+	}
+	return cfg
+}
+
+// natsMsg builds the NATS message of msg, the content type and ID travel as headers
+func natsMsg(topic string, msg broker.Message) *natsio.Msg {
+	m := natsio.NewMsg(topic)
+	m.Data = msg.Body
+	for k, v := range msg.Headers {
+		m.Header.Set(k, v)
+	}
+	if msg.ContentType != "" {
+		m.Header.Set(HeaderContentType, msg.ContentType)
+	}
+	if msg.ID != "" {
+		m.Header.Set(natsio.MsgIdHdr, msg.ID)
+	}
+	return m
+}
+
+// message converts a received message, only the first value of every header is kept
+func message(h natsio.Header, data []byte) broker.Message {
+	msg := broker.Message{Headers: make(map[string]string, len(h)), Body: data}
+	for k, values := range h {
+		if len(values) == 0 {
+			continue
+		}
+		switch k {
+		case HeaderContentType:
+			msg.ContentType = values[0]
+		case natsio.MsgIdHdr:
+			msg.ID = values[0]
+		default:
+			msg.Headers[k] = values[0]
+		}
+	}
+	return msg
+}
+
+// guard stops handing messages to handle once stop returned, even while the client
+// still delivers some
+type guard struct {
+	mu      sync.Mutex
+	handle  func(broker.Message)
+	stopped bool
+}
+
+// deliver calls handle with m unless the subscription stopped and reports whether it did
+func (g *guard) deliver(m broker.Message) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		return false
+	}
+	g.handle(m)
+	return true
+}
+
+func (g *guard) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopped = true
+}
+
+// untilDone runs stop once ctx is done or the returned function is called, whichever
+// comes first, and has the function wait for it
+func untilDone(ctx context.Context, stop func() error) func() error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		done <- stop()
+	}()
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
@@ -0,0 +1,132 @@
+package nats
+
+import (
+	"errors"
+	"sync"
+	"t3-amqp/broker"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMsg counts its acknowledgements, the embedded interface panics on anything else
+type fakeMsg struct {
+	jetstream.Msg
+	mu    sync.Mutex
+	acks  int
+	naks  int
+	terms int
+	err   error
+}
+
+func (m *fakeMsg) Ack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acks++
+	return m.err
+}
+
+func (m *fakeMsg) Nak() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.naks++
+	return nil
+}
+
+func (m *fakeMsg) Term() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.terms++
+	return nil
+}
+
+func (m *fakeMsg) acked() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.acks
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	msg := broker.Message{
+		ID:          "t3.run1.0-3",
+		ContentType: "application/json",
+		Headers:     map[string]string{broker.HeaderInstance: "t3.run1.0", broker.HeaderSchemaVersion: "1.0.0"},
+		Body:        []byte(`{"id":1}`),
+	}
+
+	m := natsMsg("orders.created", msg)
+	assert.Equal(t, "orders.created", m.Subject)
+	assert.Equal(t, "t3.run1.0-3", m.Header.Get("Nats-Msg-Id"))
+	assert.Equal(t, msg, message(m.Header, m.Data))
+}
+
+func TestStreamAndConsumerConfig(t *testing.T) {
+	b := &Broker{config: Config{JetStream: JetStreamConfig{Storage: "memory", AckWait: time.Second}}}
+	stream := b.streamConfig("orders.created")
+	assert.Equal(t, "T3_orders_created", stream.Name)
+	assert.Equal(t, jetstream.MemoryStorage, stream.Storage)
+
+	cfg := b.consumerConfig("orders.created", scenario.AckStrategy{Mode: scenario.AckAuto})
+	assert.Equal(t, jetstream.AckNonePolicy, cfg.AckPolicy)
+	cfg = b.consumerConfig("orders.created", scenario.AckStrategy{Mode: scenario.AckBatch, BatchSize: 10, Prefetch: 50})
+	assert.Equal(t, jetstream.AckAllPolicy, cfg.AckPolicy)
+	assert.Equal(t, 50, cfg.MaxAckPending)
+	cfg = b.consumerConfig("orders.created", scenario.AckStrategy{})
+	assert.Equal(t, jetstream.AckExplicitPolicy, cfg.AckPolicy)
+	assert.Equal(t, -1, cfg.MaxAckPending, "prefetch 0 leaves pending acks unlimited")
+}
+
+func TestAcker(t *testing.T) {
+	msgs := make([]*fakeMsg, 5)
+	for i := range msgs {
+		msgs[i] = &fakeMsg{}
+	}
+
+	a := newAcker(scenario.AckStrategy{Mode: scenario.AckBatch, BatchSize: 2})
+	for _, m := range msgs {
+		a.ack(m)
+	}
+	assert.Equal(t, []int{0, 1, 0, 1, 0}, acks(msgs))
+	assert.NoError(t, a.finish())
+	assert.Equal(t, 1, msgs[4].acked(), "finish acknowledges the incomplete batch")
+
+	failing := &fakeMsg{err: errors.New("timeout")}
+	a = newAcker(scenario.AckStrategy{Mode: scenario.AckManual})
+	a.ack(failing)
+	a.ack(msgs[0])
+	assert.EqualError(t, a.finish(), "timeout")
+
+	delayed := &fakeMsg{}
+	a = newAcker(scenario.AckStrategy{Mode: scenario.AckDelayed, Delay: 10 * time.Millisecond})
+	a.ack(delayed)
+	assert.Zero(t, delayed.acked())
+	assert.Eventually(t, func() bool { return delayed.acked() == 1 }, time.Second, 5*time.Millisecond)
+
+	dropped := &fakeMsg{}
+	a = newAcker(scenario.AckStrategy{Mode: scenario.AckDelayed, Delay: time.Hour})
+	a.ack(dropped)
+	assert.NoError(t, a.finish())
+	assert.Zero(t, dropped.acked())
+
+	nacked, terminated := &fakeMsg{}, &fakeMsg{}
+	a = newAcker(scenario.AckStrategy{Mode: scenario.AckNack, FailRate: 0.5})
+	a.ack(nacked)
+	a.ack(nacked)
+	assert.Equal(t, 1, nacked.acked())
+	assert.Equal(t, 1, nacked.naks)
+	a = newAcker(scenario.AckStrategy{Mode: scenario.AckReject, FailRate: 1})
+	a.ack(terminated)
+	assert.Equal(t, 1, terminated.terms)
+	assert.NoError(t, a.finish())
+}
+
+func acks(msgs []*fakeMsg) []int {
+	counts := make([]int, len(msgs))
+	for i, m := range msgs {
+		counts[i] = m.acked()
+	}
+	return counts
+}
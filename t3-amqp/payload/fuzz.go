@@ -0,0 +1,395 @@
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation names the rule of a schema a fuzzed payload breaks
+type Violation struct {
+	// Rule is the JSON schema keyword broken, like required, type or maximum
+	Rule string `json:"rule"`
+	// Path is the JSON pointer of the offending value in the payload, empty for the root
+	Path   string `json:"path"`
+	Detail string `json:"detail"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s at %q: %s", v.Rule, v.Path, v.Detail)
+}
+
+// Fuzzer produces payloads that each break one rule of a JSON schema, for testing how
+// consumers handle invalid messages
+type Fuzzer struct {
+	gen *jsonSchemaGenerator
+}
+
+// JSONSchemaFuzzer returns a Fuzzer for a JSON schema. Each payload is a valid one with a
+// single mutation: a required property dropped, a value of the wrong type, outside its
+// enum or range, a string or array of the wrong length, an unmatched pattern or an
+// unexpected property. Rules that overlap, like required and minProperties, may both
+// break. Mutations below anyOf or oneOf may still match another branch, so callers needing
+// certainty validate the result. Payload i is the same for a given seed.
+func JSONSchemaFuzzer(schema []byte, seed int64) (*Fuzzer, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %w", err)
+	}
+	f := &Fuzzer{gen: &jsonSchemaGenerator{root: root, seed: seed}}
+	if _, _, err := f.Fuzz(0); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Fuzz returns invalid payload i and the rule it breaks
+func (f *Fuzzer) Fuzz(i int) ([]byte, Violation, error) {
+	r := rand.New(rand.NewSource(f.gen.seed + int64(i)))
+	value, err := f.gen.value(r, f.gen.root, "#", 0)
+	if err != nil {
+		return nil, Violation{}, err
+	}
+
+	holder := []interface{}{value}
+	var mutations []mutation
+	err = f.collect(f.gen.root, holder[0], "", func(v interface{}) { holder[0] = v }, &mutations)
+	if err != nil {
+		return nil, Violation{}, err
+	}
+	if len(mutations) == 0 {
+		return nil, Violation{}, fmt.Errorf("%w: schema accepts any payload", ErrCannotGenerate)
+	}
+
+	m := mutations[r.Intn(len(mutations))]
+	m.apply()
+	data, err := json.Marshal(holder[0])
+	return data, m.violation, err
+}
+
+// Generate returns invalid payload i without its violation so a Fuzzer can feed a Source
+func (f *Fuzzer) Generate(i int) ([]byte, error) {
+	data, _, err := f.Fuzz(i)
+	return data, err
+}
+
+// mutation is a change to a generated value breaking one rule
+type mutation struct {
+	violation Violation
+	apply     func()
+}
+
+// collect walks value alongside its schema and records the mutations each keyword allows.
+// set replaces value in its parent.
+func (f *Fuzzer) collect(
+	schema, value interface{}, path string, set func(interface{}), mutations *[]mutation,
+) error {
+	s, err := f.gen.resolve(schema, path)
+	if err != nil || s == nil {
+		return err
+	}
+	add := func(rule, detail string, apply func()) {
+		*mutations = append(*mutations, mutation{Violation{Rule: rule, Path: path, Detail: detail}, apply})
+	}
+	replace := func(rule, detail string, v interface{}) {
+		add(rule, detail, func() { set(v) })
+	}
+
+	if _, ok := s["type"]; ok {
+		if wrong, ok := wrongType(s["type"]); ok {
+			replace("type", fmt.Sprintf("%s instead of %s", jsonType(wrong), typeList(s["type"])), wrong)
+		}
+	}
+	if constant, ok := s["const"]; ok {
+		replace("const", "value other than the constant", outside([]interface{}{constant}))
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		replace("enum", "value outside the enum", outside(enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		collectObject(s, v, add)
+		for _, name := range sortedKeys(v) {
+			set := func(x interface{}) { v[name] = x }
+			err := f.collect(propertySchema(s, name), v[name], path+"/"+pointerEscape(name), set, mutations)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		collectArray(s, v, add, set)
+		prefix, items := itemSchemas(s)
+		for i := range v {
+			schema := items
+			if i < len(prefix) {
+				schema = prefix[i]
+			}
+			set := func(x interface{}) { v[i] = x }
+			err := f.collect(schema, v[i], path+"/"+strconv.Itoa(i), set, mutations)
+			if err != nil {
+				return err
+			}
+		}
+	case string:
+		collectString(s, v, replace)
+	case float64, int64, json.Number:
+		collectNumber(s, toFloat(v), s["type"] == "integer", replace)
+	}
+	return nil
+}
+
+func collectObject(s, object map[string]interface{}, add func(string, string, func())) {
+	required, _ := s["required"].([]interface{})
+	for _, name := range required {
+		name, ok := name.(string)
+		if _, present := object[name]; !ok || !present {
+			continue
+		}
+		add("required", "missing required property "+name, func() { delete(object, name) })
+	}
+	if additional, ok := s["additionalProperties"].(bool); ok && !additional {
+		properties, _ := s["properties"].(map[string]interface{})
+		name := "unexpected"
+		for i := 1; properties[name] != nil; i++ {
+			name = fmt.Sprintf("unexpected%d", i)
+		}
+		add("additionalProperties", "unexpected property "+name, func() { object[name] = "fuzz" })
+	}
+	if n := intKeyword(s, "minProperties", 0); n > 0 && len(object) >= n {
+		add("minProperties", fmt.Sprintf("fewer than %d properties", n), func() {
+			for _, name := range sortedKeys(object)[n-1:] {
+				delete(object, name)
+			}
+		})
+	}
+}
+
+func collectArray(
+	s map[string]interface{}, array []interface{}, add func(string, string, func()), set func(interface{}),
+) {
+	if n := intKeyword(s, "minItems", 0); n > 0 {
+		add("minItems", fmt.Sprintf("%d items, at least %d required", n-1, n), func() {
+			set(slices.Clone(array[:min(n-1, len(array))]))
+		})
+	}
+	if n, ok := s["maxItems"].(float64); ok && len(array) > 0 {
+		add("maxItems", fmt.Sprintf("%d items, at most %d allowed", int(n)+1, int(n)), func() {
+			longer := slices.Clone(array)
+			for len(longer) <= int(n) {
+				longer = append(longer, array[len(longer)%len(array)])
+			}
+			set(longer)
+		})
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique && len(array) >= 2 {
+		add("uniqueItems", "items 0 and 1 are equal", func() {
+			duplicated := slices.Clone(array)
+			duplicated[1] = duplicated[0]
+			set(duplicated)
+		})
+	}
+}
+
+func collectString(s map[string]interface{}, value string, replace func(string, string, interface{})) {
+	length := utf8.RuneCountInString(value)
+	if n := intKeyword(s, "minLength", 0); n > 0 {
+		replace("minLength", fmt.Sprintf("%d characters, at least %d required", n-1, n), strings.Repeat("x", n-1))
+	}
+	if n, ok := s["maxLength"].(float64); ok {
+		longer := value + strings.Repeat("x", max(int(n)+1-length, 0))
+		replace("maxLength", fmt.Sprintf("%d characters, at most %d allowed", int(n)+1, int(n)), longer)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return
+		}
+		for _, candidate := range []string{"", "~", "~fuzz~", "FUZZ fuzz 0 ~"} {
+			if !re.MatchString(candidate) {
+				replace("pattern", fmt.Sprintf("%q does not match %s", candidate, pattern), candidate)
+				return
+			}
+		}
+	}
+}
+
+func collectNumber(
+	s map[string]interface{}, value float64, integer bool, replace func(string, string, interface{}),
+) {
+	step := 1.0
+	if !integer {
+		step = 0.5
+	}
+	if lo, ok := s["minimum"].(float64); ok {
+		if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive {
+			replace("exclusiveMinimum", fmt.Sprintf("%g not above %g", lo, lo), lo)
+		} else {
+			replace("minimum", fmt.Sprintf("%g below %g", lo-step, lo), lo-step)
+		}
+	}
+	if lo, ok := s["exclusiveMinimum"].(float64); ok {
+		replace("exclusiveMinimum", fmt.Sprintf("%g not above %g", lo, lo), lo)
+	}
+	if hi, ok := s["maximum"].(float64); ok {
+		if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive {
+			replace("exclusiveMaximum", fmt.Sprintf("%g not below %g", hi, hi), hi)
+		} else {
+			replace("maximum", fmt.Sprintf("%g above %g", hi+step, hi), hi+step)
+		}
+	}
+	if hi, ok := s["exclusiveMaximum"].(float64); ok {
+		replace("exclusiveMaximum", fmt.Sprintf("%g not below %g", hi, hi), hi)
+	}
+	// Half a step off is never a multiple, it stays an integer for integer steps above 1
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 && (!integer || m >= 2 && m == float64(int64(m))) {
+		off := value + m/2
+		if integer {
+			off = value + float64(int64(m)/2)
+		}
+		replace("multipleOf", fmt.Sprintf("%g is not a multiple of %g", off, m), off)
+	}
+}
+
+// wrongType returns a value of none of the types allowed, false when every type is
+func wrongType(types interface{}) (interface{}, bool) {
+	allowed := map[string]bool{}
+	switch t := types.(type) {
+	case string:
+		allowed[t] = true
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				allowed[name] = true
+			}
+		}
+	}
+	// A fraction breaks integer as well as every non-numeric type
+	candidates := []struct {
+		value interface{}
+		wrong bool
+	}{
+		{"fuzz", !allowed["string"]},
+		{0.5, !allowed["number"]},
+		{true, !allowed["boolean"]},
+		{map[string]interface{}{}, !allowed["object"]},
+		{[]interface{}{}, !allowed["array"]},
+		{nil, !allowed["null"]},
+	}
+	for _, c := range candidates {
+		if c.wrong {
+			return c.value, true
+		}
+	}
+	return nil, false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v != float64(int64(v)) {
+			return "number"
+		}
+		return "integer"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func typeList(types interface{}) string {
+	if list, ok := types.([]interface{}); ok {
+		names := make([]string, 0, len(list))
+		for _, name := range list {
+			names = append(names, fmt.Sprint(name))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+// outside returns a value equal to none of values, a number above them when they are all
+// numbers and a string otherwise
+func outside(values []interface{}) interface{} {
+	highest, numeric := 0.0, true
+	for _, v := range values {
+		n, ok := v.(float64)
+		numeric = numeric && ok
+		if ok && n >= highest {
+			highest = n
+		}
+	}
+	if numeric {
+		return highest + 1
+	}
+	candidate := "fuzz"
+	for i := 1; slices.ContainsFunc(values, func(v interface{}) bool { return reflect.DeepEqual(v, candidate) }); i++ {
+		candidate = fmt.Sprintf("fuzz%d", i)
+	}
+	return candidate
+}
+
+// propertySchema returns the schema of the named property of an object schema
+func propertySchema(s map[string]interface{}, name string) interface{} {
+	properties, _ := s["properties"].(map[string]interface{})
+	if schema, ok := properties[name]; ok {
+		return schema
+	}
+	if schema, ok := s["additionalProperties"]; ok {
+		return schema
+	}
+	return true
+}
+
+// itemSchemas returns the tuple schemas and the schema of the remaining items
+func itemSchemas(s map[string]interface{}) ([]interface{}, interface{}) {
+	prefix, _ := s["prefixItems"].([]interface{})
+	items := s["items"]
+	if tuple, ok := items.([]interface{}); ok {
+		prefix = tuple
+		items = s["additionalItems"]
+	}
+	if items == nil {
+		items = true
+	}
+	return prefix, items
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func pointerEscape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func toFloat(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzerBreaksOneRule(t *testing.T) {
+	schemas := map[string]string{
+		"order": orderSchema,
+		"numbers": `{
+			"type": "object",
+			"required": ["n", "x", "tags"],
+			"properties": {
+				"n": {"type": "integer", "minimum": 0, "maximum": 100, "multipleOf": 4},
+				"x": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+				"tags": {"type": "array", "minItems": 2, "maxItems": 3, "uniqueItems": true, "items": {"type": "string"}}
+			}
+		}`,
+	}
+
+	for name, schema := range schemas {
+		validator, err := validate.Compile("json", schema)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		fuzzer, err := JSONSchemaFuzzer([]byte(schema), 42)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+
+		rules := map[string]bool{}
+		for i := 0; i < 300; i++ {
+			data, violation, err := fuzzer.Fuzz(i)
+			if !assert.NoError(t, err, name) {
+				break
+			}
+			rules[violation.Rule] = true
+			err = validator.Validate(data)
+			if assert.Error(t, err, "%s payload %d breaks %s: %s", name, i, violation, data) {
+				assert.Contains(t, err.Error(), "/"+violation.Rule, "%s payload %d: %s", name, i, data)
+			}
+		}
+		assert.Greater(t, len(rules), 5, "%s should break many different rules: %v", name, rules)
+	}
+}
+
+func TestFuzzerViolations(t *testing.T) {
+	fuzzer, err := JSONSchemaFuzzer([]byte(orderSchema), 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	seen := map[string]Violation{}
+	for i := 0; i < 500; i++ {
+		data, violation, err := fuzzer.Fuzz(i)
+		if !assert.NoError(t, err) {
+			return
+		}
+		key := violation.Rule + " " + violation.Path
+		seen[key] = violation
+
+		if violation.Rule == "required" && violation.Path == "" {
+			var order map[string]interface{}
+			assert.NoError(t, json.Unmarshal(data, &order))
+			missing := strings.TrimPrefix(violation.Detail, "missing required property ")
+			assert.NotContains(t, order, missing)
+		}
+	}
+
+	assert.Contains(t, seen, "required ")
+	assert.Contains(t, seen, "additionalProperties ")
+	assert.Contains(t, seen, "enum /status")
+	assert.Contains(t, seen, "minItems /lines")
+	assert.Contains(t, seen, "exclusiveMaximum /lines/0/quantity")
+	assert.Contains(t, seen, "const /customer/tier")
+	assert.Equal(t, "string instead of object", seen["type "].Detail)
+
+	again, err := JSONSchemaFuzzer([]byte(orderSchema), 1)
+	assert.NoError(t, err)
+	first, v1, _ := fuzzer.Fuzz(9)
+	second, v2, _ := again.Fuzz(9)
+	assert.Equal(t, string(first), string(second))
+	assert.Equal(t, v1, v2)
+}
+
+func TestFuzzerNeedsRules(t *testing.T) {
+	for _, schema := range []string{`{}`, `true`, `{"type": ["string", "number", "integer", "boolean", "object", "array", "null"]}`} {
+		_, err := JSONSchemaFuzzer([]byte(schema), 0)
+		assert.True(t, errors.Is(err, ErrCannotGenerate), "%s: %v", schema, err)
+	}
+}
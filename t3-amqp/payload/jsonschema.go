@@ -0,0 +1,614 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrCannotGenerate is returned when a JSON schema allows no value the generator can build
+var ErrCannotGenerate = errors.New("cannot generate a payload for schema")
+
+const (
+	// optionalDepth stops optional properties and array items below this nesting so
+	// recursive schemas terminate
+	optionalDepth = 6
+	// maxDepth fails schemas whose required members recurse without end
+	maxDepth = 32
+	// maxRepeat bounds the open ended repetitions of arrays, strings and patterns
+	maxRepeat = 4
+)
+
+const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// jsonSchemaGenerator builds random values from a parsed JSON schema
+type jsonSchemaGenerator struct {
+	root interface{}
+	seed int64
+}
+
+// JSONSchema returns a Generator of random payloads valid against a JSON schema. It honours
+// types, enum and const, required and optional properties, array and string bounds,
+// numeric ranges, patterns and the common formats, and follows local $refs, allOf, anyOf
+// and oneOf. Payload i is the same for a given seed.
+func JSONSchema(schema []byte, seed int64) (Generator, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %w", err)
+	}
+	g := &jsonSchemaGenerator{root: root, seed: seed}
+	// Generating once up front reports schemas that cannot work before anything is sent
+	if _, err := g.Generate(0); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *jsonSchemaGenerator) Generate(i int) ([]byte, error) {
+	value, err := g.value(rand.New(rand.NewSource(g.seed+int64(i))), g.root, "#", 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func (g *jsonSchemaGenerator) value(r *rand.Rand, schema interface{}, path string, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: %s nests deeper than %d levels", ErrCannotGenerate, path, maxDepth)
+	}
+	s, err := g.resolve(schema, path)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w: %s allows no value", ErrCannotGenerate, path)
+	}
+
+	if value, ok := s["const"]; ok {
+		return value, nil
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		if len(enum) == 0 {
+			return nil, fmt.Errorf("%w: %s has an empty enum", ErrCannotGenerate, path)
+		}
+		return enum[r.Intn(len(enum))], nil
+	}
+
+	switch schemaType(r, s) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return r.Intn(2) == 1, nil
+	case "integer":
+		return integerValue(r, s, path)
+	case "number":
+		return numberValue(r, s, path)
+	case "string":
+		return stringValue(r, s, path)
+	case "array":
+		return g.arrayValue(r, s, path, depth)
+	case "object":
+		return g.objectValue(r, s, path, depth)
+	default:
+		return nil, fmt.Errorf("%w: %s has an unknown type", ErrCannotGenerate, path)
+	}
+}
+
+// resolve follows $ref and folds allOf, anyOf and oneOf into a single schema object. A
+// nil schema means none is allowed, true or an empty object allow anything.
+func (g *jsonSchemaGenerator) resolve(schema interface{}, path string) (map[string]interface{}, error) {
+	for range maxDepth {
+		switch s := schema.(type) {
+		case bool:
+			if !s {
+				return nil, nil
+			}
+			return map[string]interface{}{}, nil
+		case map[string]interface{}:
+			ref, ok := s["$ref"].(string)
+			if !ok {
+				return g.combine(s, path)
+			}
+			target, err := g.pointer(ref)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrCannotGenerate, path, err)
+			}
+			// Siblings of $ref apply too since draft 2019-09
+			if len(s) > 1 {
+				rest := clone(s)
+				delete(rest, "$ref")
+				target = map[string]interface{}{"allOf": []interface{}{target, rest}}
+			}
+			schema, path = target, ref
+		default:
+			return nil, fmt.Errorf("%w: %s is not a schema", ErrCannotGenerate, path)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has a $ref cycle", ErrCannotGenerate, path)
+}
+
+// pointer looks up a local reference such as #/$defs/address in the root schema
+func (g *jsonSchemaGenerator) pointer(ref string) (interface{}, error) {
+	fragment, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("only local $refs are supported, got %q", ref)
+	}
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %w", ref, err)
+	}
+	value := g.root
+	for _, token := range strings.Split(fragment, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %q does not resolve", ref)
+		}
+		if value, ok = object[token]; !ok {
+			return nil, fmt.Errorf("$ref %q does not resolve", ref)
+		}
+	}
+	return value, nil
+}
+
+// combine merges the allOf subschemas into s and the first branch of anyOf or oneOf.
+// Later branches are not tried, the first one usually being the common case.
+func (g *jsonSchemaGenerator) combine(s map[string]interface{}, path string) (map[string]interface{}, error) {
+	all, hasAll := s["allOf"].([]interface{})
+	branches, hasAny := s["anyOf"].([]interface{})
+	if !hasAny {
+		branches, hasAny = s["oneOf"].([]interface{})
+	}
+	if !hasAll && !hasAny {
+		return s, nil
+	}
+
+	merged := clone(s)
+	delete(merged, "allOf")
+	delete(merged, "anyOf")
+	delete(merged, "oneOf")
+	if hasAny && len(branches) > 0 {
+		all = append(slices.Clone(all), branches[0])
+	}
+	for i, sub := range all {
+		resolved, err := g.resolve(sub, fmt.Sprintf("%s/allOf/%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+		if resolved == nil {
+			return nil, nil
+		}
+		merge(merged, resolved)
+	}
+	return merged, nil
+}
+
+// merge adds the keywords of from to into, joining properties and required. Other
+// keywords in from replace those in into, which is close enough for the usual use of
+// allOf to extend a base object.
+func merge(into, from map[string]interface{}) {
+	for key, value := range from {
+		switch key {
+		case "properties":
+			properties, _ := into[key].(map[string]interface{})
+			properties = clone(properties)
+			added, _ := value.(map[string]interface{})
+			for name, property := range added {
+				properties[name] = property
+			}
+			into[key] = properties
+		case "required":
+			required, _ := into[key].([]interface{})
+			added, _ := value.([]interface{})
+			into[key] = append(slices.Clone(required), added...)
+		default:
+			into[key] = value
+		}
+	}
+}
+
+func clone(m map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		clone[key] = value
+	}
+	return clone
+}
+
+// schemaType picks one of the schema's types, guessing it from the keywords when unset
+func schemaType(r *rand.Rand, s map[string]interface{}) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		// Prefer a type other than null so optional values still carry data
+		var types []string
+		for _, name := range t {
+			if name, ok := name.(string); ok && name != "null" {
+				types = append(types, name)
+			}
+		}
+		if len(types) == 0 {
+			return "null"
+		}
+		return types[r.Intn(len(types))]
+	}
+
+	has := func(keys ...string) bool {
+		return slices.ContainsFunc(keys, func(key string) bool { _, ok := s[key]; return ok })
+	}
+	switch {
+	case has("properties", "required", "additionalProperties", "minProperties"):
+		return "object"
+	case has("items", "prefixItems", "minItems", "maxItems"):
+		return "array"
+	case has("minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf"):
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+func (g *jsonSchemaGenerator) objectValue(
+	r *rand.Rand, s map[string]interface{}, path string, depth int,
+) (interface{}, error) {
+	properties, _ := s["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	requiredList, _ := s["required"].([]interface{})
+	for _, name := range requiredList {
+		if name, ok := name.(string); ok {
+			required[name] = true
+		}
+	}
+
+	// Walk the properties in a fixed order so a seed always gives the same payload
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	for name := range required {
+		if _, ok := properties[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	minProperties := intKeyword(s, "minProperties", 0)
+	object := map[string]interface{}{}
+	var skipped []string
+	for _, name := range names {
+		if !required[name] && (depth >= optionalDepth || r.Intn(2) == 0) {
+			skipped = append(skipped, name)
+			continue
+		}
+		if err := g.property(r, s, object, name, path, depth); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range skipped {
+		if len(object) >= minProperties {
+			break
+		}
+		if err := g.property(r, s, object, name, path, depth); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; len(object) < minProperties; i++ {
+		name := fmt.Sprintf("extra%d", i)
+		if _, ok := object[name]; ok {
+			continue
+		}
+		if err := g.property(r, s, object, name, path, depth); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+// property generates the value of name from its own schema, additionalProperties for
+// names the schema does not list, or any value when neither constrains it
+func (g *jsonSchemaGenerator) property(
+	r *rand.Rand, s, object map[string]interface{}, name, path string, depth int,
+) error {
+	properties, _ := s["properties"].(map[string]interface{})
+	schema, ok := properties[name]
+	if !ok {
+		schema, ok = s["additionalProperties"]
+		if !ok {
+			schema = true
+		}
+	}
+	value, err := g.value(r, schema, path+"/properties/"+name, depth+1)
+	if err != nil {
+		return err
+	}
+	object[name] = value
+	return nil
+}
+
+func (g *jsonSchemaGenerator) arrayValue(
+	r *rand.Rand, s map[string]interface{}, path string, depth int,
+) (interface{}, error) {
+	// Tuples are prefixItems since draft 2020-12 and an items array before
+	prefix, _ := s["prefixItems"].([]interface{})
+	items := s["items"]
+	if tuple, ok := items.([]interface{}); ok {
+		prefix = tuple
+		items = s["additionalItems"]
+	}
+	if items == nil {
+		items = true
+	}
+
+	minItems := intKeyword(s, "minItems", 0)
+	maxItems := intKeyword(s, "maxItems", minItems+maxRepeat)
+	if depth >= optionalDepth {
+		maxItems = min(maxItems, minItems)
+	}
+	if minItems > maxItems {
+		return nil, fmt.Errorf("%w: %s needs more items than it allows", ErrCannotGenerate, path)
+	}
+	n := minItems + r.Intn(maxItems-minItems+1)
+	unique, _ := s["uniqueItems"].(bool)
+
+	array := make([]interface{}, 0, n)
+	seen := map[string]bool{}
+	for i, attempts := 0, 0; len(array) < n; attempts++ {
+		if attempts > 10*n+10 {
+			if len(array) >= minItems {
+				break
+			}
+			return nil, fmt.Errorf("%w: %s cannot hold %d unique items", ErrCannotGenerate, path, minItems)
+		}
+		schema, itemPath := items, fmt.Sprintf("%s/items", path)
+		if i < len(prefix) {
+			schema, itemPath = prefix[i], fmt.Sprintf("%s/prefixItems/%d", path, i)
+		}
+		value, err := g.value(r, schema, itemPath, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if unique {
+			key, _ := json.Marshal(value)
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+		}
+		array = append(array, value)
+		i++
+	}
+	return array, nil
+}
+
+// bounds returns the inclusive range of a numeric schema, accepting both the boolean
+// exclusive bounds of draft 4 and the numeric ones of later drafts
+func bounds(s map[string]interface{}, step float64) (float64, float64) {
+	lo, hasLo := s["minimum"].(float64)
+	hi, hasHi := s["maximum"].(float64)
+	if exclusive, ok := s["exclusiveMinimum"].(float64); ok && (!hasLo || exclusive >= lo) {
+		lo, hasLo = exclusive+step, true
+	} else if exclusive, _ := s["exclusiveMinimum"].(bool); exclusive && hasLo {
+		lo += step
+	}
+	if exclusive, ok := s["exclusiveMaximum"].(float64); ok && (!hasHi || exclusive <= hi) {
+		hi, hasHi = exclusive-step, true
+	} else if exclusive, _ := s["exclusiveMaximum"].(bool); exclusive && hasHi {
+		hi -= step
+	}
+	switch {
+	case !hasLo && !hasHi:
+		lo, hi = 0, 1000
+	case !hasLo:
+		lo = hi - 1000
+	case !hasHi:
+		hi = lo + 1000
+	}
+	return lo, hi
+}
+
+func integerValue(r *rand.Rand, s map[string]interface{}, path string) (interface{}, error) {
+	lo, hi := bounds(s, 1)
+	step := 1.0
+	if multiple, ok := s["multipleOf"].(float64); ok && multiple > 0 {
+		step = multiple
+	}
+	first, last := math.Ceil(lo/step), math.Floor(hi/step)
+	if first > last {
+		return nil, fmt.Errorf("%w: %s allows no integer", ErrCannotGenerate, path)
+	}
+	n := first + math.Floor(r.Float64()*(last-first+1))
+	return int64(math.Min(n, last) * step), nil
+}
+
+func numberValue(r *rand.Rand, s map[string]interface{}, path string) (interface{}, error) {
+	if multiple, ok := s["multipleOf"].(float64); ok && multiple > 0 {
+		lo, hi := bounds(s, multiple)
+		first, last := math.Ceil(lo/multiple), math.Floor(hi/multiple)
+		if first > last {
+			return nil, fmt.Errorf("%w: %s allows no multiple of %g", ErrCannotGenerate, path, multiple)
+		}
+		// Formatted with the decimals of multipleOf so 3 * 0.1 is sent as 0.3
+		value := (first + math.Floor(r.Float64()*(last-first+1))) * multiple
+		_, decimals, _ := strings.Cut(strconv.FormatFloat(multiple, 'f', -1, 64), ".")
+		return json.Number(strconv.FormatFloat(value, 'f', len(decimals), 64)), nil
+	}
+
+	// Exclusive bounds are nudged by a step small enough not to matter for test data
+	lo, hi := bounds(s, 1e-6)
+	if lo > hi {
+		return nil, fmt.Errorf("%w: %s allows no number", ErrCannotGenerate, path)
+	}
+	// Two decimals keep payloads readable, the rounded value is kept inside the bounds
+	value := math.Round((lo+r.Float64()*(hi-lo))*100) / 100
+	return math.Max(lo, math.Min(hi, value)), nil
+}
+
+func stringValue(r *rand.Rand, s map[string]interface{}, path string) (interface{}, error) {
+	minLength := intKeyword(s, "minLength", 0)
+	maxLength := intKeyword(s, "maxLength", minLength+12)
+	if minLength > maxLength {
+		return nil, fmt.Errorf("%w: %s needs a longer string than it allows", ErrCannotGenerate, path)
+	}
+
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s has an unsupported pattern: %w", ErrCannotGenerate, path, err)
+		}
+		re = re.Simplify()
+		// Matches of the pattern may fall outside the length bounds, retry a few times
+		for range 20 {
+			var b strings.Builder
+			if err := regexString(r, re, &b); err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrCannotGenerate, path, err)
+			}
+			if n := utf8.RuneCountInString(b.String()); n >= minLength && n <= maxLength {
+				return b.String(), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s pattern has no match within its length bounds", ErrCannotGenerate, path)
+	}
+
+	if format, ok := s["format"].(string); ok {
+		if value, ok := formatString(r, format); ok {
+			return value, nil
+		}
+	}
+
+	n := minLength + r.Intn(maxLength-minLength+1)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b), nil
+}
+
+// formatString generates a value of a well-known format, false for unknown formats which
+// validators ignore
+func formatString(r *rand.Rand, format string) (string, bool) {
+	// Timestamps fall between 2000 and 2030 so they look plausible
+	t := time.Unix(946684800+r.Int63n(946684800), 0).UTC()
+	word := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = letters[r.Intn(26)]
+		}
+		return string(b)
+	}
+
+	switch format {
+	case "date-time":
+		return t.Format(time.RFC3339), true
+	case "date":
+		return t.Format(time.DateOnly), true
+	case "time":
+		return t.Format("15:04:05Z"), true
+	case "email", "idn-email":
+		return word(8) + "@example.com", true
+	case "hostname", "idn-hostname":
+		return word(8) + ".example.com", true
+	case "uri", "iri", "url":
+		return "https://example.com/" + word(8), true
+	case "uri-reference", "iri-reference":
+		return "/" + word(8), true
+	case "uuid":
+		b := make([]byte, 16)
+		r.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case "ipv4":
+		return fmt.Sprintf("10.%d.%d.%d", r.Intn(256), r.Intn(256), 1+r.Intn(254)), true
+	case "ipv6":
+		return fmt.Sprintf("fd00::%x:%x", r.Intn(1<<16), r.Intn(1<<16)), true
+	case "duration":
+		return fmt.Sprintf("PT%dM", 1+r.Intn(120)), true
+	default:
+		return "", false
+	}
+}
+
+// regexString appends a random match of re to b
+func regexString(r *rand.Rand, re *syntax.Regexp, b *strings.Builder) error {
+	repeat := func(lo, hi int) error {
+		if hi < 0 {
+			hi = lo + maxRepeat
+		}
+		for range lo + r.Intn(hi-lo+1) {
+			if err := regexString(r, re.Sub[0], b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(r, re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(letters[r.Intn(len(letters))])
+	case syntax.OpCapture:
+		return regexString(r, re.Sub[0], b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := regexString(r, sub, b); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return regexString(r, re.Sub[r.Intn(len(re.Sub))], b)
+	case syntax.OpStar:
+		return repeat(0, -1)
+	case syntax.OpPlus:
+		return repeat(1, -1)
+	case syntax.OpQuest:
+		return repeat(0, 1)
+	case syntax.OpRepeat:
+		return repeat(re.Min, re.Max)
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+	default:
+		return fmt.Errorf("pattern %s matches nothing", re)
+	}
+	return nil
+}
+
+// classRune picks a rune from a character class, preferring printable ASCII so payloads
+// stay readable and never land on surrogates
+func classRune(r *rand.Rand, ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for c := max(ranges[i], ' '); c <= min(ranges[i+1], '~'); c++ {
+			printable = append(printable, c)
+		}
+	}
+	if len(printable) > 0 {
+		return printable[r.Intn(len(printable))]
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if c := ranges[i]; unicode.IsPrint(c) && utf8.ValidRune(c) {
+			return c
+		}
+	}
+	return ranges[0]
+}
+
+func intKeyword(s map[string]interface{}, key string, fallback int) int {
+	if n, ok := s[key].(float64); ok {
+		return int(n)
+	}
+	return fallback
+}
@@ -0,0 +1,172 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "status", "placed", "customer", "lines"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string", "format": "uuid"},
+		"status": {"enum": ["new", "paid", "shipped"]},
+		"placed": {"type": "string", "format": "date-time"},
+		"reference": {"type": "string", "pattern": "^ORD-[0-9]{6}$"},
+		"note": {"type": ["string", "null"], "maxLength": 20},
+		"customer": {"$ref": "#/$defs/customer"},
+		"lines": {
+			"type": "array", "minItems": 1, "maxItems": 5,
+			"items": {
+				"type": "object",
+				"required": ["sku", "quantity", "price"],
+				"properties": {
+					"sku": {"type": "string", "minLength": 3, "maxLength": 8},
+					"quantity": {"type": "integer", "minimum": 1, "exclusiveMaximum": 100},
+					"price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01}
+				}
+			}
+		}
+	},
+	"$defs": {
+		"customer": {
+			"allOf": [
+				{"type": "object", "required": ["email"], "properties": {"email": {"type": "string", "format": "email"}}},
+				{"required": ["tier"], "properties": {"tier": {"const": "gold"}}}
+			]
+		}
+	}
+}`
+
+func TestJSONSchemaPayloadsAreValid(t *testing.T) {
+	schemas := map[string]string{
+		"order": orderSchema,
+		"draft4 bounds": `{
+			"$schema": "http://json-schema.org/draft-04/schema#",
+			"type": "object",
+			"required": ["n", "x"],
+			"properties": {
+				"n": {"type": "integer", "minimum": 10, "maximum": 20, "exclusiveMinimum": true, "multipleOf": 5},
+				"x": {"type": "number", "maximum": -1, "exclusiveMaximum": true}
+			}
+		}`,
+		"tuple and unique": `{
+			"type": "array", "minItems": 3, "uniqueItems": true,
+			"prefixItems": [{"type": "boolean"}, {"type": "string", "format": "ipv4"}],
+			"items": {"type": "integer", "minimum": 0, "maximum": 1000}
+		}`,
+		"oneOf": `{"oneOf": [{"type": "string", "pattern": "^[a-f0-9]{8}(-[a-z]+)?$"}, {"type": "integer"}]}`,
+		"recursive": `{
+			"$ref": "#/definitions/node",
+			"definitions": {
+				"node": {
+					"type": "object",
+					"required": ["name"],
+					"properties": {
+						"name": {"type": "string"},
+						"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}
+					}
+				}
+			}
+		}`,
+		"min properties": `{"type": "object", "minProperties": 2, "additionalProperties": {"type": "integer"}}`,
+	}
+
+	for name, schema := range schemas {
+		validator, err := validate.Compile("json", schema)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		gen, err := JSONSchema([]byte(schema), 42)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		for i := 0; i < 200; i++ {
+			data, err := gen.Generate(i)
+			if !assert.NoError(t, err, name) {
+				break
+			}
+			assert.NoError(t, validator.Validate(data), "%s payload %d: %s", name, i, data)
+		}
+	}
+}
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestJSONSchemaFollowsKeywords(t *testing.T) {
+	gen, err := JSONSchema([]byte(orderSchema), 7)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	statuses := map[string]bool{}
+	references := 0
+	for i := 0; i < 100; i++ {
+		data, err := gen.Generate(i)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var order struct {
+			ID        string `json:"id"`
+			Status    string `json:"status"`
+			Reference *string
+			Customer  map[string]string
+		}
+		assert.NoError(t, json.Unmarshal(data, &order))
+		assert.Regexp(t, uuidV4, order.ID)
+		assert.Equal(t, "gold", order.Customer["tier"])
+		statuses[order.Status] = true
+		if order.Reference != nil {
+			references++
+		}
+	}
+	assert.Len(t, statuses, 3, "every enum value should turn up")
+	assert.True(t, references > 0 && references < 100, "optional properties should only sometimes be set")
+}
+
+func TestJSONSchemaIsReproducible(t *testing.T) {
+	a, err := JSONSchema([]byte(orderSchema), 1)
+	assert.NoError(t, err)
+	b, err := JSONSchema([]byte(orderSchema), 1)
+	assert.NoError(t, err)
+	c, err := JSONSchema([]byte(orderSchema), 2)
+	assert.NoError(t, err)
+
+	first, _ := a.Generate(5)
+	again, _ := b.Generate(5)
+	other, _ := c.Generate(5)
+	next, _ := a.Generate(6)
+	assert.Equal(t, string(first), string(again))
+	assert.NotEqual(t, string(first), string(other))
+	assert.NotEqual(t, string(first), string(next))
+}
+
+func TestJSONSchemaRejectsImpossibleSchemas(t *testing.T) {
+	schemas := map[string]string{
+		"false":         `false`,
+		"empty enum":    `{"enum": []}`,
+		"integer range": `{"type": "integer", "minimum": 1.2, "maximum": 1.8}`,
+		"string length": `{"type": "string", "minLength": 5, "maxLength": 2}`,
+		"remote ref":    `{"$ref": "https://example.com/schema.json"}`,
+		"missing ref":   `{"$ref": "#/$defs/none"}`,
+		"ref cycle":     `{"$ref": "#/$defs/a", "$defs": {"a": {"$ref": "#/$defs/a"}}}`,
+		"endless": `{"$ref": "#/$defs/a", "$defs": {
+			"a": {"type": "object", "required": ["a"], "properties": {"a": {"$ref": "#/$defs/a"}}}
+		}}`,
+		"unique booleans": `{"type": "array", "items": {"type": "boolean"}, "minItems": 3, "uniqueItems": true}`,
+	}
+	for name, schema := range schemas {
+		_, err := JSONSchema([]byte(schema), 0)
+		assert.True(t, errors.Is(err, ErrCannotGenerate), "%s: %v", name, err)
+	}
+
+	_, err := JSONSchema([]byte(`{`), 0)
+	assert.Error(t, err)
+}
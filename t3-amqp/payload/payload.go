@@ -0,0 +1,76 @@
+package payload
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Generator produces the i-th payload of a test
+type Generator interface {
+	Generate(i int) ([]byte, error)
+}
+
+// GeneratorFunc adapts a function to the Generator interface
+type GeneratorFunc func(i int) ([]byte, error)
+
+func (f GeneratorFunc) Generate(i int) ([]byte, error) {
+	return f(i)
+}
+
+// Source hands out payloads to publishers, it is safe for concurrent use
+type Source interface {
+	Next() ([]byte, error)
+}
+
+// NewSource returns a Source for gen. With poolSize > 0 the payloads are generated up
+// front and cycled, otherwise each call to Next generates a fresh payload.
+func NewSource(gen Generator, poolSize int) (Source, error) {
+	if poolSize > 0 {
+		return Pregenerate(gen, poolSize)
+	}
+	return &liveSource{gen: gen}, nil
+}
+
+type liveSource struct {
+	gen Generator
+	n   atomic.Int64
+}
+
+func (s *liveSource) Next() ([]byte, error) {
+	return s.gen.Generate(int(s.n.Add(1) - 1))
+}
+
+// Pool is a fixed set of pre-generated payloads handed out round robin so data
+// generation stays off the publishing hot path
+type Pool struct {
+	payloads [][]byte
+	next     atomic.Uint64
+}
+
+// Pregenerate builds a pool of n payloads from gen
+func Pregenerate(gen Generator, n int) (*Pool, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("payload pool size must be positive, got %d", n)
+	}
+
+	p := &Pool{payloads: make([][]byte, n)}
+	for i := 0; i < n; i++ {
+		data, err := gen.Generate(i)
+		if err != nil {
+			return nil, fmt.Errorf("error generating payload %d: %w", i, err)
+		}
+		p.payloads[i] = data
+	}
+	return p, nil
+}
+
+// Next returns the next payload in the cycle. Callers must not modify the returned slice.
+func (p *Pool) Next() ([]byte, error) {
+	i := p.next.Add(1) - 1
+	return p.payloads[i%uint64(len(p.payloads))], nil
+}
+
+// Len returns the number of distinct payloads in the pool
+func (p *Pool) Len() int {
+	return len(p.payloads)
+}
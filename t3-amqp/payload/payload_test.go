@@ -0,0 +1,52 @@
+package payload
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func counting(calls *int) Generator {
+	return GeneratorFunc(func(i int) ([]byte, error) {
+		*calls++
+		return []byte(strconv.Itoa(i)), nil
+	})
+}
+
+func TestPoolCyclesPregeneratedPayloads(t *testing.T) {
+	calls := 0
+	src, err := NewSource(counting(&calls), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls, "payloads should be generated up front")
+
+	var got []string
+	for i := 0; i < 5; i++ {
+		p, err := src.Next()
+		assert.NoError(t, err)
+		got = append(got, string(p))
+	}
+	assert.Equal(t, []string{"0", "1", "2", "0", "1"}, got)
+	assert.Equal(t, 3, calls, "publishing should not generate payloads")
+}
+
+func TestLiveSourceGeneratesOnDemand(t *testing.T) {
+	calls := 0
+	src, err := NewSource(counting(&calls), 0)
+	assert.NoError(t, err)
+	assert.Zero(t, calls)
+
+	p, err := src.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "0", string(p))
+	assert.Equal(t, 1, calls)
+}
+
+func TestPregenerateFails(t *testing.T) {
+	_, err := Pregenerate(GeneratorFunc(func(i int) ([]byte, error) { return nil, errors.New("boom") }), 2)
+	assert.Error(t, err)
+
+	_, err = Pregenerate(GeneratorFunc(func(i int) ([]byte, error) { return nil, nil }), 0)
+	assert.Error(t, err)
+}
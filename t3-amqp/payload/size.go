@@ -0,0 +1,186 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+const (
+	SizeFixed     = "fixed"
+	SizeUniform   = "uniform"
+	SizeLogNormal = "lognormal"
+)
+
+// ErrNoPaddingField is returned when a schema has no optional string property that
+// payloads can be padded with
+var ErrNoPaddingField = errors.New("schema has no optional string property to pad")
+
+// SizeSpec is the target payload size distribution of a load profile. Sizes are in bytes
+// of the encoded payload.
+type SizeSpec struct {
+	Kind string `json:"kind" mapstructure:"kind"`
+	// Bytes is the size of a fixed distribution
+	Bytes int `json:"bytes,omitempty" mapstructure:"bytes"`
+	// Min and Max bound a uniform distribution, Max also caps a log-normal one
+	Min int `json:"min,omitempty" mapstructure:"min"`
+	Max int `json:"max,omitempty" mapstructure:"max"`
+	// Median and Sigma shape a log-normal distribution
+	Median int     `json:"median,omitempty" mapstructure:"median"`
+	Sigma  float64 `json:"sigma,omitempty" mapstructure:"sigma"`
+}
+
+// Distribution picks the target size of a payload
+type Distribution interface {
+	Size(r *rand.Rand) int
+}
+
+type fixedSize int
+
+func (f fixedSize) Size(*rand.Rand) int {
+	return int(f)
+}
+
+type uniformSize struct {
+	min, max int
+}
+
+func (u uniformSize) Size(r *rand.Rand) int {
+	return u.min + r.Intn(u.max-u.min+1)
+}
+
+type logNormalSize struct {
+	median int
+	sigma  float64
+	max    int
+}
+
+func (l logNormalSize) Size(r *rand.Rand) int {
+	size := int(float64(l.median) * math.Exp(l.sigma*r.NormFloat64()))
+	if l.max > 0 && size > l.max {
+		size = l.max
+	}
+	return size
+}
+
+// Distribution validates the spec and returns the distribution it describes
+func (s SizeSpec) Distribution() (Distribution, error) {
+	switch s.Kind {
+	case SizeFixed:
+		if s.Bytes <= 0 {
+			return nil, fmt.Errorf("fixed size needs bytes > 0, got %d", s.Bytes)
+		}
+		return fixedSize(s.Bytes), nil
+	case SizeUniform:
+		if s.Min < 0 || s.Max <= 0 || s.Min > s.Max {
+			return nil, fmt.Errorf("uniform size needs 0 <= min <= max and max > 0, got %d..%d", s.Min, s.Max)
+		}
+		return uniformSize{min: s.Min, max: s.Max}, nil
+	case SizeLogNormal:
+		if s.Median <= 0 || s.Sigma <= 0 {
+			return nil, fmt.Errorf("lognormal size needs median > 0 and sigma > 0, got %d and %g", s.Median, s.Sigma)
+		}
+		return logNormalSize{median: s.Median, sigma: s.Sigma, max: s.Max}, nil
+	default:
+		return nil, fmt.Errorf("unknown size distribution %q, use fixed, uniform or lognormal", s.Kind)
+	}
+}
+
+// Padding names the optional string property used to grow payloads and the longest
+// value the schema allows for it, 0 means unlimited
+type Padding struct {
+	Field     string
+	MaxLength int
+}
+
+// PaddingFor finds an optional top level string property in a JSON schema so padding
+// keeps generated payloads valid
+func PaddingFor(schema []byte) (Padding, error) {
+	var doc struct {
+		Properties map[string]struct {
+			Type      interface{} `json:"type"`
+			MaxLength *int        `json:"maxLength"`
+			Pattern   string      `json:"pattern"`
+			Enum      []any       `json:"enum"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return Padding{}, fmt.Errorf("error parsing schema: %w", err)
+	}
+
+	required := make(map[string]bool, len(doc.Required))
+	for _, name := range doc.Required {
+		required[name] = true
+	}
+
+	// Pick the first suitable property by name so the choice is stable
+	var best *Padding
+	for name, prop := range doc.Properties {
+		if required[name] || prop.Type != "string" || prop.Pattern != "" || len(prop.Enum) > 0 {
+			continue
+		}
+		if best != nil && best.Field < name {
+			continue
+		}
+		p := Padding{Field: name}
+		if prop.MaxLength != nil {
+			p.MaxLength = *prop.MaxLength
+		}
+		best = &p
+	}
+	if best == nil {
+		return Padding{}, ErrNoPaddingField
+	}
+	return *best, nil
+}
+
+// Sized wraps gen so each payload is padded towards a size drawn from dist. Payloads must
+// be JSON objects. Sizes are reproducible for a given seed and payload index. Payloads
+// already at or above the target, or capped by the padding field's maxLength, are left
+// smaller than the target rather than made invalid.
+func Sized(gen Generator, dist Distribution, pad Padding, seed int64) Generator {
+	return GeneratorFunc(
+		func(i int) ([]byte, error) {
+			data, err := gen.Generate(i)
+			if err != nil {
+				return nil, err
+			}
+
+			target := dist.Size(rand.New(rand.NewSource(seed + int64(i))))
+			if len(data) >= target {
+				return data, nil
+			}
+
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(data, &obj); err != nil {
+				return nil, fmt.Errorf("payload %d is not a JSON object: %w", i, err)
+			}
+			delete(obj, pad.Field)
+			base, err := json.Marshal(obj)
+			if err != nil {
+				return nil, err
+			}
+
+			// Adding the field costs its quoted name, a colon, the value's quotes and a
+			// comma when the object already has members
+			overhead := len(pad.Field) + 6
+			if len(obj) == 0 {
+				overhead--
+			}
+			n := target - len(base) - overhead
+			if pad.MaxLength > 0 && n > pad.MaxLength {
+				n = pad.MaxLength
+			}
+			if n <= 0 {
+				return data, nil
+			}
+
+			obj[pad.Field], _ = json.Marshal(strings.Repeat("x", n))
+			return json.Marshal(obj)
+		},
+	)
+}
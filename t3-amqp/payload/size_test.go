@@ -0,0 +1,84 @@
+package payload
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedPayload(i int) ([]byte, error) {
+	return []byte(`{"id":1,"note":"short"}`), nil
+}
+
+func TestSizedPadsToFixedTarget(t *testing.T) {
+	dist, err := SizeSpec{Kind: SizeFixed, Bytes: 512}.Distribution()
+	assert.NoError(t, err)
+
+	gen := Sized(GeneratorFunc(fixedPayload), dist, Padding{Field: "padding"}, 1)
+	data, err := gen.Generate(0)
+	assert.NoError(t, err)
+	assert.Len(t, data, 512)
+}
+
+func TestSizedRespectsMaxLength(t *testing.T) {
+	dist, _ := SizeSpec{Kind: SizeFixed, Bytes: 4096}.Distribution()
+
+	gen := Sized(GeneratorFunc(fixedPayload), dist, Padding{Field: "padding", MaxLength: 100}, 1)
+	data, err := gen.Generate(0)
+	assert.NoError(t, err)
+	assert.Less(t, len(data), 200)
+}
+
+func TestSizedIsReproducible(t *testing.T) {
+	dist, _ := SizeSpec{Kind: SizeUniform, Min: 100, Max: 2000}.Distribution()
+	gen := Sized(GeneratorFunc(fixedPayload), dist, Padding{Field: "padding"}, 42)
+
+	a, _ := gen.Generate(7)
+	b, _ := gen.Generate(7)
+	assert.Equal(t, a, b)
+}
+
+func TestDistributions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	uniform, err := SizeSpec{Kind: SizeUniform, Min: 10, Max: 20}.Distribution()
+	assert.NoError(t, err)
+	logNormal, err := SizeSpec{Kind: SizeLogNormal, Median: 1000, Sigma: 1.5, Max: 5000}.Distribution()
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		size := uniform.Size(r)
+		assert.True(t, size >= 10 && size <= 20)
+		assert.LessOrEqual(t, logNormal.Size(r), 5000)
+	}
+
+	for _, spec := range []SizeSpec{
+		{Kind: SizeFixed},
+		{Kind: SizeUniform, Min: 20, Max: 10},
+		{Kind: SizeLogNormal, Median: 100},
+		{Kind: "pareto"},
+	} {
+		_, err := spec.Distribution()
+		assert.Error(t, err, spec.Kind)
+	}
+}
+
+func TestPaddingFor(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"status": {"type": "string", "enum": ["new", "paid"]},
+			"note": {"type": "string", "maxLength": 256},
+			"comment": {"type": "string"},
+			"count": {"type": "integer"}
+		},
+		"required": ["id"]
+	}`
+	pad, err := PaddingFor([]byte(schema))
+	assert.NoError(t, err)
+	assert.Equal(t, Padding{Field: "comment"}, pad)
+
+	_, err = PaddingFor([]byte(`{"properties": {"id": {"type": "string"}}, "required": ["id"]}`))
+	assert.ErrorIs(t, err, ErrNoPaddingField)
+}
@@ -0,0 +1,166 @@
+package quota
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRateExceeded = errors.New("request quota exceeded")
+	ErrSchemaQuota  = errors.New("schema quota exceeded")
+	ErrRunQuota     = errors.New("concurrent run quota exceeded")
+	ErrWriteRate    = errors.New("write rate exceeded")
+)
+
+const (
+	rateLimitWindow = time.Minute
+	anonymousKey    = "anonymous"
+	// usageIdleTimeout is how long the usage of a key without active runs is kept after
+	// its last request, so callers that went away do not pile up
+	usageIdleTimeout = 10 * time.Minute
+)
+
+// Config struct to hold quota limits, a zero value disables the limit
+type Config struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute" json:"requestsPerMinute"`
+	MaxSchemas        int `mapstructure:"max_schemas" json:"maxSchemas"`
+	MaxConcurrentRuns int `mapstructure:"max_concurrent_runs" json:"maxConcurrentRuns"`
+	// WritesPerSecond is the rate writes refill at, WriteBurst how many may arrive at once
+	WritesPerSecond float64 `mapstructure:"writes_per_second" json:"writesPerSecond"`
+	WriteBurst      int     `mapstructure:"write_burst" json:"writeBurst"`
+}
+
+// Usage reports what a single key has consumed
+type Usage struct {
+	Requests       int       `json:"requests"`
+	WindowStart    time.Time `json:"windowStart"`
+	Rejected       int       `json:"rejected"`
+	ActiveRuns     int       `json:"activeRuns"`
+	RejectedRuns   int       `json:"rejectedRuns"`
+	RejectedWrites int       `json:"rejectedWrites"`
+
+	seen time.Time
+}
+
+// Manager tracks usage per key and enforces the configured limits
+type Manager struct {
+	config Config
+	now    func() time.Time
+
+	mu     sync.Mutex
+	usage  map[string]*Usage
+	writes map[string]*bucket
+	// swept is when idle usage was last dropped
+	swept time.Time
+}
+
+// NewManager creates a quota manager for the given limits
+func NewManager(config Config) *Manager {
+	return &Manager{
+		config: config,
+		now:    time.Now,
+		usage:  map[string]*Usage{},
+		writes: map[string]*bucket{},
+	}
+}
+
+// Config returns the limits the manager enforces
+func (m *Manager) Config() Config {
+	return m.config
+}
+
+func (m *Manager) entry(key string) *Usage {
+	if key == "" {
+		key = anonymousKey
+	}
+	now := m.now()
+	if now.Sub(m.swept) >= rateLimitWindow {
+		m.dropIdleUsage(now)
+	}
+	u, ok := m.usage[key]
+	if !ok {
+		u = &Usage{WindowStart: now}
+		m.usage[key] = u
+	}
+	u.seen = now
+	return u
+}
+
+// dropIdleUsage forgets the keys without active runs that were not seen for
+// usageIdleTimeout
+func (m *Manager) dropIdleUsage(now time.Time) {
+	m.swept = now
+	for key, u := range m.usage {
+		if u.ActiveRuns == 0 && now.Sub(u.seen) >= usageIdleTimeout {
+			delete(m.usage, key)
+		}
+	}
+}
+
+// AllowRequest counts a request against key's per-minute quota. When the quota is
+// exhausted it returns ErrRateExceeded and how long until the window resets.
+func (m *Manager) AllowRequest(key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u := m.entry(key)
+	now := m.now()
+	if now.Sub(u.WindowStart) >= rateLimitWindow {
+		u.WindowStart = now
+		u.Requests = 0
+	}
+
+	if m.config.RequestsPerMinute > 0 && u.Requests >= m.config.RequestsPerMinute {
+		u.Rejected++
+		return u.WindowStart.Add(rateLimitWindow).Sub(now), ErrRateExceeded
+	}
+	u.Requests++
+	return 0, nil
+}
+
+// CheckSchemaCount returns ErrSchemaQuota when registering one more schema would
+// exceed the configured maximum
+func (m *Manager) CheckSchemaCount(current int) error {
+	if m.config.MaxSchemas > 0 && current >= m.config.MaxSchemas {
+		return ErrSchemaQuota
+	}
+	return nil
+}
+
+// AcquireRun reserves a concurrent run slot for key, callers must ReleaseRun when done
+func (m *Manager) AcquireRun(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u := m.entry(key)
+	if m.config.MaxConcurrentRuns > 0 && u.ActiveRuns >= m.config.MaxConcurrentRuns {
+		u.RejectedRuns++
+		return ErrRunQuota
+	}
+	u.ActiveRuns++
+	return nil
+}
+
+// ReleaseRun frees a run slot previously acquired with AcquireRun
+func (m *Manager) ReleaseRun(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u := m.entry(key)
+	if u.ActiveRuns > 0 {
+		u.ActiveRuns--
+	}
+}
+
+// Usage returns a snapshot of the usage of every key seen recently
+func (m *Manager) Usage() map[string]Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]Usage, len(m.usage))
+	for k, u := range m.usage {
+		snapshot[k] = *u
+	}
+	return snapshot
+}
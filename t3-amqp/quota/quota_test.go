@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowRequestEnforcesPerKeyLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManager(Config{RequestsPerMinute: 2})
+	m.now = func() time.Time { return now }
+
+	_, err := m.AllowRequest("team-a")
+	assert.NoError(t, err)
+	_, err = m.AllowRequest("team-a")
+	assert.NoError(t, err)
+
+	retry, err := m.AllowRequest("team-a")
+	assert.ErrorIs(t, err, ErrRateExceeded)
+	assert.Equal(t, time.Minute, retry)
+
+	// Another key has its own budget
+	_, err = m.AllowRequest("team-b")
+	assert.NoError(t, err)
+
+	// The window resets after a minute
+	now = now.Add(time.Minute)
+	_, err = m.AllowRequest("team-a")
+	assert.NoError(t, err)
+
+	usage := m.Usage()
+	assert.Equal(t, 1, usage["team-a"].Requests)
+	assert.Equal(t, 1, usage["team-a"].Rejected)
+}
+
+func TestCheckSchemaCount(t *testing.T) {
+	m := NewManager(Config{MaxSchemas: 3})
+	assert.NoError(t, m.CheckSchemaCount(2))
+	assert.ErrorIs(t, m.CheckSchemaCount(3), ErrSchemaQuota)
+
+	unlimited := NewManager(Config{})
+	assert.NoError(t, unlimited.CheckSchemaCount(1000))
+}
+
+func TestRunSlots(t *testing.T) {
+	m := NewManager(Config{MaxConcurrentRuns: 1})
+	assert.NoError(t, m.AcquireRun("team-a"))
+	assert.ErrorIs(t, m.AcquireRun("team-a"), ErrRunQuota)
+
+	m.ReleaseRun("team-a")
+	assert.NoError(t, m.AcquireRun("team-a"))
+	assert.Equal(t, 1, m.Usage()["team-a"].ActiveRuns)
+}
+
+func TestAllowWriteRefillsTokens(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManager(Config{WritesPerSecond: 2, WriteBurst: 2})
+	m.now = func() time.Time { return now }
+
+	_, err := m.AllowWrite("ci")
+	assert.NoError(t, err)
+	_, err = m.AllowWrite("ci")
+	assert.NoError(t, err)
+
+	retry, err := m.AllowWrite("ci")
+	assert.ErrorIs(t, err, ErrWriteRate)
+	assert.Equal(t, 500*time.Millisecond, retry)
+
+	// Another key has its own bucket
+	_, err = m.AllowWrite("team-b")
+	assert.NoError(t, err)
+
+	// Half a second refills one write
+	now = now.Add(500 * time.Millisecond)
+	_, err = m.AllowWrite("ci")
+	assert.NoError(t, err)
+	_, err = m.AllowWrite("ci")
+	assert.ErrorIs(t, err, ErrWriteRate)
+	assert.Equal(t, 2, m.Usage()["ci"].RejectedWrites)
+
+	unlimited := NewManager(Config{})
+	for i := 0; i < 100; i++ {
+		_, err = unlimited.AllowWrite("ci")
+		assert.NoError(t, err)
+	}
+}
+
+func TestIdleUsageIsDropped(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManager(Config{})
+	m.now = func() time.Time { return now }
+
+	_, _ = m.AllowRequest("gone")
+	assert.NoError(t, m.AcquireRun("running"))
+	now = now.Add(usageIdleTimeout)
+	_, _ = m.AllowRequest("team-a")
+
+	usage := m.Usage()
+	assert.NotContains(t, usage, "gone")
+	assert.Contains(t, usage, "running", "keys with active runs are kept")
+	assert.Contains(t, usage, "team-a")
+}
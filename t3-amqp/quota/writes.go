@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"time"
+)
+
+// maxIdleBuckets is how many write buckets are kept before full ones are dropped, a
+// full bucket behaves exactly like a missing one
+const maxIdleBuckets = 10000
+
+// bucket is a token bucket holding the writes a key may still make right away
+type bucket struct {
+	tokens float64
+	filled time.Time
+}
+
+// writeBurst is how many writes a fresh bucket holds, at least one
+func (m *Manager) writeBurst() float64 {
+	if m.config.WriteBurst > 0 {
+		return float64(m.config.WriteBurst)
+	}
+	return 1
+}
+
+// AllowWrite takes one write from key's token bucket. Buckets refill at WritesPerSecond
+// up to WriteBurst writes. When the bucket is empty it returns ErrWriteRate and how
+// long until the next write is allowed.
+func (m *Manager) AllowWrite(key string) (time.Duration, error) {
+	if m.config.WritesPerSecond <= 0 {
+		return 0, nil
+	}
+	if key == "" {
+		key = anonymousKey
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	burst := m.writeBurst()
+	b, ok := m.writes[key]
+	if !ok {
+		if len(m.writes) >= maxIdleBuckets {
+			m.dropFullBuckets(now, burst)
+		}
+		b = &bucket{tokens: burst, filled: now}
+		m.writes[key] = b
+	}
+	b.tokens = min(burst, b.tokens+now.Sub(b.filled).Seconds()*m.config.WritesPerSecond)
+	b.filled = now
+
+	if b.tokens < 1 {
+		m.entry(key).RejectedWrites++
+		wait := (1 - b.tokens) / m.config.WritesPerSecond
+		return time.Duration(wait * float64(time.Second)), ErrWriteRate
+	}
+	b.tokens--
+	return 0, nil
+}
+
+func (m *Manager) dropFullBuckets(now time.Time, burst float64) {
+	for key, b := range m.writes {
+		if b.tokens+now.Sub(b.filled).Seconds()*m.config.WritesPerSecond >= burst {
+			delete(m.writes, key)
+		}
+	}
+}
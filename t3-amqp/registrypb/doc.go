@@ -0,0 +1,5 @@
+// Package registrypb holds the protobuf messages and the gRPC service of the schema
+// registry, generated from registry.proto. Run go generate after changing it.
+package registrypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative registry.proto
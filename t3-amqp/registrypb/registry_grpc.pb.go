@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: registry.proto
+
+// The schema registry over gRPC, for services that prefer it to the REST API. Calls
+// carry the same credentials as REST requests in the x-api-key or authorization
+// metadata and choose a tenant with x-tenant.
+
+package registrypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Registry_RegisterSchema_FullMethodName  = "/t3.registry.v1.Registry/RegisterSchema"
+	Registry_GetSchema_FullMethodName       = "/t3.registry.v1.Registry/GetSchema"
+	Registry_ListSchemas_FullMethodName     = "/t3.registry.v1.Registry/ListSchemas"
+	Registry_ValidateMessage_FullMethodName = "/t3.registry.v1.Registry/ValidateMessage"
+	Registry_RunScenario_FullMethodName     = "/t3.registry.v1.Registry/RunScenario"
+)
+
+// RegistryClient is the client API for Registry service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RegistryClient interface {
+	// RegisterSchema registers a schema version, checked like POST /schema
+	RegisterSchema(ctx context.Context, in *RegisterSchemaRequest, opts ...grpc.CallOption) (*Schema, error)
+	// GetSchema returns a schema by id, or by name and type with the latest version
+	// unless one is given
+	GetSchema(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*Schema, error)
+	// ListSchemas streams the schemas one at a time, so large registries are never held
+	// in memory as a whole
+	ListSchemas(ctx context.Context, in *ListSchemasRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Schema], error)
+	// ValidateMessage validates a payload against a registered schema
+	ValidateMessage(ctx context.Context, in *ValidateMessageRequest, opts ...grpc.CallOption) (*ValidateMessageResponse, error)
+	// RunScenario starts a run of a stored scenario, waiting for its outcome on request
+	RunScenario(ctx context.Context, in *RunScenarioRequest, opts ...grpc.CallOption) (*TestRun, error)
+}
+
+type registryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRegistryClient(cc grpc.ClientConnInterface) RegistryClient {
+	return &registryClient{cc}
+}
+
+func (c *registryClient) RegisterSchema(ctx context.Context, in *RegisterSchemaRequest, opts ...grpc.CallOption) (*Schema, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Schema)
+	err := c.cc.Invoke(ctx, Registry_RegisterSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) GetSchema(ctx context.Context, in *GetSchemaRequest, opts ...grpc.CallOption) (*Schema, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Schema)
+	err := c.cc.Invoke(ctx, Registry_GetSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) ListSchemas(ctx context.Context, in *ListSchemasRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Schema], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Registry_ServiceDesc.Streams[0], Registry_ListSchemas_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListSchemasRequest, Schema]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Registry_ListSchemasClient = grpc.ServerStreamingClient[Schema]
+
+func (c *registryClient) ValidateMessage(ctx context.Context, in *ValidateMessageRequest, opts ...grpc.CallOption) (*ValidateMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateMessageResponse)
+	err := c.cc.Invoke(ctx, Registry_ValidateMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryClient) RunScenario(ctx context.Context, in *RunScenarioRequest, opts ...grpc.CallOption) (*TestRun, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TestRun)
+	err := c.cc.Invoke(ctx, Registry_RunScenario_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegistryServer is the server API for Registry service.
+// All implementations must embed UnimplementedRegistryServer
+// for forward compatibility.
+type RegistryServer interface {
+	// RegisterSchema registers a schema version, checked like POST /schema
+	RegisterSchema(context.Context, *RegisterSchemaRequest) (*Schema, error)
+	// GetSchema returns a schema by id, or by name and type with the latest version
+	// unless one is given
+	GetSchema(context.Context, *GetSchemaRequest) (*Schema, error)
+	// ListSchemas streams the schemas one at a time, so large registries are never held
+	// in memory as a whole
+	ListSchemas(*ListSchemasRequest, grpc.ServerStreamingServer[Schema]) error
+	// ValidateMessage validates a payload against a registered schema
+	ValidateMessage(context.Context, *ValidateMessageRequest) (*ValidateMessageResponse, error)
+	// RunScenario starts a run of a stored scenario, waiting for its outcome on request
+	RunScenario(context.Context, *RunScenarioRequest) (*TestRun, error)
+	mustEmbedUnimplementedRegistryServer()
+}
+
+// UnimplementedRegistryServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRegistryServer struct{}
+
+func (UnimplementedRegistryServer) RegisterSchema(context.Context, *RegisterSchemaRequest) (*Schema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterSchema not implemented")
+}
+func (UnimplementedRegistryServer) GetSchema(context.Context, *GetSchemaRequest) (*Schema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSchema not implemented")
+}
+func (UnimplementedRegistryServer) ListSchemas(*ListSchemasRequest, grpc.ServerStreamingServer[Schema]) error {
+	return status.Errorf(codes.Unimplemented, "method ListSchemas not implemented")
+}
+func (UnimplementedRegistryServer) ValidateMessage(context.Context, *ValidateMessageRequest) (*ValidateMessageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateMessage not implemented")
+}
+func (UnimplementedRegistryServer) RunScenario(context.Context, *RunScenarioRequest) (*TestRun, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunScenario not implemented")
+}
+func (UnimplementedRegistryServer) mustEmbedUnimplementedRegistryServer() {}
+func (UnimplementedRegistryServer) testEmbeddedByValue()                  {}
+
+// UnsafeRegistryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RegistryServer will
+// result in compilation errors.
+type UnsafeRegistryServer interface {
+	mustEmbedUnimplementedRegistryServer()
+}
+
+func RegisterRegistryServer(s grpc.ServiceRegistrar, srv RegistryServer) {
+	// If the following call pancis, it indicates UnimplementedRegistryServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Registry_ServiceDesc, srv)
+}
+
+func _Registry_RegisterSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).RegisterSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_RegisterSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).RegisterSchema(ctx, req.(*RegisterSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_GetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).GetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_GetSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).GetSchema(ctx, req.(*GetSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_ListSchemas_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListSchemasRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RegistryServer).ListSchemas(m, &grpc.GenericServerStream[ListSchemasRequest, Schema]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Registry_ListSchemasServer = grpc.ServerStreamingServer[Schema]
+
+func _Registry_ValidateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).ValidateMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_ValidateMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).ValidateMessage(ctx, req.(*ValidateMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registry_RunScenario_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunScenarioRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryServer).RunScenario(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registry_RunScenario_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryServer).RunScenario(ctx, req.(*RunScenarioRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Registry_ServiceDesc is the grpc.ServiceDesc for Registry service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Registry_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "t3.registry.v1.Registry",
+	HandlerType: (*RegistryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterSchema",
+			Handler:    _Registry_RegisterSchema_Handler,
+		},
+		{
+			MethodName: "GetSchema",
+			Handler:    _Registry_GetSchema_Handler,
+		},
+		{
+			MethodName: "ValidateMessage",
+			Handler:    _Registry_ValidateMessage_Handler,
+		},
+		{
+			MethodName: "RunScenario",
+			Handler:    _Registry_RunScenario_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListSchemas",
+			Handler:       _Registry_ListSchemas_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "registry.proto",
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"net/http"
+	"slices"
+	"t3-amqp/db"
+)
+
+type AliasRequest struct {
+	Alias string `json:"alias"`
+	Name  string `json:"name"`
+}
+
+// AliasesHandler manages schema aliases: GET lists them (optionally for one schema
+// name), POST creates one and DELETE removes the alias given in the query string.
+// Aliases belong to the tenant of the request.
+func AliasesHandler(pool *pgxpool.Pool) http.HandlerFunc {
+	postgresStore := db.NewPostgresStore(pool)
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant := requestTenant(r)
+		store := postgresStore.WithTenant(tenant)
+		switch r.Method {
+		case http.MethodGet:
+			aliases, err := db.ListAliases(pool, tenant, r.URL.Query().Get("name"))
+			if err != nil {
+				http.Error(w, "failed to retrieve aliases", http.StatusInternalServerError)
+				return
+			}
+			aliases = slices.DeleteFunc(
+				aliases, func(a db.Alias) bool { return !db.NamespaceAllowed(callerNamespaces(r), a.Alias) },
+			)
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(aliases)
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			var req AliasRequest
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			if req.Alias == "" || req.Name == "" {
+				http.Error(w, "alias and name are required", http.StatusBadRequest)
+				return
+			}
+			if !allowNamespace(w, r, req.Alias) {
+				return
+			}
+			if err := db.CheckNamespace(store, callerNamespaces(r), req.Name); err != nil {
+				writeError(w, r, err, "failed to check schema namespace")
+				return
+			}
+
+			alias, err := db.CreateAlias(pool, tenant, req.Alias, req.Name)
+			if err != nil {
+				writeError(w, r, err, "failed to create alias")
+				return
+			}
+			recordAudit(store, r, db.AuditActionAlias, 0, alias.Name)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(alias)
+			if err != nil {
+				return
+			}
+
+		case http.MethodDelete:
+			name := r.URL.Query().Get("alias")
+			if name == "" {
+				http.Error(w, "alias is required", http.StatusBadRequest)
+				return
+			}
+			if !allowNamespace(w, r, name) {
+				return
+			}
+
+			if err := db.DeleteAlias(pool, tenant, name); err != nil {
+				writeError(w, r, err, "failed to delete alias")
+				return
+			}
+			recordAudit(store, r, db.AuditActionUnalias, 0, name)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasesHandlerRejectsIncompleteRequests(t *testing.T) {
+	h := AliasesHandler(nil)
+
+	r := httptest.NewRequest(http.MethodPost, "/aliases", strings.NewReader(`{"alias": "orders-events"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/aliases", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/aliases", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
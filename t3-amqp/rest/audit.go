@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"t3-amqp/db"
+	"time"
+)
+
+// actorFor identifies who made a request for the audit trail, by the API key prefix or
+// token subject that authenticated it. Unauthenticated API keys are hashed so the
+// credential itself is never stored.
+func actorFor(r *http.Request) string {
+	if principal := Principal(r.Context()); principal != nil {
+		return principal.ID
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return keyID(key)
+	}
+	return "ip:" + sourceIP(r)
+}
+
+// recordAudit writes an audit entry without snapshots, for changes that are not made to
+// a schema version such as aliases
+func recordAudit(store db.SchemaStore, r *http.Request, action string, schemaID int, schemaName string) {
+	writeAudit(store, r, db.AuditEntry{Action: action, SchemaID: schemaID, SchemaName: schemaName})
+}
+
+// recordChange writes an audit entry for a change of a schema from before to after,
+// before is nil for inserts and after for deletes
+func recordChange(store db.SchemaStore, r *http.Request, action string, before, after *db.Schema) {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	writeAudit(
+		store, r, db.AuditEntry{
+			Action: action, SchemaID: subject.ID, SchemaName: subject.Name,
+			Before: db.Snapshot(before), After: db.Snapshot(after),
+		},
+	)
+}
+
+// writeAudit completes entry with the caller and writes it when store keeps an audit
+// trail, failures are logged rather than failing the request
+func writeAudit(store db.SchemaStore, r *http.Request, entry db.AuditEntry) {
+	auditor, ok := store.(db.AuditRecorder)
+	if !ok {
+		return
+	}
+	entry.Actor = actorFor(r)
+	entry.SourceIP = sourceIP(r)
+	entry.Tenant = requestTenant(r)
+	if err := auditor.RecordAudit(entry); err != nil {
+		log.Printf("failed to record audit entry: %v", err)
+	}
+}
+
+// firstSchema returns the first of schemas, nil when there are none
+func firstSchema(schemas []db.Schema) *db.Schema {
+	if len(schemas) == 0 {
+		return nil
+	}
+	return &schemas[0]
+}
+
+// insertedSchema is the schema params registered with id, as the audit trail records it
+func insertedSchema(id int, params db.QueryArgs) *db.Schema {
+	return &db.Schema{
+		ID: id, Name: params.Name, Type: params.Type, Version: params.Version, SchemaData: params.SchemaData,
+		Fingerprint: params.Fingerprint, Status: db.StatusActive,
+	}
+}
+
+// parseAuditFilter reads the audit filters and pagination from the query string, limited
+// to the caller's namespaces. The limit is the effective page size, the default when none
+// is given.
+func parseAuditFilter(r *http.Request) (db.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := db.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     q.Get("action"),
+		SchemaName: q.Get("name"),
+		SourceIP:   q.Get("ip"),
+		Namespaces: callerNamespaces(r),
+	}
+
+	var err error
+	if v := q.Get("schema_id"); v != "" {
+		if filter.SchemaID, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid schema_id: %w", err)
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid offset: %w", err)
+		}
+	}
+	filter.Limit, filter.Offset = db.AuditLimit(filter.Limit), max(filter.Offset, 0)
+	return filter, nil
+}
+
+// AuditHandler lists the audit entries of the request's tenant and the caller's namespaces
+// filtered by actor, action, schema name or id, source ip and time range. JSON results
+// carry the schema before and after each change, they are left out of CSV, which is
+// returned for format=csv or when the client accepts text/csv.
+func AuditHandler(pool *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		filter, err := parseAuditFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Tenant = requestTenant(r)
+
+		entries, total, err := db.ListAuditEntries(pool, filter)
+		if err != nil {
+			http.Error(w, "failed to retrieve audit entries", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		if r.URL.Query().Get("format") == "csv" || strings.Contains(r.Header.Get("Accept"), "text/csv") {
+			writeAuditCSV(w, entries)
+			return
+		}
+
+		links := pageLinks(r, filter.Limit, filter.Offset, total)
+		setLinkHeader(w, links)
+
+		response := map[string]interface{}{
+			"entries": entries,
+			"total":   total,
+			"limit":   filter.Limit,
+			"offset":  filter.Offset,
+			"_links":  links,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func writeAuditCSV(w http.ResponseWriter, entries []db.AuditEntry) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "actor", "action", "schema_id", "schema_name", "source_ip", "created"})
+	for _, e := range entries {
+		_ = cw.Write(
+			[]string{
+				strconv.Itoa(e.ID), e.Actor, e.Action, strconv.Itoa(e.SchemaID), e.SchemaName, e.SourceIP,
+				e.Created.Format(time.RFC3339),
+			},
+		)
+	}
+	cw.Flush()
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuditFilter(t *testing.T) {
+	from := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   string
+		want    db.AuditFilter
+		wantErr string
+	}{
+		{name: "defaults", query: "", want: db.AuditFilter{Limit: db.DefaultAuditLimit}},
+		{
+			name:  "filters",
+			query: "actor=key:abc&action=delete&name=orders&ip=10.0.0.1&schema_id=7&from=2026-01-02T03:04:05Z",
+			want: db.AuditFilter{
+				Actor: "key:abc", Action: "delete", SchemaName: "orders", SourceIP: "10.0.0.1", SchemaID: 7,
+				From: from, Limit: db.DefaultAuditLimit,
+			},
+		},
+		{name: "page", query: "limit=20&offset=40", want: db.AuditFilter{Limit: 20, Offset: 40}},
+		{name: "limit capped", query: "limit=5000", want: db.AuditFilter{Limit: 1000}},
+		{name: "zero limit", query: "limit=0&offset=-3", want: db.AuditFilter{Limit: db.DefaultAuditLimit}},
+		{name: "bad schema id", query: "schema_id=seven", wantErr: "invalid schema_id"},
+		{name: "bad from", query: "from=yesterday", wantErr: "invalid from"},
+		{name: "bad to", query: "to=2026-01-02", wantErr: "invalid to"},
+		{name: "bad limit", query: "limit=many", wantErr: "invalid limit"},
+		{name: "bad offset", query: "offset=x", wantErr: "invalid offset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseAuditFilter(httptest.NewRequest(http.MethodGet, "/audit?"+tt.query, nil))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, filter)
+			}
+		})
+	}
+}
+
+func TestParseAuditFilterNamespaces(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/audit?format=csv", nil)
+	principal := &auth.Principal{ID: "key:team-a", Namespaces: []string{"team-a"}}
+	filter, err := parseAuditFilter(r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"team-a"}, filter.Namespaces, "entries are limited to the caller's namespaces")
+	}
+}
+
+func TestWriteAuditCSV(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		entries []db.AuditEntry
+		want    string
+	}{
+		{name: "empty", want: "id,actor,action,schema_id,schema_name,source_ip,created\n"},
+		{
+			name: "entries",
+			entries: []db.AuditEntry{
+				{
+					ID: 1, Actor: "key:abc", Action: "create", SchemaID: 7, SchemaName: "orders",
+					SourceIP: "10.0.0.1", Created: created, After: &db.SchemaSnapshot{},
+				},
+				{ID: 2, Actor: `ip:"quoted", name`, Action: "delete", Created: created},
+			},
+			want: "id,actor,action,schema_id,schema_name,source_ip,created\n" +
+				"1,key:abc,create,7,orders,10.0.0.1,2026-01-02T03:04:05Z\n" +
+				`2,"ip:""quoted"", name",delete,0,,,2026-01-02T03:04:05Z` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			writeAuditCSV(rr, tt.entries)
+			assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename="audit.csv"`, rr.Header().Get("Content-Disposition"))
+			assert.Equal(t, tt.want, rr.Body.String())
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"t3-amqp/auth"
+	"t3-amqp/auth/oidc"
+	"t3-amqp/db"
+)
+
+type principalKey struct{}
+
+// Principal returns the caller the request was authenticated as, nil when authentication
+// is off
+func Principal(ctx context.Context) *auth.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*auth.Principal)
+	return principal
+}
+
+// callerNamespaces returns the namespaces the caller is restricted to, nil when it may
+// use all of them
+func callerNamespaces(r *http.Request) []string {
+	return principalNamespaces(r.Context())
+}
+
+// principalNamespaces returns the namespaces of the principal in ctx like callerNamespaces
+func principalNamespaces(ctx context.Context) []string {
+	if principal := Principal(ctx); principal != nil {
+		return principal.Namespaces
+	}
+	return nil
+}
+
+// scopedStore limits store to the tenant of the request and the namespaces of the caller
+func scopedStore(r *http.Request, store db.SchemaStore) db.SchemaStore {
+	return db.RestrictNamespaces(db.ForTenant(store, requestTenant(r)), callerNamespaces(r))
+}
+
+// allowNamespace answers 403 unless the caller may use the namespace of the schema name
+func allowNamespace(w http.ResponseWriter, r *http.Request, name string) bool {
+	if db.NamespaceAllowed(callerNamespaces(r), name) {
+		return true
+	}
+	writeError(w, r, fmt.Errorf("%s: %w", name, db.ErrNamespaceDenied), "")
+	return false
+}
+
+// presentedKey reads the key from X-API-Key or an Authorization bearer token
+func presentedKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// readOnlyRoutes only read the registry although they are called with POST
+var readOnlyRoutes = map[string]bool{
+	"/validate":                 true,
+	"/normalize":                true,
+	"POST /schema/{name}/match": true,
+	"/subjects/{subject}":       true,
+	"/encode":                   true,
+	"/decode":                   true,
+}
+
+// requiredScope is the scope a request needs: admin under /admin, read for reads and
+// read-write for everything that changes the registry
+func requiredScope(routes Router, r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/admin/") {
+		return auth.ScopeAdmin
+	}
+	if !isWrite(routes, r) {
+		return auth.ScopeRead
+	}
+	return auth.ScopeReadWrite
+}
+
+// isWrite reports whether the request may change the registry
+func isWrite(routes Router, r *http.Request) bool {
+	if isReadMethod(r.Method) {
+		return false
+	}
+	_, route := routes.Handler(r)
+	return !readOnlyRoutes[route]
+}
+
+// AuthMiddleware requires an API key or bearer token on every request once authentication
+// is enabled, answering 401 without valid credentials and 403 when their scope does not
+// cover the request.
+// Health checks, metrics, the OpenAPI document and the UI stay public, /health/details
+// needs read scope.
+func AuthMiddleware(a *auth.Authenticator, routes Router, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() || isProbe(r.URL.Path) || r.URL.Path == "/metrics" ||
+			r.URL.Path == "/openapi.json" || isUI(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := a.Authenticate(r.Context(), presentedKey(r))
+		switch {
+		case errors.Is(err, auth.ErrMissingKey), errors.Is(err, auth.ErrInvalidKey),
+			errors.Is(err, oidc.ErrInvalidToken):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="t3"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		case errors.Is(err, oidc.ErrKeysUnavailable):
+			log.Printf("Failed to verify bearer token: %v", err)
+			writeStatusProblem(w, r, http.StatusServiceUnavailable, oidc.ErrKeysUnavailable.Error())
+			return
+		case err != nil:
+			writeError(w, r, err, "failed to check api key")
+			return
+		}
+		if required := requiredScope(routes, r); !auth.Allows(principal.Scope, required) {
+			http.Error(w, "credentials do not allow "+required+" requests", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	}
+}
+
+type APIKeyRequest struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+	// Namespaces limits the key to schemas in these namespaces, empty allows all
+	Namespaces []string `json:"namespaces"`
+	// Tenant binds the key to a tenant, empty lets it choose one with X-Tenant
+	Tenant string `json:"tenant"`
+}
+
+// CreatedAPIKey is answered once when a key is created, Key is not retrievable later
+type CreatedAPIKey struct {
+	db.APIKey
+	Key string `json:"key"`
+}
+
+// manages reports whether a caller bound to tenant and limited to restricted namespaces
+// may see and revoke key, which it may when the key is bound to the same tenant and
+// limited to some of the namespaces
+func manages(tenant string, restricted []string, key db.APIKey) bool {
+	if tenant != "" && key.Tenant != tenant {
+		return false
+	}
+	if len(restricted) == 0 {
+		return true
+	}
+	if len(key.Namespaces) == 0 {
+		return false
+	}
+	for _, namespace := range key.Namespaces {
+		if !slices.Contains(restricted, namespace) {
+			return false
+		}
+	}
+	return true
+}
+
+// APIKeysHandler manages API keys: GET lists them without secrets, POST creates one and
+// returns its key once and DELETE revokes the key with the id in the query string.
+// Callers limited to some namespaces or bound to a tenant only manage keys limited to
+// those namespaces and bound to that tenant.
+func APIKeysHandler(keys db.APIKeyStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		restricted, tenant := callerNamespaces(r), callerTenant(r)
+		switch r.Method {
+		case http.MethodGet:
+			list, err := keys.APIKeys()
+			if err != nil {
+				writeError(w, r, err, "failed to retrieve api keys")
+				return
+			}
+			list = slices.DeleteFunc(list, func(key db.APIKey) bool { return !manages(tenant, restricted, key) })
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(list)
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			var req APIKeyRequest
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			if req.Name == "" {
+				http.Error(w, "name is required", http.StatusBadRequest)
+				return
+			}
+			if req.Scope == "" {
+				req.Scope = auth.ScopeRead
+			}
+			for _, namespace := range req.Namespaces {
+				if namespace == "" || db.Namespace(namespace) != namespace {
+					http.Error(w, "namespaces must be schema name prefixes without dots", http.StatusBadRequest)
+					return
+				}
+			}
+			// Keys limited to some namespaces can only hand out keys limited to those too
+			if len(restricted) > 0 && len(req.Namespaces) == 0 {
+				req.Namespaces = restricted
+			}
+			if !manages("", restricted, db.APIKey{Namespaces: req.Namespaces}) {
+				writeError(w, r, db.ErrNamespaceDenied, "")
+				return
+			}
+			// Keys bound to a tenant can only hand out keys bound to it too
+			if tenant != "" && req.Tenant == "" {
+				req.Tenant = tenant
+			}
+			if tenant != "" && req.Tenant != tenant {
+				writeError(w, r, db.ErrTenantDenied, "")
+				return
+			}
+			if req.Tenant != "" && !db.ValidTenantID(req.Tenant) {
+				writeError(w, r, db.ErrInvalidTenant, "")
+				return
+			}
+
+			secret, record, err := auth.Generate(req.Name, req.Scope)
+			if errors.Is(err, auth.ErrInvalidScope) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				http.Error(w, "failed to generate api key", http.StatusInternalServerError)
+				return
+			}
+			record.Namespaces, record.Tenant = req.Namespaces, req.Tenant
+			created, err := keys.CreateAPIKey(record)
+			if err != nil {
+				writeError(w, r, err, "failed to create api key")
+				return
+			}
+			log.Printf("Created api key %s (%s) with scope %s", created.Prefix, created.Name, created.Scope)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(CreatedAPIKey{APIKey: *created, Key: secret})
+			if err != nil {
+				return
+			}
+
+		case http.MethodDelete:
+			id, err := strconv.Atoi(r.URL.Query().Get("id"))
+			if err != nil {
+				http.Error(w, "id is required", http.StatusBadRequest)
+				return
+			}
+			if len(restricted) > 0 || tenant != "" {
+				list, err := keys.APIKeys()
+				if err != nil {
+					writeError(w, r, err, "failed to retrieve api keys")
+					return
+				}
+				i := slices.IndexFunc(list, func(key db.APIKey) bool { return key.ID == id })
+				if i < 0 || !manages(tenant, restricted, list[i]) {
+					writeError(w, r, db.ErrAPIKeyNotFound, "")
+					return
+				}
+			}
+			if err := keys.RevokeAPIKey(id); err != nil {
+				writeError(w, r, err, "failed to revoke api key")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
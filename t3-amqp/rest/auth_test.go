@@ -0,0 +1,138 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	mux.HandleFunc("/schema", ok)
+	mux.HandleFunc("/validate", ok)
+	mux.HandleFunc("/encode", ok)
+	mux.HandleFunc("/decode", ok)
+	mux.HandleFunc("/health", ok)
+	mux.HandleFunc("/health/details", ok)
+	mux.HandleFunc("/admin/keys", rest.APIKeysHandler(store))
+	handler := rest.AuthMiddleware(auth.NewAuthenticator(true, "bootstrap-secret", store, nil), mux, mux)
+
+	serve := func(method, path, key string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(http.MethodGet, "/schema", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, `Bearer realm="t3"`, rr.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/schema", "t3_abc_def", nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/health/details", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/healthcheck-admin", "", nil).Code)
+
+	rr = serve(http.MethodPost, "/admin/keys", "bootstrap-secret", []byte(`{"name":"dashboard"}`))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created rest.CreatedAPIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Equal(t, auth.ScopeRead, created.Scope)
+	assert.NotContains(t, rr.Body.String(), "secretHash")
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/schema", created.Key, nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/health/details", created.Key, nil).Code)
+	for _, path := range []string{"/validate", "/encode", "/decode"} {
+		assert.Equal(t, http.StatusOK, serve(http.MethodPost, path, created.Key, nil).Code, path)
+	}
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/schema", created.Key, nil).Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/admin/keys", created.Key, nil).Code)
+
+	rr = serve(http.MethodPost, "/admin/keys", "bootstrap-secret", []byte(`{"name":"ci","scope":"owner"}`))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve(http.MethodDelete, "/admin/keys?id="+strconv.Itoa(created.ID), "bootstrap-secret", nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/schema", created.Key, nil).Code)
+}
+
+func TestAuthMiddlewareDisabled(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := rest.AuthMiddleware(auth.NewAuthenticator(false, "", db.NewMemoryStore(), nil), http.NewServeMux(), ok)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestAuthMiddlewareNamespaces(t *testing.T) {
+	store := db.NewMemoryStore()
+	other, err := store.Insert(db.QueryArgs{Name: "team-b.orders", Type: "json", Version: "1.0.0", SchemaData: `{}`})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	mux.HandleFunc("/admin/keys", rest.APIKeysHandler(store))
+	handler := rest.AuthMiddleware(auth.NewAuthenticator(true, "bootstrap-secret", store, nil), mux, mux)
+
+	serve := func(method, path, key string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(
+		http.MethodPost, "/admin/keys", "bootstrap-secret",
+		rest.APIKeyRequest{Name: "team-a", Scope: auth.ScopeAdmin, Namespaces: []string{"team-a"}},
+	)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var teamA rest.CreatedAPIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&teamA))
+	assert.Equal(t, []string{"team-a"}, teamA.Namespaces)
+
+	schema := rest.SchemaRequest{Name: "team-a.orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/schema", teamA.Key, schema).Code)
+	schema.Name = "team-b.invoices"
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/schema", teamA.Key, schema).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/schema/"+strconv.Itoa(other), teamA.Key, nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/schema/"+strconv.Itoa(other), "bootstrap-secret", nil).Code)
+
+	// Restricted admins only hand out and see keys within their namespaces
+	rr = serve(http.MethodPost, "/admin/keys", teamA.Key, rest.APIKeyRequest{Name: "ci", Namespaces: []string{"team-b"}})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = serve(http.MethodPost, "/admin/keys", teamA.Key, rest.APIKeyRequest{Name: "ci"})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var ci rest.CreatedAPIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&ci))
+	assert.Equal(t, []string{"team-a"}, ci.Namespaces)
+
+	rr = serve(http.MethodPost, "/admin/keys", "bootstrap-secret", rest.APIKeyRequest{Name: "all"})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr = serve(http.MethodGet, "/admin/keys", teamA.Key, nil)
+	var keys []db.APIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&keys))
+	assert.Len(t, keys, 2)
+}
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"t3-amqp/db"
+)
+
+// schemaName is the pattern of schema names: letters, digits, dots, dashes and
+// underscores, starting with a letter or digit
+var schemaName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,254}$`)
+
+// FieldError is one rule a field of a request body breaks
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// RequestError lists every field of a request body that breaks its binding rules, it is
+// answered with 400, or 413 when a schema is too large, and the fields in the errors
+// member of the problem
+type RequestError struct {
+	Fields []FieldError
+}
+
+func (e *RequestError) status() int {
+	for _, field := range e.Fields {
+		if field.Rule == "schema" {
+			return http.StatusRequestEntityTooLarge
+		}
+	}
+	return http.StatusBadRequest
+}
+
+func (e *RequestError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+// checkBinding checks the string fields of the struct v points to against the rules of
+// their binding tags and returns a *RequestError listing the broken ones. The rules are
+// required, name for schema names, version for semantic versions and schema for
+// schema_data no larger than db.MaxSchemaBytes. Fields are named by their json tag.
+func checkBinding(v interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var problems []FieldError
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("binding")
+		if tag == "" || field.Type.Kind() != reflect.String {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		s := value.Field(i).String()
+		for _, rule := range strings.Split(tag, ",") {
+			if message := bindingProblem(rule, name, s); message != "" {
+				problems = append(problems, FieldError{Field: name, Rule: rule, Message: message})
+				break
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &RequestError{Fields: problems}
+	}
+	return nil
+}
+
+// bindingProblem describes how s breaks rule, empty when it does not. Rules other than
+// required pass empty values.
+func bindingProblem(rule, field, s string) string {
+	if rule == "required" {
+		if strings.TrimSpace(s) == "" {
+			return field + " is required"
+		}
+		return ""
+	}
+	if s == "" {
+		return ""
+	}
+	switch rule {
+	case "name":
+		if !schemaName.MatchString(s) {
+			return fmt.Sprintf(
+				"%s must be at most 255 letters, digits, dots, dashes and underscores starting with a letter or digit",
+				field,
+			)
+		}
+	case "version":
+		if !db.ValidVersion(s) {
+			return fmt.Sprintf("%s %q is not a semantic version such as 1.0.0", field, s)
+		}
+	case "schema":
+		if limit := db.MaxSchemaBytes(); int64(len(s)) > limit {
+			return fmt.Sprintf(
+				"%s is %d bytes, over the %d byte limit; use POST /schema/upload for large schema documents",
+				field, len(s), limit,
+			)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaRequestBinding(t *testing.T) {
+	store := db.NewMemoryStore()
+	handler := rest.PostSchemaHandler(store)
+	post := func(body string) (*httptest.ResponseRecorder, rest.Problem) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader(body)))
+		var problem rest.Problem
+		_ = json.NewDecoder(rr.Body).Decode(&problem)
+		return rr, problem
+	}
+
+	rr, problem := post(`{"name":" ","type":"json","version":"one","schemaData":""}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.Equal(
+		t, []rest.FieldError{
+			{Field: "name", Rule: "required", Message: "name is required"},
+			{Field: "version", Rule: "version", Message: `version "one" is not a semantic version such as 1.0.0`},
+			{Field: "schemaData", Rule: "required", Message: "schemaData is required"},
+		}, problem.Errors,
+	)
+	assert.Contains(t, problem.Detail, "name is required")
+
+	rr, problem = post(`{"name":"orders/created","type":"json","version":"1.0.0","schemaData":"{}"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	if assert.Len(t, problem.Errors, 1) {
+		assert.Equal(t, "name", problem.Errors[0].Rule)
+	}
+
+	large := `{"name":"orders","type":"json","version":"1.0.0","schemaData":"` +
+		strings.Repeat(" ", int(db.MaxSchemaBytes())) + `{}"}`
+	rr, problem = post(large)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	if assert.Len(t, problem.Errors, 1) {
+		assert.Equal(t, "schema", problem.Errors[0].Rule)
+		assert.Contains(t, problem.Errors[0].Message, "POST /schema/upload")
+	}
+
+	rr, _ = post(`{"name":"com.example_orders-v2","type":"json","version":"v2.1","schemaData":"{}"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count, "only the valid request is registered")
+}
@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"net/http"
+	"slices"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+	"time"
+)
+
+type BulkDeleteResponse struct {
+	DryRun   bool        `json:"dryRun"`
+	Count    int         `json:"count"`
+	Confirm  string      `json:"confirm,omitempty"`
+	Schemas  []db.Schema `json:"schemas,omitempty"`
+	Deleted  int64       `json:"deleted,omitempty"`
+	Guidance string      `json:"guidance,omitempty"`
+}
+
+// SchemasEndpointHandler dispatches /schemas by method, bulk deletes run directly on pool
+// and are not available without one
+func SchemasEndpointHandler(store db.SchemaStore, pool *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		switch r.Method {
+		case http.MethodGet:
+			GetAllSchemasHandler(store).ServeHTTP(w, r)
+		case http.MethodDelete:
+			if pool == nil {
+				http.Error(w, "bulk deletes are not supported by this store", http.StatusNotImplemented)
+				return
+			}
+			BulkDeleteSchemasHandler(pool).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// parseBulkFilter reads name_prefix, type, status and older_than (a duration like 720h
+// or an RFC 3339 timestamp) from the query string
+func parseBulkFilter(r *http.Request) (db.BulkFilter, error) {
+	q := r.URL.Query()
+	filter := db.BulkFilter{NamePrefix: q.Get("name_prefix"), Type: q.Get("type"), Status: q.Get("status")}
+	if filter.Status != "" && !db.ValidStatus(filter.Status) {
+		return filter, fmt.Errorf("invalid status %q, use active, deprecated or retired", filter.Status)
+	}
+
+	if v := q.Get("older_than"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			filter.OlderThan = time.Now().UTC().Add(-d)
+		} else if ts, err := time.Parse(time.RFC3339, v); err == nil {
+			filter.OlderThan = ts
+		} else {
+			return filter, fmt.Errorf("invalid older_than %q, use a duration like 720h or an RFC 3339 time", v)
+		}
+	}
+	return filter, nil
+}
+
+// confirmToken fingerprints the exact set of schemas a dry run matched
+func confirmToken(schemas []db.Schema) string {
+	h := sha256.New()
+	for _, s := range schemas {
+		fmt.Fprintf(h, "%d:%s;", s.ID, s.Modified.UTC().Format(time.RFC3339Nano))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// BulkDeleteSchemasHandler deletes every schema matching the filter. A dry run
+// (dry_run=true, the default) lists the affected schemas and returns a confirm token;
+// the real delete needs dry_run=false and that token, and is refused if the matched set
+// changed since the dry run or with 409 when other schemas still reference one of them.
+func BulkDeleteSchemasHandler(pool *pgxpool.Pool) http.HandlerFunc {
+	postgresStore := db.NewPostgresStore(pool)
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseBulkFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Tenant = requestTenant(r)
+		store := postgresStore.WithTenant(filter.Tenant)
+
+		dryRun := true
+		if v := r.URL.Query().Get("dry_run"); v != "" {
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
+
+		schemas, err := db.FindSchemasForBulk(pool, filter)
+		if errors.Is(err, db.ErrEmptyFilter) {
+			http.Error(w, "at least one of name_prefix, type, status or older_than is required", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to find schemas", http.StatusInternalServerError)
+			return
+		}
+		// Callers limited to some namespaces only ever delete their own schemas
+		schemas = slices.DeleteFunc(
+			schemas, func(s db.Schema) bool { return !db.NamespaceAllowed(callerNamespaces(r), s.Name) },
+		)
+		token := confirmToken(schemas)
+
+		response := BulkDeleteResponse{DryRun: dryRun, Count: len(schemas)}
+		if dryRun {
+			response.Schemas = schemas
+			response.Confirm = token
+			response.Guidance = "repeat the request with dry_run=false&confirm=" + token + " to delete"
+		} else {
+			if r.URL.Query().Get("confirm") != token {
+				http.Error(
+					w, "confirm token missing or stale, run with dry_run=true first", http.StatusPreconditionFailed,
+				)
+				return
+			}
+
+			ids := make([]int, len(schemas))
+			for i, s := range schemas {
+				ids[i] = s.ID
+			}
+			response.Deleted, err = db.DeleteSchemasByIds(pool, ids)
+			if err != nil {
+				writeError(w, r, err, "failed to delete schemas")
+				return
+			}
+			for _, s := range schemas {
+				validate.DefaultCache.InvalidateID(s.ID)
+				recordChange(store, r, db.AuditActionDelete, &s, nil)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBulkFilter(t *testing.T) {
+	r := httptest.NewRequest(http.MethodDelete, "/schemas?name_prefix=test_&older_than=24h", nil)
+	filter, err := parseBulkFilter(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "test_", filter.NamePrefix)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), filter.OlderThan, time.Minute)
+
+	r = httptest.NewRequest(http.MethodDelete, "/schemas?older_than=2024-01-01T00:00:00Z", nil)
+	filter, err = parseBulkFilter(r)
+	assert.NoError(t, err)
+	assert.Equal(t, 2024, filter.OlderThan.Year())
+
+	r = httptest.NewRequest(http.MethodDelete, "/schemas?older_than=yesterday", nil)
+	_, err = parseBulkFilter(r)
+	assert.Error(t, err)
+
+	r = httptest.NewRequest(http.MethodDelete, "/schemas?status=retired", nil)
+	filter, err = parseBulkFilter(r)
+	assert.NoError(t, err)
+	assert.Equal(t, db.StatusRetired, filter.Status)
+
+	r = httptest.NewRequest(http.MethodDelete, "/schemas?status=gone", nil)
+	_, err = parseBulkFilter(r)
+	assert.Error(t, err)
+}
+
+func TestConfirmTokenTracksMatchedSet(t *testing.T) {
+	a := []db.Schema{{ID: 1}, {ID: 2}}
+	b := []db.Schema{{ID: 1}, {ID: 2}, {ID: 3}}
+	assert.Equal(t, confirmToken(a), confirmToken([]db.Schema{{ID: 1}, {ID: 2}}))
+	assert.NotEqual(t, confirmToken(a), confirmToken(b))
+}
@@ -0,0 +1,321 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/validate"
+	"time"
+)
+
+// SchemaBundle is the document exchanged by the import and export endpoints, in JSON or
+// YAML. Exported is informational and ignored on import.
+type SchemaBundle struct {
+	Exported *time.Time      `json:"exported,omitempty"`
+	Schemas  []SchemaRequest `json:"schemas"`
+}
+
+// Outcomes of importing one bundle entry
+const (
+	ImportCreated   = "created"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportInvalid   = "invalid"
+)
+
+type ImportResult struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Action  string `json:"action"`
+	ID      int    `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun    bool           `json:"dryRun"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Invalid   int            `json:"invalid"`
+	Results   []ImportResult `json:"results"`
+}
+
+// planImport decides what importing entry would do without writing anything. An
+// existing version with the same schema_data is left alone, a different one is updated.
+func planImport(store db.SchemaStore, entry SchemaRequest, params *db.QueryArgs) (ImportResult, error) {
+	result := ImportResult{Name: entry.Name, Type: entry.Type, Version: entry.Version, Action: ImportInvalid}
+	if err := checkBinding(entry); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	*params = db.QueryArgs{Name: entry.Name, Type: entry.Type, Version: entry.Version, SchemaData: entry.SchemaData}
+	if err := prepareSchema(store, params); err != nil {
+		var schemaErr *validate.SchemaError
+		if !errors.As(err, &schemaErr) && !errors.Is(err, errDuplicateSchema) {
+			return result, err
+		}
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	existing, err := store.Filter(db.QueryArgs{Name: entry.Name, Type: entry.Type, Version: entry.Version})
+	if err != nil {
+		return result, err
+	}
+	switch {
+	case len(existing) == 0:
+		result.Action = ImportCreated
+	case existing[0].SchemaData == entry.SchemaData:
+		result.Action, result.ID = ImportUnchanged, existing[0].ID
+	default:
+		result.Action, result.ID = ImportUpdated, existing[0].ID
+	}
+	return result, nil
+}
+
+// ImportSchemasHandler upserts every schema of a JSON or YAML bundle. All entries are
+// checked first, and if any is invalid nothing is written and the problems are answered
+// with 422. With dry_run=true the planned outcome of each entry is returned without
+// applying it. Imports that would exceed the schema quota are refused with 409.
+func ImportSchemasHandler(store db.SchemaStore, q *quota.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var err error
+		dryRun := false
+		if v := r.URL.Query().Get("dry_run"); v != "" {
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var bundle SchemaBundle
+		if !decodeJSON(w, r, &bundle) {
+			return
+		}
+		if len(bundle.Schemas) == 0 {
+			http.Error(w, "the bundle contains no schemas", http.StatusBadRequest)
+			return
+		}
+
+		response := ImportResponse{DryRun: dryRun, Results: make([]ImportResult, len(bundle.Schemas))}
+		params := make([]db.QueryArgs, len(bundle.Schemas))
+		seen := map[string]bool{}
+		for i, entry := range bundle.Schemas {
+			result, err := planImport(store, entry, &params[i])
+			if err != nil {
+				http.Error(w, "failed to check schemas", http.StatusInternalServerError)
+				return
+			}
+			if !db.NamespaceAllowed(callerNamespaces(r), entry.Name) {
+				result.Action, result.Error = ImportInvalid, db.ErrNamespaceDenied.Error()
+			}
+			key := entry.Name + "/" + entry.Type + "/" + entry.Version
+			if result.Action != ImportInvalid && seen[key] {
+				result.Action, result.Error = ImportInvalid, "the bundle contains this version more than once"
+			}
+			seen[key] = true
+
+			switch result.Action {
+			case ImportCreated:
+				response.Created++
+			case ImportUpdated:
+				response.Updated++
+			case ImportUnchanged:
+				response.Unchanged++
+			default:
+				response.Invalid++
+			}
+			response.Results[i] = result
+		}
+
+		status := http.StatusOK
+		if response.Invalid > 0 {
+			status = http.StatusUnprocessableEntity
+		} else if !dryRun {
+			if response.Created > 0 && q.Config().MaxSchemas > 0 {
+				count, err := store.Count(false)
+				if err != nil {
+					http.Error(w, "failed to check schema quota", http.StatusInternalServerError)
+					return
+				}
+				if err := q.CheckSchemaCount(count + response.Created - 1); errors.Is(err, quota.ErrSchemaQuota) {
+					http.Error(w, err.Error(), http.StatusConflict)
+					return
+				}
+			}
+			if err := applyImport(store, r, response.Results, params); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// applyImport writes the planned creates and updates, stopping at the first failure.
+// Stores that insert batches register all new schemas in one round trip.
+func applyImport(store db.SchemaStore, r *http.Request, results []ImportResult, params []db.QueryArgs) error {
+	if inserter, ok := store.(db.BatchInserter); ok {
+		var created []int
+		var batch []db.QueryArgs
+		for i, result := range results {
+			if result.Action == ImportCreated {
+				created = append(created, i)
+				batch = append(batch, params[i])
+			}
+		}
+		ids, err := inserter.InsertBatch(batch)
+		if err != nil {
+			return fmt.Errorf("failed to import %d new schemas", len(batch))
+		}
+		for n, i := range created {
+			results[i].ID = ids[n]
+			recordChange(store, r, db.AuditActionInsert, nil, insertedSchema(ids[n], params[i]))
+		}
+	}
+
+	for i := range results {
+		result := &results[i]
+		switch result.Action {
+		case ImportCreated:
+			if result.ID != 0 {
+				continue
+			}
+			id, err := store.Insert(params[i])
+			if err != nil {
+				return fmt.Errorf("failed to import %s/%s/%s", result.Name, result.Type, result.Version)
+			}
+			result.ID = id
+			recordChange(store, r, db.AuditActionInsert, nil, insertedSchema(id, params[i]))
+		case ImportUpdated:
+			before, err := store.GetByID(result.ID)
+			if err != nil {
+				return fmt.Errorf("failed to import %s/%s/%s", result.Name, result.Type, result.Version)
+			}
+			updated, err := store.Update(params[i])
+			if err != nil {
+				return fmt.Errorf("failed to import %s/%s/%s", result.Name, result.Type, result.Version)
+			}
+			invalidateValidators(store, result.ID)
+			if len(updated) > 0 {
+				recordChange(store, r, db.AuditActionUpdate, before, &updated[0])
+			}
+		}
+	}
+	return nil
+}
+
+// wantsYAML reports whether the client asked for a YAML response
+func wantsYAML(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "yaml"
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, _ := strings.Cut(accept, ";")
+		if isYAML(strings.TrimSpace(mt)) {
+			return true
+		}
+	}
+	return false
+}
+
+// exportNDJSON streams the live schemas as one bundle entry per line while they are
+// scanned, so exports of large registries are never held in memory
+func exportNDJSON(w http.ResponseWriter, r *http.Request, store db.SchemaStore) {
+	var sw *streamWriter
+	start := func() {
+		w.Header().Set("Content-Disposition", `attachment; filename="schemas.ndjson"`)
+		sw = newStreamWriter(w, r)
+	}
+	err := store.List(
+		db.ListOptions{}, func(s db.Schema) error {
+			if sw == nil {
+				start()
+			}
+			return sw.Write(SchemaRequest{Name: s.Name, Type: s.Type, Version: s.Version, SchemaData: s.SchemaData})
+		},
+	)
+	if err != nil {
+		if sw != nil {
+			// The status line is already out, all we can do is stop and log
+			log.Printf("failed to stream schema export: %v", err)
+			return
+		}
+		http.Error(w, "failed to export schemas", http.StatusInternalServerError)
+		return
+	}
+	if sw == nil {
+		start()
+	}
+	_ = sw.Close()
+}
+
+// ExportSchemasHandler returns every live schema as a bundle ImportSchemasHandler
+// accepts, in JSON or, with format=yaml or a YAML Accept header, in YAML. With
+// format=ndjson or an NDJSON Accept header the schemas are streamed as bundle entries,
+// one per line.
+func ExportSchemasHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if wantsNDJSON(r) {
+			exportNDJSON(w, r, store)
+			return
+		}
+
+		exported := time.Now().UTC()
+		bundle := SchemaBundle{Exported: &exported, Schemas: []SchemaRequest{}}
+		err := store.List(
+			db.ListOptions{}, func(s db.Schema) error {
+				bundle.Schemas = append(
+					bundle.Schemas,
+					SchemaRequest{Name: s.Name, Type: s.Type, Version: s.Version, SchemaData: s.SchemaData},
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			http.Error(w, "failed to export schemas", http.StatusInternalServerError)
+			return
+		}
+
+		body, err := json.Marshal(bundle)
+		filename := "schemas.json"
+		contentType := "application/json"
+		if err == nil && wantsYAML(r) {
+			body, err = jsonToYAML(body)
+			filename, contentType = "schemas.yaml", "application/yaml"
+		}
+		if err != nil {
+			http.Error(w, "failed to encode schemas", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		_, _ = w.Write(append(body, '\n'))
+	}
+}
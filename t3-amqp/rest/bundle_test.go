@@ -0,0 +1,143 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportSchemasHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`})
+	assert.NoError(t, err)
+	_, err = store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: "1.1.0", SchemaData: `{"type":"object"}`})
+	assert.NoError(t, err)
+	handler := rest.ImportSchemasHandler(store, quota.NewManager(quota.Config{}))
+	post := func(target, contentType, body string) (*httptest.ResponseRecorder, rest.ImportResponse) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		var response rest.ImportResponse
+		_ = json.NewDecoder(rr.Body).Decode(&response)
+		return rr, response
+	}
+
+	bundle := `
+schemas:
+  - {name: orders, type: json, version: 1.0.0, schemaData: '{"type":"array"}'}
+  - {name: orders, type: json, version: 1.1.0, schemaData: '{"type":"object"}'}
+  - {name: invoices, type: json, version: 1.0.0, schemaData: '{"type":"object"}'}
+`
+	rr, response := post("/schemas/import?dry_run=true", "application/yaml", bundle)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, response.DryRun)
+	assert.Equal(t, []int{1, 1, 1}, []int{response.Created, response.Updated, response.Unchanged})
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count, "a dry run writes nothing")
+
+	rr, response = post("/schemas/import", "application/yaml", bundle)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotZero(t, response.Results[2].ID)
+	updated, err := store.GetByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"array"}`, updated.SchemaData)
+
+	invalid := `{"schemas":[{"name":"payments","type":"json","version":"1.0.0","schemaData":"{\"type\":\"objekt\"}"},` +
+		`{"name":"refunds","type":"json","version":"1.0.0","schemaData":"{}"}]}`
+	rr, response = post("/schemas/import", "application/json", invalid)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, rest.ImportInvalid, response.Results[0].Action)
+	assert.Equal(t, rest.ImportCreated, response.Results[1].Action)
+	_, err = store.Latest("refunds", "json")
+	assert.ErrorIs(t, err, db.ErrSchemaNotFound, "nothing is written when an entry is invalid")
+
+	rr, response = post(
+		"/schemas/import", "application/json",
+		`{"schemas":[{"name":"refunds","type":"json","version":"latest","schemaData":"{}"}]}`,
+	)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, response.Results[0].Error, "not a semantic version", "entries follow the binding rules")
+
+	limited := rest.ImportSchemasHandler(store, quota.NewManager(quota.Config{MaxSchemas: 3}))
+	req := httptest.NewRequest(http.MethodPost, "/schemas/import", strings.NewReader(
+		`{"schemas":[{"name":"refunds","type":"json","version":"1.0.0","schemaData":"{}"}]}`,
+	))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	limited.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestExportSchemasHandlerRoundTrips(t *testing.T) {
+	source := db.NewMemoryStore()
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		_, err := source.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: version, SchemaData: `{"type":"object"}`})
+		assert.NoError(t, err)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		rr := httptest.NewRecorder()
+		rest.ExportSchemasHandler(source).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/schemas/export?format="+format, nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "schemas."+format)
+
+		target := db.NewMemoryStore()
+		req := httptest.NewRequest(http.MethodPost, "/schemas/import", bytes.NewReader(rr.Body.Bytes()))
+		req.Header.Set("Content-Type", "application/"+format)
+		imported := httptest.NewRecorder()
+		rest.ImportSchemasHandler(target, quota.NewManager(quota.Config{})).ServeHTTP(imported, req)
+		assert.Equal(t, http.StatusOK, imported.Code, format)
+
+		count, err := target.Count(false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count, format)
+	}
+}
+
+func TestExportSchemasHandlerStreamsNDJSON(t *testing.T) {
+	store := db.NewMemoryStore()
+	for _, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		_, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: version, SchemaData: `{"type":"object"}`})
+		assert.NoError(t, err)
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/schemas/export?format=ndjson", nil),
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/schemas/export", nil)
+			req.Header.Set("Accept", "application/x-ndjson")
+			return req
+		}(),
+	} {
+		rr := httptest.NewRecorder()
+		rest.ExportSchemasHandler(store).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "schemas.ndjson")
+
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		if !assert.Len(t, lines, 3) {
+			continue
+		}
+		var entry rest.SchemaRequest
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &entry))
+		assert.Equal(t, "1.2.0", entry.Version)
+		assert.Equal(t, `{"type":"object"}`, entry.SchemaData)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/schemas/export?format=ndjson", nil)
+	rest.ExportSchemasHandler(db.NewMemoryStore()).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
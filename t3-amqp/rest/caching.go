@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"t3-amqp/db"
+	"time"
+)
+
+// SchemaCacheControl is sent with every cacheable schema read. Clients may reuse a
+// response briefly and must revalidate with If-None-Match afterwards. Responses are
+// private because what a caller sees depends on its tenant and namespaces.
+var SchemaCacheControl = "private, max-age=30, must-revalidate"
+
+// varyCaller marks a response as depending on its caller, whose tenant comes from
+// X-Tenant, the API key or the bearer credential
+func varyCaller(w http.ResponseWriter) {
+	w.Header().Add("Vary", TenantHeader+", Authorization, X-API-Key")
+}
+
+// lastModified returns the most recent modification time of the schemas
+func lastModified(schemas []db.Schema) time.Time {
+	var latest time.Time
+	for _, s := range schemas {
+		if s.Modified.After(latest) {
+			latest = s.Modified
+		}
+	}
+	return latest
+}
+
+// etagMatches reports whether the If-None-Match header matches etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified reports whether the client's conditional headers match the current
+// representation. If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !modified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// writeSchemas encodes schemas as JSON with caching validators and answers conditional
+// requests with 304 Not Modified
+func writeSchemas(w http.ResponseWriter, r *http.Request, schemas []db.Schema) {
+	writeCacheable(w, r, schemas, lastModified(schemas))
+}
+
+// writeSchema is writeSchemas for a single schema object
+func writeSchema(w http.ResponseWriter, r *http.Request, schema db.Schema) {
+	writeCacheable(w, r, schema, schema.Modified)
+}
+
+func writeCacheable(w http.ResponseWriter, r *http.Request, v interface{}, modified time.Time) {
+	writeCacheableAs(w, r, "application/json", v, modified)
+}
+
+// writeCacheableAs encodes v as JSON served as contentType. The ETag is a hash of the
+// encoded body, so it changes exactly when the content a client would receive does.
+func writeCacheableAs(w http.ResponseWriter, r *http.Request, contentType string, v interface{}, modified time.Time) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "failed to encode schemas", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", SchemaCacheControl)
+	varyCaller(w)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(append(body, '\n'))
+}
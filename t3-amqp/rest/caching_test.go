@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSchemasConditionalGet(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	schemas := []db.Schema{{ID: 1, Name: "test_schema", Type: "json", Version: "1.0.1", Modified: modified}}
+
+	rr := httptest.NewRecorder()
+	writeSchemas(rr, httptest.NewRequest(http.MethodGet, "/schema", nil), schemas)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	etag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, modified.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+	assert.Equal(t, SchemaCacheControl, rr.Header().Get("Cache-Control"))
+	assert.Contains(t, SchemaCacheControl, "private")
+	assert.Equal(t, "X-Tenant, Authorization, X-API-Key", rr.Header().Get("Vary"))
+
+	req := httptest.NewRequest(http.MethodGet, "/schema", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	writeSchemas(rr, req, schemas)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/schema", nil)
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	rr = httptest.NewRecorder()
+	writeSchemas(rr, req, schemas)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/schema", nil)
+	req.Header.Set("If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat))
+	rr = httptest.NewRecorder()
+	writeSchemas(rr, req, schemas)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/schema", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rr = httptest.NewRecorder()
+	writeSchemas(rr, req, schemas)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
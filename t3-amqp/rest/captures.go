@@ -0,0 +1,322 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/redact"
+	"time"
+)
+
+const (
+	DefaultCaptureDuration = time.Hour
+	DefaultCaptureMessages = 10000
+)
+
+// TrafficCapturer records the messages of a topic and replays them, amqp.Capturer
+// implements it
+type TrafficCapturer interface {
+	Capture(ctx context.Context, id int, topic string, max int) error
+	Replay(ctx context.Context, id int, topic string, speed float64) (int, error)
+}
+
+// CaptureLimits bounds what a single capture may record, zero values use the defaults
+type CaptureLimits struct {
+	MaxDuration time.Duration
+	MaxMessages int
+}
+
+// WithDefaults fills in unset limits
+func (l CaptureLimits) WithDefaults() CaptureLimits {
+	if l.MaxDuration <= 0 {
+		l.MaxDuration = DefaultCaptureDuration
+	}
+	if l.MaxMessages <= 0 {
+		l.MaxMessages = DefaultCaptureMessages
+	}
+	return l
+}
+
+// CaptureRequest starts a capture of topic, which ends after MaxMessages messages or
+// Duration, zero values use the limits
+type CaptureRequest struct {
+	Topic       string        `json:"topic"`
+	MaxMessages int           `json:"maxMessages,omitempty"`
+	Duration    time.Duration `json:"durationNs,omitempty"`
+}
+
+// ReplayRequest replays a capture to Topic, or to the original routing keys when it is
+// empty. Speed scales the original timing, 0 replays without waiting and nil is 1.
+type ReplayRequest struct {
+	Topic string   `json:"topic,omitempty"`
+	Speed *float64 `json:"speed,omitempty"`
+}
+
+type ReplayResponse struct {
+	CaptureID int    `json:"captureId"`
+	Topic     string `json:"topic,omitempty"`
+	Published int    `json:"published"`
+}
+
+// captureByPath loads the capture named by the id path value. Captures of other tenants
+// and of topics outside the caller's namespaces are answered as not found, topics are
+// namespaced like schema names.
+func captureByPath(w http.ResponseWriter, r *http.Request, captures db.CaptureStore) (*db.Capture, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return nil, false
+	}
+	capture, err := captures.CaptureByID(id)
+	if err == nil &&
+		(capture.Tenant != requestTenant(r) || !db.NamespaceAllowed(callerNamespaces(r), capture.Topic)) {
+		err = db.ErrCaptureNotFound
+	}
+	if err != nil {
+		writeError(w, r, err, "failed to retrieve capture")
+		return nil, false
+	}
+	return capture, true
+}
+
+// CapturesHandler lists the captures of the tenant on GET and starts one on POST, answering 202 with the
+// capture that is polled at /captures/{id}. A capture records the messages published to a
+// topic while it runs so they can be replayed later. Without a broker connection starting
+// a capture answers 503, and 429 when the tenant already runs as many tests as quotas
+// allow.
+func CapturesHandler(
+	captures db.CaptureStore, capturer TrafficCapturer, limits CaptureLimits, quotas *quota.Manager,
+) http.HandlerFunc {
+	limits = limits.WithDefaults()
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			list, err := captures.Captures(requestTenant(r))
+			if err != nil {
+				writeError(w, r, err, "failed to retrieve captures")
+				return
+			}
+			restricted := callerNamespaces(r)
+			list = slices.DeleteFunc(list, func(c db.Capture) bool { return !db.NamespaceAllowed(restricted, c.Topic) })
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(list)
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			if capturer == nil {
+				http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			var req CaptureRequest
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			if req.Topic == "" {
+				http.Error(w, "topic is required", http.StatusBadRequest)
+				return
+			}
+			if req.MaxMessages < 0 || req.Duration < 0 {
+				http.Error(w, "maxMessages and durationNs must not be negative", http.StatusBadRequest)
+				return
+			}
+			if req.Duration > limits.MaxDuration || req.MaxMessages > limits.MaxMessages {
+				http.Error(
+					w, fmt.Sprintf(
+						"captures may run for at most %s and record at most %d messages",
+						limits.MaxDuration, limits.MaxMessages,
+					), http.StatusBadRequest,
+				)
+				return
+			}
+			if !db.NamespaceAllowed(callerNamespaces(r), req.Topic) {
+				http.Error(w, db.ErrNamespaceDenied.Error(), http.StatusForbidden)
+				return
+			}
+			if req.Duration == 0 {
+				req.Duration = limits.MaxDuration
+			}
+			if req.MaxMessages == 0 {
+				req.MaxMessages = limits.MaxMessages
+			}
+
+			release, err := acquireRun(quotas, requestTenant(r))
+			if err != nil {
+				writeError(w, r, err, "failed to start capture")
+				return
+			}
+			created, err := captures.CreateCapture(
+				db.Capture{
+					Topic: req.Topic, Status: db.CaptureRunning, Started: time.Now().UTC(), Tenant: requestTenant(r),
+				},
+			)
+			if err != nil {
+				release()
+				writeError(w, r, err, "failed to record capture")
+				return
+			}
+			// The capture outlives the request
+			go func() {
+				defer release()
+				runCapture(context.WithoutCancel(r.Context()), captures, capturer, req, *created)
+			}()
+
+			w.Header().Set("Location", fmt.Sprintf("/captures/%d", created.ID))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			err = json.NewEncoder(w).Encode(created)
+			if err != nil {
+				return
+			}
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// runCapture records the messages of req into capture and stores how it ended
+func runCapture(
+	ctx context.Context, captures db.CaptureStore, capturer TrafficCapturer, req CaptureRequest, capture db.Capture,
+) {
+	ctx, cancel := context.WithTimeout(ctx, req.Duration)
+	defer cancel()
+	err := capturer.Capture(ctx, capture.ID, capture.Topic, req.MaxMessages)
+
+	finished := time.Now().UTC()
+	capture.Finished = &finished
+	capture.Status = db.CaptureFinished
+	if err != nil {
+		capture.Status, capture.Error = db.CaptureError, err.Error()
+	}
+	if err := captures.FinishCapture(capture); err != nil {
+		log.Printf("Failed to record the end of capture %d: %v", capture.ID, err)
+	}
+}
+
+// CaptureHandler returns the capture named in the path on GET and deletes it together
+// with its messages on DELETE. A capture that is still running cannot be deleted.
+func CaptureHandler(captures db.CaptureStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		capture, ok := captureByPath(w, r, captures)
+		if !ok {
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if capture.Status == db.CaptureRunning {
+				http.Error(w, "capture is still running", http.StatusConflict)
+				return
+			}
+			if err := captures.DeleteCapture(capture.ID); err != nil {
+				writeError(w, r, err, "failed to delete capture")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(capture)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// CapturedMessagesHandler returns the messages of the capture named in the path in the
+// order they were received, bodies are base64 encoded. Sensitive payload fields are
+// masked, the stored bodies are kept as received so replays publish the original messages.
+func CapturedMessagesHandler(captures db.CaptureStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		capture, ok := captureByPath(w, r, captures)
+		if !ok {
+			return
+		}
+		messages, err := captures.CapturedMessages(capture.ID)
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve captured messages")
+			return
+		}
+		for i := range messages {
+			messages[i].Body = redact.Payload(messages[i].Body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(messages)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ReplayHandler republishes the messages of the capture named in the path in their
+// original order and answers once the broker confirmed all of them. A capture that is
+// still running cannot be replayed. Without a broker connection it answers 503.
+func ReplayHandler(captures db.CaptureStore, capturer TrafficCapturer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if capturer == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		capture, ok := captureByPath(w, r, captures)
+		if !ok {
+			return
+		}
+
+		var req ReplayRequest
+		if r.ContentLength != 0 && !decodeJSON(w, r, &req) {
+			return
+		}
+		speed := 1.0
+		if req.Speed != nil {
+			speed = *req.Speed
+		}
+		if speed < 0 {
+			http.Error(w, "speed must not be negative", http.StatusBadRequest)
+			return
+		}
+		if req.Topic != "" && !db.NamespaceAllowed(callerNamespaces(r), req.Topic) {
+			http.Error(w, db.ErrNamespaceDenied.Error(), http.StatusForbidden)
+			return
+		}
+		if capture.Status == db.CaptureRunning {
+			http.Error(w, "capture is still running", http.StatusConflict)
+			return
+		}
+
+		published, err := capturer.Replay(r.Context(), capture.ID, req.Topic, speed)
+		if err != nil {
+			log.Printf("Failed to replay capture %d after %d messages: %v", capture.ID, published, err)
+			http.Error(
+				w, fmt.Sprintf("replay failed after %d messages", published), http.StatusBadGateway,
+			)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(ReplayResponse{CaptureID: capture.ID, Topic: req.Topic, Published: published})
+		if err != nil {
+			return
+		}
+	}
+}
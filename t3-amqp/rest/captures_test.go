@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCapturer stores count messages per capture and records the replays it was asked for
+type fakeCapturer struct {
+	store   db.CaptureStore
+	count   int
+	max     chan int
+	replays []ReplayRequest
+}
+
+func (f *fakeCapturer) Capture(_ context.Context, id int, topic string, max int) error {
+	f.max <- max
+	var messages []db.CapturedMessage
+	for i := 0; i < f.count; i++ {
+		messages = append(messages, db.CapturedMessage{Seq: i, RoutingKey: topic, Body: []byte(`{"id":1,"password":"hunter2"}`)})
+	}
+	return f.store.AddCapturedMessages(id, messages)
+}
+
+func (f *fakeCapturer) Replay(_ context.Context, id int, topic string, speed float64) (int, error) {
+	f.replays = append(f.replays, ReplayRequest{Topic: topic, Speed: &speed})
+	messages, err := f.store.CapturedMessages(id)
+	if topic == "broken" {
+		return 1, errors.New("channel closed")
+	}
+	return len(messages), err
+}
+
+func TestCaptureHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	capturer := &fakeCapturer{store: store, count: 3, max: make(chan int, 1)}
+	limits := CaptureLimits{MaxDuration: time.Minute, MaxMessages: 100}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/captures", CapturesHandler(store, capturer, limits, nil))
+	mux.HandleFunc("/captures/{id}", CaptureHandler(store))
+	mux.HandleFunc("GET /captures/{id}/messages", CapturedMessagesHandler(store))
+	mux.HandleFunc("POST /captures/{id}/replay", ReplayHandler(store, capturer))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodPost, "/captures", `{"topic":"orders.created","durationNs":1000000000}`)
+	if !assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String()) {
+		return
+	}
+	assert.Equal(t, 100, <-capturer.max, "captures are bounded by the message limit")
+	location := w.Header().Get("Location")
+
+	var finished db.Capture
+	assert.Eventually(t, func() bool {
+		w := do(http.MethodGet, location, "")
+		return json.Unmarshal(w.Body.Bytes(), &finished) == nil && finished.Status != db.CaptureRunning
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, db.CaptureFinished, finished.Status)
+	assert.Equal(t, 3, finished.Messages)
+
+	var messages []db.CapturedMessage
+	w = do(http.MethodGet, location+"/messages", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &messages))
+	if assert.Len(t, messages, 3) {
+		assert.JSONEq(t, `{"id":1,"password":"****"}`, string(messages[0].Body), "sensitive fields are masked")
+	}
+	stored, _ := store.CapturedMessages(finished.ID)
+	assert.Contains(t, string(stored[0].Body), "hunter2", "the stored bodies are kept for replays")
+
+	w = do(http.MethodPost, location+"/replay", "")
+	if assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+		var replayed ReplayResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &replayed))
+		assert.Equal(t, 3, replayed.Published)
+		assert.Equal(t, 1.0, *capturer.replays[0].Speed, "replays keep the original timing by default")
+	}
+	w = do(http.MethodPost, location+"/replay", `{"topic":"orders.replayed","speed":0}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, capturer.replays, 2) {
+		assert.Equal(t, "orders.replayed", capturer.replays[1].Topic)
+		assert.Equal(t, 0.0, *capturer.replays[1].Speed)
+	}
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, location+"/replay", `{"speed":-1}`).Code)
+	assert.Equal(t, http.StatusBadGateway, do(http.MethodPost, location+"/replay", `{"topic":"broken"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/captures/99/replay", "").Code)
+
+	invalid := []struct {
+		name string
+		body string
+	}{
+		{"no topic", `{}`},
+		{"too long", `{"topic":"orders.created","durationNs":3600000000000}`},
+		{"too many messages", `{"topic":"orders.created","maxMessages":101}`},
+		{"negative", `{"topic":"orders.created","maxMessages":-1}`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/captures", tt.body).Code)
+		})
+	}
+
+	teamA := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		principal := &auth.Principal{ID: "key:team-a", Namespaces: []string{"team-a"}}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+		return w
+	}
+	hidden := teamA(http.MethodGet, location, "")
+	assert.Equal(t, http.StatusNotFound, hidden.Code, "captures of other namespaces are hidden")
+	assert.Equal(t, http.StatusForbidden, teamA(http.MethodPost, "/captures", `{"topic":"orders.created"}`).Code)
+	var listed []db.Capture
+	assert.NoError(t, json.Unmarshal(teamA(http.MethodGet, "/captures", "").Body.Bytes(), &listed))
+	assert.Empty(t, listed)
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, location, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, location, "").Code)
+
+	mux = http.NewServeMux()
+	mux.HandleFunc("/captures", CapturesHandler(store, nil, limits, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, do(http.MethodPost, "/captures", `{"topic":"orders"}`).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodPut, "/captures", "").Code)
+}
@@ -0,0 +1,183 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+const octetStream = "application/octet-stream"
+
+// EncodeRequest converts Payload, the JSON form of a message, to the binary encoding of
+// the named schema. Framing "confluent" prefixes it with the Confluent wire format header.
+type EncodeRequest struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Version string          `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+	Framing string          `json:"framing,omitempty"`
+}
+
+type EncodeResponse struct {
+	SchemaID int    `json:"schemaId"`
+	Framing  string `json:"framing,omitempty"`
+	// Data is the encoded payload, base64 encoded
+	Data []byte `json:"data"`
+}
+
+// DecodeRequest converts Data, base64 encoded binary, to its JSON form. The schema may be
+// left out when Data is in the Confluent wire format, which names the schema ID.
+type DecodeRequest struct {
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version,omitempty"`
+	Data    []byte `json:"data"`
+	Framing string `json:"framing,omitempty"`
+}
+
+type DecodeResponse struct {
+	SchemaID int             `json:"schemaId"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// EncodeHandler encodes a JSON payload with the binary codec of a registered schema, Avro
+// binary for avro schemas. The encoded data is answered base64 encoded in JSON, or raw to
+// clients accepting only application/octet-stream. Payloads that do not match the schema
+// and schema types without a binary encoding answer 422.
+func EncodeHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req EncodeRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Name == "" || req.Type == "" || req.Version == "" || len(req.Payload) == 0 {
+			http.Error(w, "name, type, version and payload are required", http.StatusBadRequest)
+			return
+		}
+		if _, err := validate.Frame(req.Framing, 0, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		schema, err := db.ResolveReference(store, db.SchemaRef{Name: req.Name, Type: req.Type, Version: req.Version})
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		setLifecycleHeaders(w, *schema)
+		if schema.Status == db.StatusRetired {
+			http.Error(w, "schema has been retired", http.StatusGone)
+			return
+		}
+		codec, err := validate.NewCodec(schema.Type, schema.SchemaData)
+		if err != nil {
+			writeStatusProblem(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		data, err := codec.Encode(req.Payload)
+		if err != nil {
+			writeStatusProblem(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		data, _ = validate.Frame(req.Framing, schema.ID, data)
+
+		if strings.TrimSpace(r.Header.Get("Accept")) == octetStream {
+			w.Header().Set("Content-Type", octetStream)
+			_, _ = w.Write(data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(EncodeResponse{SchemaID: schema.ID, Framing: req.Framing, Data: data})
+		if err != nil {
+			return
+		}
+	}
+}
+
+// DecodeHandler decodes binary data with the codec of a registered schema and answers its
+// JSON form. Raw data may be posted as application/octet-stream with the schema and
+// framing in the query string. Data in the Confluent wire format is decoded with the
+// schema it names unless the request names one.
+func DecodeHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req DecodeRequest
+		if mediaType(r) == octetStream {
+			q := r.URL.Query()
+			req = DecodeRequest{
+				Name: q.Get("name"), Type: q.Get("type"), Version: q.Get("version"), Framing: q.Get("framing"),
+			}
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeBodyError(w, err)
+				return
+			}
+			req.Data = data
+		} else if !decodeJSON(w, r, &req) {
+			return
+		}
+		if len(req.Data) == 0 {
+			http.Error(w, "data is required", http.StatusBadRequest)
+			return
+		}
+		id, data, err := validate.Unframe(req.Framing, req.Data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var schema *db.Schema
+		named := req.Name != "" || req.Type != "" || req.Version != ""
+		switch {
+		case named && (req.Name == "" || req.Type == "" || req.Version == ""):
+			http.Error(w, "name, type and version are required together", http.StatusBadRequest)
+			return
+		case named:
+			schema, err = db.ResolveReference(store, db.SchemaRef{Name: req.Name, Type: req.Type, Version: req.Version})
+		case id > 0:
+			schema, err = store.GetByID(id)
+		default:
+			http.Error(w, "name, type and version are required for data without a schema ID", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		if id > 0 && id != schema.ID {
+			http.Error(w, fmt.Sprintf("data was encoded with schema %d, not %d", id, schema.ID), http.StatusBadRequest)
+			return
+		}
+
+		codec, err := validate.NewCodec(schema.Type, schema.SchemaData)
+		if err != nil {
+			writeStatusProblem(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		payload, err := codec.Decode(data)
+		if err != nil {
+			writeStatusProblem(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(DecodeResponse{SchemaID: schema.ID, Payload: payload})
+		if err != nil {
+			return
+		}
+	}
+}
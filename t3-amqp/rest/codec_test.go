@@ -0,0 +1,77 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, err := store.Insert(db.QueryArgs{
+		Name: "order", Type: "avro", Version: "1.0.0",
+		SchemaData: `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/encode", rest.EncodeHandler(store))
+	mux.HandleFunc("/decode", rest.DecodeHandler(store))
+	serve := func(target, contentType, accept, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", accept)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := serve(
+		"/encode", "application/json", "",
+		`{"name":"order","type":"avro","version":"1.0.0","payload":{"id":3},"framing":"confluent"}`,
+	)
+	if !assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String()) {
+		return
+	}
+	var encoded rest.EncodeResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&encoded))
+	assert.Equal(t, id, encoded.SchemaID)
+	assert.Equal(t, []byte{0, 0, 0, 0, byte(id), 6}, encoded.Data)
+
+	// Framed data names its schema
+	body, _ := json.Marshal(rest.DecodeRequest{Data: encoded.Data, Framing: "confluent"})
+	rr = serve("/decode", "application/json", "", string(body))
+	if assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String()) {
+		var decoded rest.DecodeResponse
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&decoded))
+		assert.Equal(t, id, decoded.SchemaID)
+		assert.JSONEq(t, `{"id":3}`, string(decoded.Payload))
+	}
+
+	order := `{"name":"order","type":"avro","version":"1.0.0",`
+	rr = serve("/encode", "application/json", "application/octet-stream", order+`"payload":{"id":3}}`)
+	assert.Equal(t, "application/octet-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, []byte{6}, rr.Body.Bytes())
+	rr = serve("/decode?name=order&type=avro&version=1.0.0", "application/octet-stream", "", string(rr.Body.Bytes()))
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = serve("/encode", "application/json", "", order+`"payload":{"id":"x"}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	rr = serve("/encode", "application/json", "", `{"name":"order","type":"avro","version":"2.0.0","payload":{"id":3}}`)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr = serve("/decode", "application/octet-stream", "", string([]byte{6}))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "unframed data needs a schema")
+	rr = serve(
+		"/decode?name=order&type=avro&version=1.0.0&framing=confluent", "application/octet-stream", "",
+		string(bytes.Repeat([]byte{0}, 4)),
+	)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "too short for a frame")
+}
@@ -0,0 +1,202 @@
+package rest
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MinCompressBytes is the smallest response body that is gzipped, smaller ones do not
+// get any shorter
+var MinCompressBytes = 1024
+
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response. A
+// gzip entry takes precedence over a wildcard, q=0 refuses the coding.
+func acceptsGzip(header string) bool {
+	accepted, wildcard := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip", "x-gzip":
+			accepted = q
+		case "*":
+			wildcard = q
+		}
+	}
+	if accepted >= 0 {
+		return accepted > 0
+	}
+	return wildcard > 0
+}
+
+// gzipBody reads a gzipped request body, closing it closes the original body too
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// gzipResponseWriter holds back the status and the first MinCompressBytes of a response
+// to decide whether it is worth compressing, then writes it gzipped or as is
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	pending []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (gw *gzipResponseWriter) WriteHeader(status int) {
+	if gw.status != 0 || gw.decided {
+		return
+	}
+	if status < http.StatusOK {
+		gw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	gw.status = status
+
+	// Bodiless responses, bodies encoded by the handler and short ones are sent as is
+	h := gw.Header()
+	length, err := strconv.Atoi(h.Get("Content-Length"))
+	if status == http.StatusNoContent || status == http.StatusNotModified || h.Get("Content-Encoding") != "" ||
+		(err == nil && length < MinCompressBytes) {
+		gw.passThrough()
+	}
+}
+
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if gw.status == 0 {
+		gw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case gw.gz != nil:
+		return gw.gz.Write(b)
+	case gw.decided:
+		return gw.ResponseWriter.Write(b)
+	}
+
+	gw.pending = append(gw.pending, b...)
+	if len(gw.pending) >= MinCompressBytes {
+		if err := gw.compress(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends what was written so far, compressed since the response is streamed and
+// its size cannot be known
+func (gw *gzipResponseWriter) Flush() {
+	if gw.status == 0 {
+		gw.WriteHeader(http.StatusOK)
+	}
+	if !gw.decided && gw.compress() != nil {
+		return
+	}
+	if gw.gz != nil && gw.gz.Flush() != nil {
+		return
+	}
+	if f, ok := gw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// compress starts the gzipped response. The ETag is weakened because the compressed
+// bytes differ from the representation it was computed for.
+func (gw *gzipResponseWriter) compress() error {
+	gw.decided = true
+	h := gw.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", "gzip")
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+
+	gw.gz = gzipWriters.Get().(*gzip.Writer)
+	gw.gz.Reset(gw.ResponseWriter)
+	_, err := gw.gz.Write(gw.pending)
+	gw.pending = nil
+	return err
+}
+
+func (gw *gzipResponseWriter) passThrough() {
+	gw.decided = true
+	gw.ResponseWriter.WriteHeader(gw.status)
+}
+
+// close finishes the response, a body shorter than MinCompressBytes is sent as is
+func (gw *gzipResponseWriter) close() error {
+	switch {
+	case gw.gz != nil:
+		err := gw.gz.Close()
+		gzipWriters.Put(gw.gz)
+		gw.gz = nil
+		return err
+	case gw.status == 0 || gw.decided:
+		return nil
+	}
+	gw.passThrough()
+	_, err := gw.ResponseWriter.Write(gw.pending)
+	return err
+}
+
+// CompressionMiddleware gzips responses for clients sending Accept-Encoding: gzip and
+// unpacks request bodies sent with Content-Encoding: gzip, so large schema documents
+// travel compressed both ways. Body limits apply to the unpacked body. Other request
+// encodings are answered with 415.
+func CompressionMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			if r.ContentLength == 0 {
+				break
+			}
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "invalid gzip request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = gzipBody{Reader: gz, body: r.Body}
+			r.ContentLength = -1
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+		default:
+			w.Header().Set("Accept-Encoding", "gzip, identity")
+			http.Error(w, fmt.Sprintf("unsupported content encoding %q", encoding), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	}
+}
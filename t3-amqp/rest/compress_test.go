@@ -0,0 +1,95 @@
+package rest_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressionMiddlewareResponses(t *testing.T) {
+	large := strings.Repeat(`{"name":"customerId","type":"string"},`, 100)
+	handler := rest.CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		switch r.URL.Path {
+		case "/large":
+			_, _ = io.WriteString(w, large)
+		case "/small":
+			_, _ = io.WriteString(w, `{}`)
+		case "/cached":
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("/large", "br, gzip")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+	assert.Equal(t, `W/"abc"`, rr.Header().Get("ETag"), "compressed responses carry a weak ETag")
+	gz, err := gzip.NewReader(rr.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	body, err := io.ReadAll(gz)
+	assert.NoError(t, err)
+	assert.Equal(t, large, string(body))
+
+	for _, encoding := range []string{"", "identity", "gzip;q=0, *"} {
+		rr = get("/large", encoding)
+		assert.Empty(t, rr.Header().Get("Content-Encoding"), encoding)
+		assert.Equal(t, large, rr.Body.String(), encoding)
+	}
+
+	rr = get("/small", "gzip")
+	assert.Empty(t, rr.Header().Get("Content-Encoding"), "short bodies are sent as is")
+	assert.Equal(t, `{}`, rr.Body.String())
+
+	rr = get("/cached", "gzip")
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+}
+
+func TestCompressionMiddlewareRequests(t *testing.T) {
+	handler := rest.CompressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/schema", bytes.NewReader(body))
+		req.Header.Set("Content-Encoding", encoding)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = io.WriteString(gz, `{"name":"orders"}`)
+	assert.NoError(t, gz.Close())
+
+	rr := post("gzip", compressed.Bytes())
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"name":"orders"}`, rr.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, post("gzip", []byte(`{"name":"orders"}`)).Code)
+	rr = post("br", []byte(`{}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Equal(t, "gzip, identity", rr.Header().Get("Accept-Encoding"))
+}
@@ -0,0 +1,349 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+// ConfluentMediaTypes are accepted by the Confluent Schema Registry compatible routes
+var ConfluentMediaTypes = []string{
+	"application/vnd.schemaregistry.v1+json", "application/vnd.schemaregistry+json", "application/json",
+}
+
+const confluentContentType = "application/vnd.schemaregistry.v1+json"
+
+// Confluent Schema Registry error codes
+const (
+	confluentForbidden       = 40301
+	confluentSubjectNotFound = 40401
+	confluentVersionNotFound = 40402
+	confluentSchemaNotFound  = 40403
+	confluentInvalidSchema   = 42201
+	confluentInvalidVersion  = 42202
+	confluentBackendError    = 50001
+)
+
+// confluentTypes maps the registry types Confluent clients understand to their schemaType
+var confluentTypes = map[string]string{"avro": "AVRO", "json": "JSON", "protobuf": "PROTOBUF"}
+
+type ConfluentSchemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+type ConfluentSchema struct {
+	Subject    string `json:"subject,omitempty"`
+	ID         int    `json:"id"`
+	Version    int    `json:"version,omitempty"`
+	SchemaType string `json:"schemaType,omitempty"`
+	Schema     string `json:"schema"`
+}
+
+type confluentError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func writeConfluent(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", confluentContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeConfluentError answers in the error format Confluent clients parse
+func writeConfluentError(w http.ResponseWriter, status, code int, message string) {
+	writeConfluent(w, status, confluentError{ErrorCode: code, Message: message})
+}
+
+// confluentType returns the Confluent schemaType of a registry type, empty for AVRO
+// which clients assume when the field is missing
+func confluentType(schemaType string) string {
+	if schemaType == "avro" {
+		return ""
+	}
+	return confluentTypes[schemaType]
+}
+
+// registryType returns the registry type of a Confluent schemaType
+func registryType(schemaType string) (string, bool) {
+	if schemaType == "" {
+		return "avro", true
+	}
+	for registry, confluent := range confluentTypes {
+		if confluent == strings.ToUpper(schemaType) {
+			return registry, true
+		}
+	}
+	return "", false
+}
+
+// subjectVersions returns the versions of a subject Confluent clients can use, ordered
+// by semantic version. Confluent version numbers are the 1-based positions in this list.
+func subjectVersions(store db.SchemaStore, subject string) ([]db.Schema, error) {
+	schemas, err := store.Filter(db.QueryArgs{Name: subject})
+	if err != nil {
+		return nil, err
+	}
+	versions := []db.Schema{}
+	for _, s := range schemas {
+		if _, ok := confluentTypes[s.Type]; ok {
+			versions = append(versions, s)
+		}
+	}
+	db.SortByVersion(versions)
+	return versions, nil
+}
+
+func toConfluent(subject string, version int, s db.Schema) ConfluentSchema {
+	return ConfluentSchema{
+		Subject: subject, ID: s.ID, Version: version, SchemaType: confluentType(s.Type), Schema: s.SchemaData,
+	}
+}
+
+// sameSchemaData compares two schema documents ignoring JSON formatting and key order
+func sameSchemaData(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var av, bv interface{}
+	if json.Unmarshal([]byte(a), &av) != nil || json.Unmarshal([]byte(b), &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// findRegistered returns the position among versions of the schema with schemaType and
+// data, -1 when it is not registered
+func findRegistered(versions []db.Schema, schemaType, data string) int {
+	fingerprint, _ := validate.SchemaFingerprint(schemaType, data)
+	for i, s := range versions {
+		if s.Type != schemaType {
+			continue
+		}
+		if (fingerprint != "" && s.Fingerprint == fingerprint) || sameSchemaData(s.SchemaData, data) {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeConfluentSchema reads a register or lookup request and resolves its type
+func decodeConfluentSchema(w http.ResponseWriter, r *http.Request) (ConfluentSchemaRequest, string, bool) {
+	var req ConfluentSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeConfluentError(w, http.StatusUnprocessableEntity, confluentInvalidSchema, "invalid request body")
+		return req, "", false
+	}
+	schemaType, ok := registryType(req.SchemaType)
+	if !ok || req.Schema == "" {
+		writeConfluentError(w, http.StatusUnprocessableEntity, confluentInvalidSchema, "invalid schema or schemaType")
+		return req, "", false
+	}
+	return req, schemaType, true
+}
+
+// ConfluentSubjectsHandler lists every subject, the names of schemas Confluent clients
+// can use
+func ConfluentSubjectsHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		seen := map[string]bool{}
+		subjects := []string{}
+		err := store.List(
+			db.ListOptions{}, func(s db.Schema) error {
+				if _, ok := confluentTypes[s.Type]; ok && !seen[s.Name] {
+					seen[s.Name] = true
+					subjects = append(subjects, s.Name)
+				}
+				return nil
+			},
+		)
+		if err != nil {
+			writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to list subjects")
+			return
+		}
+		sort.Strings(subjects)
+		writeConfluent(w, http.StatusOK, subjects)
+	}
+}
+
+// ConfluentVersionsHandler lists the version numbers of a subject on GET and registers
+// a schema under it on POST. Registering a schema the subject already has returns the
+// existing id, new schemas become the next major version.
+func ConfluentVersionsHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		subject := r.PathValue("subject")
+		versions, err := subjectVersions(store, subject)
+		if err != nil {
+			writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to read subject")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if len(versions) == 0 {
+				writeConfluentError(w, http.StatusNotFound, confluentSubjectNotFound, "Subject not found.")
+				return
+			}
+			numbers := make([]int, len(versions))
+			for i := range versions {
+				numbers[i] = i + 1
+			}
+			writeCacheableAs(w, r, confluentContentType, numbers, lastModified(versions))
+
+		case http.MethodPost:
+			req, schemaType, ok := decodeConfluentSchema(w, r)
+			if !ok {
+				return
+			}
+			if i := findRegistered(versions, schemaType, req.Schema); i >= 0 {
+				writeConfluent(w, http.StatusOK, map[string]int{"id": versions[i].ID})
+				return
+			}
+
+			existing := make([]string, len(versions))
+			for i, s := range versions {
+				existing[i] = s.Version
+			}
+			params := db.QueryArgs{
+				Name: subject, Type: schemaType, Version: db.NextMajorVersion(existing), SchemaData: req.Schema,
+			}
+			if err := prepareSchema(store, &params); err != nil {
+				var schemaErr *validate.SchemaError
+				if errors.As(err, &schemaErr) || errors.Is(err, errDuplicateSchema) {
+					writeConfluentError(w, http.StatusUnprocessableEntity, confluentInvalidSchema, err.Error())
+				} else {
+					writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to check schema")
+				}
+				return
+			}
+
+			id, err := store.Insert(params)
+			if errors.Is(err, db.ErrForbidden) {
+				writeConfluentError(w, http.StatusForbidden, confluentForbidden, err.Error())
+				return
+			}
+			if err != nil {
+				writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to register schema")
+				return
+			}
+			recordChange(store, r, db.AuditActionInsert, nil, insertedSchema(id, params))
+			writeConfluent(w, http.StatusOK, map[string]int{"id": id})
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// ConfluentVersionHandler returns one version of a subject by number or as latest
+func ConfluentVersionHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		subject := r.PathValue("subject")
+		versions, err := subjectVersions(store, subject)
+		if err != nil {
+			writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to read subject")
+			return
+		}
+		if len(versions) == 0 {
+			writeConfluentError(w, http.StatusNotFound, confluentSubjectNotFound, "Subject not found.")
+			return
+		}
+
+		number := len(versions)
+		if v := r.PathValue("version"); v != db.LatestVersion && v != "-1" {
+			number, err = strconv.Atoi(v)
+			if err != nil || number < 1 {
+				writeConfluentError(
+					w, http.StatusUnprocessableEntity, confluentInvalidVersion,
+					"The specified version is not a valid version id.",
+				)
+				return
+			}
+			if number > len(versions) {
+				writeConfluentError(w, http.StatusNotFound, confluentVersionNotFound, "Version not found.")
+				return
+			}
+		}
+		schema := versions[number-1]
+		writeCacheableAs(w, r, confluentContentType, toConfluent(subject, number, schema), schema.Modified)
+	}
+}
+
+// ConfluentLookupHandler reports whether a schema is registered under a subject and
+// with which id and version
+func ConfluentLookupHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		subject := r.PathValue("subject")
+		versions, err := subjectVersions(store, subject)
+		if err != nil {
+			writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to read subject")
+			return
+		}
+		if len(versions) == 0 {
+			writeConfluentError(w, http.StatusNotFound, confluentSubjectNotFound, "Subject not found.")
+			return
+		}
+
+		req, schemaType, ok := decodeConfluentSchema(w, r)
+		if !ok {
+			return
+		}
+		i := findRegistered(versions, schemaType, req.Schema)
+		if i < 0 {
+			writeConfluentError(w, http.StatusNotFound, confluentSchemaNotFound, "Schema not found.")
+			return
+		}
+		writeConfluent(w, http.StatusOK, toConfluent(subject, i+1, versions[i]))
+	}
+}
+
+// ConfluentSchemaByIdHandler returns the schema with the global id in the path
+func ConfluentSchemaByIdHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeConfluentError(w, http.StatusNotFound, confluentSchemaNotFound, "Schema not found.")
+			return
+		}
+		schema, err := store.GetByID(id)
+		if errors.Is(err, db.ErrSchemaNotFound) {
+			writeConfluentError(w, http.StatusNotFound, confluentSchemaNotFound, "Schema not found.")
+			return
+		}
+		if err != nil {
+			writeConfluentError(w, http.StatusInternalServerError, confluentBackendError, "failed to read schema")
+			return
+		}
+		writeCacheableAs(
+			w, r, confluentContentType,
+			ConfluentSchema{SchemaType: confluentType(schema.Type), Schema: schema.SchemaData}, schema.Modified,
+		)
+	}
+}
@@ -0,0 +1,107 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfluentFacade(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "payments", Type: "xsd", Version: "1.0.0", SchemaData: `"<xs:schema/>"`})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/subjects", rest.ConfluentSubjectsHandler(store))
+	mux.HandleFunc("/subjects/{subject}", rest.ConfluentLookupHandler(store))
+	mux.HandleFunc("/subjects/{subject}/versions", rest.ConfluentVersionsHandler(store))
+	mux.HandleFunc("/subjects/{subject}/versions/{version}", rest.ConfluentVersionHandler(store))
+	mux.HandleFunc("/schemas/ids/{id}", rest.ConfluentSchemaByIdHandler(store))
+	serve := func(method, target, body string, v interface{}) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		if v != nil {
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(v), target)
+		}
+		return rr
+	}
+
+	v1 := `{"schema":"{\"type\":\"record\",\"name\":\"Order\",\"fields\":[{\"name\":\"id\",\"type\":\"long\"}]}"}`
+	v2 := `{"schema":"{\"type\":\"record\",\"name\":\"Order\",\"fields\":[{\"name\":\"id\",\"type\":\"string\"}]}"}`
+	var first, again, second map[string]int
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/subjects/orders-value/versions", v1, &first).Code)
+	serve(http.MethodPost, "/subjects/orders-value/versions", v1, &again)
+	assert.Equal(t, first["id"], again["id"], "registering the same schema returns its id")
+	serve(http.MethodPost, "/subjects/orders-value/versions", v2, &second)
+	assert.NotEqual(t, first["id"], second["id"])
+
+	var subjects []string
+	serve(http.MethodGet, "/subjects", "", &subjects)
+	assert.Equal(t, []string{"orders-value"}, subjects, "xsd schemas are not Confluent subjects")
+
+	var versions []int
+	serve(http.MethodGet, "/subjects/orders-value/versions", "", &versions)
+	assert.Equal(t, []int{1, 2}, versions)
+
+	var latest rest.ConfluentSchema
+	serve(http.MethodGet, "/subjects/orders-value/versions/latest", "", &latest)
+	assert.Equal(t, second["id"], latest.ID)
+	assert.Equal(t, 2, latest.Version)
+	assert.Empty(t, latest.SchemaType, "AVRO is implied")
+
+	var found rest.ConfluentSchema
+	serve(http.MethodPost, "/subjects/orders-value", v1, &found)
+	assert.Equal(t, 1, found.Version)
+
+	var byID rest.ConfluentSchema
+	serve(http.MethodGet, fmt.Sprintf("/schemas/ids/%d", first["id"]), "", &byID)
+	assert.Contains(t, byID.Schema, `"long"`)
+
+	var notFound struct {
+		ErrorCode int `json:"error_code"`
+	}
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/subjects/missing/versions", "", &notFound).Code)
+	assert.Equal(t, 40401, notFound.ErrorCode)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/subjects/orders-value/versions/3", "", &notFound).Code)
+	assert.Equal(t, 40402, notFound.ErrorCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/subjects/orders-value/versions", `{"schema":"{\"type\":\"record\"}"}`, &notFound).Code)
+	assert.Equal(t, 42201, notFound.ErrorCode)
+}
+
+func TestConfluentConditionalGet(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, err := store.Insert(db.QueryArgs{
+		Name: "orders-value", Type: "avro", Version: "1.0.0", SchemaData: `{"type":"string"}`,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/subjects/{subject}/versions/{version}", rest.ConfluentVersionHandler(store))
+	mux.HandleFunc("/schemas/ids/{id}", rest.ConfluentSchemaByIdHandler(store))
+	for _, target := range []string{"/subjects/orders-value/versions/latest", fmt.Sprintf("/schemas/ids/%d", id)} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rr.Code, target)
+		assert.Equal(t, "application/vnd.schemaregistry.v1+json", rr.Header().Get("Content-Type"), target)
+		etag := rr.Header().Get("ETag")
+		if !assert.NotEmpty(t, etag, target) {
+			continue
+		}
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusNotModified, rr.Code, target)
+		assert.Empty(t, rr.Body.String(), target)
+	}
+}
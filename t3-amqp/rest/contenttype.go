@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	// StructuredMediaTypes are accepted by endpoints that decode a request document
+	StructuredMediaTypes = []string{"application/json", "application/yaml"}
+	// ValidationMediaTypes are accepted by the validate endpoint, XML carries raw payloads
+	// for xsd schemas
+	ValidationMediaTypes = []string{"application/json", "application/yaml", "application/xml", "text/xml"}
+	// CodecMediaTypes are accepted by the decode endpoint, octet streams carry raw binary
+	// payloads
+	CodecMediaTypes = []string{"application/json", "application/yaml", "application/octet-stream"}
+	// UploadMediaTypes are accepted by the raw schema upload endpoint
+	UploadMediaTypes = []string{
+		"application/json", "application/yaml", "application/xml", "text/xml", "text/plain",
+		"application/octet-stream",
+	}
+)
+
+// mediaType returns the lower cased media type of the request without parameters
+func mediaType(r *http.Request) string {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(mt)
+}
+
+func isXML(mt string) bool {
+	return mt == "application/xml" || mt == "text/xml"
+}
+
+func isYAML(mt string) bool {
+	return mt == "application/yaml" || mt == "application/x-yaml" || mt == "text/yaml"
+}
+
+// ContentTypeMiddleware rejects POST, PUT and PATCH requests with a body whose
+// Content-Type is not one of accepted, answering 415 with the supported types
+func ContentTypeMiddleware(accepted []string, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mt := mediaType(r)
+		for _, a := range accepted {
+			if mt == a || (a == "application/yaml" && isYAML(mt)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Accept", strings.Join(accepted, ", "))
+		http.Error(
+			w, fmt.Sprintf(
+				"unsupported content type %q, supported types: %s", r.Header.Get("Content-Type"),
+				strings.Join(accepted, ", "),
+			), http.StatusUnsupportedMediaType,
+		)
+	}
+}
+
+// yamlToJSON converts a YAML document to JSON so it can be decoded into the same
+// structs as a JSON body
+func yamlToJSON(r *http.Request) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.NewDecoder(r.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// jsonToYAML re-encodes a JSON document as YAML with the same keys, so a YAML export
+// imports exactly like the JSON one
+func jsonToYAML(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
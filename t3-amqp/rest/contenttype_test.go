@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentTypeMiddleware(t *testing.T) {
+	var got struct {
+		Name       string `json:"name"`
+		SchemaData string `json:"schemaData"`
+	}
+	handler := ContentTypeMiddleware(
+		StructuredMediaTypes, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if decodeJSON(w, r, &got) {
+				w.WriteHeader(http.StatusOK)
+			}
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader("name: b\nschemaData: '{}'\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "b", got.Name)
+	assert.Equal(t, "{}", got.SchemaData)
+
+	req = httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader("name=c"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Contains(t, rr.Header().Get("Accept"), "application/json")
+}
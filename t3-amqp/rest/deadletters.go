@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"t3-amqp/amqp"
+	"t3-amqp/db"
+)
+
+// DeadLetterInspector validates the messages of a dead letter queue and requeues the ones
+// that conform, amqp.DeadLetters implements it
+type DeadLetterInspector interface {
+	Inspect(ctx context.Context, req amqp.DeadLetterRequest) (*amqp.DeadLetterReport, error)
+}
+
+// DeadLettersHandler inspects the dead letter queue of the request and answers with a
+// summary of why its messages were dead lettered and why they fail validation. With
+// requeue the messages that conform by now are published to their original topic. Queues
+// are namespaced like schema names, without a broker connection it answers 503.
+func DeadLettersHandler(inspector DeadLetterInspector) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if inspector == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var req amqp.DeadLetterRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if err := req.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !db.NamespaceAllowed(callerNamespaces(r), req.Queue) {
+			http.Error(w, db.ErrNamespaceDenied.Error(), http.StatusForbidden)
+			return
+		}
+
+		report, err := inspector.Inspect(r.Context(), req)
+		if errors.Is(err, amqp.ErrQueueNotFound) {
+			writeError(w, r, err, "failed to inspect dead letter queue")
+			return
+		}
+		if err != nil {
+			inspected := 0
+			if report != nil {
+				inspected = report.Inspected
+			}
+			log.Printf("Failed to inspect %s after %d messages: %v", req.Queue, inspected, err)
+			http.Error(w, fmt.Sprintf("inspection failed after %d messages", inspected), http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(report)
+		if err != nil {
+			return
+		}
+	}
+}
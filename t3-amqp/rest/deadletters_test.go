@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/amqp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeInspector struct{}
+
+func (fakeInspector) Inspect(_ context.Context, req amqp.DeadLetterRequest) (*amqp.DeadLetterReport, error) {
+	switch req.Queue {
+	case "missing":
+		return nil, fmt.Errorf("error reading missing: %w", amqp.ErrQueueNotFound)
+	case "broken":
+		return &amqp.DeadLetterReport{Queue: req.Queue, Inspected: 2}, errors.New("channel closed")
+	}
+	return &amqp.DeadLetterReport{Queue: req.Queue, Inspected: 1, Valid: 1, Requeued: 1}, nil
+}
+
+func TestDeadLettersHandler(t *testing.T) {
+	do := func(inspector DeadLetterInspector, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		DeadLettersHandler(inspector)(w, httptest.NewRequest(http.MethodPost, "/deadletters", strings.NewReader(body)))
+		return w
+	}
+
+	w := do(fakeInspector{}, `{"queue":"orders.dlq","requeue":true}`)
+	if assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+		var report amqp.DeadLetterReport
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.Equal(t, 1, report.Requeued)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(fakeInspector{}, `{"max":5}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(fakeInspector{}, `{"queue":"missing"}`).Code)
+	assert.Equal(t, http.StatusBadGateway, do(fakeInspector{}, `{"queue":"broken"}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, do(nil, `{"queue":"orders.dlq"}`).Code)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"t3-amqp/db"
+	"t3-amqp/diff"
+	"t3-amqp/validate"
+)
+
+type DiffResponse struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	diff.Diff
+}
+
+// SchemaDiffHandler compares two versions of the schema given by the name and type query
+// parameters, from and to naming the versions or latest, and lists the fields the newer
+// one adds, removes and changes
+func SchemaDiffHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		name, schemaType := query.Get("name"), query.Get("type")
+		if name == "" || schemaType == "" || query.Get("from") == "" || query.Get("to") == "" {
+			http.Error(w, "name, type, from and to are required", http.StatusBadRequest)
+			return
+		}
+		from, err := schemaVersion(store, name, schemaType, query.Get("from"))
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		to, err := schemaVersion(store, name, schemaType, query.Get("to"))
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+
+		d, err := diff.Compare(schemaType, from.SchemaData, to.SchemaData)
+		switch {
+		case errors.Is(err, validate.ErrUnsupportedType):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		setLinkHeader(w, schemaLinks(from.Name, from.Type))
+		response := DiffResponse{Name: from.Name, Type: from.Type, From: from.Version, To: to.Version, Diff: *d}
+		writeCacheable(w, r, response, lastModified([]db.Schema{*from, *to}))
+	}
+}
+
+// schemaVersion returns one version of a schema, latest resolving to the newest one
+func schemaVersion(store db.SchemaStore, name, schemaType, version string) (*db.Schema, error) {
+	if version == db.LatestVersion {
+		return store.Latest(name, schemaType)
+	}
+	schemas, err := store.Filter(db.QueryArgs{Name: name, Type: schemaType, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s/%s", db.ErrSchemaNotFound, name, schemaType, version)
+	}
+	return &schemas[0], nil
+}
@@ -0,0 +1,52 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/diff"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaDiffHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	schemas := map[string]string{
+		"1.0.0": `{"type":"object","required":["id"],"properties":{"id":{"type":"integer"},"note":{"type":"string"}}}`,
+		"2.0.0": `{
+			"type": "object",
+			"required": ["id", "total"],
+			"properties": {"id": {"type": "string"}, "total": {"type": "number"}}
+		}`,
+	}
+	for version, data := range schemas {
+		_, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: version, SchemaData: data})
+		assert.NoError(t, err)
+	}
+	_, err := store.Insert(db.QueryArgs{Name: "orders", Type: "xsd", Version: "1.0.0", SchemaData: "<xs:schema/>"})
+	assert.NoError(t, err)
+	handler := rest.SchemaDiffHandler(store)
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	rr := get("/schema/diff?name=orders&type=json&from=1.0.0&to=latest")
+	if !assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String()) {
+		return
+	}
+	var response rest.DiffResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "2.0.0", response.To, "latest resolves to the newest version")
+	assert.Equal(t, []diff.Field{{Path: "/total", Type: "number", Required: true}}, response.Added)
+	assert.Equal(t, []diff.Field{{Path: "/note", Type: "string"}}, response.Removed)
+	assert.Equal(t, []diff.Change{{Path: "/id", Kind: diff.KindType, From: "integer", To: "string"}}, response.Changed)
+
+	assert.Equal(t, http.StatusNotFound, get("/schema/diff?name=orders&type=json&from=1.0.0&to=3.0.0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/schema/diff?name=orders&type=json&from=1.0.0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/schema/diff?name=orders&type=xsd&from=1.0.0&to=1.0.0").Code)
+}
@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/validate"
+)
+
+// errorStatus maps the error categories of the db package, schema validation failures,
+// invalid request bodies and quota refusals to a status code
+func errorStatus(err error) int {
+	var schemaErr *validate.SchemaError
+	var requestErr *RequestError
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrConflict), errors.Is(err, quota.ErrSchemaQuota):
+		return http.StatusConflict
+	case errors.As(err, &schemaErr):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &requestErr):
+		return requestErr.status()
+	case errors.Is(err, db.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, db.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, quota.ErrRunQuota):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError answers err as a problem+json body with the status errorStatus picks,
+// listing the invalid fields of a *RequestError. Unexpected errors are described by
+// fallback so internals never leak.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	status := errorStatus(err)
+	detail := err.Error()
+	switch status {
+	case http.StatusInternalServerError:
+		detail = fallback
+	case http.StatusServiceUnavailable:
+		detail = db.ErrUnavailable.Error()
+	}
+
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: RequestID(r.Context()),
+	}
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		problem.Errors = requestErr.Fields
+	}
+	writeProblem(w, problem)
+}
+
+// writeStatusProblem answers status with detail as a problem+json body
+func writeStatusProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblem(
+		w, Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    detail,
+			Instance:  r.URL.Path,
+			RequestID: RequestID(r.Context()),
+		},
+	)
+}
+
+// errorRecorder holds back plain text error responses written with http.Error so
+// ErrorMiddleware can answer them as problem+json instead
+type errorRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (er *errorRecorder) WriteHeader(status int) {
+	if er.status != 0 {
+		return
+	}
+	h := er.Header()
+	if status >= 400 && strings.HasPrefix(h.Get("Content-Type"), "text/plain") {
+		er.status = status
+		return
+	}
+	er.status = -1
+	er.ResponseWriter.WriteHeader(status)
+}
+
+func (er *errorRecorder) Write(b []byte) (int, error) {
+	if er.status == 0 {
+		er.WriteHeader(http.StatusOK)
+	}
+	if er.status > 0 {
+		return er.body.Write(b)
+	}
+	return er.ResponseWriter.Write(b)
+}
+
+func (er *errorRecorder) Flush() {
+	if er.status == 0 {
+		er.WriteHeader(http.StatusOK)
+	}
+	if er.status > 0 {
+		return
+	}
+	if f, ok := er.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (er *errorRecorder) Unwrap() http.ResponseWriter {
+	return er.ResponseWriter
+}
+
+// ErrorMiddleware makes every error answer a problem+json body like writeError's,
+// rewriting the plain text ones handlers produce with http.Error. Other responses,
+// including the Confluent routes' own error format, pass through untouched.
+func ErrorMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		er := &errorRecorder{ResponseWriter: w}
+		next.ServeHTTP(er, r)
+		if er.status <= 0 {
+			return
+		}
+
+		w.Header().Del("Content-Length")
+		writeStatusProblem(w, r, er.status, strings.TrimSpace(er.body.String()))
+	}
+}
@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatus(t *testing.T) {
+	cases := map[error]int{
+		fmt.Errorf("error getting schema: %w", db.ErrSchemaNotFound): http.StatusNotFound,
+		db.ErrAliasNotFound:   http.StatusNotFound,
+		db.ErrNamespaceDenied: http.StatusForbidden,
+		db.ErrAlreadyExists:   http.StatusConflict,
+		errDuplicateSchema:    http.StatusConflict,
+		quota.ErrSchemaQuota:  http.StatusConflict,
+		quota.ErrRunQuota:     http.StatusTooManyRequests,
+		db.ErrInvalidSort:     http.StatusBadRequest,
+		db.ErrInvalidSchedule: http.StatusBadRequest,
+		db.NewError(db.ErrTooLarge, "schema is too large"):                    http.StatusRequestEntityTooLarge,
+		&RequestError{Fields: []FieldError{{Rule: "required"}}}:               http.StatusBadRequest,
+		&RequestError{Fields: []FieldError{{Rule: "name"}, {Rule: "schema"}}}: http.StatusRequestEntityTooLarge,
+		fmt.Errorf("%w: dial tcp: connection refused", db.ErrUnavailable):     http.StatusServiceUnavailable,
+		fmt.Errorf("boom"): http.StatusInternalServerError,
+	}
+	for err, status := range cases {
+		assert.Equal(t, status, errorStatus(err), err.Error())
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	serve := func(err error) Problem {
+		req := httptest.NewRequest(http.MethodGet, "/schema/7", nil)
+		rr := httptest.NewRecorder()
+		RequestIDMiddleware(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, r, err, "failed to retrieve schema")
+			}),
+		).ServeHTTP(rr, req)
+
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+		var problem Problem
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+		assert.Equal(t, rr.Code, problem.Status)
+		assert.Equal(t, "/schema/7", problem.Instance)
+		assert.NotEmpty(t, problem.RequestID)
+		return problem
+	}
+
+	problem := serve(fmt.Errorf("error getting schema: %w", db.ErrSchemaNotFound))
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, "error getting schema: schema not found", problem.Detail)
+
+	problem = serve(fmt.Errorf("%w: dial tcp 10.0.0.5:5432: connection refused", db.ErrUnavailable))
+	assert.Equal(t, http.StatusServiceUnavailable, problem.Status)
+	assert.Equal(t, "database unavailable", problem.Detail)
+
+	problem = serve(fmt.Errorf("syntax error at or near SELEKT"))
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Equal(t, "failed to retrieve schema", problem.Detail)
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "name is required", http.StatusBadRequest)
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		writeConfluentError(w, http.StatusNotFound, confluentSubjectNotFound, "Subject not found.")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("fine"))
+	})
+	handler := ErrorMiddleware(mux)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/plain", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+	assert.Equal(t, "name is required", problem.Detail)
+	assert.Equal(t, "Bad Request", problem.Title)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/json", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"error_code":40401`)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "fine", rr.Body.String())
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"context"
+	"sync"
+	"t3-amqp/amqp"
+	"t3-amqp/scenario"
+	"time"
+)
+
+var (
+	flowMu       sync.RWMutex
+	flowMgmt     *amqp.ManagementClient
+	flowInterval time.Duration
+)
+
+// SetManagementClient makes scenario runs and load tests watch the broker through the
+// management API, polling every interval, and report when it throttled publishers. With
+// a nil client runs are not watched.
+func SetManagementClient(mgmt *amqp.ManagementClient, interval time.Duration) {
+	flowMu.Lock()
+	defer flowMu.Unlock()
+	flowMgmt, flowInterval = mgmt, interval
+}
+
+// watchFlow starts a flow monitor for a run about to start. The returned function stops
+// it and annotates the report of the run with the intervals the broker throttled.
+func watchFlow(ctx context.Context) func(report *scenario.Report) {
+	flowMu.RLock()
+	mgmt, interval := flowMgmt, flowInterval
+	flowMu.RUnlock()
+	if mgmt == nil {
+		return func(*scenario.Report) {}
+	}
+
+	monitor := amqp.NewFlowMonitor(mgmt, interval)
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.Run(ctx)
+	}()
+	return func(report *scenario.Report) {
+		cancel()
+		<-done
+		report.AnnotateThrottling(monitor.Intervals())
+	}
+}
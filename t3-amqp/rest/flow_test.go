@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/amqp"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunScenarioRecordsThrottling(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/nodes":
+				_, _ = w.Write([]byte(`[{"name":"rabbit@a","mem_alarm":true,"disk_free_alarm":false}]`))
+			case "/api/connections":
+				_, _ = w.Write([]byte(`[]`))
+			}
+		}),
+	)
+	defer server.Close()
+	SetManagementClient(amqp.NewManagementClient(server.URL, "guest", "guest"), 5*time.Millisecond)
+	t.Cleanup(func() { SetManagementClient(nil, 0) })
+
+	store := db.NewMemoryStore()
+	run, err := store.CreateRun(db.TestRun{RunID: "run-1", Status: db.RunRunning, Started: time.Now().UTC()})
+	if !assert.NoError(t, err) {
+		return
+	}
+	runner := runnerFunc(func(_ context.Context, runID string, insts []scenario.Instance) scenario.Report {
+		started := time.Now()
+		time.Sleep(30 * time.Millisecond)
+		return scenario.Report{
+			RunID: runID, Passed: 1,
+			Results: []scenario.Result{{Instance: insts[0], Started: started, Duration: time.Since(started)}},
+		}
+	})
+	runScenario(context.Background(), store, runner, scenario.Scenario{Name: "smoke", MessageCount: 1}, *run)
+
+	finished, err := store.RunByID(run.ID)
+	if assert.NoError(t, err) && assert.Len(t, finished.Results, 1) {
+		assert.Contains(t, string(finished.Results[0].Details), "memory alarm on rabbit@a")
+	}
+}
@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/payload"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// maxGenerate caps how many messages one generate request produces
+const maxGenerate = 10000
+
+// Generate modes, invalid messages each break one rule of the schema
+const (
+	GenerateValid   = scenario.ModeValid
+	GenerateInvalid = scenario.ModeInvalid
+)
+
+// InvalidPublisher sends payloads without validating them, tagged with the rule they break
+type InvalidPublisher interface {
+	PublishInvalid(ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte, violation string) error
+}
+
+type GenerateRequest struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	// Topic publishes the messages, without it they are returned instead
+	Topic string `json:"topic,omitempty"`
+	Count int    `json:"count"`
+	// Mode is valid, the default, or invalid for messages that fail validation
+	Mode string `json:"mode,omitempty"`
+	// Seed makes the messages reproducible, a random seed is used and returned when unset
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+type GenerateResponse struct {
+	Schema    scenario.SchemaRef `json:"schema"`
+	Topic     string             `json:"topic,omitempty"`
+	Seed      int64              `json:"seed"`
+	Mode      string             `json:"mode"`
+	Published int                `json:"published"`
+	Messages  []json.RawMessage  `json:"messages,omitempty"`
+	// Violations holds the rule each invalid message breaks, in the order of Messages
+	Violations []payload.Violation `json:"violations,omitempty"`
+}
+
+// GenerateHandler generates random payloads valid against a JSON schema for load and
+// contract testing, or in invalid mode payloads breaking one of its rules to test error
+// handling. With a topic they are published one by one, stopping at the first failure,
+// otherwise they are returned. Publishing without a broker connection gets a 503.
+func GenerateHandler(store db.SchemaStore, publisher Publisher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req GenerateRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Name == "" || req.Type == "" || req.Version == "" {
+			http.Error(w, "name, type and version are required", http.StatusBadRequest)
+			return
+		}
+		if req.Type != "json" {
+			http.Error(w, "payloads can only be generated for json schemas", http.StatusBadRequest)
+			return
+		}
+		if req.Count == 0 {
+			req.Count = 1
+		}
+		if req.Mode == "" {
+			req.Mode = GenerateValid
+		}
+		if req.Mode != GenerateValid && req.Mode != GenerateInvalid {
+			http.Error(w, "mode must be valid or invalid", http.StatusBadRequest)
+			return
+		}
+		if req.Count < 0 || req.Count > maxGenerate {
+			http.Error(w, fmt.Sprintf("count must be between 1 and %d", maxGenerate), http.StatusBadRequest)
+			return
+		}
+		if req.Topic != "" && publisher == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		invalidPublisher, canPublishInvalid := publisher.(InvalidPublisher)
+		if req.Topic != "" && req.Mode == GenerateInvalid && !canPublishInvalid {
+			http.Error(w, "publisher cannot send invalid messages", http.StatusNotImplemented)
+			return
+		}
+
+		schemas, err := store.Filter(db.QueryArgs{Name: req.Name, Type: req.Type, Version: req.Version})
+		if err != nil {
+			http.Error(w, "failed to retrieve schema", http.StatusInternalServerError)
+			return
+		}
+		if len(schemas) == 0 {
+			http.Error(w, "schema not found", http.StatusNotFound)
+			return
+		}
+		setLifecycleHeaders(w, schemas[0])
+		if schemas[0].Status == db.StatusRetired {
+			http.Error(w, "schema has been retired", http.StatusGone)
+			return
+		}
+
+		seed := time.Now().UnixNano()
+		if req.Seed != nil {
+			seed = *req.Seed
+		}
+		gen, err := broker.NewGenerator(schemas[0], req.Mode, seed)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		ref := scenario.SchemaRef{Name: req.Name, Type: req.Type, Version: req.Version, Tenant: requestTenant(r)}
+		response := GenerateResponse{Schema: ref, Topic: req.Topic, Seed: seed, Mode: req.Mode}
+		for i := 0; i < req.Count; i++ {
+			data, violation, err := gen()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			if req.Topic == "" {
+				response.Messages = append(response.Messages, data)
+				if violation != nil {
+					response.Violations = append(response.Violations, *violation)
+				}
+				continue
+			}
+
+			if violation != nil {
+				err = invalidPublisher.PublishInvalid(r.Context(), req.Topic, ref, data, violation.String())
+			} else {
+				err = publisher.Publish(r.Context(), req.Topic, ref, data)
+			}
+			switch {
+			case errors.Is(err, broker.ErrInvalidPayload):
+				msg := fmt.Sprintf("generated message %d does not match schema, %d published: %v", i, i, err)
+				http.Error(w, msg, http.StatusUnprocessableEntity)
+				return
+			case err != nil:
+				msg := fmt.Sprintf("failed to publish message %d, %d published", i, i)
+				http.Error(w, msg, http.StatusBadGateway)
+				return
+			}
+			response.Published++
+		}
+
+		status := http.StatusOK
+		if req.Topic != "" {
+			status = http.StatusAccepted
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingPublisher keeps what it publishes and fails from message failAt on when set
+type recordingPublisher struct {
+	failAt     int
+	payloads   [][]byte
+	violations []string
+}
+
+func (p *recordingPublisher) PublishInvalid(
+	ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte, violation string,
+) error {
+	p.violations = append(p.violations, violation)
+	return p.Publish(ctx, topic, ref, payload)
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, _ string, _ scenario.SchemaRef, payload []byte) error {
+	if p.failAt > 0 && len(p.payloads) >= p.failAt {
+		return fmt.Errorf("channel closed")
+	}
+	p.payloads = append(p.payloads, payload)
+	return nil
+}
+
+const eventSchema = `{
+	"type": "object",
+	"required": ["id", "kind"],
+	"properties": {"id": {"type": "integer", "minimum": 1}, "kind": {"enum": ["created", "deleted"]}}
+}`
+
+func TestGenerateHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema})
+	assert.NoError(t, err)
+	_, err = store.Insert(db.QueryArgs{Name: "broken", Type: "json", Version: "1.0.0", SchemaData: `{"enum": []}`})
+	assert.NoError(t, err)
+	validator, err := validate.Compile("json", eventSchema)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	post := func(publisher Publisher, body string) (*httptest.ResponseRecorder, GenerateResponse) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/generate", strings.NewReader(body))
+		GenerateHandler(store, publisher).ServeHTTP(w, r)
+		var response GenerateResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response
+	}
+
+	w, response := post(nil, `{"name":"events","type":"json","version":"1.0.0","count":20,"seed":3}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), response.Seed)
+	assert.Len(t, response.Messages, 20)
+	for _, message := range response.Messages {
+		assert.NoError(t, validator.Validate(message))
+	}
+	_, again := post(nil, `{"name":"events","type":"json","version":"1.0.0","count":20,"seed":3}`)
+	assert.Equal(t, response.Messages, again.Messages, "a seed should reproduce the messages")
+
+	publisher := &recordingPublisher{}
+	w, response = post(publisher, `{"name":"events","type":"json","version":"1.0.0","count":5,"topic":"events.test"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, 5, response.Published)
+	assert.Empty(t, response.Messages)
+	assert.Len(t, publisher.payloads, 5)
+
+	w, _ = post(&recordingPublisher{failAt: 2}, `{"name":"events","type":"json","version":"1.0.0","count":5,"topic":"t"}`)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "2 published")
+
+	w, response = post(nil, `{"name":"events","type":"json","version":"1.0.0","count":50,"mode":"invalid"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, GenerateInvalid, response.Mode)
+	if assert.Len(t, response.Violations, 50) {
+		for i, message := range response.Messages {
+			assert.Error(t, validator.Validate(message), "%s should break %s", message, response.Violations[i])
+		}
+	}
+
+	publisher = &recordingPublisher{}
+	body := `{"name":"events","type":"json","version":"1.0.0","count":3,"topic":"t","mode":"invalid"}`
+	w, response = post(publisher, body)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, 3, response.Published)
+	assert.Len(t, publisher.violations, 3)
+
+	tests := []struct {
+		name      string
+		publisher Publisher
+		body      string
+		want      int
+	}{
+		{"missing version", nil, `{"name":"events","type":"json"}`, http.StatusBadRequest},
+		{"not json", nil, `{"name":"events","type":"avro","version":"1.0.0"}`, http.StatusBadRequest},
+		{"too many", nil, `{"name":"events","type":"json","version":"1.0.0","count":10001}`, http.StatusBadRequest},
+		{"no broker", nil, `{"name":"events","type":"json","version":"1.0.0","topic":"t"}`, http.StatusServiceUnavailable},
+		{"unknown mode", nil, `{"name":"events","type":"json","version":"1.0.0","mode":"fuzzy"}`, http.StatusBadRequest},
+		{
+			"validating publisher", &stubPublisher{},
+			`{"name":"events","type":"json","version":"1.0.0","topic":"t","mode":"invalid"}`, http.StatusNotImplemented,
+		},
+		{"unknown schema", nil, `{"name":"orders","type":"json","version":"1.0.0"}`, http.StatusNotFound},
+		{"impossible schema", nil, `{"name":"broken","type":"json","version":"1.0.0"}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := post(tt.publisher, tt.body)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"t3-amqp/auth"
+	"t3-amqp/auth/oidc"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/registrypb"
+	"t3-amqp/validate"
+	"time"
+)
+
+// GRPCStore is what the gRPC service needs from the registry store
+type GRPCStore interface {
+	db.SchemaStore
+	db.TenantStore
+	db.ScenarioStore
+	db.RunStore
+}
+
+// GRPCRegistry serves the registry over gRPC with the checks of the REST API: the same
+// credentials, tenants, namespaces, schema quota and server mode apply to every call
+type GRPCRegistry struct {
+	registrypb.UnimplementedRegistryServer
+	store      GRPCStore
+	runner     ScenarioRunner
+	validators *validate.Pool
+	auth       *auth.Authenticator
+	quotas     *quota.Manager
+	modes      *ModeController
+}
+
+// NewGRPCRegistry creates the gRPC service. Without runner RunScenario is unavailable.
+func NewGRPCRegistry(
+	store GRPCStore, runner ScenarioRunner, validators *validate.Pool, a *auth.Authenticator, quotas *quota.Manager,
+	modes *ModeController,
+) *GRPCRegistry {
+	return &GRPCRegistry{
+		store: store, runner: runner, validators: validators, auth: a, quotas: quotas, modes: modes,
+	}
+}
+
+// NewGRPCServer creates a server for registry with reflection enabled, opts such as TLS
+// credentials are passed on to grpc.NewServer
+func NewGRPCServer(registry *GRPCRegistry, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(
+		opts, grpc.ChainUnaryInterceptor(registry.unaryInterceptor),
+		grpc.ChainStreamInterceptor(registry.streamInterceptor),
+	)
+	server := grpc.NewServer(opts...)
+	registrypb.RegisterRegistryServer(server, registry)
+	reflection.Register(server)
+	return server
+}
+
+// grpcWrites are the calls that change the registry or start test runs
+var grpcWrites = map[string]bool{
+	registrypb.Registry_RegisterSchema_FullMethodName: true,
+	registrypb.Registry_RunScenario_FullMethodName:    true,
+}
+
+// grpcStatus converts err to a status with the code matching the REST status errorStatus
+// picks. Unexpected errors are described by fallback so internals never leak.
+func grpcStatus(err error, fallback string) error {
+	if errors.Is(err, quota.ErrSchemaQuota) || errors.Is(err, quota.ErrRunQuota) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	switch errorStatus(err) {
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case http.StatusConflict:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case http.StatusServiceUnavailable:
+		return status.Error(codes.Unavailable, db.ErrUnavailable.Error())
+	default:
+		return status.Error(codes.Internal, fallback)
+	}
+}
+
+// metadataValue returns the first value of key in md, empty when it is missing
+func metadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcKey reads the key from x-api-key or an authorization bearer token like presentedKey
+func grpcKey(md metadata.MD) string {
+	if key := metadataValue(md, "x-api-key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(metadataValue(md, "authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// authorize applies the server mode, authentication and tenant selection of the REST
+// middleware to a call, returning the context the call runs in. Reflection is public
+// like the OpenAPI document.
+func (g *GRPCRegistry) authorize(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, "/"+registrypb.Registry_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+
+	write := grpcWrites[method]
+	if mode, _ := g.modes.Mode(); mode == ModeMaintenance || (mode == ModeReadOnly && write) {
+		return nil, status.Errorf(codes.Unavailable, "server is in %s mode", mode)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	tenant := metadataValue(md, strings.ToLower(TenantHeader))
+	if g.auth.Enabled() {
+		principal, err := g.auth.Authenticate(ctx, grpcKey(md))
+		switch {
+		case errors.Is(err, auth.ErrMissingKey), errors.Is(err, auth.ErrInvalidKey),
+			errors.Is(err, oidc.ErrInvalidToken):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, oidc.ErrKeysUnavailable):
+			log.Printf("Failed to verify bearer token: %v", err)
+			return nil, status.Error(codes.Unavailable, oidc.ErrKeysUnavailable.Error())
+		case err != nil:
+			return nil, grpcStatus(err, "failed to check api key")
+		}
+		required := auth.ScopeRead
+		if write {
+			required = auth.ScopeReadWrite
+		}
+		if !auth.Allows(principal.Scope, required) {
+			return nil, status.Error(codes.PermissionDenied, "credentials do not allow "+required+" requests")
+		}
+		ctx = context.WithValue(ctx, principalKey{}, principal)
+
+		if principal.Tenant != "" {
+			if tenant != "" && tenant != principal.Tenant {
+				return nil, grpcStatus(fmt.Errorf("%s: %w", tenant, db.ErrTenantDenied), "")
+			}
+			tenant = principal.Tenant
+		}
+	}
+	if tenant == "" {
+		tenant = db.DefaultTenant
+	}
+	if _, err := g.store.TenantByID(tenant); err != nil {
+		return nil, grpcStatus(err, "failed to check tenant")
+	}
+	return context.WithValue(ctx, tenantKey{}, tenant), nil
+}
+
+func (g *GRPCRegistry) unaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := g.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authorizedStream runs a stream in the context authorize returned
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (g *GRPCRegistry) streamInterceptor(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	ctx, err := g.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+// scopedStore limits the store to the tenant of the call and the namespaces of the caller
+func (g *GRPCRegistry) scopedStore(ctx context.Context) db.SchemaStore {
+	return db.RestrictNamespaces(db.ForTenant(g.store, Tenant(ctx)), principalNamespaces(ctx))
+}
+
+// recordInsert writes the audit entry of a schema registered over gRPC, failures are
+// logged rather than failing the call
+func recordInsert(ctx context.Context, store db.SchemaStore, schema *db.Schema) {
+	auditor, ok := store.(db.AuditRecorder)
+	if !ok {
+		return
+	}
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	entry := db.AuditEntry{
+		Action: db.AuditActionInsert, SchemaID: schema.ID, SchemaName: schema.Name, After: db.Snapshot(schema),
+		Actor: "ip:" + ip, SourceIP: ip, Tenant: Tenant(ctx),
+	}
+	if principal := Principal(ctx); principal != nil {
+		entry.Actor = principal.ID
+	}
+	if err := auditor.RecordAudit(entry); err != nil {
+		log.Printf("failed to record audit entry: %v", err)
+	}
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func schemaMessage(schema db.Schema) *registrypb.Schema {
+	return &registrypb.Schema{
+		Id:          int64(schema.ID),
+		Name:        schema.Name,
+		Type:        schema.Type,
+		Version:     schema.Version,
+		SchemaData:  schema.SchemaData,
+		Status:      schema.Status,
+		Fingerprint: schema.Fingerprint,
+		Namespace:   schema.Namespace,
+		Tenant:      schema.Tenant,
+		Created:     timestamppb.New(schema.Created),
+		Modified:    timestamppb.New(schema.Modified),
+		DeprecateAt: timestamp(schema.DeprecateAt),
+		RetireAt:    timestamp(schema.RetireAt),
+		DeletedAt:   timestamp(schema.DeletedAt),
+	}
+}
+
+func runMessage(run db.TestRun) *registrypb.TestRun {
+	message := &registrypb.TestRun{
+		Id:            int64(run.ID),
+		RunId:         run.RunID,
+		ScenarioName:  run.ScenarioName,
+		Topic:         run.Topic,
+		SchemaName:    run.SchemaName,
+		Status:        run.Status,
+		Started:       timestamppb.New(run.Started),
+		Finished:      timestamp(run.Finished),
+		MessagesSent:  int64(run.MessagesSent),
+		MessagesValid: int64(run.MessagesValid),
+		Failures:      int64(run.Failures),
+	}
+	if run.ScenarioID != nil {
+		message.ScenarioId = int64(*run.ScenarioID)
+	}
+	return message
+}
+
+// RegisterSchema registers a schema version like POST /schema. Versions that are taken
+// are answered with AlreadyExists and a full tenant with ResourceExhausted.
+func (g *GRPCRegistry) RegisterSchema(
+	ctx context.Context, req *registrypb.RegisterSchemaRequest,
+) (*registrypb.Schema, error) {
+	entry := SchemaRequest{Name: req.Name, Type: req.Type, Version: req.Version, SchemaData: req.SchemaData}
+	if err := checkBinding(entry); err != nil {
+		return nil, grpcStatus(err, "")
+	}
+	store := g.scopedStore(ctx)
+	if g.quotas.Config().MaxSchemas > 0 {
+		count, err := db.ForTenant(g.store, Tenant(ctx)).Count(false)
+		if err != nil {
+			return nil, grpcStatus(err, "failed to check schema quota")
+		}
+		if err := g.quotas.CheckSchemaCount(count); err != nil {
+			return nil, grpcStatus(err, "failed to check schema quota")
+		}
+	}
+
+	params := db.QueryArgs{Name: req.Name, Type: req.Type, Version: req.Version, SchemaData: req.SchemaData}
+	if err := prepareSchema(store, &params); err != nil {
+		return nil, grpcStatus(err, "failed to check schema")
+	}
+	id, err := store.Insert(params)
+	if err != nil {
+		return nil, grpcStatus(err, "failed to insert schema")
+	}
+	schema, err := store.GetByID(id)
+	if err != nil {
+		return nil, grpcStatus(err, "failed to retrieve schema")
+	}
+	recordInsert(ctx, store, schema)
+	return schemaMessage(*schema), nil
+}
+
+// GetSchema returns the schema with the id of the request, otherwise the named version
+// or the latest version of the name and type
+func (g *GRPCRegistry) GetSchema(ctx context.Context, req *registrypb.GetSchemaRequest) (*registrypb.Schema, error) {
+	store := g.scopedStore(ctx)
+	var schema *db.Schema
+	var err error
+	switch {
+	case req.Id > 0:
+		schema, err = store.GetByID(int(req.Id))
+	case req.Name == "" || req.Type == "":
+		return nil, status.Error(codes.InvalidArgument, "id or name and type are required")
+	case req.Version != "":
+		var schemas []db.Schema
+		schemas, err = store.Filter(db.QueryArgs{Name: req.Name, Type: req.Type, Version: req.Version})
+		if err == nil && len(schemas) == 0 {
+			err = db.ErrSchemaNotFound
+		}
+		if err == nil {
+			schema = &schemas[0]
+		}
+	default:
+		schema, err = store.Latest(req.Name, req.Type)
+	}
+	if err != nil {
+		return nil, grpcStatus(err, "failed to retrieve schema")
+	}
+	return schemaMessage(*schema), nil
+}
+
+// ListSchemas streams the schemas page by page like GET /schemas, a limit of 0 streams
+// every schema
+func (g *GRPCRegistry) ListSchemas(
+	req *registrypb.ListSchemasRequest, stream grpc.ServerStreamingServer[registrypb.Schema],
+) error {
+	if req.Limit < 0 || req.Offset < 0 {
+		return status.Error(codes.InvalidArgument, "limit and offset must not be negative")
+	}
+	opts := db.ListOptions{
+		Limit: int(req.Limit), Offset: int(req.Offset), Sort: req.Sort, IncludeDeleted: req.IncludeDeleted,
+	}
+	sent := false
+	err := g.scopedStore(stream.Context()).List(
+		opts, func(schema db.Schema) error {
+			sent = true
+			return stream.Send(schemaMessage(schema))
+		},
+	)
+	if err != nil {
+		if sent {
+			log.Printf("failed to stream schemas: %v", err)
+		}
+		return grpcStatus(err, "failed to retrieve schemas")
+	}
+	return nil
+}
+
+// ValidateMessage validates a payload against a registered schema like POST /validate.
+// Retired schemas are answered with FailedPrecondition and a saturated validation pool
+// with ResourceExhausted.
+func (g *GRPCRegistry) ValidateMessage(
+	ctx context.Context, req *registrypb.ValidateMessageRequest,
+) (*registrypb.ValidateMessageResponse, error) {
+	if req.Name == "" || req.Type == "" || req.Version == "" || len(req.Payload) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name, type, version and payload are required")
+	}
+	schemas, err := g.scopedStore(ctx).Filter(db.QueryArgs{Name: req.Name, Type: req.Type, Version: req.Version})
+	if err != nil {
+		return nil, grpcStatus(err, "failed to retrieve schema")
+	}
+	if len(schemas) == 0 {
+		return nil, status.Error(codes.NotFound, "schema not found")
+	}
+	if schemas[0].Status == db.StatusRetired {
+		return nil, status.Error(codes.FailedPrecondition, "schema has been retired")
+	}
+
+	validator, err := validate.DefaultCache.Validator(schemas[0])
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	response := &registrypb.ValidateMessageResponse{Valid: true, SchemaId: int64(schemas[0].ID)}
+	err = g.validators.Validate(ctx, validator, req.Payload)
+	switch {
+	case errors.Is(err, validate.ErrOverloaded):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, validate.ErrPoolClosed):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		response.Valid = false
+		response.Error = err.Error()
+	}
+	return response, nil
+}
+
+// RunScenario starts a run of a stored scenario like POST /scenarios/{id}/run. With wait
+// the call returns once the run finished, otherwise as soon as it started. Without a
+// broker connection it answers Unavailable.
+func (g *GRPCRegistry) RunScenario(
+	ctx context.Context, req *registrypb.RunScenarioRequest,
+) (*registrypb.TestRun, error) {
+	if g.runner == nil {
+		return nil, status.Error(codes.Unavailable, "broker unavailable")
+	}
+	stored, err := g.store.ScenarioByID(int(req.ScenarioId))
+	if err == nil &&
+		(stored.Tenant != Tenant(ctx) || !db.NamespaceAllowed(principalNamespaces(ctx), stored.SchemaName)) {
+		err = db.ErrScenarioNotFound
+	}
+	if err != nil {
+		return nil, grpcStatus(err, "failed to retrieve scenario")
+	}
+	release, err := acquireRun(g.quotas, stored.Tenant)
+	if err != nil {
+		return nil, grpcStatus(err, "failed to start test run")
+	}
+	s, run, err := startRun(g.store, *stored)
+	if err != nil {
+		release()
+		return nil, grpcStatus(err, "failed to record test run")
+	}
+
+	if !req.Wait {
+		// The run outlives the call
+		go func() {
+			defer release()
+			runScenario(context.WithoutCancel(ctx), g.store, g.runner, s, *run)
+		}()
+		return runMessage(*run), nil
+	}
+	runScenario(ctx, g.store, g.runner, s, *run)
+	release()
+	finished, err := g.store.RunByID(run.ID)
+	if err != nil {
+		return nil, grpcStatus(err, "failed to retrieve test run")
+	}
+	return runMessage(*finished), nil
+}
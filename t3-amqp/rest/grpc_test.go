@@ -0,0 +1,179 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/registrypb"
+	"t3-amqp/scenario"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPCRegistry(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.CreateTenant(db.Tenant{ID: "acme", Name: "Acme"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	runner := runnerFunc(func(ctx context.Context, runID string, instances []scenario.Instance) scenario.Report {
+		return scenario.Report{RunID: runID, Instances: len(instances), Passed: len(instances), MessagesSent: 10}
+	})
+	validators := validate.NewPool(1, 4)
+	defer validators.Close()
+	registry := NewGRPCRegistry(
+		store, runner, validators, auth.NewAuthenticator(true, "bootstrap-secret", store, nil),
+		quota.NewManager(quota.Config{MaxSchemas: 3}), NewModeController(ModeNormal, 0),
+	)
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(registry)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	client := registrypb.NewRegistryClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "bootstrap-secret")
+
+	_, err = client.GetSchema(context.Background(), &registrypb.GetSchemaRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	registered, err := client.RegisterSchema(ctx, &registrypb.RegisterSchemaRequest{
+		Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, db.StatusActive, registered.Status)
+	_, err = client.RegisterSchema(ctx, &registrypb.RegisterSchemaRequest{
+		Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema,
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = client.RegisterSchema(ctx, &registrypb.RegisterSchemaRequest{
+		Name: "broken", Type: "json", Version: "1.0.0", SchemaData: `{"type":"nope"}`,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	for _, version := range []string{"1.1.0", "1.2.0"} {
+		_, err = client.RegisterSchema(ctx, &registrypb.RegisterSchemaRequest{
+			Name: "events", Type: "json", Version: version, SchemaData: eventSchema,
+		})
+		assert.NoError(t, err)
+	}
+	_, err = client.RegisterSchema(ctx, &registrypb.RegisterSchemaRequest{
+		Name: "events", Type: "json", Version: "2.0.0", SchemaData: eventSchema,
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	got, err := client.GetSchema(ctx, &registrypb.GetSchemaRequest{Id: registered.Id})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.0.0", got.Version)
+	}
+	got, err = client.GetSchema(ctx, &registrypb.GetSchemaRequest{Name: "events", Type: "json"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.2.0", got.Version)
+	}
+	_, err = client.GetSchema(ctx, &registrypb.GetSchemaRequest{Name: "events", Type: "json", Version: "9.0.0"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.ListSchemas(ctx, &registrypb.ListSchemasRequest{Sort: []string{"-version"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var versions []string
+	for {
+		schema, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		versions = append(versions, schema.Version)
+	}
+	assert.Equal(t, []string{"1.2.0", "1.1.0", "1.0.0"}, versions)
+
+	// Schemas are scoped to the tenant chosen with x-tenant
+	acme := metadata.AppendToOutgoingContext(ctx, "x-tenant", "acme")
+	_, err = client.GetSchema(acme, &registrypb.GetSchemaRequest{Id: registered.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.GetSchema(metadata.AppendToOutgoingContext(ctx, "x-tenant", "nope"), &registrypb.GetSchemaRequest{
+		Id: registered.Id,
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	valid, err := client.ValidateMessage(ctx, &registrypb.ValidateMessageRequest{
+		Name: "events", Type: "json", Version: "1.0.0", Payload: []byte(`{"id":1,"kind":"created"}`),
+	})
+	if assert.NoError(t, err) {
+		assert.True(t, valid.Valid)
+		assert.Equal(t, registered.Id, valid.SchemaId)
+	}
+	invalid, err := client.ValidateMessage(ctx, &registrypb.ValidateMessageRequest{
+		Name: "events", Type: "json", Version: "1.0.0", Payload: []byte(`{"id":0,"kind":"updated"}`),
+	})
+	if assert.NoError(t, err) {
+		assert.False(t, invalid.Valid)
+		assert.NotEmpty(t, invalid.Error)
+	}
+
+	stored, err := store.CreateScenario(db.TestScenario{
+		Name: "events-smoke", Topic: "events.created", SchemaName: "events", SchemaType: "json",
+		SchemaVersion: "1.0.0", MessageCount: 10, Rate: 5, Mode: scenario.ModeValid, Options: []byte(`{}`),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	run, err := client.RunScenario(ctx, &registrypb.RunScenarioRequest{ScenarioId: int64(stored.ID), Wait: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, db.RunPassed, run.Status)
+		assert.Equal(t, int64(10), run.MessagesSent)
+		assert.NotNil(t, run.Finished)
+	}
+	_, err = client.RunScenario(ctx, &registrypb.RunScenarioRequest{ScenarioId: 99})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Read-only mode turns writes away but keeps serving reads
+	registry.modes.SetMode(ModeReadOnly, 0)
+	_, err = client.RegisterSchema(ctx, &registrypb.RegisterSchemaRequest{
+		Name: "orders", Type: "json", Version: "1.0.0", SchemaData: eventSchema,
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	_, err = client.GetSchema(ctx, &registrypb.GetSchemaRequest{Id: registered.Id})
+	assert.NoError(t, err)
+
+	// Reflection needs no credentials
+	info, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, info.Send(&grpc_reflection_v1.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+	}))
+	response, err := info.Recv()
+	if assert.NoError(t, err) {
+		var services []string
+		for _, service := range response.GetListServicesResponse().GetService() {
+			services = append(services, service.Name)
+		}
+		assert.Contains(t, services, "t3.registry.v1.Registry")
+	}
+}
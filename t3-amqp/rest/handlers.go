@@ -1,15 +1,22 @@
 package rest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"t3-amqp/db"
+	"t3-amqp/validate"
 )
 
 // imlement a health check handler that will verify the datbase is avalable
 // it will return a 200 status code if the database is available and a 500 status code if it is not
-func HealthCheckHandler(pool *pgxpool.Pool) http.HandlerFunc {
+func HealthCheckHandler(pool db.Pinger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		err := pool.Ping(r.Context())
 		if err != nil {
@@ -21,16 +28,21 @@ func HealthCheckHandler(pool *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
-func SchemaEndpointHandler(pool *pgxpool.Pool) http.HandlerFunc {
+func SchemaEndpointHandler(store db.SchemaStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
 		// Define the HTTP handlers
 		switch r.Method {
 		case http.MethodGet:
-			GetSchemaFilterParamsHandler(pool).ServeHTTP(w, r)
+			GetSchemaFilterParamsHandler(store).ServeHTTP(w, r)
 		case http.MethodPost:
-			PostSchemaHandler(pool).ServeHTTP(w, r)
+			PostSchemaHandler(store).ServeHTTP(w, r)
 		case http.MethodPut:
-			UpdateSchemaHandler(pool).ServeHTTP(w, r)
+			UpdateSchemaHandler(store).ServeHTTP(w, r)
+		case http.MethodPatch:
+			UpsertSchemaHandler(store).ServeHTTP(w, r)
+		case http.MethodDelete:
+			DeleteSchemaHandler(store).ServeHTTP(w, r)
 		default:
 
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -38,11 +50,74 @@ func SchemaEndpointHandler(pool *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
-func PostSchemaHandler(pool *pgxpool.Pool) http.HandlerFunc {
+var errDuplicateSchema = db.NewError(db.ErrConflict, "schema duplicates an existing version")
+
+// prepareSchema checks schema_data, resolving its t3:// references to schemas of store,
+// and fills in its fingerprints. An Avro schema whose canonical form matches another
+// version of the same schema is a duplicate.
+func prepareSchema(store db.SchemaStore, params *db.QueryArgs) error {
+	if err := validate.CheckSchema(params.Type, params.SchemaData, validate.StoreResolver(store)); err != nil {
+		return err
+	}
+	fingerprint, rabin, err := validate.SchemaFingerprints(params.Type, params.SchemaData)
+	if err != nil {
+		return err
+	}
+	params.Fingerprint, params.RabinFingerprint = fingerprint, rabin
+	if params.Type != "avro" {
+		return nil
+	}
+
+	existing, err := store.Filter(db.QueryArgs{Name: params.Name, Type: params.Type, Fingerprint: fingerprint})
+	if err != nil {
+		return fmt.Errorf("error checking for duplicate schemas: %w", err)
+	}
+	for _, schema := range existing {
+		if schema.Version != params.Version {
+			return fmt.Errorf("%w: version %s has the same canonical form", errDuplicateSchema, schema.Version)
+		}
+	}
+	return nil
+}
+
+// CreatedResponse answers a registration with the id of the new schema and its
+// fingerprints, so clients can verify what was stored
+type CreatedResponse struct {
+	ID               int    `json:"id"`
+	Fingerprint      string `json:"fingerprint"`
+	RabinFingerprint string `json:"rabinFingerprint,omitempty"`
+}
+
+// ConflictResponse answers a registration whose name, type and version are taken, ID is
+// the existing schema
+type ConflictResponse struct {
+	Error string `json:"error"`
+	ID    int    `json:"id"`
+}
+
+// writeAlreadyExists answers 409 with the id of the schema that already has the name,
+// type and version of params
+func writeAlreadyExists(w http.ResponseWriter, store db.SchemaStore, params db.QueryArgs) {
+	existing, err := store.Filter(db.QueryArgs{Name: params.Name, Type: params.Type, Version: params.Version})
+	if err != nil || len(existing) == 0 {
+		http.Error(w, db.ErrAlreadyExists.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/schema/"+strconv.Itoa(existing[0].ID))
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(ConflictResponse{Error: db.ErrAlreadyExists.Error(), ID: existing[0].ID})
+}
+
+// PostSchemaHandler registers a schema, answering 201 with a Location header. JSON schemas
+// must be valid draft-07 or later documents and Avro schemas must follow the Avro
+// specification, the problems found are answered with 422. Avro schemas duplicating another version are answered with 409, as
+// are versions already registered, together with the existing schema's id.
+func PostSchemaHandler(store db.SchemaStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req SchemaRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
@@ -52,15 +127,26 @@ func PostSchemaHandler(pool *pgxpool.Pool) http.HandlerFunc {
 			Version:    req.Version,
 			SchemaData: req.SchemaData,
 		}
+		if err := prepareSchema(store, &params); err != nil {
+			writeError(w, r, err, "failed to check schema")
+			return
+		}
 
-		id, err := db.InsertSchema(pool, params)
+		id, err := store.Insert(params)
+		if errors.Is(err, db.ErrAlreadyExists) {
+			writeAlreadyExists(w, store, params)
+			return
+		}
 		if err != nil {
-			http.Error(w, "failed to insert schema", http.StatusInternalServerError)
+			writeError(w, r, err, "failed to insert schema")
 			return
 		}
+		recordChange(store, r, db.AuditActionInsert, nil, insertedSchema(id, params))
 
-		response := map[string]int64{"id": int64(id)}
+		response := CreatedResponse{ID: id, Fingerprint: params.Fingerprint, RabinFingerprint: params.RabinFingerprint}
+		w.Header().Set("Location", "/schema/"+strconv.Itoa(id))
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
 		err = json.NewEncoder(w).Encode(response)
 		if err != nil {
 			return
@@ -68,11 +154,12 @@ func PostSchemaHandler(pool *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
-func UpdateSchemaHandler(pool *pgxpool.Pool) http.HandlerFunc {
+// UpdateSchemaHandler replaces the data of an existing schema version, answering 404 when
+// the name, type and version are not registered. Schemas are checked like on registration.
+func UpdateSchemaHandler(store db.SchemaStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req SchemaRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
@@ -82,16 +169,25 @@ func UpdateSchemaHandler(pool *pgxpool.Pool) http.HandlerFunc {
 			Version:    req.Version,
 			SchemaData: req.SchemaData,
 		}
+		if err := prepareSchema(store, &params); err != nil {
+			writeError(w, r, err, "failed to check schema")
+			return
+		}
 
-		dbResponse, err := db.UpdateSchema(pool, params)
+		previous, err := store.Filter(db.QueryArgs{Name: params.Name, Type: params.Type, Version: params.Version})
 		if err != nil {
-			if err.Error() == "schema not found" {
-				http.Error(w, "schema not found", http.StatusNotFound)
-			} else {
-				http.Error(w, "failed to update schema", http.StatusInternalServerError)
-			}
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		dbResponse, err := store.Update(params)
+		if err != nil {
+			writeError(w, r, err, "failed to update schema")
 			return
 		}
+		if len(dbResponse) > 0 {
+			invalidateValidators(store, dbResponse[0].ID)
+			recordChange(store, r, db.AuditActionUpdate, firstSchema(previous), &dbResponse[0])
+		}
 
 		response := dbResponse
 		w.Header().Set("Content-Type", "application/json")
@@ -102,46 +198,419 @@ func UpdateSchemaHandler(pool *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
-func GetAllSchemasHandler(pool *pgxpool.Pool) http.HandlerFunc {
+// UpsertSchemaHandler registers the schema version of the request or replaces its data
+// when it exists, answering 201 with a Location header when the version was created and
+// 200 when it was updated. Schemas are checked like on registration.
+func UpsertSchemaHandler(store db.SchemaStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		schemas, err := db.GetAllSchemas(pool)
+		upserter, ok := store.(db.Upserter)
+		if !ok {
+			http.Error(w, "upserting schemas is not supported by this store", http.StatusNotImplemented)
+			return
+		}
+		var req SchemaRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		params := db.QueryArgs{
+			Name:       req.Name,
+			Type:       req.Type,
+			Version:    req.Version,
+			SchemaData: req.SchemaData,
+		}
+		if err := prepareSchema(store, &params); err != nil {
+			writeError(w, r, err, "failed to check schema")
+			return
+		}
+
+		previous, err := store.Filter(db.QueryArgs{Name: params.Name, Type: params.Type, Version: params.Version})
 		if err != nil {
-			http.Error(w, "failed to retrieve schemas", http.StatusInternalServerError)
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		schema, created, err := upserter.Upsert(params)
+		if err != nil {
+			writeError(w, r, err, "failed to upsert schema")
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			recordChange(store, r, db.AuditActionInsert, nil, schema)
+			w.Header().Set("Location", "/schema/"+strconv.Itoa(schema.ID))
+			status = http.StatusCreated
+		} else {
+			invalidateValidators(store, schema.ID)
+			recordChange(store, r, db.AuditActionUpdate, firstSchema(previous), schema)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(schema)
+	}
+}
+
+// DeleteSchemaHandler soft-deletes the schema identified by the id query parameter or by
+// the name, type and version triple, answering 204 or 404 when nothing was deleted and
+// 409 naming the schemas that still reference it
+func DeleteSchemaHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var schema db.Schema
+		switch {
+		case q.Get("id") != "":
+			id, err := strconv.Atoi(q.Get("id"))
+			if err != nil {
+				http.Error(w, "invalid id", http.StatusBadRequest)
+				return
+			}
+			found, err := store.GetByID(id)
+			if err != nil {
+				writeError(w, r, err, "failed to retrieve schema")
+				return
+			}
+			schema = *found
+		case q.Get("name") != "" && q.Get("type") != "" && q.Get("version") != "":
+			schemas, err := store.Filter(
+				db.QueryArgs{Name: q.Get("name"), Type: q.Get("type"), Version: q.Get("version")},
+			)
+			if err != nil {
+				http.Error(w, "failed to retrieve schema", http.StatusInternalServerError)
+				return
+			}
+			if len(schemas) == 0 {
+				http.Error(w, "schema not found", http.StatusNotFound)
+				return
+			}
+			schema = schemas[0]
+		default:
+			http.Error(w, "id or name, type and version are required", http.StatusBadRequest)
+			return
+		}
+
+		err := store.Delete(schema.ID)
+		if errors.Is(err, db.ErrSchemaReferenced) {
+			writeReferenced(w, r, store, schema.ID)
 			return
 		}
+		if err != nil {
+			writeError(w, r, err, "failed to delete schema")
+			return
+		}
+		validate.DefaultCache.InvalidateID(schema.ID)
+		recordChange(store, r, db.AuditActionDelete, &schema, nil)
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RestoreSchemaHandler brings back the soft-deleted schema with the ID in the path,
+// answering 404 when no deleted schema has it and 409 when a live schema has since
+// taken its name, type and version
+func RestoreSchemaHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+
+		schema, err := store.Restore(id)
+		if err != nil {
+			writeError(w, r, err, "failed to restore schema")
+			return
+		}
+		recordChange(store, r, db.AuditActionRestore, nil, schema)
 
 		w.Header().Set("Content-Type", "application/json")
-		err = json.NewEncoder(w).Encode(schemas)
+		err = json.NewEncoder(w).Encode(schema)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// includeDeleted reports whether the caller asked for soft-deleted schemas too
+func includeDeleted(r *http.Request) bool {
+	v, _ := strconv.ParseBool(r.URL.Query().Get("include_deleted"))
+	return v
+}
+
+// parseListOptions reads the limit, offset, comma separated sort and include_deleted
+// query parameters
+func parseListOptions(r *http.Request) (db.ListOptions, error) {
+	q := r.URL.Query()
+	opts := db.ListOptions{IncludeDeleted: includeDeleted(r)}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil || opts.Limit < 0 {
+			return opts, fmt.Errorf("invalid limit %q", v)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if opts.Offset, err = strconv.Atoi(v); err != nil || opts.Offset < 0 {
+			return opts, fmt.Errorf("invalid offset %q", v)
+		}
+	}
+	if v := q.Get("sort"); v != "" {
+		opts.Sort = strings.Split(v, ",")
+	}
+	opts.Limit = min(opts.Limit, db.MaxSchemaLimit)
+	return opts, nil
+}
+
+// GetAllSchemasHandler streams schemas as a JSON array, or as NDJSON when the client
+// asks for application/x-ndjson or format=ndjson. limit and offset page the listing and
+// sort orders it, e.g. sort=name,-modified. The total number of schemas is sent in
+// X-Total-Count with paging links in the Link header. With ids=1,2,3 only those schemas
+// are returned, include_deleted=true lists soft-deleted schemas as well.
+func GetAllSchemasHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("ids") {
+			GetSchemasByIdsHandler(store).ServeHTTP(w, r)
+			return
+		}
+
+		opts, err := parseListOptions(r)
 		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		total, err := store.Count(opts.IncludeDeleted)
+		if err != nil {
+			http.Error(w, "failed to retrieve schemas", http.StatusInternalServerError)
+			return
+		}
+		start := func() {
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			setLinkHeader(w, pageLinks(r, opts.Limit, opts.Offset, total))
+		}
+
+		var sw *streamWriter
+		err = store.List(
+			opts, func(schema db.Schema) error {
+				if sw == nil {
+					start()
+					sw = newStreamWriter(w, r)
+				}
+				return sw.Write(schema)
+			},
+		)
+		if err != nil {
+			switch {
+			case sw != nil:
+				// The status line is already out, all we can do is stop and log
+				log.Printf("failed to stream schemas: %v", err)
+			default:
+				writeError(w, r, err, "failed to retrieve schemas")
+			}
 			return
 		}
+
+		if sw == nil {
+			start()
+			sw = newStreamWriter(w, r)
+		}
+		_ = sw.Close()
+	}
+}
+
+// maxBatchIds caps how many schemas can be requested in one batch lookup
+const maxBatchIds = 1000
+
+// parseIds parses a comma separated list of schema IDs
+func parseIds(s string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", part)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("ids must not be empty")
 	}
+	if len(ids) > maxBatchIds {
+		return nil, fmt.Errorf("at most %d ids may be requested at once", maxBatchIds)
+	}
+	return ids, nil
 }
 
-func GetSchemaFilterParamsHandler(pool *pgxpool.Pool) http.HandlerFunc {
+// GetSchemasByIdsHandler returns the schemas listed in the ids query parameter
+func GetSchemasByIdsHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ids, err := parseIds(r.URL.Query().Get("ids"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		schemas, err := store.GetByIDs(ids)
+		if err != nil {
+			http.Error(w, "failed to retrieve schemas", http.StatusInternalServerError)
+			return
+		}
+
+		writeSchemas(w, r, schemas)
+	}
+}
+
+// GetSchemaFilterParamsHandler lists the schemas matching the optional name, type,
+// version and fingerprint query parameters, including soft-deleted ones with
+// include_deleted=true. The fingerprint is either the SHA-256 or, for Avro schemas, the
+// Rabin fingerprint. When name, type and version are given the single schema is returned
+// as an object, or 404 when it does not exist. version=latest resolves to the latest
+// version of the named schema.
+func GetSchemaFilterParamsHandler(store db.SchemaStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		name := r.URL.Query().Get("name")
 		typeStr := r.URL.Query().Get("type")
 		versionStr := r.URL.Query().Get("version")
+		fingerprint, rabin, ok := parseFingerprint(r.URL.Query().Get("fingerprint"))
+		if !ok {
+			http.Error(w, "fingerprint must be a hex SHA-256 or Rabin fingerprint", http.StatusBadRequest)
+			return
+		}
+
+		if versionStr == db.LatestVersion {
+			getLatestSchema(w, r, store, name, typeStr)
+			return
+		}
 
 		var err error
 
 		args := db.QueryArgs{
-			Name:    name,
-			Type:    typeStr,
-			Version: versionStr,
+			Name:             name,
+			Type:             typeStr,
+			Version:          versionStr,
+			Fingerprint:      fingerprint,
+			RabinFingerprint: rabin,
+			IncludeDeleted:   includeDeleted(r),
 		}
 
-		schema, err := db.GetSchemaFilterParams(pool, args)
+		schema, err := store.Filter(args)
 		if err != nil {
 			http.Error(w, "schema not found", http.StatusNotFound)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		err = json.NewEncoder(w).Encode(schema)
+		links := Links{"self": linkTo(r, r.URL.Path, nil)}
+		if name != "" && typeStr != "" {
+			for rel, href := range schemaLinks(name, typeStr) {
+				links[rel] = href
+			}
+		}
+		setLinkHeader(w, links)
+		if len(schema) == 1 {
+			setLifecycleHeaders(w, schema[0])
+		}
+
+		// name, type and version identify exactly one schema
+		if name != "" && typeStr != "" && versionStr != "" {
+			if len(schema) == 0 {
+				http.Error(w, "schema not found", http.StatusNotFound)
+				return
+			}
+			writeSchema(w, r, schema[0])
+			return
+		}
+		writeSchemas(w, r, schema)
+	}
+}
+
+// parseFingerprint tells a hex SHA-256 fingerprint from a hex Rabin one by its length,
+// an empty value is neither
+func parseFingerprint(v string) (fingerprint, rabin string, ok bool) {
+	v = strings.ToLower(v)
+	if _, err := hex.DecodeString(v); err != nil {
+		return "", "", false
+	}
+	switch len(v) {
+	case 0:
+		return "", "", true
+	case sha256.Size * 2:
+		return v, "", true
+	case 16:
+		return "", v, true
+	default:
+		return "", "", false
+	}
+}
+
+// getLatestSchema answers a version=latest query with the latest version as an object
+func getLatestSchema(w http.ResponseWriter, r *http.Request, store db.SchemaStore, name, schemaType string) {
+	if name == "" || schemaType == "" {
+		http.Error(w, "name and type are required to resolve the latest version", http.StatusBadRequest)
+		return
+	}
+
+	schema, err := store.Latest(name, schemaType)
+	if err != nil {
+		writeError(w, r, err, "failed to retrieve schema")
+		return
+	}
+
+	links := Links{"self": linkTo(r, r.URL.Path, nil)}
+	for rel, href := range schemaLinks(schema.Name, schema.Type) {
+		links[rel] = href
+	}
+	setLinkHeader(w, links)
+	setLifecycleHeaders(w, *schema)
+	writeSchema(w, r, *schema)
+}
+
+// GetSchemaByIdHandler returns the schema with the ID in the path as a single object.
+// With format=pretty its schema_data is indented, with format=canonical it is in the
+// canonical form POST /normalize answers.
+func GetSchemaByIdHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id, err := strconv.Atoi(r.PathValue("id"))
 		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
 			return
 		}
+		format := r.URL.Query().Get("format")
+		if format != "" && format != validate.FormatPretty && format != validate.FormatCanonical {
+			http.Error(w, fmt.Sprintf("invalid format %q, use pretty or canonical", format), http.StatusBadRequest)
+			return
+		}
+
+		schema, err := store.GetByID(id)
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		if format != "" {
+			schema.SchemaData, err = validate.Format(format, schema.Type, schema.SchemaData)
+			if errors.Is(err, validate.ErrUnsupportedType) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				writeError(w, r, err, "failed to format schema")
+				return
+			}
+		}
+
+		links := Links{"self": linkTo(r, r.URL.Path, nil)}
+		for rel, href := range schemaLinks(schema.Name, schema.Type) {
+			links[rel] = href
+		}
+		setLinkHeader(w, links)
+		setLifecycleHeaders(w, *schema)
+		writeSchema(w, r, *schema)
 	}
 }
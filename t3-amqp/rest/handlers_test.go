@@ -2,12 +2,15 @@ package rest_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"t3-amqp/db"
 	"t3-amqp/rest"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +27,11 @@ func setupTestDB(t *testing.T) *pgxpool.Pool {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Loading fixtures is idempotent, so tests can rely on them without clearing tables
+	if err := db.LoadFixtures(context.Background(), pool, "testdata/schemas.yaml"); err != nil {
+		t.Fatalf("Failed to load fixtures: %v", err)
+	}
+
 	return pool
 }
 
@@ -46,9 +54,13 @@ func TestCreateSchemaHandler(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	handler := rest.PostSchemaHandler(pool)
+	handler := rest.PostSchemaHandler(db.NewPostgresStore(pool))
 
-	reqBody := `{"name":"test_schema","type":"json","version":"1.0.1","schemaData":"{\"type\": \"object\", \"properties\": {\"example\": {\"type\": \"string\"}}}"}`
+	// testdata/schemas.yaml seeds 1.0.1 and the database outlives the test, so every run
+	// registers a version of its own
+	version := "2.0." + strconv.FormatInt(time.Now().UnixNano(), 10)
+	reqBody := `{"name":"test_schema","type":"json","version":"` + version +
+		`","schemaData":"{\"type\": \"object\", \"properties\": {\"example\": {\"type\": \"string\"}}}"}`
 
 	req := httptest.NewRequest(http.MethodPost, "/schemas", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
@@ -56,19 +68,19 @@ func TestCreateSchemaHandler(t *testing.T) {
 
 	handler.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
 
-	var response map[string]int64
+	var response rest.CreatedResponse
 	err := json.NewDecoder(rr.Body).Decode(&response)
 	assert.NoError(t, err)
-	assert.NotZero(t, response["id"])
+	assert.NotZero(t, response.ID)
 }
 
 func TestGetSchemasHandler(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	handler := rest.GetAllSchemasHandler(pool)
+	handler := rest.GetAllSchemasHandler(db.NewPostgresStore(pool))
 
 	req := httptest.NewRequest(http.MethodGet, "/schemas", nil)
 	rr := httptest.NewRecorder()
@@ -87,17 +99,15 @@ func TestGetSchemaByNameHandler(t *testing.T) {
 	pool := setupTestDB(t)
 	defer pool.Close()
 
-	handler := rest.GetSchemaFilterParamsHandler(pool)
+	handler := rest.GetSchemaFilterParamsHandler(db.NewPostgresStore(pool))
 
-	// Insert a schema for testing
+	// The schema comes from testdata/schemas.yaml
 	schema := db.QueryArgs{
 		Name:       "test_schema",
 		Type:       "json",
 		Version:    "1.0.1",
 		SchemaData: `{"type": "object", "properties": {"example": {"type": "string"}}}`,
 	}
-	_, err := db.InsertSchema(pool, schema)
-	assert.NoError(t, err)
 
 	req := httptest.NewRequest(
 		http.MethodGet, "/schema?name=test_schema&type=json&version=1.0.1", nil,
@@ -109,10 +119,20 @@ func TestGetSchemaByNameHandler(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 
 	var retrievedSchema db.QueryArgs
-	err = json.NewDecoder(rr.Body).Decode(&retrievedSchema)
+	err := json.NewDecoder(rr.Body).Decode(&retrievedSchema)
 	assert.NoError(t, err)
 	assert.Equal(t, schema.Name, retrievedSchema.Name)
 	assert.Equal(t, schema.Type, retrievedSchema.Type)
 	assert.Equal(t, schema.Version, retrievedSchema.Version)
 	assert.Equal(t, schema.SchemaData, retrievedSchema.SchemaData)
 }
+
+func TestDeleteSchemaHandlerRequiresIdentifier(t *testing.T) {
+	handler := rest.DeleteSchemaHandler(nil)
+
+	for _, target := range []string{"/schema", "/schema?id=abc", "/schema?name=orders&type=json"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, target, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
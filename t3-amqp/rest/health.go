@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"t3-amqp/amqp"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+)
+
+// HealthDetailsHandler reports database reachability along with the latest pool and
+// runtime statistics so capacity problems show up before requests start failing. The
+// connection section tells since when the database is up or down and how often it came
+// back after an outage. When broker is set the amqp section reports the broker connection,
+// its open channels and drops; a broker that is down does not fail the check, readiness
+// covers it.
+func HealthDetailsHandler(health *db.Health, sampler *metrics.Sampler, broker func() amqp.ConnState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		state := health.Check(r.Context())
+		response := map[string]interface{}{
+			"database":   "up",
+			"connection": state,
+			"stats":      sampler.Last(),
+		}
+		if !state.Up {
+			status = http.StatusInternalServerError
+			response["database"] = "down"
+		}
+		if broker != nil {
+			response["amqp"] = broker()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
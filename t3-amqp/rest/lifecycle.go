@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"net/http"
+	"t3-amqp/db"
+	"time"
+)
+
+type LifecycleRequest struct {
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	Version     string     `json:"version"`
+	DeprecateAt *time.Time `json:"deprecateAt"`
+	RetireAt    *time.Time `json:"retireAt"`
+}
+
+// setLifecycleHeaders announces a planned sunset with the Deprecation and Sunset headers
+func setLifecycleHeaders(w http.ResponseWriter, schema db.Schema) {
+	if schema.DeprecateAt != nil {
+		w.Header().Set("Deprecation", schema.DeprecateAt.UTC().Format(http.TimeFormat))
+	}
+	if schema.RetireAt != nil {
+		w.Header().Set("Sunset", schema.RetireAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// LifecycleHandler schedules the deprecation and retirement of a schema. The background
+// lifecycle scheduler performs the transitions once the timestamps are due.
+func LifecycleHandler(pool *pgxpool.Pool) http.HandlerFunc {
+	postgresStore := db.NewPostgresStore(pool)
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := postgresStore.WithTenant(requestTenant(r))
+		if r.Method != http.MethodPut {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req LifecycleRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Name == "" || req.Type == "" || req.Version == "" {
+			http.Error(w, "name, type and version are required", http.StatusBadRequest)
+			return
+		}
+		if err := db.CheckNamespace(store, callerNamespaces(r), req.Name); err != nil {
+			writeError(w, r, err, "failed to check schema namespace")
+			return
+		}
+
+		args := db.QueryArgs{Name: req.Name, Type: req.Type, Version: req.Version, Tenant: requestTenant(r)}
+		previous, err := store.Filter(args)
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		schema, err := db.ScheduleSchemaLifecycle(pool, args, req.DeprecateAt, req.RetireAt)
+		if err != nil {
+			writeError(w, r, err, "failed to schedule schema lifecycle")
+			return
+		}
+		recordChange(store, r, db.AuditActionUpdate, firstSchema(previous), schema)
+
+		setLifecycleHeaders(w, *schema)
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(schema)
+		if err != nil {
+			return
+		}
+	}
+}
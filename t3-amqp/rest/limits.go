@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"t3-amqp/redact"
+)
+
+const (
+	DefaultSchemaBodyBytes     = 8 << 20
+	DefaultValidationBodyBytes = 4 << 20
+	DefaultBodyBytes           = 1 << 20
+	DefaultMaxBodyBytes        = 64 << 20
+)
+
+// BodyLimits holds the maximum request body size per kind of endpoint, zero values use
+// the defaults
+type BodyLimits struct {
+	Schema     int64
+	Validation int64
+	Default    int64
+	// Max caps every request body, including those of endpoints without a limit of their
+	// own such as uploads
+	Max int64
+}
+
+// WithDefaults fills in unset limits
+func (l BodyLimits) WithDefaults() BodyLimits {
+	if l.Schema <= 0 {
+		l.Schema = DefaultSchemaBodyBytes
+	}
+	if l.Validation <= 0 {
+		l.Validation = DefaultValidationBodyBytes
+	}
+	if l.Default <= 0 {
+		l.Default = DefaultBodyBytes
+	}
+	if l.Max <= 0 {
+		l.Max = DefaultMaxBodyBytes
+	}
+	return l
+}
+
+func writeTooLarge(w http.ResponseWriter, limit int64) {
+	http.Error(
+		w, fmt.Sprintf(
+			"request body exceeds the %d byte limit for this endpoint; "+
+				"use POST /schema/upload for large schema documents", limit,
+		), http.StatusRequestEntityTooLarge,
+	)
+}
+
+// BodyLimitMiddleware caps the request body at limit bytes. Requests that announce a
+// larger Content-Length are rejected up front, others fail when the handler reads past
+// the limit.
+func BodyLimitMiddleware(limit int64, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeTooLarge(w, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	}
+}
+
+// decodeJSON decodes the JSON (or YAML) request body into v. On failure it writes a 413
+// when the body limit was hit and a 400 otherwise, and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	var err error
+	if isYAML(mediaType(r)) {
+		var data []byte
+		if data, err = yamlToJSON(r); err == nil {
+			err = json.Unmarshal(data, v)
+		}
+	} else {
+		err = json.NewDecoder(r.Body).Decode(v)
+	}
+	if err != nil {
+		writeBodyError(w, err)
+		return false
+	}
+	if err := checkBinding(v); err != nil {
+		writeError(w, r, err, "")
+		return false
+	}
+	return true
+}
+
+// writeBodyError answers a failed body read with 413 when it hit the size limit and 400
+// otherwise
+func writeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeTooLarge(w, tooLarge.Limit)
+		return
+	}
+	http.Error(w, redact.String(err.Error()), http.StatusBadRequest)
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	handler := BodyLimitMiddleware(
+		16, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Partial bodies, without the binding rules of SchemaRequest
+			var req struct {
+				Name string `json:"name"`
+			}
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader(`{"name":"a"}`)))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Announced length over the limit is rejected before reading
+	rr = httptest.NewRecorder()
+	big := `{"name":"` + strings.Repeat("a", 64) + `"}`
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader(big)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Contains(t, rr.Body.String(), "16 byte limit")
+
+	// Unknown length is cut off while decoding
+	req := httptest.NewRequest(http.MethodPost, "/schema", bytes.NewBufferString(big))
+	req.ContentLength = -1
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", strings.NewReader(`{`)))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Links maps RFC 8288 relation types to target URLs
+type Links map[string]string
+
+// linkTo builds a path with query on top of the request's query string
+func linkTo(r *http.Request, path string, set map[string]string) string {
+	q := url.Values{}
+	for k, v := range r.URL.Query() {
+		q[k] = v
+	}
+	for k, v := range set {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
+
+// pageLinks returns self, first, prev and next links for an offset paginated listing
+func pageLinks(r *http.Request, limit, offset, total int) Links {
+	links := Links{"self": linkTo(r, r.URL.Path, nil)}
+	if limit <= 0 {
+		return links
+	}
+
+	links["first"] = linkTo(r, r.URL.Path, map[string]string{"offset": "0", "limit": strconv.Itoa(limit)})
+	if offset > 0 {
+		prev := max(offset-limit, 0)
+		links["prev"] = linkTo(r, r.URL.Path, map[string]string{"offset": strconv.Itoa(prev), "limit": strconv.Itoa(limit)})
+	}
+	if offset+limit < total {
+		links["next"] = linkTo(
+			r, r.URL.Path, map[string]string{"offset": strconv.Itoa(offset + limit), "limit": strconv.Itoa(limit)},
+		)
+	}
+	return links
+}
+
+// schemaLinks returns navigation links for a single schema subject
+func schemaLinks(name, schemaType string) Links {
+	q := url.Values{"name": {name}, "type": {schemaType}}
+	return Links{
+		"versions": "/schema/versions?" + q.Encode(),
+		"history":  "/audit?" + url.Values{"name": {name}}.Encode(),
+	}
+}
+
+// setLinkHeader writes links as an RFC 8288 Link header in a stable order
+func setLinkHeader(w http.ResponseWriter, links Links) {
+	rels := make([]string, 0, len(links))
+	for rel := range links {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	parts := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		parts = append(parts, fmt.Sprintf(`<%s>; rel="%s"`, links[rel], rel))
+	}
+	if len(parts) > 0 {
+		w.Header().Set("Link", strings.Join(parts, ", "))
+	}
+}
@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageLinks(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/audit?actor=ci&limit=10&offset=10", nil)
+	links := pageLinks(r, 10, 10, 25)
+
+	assert.Equal(t, "/audit?actor=ci&limit=10&offset=10", links["self"])
+	assert.Equal(t, "/audit?actor=ci&limit=10&offset=0", links["prev"])
+	assert.Equal(t, "/audit?actor=ci&limit=10&offset=20", links["next"])
+
+	last := pageLinks(r, 10, 20, 25)
+	assert.NotContains(t, last, "next")
+}
+
+func TestSetLinkHeader(t *testing.T) {
+	rr := httptest.NewRecorder()
+	setLinkHeader(rr, Links{"self": "/schemas", "next": "/schemas?offset=10"})
+	assert.Equal(t, `</schemas?offset=10>; rel="next", </schemas>; rel="self"`, rr.Header().Get("Link"))
+}
@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/scenario"
+	"time"
+)
+
+const (
+	DefaultLoadTestDuration    = 10 * time.Minute
+	DefaultLoadTestConcurrency = 32
+)
+
+// LoadRunner executes load tests, amqp.LoadTester implements it
+type LoadRunner interface {
+	Run(ctx context.Context, runID string, test scenario.LoadTest) scenario.Report
+}
+
+// LoadTestLimits bounds what a single load test may ask for, zero values use the defaults
+type LoadTestLimits struct {
+	MaxDuration    time.Duration
+	MaxConcurrency int
+}
+
+// WithDefaults fills in unset limits
+func (l LoadTestLimits) WithDefaults() LoadTestLimits {
+	if l.MaxDuration <= 0 {
+		l.MaxDuration = DefaultLoadTestDuration
+	}
+	if l.MaxConcurrency <= 0 {
+		l.MaxConcurrency = DefaultLoadTestConcurrency
+	}
+	return l
+}
+
+// LoadTestHandler starts a load test on POST and answers 202 with its run, whose outcome
+// is stored once it finished and polled at /runs/{id}. A load test publishes valid
+// messages of a json schema to a topic at a rate, from several publishers, for a
+// duration and reports the confirm and end to end latency percentiles it measured.
+// Without a broker connection it answers 503, and 429 when the tenant already runs as
+// many tests as quotas allow.
+func LoadTestHandler(
+	schemas db.SchemaStore, runs db.RunStore, runner LoadRunner, limits LoadTestLimits, quotas *quota.Manager,
+) http.HandlerFunc {
+	limits = limits.WithDefaults()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if runner == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		var test scenario.LoadTest
+		if !decodeJSON(w, r, &test) {
+			return
+		}
+		if test.Concurrency == 0 {
+			test.Concurrency = 1
+		}
+		if err := test.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if test.Duration > limits.MaxDuration || test.Concurrency > limits.MaxConcurrency {
+			http.Error(
+				w, fmt.Sprintf(
+					"load tests may run for at most %s with at most %d publishers",
+					limits.MaxDuration, limits.MaxConcurrency,
+				), http.StatusBadRequest,
+			)
+			return
+		}
+		if test.Schema.Type != "json" {
+			http.Error(w, "load tests can only generate payloads for json schemas", http.StatusBadRequest)
+			return
+		}
+
+		found, err := scopedStore(r, schemas).Filter(
+			db.QueryArgs{Name: test.Schema.Name, Type: test.Schema.Type, Version: test.Schema.Version},
+		)
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema")
+			return
+		}
+		if len(found) == 0 {
+			http.Error(w, "schema not found", http.StatusNotFound)
+			return
+		}
+
+		test.Schema.Tenant = requestTenant(r)
+		release, err := acquireRun(quotas, test.Schema.Tenant)
+		if err != nil {
+			writeError(w, r, err, "failed to start load test")
+			return
+		}
+		run, err := runs.CreateRun(db.TestRun{
+			Kind:       db.RunKindLoad,
+			Tenant:     test.Schema.Tenant,
+			Topic:      test.Topic,
+			SchemaName: test.Schema.Name,
+			RunID:      newRequestID(),
+			Status:     db.RunRunning,
+			Started:    time.Now().UTC(),
+		})
+		if err != nil {
+			release()
+			writeError(w, r, err, "failed to record test run")
+			return
+		}
+		// The load test outlives the request
+		go func() {
+			defer release()
+			runLoadTest(context.WithoutCancel(r.Context()), runs, runner, test, *run)
+		}()
+
+		w.Header().Set("Location", fmt.Sprintf("/runs/%d", run.ID))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(run)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// runLoadTest executes test and stores the outcome of run, together with the times the
+// broker throttled publishers while it ran
+func runLoadTest(ctx context.Context, runs db.RunStore, runner LoadRunner, test scenario.LoadTest, run db.TestRun) {
+	stopWatching := watchFlow(ctx)
+	report := runner.Run(ctx, run.RunID, test)
+	stopWatching(&report)
+	finishRun(&run, report)
+	if err := runs.FinishRun(run); err != nil {
+		log.Printf("Failed to record the outcome of load test %s: %v", run.RunID, err)
+	}
+}
@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// loadRunnerFunc adapts a function to the LoadRunner interface
+type loadRunnerFunc func(ctx context.Context, runID string, test scenario.LoadTest) scenario.Report
+
+func (f loadRunnerFunc) Run(ctx context.Context, runID string, test scenario.LoadTest) scenario.Report {
+	return f(ctx, runID, test)
+}
+
+func TestLoadTestHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := make(chan scenario.LoadTest, 1)
+	runner := loadRunnerFunc(func(_ context.Context, runID string, test scenario.LoadTest) scenario.Report {
+		confirm := &scenario.Latency{
+			Samples: 100, P50: time.Millisecond, P95: 2 * time.Millisecond, P99: 4 * time.Millisecond,
+		}
+		report := scenario.Report{
+			RunID: runID, Instances: test.Concurrency, Passed: test.Concurrency, MessagesSent: 100,
+			Throughput: 50, ConfirmLatency: confirm, Latency: &scenario.Latency{Samples: 100, P99: 9 * time.Millisecond},
+		}
+		for i := 0; i < test.Concurrency; i++ {
+			report.Results = append(report.Results, scenario.Result{
+				Instance: scenario.Instance{RunID: runID, Index: i}, MessagesSent: 100 / test.Concurrency,
+				ConfirmLatency: confirm,
+			})
+		}
+		tests <- test
+		return report
+	})
+	limits := LoadTestLimits{MaxDuration: time.Minute, MaxConcurrency: 4}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/loadtests", LoadTestHandler(store, store, runner, limits, nil))
+	mux.HandleFunc("GET /runs/{id}", RunHandler(store))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	events := `"topic":"events.created","schema":{"name":"events","type":"json","version":"1.0.0"}`
+	w := do(http.MethodPost, "/loadtests", `{`+events+`,"rate":100,"concurrency":2,"durationNs":1000000000}`)
+	if !assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String()) {
+		return
+	}
+	var started db.TestRun
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	assert.Equal(t, db.RunKindLoad, started.Kind)
+	assert.Nil(t, started.ScenarioID)
+	location := w.Header().Get("Location")
+	test := <-tests
+	assert.Equal(t, 2, test.Concurrency)
+	assert.Equal(t, time.Second, test.Duration)
+
+	var finished db.TestRun
+	assert.Eventually(t, func() bool {
+		w := do(http.MethodGet, location, "")
+		return json.Unmarshal(w.Body.Bytes(), &finished) == nil && finished.Status != db.RunRunning
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, db.RunPassed, finished.Status)
+	assert.Equal(t, 4*time.Millisecond, finished.ConfirmP99)
+	assert.Equal(t, 9*time.Millisecond, finished.LatencyP99)
+	assert.Equal(t, 50.0, finished.Throughput)
+	if assert.Len(t, finished.Results, 2) {
+		assert.Equal(t, time.Millisecond, finished.Results[1].ConfirmP50)
+	}
+
+	w = do(http.MethodPost, "/loadtests", `{`+events+`,"durationNs":1000000000}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, 1, (<-tests).Concurrency, "a load test without concurrency has one publisher")
+
+	invalid := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"no duration", `{` + events + `}`, http.StatusBadRequest},
+		{"too long", `{` + events + `,"durationNs":3600000000000}`, http.StatusBadRequest},
+		{"too many publishers", `{` + events + `,"concurrency":5,"durationNs":1000000000}`, http.StatusBadRequest},
+		{
+			"unknown schema",
+			`{"topic":"t","schema":{"name":"events","type":"json","version":"2.0.0"},"durationNs":1000000000}`,
+			http.StatusNotFound,
+		},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, do(http.MethodPost, "/loadtests", tt.body).Code)
+		})
+	}
+
+	mux = http.NewServeMux()
+	mux.HandleFunc("/loadtests", LoadTestHandler(store, store, nil, limits, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, do(http.MethodPost, "/loadtests", `{`+events+`}`).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "/loadtests", "").Code)
+}
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+type MatchRequest struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type VersionMatch struct {
+	SchemaID int    `json:"schemaId"`
+	Type     string `json:"type"`
+	Version  string `json:"version"`
+	Status   string `json:"status"`
+	Valid    bool   `json:"valid"`
+	Error    string `json:"error,omitempty"`
+}
+
+type MatchResponse struct {
+	Name     string         `json:"name"`
+	Accepted []string       `json:"accepted"`
+	Versions []VersionMatch `json:"versions"`
+}
+
+// MatchHandler validates a sample payload against every registered version of the schema
+// named in the path, optionally narrowed to one type, and reports which versions accept it
+func MatchHandler(store db.SchemaStore, workers *validate.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := r.PathValue("name")
+		var req MatchRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if name == "" || len(req.Payload) == 0 {
+			http.Error(w, "name and payload are required", http.StatusBadRequest)
+			return
+		}
+
+		schemas, err := store.Filter(db.QueryArgs{Name: name, Type: req.Type})
+		if err != nil {
+			http.Error(w, "failed to retrieve schemas", http.StatusInternalServerError)
+			return
+		}
+		if len(schemas) == 0 {
+			http.Error(w, "schema not found", http.StatusNotFound)
+			return
+		}
+
+		response := MatchResponse{Name: schemas[0].Name, Accepted: []string{}, Versions: []VersionMatch{}}
+		for _, schema := range schemas {
+			match := VersionMatch{
+				SchemaID: schema.ID, Type: schema.Type, Version: schema.Version, Status: schema.Status,
+			}
+
+			validator, err := validate.DefaultCache.Validator(schema)
+			if err == nil {
+				err = workers.Validate(r.Context(), validator, req.Payload)
+			}
+			switch {
+			case errors.Is(err, validate.ErrOverloaded):
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			case errors.Is(err, validate.ErrPoolClosed):
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			case err != nil:
+				match.Error = err.Error()
+			default:
+				match.Valid = true
+				response.Accepted = append(response.Accepted, schema.Version)
+			}
+			response.Versions = append(response.Versions, match)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchHandlerRequiresPayload(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /schema/{name}/match", MatchHandler(nil, nil))
+
+	r := httptest.NewRequest(http.MethodPost, "/schema/order.created/match", strings.NewReader(`{"type": "json"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schema/order.created/match", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"t3-amqp/metrics"
+	"time"
+)
+
+// Router resolves the route pattern that serves a request, *http.ServeMux implements it
+type Router interface {
+	Handler(r *http.Request) (http.Handler, string)
+}
+
+// statusRecorder remembers the status code written through it, it still flushes so
+// streamed listings keep working
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if sr.status == 0 {
+		sr.status = status
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// MetricsMiddleware counts requests and observes their latency labelled with the route
+// pattern from routes, requests no route matches share the "unmatched" label
+func MetricsMiddleware(routes Router, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			status := sr.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			metrics.HTTPRequests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+			metrics.HTTPDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		}()
+		next.ServeHTTP(sr, r)
+	}
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "missing", http.StatusNotFound)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	handler := MetricsMiddleware(mux, mux)
+
+	notFound := metrics.HTTPRequests.WithLabelValues("/things/{id}", http.MethodGet, "404")
+	ok := metrics.HTTPRequests.WithLabelValues("/ok", http.MethodGet, "200")
+	unmatched := metrics.HTTPRequests.WithLabelValues("unmatched", http.MethodGet, "404")
+	before := []float64{testutil.ToFloat64(notFound), testutil.ToFloat64(ok), testutil.ToFloat64(unmatched)}
+
+	for _, path := range []string{"/things/1", "/things/2", "/ok", "/nowhere"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Both ids are counted under their route pattern rather than the raw path
+	assert.Equal(t, before[0]+2, testutil.ToFloat64(notFound))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(ok))
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(unmatched))
+
+	var flushed bool
+	rr := httptest.NewRecorder()
+	MetricsMiddleware(mux, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flushed = w.(http.Flusher)
+	})).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.True(t, flushed)
+}
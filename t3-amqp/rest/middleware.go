@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+)
+
+// clientKey identifies the caller for quota purposes: the authenticated caller, or the
+// remote IP. A key that was not authenticated is ignored, with auth disabled a client
+// could otherwise get fresh quotas by sending a new one with every request.
+func clientKey(r *http.Request) string {
+	if principal := Principal(r.Context()); principal != nil {
+		return principal.ID
+	}
+	return sourceIP(r)
+}
+
+// keyID identifies an API key by a prefix of its SHA-256 hash, which does not reveal it
+func keyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// sourceIP returns the address the request came from
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// QuotaMiddleware rejects callers that exceed their per-minute request quota with a 429
+func QuotaMiddleware(q *quota.Manager, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		retryAfter, err := q.AllowRequest(clientKey(r))
+		if err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// WriteRateMiddleware rejects writes beyond the caller's write rate with a 429 and a
+// Retry-After header, so a runaway job registering schemas in a loop cannot flood the
+// registry. Reads are not limited here.
+func WriteRateMiddleware(q *quota.Manager, routes Router, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isWrite(routes, r) && !isProbe(r.URL.Path) {
+			retryAfter, err := q.AllowWrite(clientKey(r))
+			if err != nil {
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter.Seconds())))))
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// SchemaQuotaMiddleware rejects schema registrations with a 409 once the registry holds
+// the configured maximum number of schemas in the tenant of the request
+func SchemaQuotaMiddleware(store db.SchemaStore, q *quota.Manager, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && q.Config().MaxSchemas > 0 {
+			count, err := db.ForTenant(store, requestTenant(r)).Count(false)
+			if err != nil {
+				http.Error(w, "failed to check schema quota", http.StatusInternalServerError)
+				return
+			}
+			if err := q.CheckSchemaCount(count); errors.Is(err, quota.ErrSchemaQuota) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// QuotaUsageHandler reports the configured limits and the usage of every caller
+func QuotaUsageHandler(q *quota.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]interface{}{
+			"limits": q.Config(),
+			"usage":  q.Usage(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Mode is the operating mode of the server
+type Mode string
+
+const (
+	ModeNormal      Mode = "normal"
+	ModeReadOnly    Mode = "read_only"
+	ModeMaintenance Mode = "maintenance"
+)
+
+// ParseMode converts a configured mode string into a Mode, an empty string is ModeNormal
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeNormal:
+		return ModeNormal, nil
+	case ModeReadOnly, ModeMaintenance:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("unknown server mode %q", s)
+	}
+}
+
+// ModeController holds the current operating mode and can be switched at runtime
+type ModeController struct {
+	mu         sync.RWMutex
+	mode       Mode
+	retryAfter int
+}
+
+// NewModeController creates a controller starting in the given mode. retryAfter is the
+// number of seconds clients are told to wait when a request is rejected.
+func NewModeController(mode Mode, retryAfter int) *ModeController {
+	if retryAfter <= 0 {
+		retryAfter = 60
+	}
+	return &ModeController{mode: mode, retryAfter: retryAfter}
+}
+
+// Mode returns the current mode and Retry-After seconds
+func (c *ModeController) Mode() (Mode, int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.mode, c.retryAfter
+}
+
+// SetMode switches the server into mode, a non-positive retryAfter keeps the current value
+func (c *ModeController) SetMode(mode Mode, retryAfter int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mode = mode
+	if retryAfter > 0 {
+		c.retryAfter = retryAfter
+	}
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// ModeMiddleware rejects writes in read-only mode and everything in maintenance mode with a
+// 503 and Retry-After, requests are classified through routes like for auth scopes. Health
+// checks, metrics and the mode admin endpoint always pass.
+func ModeMiddleware(c *ModeController, routes Router, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if isProbe(path) || path == "/health/details" || path == "/metrics" || path == "/admin/mode" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mode, retryAfter := c.Mode()
+		if mode == ModeMaintenance || (mode == ModeReadOnly && isWrite(routes, r)) {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, fmt.Sprintf("server is in %s mode", mode), http.StatusServiceUnavailable)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+type modeRequest struct {
+	Mode       string `json:"mode"`
+	RetryAfter int    `json:"retryAfter"`
+}
+
+// ModeHandler reports the current mode on GET and switches it on PUT
+func ModeHandler(c *ModeController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req modeRequest
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			mode, err := ParseMode(req.Mode)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			c.SetMode(mode, req.RetryAfter)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		mode, retryAfter := c.Mode()
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(modeRequest{Mode: string(mode), RetryAfter: retryAfter})
+		if err != nil {
+			return
+		}
+	}
+}
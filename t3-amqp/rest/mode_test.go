@@ -0,0 +1,67 @@
+package rest_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeMiddleware(t *testing.T) {
+	modes := rest.NewModeController(rest.ModeReadOnly, 30)
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", ok)
+	mux.HandleFunc("/validate", ok)
+	handler := rest.ModeMiddleware(modes, mux, mux)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/validate", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "read-only POSTs pass in read-only mode")
+
+	modes.SetMode(rest.ModeMaintenance, 0)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	for _, probe := range []string{"/health", "/health/details", "/healthz", "/readyz"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, probe, nil))
+		assert.Equal(t, http.StatusOK, rr.Code, probe)
+	}
+}
+
+func TestModeHandlerSwitchesMode(t *testing.T) {
+	modes := rest.NewModeController(rest.ModeNormal, 0)
+	handler := rest.ModeHandler(modes)
+
+	body := bytes.NewBufferString(`{"mode":"read_only","retryAfter":120}`)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/admin/mode", body))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	mode, retryAfter := modes.Mode()
+	assert.Equal(t, rest.ModeReadOnly, mode)
+	assert.Equal(t, 120, retryAfter)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(
+		rr, httptest.NewRequest(http.MethodPut, "/admin/mode", bytes.NewBufferString(`{"mode":"off"}`)),
+	)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+// NormalizeRequest is schema_data to bring into canonical form before it is registered
+type NormalizeRequest struct {
+	Type       string `json:"type"`
+	SchemaData string `json:"schemaData"`
+}
+
+// NormalizeResponse is the canonical form of a schema
+type NormalizeResponse struct {
+	Type       string `json:"type"`
+	SchemaData string `json:"schemaData"`
+}
+
+// NormalizeHandler answers the canonical form of a JSON or Avro schema, so versions that
+// only differ in whitespace or key order are registered and compared alike. The schema is
+// checked like a registration first, its problems are answered with 422.
+func NormalizeHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req NormalizeRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Type == "" || req.SchemaData == "" {
+			http.Error(w, "type and schemaData are required", http.StatusBadRequest)
+			return
+		}
+		if err := validate.CheckSchema(req.Type, req.SchemaData, validate.StoreResolver(store)); err != nil {
+			writeError(w, r, err, "failed to check schema")
+			return
+		}
+		canonical, err := validate.Canonical(req.Type, req.SchemaData)
+		if errors.Is(err, validate.ErrUnsupportedType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeError(w, r, err, "failed to normalize schema")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(NormalizeResponse{Type: req.Type, SchemaData: canonical})
+		if err != nil {
+			return
+		}
+	}
+}
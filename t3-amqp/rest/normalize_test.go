@@ -0,0 +1,88 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeHandler(t *testing.T) {
+	handler := rest.NormalizeHandler(db.NewMemoryStore())
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/normalize", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := post(
+		`{"type":"json","schemaData":"{\n  \"type\": \"object\",\n  \"required\": [\"id\"],\n` +
+			`  \"$schema\": \"http://json-schema.org/draft-07/schema#\"\n}"}`,
+	)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response rest.NormalizeResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, "json", response.Type)
+	assert.Equal(
+		t, `{"$schema":"http://json-schema.org/draft-07/schema#","required":["id"],"type":"object"}`, response.SchemaData,
+	)
+
+	rr = post(`{"type":"avro","schemaData":"{\"type\": \"record\", \"name\": \"Order\", \"doc\": \"x\", \"fields\": []}"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, `{"name":"Order","type":"record","fields":[]}`, response.SchemaData)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post(`{"type":"json","schemaData":"{\"type\":"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(`{"type":"avro","schemaData":"{\"type\":\"record\"}"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"type":"xsd","schemaData":"<xs:schema/>"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"type":"json"}`).Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/normalize", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestGetSchemaByIdHandlerFormat(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, err := store.Insert(
+		db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type": "object",  "title":"Order"}`},
+	)
+	assert.NoError(t, err)
+	xsd, err := store.Insert(db.QueryArgs{Name: "orders", Type: "xsd", Version: "1.0.0", SchemaData: `<xs:schema/>`})
+	assert.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	get := func(target string) (*httptest.ResponseRecorder, db.Schema) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		var schema db.Schema
+		_ = json.Unmarshal(rr.Body.Bytes(), &schema)
+		return rr, schema
+	}
+
+	path := "/schema/" + strconv.Itoa(id)
+	rr, schema := get(path)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"type": "object",  "title":"Order"}`, schema.SchemaData)
+	etag := rr.Header().Get("ETag")
+
+	rr, schema = get(path + "?format=pretty")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "{\n  \"type\": \"object\",\n  \"title\": \"Order\"\n}", schema.SchemaData)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+
+	rr, schema = get(path + "?format=canonical")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"title":"Order","type":"object"}`, schema.SchemaData)
+
+	rr, _ = get(path + "?format=yaml")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr, _ = get("/schema/" + strconv.Itoa(xsd) + "?format=canonical")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
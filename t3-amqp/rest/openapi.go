@@ -0,0 +1,25 @@
+package rest
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes the schema endpoints, t3client is generated from it
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPIHandler serves the OpenAPI 3 document describing the schema endpoints
+func OpenAPIHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(openAPISpec)
+	}
+}
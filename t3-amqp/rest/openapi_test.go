@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	OpenAPIHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]map[string]any  `json:"paths"`
+		Components map[string]map[string]json.RawMessage `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."))
+	paths := []string{
+		"/healthz", "/readyz", "/schema", "/schema/{id}", "/schema/versions", "/schema/diff", "/schemas",
+		"/schemas/import", "/schemas/search",
+	}
+	for _, path := range paths {
+		assert.Contains(t, doc.Paths, path)
+	}
+
+	// Every operation is named for the generated client and every reference resolves
+	seen := map[string]bool{}
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			id, _ := op["operationId"].(string)
+			assert.NotEmpty(t, id, "%s %s", method, path)
+			assert.False(t, seen[id], "duplicate operationId %s", id)
+			seen[id] = true
+		}
+	}
+	for _, ref := range strings.Split(rr.Body.String(), `"$ref": "#/components/`)[1:] {
+		kind, tail, _ := strings.Cut(ref, "/")
+		name, _, _ := strings.Cut(tail, `"`)
+		assert.Contains(t, doc.Components[kind], name)
+	}
+}
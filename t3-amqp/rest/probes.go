@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds how long the readiness checks may take together
+const probeTimeout = 5 * time.Second
+
+// ReadinessCheck reports whether one dependency is usable, nil when it is
+type ReadinessCheck func(ctx context.Context) error
+
+// DependencyStatus is the state of one dependency in the readiness report
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Readiness is the body of /readyz
+type Readiness struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// isProbe reports whether path is a health check, which middlewares always let through.
+// /health/details is not one, its pool and broker statistics need read scope.
+func isProbe(path string) bool {
+	switch path {
+	case "/health", "/healthz", "/readyz":
+		return true
+	}
+	return false
+}
+
+// LivenessHandler answers 200 as long as the process serves requests. It checks no
+// dependency, so an outage of the database never gets the registry restarted.
+func LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(map[string]string{"status": "alive"})
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ReadinessHandler runs every check concurrently and answers 200 when all of them pass,
+// 503 otherwise, listing the state of each dependency
+func ReadinessHandler(checks map[string]ReadinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), probeTimeout)
+		defer cancel()
+
+		response := Readiness{Status: "ready", Dependencies: make(map[string]DependencyStatus, len(checks))}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check ReadinessCheck) {
+				defer wg.Done()
+				status := DependencyStatus{Status: "up"}
+				if err := check(ctx); err != nil {
+					status = DependencyStatus{Status: "down", Error: err.Error()}
+				}
+				mu.Lock()
+				response.Dependencies[name] = status
+				mu.Unlock()
+			}(name, check)
+		}
+		wg.Wait()
+
+		status := http.StatusOK
+		for _, dependency := range response.Dependencies {
+			if dependency.Status != "up" {
+				response.Status = "not ready"
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/amqp"
+	"t3-amqp/db"
+	"t3-amqp/metrics"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rest.LivenessHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"alive"}`, rr.Body.String())
+}
+
+func TestReadinessHandler(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	checks := map[string]rest.ReadinessCheck{"database": up, "migrations": up}
+	serve := func() (int, rest.Readiness) {
+		rr := httptest.NewRecorder()
+		rest.ReadinessHandler(checks).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body rest.Readiness
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return rr.Code, body
+	}
+
+	code, body := serve()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body.Status)
+	assert.Equal(t, rest.DependencyStatus{Status: "up"}, body.Dependencies["migrations"])
+
+	checks["amqp"] = func(context.Context) error { return errors.New("broker connection is closed") }
+	code, body = serve()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not ready", body.Status)
+	assert.Len(t, body.Dependencies, 3)
+	assert.Equal(t, rest.DependencyStatus{Status: "down", Error: "broker connection is closed"}, body.Dependencies["amqp"])
+	assert.Equal(t, "up", body.Dependencies["database"].Status)
+}
+
+type upPinger struct{}
+
+func (upPinger) Ping(context.Context) error { return nil }
+func (upPinger) Reset()                     {}
+
+func TestHealthDetailsHandlerReportsBroker(t *testing.T) {
+	health, sampler := db.NewHealth(upPinger{}), metrics.NewSampler(nil)
+	serve := func(broker func() amqp.ConnState) (int, map[string]json.RawMessage) {
+		rr := httptest.NewRecorder()
+		handler := rest.HealthDetailsHandler(health, sampler, broker)
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/details", nil))
+		var body map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return rr.Code, body
+	}
+
+	code, body := serve(nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, body, "amqp", "no broker is configured")
+
+	// A broker that is down is reported without failing the database check
+	code, body = serve(func() amqp.ConnState {
+		drop := amqp.DropEvent{Reason: "i/o timeout", MissedHeartbeat: true}
+		return amqp.ConnState{MissedHeartbeats: 1, Drops: []amqp.DropEvent{drop}}
+	})
+	assert.Equal(t, http.StatusOK, code)
+	var state amqp.ConnState
+	if assert.NoError(t, json.Unmarshal(body["amqp"], &state)) {
+		assert.False(t, state.Up)
+		assert.Equal(t, 1, state.MissedHeartbeats)
+		assert.Len(t, state.Drops, 1)
+	}
+
+	_, body = serve(func() amqp.ConnState { return amqp.ConnState{Up: true, Channels: 3} })
+	assert.JSONEq(t, `{"up":true,"channels":3,"missedHeartbeats":0}`, string(body["amqp"]))
+}
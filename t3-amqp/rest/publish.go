@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+)
+
+// Publisher sends a schema checked payload to a topic
+type Publisher interface {
+	Publish(ctx context.Context, topic string, ref scenario.SchemaRef, payload []byte) error
+}
+
+type PublishRequest struct {
+	Topic   string          `json:"topic"`
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Version string          `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type PublishResponse struct {
+	Topic  string             `json:"topic"`
+	Schema scenario.SchemaRef `json:"schema"`
+}
+
+// PublishHandler publishes a test message to a topic after validating it against the
+// named schema. XML payloads for xsd schemas are sent as a JSON string. Without a broker
+// connection publisher is nil and every request gets a 503.
+func PublishHandler(publisher Publisher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req PublishRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Topic == "" || req.Name == "" || req.Type == "" || req.Version == "" || len(req.Payload) == 0 {
+			http.Error(w, "topic, name, type, version and payload are required", http.StatusBadRequest)
+			return
+		}
+		payload := []byte(req.Payload)
+		if req.Type == "xsd" {
+			var doc string
+			if json.Unmarshal(req.Payload, &doc) == nil {
+				payload = []byte(doc)
+			}
+		}
+		if publisher == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		ref := scenario.SchemaRef{Name: req.Name, Type: req.Type, Version: req.Version, Tenant: requestTenant(r)}
+		err := publisher.Publish(r.Context(), req.Topic, ref, payload)
+		switch {
+		case errors.Is(err, db.ErrSchemaNotFound):
+			http.Error(w, "schema not found", http.StatusNotFound)
+			return
+		case errors.Is(err, broker.ErrSchemaRetired):
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		case errors.Is(err, broker.ErrInvalidPayload):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			http.Error(w, "failed to publish message", http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(PublishResponse{Topic: req.Topic, Schema: ref})
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/broker"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubPublisher struct {
+	err     error
+	payload []byte
+}
+
+func (p *stubPublisher) Publish(_ context.Context, _ string, _ scenario.SchemaRef, payload []byte) error {
+	p.payload = payload
+	return p.err
+}
+
+func TestPublishHandlerStatus(t *testing.T) {
+	body := `{"topic":"orders.created","name":"orders","type":"json","version":"1.0.0","payload":{"id":1}}`
+	tests := []struct {
+		name      string
+		publisher Publisher
+		body      string
+		want      int
+	}{
+		{"published", &stubPublisher{}, body, http.StatusAccepted},
+		{"missing topic", &stubPublisher{}, `{"name":"orders","type":"json","version":"1.0.0","payload":{}}`, http.StatusBadRequest},
+		{"no broker", nil, body, http.StatusServiceUnavailable},
+		{"unknown schema", &stubPublisher{err: fmt.Errorf("x: %w", db.ErrSchemaNotFound)}, body, http.StatusNotFound},
+		{"invalid payload", &stubPublisher{err: broker.ErrInvalidPayload}, body, http.StatusUnprocessableEntity},
+		{"broker error", &stubPublisher{err: fmt.Errorf("channel closed")}, body, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PublishHandler(tt.publisher).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/publish", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestPublishHandlerUnquotesXML(t *testing.T) {
+	p := &stubPublisher{}
+	body := `{"topic":"orders","name":"orders","type":"xsd","version":"1","payload":"<order id=\"1\"/>"}`
+	w := httptest.NewRecorder()
+	PublishHandler(p).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/publish", strings.NewReader(body)))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, `<order id="1"/>`, string(p.payload))
+}
@@ -0,0 +1,54 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/quota"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRateMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	mux.HandleFunc("/schema", ok)
+	mux.HandleFunc("/validate", ok)
+	q := quota.NewManager(quota.Config{WritesPerSecond: 0.1, WriteBurst: 1})
+	mux.HandleFunc("/quota", rest.QuotaUsageHandler(q))
+	handler := rest.WriteRateMiddleware(q, mux, mux)
+
+	post := func(path, addr, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = addr
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, post("/schema", "192.0.2.1:4000", "ci").Code)
+	rr := post("/schema", "192.0.2.1:4000", "ci")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "10", rr.Header().Get("Retry-After"))
+	assert.Equal(
+		t, http.StatusTooManyRequests, post("/schema", "192.0.2.1:4001", "random").Code,
+		"keys that were not authenticated do not get their own quota",
+	)
+
+	assert.Equal(t, http.StatusOK, post("/schema", "192.0.2.2:4000", "ci").Code, "clients are limited separately")
+	assert.Equal(t, http.StatusOK, post("/validate", "192.0.2.1:4000", "ci").Code, "read-only POSTs are not limited")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/schema", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "reads are not limited")
+
+	// Unauthenticated callers are told apart by their address, a key they sent is not shown
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/quota", nil))
+	assert.NotContains(t, rr.Body.String(), `"ci"`)
+	assert.Contains(t, rr.Body.String(), `"192.0.2.1"`)
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"t3-amqp/metrics"
+)
+
+type requestIDKey struct{}
+
+// RequestID returns the request id assigned by RequestIDMiddleware
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestIDMiddleware propagates the caller's X-Request-ID or assigns a new one, echoes it
+// on the response and stores it in the request context
+func RequestIDMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	}
+}
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	// Errors lists the fields of an invalid request body
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// RecoverMiddleware turns a panic in any handler into a 500 problem+json response, logs
+// the stack trace with the request id and counts it, keeping the process alive
+func RecoverMiddleware(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// The handler deliberately aborted the response, let net/http handle it
+				panic(rec)
+			}
+
+			id := RequestID(r.Context())
+			metrics.Panics.Inc()
+			log.Printf("panic serving %s %s (request %s): %v\n%s", r.Method, r.URL.Path, id, rec, debug.Stack())
+
+			writeProblem(
+				w, Problem{
+					Type:      "about:blank",
+					Title:     "Internal Server Error",
+					Status:    http.StatusInternalServerError,
+					Detail:    "the server hit an unexpected error while handling the request",
+					Instance:  r.URL.Path,
+					RequestID: id,
+				},
+			)
+		}()
+
+		next.ServeHTTP(w, r)
+	}
+}
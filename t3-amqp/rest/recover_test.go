@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	handler := RequestIDMiddleware(
+		RecoverMiddleware(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			}),
+		),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/schema", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "req-123", rr.Header().Get("X-Request-ID"))
+
+	var problem Problem
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&problem))
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Equal(t, "req-123", problem.RequestID)
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = RequestID(r.Context())
+		}),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, rr.Header().Get("X-Request-ID"))
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+// ReferencedSchema is the other end of an edge of the schema dependency graph, Ref is
+// how a JSON schema references it
+type ReferencedSchema struct {
+	ID  int    `json:"id"`
+	Ref string `json:"ref"`
+}
+
+type ReferencesResponse struct {
+	ID int `json:"id"`
+	// References are the schemas the schema references, Dependents those referencing it
+	References []ReferencedSchema `json:"references"`
+	Dependents []ReferencedSchema `json:"dependents"`
+}
+
+func referencedSchemas(schemas []db.Schema) []ReferencedSchema {
+	referenced := []ReferencedSchema{}
+	for _, s := range schemas {
+		ref := db.SchemaRef{Name: s.Name, Type: s.Type, Version: s.Version}
+		referenced = append(referenced, ReferencedSchema{ID: s.ID, Ref: ref.String()})
+	}
+	return referenced
+}
+
+// SchemaReferencesHandler lists the live schemas the schema with the ID in the path
+// references through t3:// references and the live schemas referencing it
+func SchemaReferencesHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		references, ok := store.(db.ReferenceStore)
+		if !ok {
+			http.Error(w, "schema references are not supported by this store", http.StatusNotImplemented)
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+
+		referenced, err := references.References(id)
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema references")
+			return
+		}
+		dependents, err := references.Dependents(id)
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve schema references")
+			return
+		}
+
+		response := ReferencesResponse{
+			ID: id, References: referencedSchemas(referenced), Dependents: referencedSchemas(dependents),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeReferenced answers the refused delete of the schema with id with 409, naming the
+// schemas that still reference it when the store can tell
+func writeReferenced(w http.ResponseWriter, r *http.Request, store db.SchemaStore, id int) {
+	references, ok := store.(db.ReferenceStore)
+	if !ok {
+		writeError(w, r, db.ErrSchemaReferenced, "failed to delete schema")
+		return
+	}
+	dependents, err := references.Dependents(id)
+	if err != nil || len(dependents) == 0 {
+		writeError(w, r, db.ErrSchemaReferenced, "failed to delete schema")
+		return
+	}
+
+	var refs []string
+	for _, d := range referencedSchemas(dependents) {
+		refs = append(refs, d.Ref)
+	}
+	writeStatusProblem(
+		w, r, http.StatusConflict, db.ErrSchemaReferenced.Error()+": "+strings.Join(refs, ", "),
+	)
+}
+
+// invalidateValidators drops the cached validators of the schema with id and of the
+// schemas referencing it, directly or through others, which embed its previous data
+func invalidateValidators(store db.SchemaStore, id int) {
+	validate.DefaultCache.InvalidateID(id)
+	references, ok := store.(db.ReferenceStore)
+	if !ok {
+		return
+	}
+
+	seen := map[int]bool{id: true}
+	pending := []int{id}
+	for len(pending) > 0 {
+		dependents, err := references.Dependents(pending[0])
+		pending = pending[1:]
+		if err != nil {
+			continue
+		}
+		for _, d := range dependents {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				validate.DefaultCache.InvalidateID(d.ID)
+				pending = append(pending, d.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaReferences(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	mux.HandleFunc("GET /schema/{id}/references", rest.SchemaReferencesHandler(store))
+	serve := func(method, target string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, target, &buf))
+		return rr
+	}
+
+	order := rest.SchemaRequest{
+		Name: "order", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","properties":{"to":{"$ref":"t3://address/json/1.0.0"}},"required":["to"]}`,
+	}
+	rr := serve(http.MethodPost, "/schema", order)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, "the referenced schema is not registered yet")
+	assert.Contains(t, rr.Body.String(), "t3://address/json/1.0.0")
+
+	address := rest.SchemaRequest{
+		Name: "address", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","properties":{"street":{"type":"string"}},"required":["street"]}`,
+	}
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/schema", address).Code)
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/schema", order).Code)
+
+	rr = serve(http.MethodGet, "/schema/1/references", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(
+		t, `{"id":1,"references":[],"dependents":[{"id":2,"ref":"t3://order/json/1.0.0"}]}`, rr.Body.String(),
+	)
+	rr = serve(http.MethodGet, "/schema/2/references", nil)
+	assert.JSONEq(
+		t, `{"id":2,"references":[{"id":1,"ref":"t3://address/json/1.0.0"}],"dependents":[]}`, rr.Body.String(),
+	)
+
+	// Validation resolves the reference server-side
+	schema, err := store.GetByID(2)
+	assert.NoError(t, err)
+	cache := validate.NewCache(10)
+	cache.UseStore(store)
+	validator, err := cache.Validator(*schema)
+	assert.NoError(t, err)
+	assert.NoError(t, validator.Validate([]byte(`{"to":{"street":"Main St"}}`)))
+	assert.Error(t, validator.Validate([]byte(`{"to":{}}`)))
+
+	rr = serve(http.MethodDelete, "/schema?id=1", nil)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "t3://order/json/1.0.0")
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/schema?id=2", nil).Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/schema?id=1", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/schema/1/references", nil).Code)
+}
@@ -0,0 +1,224 @@
+package rest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// acquireRun takes one of the concurrent run slots of tenant, which scenario runs, load
+// tests and captures share whether started through the API or by the scheduler. The
+// returned function gives the slot back and must be called once the run ended, however
+// it ended. It fails with quota.ErrRunQuota when every slot is taken, without quotas runs
+// are not limited.
+func acquireRun(quotas *quota.Manager, tenant string) (release func(), err error) {
+	if quotas == nil {
+		return func() {}, nil
+	}
+	key := "tenant:" + cmp.Or(tenant, db.DefaultTenant)
+	if err := quotas.AcquireRun(key); err != nil {
+		return nil, err
+	}
+	return func() { quotas.ReleaseRun(key) }, nil
+}
+
+// resultDetails are the instance stats stored together as JSON with each test result
+type resultDetails struct {
+	Assertions       []scenario.AssertionResult  `json:"assertions,omitempty"`
+	Ack              *scenario.AckStats          `json:"ack,omitempty"`
+	Consumers        []scenario.ConsumerStats    `json:"consumers,omitempty"`
+	Chaos            *scenario.ChaosStats        `json:"chaos,omitempty"`
+	MissedHeartbeats int                         `json:"missedHeartbeats,omitempty"`
+	Throttled        []scenario.ThrottleInterval `json:"throttled,omitempty"`
+}
+
+// finishRun fills in the outcome of run from report. A run fails when any message or
+// assertion failed and errors when an instance could not be executed at all.
+func finishRun(run *db.TestRun, report scenario.Report) {
+	finished := time.Now().UTC()
+	run.Finished = &finished
+	run.MessagesSent, run.Failures = report.MessagesSent, report.Failures
+	run.MessagesValid = 0
+	if report.Latency != nil {
+		run.LatencyP50, run.LatencyP95, run.LatencyP99 = report.Latency.P50, report.Latency.P95, report.Latency.P99
+	}
+	if confirm := report.ConfirmLatency; confirm != nil {
+		run.ConfirmP50, run.ConfirmP95, run.ConfirmP99 = confirm.P50, confirm.P95, confirm.P99
+	}
+	run.Throughput = report.Throughput
+	run.Status = db.RunPassed
+	if report.Failed > 0 || report.Failures > 0 {
+		// Load tests count messages that never came back against the run, not a publisher
+		run.Status = db.RunFailed
+	}
+
+	run.Results = make([]db.TestResult, 0, len(report.Results))
+	for _, result := range report.Results {
+		if result.Error != "" {
+			run.Status = db.RunError
+		}
+		run.MessagesValid += result.MessagesValid
+		stored := db.TestResult{
+			Instance:      result.Instance.Index,
+			TempQueue:     result.Instance.TempQueue,
+			Params:        result.Instance.Params,
+			Started:       result.Started,
+			Duration:      result.Duration,
+			MessagesSent:  result.MessagesSent,
+			MessagesValid: result.MessagesValid,
+			Failures:      result.Failures,
+			Redelivered:   result.Redelivered,
+			Error:         result.Error,
+		}
+		if result.Latency != nil {
+			stored.LatencyP50, stored.LatencyP95, stored.LatencyP99 = result.Latency.P50, result.Latency.P95,
+				result.Latency.P99
+		}
+		if confirm := result.ConfirmLatency; confirm != nil {
+			stored.ConfirmP50, stored.ConfirmP95, stored.ConfirmP99 = confirm.P50, confirm.P95, confirm.P99
+		}
+		details, err := json.Marshal(resultDetails{
+			Assertions: result.Assertions, Ack: result.Ack, Consumers: result.Consumers, Chaos: result.Chaos,
+			MissedHeartbeats: result.MissedHeartbeats, Throttled: result.Throttled,
+		})
+		if err != nil {
+			log.Printf("Failed to encode details of test run %s instance %d: %v", run.RunID, stored.Instance, err)
+		}
+		stored.Details = details
+		run.Results = append(run.Results, stored)
+	}
+}
+
+// parseRunFilter reads the test run filters and pagination from the query string
+func parseRunFilter(r *http.Request) (db.RunFilter, error) {
+	q := r.URL.Query()
+	filter := db.RunFilter{
+		Tenant:     requestTenant(r),
+		Kind:       q.Get("kind"),
+		Topic:      q.Get("topic"),
+		Status:     q.Get("status"),
+		Namespaces: callerNamespaces(r),
+	}
+
+	if filter.Kind != "" && filter.Kind != db.RunKindScenario && filter.Kind != db.RunKindLoad {
+		return filter, fmt.Errorf("invalid kind %q, use %s or %s", filter.Kind, db.RunKindScenario, db.RunKindLoad)
+	}
+	statuses := []string{db.RunRunning, db.RunPassed, db.RunFailed, db.RunError}
+	if filter.Status != "" && !slices.Contains(statuses, filter.Status) {
+		return filter, fmt.Errorf("invalid status %q, expected one of %v", filter.Status, statuses)
+	}
+	var err error
+	if v := q.Get("scenario"); v != "" {
+		if filter.ScenarioID, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid scenario: %w", err)
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid limit: %w", err)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			return filter, fmt.Errorf("invalid offset: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+// writeRuns answers with a page of the runs matching filter
+func writeRuns(w http.ResponseWriter, r *http.Request, runs db.RunStore, filter db.RunFilter) {
+	list, total, err := runs.Runs(filter)
+	if err != nil {
+		writeError(w, r, err, "failed to retrieve test runs")
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if filter.Limit <= 0 {
+		filter.Limit = db.DefaultRunLimit
+	}
+	links := pageLinks(r, filter.Limit, filter.Offset, total)
+	setLinkHeader(w, links)
+
+	response := map[string]interface{}{
+		"runs":   list,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+		"_links": links,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// RunsHandler lists scenario runs and load tests newest first, filtered by kind, scenario,
+// topic, status and a start time range, so the health of a topic can be followed across runs. Runs against
+// schemas outside the caller's namespaces are left out.
+func RunsHandler(runs db.RunStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		filter, err := parseRunFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeRuns(w, r, runs, filter)
+	}
+}
+
+// RunHandler returns the test run named in the path with the result of every instance
+// once it finished. Runs against schemas outside the caller's namespaces are answered as
+// not found.
+func RunHandler(runs db.RunStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+		run, err := runs.RunByID(id)
+		if err == nil &&
+			(run.Tenant != requestTenant(r) || !db.NamespaceAllowed(callerNamespaces(r), run.SchemaName)) {
+			err = db.ErrRunNotFound
+		}
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve test run")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(run)
+		if err != nil {
+			return
+		}
+	}
+}
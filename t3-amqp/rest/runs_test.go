@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunsHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, run := range []db.TestRun{
+		{RunID: "a", Topic: "orders.created", SchemaName: "orders", Status: db.RunPassed},
+		{RunID: "b", Topic: "orders.created", SchemaName: "orders", Status: db.RunFailed},
+		{RunID: "c", Topic: "team-a.events", SchemaName: "team-a.events", Status: db.RunPassed},
+	} {
+		run.Started = started.Add(time.Duration(i) * time.Hour)
+		_, err := store.CreateRun(run)
+		assert.NoError(t, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/runs", RunsHandler(store))
+	mux.HandleFunc("/runs/{id}", RunHandler(store))
+
+	type page struct {
+		Runs  []db.TestRun `json:"runs"`
+		Total int          `json:"total"`
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+		total int
+	}{
+		{"all", "", []string{"c", "b", "a"}, 3},
+		{"topic", "?topic=orders.created", []string{"b", "a"}, 2},
+		{"status", "?status=passed", []string{"c", "a"}, 2},
+		{"from", "?from=2024-05-01T13:00:00Z", []string{"c", "b"}, 2},
+		{"paged", "?limit=1&offset=1", []string{"b"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/runs"+tt.query, nil))
+			if !assert.Equal(t, http.StatusOK, w.Code, w.Body.String()) {
+				return
+			}
+			var got page
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			ids := []string{}
+			for _, run := range got.Runs {
+				ids = append(ids, run.RunID)
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.total, got.Total)
+			assert.Equal(t, strconv.Itoa(tt.total), w.Header().Get("X-Total-Count"))
+		})
+	}
+
+	for _, query := range []string{"?status=slow", "?from=yesterday", "?scenario=x", "?limit=many"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/runs"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	teamA := func(method, path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		principal := &auth.Principal{ID: "key:team-a", Namespaces: []string{"team-a"}}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+		return w
+	}
+	var got page
+	assert.NoError(t, json.Unmarshal(teamA(http.MethodGet, "/runs").Body.Bytes(), &got))
+	if assert.Len(t, got.Runs, 1) {
+		assert.Equal(t, "c", got.Runs[0].RunID)
+	}
+	assert.Equal(t, http.StatusNotFound, teamA(http.MethodGet, "/runs/1").Code, "runs of other namespaces are hidden")
+	assert.Equal(t, http.StatusOK, teamA(http.MethodGet, "/runs/3").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, teamA(http.MethodPost, "/runs").Code)
+}
+
+func TestFinishRun(t *testing.T) {
+	latency := &scenario.Latency{
+		Samples: 2, P50: time.Millisecond, P95: 4 * time.Millisecond, P99: 5 * time.Millisecond,
+	}
+	report := scenario.Report{
+		MessagesSent: 4, Failures: 1, Failed: 1, Latency: latency,
+		Results: []scenario.Result{
+			{
+				Instance:     scenario.Instance{Index: 0, TempQueue: "t3.run.0", Params: map[string]string{"region": "eu"}},
+				MessagesSent: 2, MessagesValid: 2, Latency: latency,
+			},
+			{
+				Instance:     scenario.Instance{Index: 1, TempQueue: "t3.run.1"},
+				MessagesSent: 2, MessagesValid: 1, Failures: 1,
+				Assertions: []scenario.AssertionResult{{Passed: false, Message: "no dead letters"}},
+			},
+		},
+	}
+
+	run := db.TestRun{RunID: "run", Status: db.RunRunning}
+	finishRun(&run, report)
+	assert.Equal(t, db.RunFailed, run.Status)
+	assert.NotNil(t, run.Finished)
+	assert.Equal(t, 3, run.MessagesValid)
+	assert.Equal(t, 5*time.Millisecond, run.LatencyP99)
+	if assert.Len(t, run.Results, 2) {
+		assert.Equal(t, "eu", run.Results[0].Params["region"])
+		assert.Equal(t, 4*time.Millisecond, run.Results[0].LatencyP95)
+		assert.Zero(t, run.Results[1].LatencyP95)
+		var details resultDetails
+		assert.NoError(t, json.Unmarshal(run.Results[1].Details, &details))
+		assert.Len(t, details.Assertions, 1)
+	}
+
+	report.Results[1].Error = "broker went away"
+	finishRun(&run, report)
+	assert.Equal(t, db.RunError, run.Status)
+}
@@ -0,0 +1,387 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/payload"
+	"t3-amqp/quota"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// ScenarioRunner executes the instances of a scenario run, scenario.Engine implements it
+type ScenarioRunner interface {
+	Run(ctx context.Context, runID string, instances []scenario.Instance) scenario.Report
+}
+
+// ScenarioResponse is a stored scenario with the times it was created and last changed
+type ScenarioResponse struct {
+	scenario.Scenario
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// scenarioOptions are the settings of a scenario stored together as JSON
+type scenarioOptions struct {
+	PayloadPool int                     `json:"payloadPool,omitempty"`
+	PayloadSize *payload.SizeSpec       `json:"payloadSize,omitempty"`
+	Ack         *scenario.AckStrategy   `json:"ack,omitempty"`
+	Group       *scenario.ConsumerGroup `json:"group,omitempty"`
+	Chaos       *scenario.ChaosOptions  `json:"chaos,omitempty"`
+	Schedule    string                  `json:"schedule,omitempty"`
+	Assertions  []scenario.Assertion    `json:"assertions,omitempty"`
+	MQTT        *scenario.MQTTOptions   `json:"mqtt,omitempty"`
+	Probe       *scenario.ProbeOptions  `json:"probe,omitempty"`
+	Params      map[string]string       `json:"params,omitempty"`
+}
+
+func toStoredScenario(s scenario.Scenario) (db.TestScenario, error) {
+	options, err := json.Marshal(
+		scenarioOptions{
+			PayloadPool: s.PayloadPool, PayloadSize: s.PayloadSize, Ack: s.Ack, Assertions: s.Assertions, MQTT: s.MQTT,
+			Group: s.Group, Chaos: s.Chaos, Probe: s.Probe, Schedule: s.Schedule, Params: s.Params,
+		},
+	)
+	if err != nil {
+		return db.TestScenario{}, err
+	}
+	return db.TestScenario{
+		ID:            s.ID,
+		Name:          s.Name,
+		Topic:         s.Topic,
+		SchemaName:    s.Schema.Name,
+		SchemaType:    s.Schema.Type,
+		SchemaVersion: s.Schema.Version,
+		MessageCount:  s.MessageCount,
+		Rate:          s.Rate,
+		Mode:          s.Mode,
+		Options:       options,
+		Tenant:        s.Schema.Tenant,
+	}, nil
+}
+
+func fromStoredScenario(stored db.TestScenario) (ScenarioResponse, error) {
+	var options scenarioOptions
+	if len(stored.Options) > 0 {
+		if err := json.Unmarshal(stored.Options, &options); err != nil {
+			return ScenarioResponse{}, fmt.Errorf("error decoding options of scenario %d: %w", stored.ID, err)
+		}
+	}
+	ref := scenario.SchemaRef{
+		Name: stored.SchemaName, Type: stored.SchemaType, Version: stored.SchemaVersion, Tenant: stored.Tenant,
+	}
+	return ScenarioResponse{
+		Scenario: scenario.Scenario{
+			ID:           stored.ID,
+			Name:         stored.Name,
+			Topic:        stored.Topic,
+			Schema:       ref,
+			MessageCount: stored.MessageCount,
+			Rate:         stored.Rate,
+			Mode:         stored.Mode,
+			PayloadPool:  options.PayloadPool,
+			PayloadSize:  options.PayloadSize,
+			Ack:          options.Ack,
+			Group:        options.Group,
+			Chaos:        options.Chaos,
+			Schedule:     options.Schedule,
+			Assertions:   options.Assertions,
+			MQTT:         options.MQTT,
+			Probe:        options.Probe,
+			Params:       options.Params,
+		},
+		Created: stored.Created,
+		Updated: stored.Updated,
+	}, nil
+}
+
+// LoadScenario retrieves the stored scenario with id, ready to run
+func LoadScenario(scenarios db.ScenarioStore, id int) (scenario.Scenario, error) {
+	stored, err := scenarios.ScenarioByID(id)
+	if err != nil {
+		return scenario.Scenario{}, err
+	}
+	response, err := fromStoredScenario(*stored)
+	if err != nil {
+		return scenario.Scenario{}, err
+	}
+	return response.Scenario, nil
+}
+
+// decodeScenario reads and checks a scenario definition, answering 400 or 404 when it is
+// incomplete or names a schema the caller cannot see
+func decodeScenario(w http.ResponseWriter, r *http.Request, schemas db.SchemaStore) (scenario.Scenario, bool) {
+	var s scenario.Scenario
+	if !decodeJSON(w, r, &s) {
+		return s, false
+	}
+	if s.Mode == "" {
+		s.Mode = scenario.ModeValid
+	}
+	s.Schema.Tenant = requestTenant(r)
+	if err := s.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return s, false
+	}
+	if s.Schema.Type != "json" {
+		http.Error(w, "scenarios can only generate payloads for json schemas", http.StatusBadRequest)
+		return s, false
+	}
+
+	found, err := scopedStore(r, schemas).Filter(
+		db.QueryArgs{Name: s.Schema.Name, Type: s.Schema.Type, Version: s.Schema.Version},
+	)
+	if err != nil {
+		writeError(w, r, err, "failed to retrieve schema")
+		return s, false
+	}
+	if len(found) == 0 {
+		http.Error(w, "schema not found", http.StatusNotFound)
+		return s, false
+	}
+	return s, true
+}
+
+// scenarioByPath loads the scenario named by the id path value. Scenarios of other
+// tenants and of schemas outside the caller's namespaces are answered as not found.
+func scenarioByPath(w http.ResponseWriter, r *http.Request, scenarios db.ScenarioStore) (*db.TestScenario, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return nil, false
+	}
+	stored, err := scenarios.ScenarioByID(id)
+	if err == nil &&
+		(stored.Tenant != requestTenant(r) || !db.NamespaceAllowed(callerNamespaces(r), stored.SchemaName)) {
+		err = db.ErrScenarioNotFound
+	}
+	if err != nil {
+		writeError(w, r, err, "failed to retrieve scenario")
+		return nil, false
+	}
+	return stored, true
+}
+
+func writeScenario(w http.ResponseWriter, r *http.Request, status int, stored db.TestScenario) {
+	response, err := fromStoredScenario(stored)
+	if err != nil {
+		writeError(w, r, err, "failed to decode scenario")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return
+	}
+}
+
+// ScenariosHandler lists the topic test scenarios of the tenant on GET and creates one on
+// POST.
+// A scenario names the topic, the json schema its messages are generated from, how many
+// are published at what rate and whether they are valid or each break one rule.
+func ScenariosHandler(scenarios db.ScenarioStore, schemas db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			list, err := scenarios.Scenarios(requestTenant(r))
+			if err != nil {
+				writeError(w, r, err, "failed to retrieve scenarios")
+				return
+			}
+			restricted := callerNamespaces(r)
+			list = slices.DeleteFunc(
+				list, func(s db.TestScenario) bool { return !db.NamespaceAllowed(restricted, s.SchemaName) },
+			)
+			response := make([]ScenarioResponse, 0, len(list))
+			for _, stored := range list {
+				s, err := fromStoredScenario(stored)
+				if err != nil {
+					writeError(w, r, err, "failed to decode scenarios")
+					return
+				}
+				response = append(response, s)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(response)
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			s, ok := decodeScenario(w, r, schemas)
+			if !ok {
+				return
+			}
+			stored, err := toStoredScenario(s)
+			if err != nil {
+				writeError(w, r, err, "failed to encode scenario")
+				return
+			}
+			created, err := scenarios.CreateScenario(stored)
+			if err != nil {
+				writeError(w, r, err, "failed to create scenario")
+				return
+			}
+			w.Header().Set("Location", fmt.Sprintf("/scenarios/%d", created.ID))
+			writeScenario(w, r, http.StatusCreated, *created)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// ScenarioHandler reads, replaces and deletes the scenario named in the path. The runs of
+// a deleted scenario are kept.
+func ScenarioHandler(scenarios db.ScenarioStore, schemas db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		existing, ok := scenarioByPath(w, r, scenarios)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeScenario(w, r, http.StatusOK, *existing)
+
+		case http.MethodPut:
+			s, ok := decodeScenario(w, r, schemas)
+			if !ok {
+				return
+			}
+			s.ID = existing.ID
+			stored, err := toStoredScenario(s)
+			if err != nil {
+				writeError(w, r, err, "failed to encode scenario")
+				return
+			}
+			updated, err := scenarios.UpdateScenario(stored)
+			if err != nil {
+				writeError(w, r, err, "failed to update scenario")
+				return
+			}
+			writeScenario(w, r, http.StatusOK, *updated)
+
+		case http.MethodDelete:
+			if err := scenarios.DeleteScenario(existing.ID); err != nil {
+				writeError(w, r, err, "failed to delete scenario")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// RunScenarioHandler starts running the scenario named in the path over AMQP and answers
+// 202 with the new run, whose outcome is stored once it finished and polled at /runs/{id}.
+// Without a broker connection it answers 503, and 429 when the tenant already runs as
+// many tests as quotas allow.
+func RunScenarioHandler(
+	scenarios db.ScenarioStore, runs db.RunStore, runner ScenarioRunner, quotas *quota.Manager,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if runner == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		stored, ok := scenarioByPath(w, r, scenarios)
+		if !ok {
+			return
+		}
+		release, err := acquireRun(quotas, stored.Tenant)
+		if err != nil {
+			writeError(w, r, err, "failed to start test run")
+			return
+		}
+		s, run, err := startRun(runs, *stored)
+		if err != nil {
+			release()
+			writeError(w, r, err, "failed to record test run")
+			return
+		}
+		// The run outlives the request
+		go func() {
+			defer release()
+			runScenario(context.WithoutCancel(r.Context()), runs, runner, s, *run)
+		}()
+
+		w.Header().Set("Location", fmt.Sprintf("/runs/%d", run.ID))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(run)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// startRun records a new run of stored, returning it together with the scenario to execute
+func startRun(runs db.RunStore, stored db.TestScenario) (scenario.Scenario, *db.TestRun, error) {
+	s, err := fromStoredScenario(stored)
+	if err != nil {
+		return scenario.Scenario{}, nil, err
+	}
+	run, err := runs.CreateRun(db.TestRun{
+		ScenarioID:   &stored.ID,
+		ScenarioName: stored.Name,
+		Tenant:       stored.Tenant,
+		Topic:        stored.Topic,
+		SchemaName:   stored.SchemaName,
+		RunID:        newRequestID(),
+		Status:       db.RunRunning,
+		Started:      time.Now().UTC(),
+	})
+	if err != nil {
+		return scenario.Scenario{}, nil, err
+	}
+	return s.Scenario, run, nil
+}
+
+// runScenario executes s and stores the outcome of run, together with the times the
+// broker throttled publishers while it ran
+func runScenario(ctx context.Context, runs db.RunStore, runner ScenarioRunner, s scenario.Scenario, run db.TestRun) {
+	stopWatching := watchFlow(ctx)
+	report := runner.Run(ctx, run.RunID, scenario.Expand(run.RunID, []scenario.Scenario{s}, nil))
+	stopWatching(&report)
+	finishRun(&run, report)
+	if err := runs.FinishRun(run); err != nil {
+		log.Printf("Failed to record the outcome of test run %s: %v", run.RunID, err)
+	}
+}
+
+// ScenarioRunsHandler lists the runs of the scenario named in the path like RunsHandler,
+// newest first
+func ScenarioRunsHandler(scenarios db.ScenarioStore, runs db.RunStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		stored, ok := scenarioByPath(w, r, scenarios)
+		if !ok {
+			return
+		}
+		filter, err := parseRunFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.ScenarioID = stored.ID
+		writeRuns(w, r, runs, filter)
+	}
+}
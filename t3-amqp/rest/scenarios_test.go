@@ -0,0 +1,248 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runnerFunc adapts a function to the ScenarioRunner interface
+type runnerFunc func(ctx context.Context, runID string, instances []scenario.Instance) scenario.Report
+
+func (f runnerFunc) Run(ctx context.Context, runID string, instances []scenario.Instance) scenario.Report {
+	return f(ctx, runID, instances)
+}
+
+func scenarioMux(store *db.MemoryStore, runner ScenarioRunner) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/scenarios", ScenariosHandler(store, store))
+	mux.HandleFunc("/scenarios/{id}", ScenarioHandler(store, store))
+	mux.HandleFunc("POST /scenarios/{id}/run", RunScenarioHandler(store, store, runner, nil))
+	mux.HandleFunc("GET /scenarios/{id}/runs", ScenarioRunsHandler(store, store))
+	mux.HandleFunc("GET /scenarios/{id}/schedule", ScenarioScheduleHandler(store, store))
+	mux.HandleFunc("GET /runs/{id}", RunHandler(store))
+	return mux
+}
+
+func TestScenarioHandlers(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema})
+	assert.NoError(t, err)
+	mux := scenarioMux(store, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	definition := `{"name":"events-smoke","topic":"events.created",
+		"schema":{"name":"events","type":"json","version":"1.0.0"},"messageCount":10,"rate":5,
+		"ack":{"mode":"batch","batchSize":5}}`
+	w := do(http.MethodPost, "/scenarios", definition)
+	if !assert.Equal(t, http.StatusCreated, w.Code, w.Body.String()) {
+		return
+	}
+	var created ScenarioResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "/scenarios/1", w.Header().Get("Location"))
+	assert.Equal(t, scenario.ModeValid, created.Mode)
+	if assert.NotNil(t, created.Ack) {
+		assert.Equal(t, 5, created.Ack.BatchSize)
+	}
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/scenarios", definition).Code)
+
+	w = do(http.MethodPut, "/scenarios/1", strings.Replace(definition, `"rate":5`, `"rate":20`, 1))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got ScenarioResponse
+	w = do(http.MethodGet, "/scenarios/1", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, 20, got.Rate)
+	assert.Equal(t, created.Created, got.Created)
+
+	var list []ScenarioResponse
+	w = do(http.MethodGet, "/scenarios", "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, 1)
+
+	events := `"schema":{"name":"events","type":"json","version":"1.0.0"}`
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{
+			"missing topic", http.MethodPost, "/scenarios", `{"name":"x",` + events + `,"messageCount":1}`,
+			http.StatusBadRequest,
+		},
+		{"no messages", http.MethodPost, "/scenarios", `{"name":"x","topic":"t",` + events + `}`, http.StatusBadRequest},
+		{
+			"unknown mode", http.MethodPost, "/scenarios",
+			`{"name":"x","topic":"t",` + events + `,"messageCount":1,"mode":"fuzzy"}`, http.StatusBadRequest,
+		},
+		{
+			"not json", http.MethodPost, "/scenarios",
+			`{"name":"x","topic":"t","schema":{"name":"events","type":"avro","version":"1.0.0"},"messageCount":1}`,
+			http.StatusBadRequest,
+		},
+		{
+			"unknown schema", http.MethodPost, "/scenarios",
+			`{"name":"x","topic":"t","schema":{"name":"events","type":"json","version":"2.0.0"},"messageCount":1}`,
+			http.StatusNotFound,
+		},
+		{"unknown scenario", http.MethodGet, "/scenarios/9", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/scenarios/x", "", http.StatusBadRequest},
+		{"no broker", http.MethodPost, "/scenarios/1/run", "", http.StatusServiceUnavailable},
+		{"wrong method", http.MethodPatch, "/scenarios/1", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, do(tt.method, tt.path, tt.body).Code)
+		})
+	}
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/scenarios/1", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/scenarios/1", "").Code)
+}
+
+func TestRunScenarioHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema})
+	assert.NoError(t, err)
+	for _, name := range []string{"events-smoke", "events-load"} {
+		_, err = store.CreateScenario(db.TestScenario{
+			Name: name, Topic: "events.created", SchemaName: "events", SchemaType: "json", SchemaVersion: "1.0.0",
+			MessageCount: 3, Mode: scenario.ModeValid,
+		})
+		assert.NoError(t, err)
+	}
+
+	instances := make(chan []scenario.Instance, 2)
+	failures := 0
+	runner := runnerFunc(func(_ context.Context, runID string, insts []scenario.Instance) scenario.Report {
+		report := scenario.Report{RunID: runID, Instances: len(insts), MessagesSent: 3, Failures: failures}
+		if failures > 0 {
+			report.Failed = 1
+		} else {
+			report.Passed = 1
+		}
+		report.Latency = &scenario.Latency{
+			Samples: 3, P50: time.Millisecond, P95: 2 * time.Millisecond, P99: 3 * time.Millisecond,
+		}
+		report.Results = []scenario.Result{
+			{Instance: insts[0], MessagesSent: 3, MessagesValid: 3 - failures, Failures: failures, Latency: report.Latency},
+		}
+		instances <- insts
+		return report
+	})
+	mux := scenarioMux(store, runner)
+
+	run := func() db.TestRun {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/scenarios/1/run", nil))
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		var started db.TestRun
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+		assert.Equal(t, db.RunRunning, started.Status)
+		location := "/runs/" + strconv.Itoa(started.ID)
+		assert.Equal(t, location, w.Header().Get("Location"))
+
+		insts := <-instances
+		if assert.Len(t, insts, 1) {
+			assert.Equal(t, "events-smoke", insts[0].Scenario.Name)
+			assert.Equal(t, scenario.TempQueueName(started.RunID, 0), insts[0].TempQueue)
+		}
+
+		var finished db.TestRun
+		assert.Eventually(t, func() bool {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, location, nil))
+			return json.Unmarshal(w.Body.Bytes(), &finished) == nil && finished.Status != db.RunRunning
+		}, time.Second, 5*time.Millisecond)
+		return finished
+	}
+
+	passed := run()
+	assert.Equal(t, db.RunPassed, passed.Status)
+	assert.Equal(t, 3, passed.MessagesSent)
+	assert.NotNil(t, passed.Finished)
+	assert.Equal(t, "events.created", passed.Topic)
+	assert.Equal(t, 3*time.Millisecond, passed.LatencyP99)
+	if assert.Len(t, passed.Results, 1) {
+		assert.Equal(t, 3, passed.Results[0].MessagesValid)
+		assert.Equal(t, time.Millisecond, passed.Results[0].LatencyP50)
+	}
+
+	failures = 2
+	failed := run()
+	assert.Equal(t, db.RunFailed, failed.Status)
+	assert.Equal(t, 2, failed.Failures)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scenarios/1/runs", nil))
+	var page struct {
+		Runs  []db.TestRun `json:"runs"`
+		Total int          `json:"total"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 2, page.Total)
+	if assert.Len(t, page.Runs, 2) {
+		assert.Equal(t, failed.ID, page.Runs[0].ID)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scenarios/2/runs", nil))
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Empty(t, page.Runs, "runs are only listed under their own scenario")
+}
+
+func TestRunScenarioHandlerEnforcesRunQuota(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.CreateScenario(db.TestScenario{
+		Name: "events-smoke", Topic: "events.created", SchemaName: "events", SchemaType: "json",
+		SchemaVersion: "1.0.0", MessageCount: 1, Mode: scenario.ModeValid,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	release := make(chan struct{})
+	runner := runnerFunc(func(_ context.Context, runID string, insts []scenario.Instance) scenario.Report {
+		<-release
+		return scenario.Report{RunID: runID, Instances: len(insts), Passed: 1}
+	})
+	quotas := quota.NewManager(quota.Config{MaxConcurrentRuns: 1})
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scenarios/{id}/run", RunScenarioHandler(store, store, runner, quotas))
+	start := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/scenarios/1/run", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusAccepted, start().Code)
+	w := start()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "the only run slot is taken")
+	assert.Contains(t, w.Body.String(), quota.ErrRunQuota.Error())
+	_, total, err := store.Runs(db.RunFilter{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, total, "a refused run is not recorded")
+	}
+
+	// The slot is free again once the run finished
+	close(release)
+	idle := func() bool { return quotas.Usage()["tenant:"+db.DefaultTenant].ActiveRuns == 0 }
+	assert.Eventually(t, idle, time.Second, time.Millisecond)
+	assert.Equal(t, http.StatusAccepted, start().Code)
+}
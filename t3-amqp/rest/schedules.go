@@ -0,0 +1,183 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"t3-amqp/db"
+	"t3-amqp/quota"
+	"t3-amqp/scenario"
+	"time"
+)
+
+// DefaultScheduleInterval is how often the scheduler looks for scenarios that are due
+const DefaultScheduleInterval = 30 * time.Second
+
+// ScenarioScheduler runs the stored scenarios that have a schedule whenever their cron
+// expression comes due and records their runs like runs started through the API. A
+// scenario still running when it comes due again is skipped rather than run twice, and
+// so is one whose tenant has no run slot left.
+type ScenarioScheduler struct {
+	scenarios db.ScenarioStore
+	runs      db.RunStore
+	runner    ScenarioRunner
+	quotas    *quota.Manager
+
+	mu      sync.Mutex
+	checked time.Time
+	running map[int]bool
+}
+
+// NewScenarioScheduler creates a scheduler running scenarios on runner, taking the run
+// slots of their tenants from quotas
+func NewScenarioScheduler(
+	scenarios db.ScenarioStore, runs db.RunStore, runner ScenarioRunner, quotas *quota.Manager,
+) *ScenarioScheduler {
+	return &ScenarioScheduler{
+		scenarios: scenarios, runs: runs, runner: runner, quotas: quotas, running: map[int]bool{},
+	}
+}
+
+// Run starts the scenarios that come due every interval until ctx is done. Runs missed
+// while the server was down are not made up for.
+func (s *ScenarioScheduler) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultScheduleInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.tickAndLog(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tickAndLog(ctx, now)
+		}
+	}
+}
+
+func (s *ScenarioScheduler) tickAndLog(ctx context.Context, now time.Time) {
+	started, err := s.Tick(ctx, now)
+	if err != nil {
+		log.Printf("Failed to run scheduled scenarios: %v", err)
+	}
+	for _, run := range started {
+		log.Printf("Started scheduled run %s of scenario %s", run.RunID, run.ScenarioName)
+	}
+}
+
+// Tick starts the scenarios that came due since the previous tick and returns their
+// runs. The first tick only marks the time later ticks look back to.
+func (s *ScenarioScheduler) Tick(ctx context.Context, now time.Time) ([]db.TestRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	since := s.checked
+	s.checked = now
+	if since.IsZero() {
+		return nil, nil
+	}
+
+	// Scenarios of every tenant run on their schedules
+	list, err := s.scenarios.Scenarios("")
+	if err != nil {
+		return nil, err
+	}
+	var started []db.TestRun
+	for _, stored := range list {
+		if !s.due(stored, since, now) {
+			continue
+		}
+		if s.running[stored.ID] {
+			log.Printf("Skipped scheduled run of scenario %s, the previous one is still running", stored.Name)
+			continue
+		}
+		release, err := acquireRun(s.quotas, stored.Tenant)
+		if err != nil {
+			log.Printf("Skipped scheduled run of scenario %s: %v", stored.Name, err)
+			continue
+		}
+		sc, run, err := startRun(s.runs, stored)
+		if err != nil {
+			release()
+			log.Printf("Failed to record scheduled run of scenario %s: %v", stored.Name, err)
+			continue
+		}
+		s.running[stored.ID] = true
+		go func() {
+			defer release()
+			runScenario(ctx, s.runs, s.runner, sc, *run)
+			s.mu.Lock()
+			delete(s.running, stored.ID)
+			s.mu.Unlock()
+		}()
+		started = append(started, *run)
+	}
+	return started, nil
+}
+
+// due reports whether the schedule of stored selects a time in (since, now]
+func (s *ScenarioScheduler) due(stored db.TestScenario, since, now time.Time) bool {
+	response, err := fromStoredScenario(stored)
+	if err != nil || response.Schedule == "" {
+		return false
+	}
+	cron, err := scenario.ParseCron(response.Schedule)
+	if err != nil {
+		return false
+	}
+	next := cron.Next(since)
+	return !next.IsZero() && !next.After(now)
+}
+
+// ScheduleResponse is the schedule of a scenario, when it runs next and its latest run
+type ScheduleResponse struct {
+	ScenarioID int         `json:"scenarioId"`
+	Schedule   string      `json:"schedule,omitempty"`
+	NextRun    *time.Time  `json:"nextRun,omitempty"`
+	LastRun    *db.TestRun `json:"lastRun,omitempty"`
+}
+
+// ScenarioScheduleHandler answers when the scenario named in the path runs next on its
+// schedule and with its latest run, whether it was scheduled or started through the API
+func ScenarioScheduleHandler(scenarios db.ScenarioStore, runs db.RunStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		stored, ok := scenarioByPath(w, r, scenarios)
+		if !ok {
+			return
+		}
+		s, err := fromStoredScenario(*stored)
+		if err != nil {
+			writeError(w, r, err, "failed to decode scenario")
+			return
+		}
+
+		response := ScheduleResponse{ScenarioID: stored.ID, Schedule: s.Schedule}
+		if cron, err := scenario.ParseCron(s.Schedule); err == nil {
+			if next := cron.Next(time.Now()); !next.IsZero() {
+				response.NextRun = &next
+			}
+		}
+		latest, _, err := runs.Runs(db.RunFilter{ScenarioID: stored.ID, Limit: 1})
+		if err != nil {
+			writeError(w, r, err, "failed to retrieve test runs")
+			return
+		}
+		if len(latest) > 0 {
+			response.LastRun = &latest[0]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/scenario"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioScheduler(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "events", Type: "json", Version: "1.0.0", SchemaData: eventSchema})
+	assert.NoError(t, err)
+	for _, s := range []scenario.Scenario{
+		{Name: "events-nightly", Schedule: "0 2 * * *"},
+		{Name: "events-adhoc"},
+	} {
+		s.Topic, s.MessageCount, s.Mode = "events.created", 1, scenario.ModeValid
+		s.Schema = scenario.SchemaRef{Name: "events", Type: "json", Version: "1.0.0"}
+		stored, err := toStoredScenario(s)
+		assert.NoError(t, err)
+		_, err = store.CreateScenario(stored)
+		assert.NoError(t, err)
+	}
+
+	release := make(chan struct{})
+	runner := runnerFunc(func(_ context.Context, runID string, insts []scenario.Instance) scenario.Report {
+		<-release
+		return scenario.Report{RunID: runID, Instances: len(insts), Passed: len(insts)}
+	})
+	scheduler := NewScenarioScheduler(store, store, runner, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	night := time.Date(2026, 10, 1, 1, 59, 45, 0, time.UTC)
+	started, err := scheduler.Tick(ctx, night)
+	assert.NoError(t, err)
+	assert.Empty(t, started, "the first tick only marks the time")
+
+	started, err = scheduler.Tick(ctx, night.Add(30*time.Second))
+	assert.NoError(t, err)
+	if assert.Len(t, started, 1) {
+		assert.Equal(t, "events-nightly", started[0].ScenarioName)
+		assert.Equal(t, db.RunRunning, started[0].Status)
+	}
+
+	started, err = scheduler.Tick(ctx, night.Add(24*time.Hour+30*time.Second))
+	assert.NoError(t, err)
+	assert.Empty(t, started, "a scenario still running is skipped")
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		runs, _, err := store.Runs(db.RunFilter{})
+		return err == nil && len(runs) == 1 && runs[0].Status == db.RunPassed
+	}, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		started, err = scheduler.Tick(ctx, night.Add(48*time.Hour+30*time.Second))
+		return err == nil && len(started) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mux := scenarioMux(store, runner)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scenarios/1/schedule", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var schedule ScheduleResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &schedule))
+	assert.Equal(t, "0 2 * * *", schedule.Schedule)
+	if assert.NotNil(t, schedule.NextRun) {
+		assert.Equal(t, 2, schedule.NextRun.Hour())
+		assert.True(t, schedule.NextRun.After(time.Now()))
+	}
+	if assert.NotNil(t, schedule.LastRun) {
+		assert.Equal(t, started[0].RunID, schedule.LastRun.RunID)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scenarios/2/schedule", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	schedule = ScheduleResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &schedule))
+	assert.Empty(t, schedule.Schedule)
+	assert.Nil(t, schedule.NextRun)
+	assert.Nil(t, schedule.LastRun)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/scenarios/9/schedule", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
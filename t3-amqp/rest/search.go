@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"t3-amqp/db"
+)
+
+// SearchHit is a schema found by a search. Matched lists where the query was found, name
+// or data.
+type SearchHit struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Version string   `json:"version"`
+	Status  string   `json:"status"`
+	Rank    float64  `json:"rank"`
+	Matched []string `json:"matched"`
+	// Fingerprint is empty for schemas registered before every type got one
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// SearchSchemasHandler finds schemas by the q query parameter, best matches first. Names
+// match by substring and similarity, with data=true the field, record and message names
+// inside schema documents are searched too. type limits the search to one schema type
+// and limit bounds the number of hits.
+func SearchSchemasHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		searcher, ok := store.(db.Searcher)
+		if !ok {
+			http.Error(w, "schema search is not supported by this store", http.StatusNotImplemented)
+			return
+		}
+
+		q := r.URL.Query()
+		query := db.SearchQuery{Text: q.Get("q"), Type: q.Get("type")}
+		if query.Text == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		var err error
+		if v := q.Get("data"); v != "" {
+			if query.InData, err = strconv.ParseBool(v); err != nil {
+				http.Error(w, fmt.Sprintf("invalid data %q", v), http.StatusBadRequest)
+				return
+			}
+		}
+		if v := q.Get("limit"); v != "" {
+			if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 0 {
+				http.Error(w, fmt.Sprintf("invalid limit %q", v), http.StatusBadRequest)
+				return
+			}
+		}
+
+		results, err := searcher.Search(query)
+		if err != nil {
+			writeError(w, r, err, "failed to search schemas")
+			return
+		}
+		hits := make([]SearchHit, len(results))
+		for i, result := range results {
+			s := result.Schema
+			hits[i] = SearchHit{
+				ID: s.ID, Name: s.Name, Type: s.Type, Version: s.Version, Status: s.Status, Rank: result.Rank,
+				Matched: []string{}, Fingerprint: s.Fingerprint,
+			}
+			if result.NameMatched {
+				hits[i].Matched = append(hits[i].Matched, "name")
+			}
+			if result.DataMatched {
+				hits[i].Matched = append(hits[i].Matched, "data")
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(hits)
+	}
+}
@@ -0,0 +1,64 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchSchemasHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	for _, args := range []db.QueryArgs{
+		{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"properties":{"customerId":{"type":"string"}}}`},
+		{Name: "team-a.orders-archive", Type: "avro", Version: "1.0.0", SchemaData: `{"type":"string"}`},
+		{
+			Name: "payments", Type: "avro", Version: "1.0.0",
+			SchemaData: `{"type":"record","name":"Payment","fields":[{"name":"customerId","type":"long"}]}`,
+		},
+	} {
+		_, err := store.Insert(args)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	handler := rest.SearchSchemasHandler(store)
+	search := func(target string) ([]rest.SearchHit, int) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		var hits []rest.SearchHit
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&hits))
+		}
+		return hits, rr.Code
+	}
+
+	hits, code := search("/schemas/search?q=ORDERS")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, "orders", hits[0].Name, "the exact name ranks first")
+		assert.Equal(t, []string{"name"}, hits[0].Matched)
+		assert.Equal(t, "team-a.orders-archive", hits[1].Name)
+	}
+
+	hits, _ = search("/schemas/search?q=customerid")
+	assert.Empty(t, hits, "documents are only searched with data=true")
+
+	hits, _ = search("/schemas/search?q=customer&data=true&type=avro")
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "payments", hits[0].Name)
+		assert.Equal(t, []string{"data"}, hits[0].Matched)
+	}
+
+	hits, _ = search("/schemas/search?q=orders&limit=1")
+	assert.Len(t, hits, 1)
+
+	_, code = search("/schemas/search")
+	assert.Equal(t, http.StatusBadRequest, code)
+	_, code = search("/schemas/search?q=orders&data=maybe")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"t3-amqp/db"
+	"t3-amqp/gitsync"
+	"time"
+)
+
+// SnapshotPrincipal names the timer-driven snapshots in their commit messages
+const SnapshotPrincipal = "git-snapshot"
+
+// DefaultSnapshotAuthor signs snapshot commits when no author is configured
+const DefaultSnapshotAuthor = "t3 <t3@localhost>"
+
+// errSnapshotRepo is returned when the snapshot repository could not be pulled or pushed
+var errSnapshotRepo = errors.New("failed to update the snapshot repository")
+
+// SnapshotResponse is the outcome of one snapshot of the registry
+type SnapshotResponse struct {
+	Taken   time.Time `json:"taken"`
+	Schemas int       `json:"schemas"`
+	// Skipped lists the name/type/version of the schemas of types without a file extension
+	Skipped []string `json:"skipped,omitempty"`
+	Commit  string   `json:"commit,omitempty"`
+	Changed bool     `json:"changed"`
+	Pushed  bool     `json:"pushed"`
+}
+
+// SchemaSnapshotter writes every live schema below dir in the layout SchemaSyncer reads,
+// removing the files of schemas that are gone. With a repository dir is in its checkout
+// and the snapshot is committed and, with push, pushed, so the registry can be backed up
+// and its changes reviewed like code.
+type SchemaSnapshotter struct {
+	store  db.SchemaStore
+	repo   *gitsync.Repo
+	root   string
+	push   bool
+	author string
+
+	// snapshots must not interleave their writes
+	mu sync.Mutex
+}
+
+// NewSchemaSnapshotter creates a snapshotter writing below dir of the checkout of repo,
+// or below dir itself when repo is nil
+func NewSchemaSnapshotter(
+	store db.SchemaStore, repo *gitsync.Repo, dir string, push bool, author string,
+) *SchemaSnapshotter {
+	root := dir
+	if repo != nil {
+		root = filepath.Join(repo.Dir, dir)
+	}
+	if author == "" {
+		author = DefaultSnapshotAuthor
+	}
+	return &SchemaSnapshotter{store: store, repo: repo, root: root, push: push, author: author}
+}
+
+// Run takes a snapshot every interval until ctx is done, of the default tenant
+func (s *SchemaSnapshotter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	r := backgroundRequest(ctx, SnapshotPrincipal, "/admin/snapshot")
+	for {
+		response, err := s.Snapshot(r)
+		if err != nil {
+			log.Printf("Failed to snapshot schemas: %v", err)
+		} else if response.Changed {
+			log.Printf("Snapshot of %d schemas committed as %s", response.Schemas, response.Commit)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot writes the live schemas of the tenant of r and commits them on behalf of its
+// caller
+func (s *SchemaSnapshotter) Snapshot(r *http.Request) (*SnapshotResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.repo != nil {
+		if _, err := s.repo.Pull(r.Context()); err != nil {
+			log.Printf("Failed to pull %s: %v", s.repo.URL, err)
+			return nil, errSnapshotRepo
+		}
+	}
+
+	response := &SnapshotResponse{Taken: time.Now().UTC()}
+	var files []gitsync.SchemaFile
+	err := scopedStore(r, s.store).List(
+		db.ListOptions{}, func(schema db.Schema) error {
+			p, ok := gitsync.FilePath(schema.Name, schema.Type, schema.Version)
+			if !ok {
+				response.Skipped = append(response.Skipped, schema.Name+"/"+schema.Type+"/"+schema.Version)
+				return nil
+			}
+			files = append(
+				files, gitsync.SchemaFile{
+					Name: schema.Name, Type: schema.Type, Version: schema.Version, Path: p, Data: schema.SchemaData,
+				},
+			)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := gitsync.Write(s.root, files); err != nil {
+		return nil, fmt.Errorf("failed to write the snapshot: %w", err)
+	}
+	response.Schemas = len(files)
+	if s.repo == nil {
+		response.Changed = true
+		return response, nil
+	}
+
+	message := fmt.Sprintf("Snapshot of %d schemas by %s", len(files), actorFor(r))
+	response.Commit, response.Changed, err = s.repo.Commit(r.Context(), message, s.author, s.push)
+	if err != nil {
+		log.Printf("Failed to commit the snapshot to %s: %v", s.repo.URL, err)
+		return nil, errSnapshotRepo
+	}
+	response.Pushed = s.push && response.Changed
+	return response, nil
+}
+
+// SnapshotHandler takes a snapshot of the registry with POST. Without a configured
+// snapshot directory it answers 503, and 502 when the repository cannot be updated.
+func SnapshotHandler(snapshotter *SchemaSnapshotter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if snapshotter == nil {
+			http.Error(w, "schema snapshots are not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		response, err := snapshotter.Snapshot(r)
+		switch {
+		case errors.Is(err, errSnapshotRepo):
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		case err != nil:
+			http.Error(w, "failed to snapshot schemas", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"t3-amqp/db"
+	"t3-amqp/gitsync"
+	"t3-amqp/quota"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	for _, args := range []db.QueryArgs{
+		{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`},
+		{Name: "orders.created", Type: "avro", Version: "1", SchemaData: `"string"`},
+		{Name: "invoices", Type: "csv", Version: "1", SchemaData: "id,total"},
+	} {
+		_, err := store.Insert(args)
+		assert.NoError(t, err)
+	}
+	post := func(handler http.HandlerFunc) (*httptest.ResponseRecorder, rest.SnapshotResponse) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil))
+		var response rest.SnapshotResponse
+		_ = json.NewDecoder(rr.Body).Decode(&response)
+		return rr, response
+	}
+
+	dir := t.TempDir()
+	rr, response := post(rest.SnapshotHandler(rest.NewSchemaSnapshotter(store, nil, dir, false, "")))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 2, response.Schemas)
+	assert.Equal(t, []string{"invoices/csv/1"}, response.Skipped)
+	data, err := os.ReadFile(filepath.Join(dir, "orders", "created", "1.avsc"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"string"`, string(data))
+
+	rr = httptest.NewRecorder()
+	rest.SnapshotHandler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	rr = httptest.NewRecorder()
+	rest.SnapshotHandler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestSnapshotHandlerRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origin := t.TempDir()
+	out, err := exec.Command("git", "init", "--quiet", "--initial-branch", "main", origin).CombinedOutput()
+	assert.NoError(t, err, string(out))
+	commitSchemas(t, origin, map[string]string{"README.md": "registry snapshots"})
+	bare := filepath.Join(t.TempDir(), "registry.git")
+	out, err = exec.Command("git", "clone", "--quiet", "--bare", origin, bare).CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	store := db.NewMemoryStore()
+	_, err = store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`})
+	assert.NoError(t, err)
+	repo := gitsync.NewRepo(bare, "main", filepath.Join(t.TempDir(), "snapshot"))
+	handler := rest.SnapshotHandler(rest.NewSchemaSnapshotter(store, repo, "schemas", true, ""))
+	post := func() (*httptest.ResponseRecorder, rest.SnapshotResponse) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil))
+		var response rest.SnapshotResponse
+		_ = json.NewDecoder(rr.Body).Decode(&response)
+		return rr, response
+	}
+
+	rr, response := post()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, response.Changed)
+	assert.True(t, response.Pushed)
+	assert.Len(t, response.Commit, 40)
+
+	rr, again := post()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, again.Changed, "an unchanged registry commits nothing")
+	assert.Equal(t, response.Commit, again.Commit)
+
+	// The snapshot round trips through a sync
+	synced := db.NewMemoryStore()
+	syncer := rest.NewSchemaSyncer(
+		synced, quota.NewManager(quota.Config{}), gitsync.NewRepo(bare, "main", filepath.Join(t.TempDir(), "sync")),
+		"schemas",
+	)
+	rr = httptest.NewRecorder()
+	rest.SyncHandler(syncer).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/sync", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	schemas, err := synced.Filter(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0"})
+	assert.NoError(t, err)
+	if assert.Len(t, schemas, 1) {
+		assert.Equal(t, `{"type":"object"}`, schemas[0].SchemaData)
+	}
+
+	broken := gitsync.NewRepo(filepath.Join(bare, "missing"), "main", filepath.Join(t.TempDir(), "broken"))
+	rr = httptest.NewRecorder()
+	rest.SnapshotHandler(rest.NewSchemaSnapshotter(store, broken, "", false, "")).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/snapshot", nil))
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+}
@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"t3-amqp/db"
+	"time"
+)
+
+// statsTTL is how long computed statistics are reused, dashboards polling every few
+// seconds then cost at most one round of aggregate queries per interval
+const statsTTL = 5 * time.Second
+
+type cachedStats struct {
+	computed time.Time
+	stats    *db.SchemaStats
+}
+
+// statsCache keeps the statistics of each tenant and set of namespaces
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedStats
+}
+
+func (c *statsCache) get(pool *pgxpool.Pool, tenant string, namespaces []string) (*db.SchemaStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := statsKey(tenant, namespaces)
+	if cached, ok := c.entries[key]; ok && time.Since(cached.computed) < statsTTL {
+		return cached.stats, nil
+	}
+	stats, err := db.GetSchemaStats(pool, tenant, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	c.entries[key] = cachedStats{computed: time.Now(), stats: stats}
+	return stats, nil
+}
+
+// statsKey identifies the statistics of tenant limited to namespaces, callers granted the
+// same namespaces in any order share them
+func statsKey(tenant string, namespaces []string) string {
+	namespaces = slices.Clone(namespaces)
+	slices.Sort(namespaces)
+	return tenant + "/" + strings.Join(namespaces, ",")
+}
+
+// SchemaCountHandler returns the total number of registered schemas, deleted ones are
+// only counted with include_deleted=true
+func SchemaCountHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		count, err := store.Count(includeDeleted(r))
+		if err != nil {
+			http.Error(w, "failed to count schemas", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(map[string]int{"count": count})
+		if err != nil {
+			return
+		}
+	}
+}
+
+// StatsHandler returns the totals of the request's tenant and the caller's namespaces by
+// type, namespace and status and the daily growth
+func StatsHandler(pool *pgxpool.Pool) http.HandlerFunc {
+	cache := &statsCache{entries: map[string]cachedStats{}}
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, err := cache.get(pool, requestTenant(r), callerNamespaces(r))
+		if err != nil {
+			http.Error(w, "failed to compute statistics", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "private, max-age=5")
+		varyCaller(w)
+		err = json.NewEncoder(w).Encode(stats)
+		if err != nil {
+			return
+		}
+	}
+}
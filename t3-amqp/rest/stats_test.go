@@ -0,0 +1,13 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsKey(t *testing.T) {
+	assert.Equal(t, statsKey("acme", []string{"orders", "billing"}), statsKey("acme", []string{"billing", "orders"}))
+	assert.NotEqual(t, statsKey("acme", nil), statsKey("acme", []string{"orders"}), "restricted callers do not share")
+	assert.NotEqual(t, statsKey("acme", []string{"orders"}), statsKey("globex", []string{"orders"}))
+}
@@ -0,0 +1,248 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaEndpointHandlerWithMemoryStore(t *testing.T) {
+	store := db.NewMemoryStore()
+	handler := rest.SchemaEndpointHandler(store)
+	serve := func(method, target string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, target, &buf))
+		return rr
+	}
+
+	schema := rest.SchemaRequest{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"objekt"}`}
+	rr := serve(http.MethodPost, "/schema", schema)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "at /type")
+
+	schema.SchemaData = `{"type":"object"}`
+	rr = serve(http.MethodPost, "/schema", schema)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	rr = serve(http.MethodGet, "/schema?name=orders&type=json&version=1.0.0", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"orders"`)
+
+	schema.SchemaData = `{"$schema":"http://json-schema.org/draft-04/schema#"}`
+	rr = serve(http.MethodPut, "/schema", schema)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	schema.SchemaData = `{"type":"array"}`
+	rr = serve(http.MethodPut, "/schema", schema)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	schema.Version = "9.9.9"
+	rr = serve(http.MethodPut, "/schema", schema)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = serve(http.MethodDelete, "/schema?name=orders&type=json&version=1.0.0", nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	rr = serve(http.MethodDelete, "/schema?id=1", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	entries := store.AuditEntries()
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, "192.0.2.1", entry.SourceIP)
+	}
+	if !assert.Equal(t, []string{db.AuditActionInsert, db.AuditActionUpdate, db.AuditActionDelete}, actions) {
+		return
+	}
+	assert.Nil(t, entries[0].Before)
+	assert.JSONEq(t, `{"type":"object"}`, string(entries[0].After.SchemaData))
+	assert.JSONEq(t, `{"type":"object"}`, string(entries[1].Before.SchemaData))
+	assert.JSONEq(t, `{"type":"array"}`, string(entries[1].After.SchemaData))
+	assert.Equal(t, "orders", entries[2].Before.Name)
+	assert.Nil(t, entries[2].After)
+}
+
+func TestSchemaEndpointHandlerUpsert(t *testing.T) {
+	store := db.NewMemoryStore()
+	handler := rest.SchemaEndpointHandler(store)
+	serve := func(method string, body rest.SchemaRequest) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, "/schema", &buf))
+		return rr
+	}
+
+	schema := rest.SchemaRequest{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPut, schema).Code, "PUT only updates")
+
+	rr := serve(http.MethodPatch, schema)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created db.Schema
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Equal(t, fmt.Sprintf("/schema/%d", created.ID), rr.Header().Get("Location"))
+
+	schema.SchemaData = `{"type":"array"}`
+	rr = serve(http.MethodPatch, schema)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var updated db.Schema
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&updated))
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, `{"type":"array"}`, updated.SchemaData)
+
+	schema.SchemaData = `{"type":"objekt"}`
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPatch, schema).Code)
+
+	var actions []string
+	for _, entry := range store.AuditEntries() {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []string{db.AuditActionInsert, db.AuditActionUpdate}, actions)
+}
+
+func TestPostSchemaHandlerRejectsDuplicateAvro(t *testing.T) {
+	store := db.NewMemoryStore()
+	handler := rest.PostSchemaHandler(store)
+	post := func(version, data string) int {
+		var buf bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buf).Encode(rest.SchemaRequest{Name: "orders", Type: "avro", Version: version, SchemaData: data}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", &buf))
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, post("1.0.0", `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`))
+	assert.Equal(t, http.StatusConflict, post("1.0.1", `{"name": "Order", "type": "record", "fields": [{"type": "long", "name": "id"}]}`))
+	assert.Equal(t, http.StatusCreated, post("1.1.0", `{"type":"record","name":"Order","fields":[{"name":"id","type":"string"}]}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, post("2.0.0", `{"type":"record"}`))
+
+	schemas, err := store.Filter(db.QueryArgs{Name: "orders"})
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 2)
+	assert.NotEmpty(t, schemas[0].Fingerprint)
+}
+
+func TestPostSchemaHandlerConflict(t *testing.T) {
+	store := db.NewMemoryStore()
+	handler := rest.PostSchemaHandler(store)
+	post := func() *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		schema := rest.SchemaRequest{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+		assert.NoError(t, json.NewEncoder(&buf).Encode(schema))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/schema", &buf))
+		return rr
+	}
+
+	rr := post()
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var created rest.CreatedResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Len(t, created.Fingerprint, 64)
+
+	rr = post()
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, fmt.Sprintf("/schema/%d", created.ID), rr.Header().Get("Location"))
+	var conflict rest.ConflictResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&conflict))
+	assert.Equal(t, created.ID, conflict.ID)
+
+	count, err := store.Count(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestGetAllSchemasHandlerPages(t *testing.T) {
+	store := db.NewMemoryStore()
+	for _, name := range []string{"orders", "invoices", "accounts"} {
+		_, err := store.Insert(db.QueryArgs{Name: name, Type: "json", Version: "1.0.0"})
+		assert.NoError(t, err)
+	}
+	handler := rest.GetAllSchemasHandler(store)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/schemas?limit=2&offset=0&sort=name", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get("X-Total-Count"))
+	assert.Contains(t, rr.Header().Get("Link"), `rel="next"`)
+
+	var page []db.Schema
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+	assert.Len(t, page, 2)
+	assert.Equal(t, "accounts", page[0].Name)
+	assert.Equal(t, "invoices", page[1].Name)
+
+	for _, target := range []string{"/schemas?limit=-1", "/schemas?offset=x", "/schemas?sort=schema_data"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+}
+
+func TestGetSchemaByIdHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{}`})
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	mux.HandleFunc("/schema", rest.GetSchemaFilterParamsHandler(store))
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	for _, target := range []string{fmt.Sprintf("/schema/%d", id), "/schema?name=orders&type=json&version=1.0.0"} {
+		rr := get(target)
+		assert.Equal(t, http.StatusOK, rr.Code, target)
+		var schema db.Schema
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&schema), target)
+		assert.Equal(t, id, schema.ID)
+		assert.NotEmpty(t, rr.Header().Get("ETag"))
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/schema/99").Code)
+	assert.Equal(t, http.StatusNotFound, get("/schema?name=orders&type=json&version=2.0.0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/schema/abc").Code)
+}
+
+func TestRestoreSchemaHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	id, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{}`})
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete(id))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.GetSchemaFilterParamsHandler(store))
+	mux.HandleFunc("/schemas", rest.GetAllSchemasHandler(store))
+	mux.HandleFunc("POST /schema/{id}/restore", rest.RestoreSchemaHandler(store))
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, target, nil))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/schema?name=orders&type=json&version=1.0.0").Code)
+	rr := serve(http.MethodGet, "/schemas?include_deleted=true")
+	assert.Equal(t, "1", rr.Header().Get("X-Total-Count"))
+	rr = serve(http.MethodGet, "/schemas")
+	assert.Equal(t, "0", rr.Header().Get("X-Total-Count"))
+
+	restore := fmt.Sprintf("/schema/%d/restore", id)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, restore).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, restore).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/schema?name=orders&type=json&version=1.0.0").Code)
+	assert.Equal(t, db.AuditActionRestore, store.AuditEntries()[0].Action)
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// flushEvery controls how many items are written between explicit flushes
+const flushEvery = 100
+
+// wantsNDJSON reports whether the client asked for newline delimited JSON
+func wantsNDJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "ndjson" ||
+		strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+}
+
+// streamWriter writes a listing item by item, either as a chunked JSON array or as NDJSON,
+// flushing periodically so nothing is buffered beyond the current item
+type streamWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	ndjson  bool
+	count   int
+	flusher http.Flusher
+}
+
+func newStreamWriter(w http.ResponseWriter, r *http.Request) *streamWriter {
+	sw := &streamWriter{w: w, enc: json.NewEncoder(w), ndjson: wantsNDJSON(r)}
+	sw.flusher, _ = w.(http.Flusher)
+
+	if sw.ndjson {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	return sw
+}
+
+// Write encodes one item of the listing
+func (sw *streamWriter) Write(item interface{}) error {
+	if !sw.ndjson {
+		sep := ","
+		if sw.count == 0 {
+			sep = "["
+		}
+		if _, err := io.WriteString(sw.w, sep); err != nil {
+			return err
+		}
+	}
+
+	// Encode terminates every item with a newline which is exactly what NDJSON needs
+	if err := sw.enc.Encode(item); err != nil {
+		return err
+	}
+
+	sw.count++
+	if sw.flusher != nil && sw.count%flushEvery == 0 {
+		sw.flusher.Flush()
+	}
+	return nil
+}
+
+// Close terminates the listing
+func (sw *streamWriter) Close() error {
+	if !sw.ndjson {
+		end := "]\n"
+		if sw.count == 0 {
+			end = "[]\n"
+		}
+		if _, err := io.WriteString(sw.w, end); err != nil {
+			return err
+		}
+	}
+	if sw.flusher != nil {
+		sw.flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWriterJSONArray(t *testing.T) {
+	rr := httptest.NewRecorder()
+	sw := newStreamWriter(rr, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	assert.NoError(t, sw.Write(map[string]int{"id": 1}))
+	assert.NoError(t, sw.Write(map[string]int{"id": 2}))
+	assert.NoError(t, sw.Close())
+
+	var items []map[string]int
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &items))
+	assert.Equal(t, []map[string]int{{"id": 1}, {"id": 2}}, items)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+}
+
+func TestStreamWriterEmptyArray(t *testing.T) {
+	rr := httptest.NewRecorder()
+	sw := newStreamWriter(rr, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	assert.NoError(t, sw.Close())
+	assert.Equal(t, "[]\n", rr.Body.String())
+}
+
+func TestStreamWriterNDJSON(t *testing.T) {
+	rr := httptest.NewRecorder()
+	sw := newStreamWriter(rr, httptest.NewRequest(http.MethodGet, "/schemas?format=ndjson", nil))
+	assert.NoError(t, sw.Write(map[string]int{"id": 1}))
+	assert.NoError(t, sw.Write(map[string]int{"id": 2}))
+	assert.NoError(t, sw.Close())
+
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", rr.Body.String())
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+}
@@ -0,0 +1,205 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"t3-amqp/gitsync"
+	"t3-amqp/quota"
+	"time"
+)
+
+// SyncPrincipal names the timer-driven syncs in audit entries
+const SyncPrincipal = "git-sync"
+
+// errSyncPull is returned when the schema repository could not be cloned or fetched
+var errSyncPull = errors.New("failed to pull the schema repository")
+
+// SyncResult is the outcome of registering one schema file
+type SyncResult struct {
+	ImportResult
+	Path string `json:"path"`
+}
+
+// SyncResponse is the outcome of one sync of the schema repository
+type SyncResponse struct {
+	Commit    string       `json:"commit"`
+	Synced    time.Time    `json:"synced"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	Invalid   int          `json:"invalid"`
+	Results   []SyncResult `json:"results"`
+}
+
+// SchemaSyncer registers the schema files of a Git repository, laid out as gitsync.Scan
+// expects below dir, as new or updated versions. Unlike imports a sync is not all or
+// nothing: invalid files are reported and the valid ones registered anyway, so one bad
+// commit does not hold the registry back.
+type SchemaSyncer struct {
+	store db.SchemaStore
+	quota *quota.Manager
+	repo  *gitsync.Repo
+	dir   string
+
+	mu sync.Mutex
+	// last holds the latest sync of each tenant
+	last map[string]*SyncResponse
+}
+
+// NewSchemaSyncer creates a syncer for the schema files below dir in repo
+func NewSchemaSyncer(store db.SchemaStore, q *quota.Manager, repo *gitsync.Repo, dir string) *SchemaSyncer {
+	return &SchemaSyncer{store: store, quota: q, repo: repo, dir: dir, last: map[string]*SyncResponse{}}
+}
+
+// Run syncs the repository every interval until ctx is done, as SyncPrincipal in the
+// default tenant
+func (s *SchemaSyncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	r := backgroundRequest(ctx, SyncPrincipal, "/sync")
+	for {
+		response, err := s.Sync(r)
+		if err != nil {
+			log.Printf("Failed to sync schemas: %v", err)
+		} else if response.Created+response.Updated+response.Invalid > 0 {
+			log.Printf(
+				"Synced schemas at %s: %d created, %d updated, %d invalid",
+				response.Commit, response.Created, response.Updated, response.Invalid,
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backgroundRequest stands in for a request to path made by principal in the default
+// tenant, for work done on a timer that is audited like requests
+func backgroundRequest(ctx context.Context, principal, path string) *http.Request {
+	ctx = context.WithValue(ctx, principalKey{}, &auth.Principal{ID: principal, Scope: auth.ScopeReadWrite})
+	return (&http.Request{Method: http.MethodPost, URL: &url.URL{Path: path}, Header: http.Header{}}).WithContext(ctx)
+}
+
+// Sync pulls the repository and registers its schema files in the tenant of r, on
+// behalf of the caller of r
+func (s *SchemaSyncer) Sync(r *http.Request) (*SyncResponse, error) {
+	commit, err := s.repo.Pull(r.Context())
+	if err != nil {
+		log.Printf("Failed to pull %s: %v", s.repo.URL, err)
+		return nil, errSyncPull
+	}
+	files, err := gitsync.Scan(filepath.Join(s.repo.Dir, s.dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema files: %w", err)
+	}
+
+	store := scopedStore(r, s.store)
+	response := &SyncResponse{Commit: commit, Synced: time.Now().UTC(), Results: make([]SyncResult, len(files))}
+	results := make([]ImportResult, len(files))
+	params := make([]db.QueryArgs, len(files))
+	for i, file := range files {
+		entry := SchemaRequest{Name: file.Name, Type: file.Type, Version: file.Version, SchemaData: file.Data}
+		result, err := planImport(store, entry, &params[i])
+		if err != nil {
+			return nil, err
+		}
+		if result.Action != ImportInvalid && !db.NamespaceAllowed(callerNamespaces(r), file.Name) {
+			result.Action, result.Error = ImportInvalid, db.ErrNamespaceDenied.Error()
+		}
+		switch result.Action {
+		case ImportCreated:
+			response.Created++
+		case ImportUpdated:
+			response.Updated++
+		case ImportUnchanged:
+			response.Unchanged++
+		default:
+			response.Invalid++
+		}
+		results[i] = result
+	}
+
+	if response.Created > 0 && s.quota.Config().MaxSchemas > 0 {
+		count, err := store.Count(false)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.quota.CheckSchemaCount(count + response.Created - 1); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyImport(store, r, results, params); err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		response.Results[i] = SyncResult{ImportResult: result, Path: files[i].Path}
+	}
+
+	s.mu.Lock()
+	s.last[requestTenant(r)] = response
+	s.mu.Unlock()
+	return response, nil
+}
+
+// Last returns the latest sync in tenant, nil before the first
+func (s *SchemaSyncer) Last(tenant string) *SyncResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last[tenant]
+}
+
+// SyncHandler syncs the schema repository with POST and returns the latest sync of the
+// tenant with GET. Without a configured repository it answers 503, and 502 when the
+// repository cannot be pulled.
+func SyncHandler(syncer *SchemaSyncer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if syncer == nil {
+			http.Error(w, "schema sync is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		var response *SyncResponse
+		if r.Method == http.MethodGet {
+			if response = syncer.Last(requestTenant(r)); response == nil {
+				http.Error(w, "the schemas have not been synced yet", http.StatusNotFound)
+				return
+			}
+		} else {
+			var err error
+			response, err = syncer.Sync(r)
+			switch {
+			case errors.Is(err, errSyncPull):
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			case errors.Is(err, quota.ErrSchemaQuota):
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			case err != nil:
+				http.Error(w, "failed to sync schemas", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"t3-amqp/db"
+	"t3-amqp/gitsync"
+	"t3-amqp/quota"
+	"t3-amqp/rest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// commitSchemas writes files to the repository in dir and commits them
+func commitSchemas(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(data), 0o644))
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=t3", "-c", "user.email=t3@example.com", "commit", "--quiet", "-m", "schemas"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+}
+
+func TestSyncHandler(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origin := t.TempDir()
+	out, err := exec.Command("git", "init", "--quiet", "--initial-branch", "main", origin).CombinedOutput()
+	assert.NoError(t, err, string(out))
+	commitSchemas(
+		t, origin, map[string]string{
+			"schemas/orders/1.0.0.json":       `{"type":"object"}`,
+			"schemas/orders/created/1.avsc":   `{"type":"record","name":"Created","fields":[]}`,
+			"schemas/invoices/1.0.0.json":     `{"type":"objekt"}`,
+			"docs/orders/1.0.0.json":          `{"type":"array"}`,
+			"schemas/orders/1.0.0.schema.txt": "ignored",
+		},
+	)
+
+	store := db.NewMemoryStore()
+	repo := gitsync.NewRepo(origin, "main", filepath.Join(t.TempDir(), "checkout"))
+	syncer := rest.NewSchemaSyncer(store, quota.NewManager(quota.Config{}), repo, "schemas")
+	handler := rest.SyncHandler(syncer)
+	do := func(method string) (*httptest.ResponseRecorder, rest.SyncResponse) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, "/sync", nil))
+		var response rest.SyncResponse
+		_ = json.NewDecoder(rr.Body).Decode(&response)
+		return rr, response
+	}
+
+	rr, _ := do(http.MethodGet)
+	assert.Equal(t, http.StatusNotFound, rr.Code, "nothing synced yet")
+
+	rr, response := do(http.MethodPost)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.Commit, 40)
+	assert.Equal(t, []int{2, 0, 0, 1}, []int{response.Created, response.Updated, response.Unchanged, response.Invalid})
+	if assert.Len(t, response.Results, 3) {
+		assert.Equal(t, "invoices/1.0.0.json", response.Results[0].Path)
+		assert.Equal(t, rest.ImportInvalid, response.Results[0].Action)
+		assert.Equal(t, "orders.created", response.Results[2].Name)
+		assert.Equal(t, "avro", response.Results[2].Type)
+	}
+	synced, err := store.Filter(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0"})
+	assert.NoError(t, err)
+	assert.Len(t, synced, 1, "valid files are registered next to invalid ones")
+	entries := store.AuditEntries()
+	if assert.NotEmpty(t, entries) {
+		assert.Equal(t, db.AuditActionInsert, entries[0].Action)
+	}
+
+	commitSchemas(
+		t, origin, map[string]string{
+			"schemas/orders/1.0.0.json":   `{"type":"array"}`,
+			"schemas/invoices/1.0.0.json": `{"type":"object"}`,
+		},
+	)
+	rr, response = do(http.MethodPost)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []int{1, 1, 1, 0}, []int{response.Created, response.Updated, response.Unchanged, response.Invalid})
+	updated, err := store.GetByID(synced[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"array"}`, updated.SchemaData)
+
+	rr, last := do(http.MethodGet)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, response.Commit, last.Commit)
+
+	rr = httptest.NewRecorder()
+	rest.SyncHandler(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/sync", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	missing := gitsync.NewRepo(filepath.Join(origin, "missing"), "main", filepath.Join(t.TempDir(), "checkout"))
+	rr = httptest.NewRecorder()
+	rest.SyncHandler(rest.NewSchemaSyncer(store, quota.NewManager(quota.Config{}), missing, "")).
+		ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/sync", nil))
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+
+	full := rest.NewSchemaSyncer(db.NewMemoryStore(), quota.NewManager(quota.Config{MaxSchemas: 1}), repo, "schemas")
+	rr = httptest.NewRecorder()
+	rest.SyncHandler(full).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/sync", nil))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestSchemaSyncerRun(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origin := t.TempDir()
+	out, err := exec.Command("git", "init", "--quiet", "--initial-branch", "main", origin).CombinedOutput()
+	assert.NoError(t, err, string(out))
+	commitSchemas(t, origin, map[string]string{"orders/1.0.0.json": `{"type":"object"}`})
+
+	store := db.NewMemoryStore()
+	repo := gitsync.NewRepo(origin, "main", filepath.Join(t.TempDir(), "checkout"))
+	syncer := rest.NewSchemaSyncer(store, quota.NewManager(quota.Config{}), repo, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go syncer.Run(ctx, time.Hour)
+
+	assert.Eventually(t, func() bool { return syncer.Last(db.DefaultTenant) != nil }, 5*time.Second, 10*time.Millisecond)
+	entries := store.AuditEntries()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, rest.SyncPrincipal, entries[0].Actor, "timer syncs are attributed to the syncer")
+	}
+}
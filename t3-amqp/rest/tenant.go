@@ -0,0 +1,152 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"t3-amqp/db"
+)
+
+// TenantHeader names the tenant a request works in, callers bound to a tenant by their
+// credentials may leave it out
+const TenantHeader = "X-Tenant"
+
+type tenantKey struct{}
+
+// Tenant returns the tenant the request works in, DefaultTenant when none was chosen
+func Tenant(ctx context.Context) string {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok && tenant != "" {
+		return tenant
+	}
+	return db.DefaultTenant
+}
+
+// requestTenant returns the tenant r works in
+func requestTenant(r *http.Request) string {
+	return Tenant(r.Context())
+}
+
+// callerTenant returns the tenant the caller's credentials bind it to, empty when it may
+// choose one
+func callerTenant(r *http.Request) string {
+	if principal := Principal(r.Context()); principal != nil {
+		return principal.Tenant
+	}
+	return ""
+}
+
+// TenantMiddleware selects the tenant of every request: the one the caller's credentials
+// are bound to, otherwise the one named by X-Tenant and DefaultTenant without either.
+// Bound callers naming another tenant are answered 403 and unknown tenants 404.
+// Health checks, metrics, the OpenAPI document and the UI are not tenant-scoped.
+func TenantMiddleware(tenants db.TenantStore, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isProbe(r.URL.Path) || r.URL.Path == "/metrics" || r.URL.Path == "/openapi.json" || isUI(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tenant := r.Header.Get(TenantHeader)
+		if bound := callerTenant(r); bound != "" {
+			if tenant != "" && tenant != bound {
+				writeError(w, r, fmt.Errorf("%s: %w", tenant, db.ErrTenantDenied), "")
+				return
+			}
+			tenant = bound
+		}
+		if tenant == "" {
+			tenant = db.DefaultTenant
+		}
+		if _, err := tenants.TenantByID(tenant); err != nil {
+			writeError(w, r, err, "failed to check tenant")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, tenant)))
+	}
+}
+
+type TenantRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// TenantsHandler manages tenants: GET lists them, POST creates one and DELETE removes the
+// tenant with the id in the query string once it owns no schemas, api keys, scenarios or
+// webhooks.
+// Callers bound to a tenant only see their own and cannot create or remove tenants.
+func TenantsHandler(tenants db.TenantStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bound := callerTenant(r)
+		switch r.Method {
+		case http.MethodGet:
+			var list []db.Tenant
+			if bound != "" {
+				tenant, err := tenants.TenantByID(bound)
+				if err != nil {
+					writeError(w, r, err, "failed to retrieve tenants")
+					return
+				}
+				list = []db.Tenant{*tenant}
+			} else {
+				var err error
+				if list, err = tenants.Tenants(); err != nil {
+					writeError(w, r, err, "failed to retrieve tenants")
+					return
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			err := json.NewEncoder(w).Encode(list)
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			if bound != "" {
+				writeError(w, r, db.ErrTenantDenied, "")
+				return
+			}
+			var req TenantRequest
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			if req.Name == "" {
+				req.Name = req.ID
+			}
+			created, err := tenants.CreateTenant(db.Tenant{ID: req.ID, Name: req.Name})
+			if err != nil {
+				writeError(w, r, err, "failed to create tenant")
+				return
+			}
+			log.Printf("Created tenant %s (%s)", created.ID, created.Name)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(created)
+			if err != nil {
+				return
+			}
+
+		case http.MethodDelete:
+			if bound != "" {
+				writeError(w, r, db.ErrTenantDenied, "")
+				return
+			}
+			id := r.URL.Query().Get("id")
+			if id == "" {
+				http.Error(w, "id is required", http.StatusBadRequest)
+				return
+			}
+			if err := tenants.DeleteTenant(id); err != nil {
+				writeError(w, r, err, "failed to delete tenant")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.CreateTenant(db.Tenant{ID: "acme", Name: "Acme"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	mux.HandleFunc("/admin/keys", rest.APIKeysHandler(store))
+	mux.HandleFunc("/admin/tenants", rest.TenantsHandler(store))
+	handler := rest.AuthMiddleware(
+		auth.NewAuthenticator(true, "bootstrap-secret", store, nil), mux, rest.TenantMiddleware(store, mux),
+	)
+
+	serve := func(method, target, key, tenant string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req := httptest.NewRequest(method, target, &buf)
+		req.Header.Set("X-API-Key", key)
+		if tenant != "" {
+			req.Header.Set(rest.TenantHeader, tenant)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	schema := rest.SchemaRequest{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/schema", "bootstrap-secret", "", schema).Code)
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/schema", "bootstrap-secret", "acme", schema).Code)
+	rr := serve(http.MethodGet, "/schema?name=orders", "bootstrap-secret", "nope", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = serve(http.MethodPost, "/admin/keys", "bootstrap-secret", "", rest.APIKeyRequest{
+		Name: "acme-admin", Scope: auth.ScopeAdmin, Tenant: "acme",
+	})
+	if !assert.Equal(t, http.StatusCreated, rr.Code) {
+		return
+	}
+	var created rest.CreatedAPIKey
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&created))
+	assert.Equal(t, "acme", created.Tenant)
+
+	rr = serve(http.MethodGet, "/schema?name=orders", created.Key, "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var schemas []db.Schema
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&schemas))
+	if assert.Len(t, schemas, 1) {
+		assert.Equal(t, "acme", schemas[0].Tenant)
+	}
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/schema?name=orders", created.Key, "default", nil).Code)
+
+	// Keys bound to a tenant only see their tenant and hand out keys bound to it
+	rr = serve(http.MethodGet, "/admin/tenants", created.Key, "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var tenants []db.Tenant
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&tenants))
+	if assert.Len(t, tenants, 1) {
+		assert.Equal(t, "acme", tenants[0].ID)
+	}
+	rr = serve(http.MethodPost, "/admin/tenants", created.Key, "", rest.TenantRequest{ID: "globex"})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = serve(http.MethodPost, "/admin/keys", created.Key, "", rest.APIKeyRequest{Name: "acme-ci"})
+	if assert.Equal(t, http.StatusCreated, rr.Code) {
+		var ci rest.CreatedAPIKey
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&ci))
+		assert.Equal(t, "acme", ci.Tenant)
+	}
+	rr = serve(http.MethodPost, "/admin/keys", created.Key, "", rest.APIKeyRequest{Name: "x", Tenant: "default"})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = serve(http.MethodPost, "/admin/tenants", "bootstrap-secret", "", rest.TenantRequest{ID: "globex"})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr = serve(http.MethodDelete, "/admin/tenants?id=acme", "bootstrap-secret", "", nil)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	rr = serve(http.MethodDelete, "/admin/tenants?id=globex", "bootstrap-secret", "", nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestTenantScopedTestResources(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.CreateTenant(db.Tenant{ID: "acme", Name: "Acme"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	mux.HandleFunc("/scenarios", rest.ScenariosHandler(store, store))
+	mux.HandleFunc("/scenarios/{id}", rest.ScenarioHandler(store, store))
+	mux.HandleFunc("/runs", rest.RunsHandler(store))
+	mux.HandleFunc("/captures", rest.CapturesHandler(store, nil, rest.CaptureLimits{}, nil))
+	mux.HandleFunc("/captures/{id}", rest.CaptureHandler(store))
+	mux.HandleFunc("/webhooks", rest.WebhooksHandler(store))
+	handler := rest.AuthMiddleware(
+		auth.NewAuthenticator(true, "bootstrap-secret", store, nil), mux, rest.TenantMiddleware(store, mux),
+	)
+	serve := func(method, target, tenant string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			assert.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req := httptest.NewRequest(method, target, &buf)
+		req.Header.Set("X-API-Key", "bootstrap-secret")
+		if tenant != "" {
+			req.Header.Set(rest.TenantHeader, tenant)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	schema := rest.SchemaRequest{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/schema", "", schema).Code)
+	definition := map[string]any{
+		"name": "orders-smoke", "topic": "orders.created", "messageCount": 1,
+		"schema": map[string]string{"name": "orders", "type": "json", "version": "1.0.0"},
+	}
+	rr := serve(http.MethodPost, "/scenarios", "", definition)
+	if !assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String()) {
+		return
+	}
+	// Scenarios are checked against the schemas of their own tenant
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/scenarios", "acme", definition).Code)
+	_, err = store.CreateRun(db.TestRun{RunID: "run-1", ScenarioName: "orders-smoke", Status: db.RunPassed})
+	assert.NoError(t, err)
+	_, err = store.CreateCapture(db.Capture{Topic: "orders.created", Status: db.CaptureFinished})
+	assert.NoError(t, err)
+	hook := map[string]any{"url": "https://example.com/hook"}
+	assert.Equal(t, http.StatusCreated, serve(http.MethodPost, "/webhooks", "", hook).Code)
+
+	for _, path := range []string{"/scenarios", "/captures", "/webhooks"} {
+		assert.Equal(t, "[]\n", serve(http.MethodGet, path, "acme", nil).Body.String(), path)
+		assert.NotEqual(t, "[]\n", serve(http.MethodGet, path, "", nil).Body.String(), path)
+	}
+	assert.Equal(t, "0", serve(http.MethodGet, "/runs", "acme", nil).Header().Get("X-Total-Count"))
+	assert.Equal(t, "1", serve(http.MethodGet, "/runs", "", nil).Header().Get("X-Total-Count"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/scenarios/1", "acme", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/captures/1", "acme", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/webhooks?id=1", "acme", nil).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/scenarios/1", "", nil).Code)
+}
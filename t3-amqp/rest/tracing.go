@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"t3-amqp/tracing"
+)
+
+// TracingMiddleware records a server span for every request, continuing the trace of an
+// incoming traceparent header. Spans are named after the route pattern from routes so
+// they group like the request metrics. Probes and metrics scrapes are not traced.
+func TracingMiddleware(routes Router, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isProbe(r.URL.Path) || r.URL.Path == "/metrics" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := tracing.ExtractHTTP(r.Context(), r.Header)
+		ctx, span := tracing.Tracer().Start(
+			ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+				attribute.String("http.request_id", RequestID(r.Context())),
+			),
+		)
+		defer span.End()
+
+		sr := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(sr, r.WithContext(ctx))
+
+		status := sr.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
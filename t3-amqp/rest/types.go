@@ -1,8 +1,8 @@
 package rest
 
 type SchemaRequest struct {
-	Name       string `json:"name" binding:"required"`
+	Name       string `json:"name" binding:"required,name"`
 	Type       string `json:"type" binding:"required"`
-	Version    string `json:"version" binding:"required"`
-	SchemaData string `json:"schemaData" binding:"required"`
+	Version    string `json:"version" binding:"required,version"`
+	SchemaData string `json:"schemaData" binding:"required,schema"`
 }
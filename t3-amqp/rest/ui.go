@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// uiFiles is the single-page UI, it talks to the registry through the REST API only
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// isUI reports whether path is part of the UI rather than the API
+func isUI(path string) bool {
+	return path == "/" || strings.HasPrefix(path, "/ui/")
+}
+
+// UIHandler serves the web UI for browsing schemas and test runs and registering schemas,
+// its page at / and its assets under /ui/. The page itself is public, the API calls it
+// makes carry the key entered in the UI.
+func UIHandler() http.HandlerFunc {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	assets := http.StripPrefix("/ui/", http.FileServerFS(files))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; style-src 'self'; img-src 'self' data:")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if r.URL.Path == "/" {
+			http.ServeFileFS(w, r, files, "index.html")
+			return
+		}
+		assets.ServeHTTP(w, r)
+	}
+}
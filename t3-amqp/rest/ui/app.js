@@ -0,0 +1,431 @@
+'use strict';
+
+// The UI only uses the public REST API, with the API key and tenant kept in localStorage
+const view = document.getElementById('view');
+const pageSize = 50;
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  setAttrs(node, attrs);
+  node.append(...children.flat().filter((c) => c !== null && c !== undefined));
+  return node;
+}
+
+function svg(tag, attrs, ...children) {
+  const node = document.createElementNS('http://www.w3.org/2000/svg', tag);
+  setAttrs(node, attrs);
+  node.append(...children);
+  return node;
+}
+
+function setAttrs(node, attrs) {
+  for (const [name, value] of Object.entries(attrs || {})) {
+    if (name.startsWith('on')) {
+      node.addEventListener(name.slice(2), value);
+    } else if (value !== false && value !== null && value !== undefined) {
+      node.setAttribute(name, value === true ? '' : value);
+    }
+  }
+}
+
+// api calls the registry with the saved credentials and throws the problem detail of
+// failed requests
+async function api(path, options = {}) {
+  const headers = new Headers(options.headers);
+  const key = localStorage.getItem('t3.apiKey');
+  const tenant = localStorage.getItem('t3.tenant');
+  if (key) headers.set('X-API-Key', key);
+  if (tenant) headers.set('X-Tenant', tenant);
+  const res = await fetch(path, { ...options, headers });
+  if (!res.ok) {
+    let detail = await res.text();
+    try {
+      const problem = JSON.parse(detail);
+      detail = problem.detail || problem.error || problem.title || detail;
+    } catch (_) {
+      // plain text error
+    }
+    throw new Error(`${res.status} ${res.statusText}: ${detail.trim()}`);
+  }
+  return res;
+}
+
+async function getJSON(path) {
+  const res = await api(path);
+  return { body: await res.json(), res };
+}
+
+function formatTime(value) {
+  return value ? new Date(value).toLocaleString() : '';
+}
+
+function formatDuration(ns) {
+  if (!ns) return '–';
+  if (ns < 1e6) return `${(ns / 1e3).toFixed(0)} µs`;
+  if (ns < 1e9) return `${(ns / 1e6).toFixed(1)} ms`;
+  return `${(ns / 1e9).toFixed(2)} s`;
+}
+
+function status(value) {
+  return el('span', { class: `status status-${value || 'active'}` }, value || 'active');
+}
+
+// pretty indents JSON and Avro schema documents, other documents are shown as they are
+function pretty(data) {
+  try {
+    return JSON.stringify(JSON.parse(data), null, 2);
+  } catch (_) {
+    return data;
+  }
+}
+
+// lineDiff compares two documents line by line with a longest common subsequence
+function lineDiff(a, b) {
+  const x = a.split('\n');
+  const y = b.split('\n');
+  if (x.length * y.length > 4e6) {
+    return [...x.map((line) => ['del', line]), ...y.map((line) => ['add', line])];
+  }
+  const lcs = Array.from({ length: x.length + 1 }, () => new Uint32Array(y.length + 1));
+  for (let i = x.length - 1; i >= 0; i--) {
+    for (let j = y.length - 1; j >= 0; j--) {
+      lcs[i][j] = x[i] === y[j] ? lcs[i + 1][j + 1] + 1 : Math.max(lcs[i + 1][j], lcs[i][j + 1]);
+    }
+  }
+  const out = [];
+  let i = 0;
+  let j = 0;
+  while (i < x.length || j < y.length) {
+    if (i < x.length && j < y.length && x[i] === y[j]) {
+      out.push(['same', x[i]]);
+      i++;
+      j++;
+    } else if (j < y.length && (i === x.length || lcs[i][j + 1] >= lcs[i + 1][j])) {
+      out.push(['add', y[j++]]);
+    } else {
+      out.push(['del', x[i++]]);
+    }
+  }
+  return out;
+}
+
+function table(headings, rows) {
+  return el(
+    'table', {},
+    el('thead', {}, el('tr', {}, headings.map((h) => el('th', {}, h)))),
+    el('tbody', {}, rows),
+  );
+}
+
+function linkRow(href, cells) {
+  return el('tr', { class: 'link', onclick: () => { location.hash = href; } }, cells.map((c) => el('td', {}, c)));
+}
+
+// barChart draws stacked bars, one per item, series are [label, color, value(item)]
+function barChart(items, label, series, height = 160) {
+  const width = Math.max(320, items.length * 28);
+  const max = Math.max(1, ...items.map((item) => series.reduce((sum, [, , value]) => sum + value(item), 0)));
+  const plot = height - 20;
+  const step = width / Math.max(1, items.length);
+  const chart = svg('svg', { viewBox: `0 0 ${width} ${height}`, role: 'img' });
+  items.forEach((item, i) => {
+    let y = plot;
+    for (const [name, color, value] of series) {
+      const h = (value(item) / max) * (plot - 10);
+      y -= h;
+      chart.append(
+        svg(
+          'rect', { x: i * step + 3, y, width: Math.max(1, step - 6), height: h, fill: color },
+          svg('title', {}, `${label(item)}: ${value(item)} ${name}`),
+        ),
+      );
+    }
+    chart.append(svg('text', { x: i * step + step / 2, y: height - 6, 'text-anchor': 'middle' }, label(item)));
+  });
+  chart.append(svg('text', { x: 2, y: 10 }, String(max)));
+  return el('div', { class: 'chart' }, legend(series), chart);
+}
+
+// lineChart draws one line per series over the items, skipping items without a value
+function lineChart(items, label, series, format, height = 160) {
+  const width = Math.max(320, items.length * 28);
+  const values = items.flatMap((item) => series.map(([, , value]) => value(item) || 0));
+  const max = Math.max(1, ...values);
+  const plot = height - 20;
+  const step = width / Math.max(1, items.length);
+  const chart = svg('svg', { viewBox: `0 0 ${width} ${height}`, role: 'img' });
+  for (const [name, color, value] of series) {
+    const points = [];
+    items.forEach((item, i) => {
+      const v = value(item);
+      if (!v) return;
+      const x = i * step + step / 2;
+      const y = plot - (v / max) * (plot - 10);
+      points.push(`${x},${y}`);
+      chart.append(svg('circle', { cx: x, cy: y, r: 3, fill: color }, svg('title', {}, `${label(item)}: ${name} ${format(v)}`)));
+    });
+    chart.append(svg('polyline', { points: points.join(' '), fill: 'none', stroke: color, 'stroke-width': 1.5 }));
+  }
+  items.forEach((item, i) => {
+    chart.append(svg('text', { x: i * step + step / 2, y: height - 6, 'text-anchor': 'middle' }, label(item)));
+  });
+  chart.append(svg('text', { x: 2, y: 10 }, format(max)));
+  return el('div', { class: 'chart' }, legend(series), chart);
+}
+
+function legend(series) {
+  return el('div', { class: 'legend' }, series.map(([name, color]) => {
+    const swatch = el('i');
+    swatch.style.background = color;
+    return el('span', {}, swatch, name);
+  }));
+}
+
+const colors = { pass: '#2e9d5b', fail: '#d64545', p50: '#2f6fde', p95: '#b26b00', p99: '#8a3fc7' };
+
+async function schemasView() {
+  let offset = 0;
+  const search = el('input', { type: 'search', placeholder: 'Search names and fields' });
+  const body = el('div');
+  const pager = el('span', { class: 'muted' });
+  const prev = el('button', { type: 'button', onclick: () => { offset -= pageSize; load().catch(showError); } }, 'Previous');
+  const next = el('button', { type: 'button', onclick: () => { offset += pageSize; load().catch(showError); } }, 'Next');
+
+  const rows = (schemas) => schemas.map((s) => linkRow(`#/schemas/${s.id}`, [s.name, s.type, s.version, status(s.status), formatTime(s.modified)]));
+  const headings = ['Name', 'Type', 'Version', 'Status', 'Modified'];
+
+  async function load() {
+    const q = search.value.trim();
+    if (q) {
+      const { body: hits } = await getJSON(`/schemas/search?data=true&limit=${pageSize}&q=${encodeURIComponent(q)}`);
+      body.replaceChildren(hits.length ? table(headings, rows(hits)) : el('p', { class: 'muted' }, 'No schemas match.'));
+      pager.textContent = `${hits.length} matches`;
+      prev.disabled = next.disabled = true;
+      return;
+    }
+    const { body: schemas, res } = await getJSON(`/schemas?limit=${pageSize}&offset=${offset}&sort=name,-modified`);
+    const total = Number(res.headers.get('X-Total-Count')) || schemas.length;
+    const list = schemas.map((s) => ({ id: s.ID, name: s.Name, type: s.Type, version: s.Version, status: s.Status, modified: s.Modified }));
+    body.replaceChildren(list.length ? table(headings, rows(list)) : el('p', { class: 'muted' }, 'No schemas registered yet.'));
+    pager.textContent = total ? `${offset + 1}–${offset + list.length} of ${total}` : '';
+    prev.disabled = offset === 0;
+    next.disabled = offset + list.length >= total;
+  }
+
+  let timer;
+  search.addEventListener('input', () => {
+    clearTimeout(timer);
+    timer = setTimeout(() => { offset = 0; load().catch(showError); }, 250);
+  });
+  await load();
+  view.replaceChildren(el('h2', {}, 'Schemas'), el('div', { class: 'toolbar' }, search, prev, next, pager), body);
+}
+
+async function schemaView(id) {
+  const { body: schema } = await getJSON(`/schema/${id}`);
+  const { body: versions } = await getJSON(`/schema/versions?name=${encodeURIComponent(schema.Name)}&type=${encodeURIComponent(schema.Type)}`);
+  const data = pretty(schema.SchemaData);
+  const content = el('div', {}, el('pre', {}, data));
+
+  const others = versions.versions.filter((v) => v.id !== schema.ID);
+  const compare = el(
+    'select', {},
+    el('option', { value: '' }, 'Compare with…'),
+    others.map((v) => el('option', { value: v.id }, `${v.version}${v.latest ? ' (latest)' : ''}`)),
+  );
+  compare.addEventListener('change', () => {
+    showDiff(compare.value).catch(showError);
+  });
+
+  async function showDiff(otherId) {
+    if (!otherId) {
+      content.replaceChildren(el('pre', {}, data));
+      return;
+    }
+    const { body: other } = await getJSON(`/schema/${otherId}`);
+    // Canonical forms sort keys, so the line diff only shows what really changed
+    const canonical = async (s) => getJSON(`/schema/${s.ID}?format=canonical`)
+      .then(({ body }) => pretty(body.SchemaData))
+      .catch(() => pretty(s.SchemaData));
+    const [before, after] = await Promise.all([canonical(other), canonical(schema)]);
+    const { body: changes } = await getJSON(
+      `/schema/diff?name=${encodeURIComponent(schema.Name)}&type=${encodeURIComponent(schema.Type)}` +
+      `&from=${encodeURIComponent(other.Version)}&to=${encodeURIComponent(schema.Version)}`,
+    ).catch((err) => ({ body: { error: err.message } }));
+    const lines = lineDiff(before, after).map(([kind, line]) => {
+      const prefix = { add: '+ ', del: '- ', same: '  ' }[kind];
+      return el('span', { class: kind }, prefix + line);
+    });
+    content.replaceChildren(
+      el('h3', {}, `Changes from ${other.Version} to ${schema.Version}`),
+      fieldChanges(changes),
+      el('pre', { class: 'diff' }, lines),
+    );
+  }
+
+  view.replaceChildren(
+    el('h2', {}, `${schema.Name} `, el('span', { class: 'muted' }, `${schema.Type} ${schema.Version}`)),
+    el(
+      'div', { class: 'stats' },
+      el('div', {}, el('span', { class: 'muted' }, 'Status'), status(schema.Status)),
+      el('div', {}, el('span', { class: 'muted' }, 'Created'), formatTime(schema.Created)),
+      el('div', {}, el('span', { class: 'muted' }, 'Modified'), formatTime(schema.Modified)),
+      schema.Fingerprint ? el('div', {}, el('span', { class: 'muted' }, 'Fingerprint'), el('code', {}, schema.Fingerprint)) : null,
+    ),
+    el('div', { class: 'toolbar' }, compare, el('a', { href: '#/schemas' }, 'All schemas')),
+    content,
+  );
+}
+
+function fieldChanges(d) {
+  if (d.error) return el('p', { class: 'muted' }, `No field comparison: ${d.error}`);
+  const items = [
+    ...(d.added || []).map((f) => `added ${f.path}`),
+    ...(d.removed || []).map((f) => `removed ${f.path}`),
+    ...(d.changed || []).map((c) => `${c.path}: ${c.kind} ${JSON.stringify(c.from)} → ${JSON.stringify(c.to)}`),
+  ];
+  if (!items.length) return el('p', { class: 'muted' }, 'Both versions describe the same fields.');
+  return el('ul', {}, items.map((item) => el('li', {}, item)));
+}
+
+async function runsView() {
+  const { body } = await getJSON(`/runs?limit=${pageSize}`);
+  const runs = body.runs || [];
+  const rows = runs.map((r) => linkRow(`#/runs/${r.id}`, [
+    r.scenarioName || r.kind, r.topic, status(r.status), formatTime(r.started),
+    `${r.messagesValid}/${r.messagesSent}`, String(r.failures), formatDuration(r.latencyP95Ns),
+  ]));
+
+  // Oldest first so the charts read left to right
+  const history = runs.filter((r) => r.status !== 'running').reverse();
+  const label = (r) => `#${r.id}`;
+  view.replaceChildren(
+    el('h2', {}, 'Test runs ', el('span', { class: 'muted' }, `${body.total} in total`)),
+    history.length ? barChart(history, label, [
+      ['valid', colors.pass, (r) => r.messagesValid],
+      ['failures', colors.fail, (r) => r.failures],
+    ]) : null,
+    history.some((r) => r.latencyP95Ns) ? lineChart(history, label, [
+      ['p50', colors.p50, (r) => r.latencyP50Ns],
+      ['p95', colors.p95, (r) => r.latencyP95Ns],
+      ['p99', colors.p99, (r) => r.latencyP99Ns],
+    ], formatDuration) : null,
+    runs.length
+      ? table(['Scenario', 'Topic', 'Status', 'Started', 'Valid', 'Failures', 'Latency p95'], rows)
+      : el('p', { class: 'muted' }, 'No test runs yet.'),
+  );
+}
+
+async function runView(id) {
+  const { body: run } = await getJSON(`/runs/${id}`);
+  const results = run.results || [];
+  const stat = (name, value) => el('div', {}, el('span', { class: 'muted' }, name), el('strong', {}, value));
+  const label = (r) => `#${r.instance}`;
+
+  view.replaceChildren(
+    el('h2', {}, `${run.scenarioName || run.kind} `, el('span', { class: 'muted' }, run.runId)),
+    el(
+      'div', { class: 'stats' },
+      el('div', {}, el('span', { class: 'muted' }, 'Status'), status(run.status)),
+      stat('Sent', String(run.messagesSent)),
+      stat('Valid', String(run.messagesValid)),
+      stat('Failures', String(run.failures)),
+      stat('Latency p50 / p95 / p99', [run.latencyP50Ns, run.latencyP95Ns, run.latencyP99Ns].map(formatDuration).join(' / ')),
+      run.throughput ? stat('Throughput', `${run.throughput.toFixed(1)} msg/s`) : null,
+    ),
+    el('p', { class: 'muted' }, `Topic ${run.topic}, schema ${run.schemaName}, started ${formatTime(run.started)}`),
+    results.length ? barChart(results, label, [
+      ['valid', colors.pass, (r) => r.messagesValid],
+      ['failures', colors.fail, (r) => r.failures],
+    ]) : null,
+    results.some((r) => r.latencyP95Ns) ? lineChart(results, label, [
+      ['p50', colors.p50, (r) => r.latencyP50Ns],
+      ['p95', colors.p95, (r) => r.latencyP95Ns],
+      ['p99', colors.p99, (r) => r.latencyP99Ns],
+    ], formatDuration) : null,
+    results.length ? table(
+      ['Instance', 'Queue', 'Duration', 'Sent', 'Valid', 'Failures', 'Redelivered', 'Latency p95'],
+      results.map((r) => el('tr', {}, [
+        label(r), r.tempQueue, formatDuration(r.durationNs), String(r.messagesSent), String(r.messagesValid),
+        String(r.failures), String(r.redelivered), formatDuration(r.latencyP95Ns),
+      ].map((c) => el('td', {}, c)))),
+    ) : el('p', { class: 'muted' }, run.status === 'running' ? 'The run is still in progress.' : 'No instance results.'),
+    el('p', {}, el('a', { href: '#/runs' }, 'All runs')),
+  );
+}
+
+function registerView() {
+  const message = el('p');
+  const form = el(
+    'form', { class: 'register' },
+    el('label', { for: 'name' }, 'Name'), el('input', { id: 'name', name: 'name', required: true }),
+    el('label', { for: 'type' }, 'Type'),
+    el('select', { id: 'type', name: 'type' }, el('option', { value: 'json' }, 'JSON Schema'), el('option', { value: 'avro' }, 'Avro')),
+    el('label', { for: 'version' }, 'Version'), el('input', { id: 'version', name: 'version', required: true, placeholder: '1.0.0' }),
+    el('label', { for: 'schemaData' }, 'Schema'), el('textarea', { id: 'schemaData', name: 'schemaData', required: true, spellcheck: 'false' }),
+    el('p', {}, el('button', { type: 'submit' }, 'Register')),
+    message,
+  );
+  form.addEventListener('submit', async (event) => {
+    event.preventDefault();
+    const data = Object.fromEntries(new FormData(form));
+    message.className = '';
+    message.textContent = 'Registering…';
+    try {
+      const res = await api('/schema', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(data),
+      });
+      const { id } = await res.json();
+      location.hash = `#/schemas/${id}`;
+    } catch (err) {
+      message.className = 'error';
+      message.textContent = err.message;
+    }
+  });
+  view.replaceChildren(el('h2', {}, 'Register schema'), form);
+}
+
+function showError(err) {
+  view.replaceChildren(el('p', { class: 'error' }, err.message));
+}
+
+const routes = [
+  [/^#\/schemas$/, schemasView],
+  [/^#\/schemas\/(\d+)$/, schemaView],
+  [/^#\/runs$/, runsView],
+  [/^#\/runs\/(\d+)$/, runView],
+  [/^#\/register$/, registerView],
+];
+
+async function route() {
+  const hash = location.hash || '#/schemas';
+  for (const link of document.querySelectorAll('nav a')) {
+    link.classList.toggle('active', hash.startsWith(link.getAttribute('href')));
+  }
+  const match = routes.map(([pattern, render]) => [hash.match(pattern), render]).find(([m]) => m);
+  if (!match) {
+    location.hash = '#/schemas';
+    return;
+  }
+  view.replaceChildren(el('p', { class: 'muted' }, 'Loading…'));
+  try {
+    await match[1](...match[0].slice(1));
+  } catch (err) {
+    showError(err);
+  }
+}
+
+const credentials = document.getElementById('credentials');
+credentials.elements['api-key'].value = localStorage.getItem('t3.apiKey') || '';
+credentials.elements.tenant.value = localStorage.getItem('t3.tenant') || '';
+credentials.addEventListener('submit', (event) => {
+  event.preventDefault();
+  localStorage.setItem('t3.apiKey', credentials.elements['api-key'].value.trim());
+  localStorage.setItem('t3.tenant', credentials.elements.tenant.value.trim());
+  route();
+});
+
+window.addEventListener('hashchange', route);
+route();
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", UIHandler())
+	mux.HandleFunc("GET /ui/", UIHandler())
+	mux.HandleFunc("/schemas", GetAllSchemasHandler(store))
+	handler := AuthMiddleware(auth.NewAuthenticator(true, "bootstrap-secret", store, nil), mux, mux)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	rr := serve(http.MethodGet, "/")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rr.Body.String(), `<script src="/ui/app.js"`)
+	assert.NotEmpty(t, rr.Header().Get("Content-Security-Policy"))
+
+	rr = serve(http.MethodGet, "/ui/app.js")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "javascript")
+	rr = serve(http.MethodGet, "/ui/app.css")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/css")
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/ui/missing.js").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/schemas").Code, "the API the UI calls is not public")
+
+	rr = httptest.NewRecorder()
+	UIHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"t3-amqp/blob"
+	"t3-amqp/db"
+)
+
+const (
+	DefaultUploadMaxBytes    = 64 << 20
+	DefaultUploadInlineBytes = 1 << 20
+)
+
+// UploadLimits bounds streaming schema uploads, zero values use the defaults
+type UploadLimits struct {
+	// MaxBytes is the largest document accepted at all
+	MaxBytes int64
+	// InlineBytes is the largest document stored directly in schema_data, anything bigger
+	// is spilled to the blob store and referenced from schema_data by a db.BlobRef
+	InlineBytes int64
+}
+
+type UploadResponse struct {
+	ID      int    `json:"id"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Spilled bool   `json:"spilled"`
+}
+
+// UploadSchemaHandler registers a schema whose document is the raw request body. The body
+// is streamed to a temporary file while it is hashed and only read back once it is known
+// to be within MaxBytes. name, type and version come from the query string. Every
+// document is checked and fingerprinted like posted schemas before large ones are
+// spilled, and the stores read spilled documents back wherever schemas are read.
+func UploadSchemaHandler(store db.SchemaStore, blobs blob.Store, limits UploadLimits) http.HandlerFunc {
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultUploadMaxBytes
+	}
+	if limits.InlineBytes <= 0 {
+		limits.InlineBytes = DefaultUploadInlineBytes
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		params := db.QueryArgs{Name: q.Get("name"), Type: q.Get("type"), Version: q.Get("version")}
+		if params.Name == "" || params.Type == "" || params.Version == "" {
+			http.Error(w, "name, type and version are required", http.StatusBadRequest)
+			return
+		}
+		if !allowNamespace(w, r, params.Name) {
+			return
+		}
+
+		tmp, err := os.CreateTemp("", "t3-upload-*")
+		if err != nil {
+			http.Error(w, "failed to buffer upload", http.StatusInternalServerError)
+			return
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		hasher := sha256.New()
+		body := http.MaxBytesReader(w, r.Body, limits.MaxBytes)
+		size, err := io.Copy(io.MultiWriter(tmp, hasher), body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(
+					w, fmt.Sprintf("schema exceeds the %d byte upload limit", limits.MaxBytes),
+					http.StatusRequestEntityTooLarge,
+				)
+				return
+			}
+			http.Error(w, "failed to read upload", http.StatusBadRequest)
+			return
+		}
+		sum := hex.EncodeToString(hasher.Sum(nil))
+
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			http.Error(w, "failed to buffer upload", http.StatusInternalServerError)
+			return
+		}
+
+		data, err := io.ReadAll(tmp)
+		if err != nil {
+			http.Error(w, "failed to buffer upload", http.StatusInternalServerError)
+			return
+		}
+		params.SchemaData = inlineSchemaData(data)
+		if err := prepareSchema(store, &params); err != nil {
+			writeError(w, r, err, "failed to check schema")
+			return
+		}
+
+		// Documents over the schema_data limit are spilled whatever InlineBytes allows, the
+		// blob is keyed by the hash of what schema_data would have held
+		response := UploadResponse{Size: size, SHA256: sum}
+		if int64(len(params.SchemaData)) > min(limits.InlineBytes, db.MaxSchemaBytes()) {
+			stored := sha256.Sum256([]byte(params.SchemaData))
+			key := "sha256/" + hex.EncodeToString(stored[:])
+			if err := blobs.Put(r.Context(), key, strings.NewReader(params.SchemaData)); err != nil {
+				http.Error(w, "failed to store schema document", http.StatusInternalServerError)
+				return
+			}
+			params.SchemaData = db.NewBlobRef(key, params.SchemaData)
+			response.Spilled = true
+		}
+
+		response.ID, err = store.Insert(params)
+		if errors.Is(err, db.ErrAlreadyExists) {
+			writeAlreadyExists(w, store, params)
+			return
+		}
+		if err != nil {
+			writeError(w, r, err, "failed to insert schema")
+			return
+		}
+		recordChange(store, r, db.AuditActionInsert, nil, insertedSchema(response.ID, params))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// inlineSchemaData keeps JSON documents as they are and wraps anything else (XSD,
+// protobuf text) in a JSON string so it fits the JSONB column
+func inlineSchemaData(data []byte) string {
+	if json.Valid(data) {
+		return string(data)
+	}
+	quoted, _ := json.Marshal(string(data))
+	return string(quoted)
+}
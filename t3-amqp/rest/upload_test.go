@@ -0,0 +1,64 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"t3-amqp/blob"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"t3-amqp/validate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadSchemaHandlerSpills(t *testing.T) {
+	blobs, err := blob.NewDirStore(t.TempDir())
+	if !assert.NoError(t, err) {
+		return
+	}
+	db.SetBlobStore(blobs)
+	t.Cleanup(func() { db.SetBlobStore(nil) })
+
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema/upload", rest.UploadSchemaHandler(store, blobs, rest.UploadLimits{InlineBytes: 16}))
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	upload := func(version, document string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		target := "/schema/upload?name=orders&type=json&version=" + version
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, target, strings.NewReader(document)))
+		return rr
+	}
+
+	// Spilled documents are checked like inline ones
+	rr := upload("1.0.0", `{"type": "object", "properties": {"id": {"type": 12}}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code, rr.Body.String())
+	count, _ := store.Count(true)
+	assert.Zero(t, count)
+
+	document := `{"type": "object", "required": ["id"]}`
+	rr = upload("1.0.0", document)
+	if !assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String()) {
+		return
+	}
+	var response rest.UploadResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.True(t, response.Spilled)
+
+	// Reads see the document rather than the reference standing in for it
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/schema/"+strconv.Itoa(response.ID), nil))
+	var schema db.Schema
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &schema))
+	assert.Equal(t, document, schema.SchemaData)
+	assert.NotEmpty(t, schema.Fingerprint)
+
+	validator, err := validate.NewCache(1).Validator(schema)
+	if assert.NoError(t, err) {
+		assert.Error(t, validator.Validate([]byte(`{}`)), "the spilled schema requires id")
+	}
+}
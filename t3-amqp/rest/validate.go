@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"t3-amqp/db"
+	"t3-amqp/validate"
+)
+
+type ValidateRequest struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Version string          `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type ValidateResponse struct {
+	Valid    bool   `json:"valid"`
+	SchemaID int    `json:"schemaId"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ValidateHandler validates a payload against a registered schema. XML payloads for xsd
+// schemas may be posted as application/xml with the schema in the query string, or as a
+// JSON string in the payload field. Validations run on
+// the bounded worker pool; when it is saturated the request is shed with a 429 and
+// once it has shut down with a 503.
+func ValidateHandler(store db.SchemaStore, workers *validate.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req ValidateRequest
+		if isXML(mediaType(r)) {
+			// Raw XML payloads name the schema in the query string
+			q := r.URL.Query()
+			req = ValidateRequest{Name: q.Get("name"), Type: q.Get("type"), Version: q.Get("version")}
+			payload, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeBodyError(w, err)
+				return
+			}
+			req.Payload = payload
+		} else if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Name == "" || req.Type == "" || req.Version == "" || len(req.Payload) == 0 {
+			http.Error(w, "name, type, version and payload are required", http.StatusBadRequest)
+			return
+		}
+
+		schemas, err := store.Filter(
+			db.QueryArgs{Name: req.Name, Type: req.Type, Version: req.Version},
+		)
+		if err != nil {
+			http.Error(w, "failed to retrieve schema", http.StatusInternalServerError)
+			return
+		}
+		if len(schemas) == 0 {
+			http.Error(w, "schema not found", http.StatusNotFound)
+			return
+		}
+		setLifecycleHeaders(w, schemas[0])
+		if schemas[0].Status == db.StatusRetired {
+			http.Error(w, "schema has been retired", http.StatusGone)
+			return
+		}
+
+		validator, err := validate.DefaultCache.Validator(schemas[0])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		response := ValidateResponse{Valid: true, SchemaID: schemas[0].ID}
+		err = workers.Validate(r.Context(), validator, req.Payload)
+		switch {
+		case errors.Is(err, validate.ErrOverloaded):
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		case errors.Is(err, validate.ErrPoolClosed):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			response.Valid = false
+			response.Error = err.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"t3-amqp/amqp"
+)
+
+// VerificationsHandler lists verification reports on GET and on POST starts consuming
+// the subscribed topic, validating every message against the registry. The new
+// verification is answered with 202 and its report is polled at /verify/{id}.
+func VerificationsHandler(verifications *amqp.Verifications) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if verifications == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			err := json.NewEncoder(w).Encode(verifications.List())
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			var sub amqp.Subscription
+			if !decodeJSON(w, r, &sub) {
+				return
+			}
+			if err := sub.Validate(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			// The verification outlives the request
+			run := verifications.Start(context.WithoutCancel(r.Context()), sub)
+			report := run.Report()
+			w.Header().Set("Location", "/verify/"+report.ID)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			err := json.NewEncoder(w).Encode(report)
+			if err != nil {
+				return
+			}
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// VerificationHandler returns the report of the verification named in the path, it is
+// final once finished is set
+func VerificationHandler(verifications *amqp.Verifications) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if verifications == nil {
+			http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		run, ok := verifications.Get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "verification not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(run.Report())
+		if err != nil {
+			return
+		}
+	}
+}
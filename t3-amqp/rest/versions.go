@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"net/http"
+	"t3-amqp/db"
+	"time"
+)
+
+type SchemaVersion struct {
+	ID       int       `json:"id"`
+	Version  string    `json:"version"`
+	Status   string    `json:"status"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+	Latest   bool      `json:"latest"`
+	// Fingerprint is empty for versions registered before every type got one
+	Fingerprint      string `json:"fingerprint,omitempty"`
+	RabinFingerprint string `json:"rabinFingerprint,omitempty"`
+}
+
+type VersionsResponse struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Versions []SchemaVersion `json:"versions"`
+}
+
+// SchemaVersionsHandler lists every version of the schema given by the name and type
+// query parameters in semantic version order, flagging the latest one so consumers
+// know which version to validate against
+func SchemaVersionsHandler(store db.SchemaStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		store := scopedStore(r, store)
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		schemaType := r.URL.Query().Get("type")
+		if name == "" || schemaType == "" {
+			http.Error(w, "name and type are required", http.StatusBadRequest)
+			return
+		}
+
+		schemas, err := store.Filter(db.QueryArgs{Name: name, Type: schemaType})
+		if err != nil {
+			http.Error(w, "failed to retrieve schemas", http.StatusInternalServerError)
+			return
+		}
+		if len(schemas) == 0 {
+			http.Error(w, "schema not found", http.StatusNotFound)
+			return
+		}
+
+		db.SortByVersion(schemas)
+		latest := db.LatestIndex(schemas)
+		response := VersionsResponse{Name: schemas[0].Name, Type: schemas[0].Type, Versions: []SchemaVersion{}}
+		for i, schema := range schemas {
+			response.Versions = append(
+				response.Versions, SchemaVersion{
+					ID: schema.ID, Version: schema.Version, Status: schema.Status, Created: schema.Created,
+					Modified: schema.Modified, Latest: i == latest, Fingerprint: schema.Fingerprint,
+					RabinFingerprint: schema.RabinFingerprint,
+				},
+			)
+		}
+
+		setLinkHeader(w, schemaLinks(response.Name, response.Type))
+		writeCacheable(w, r, response, lastModified(schemas))
+	}
+}
@@ -0,0 +1,122 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersionsHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	for _, version := range []string{"1.10.0", "1.2.0", "2.0.0-rc.1", "1.9.0"} {
+		_, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: version})
+		assert.NoError(t, err)
+	}
+	_, err := store.Insert(db.QueryArgs{Name: "orders", Type: "avro", Version: "3.0.0"})
+	assert.NoError(t, err)
+	handler := rest.SchemaVersionsHandler(store)
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	rr := get("/schema/versions?name=orders&type=json")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response rest.VersionsResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+
+	var versions, latest []string
+	for _, v := range response.Versions {
+		versions = append(versions, v.Version)
+		if v.Latest {
+			latest = append(latest, v.Version)
+		}
+	}
+	assert.Equal(t, []string{"1.2.0", "1.9.0", "1.10.0", "2.0.0-rc.1"}, versions)
+	assert.Equal(t, []string{"1.10.0"}, latest, "a prerelease is not the latest version")
+
+	assert.Equal(t, http.StatusNotFound, get("/schema/versions?name=invoices&type=json").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/schema/versions?name=orders").Code)
+}
+
+func TestGetSchemaFilterParamsHandlerLatest(t *testing.T) {
+	store := db.NewMemoryStore()
+	for _, version := range []string{"1.0.9", "1.0.10", "1.1.0-rc.1"} {
+		_, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: version})
+		assert.NoError(t, err)
+	}
+	handler := rest.GetSchemaFilterParamsHandler(store)
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr
+	}
+
+	rr := get("/schema?name=orders&type=json&version=latest")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var schema db.Schema
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&schema))
+	assert.Equal(t, "1.0.10", schema.Version)
+
+	assert.Equal(t, http.StatusNotFound, get("/schema?name=orders&type=avro&version=latest").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/schema?name=orders&version=latest").Code)
+}
+
+func TestGetSchemaFilterParamsHandlerFingerprint(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rr
+	}
+
+	var orders, invoices, events rest.CreatedResponse
+	rr := serve(
+		http.MethodPost, "/schema", `{"name":"orders","type":"json","version":"1.0.0","schemaData":"{\"type\": \"object\"}"}`,
+	)
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&orders))
+	rr = serve(
+		http.MethodPost, "/schema", `{"name":"invoices","type":"json","version":"1.0.0","schemaData":"{\"type\":\"object\"}"}`,
+	)
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&invoices))
+	rr = serve(
+		http.MethodPost, "/schema",
+		`{"name":"events","type":"avro","version":"1.0.0","schemaData":"{\"type\":\"fixed\",\"name\":\"Id\",\"size\":16}"}`,
+	)
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&events))
+	assert.Equal(t, orders.Fingerprint, invoices.Fingerprint, "the same content has the same fingerprint")
+	assert.Empty(t, orders.RabinFingerprint)
+	assert.Len(t, events.RabinFingerprint, 16)
+
+	var schemas []db.Schema
+	rr = serve(http.MethodGet, "/schema?fingerprint="+strings.ToUpper(orders.Fingerprint), "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&schemas))
+	if assert.Len(t, schemas, 2) {
+		assert.Equal(t, []string{"orders", "invoices"}, []string{schemas[0].Name, schemas[1].Name})
+		assert.Equal(t, orders.Fingerprint, schemas[0].Fingerprint)
+	}
+
+	rr = serve(http.MethodGet, "/schema?fingerprint="+events.RabinFingerprint, "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&schemas))
+	if assert.Len(t, schemas, 1) {
+		assert.Equal(t, events.ID, schemas[0].ID)
+		assert.Equal(t, events.Fingerprint, schemas[0].Fingerprint)
+	}
+
+	rr = serve(http.MethodGet, "/schema?name=orders&fingerprint="+events.Fingerprint, "")
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&schemas))
+	assert.Empty(t, schemas)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/schema?fingerprint=abc", "").Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/schema?fingerprint=zz"+orders.Fingerprint[2:], "").Code)
+}
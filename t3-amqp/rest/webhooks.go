@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"t3-amqp/db"
+	"t3-amqp/webhook"
+)
+
+type WebhookRequest struct {
+	URL string `json:"url"`
+	// Events limits the callbacks to these event types, empty sends every one
+	Events []string `json:"events"`
+	// Secret signs the callbacks, one is generated when it is empty
+	Secret string `json:"secret"`
+}
+
+// CreatedWebhook is answered once when a webhook is created, Secret is not retrievable later
+type CreatedWebhook struct {
+	db.Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhooksHandler manages the webhooks told about the schema changes of the tenant: GET
+// lists them without their secrets, POST registers one and returns its secret once and
+// DELETE removes the webhook with the id in the query string. Webhooks see every
+// namespace, so callers limited to some namespaces cannot manage them.
+func WebhooksHandler(hooks db.WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(callerNamespaces(r)) > 0 {
+			writeError(w, r, db.ErrNamespaceDenied, "")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			list, err := hooks.Webhooks(requestTenant(r))
+			if err != nil {
+				writeError(w, r, err, "failed to retrieve webhooks")
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(list)
+			if err != nil {
+				return
+			}
+
+		case http.MethodPost:
+			var req WebhookRequest
+			if !decodeJSON(w, r, &req) {
+				return
+			}
+			if err := checkWebhook(req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if req.Secret == "" {
+				secret, err := webhook.NewSecret()
+				if err != nil {
+					http.Error(w, "failed to generate webhook secret", http.StatusInternalServerError)
+					return
+				}
+				req.Secret = secret
+			}
+			created, err := hooks.CreateWebhook(
+				db.Webhook{URL: req.URL, Events: req.Events, Secret: req.Secret, Tenant: requestTenant(r)},
+			)
+			if err != nil {
+				writeError(w, r, err, "failed to create webhook")
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(w).Encode(CreatedWebhook{Webhook: *created, Secret: created.Secret})
+			if err != nil {
+				return
+			}
+
+		case http.MethodDelete:
+			id, err := strconv.Atoi(r.URL.Query().Get("id"))
+			if err != nil {
+				http.Error(w, "id is required", http.StatusBadRequest)
+				return
+			}
+			// Webhooks of other tenants are answered as not found
+			owned, err := hooks.Webhooks(requestTenant(r))
+			if err != nil {
+				writeError(w, r, err, "failed to retrieve webhooks")
+				return
+			}
+			if !slices.ContainsFunc(owned, func(hook db.Webhook) bool { return hook.ID == id }) {
+				writeError(w, r, db.ErrWebhookNotFound, "")
+				return
+			}
+			if err := hooks.DeleteWebhook(id); err != nil {
+				writeError(w, r, err, "failed to delete webhook")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// checkWebhook accepts absolute http and https URLs and the known event types
+func checkWebhook(req WebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhook.EventTypes, event) {
+			return fmt.Errorf("unknown event %q, expected one of %v", event, webhook.EventTypes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/auth"
+	"t3-amqp/db"
+	"t3-amqp/webhook"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhooksHandler(t *testing.T) {
+	store := db.NewMemoryStore()
+	handler := WebhooksHandler(store)
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, target, bytes.NewBufferString(body)))
+		return rr
+	}
+
+	rr := serve(http.MethodPost, "/admin/webhooks", `{"url":"https://ci.example.com/hook","events":["schema.created"]}`)
+	if !assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String()) {
+		return
+	}
+	var created CreatedWebhook
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Len(t, created.Secret, 64, "a secret is generated")
+	assert.Equal(t, []string{webhook.EventCreated}, created.Events)
+
+	rr = serve(http.MethodPost, "/admin/webhooks", `{"url":"http://cache.local/hook","secret":"mine"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"secret":"mine"`)
+
+	rr = serve(http.MethodGet, "/admin/webhooks", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "ci.example.com")
+	assert.NotContains(t, rr.Body.String(), "mine", "listings leave out the secrets")
+	assert.NotContains(t, rr.Body.String(), created.Secret)
+
+	for _, body := range []string{
+		`{"url":"ftp://example.com"}`, `{"url":"/relative"}`, `{"url":"https://example.com","events":["schema.renamed"]}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/admin/webhooks", body).Code, body)
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/admin/webhooks?id=1", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/admin/webhooks?id=1", "").Code)
+	hooks, _ := store.Webhooks("")
+	assert.Len(t, hooks, 1)
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/webhooks", nil)
+	principal := &auth.Principal{ID: "key:team-a", Namespaces: []string{"team-a"}}
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	assert.Equal(t, http.StatusForbidden, rr.Code, "webhooks see every namespace")
+}
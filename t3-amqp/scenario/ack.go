@@ -0,0 +1,85 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// AckAuto lets the broker consider messages acknowledged as soon as they are delivered
+	AckAuto = "auto"
+	// AckManual acknowledges every message once it has been handled
+	AckManual = "manual"
+	// AckBatch acknowledges BatchSize messages at a time with a single multiple ack
+	AckBatch = "batch"
+	// AckDelayed acknowledges every message Delay after it has been handled
+	AckDelayed = "delayed"
+	// AckNack negatively acknowledges FailRate of the messages so the broker requeues
+	// them and acknowledges the rest
+	AckNack = "nack"
+	// AckReject rejects FailRate of the messages without requeueing them, so they are dead
+	// lettered or dropped, and acknowledges the rest
+	AckReject = "reject"
+)
+
+// AckStrategy configures how the consumer of a scenario acknowledges messages. Prefetch
+// limits the unacknowledged messages the broker sends ahead, 0 means unlimited.
+type AckStrategy struct {
+	Mode      string        `json:"mode"`
+	Prefetch  int           `json:"prefetch,omitempty"`
+	BatchSize int           `json:"batchSize,omitempty"`
+	Delay     time.Duration `json:"delayNs,omitempty"`
+	FailRate  float64       `json:"failRate,omitempty"`
+}
+
+// Validate checks that the strategy is complete, an empty mode is AckManual
+func (a AckStrategy) Validate() error {
+	if a.Prefetch < 0 {
+		return fmt.Errorf("prefetch must not be negative, got %d", a.Prefetch)
+	}
+	switch a.Mode {
+	case "", AckAuto, AckManual:
+		return nil
+	case AckBatch:
+		if a.BatchSize <= 0 {
+			return fmt.Errorf("batch ack needs batchSize > 0, got %d", a.BatchSize)
+		}
+		if a.Prefetch > 0 && a.BatchSize > a.Prefetch {
+			return fmt.Errorf("batchSize %d exceeds prefetch %d, the consumer would stall", a.BatchSize, a.Prefetch)
+		}
+		return nil
+	case AckDelayed:
+		if a.Delay <= 0 {
+			return fmt.Errorf("delayed ack needs delayNs > 0, got %s", a.Delay)
+		}
+		return nil
+	case AckNack, AckReject:
+		if a.FailRate <= 0 || a.FailRate > 1 {
+			return fmt.Errorf("%s ack needs failRate in (0, 1], got %g", a.Mode, a.FailRate)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown ack mode %q, use auto, manual, batch, delayed, nack or reject", a.Mode)
+	}
+}
+
+// Fails reports whether the nth message handled, counting from 0, is nacked or rejected.
+// Failures are spread evenly, a rate of 0.25 fails every fourth message.
+func (a AckStrategy) Fails(n int) bool {
+	if a.Mode != AckNack && a.Mode != AckReject {
+		return false
+	}
+	return int(float64(n+1)*a.FailRate) > int(float64(n)*a.FailRate)
+}
+
+// AckStats reports how an ack strategy behaved during a run
+type AckStats struct {
+	Mode       string  `json:"mode"`
+	Prefetch   int     `json:"prefetch,omitempty"`
+	Consumed   int     `json:"consumed"`
+	Acked      int     `json:"acked"`
+	Nacked     int     `json:"nacked,omitempty"`
+	Rejected   int     `json:"rejected,omitempty"`
+	MaxUnacked int     `json:"maxUnacked"`
+	Throughput float64 `json:"throughputPerSec"`
+}
@@ -0,0 +1,32 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAckStrategyValidate(t *testing.T) {
+	assert.NoError(t, AckStrategy{}.Validate())
+	assert.NoError(t, AckStrategy{Mode: AckBatch, BatchSize: 10, Prefetch: 50}.Validate())
+	assert.Error(t, AckStrategy{Mode: AckBatch}.Validate())
+	assert.Error(t, AckStrategy{Mode: AckBatch, BatchSize: 100, Prefetch: 50}.Validate())
+	assert.Error(t, AckStrategy{Mode: AckDelayed}.Validate())
+	assert.Error(t, AckStrategy{Mode: AckNack}.Validate())
+	assert.Error(t, AckStrategy{Mode: AckReject, FailRate: 1.5}.Validate())
+	assert.NoError(t, AckStrategy{Mode: AckReject, FailRate: 1}.Validate())
+	assert.Error(t, AckStrategy{Mode: "requeue"}.Validate())
+}
+
+func TestAckStrategyFails(t *testing.T) {
+	nack := AckStrategy{Mode: AckNack, FailRate: 0.25}
+	var failed []int
+	for n := 0; n < 12; n++ {
+		if nack.Fails(n) {
+			failed = append(failed, n)
+		}
+	}
+	assert.Equal(t, []int{3, 7, 11}, failed)
+	assert.True(t, AckStrategy{Mode: AckReject, FailRate: 1}.Fails(0))
+	assert.False(t, AckStrategy{Mode: AckManual, FailRate: 1}.Fails(0), "only nack and reject fail messages")
+}
@@ -0,0 +1,65 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// AssertExpectRouted passes when a mandatory publish reaches at least one queue
+	AssertExpectRouted = "expect_routed"
+	// AssertExpectUnroutable passes when a mandatory publish is returned by the broker
+	AssertExpectUnroutable = "expect_unroutable"
+)
+
+// Assertion is a check evaluated as part of a scenario. Routing assertions use Exchange
+// and RoutingKey, redelivery assertions use Queue and Retries.
+type Assertion struct {
+	Type       string `json:"type"`
+	Exchange   string `json:"exchange,omitempty"`
+	RoutingKey string `json:"routingKey,omitempty"`
+	Queue      string `json:"queue,omitempty"`
+	Retries    int    `json:"retries,omitempty"`
+}
+
+// AssertionResult is the outcome of one assertion
+type AssertionResult struct {
+	Assertion Assertion `json:"assertion"`
+	Passed    bool      `json:"passed"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// RoutingProber publishes a mandatory probe message and reports whether it was routed
+type RoutingProber interface {
+	ProbeRouting(ctx context.Context, exchange, routingKey string) (bool, error)
+}
+
+// CheckRoutingAssertions evaluates every routing assertion of s. The scenario topic is
+// used as the exchange when an assertion does not name one.
+func CheckRoutingAssertions(ctx context.Context, prober RoutingProber, s Scenario) []AssertionResult {
+	var results []AssertionResult
+	for _, a := range s.Assertions {
+		if a.Type != AssertExpectRouted && a.Type != AssertExpectUnroutable {
+			continue
+		}
+		exchange := a.Exchange
+		if exchange == "" {
+			exchange = s.Topic
+		}
+
+		res := AssertionResult{Assertion: a}
+		routed, err := prober.ProbeRouting(ctx, exchange, a.RoutingKey)
+		switch {
+		case err != nil:
+			res.Message = fmt.Sprintf("probe failed: %v", err)
+		case a.Type == AssertExpectRouted && !routed:
+			res.Message = fmt.Sprintf("routing key %q on %q has no bound queue", a.RoutingKey, exchange)
+		case a.Type == AssertExpectUnroutable && routed:
+			res.Message = fmt.Sprintf("routing key %q on %q was routed to a queue", a.RoutingKey, exchange)
+		default:
+			res.Passed = true
+		}
+		results = append(results, res)
+	}
+	return results
+}
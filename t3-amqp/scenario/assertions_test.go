@@ -0,0 +1,35 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProber map[string]bool
+
+func (f fakeProber) ProbeRouting(ctx context.Context, exchange, routingKey string) (bool, error) {
+	return f[exchange+"/"+routingKey], nil
+}
+
+func TestCheckRoutingAssertions(t *testing.T) {
+	prober := fakeProber{"orders/order.created": true}
+	s := Scenario{
+		Topic: "orders",
+		Assertions: []Assertion{
+			{Type: AssertExpectRouted, RoutingKey: "order.created"},
+			{Type: AssertExpectUnroutable, RoutingKey: "order.legacy"},
+			{Type: AssertExpectRouted, RoutingKey: "order.deleted"},
+		},
+	}
+
+	results := CheckRoutingAssertions(context.Background(), prober, s)
+	assert.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.True(t, results[1].Passed)
+	assert.False(t, results[2].Passed)
+	assert.Contains(t, results[2].Message, "no bound queue")
+
+	assert.False(t, Result{Assertions: results}.Passed())
+}
@@ -0,0 +1,50 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxReorderWindow bounds how many messages are held back to be published out of order
+const MaxReorderWindow = 1000
+
+// ChaosOptions inject faults into how a scenario publishes and consumes, so systems
+// reading the topic can be validated against realistic failure modes. Rates are the
+// chance of a fault per message, a non-zero Seed makes the faults of a run repeatable.
+type ChaosOptions struct {
+	// DropRate drops the publisher's connection before a message, the publisher then
+	// reconnects and carries on like a resilient client would
+	DropRate float64 `json:"dropRate,omitempty"`
+	// DuplicateRate publishes a message a second time with the same message ID
+	DuplicateRate float64 `json:"duplicateRate,omitempty"`
+	// ReorderWindow holds this many messages back and publishes them in random order
+	ReorderWindow int `json:"reorderWindow,omitempty"`
+	// AckDelay holds every acknowledgement of the consumer back for a random time up to it
+	AckDelay time.Duration `json:"ackDelayNs,omitempty"`
+	Seed     int64         `json:"seed,omitempty"`
+}
+
+// Validate checks that the rates are probabilities and the window and delay are bounded
+func (o ChaosOptions) Validate() error {
+	if o.DropRate < 0 || o.DropRate > 1 {
+		return fmt.Errorf("dropRate must be between 0 and 1, got %g", o.DropRate)
+	}
+	if o.DuplicateRate < 0 || o.DuplicateRate > 1 {
+		return fmt.Errorf("duplicateRate must be between 0 and 1, got %g", o.DuplicateRate)
+	}
+	if o.ReorderWindow < 0 || o.ReorderWindow > MaxReorderWindow {
+		return fmt.Errorf("reorderWindow must be between 0 and %d, got %d", MaxReorderWindow, o.ReorderWindow)
+	}
+	if o.AckDelay < 0 {
+		return fmt.Errorf("ackDelayNs must not be negative, got %s", o.AckDelay)
+	}
+	return nil
+}
+
+// ChaosStats counts the faults injected into a run. Reordered counts the messages that
+// were not published in the position they were generated in.
+type ChaosStats struct {
+	Drops      int `json:"drops"`
+	Duplicates int `json:"duplicates"`
+	Reordered  int `json:"reordered"`
+}
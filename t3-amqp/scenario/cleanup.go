@@ -0,0 +1,221 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cleanupTimeout bounds deleting an instance's resources, it applies even when the run
+// itself was cancelled
+const cleanupTimeout = 30 * time.Second
+
+// ResourceKind is the kind of a temporary broker object
+type ResourceKind string
+
+const (
+	KindQueue    ResourceKind = "queue"
+	KindExchange ResourceKind = "exchange"
+)
+
+// Resource is a temporary broker object, queues and exchanges have separate namespaces
+type Resource struct {
+	Kind ResourceKind
+	Name string
+}
+
+func (r Resource) String() string {
+	return string(r.Kind) + " " + r.Name
+}
+
+// journalKey is how r is recorded in the journal
+func (r Resource) journalKey() string {
+	return string(r.Kind) + ":" + r.Name
+}
+
+// parseJournalKey returns the resource recorded under key, journals written before
+// exchanges were tracked hold bare queue names
+func parseJournalKey(key string) Resource {
+	for _, kind := range []ResourceKind{KindQueue, KindExchange} {
+		if name, ok := strings.CutPrefix(key, string(kind)+":"); ok {
+			return Resource{Kind: kind, Name: name}
+		}
+	}
+	return Resource{Kind: KindQueue, Name: key}
+}
+
+// Resources creates and removes the temporary broker objects used by an instance
+type Resources interface {
+	DeclareTemp(ctx context.Context, r Resource) error
+	DeleteTemp(ctx context.Context, r Resource) error
+}
+
+// Journal remembers temporary resources that exist on the broker so leftovers from a
+// crashed run can be swept on the next start. It is persisted as a JSON file, without a
+// path it is kept in memory only.
+type Journal struct {
+	path string
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+// OpenJournal loads the journal at path, a missing file is an empty journal. The file and
+// its directory are created on the first write.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, pending: map[string]time.Time{}}
+	if path == "" {
+		return j, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cleanup journal: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &j.pending); err != nil {
+			return nil, fmt.Errorf("error decoding cleanup journal: %w", err)
+		}
+	}
+	return j, nil
+}
+
+// Add records r before it is declared on the broker
+func (j *Journal) Add(r Resource) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.pending[r.journalKey()] = time.Now().UTC()
+	return j.saveLocked()
+}
+
+// Remove forgets r once it has been deleted from the broker
+func (j *Journal) Remove(r Resource) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.pending, r.journalKey())
+	delete(j.pending, r.Name) // Recorded by a journal from before exchanges were tracked
+	return j.saveLocked()
+}
+
+// Pending returns the resources that have not been cleaned up, queues before exchanges
+// so a sweep deletes them in the reverse of the order they were declared in
+func (j *Journal) Pending() []Resource {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	resources := make([]Resource, 0, len(j.pending))
+	for key := range j.pending {
+		resources = append(resources, parseJournalKey(key))
+	}
+	sort.Slice(resources, func(a, b int) bool {
+		if resources[a].Kind != resources[b].Kind {
+			return resources[a].Kind == KindQueue
+		}
+		return resources[a].Name < resources[b].Name
+	})
+	return resources
+}
+
+func (j *Journal) saveLocked() error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(j.pending)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return fmt.Errorf("error creating cleanup journal directory: %w", err)
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing cleanup journal: %w", err)
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// Sweep deletes every resource left in the journal, typically by a run that crashed.
+// It returns the resources it removed.
+func Sweep(ctx context.Context, r Resources, j *Journal) ([]Resource, error) {
+	var removed []Resource
+	var errs []error
+	for _, res := range j.Pending() {
+		if err := r.DeleteTemp(ctx, res); err != nil {
+			errs = append(errs, fmt.Errorf("error deleting %s: %w", res, err))
+			continue
+		}
+		if err := j.Remove(res); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, res)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// withTempResources declares the instance's temporary exchange and queue, runs fn and
+// always deletes them afterwards, whether fn succeeded, failed, panicked or the run was
+// cancelled
+func withTempResources(ctx context.Context, r Resources, j *Journal, inst Instance, fn func() Result) (result Result) {
+	var resources []Resource
+	if inst.TempExchange != "" {
+		resources = append(resources, Resource{Kind: KindExchange, Name: inst.TempExchange})
+	}
+	resources = append(resources, Resource{Kind: KindQueue, Name: inst.TempQueue})
+	if err := WithTemp(ctx, r, j, resources, func() { result = fn() }); err != nil {
+		return Result{Error: err.Error()}
+	}
+	return result
+}
+
+// WithTempQueue declares the temporary queue name, tracked in j until it is deleted, runs
+// fn and always deletes the queue afterwards. It returns the error of declaring the queue,
+// fn is not run then.
+func WithTempQueue(ctx context.Context, r Resources, j *Journal, name string, fn func()) error {
+	return WithTemp(ctx, r, j, []Resource{{Kind: KindQueue, Name: name}}, fn)
+}
+
+// WithTemp declares resources in order, each tracked in j until it is deleted, runs fn and
+// always deletes the declared ones afterwards in reverse order. It returns the error of
+// declaring a resource, fn is not run then.
+func WithTemp(ctx context.Context, r Resources, j *Journal, resources []Resource, fn func()) error {
+	var declared []Resource
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		for i := len(declared) - 1; i >= 0; i-- {
+			res := declared[i]
+			if err := r.DeleteTemp(cleanupCtx, res); err != nil {
+				// Leave it in the journal for the next startup sweep
+				log.Printf("failed to delete temporary %s: %v", res, err)
+				continue
+			}
+			if err := j.Remove(res); err != nil {
+				log.Printf("failed to update cleanup journal: %v", err)
+			}
+		}
+	}()
+
+	for _, res := range resources {
+		if err := j.Add(res); err != nil {
+			return err
+		}
+		// Tracked before declaring, a declare that fails halfway may still leave it behind
+		declared = append(declared, res)
+		if err := r.DeclareTemp(ctx, res); err != nil {
+			return fmt.Errorf("error declaring %s: %v", res, err)
+		}
+	}
+	fn()
+	return nil
+}
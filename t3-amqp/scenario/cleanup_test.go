@@ -0,0 +1,97 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResources struct {
+	mu       sync.Mutex
+	declared map[Resource]bool
+	failDel  bool
+}
+
+func (f *fakeResources) DeclareTemp(ctx context.Context, r Resource) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.declared[r] = true
+	return nil
+}
+
+func (f *fakeResources) DeleteTemp(ctx context.Context, r Resource) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failDel {
+		return errors.New("broker unreachable")
+	}
+	delete(f.declared, r)
+	return nil
+}
+
+func TestEngineCleansUpOnFailure(t *testing.T) {
+	res := &fakeResources{declared: map[Resource]bool{}}
+	journal, err := OpenJournal(filepath.Join(t.TempDir(), "journal.json"))
+	assert.NoError(t, err)
+
+	executor := ExecutorFunc(func(ctx context.Context, inst Instance) (Result, error) {
+		res.mu.Lock()
+		defer res.mu.Unlock()
+		assert.True(t, res.declared[Resource{KindQueue, inst.TempQueue}], "queue should exist while the instance runs")
+		assert.True(t, res.declared[Resource{KindExchange, inst.TempExchange}])
+		return Result{}, errors.New("consumer crashed")
+	})
+
+	instances := Expand("run42", []Scenario{{ID: 1}, {ID: 2}}, nil)
+	report := NewEngine(executor, 2).WithCleanup(res, journal).Run(context.Background(), "run42", instances)
+
+	assert.Equal(t, 2, report.Failed)
+	assert.Empty(t, res.declared, "temporary queues and exchanges should be deleted after failures")
+	assert.Empty(t, journal.Pending())
+}
+
+func TestSweepRemovesLeftovers(t *testing.T) {
+	// The directory of the journal is created on the first write
+	path := filepath.Join(t.TempDir(), "t3", "journal.json")
+	res := &fakeResources{declared: map[Resource]bool{}, failDel: true}
+	journal, err := OpenJournal(path)
+	assert.NoError(t, err)
+
+	// Simulate a run whose cleanup could not reach the broker
+	executor := ExecutorFunc(func(ctx context.Context, inst Instance) (Result, error) { return Result{}, nil })
+	NewEngine(executor, 1).WithCleanup(res, journal).Run(context.Background(), "run7", Expand("run7", []Scenario{{ID: 1}}, nil))
+	leftovers := []Resource{{KindQueue, "t3.run7.0"}, {KindExchange, "t3.run7.0.x"}}
+	assert.Equal(t, leftovers, journal.Pending())
+
+	// The next process start sweeps it
+	res.failDel = false
+	reopened, err := OpenJournal(path)
+	assert.NoError(t, err)
+	removed, err := Sweep(context.Background(), res, reopened)
+	assert.NoError(t, err)
+	assert.Equal(t, leftovers, removed)
+	assert.Empty(t, reopened.Pending())
+	assert.Empty(t, res.declared)
+}
+
+func TestOpenJournalReadsBareQueueNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"t3.run3.0":"2026-01-02T03:04:05Z"}`), 0o644))
+	journal, err := OpenJournal(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Resource{{KindQueue, "t3.run3.0"}}, journal.Pending())
+	assert.NoError(t, journal.Remove(Resource{KindQueue, "t3.run3.0"}))
+	assert.Empty(t, journal.Pending())
+
+	journal, err = OpenJournal("")
+	if assert.NoError(t, err) {
+		assert.NoError(t, journal.Add(Resource{KindExchange, "t3.run3.0.x"}), "without a path nothing is written")
+	}
+}
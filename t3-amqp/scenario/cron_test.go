@@ -0,0 +1,56 @@
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * * *", "*/15 * * * *", "0 2 * * 1-5", "5/15 0,12 1 */3 7", "@daily", " @hourly "} {
+		_, err := ParseCron(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	for name, tc := range map[string]struct{ expr, msg string }{
+		"fields": {"* * * *", "needs 5 fields"},
+		"range":  {"60 * * * *", "minute \"60\" is outside 0-59"},
+		"bounds": {"* 5-2 * * *", "invalid range in hour"},
+		"step":   {"*/0 * * * *", "invalid step in minute"},
+		"value":  {"* * * jan *", "invalid month"},
+		"macro":  {"@reboot", "needs 5 fields"},
+	} {
+		_, err := ParseCron(tc.expr)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), tc.msg, name)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Thursday
+	from := time.Date(2026, 10, 1, 10, 7, 30, 0, time.UTC)
+	for expr, want := range map[string]time.Time{
+		"* * * * *":    time.Date(2026, 10, 1, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *": time.Date(2026, 10, 1, 10, 15, 0, 0, time.UTC),
+		"0 2 * * *":    time.Date(2026, 10, 2, 2, 0, 0, 0, time.UTC),
+		"@monthly":     time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		"@yearly":      time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		"30 9 * * 7":   time.Date(2026, 10, 4, 9, 30, 0, 0, time.UTC),
+		// the 13th or a Friday
+		"0 0 13 * 5": time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *": time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 31 2 *": {},
+	} {
+		cron, err := ParseCron(expr)
+		if assert.NoError(t, err, expr) {
+			assert.Equal(t, want, cron.Next(from), expr)
+		}
+	}
+
+	cron, err := ParseCron("0 * * * *")
+	assert.NoError(t, err)
+	local := time.Date(2026, 10, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, time.Date(2026, 10, 1, 11, 0, 0, 0, time.UTC), cron.Next(local), "times are in UTC")
+}
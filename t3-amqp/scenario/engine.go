@@ -0,0 +1,146 @@
+package scenario
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultWorkers is used when an engine is created without a worker count
+const DefaultWorkers = 4
+
+// Report aggregates the results of every instance in a run
+type Report struct {
+	RunID        string        `json:"runId"`
+	Started      time.Time     `json:"started"`
+	Duration     time.Duration `json:"durationNs"`
+	Instances    int           `json:"instances"`
+	Passed       int           `json:"passed"`
+	Failed       int           `json:"failed"`
+	MessagesSent int           `json:"messagesSent"`
+	Failures     int           `json:"failures"`
+	// MissedHeartbeats is reported separately so flaky networks aren't mistaken for
+	// broken consumers
+	MissedHeartbeats int `json:"missedHeartbeats"`
+	// Latency takes each percentile from the slowest instance
+	Latency *Latency `json:"latency,omitempty"`
+	// ConfirmLatency and Throughput are only measured by load tests, which publish from
+	// several publishers and report over all of their messages. Throughput is the
+	// number of confirmed messages per second while publishing.
+	ConfirmLatency *Latency `json:"confirmLatency,omitempty"`
+	Throughput     float64  `json:"throughput,omitempty"`
+	// Throttled is filled in by the caller from a broker flow monitor watching the run
+	Throttled []ThrottleInterval `json:"throttled,omitempty"`
+	Results   []Result           `json:"results"`
+}
+
+// Engine executes scenario instances concurrently with a bounded worker pool
+type Engine struct {
+	executor  Executor
+	workers   int
+	resources Resources
+	journal   *Journal
+}
+
+// NewEngine creates an engine running at most workers instances at a time
+func NewEngine(executor Executor, workers int) *Engine {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	return &Engine{executor: executor, workers: workers}
+}
+
+// WithCleanup makes the engine declare each instance's temporary queue before it runs
+// and delete it afterwards. Created resources are tracked in journal until deleted.
+func (e *Engine) WithCleanup(resources Resources, journal *Journal) *Engine {
+	if journal == nil {
+		journal = &Journal{pending: map[string]time.Time{}}
+	}
+	e.resources = resources
+	e.journal = journal
+	return e
+}
+
+// Run executes all instances and returns the aggregated report. Results keep the order
+// of instances regardless of completion order. Cancelling ctx stops instances that have
+// not started yet; they are reported with the context error.
+func (e *Engine) Run(ctx context.Context, runID string, instances []Instance) Report {
+	report := Report{RunID: runID, Started: time.Now().UTC(), Instances: len(instances)}
+	results := make([]Result, len(instances))
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(e.workers, len(instances)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = e.execute(ctx, instances[i])
+			}
+		}()
+	}
+
+	for i := range instances {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	report.Duration = time.Since(report.Started)
+	report.Results = results
+	latencies := make([]*Latency, 0, len(results))
+	for _, r := range results {
+		if r.Passed() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.MessagesSent += r.MessagesSent
+		report.Failures += r.Failures
+		report.MissedHeartbeats += r.MissedHeartbeats
+		latencies = append(latencies, r.Latency)
+	}
+	report.Latency = worstLatency(latencies)
+	return report
+}
+
+func (e *Engine) execute(ctx context.Context, inst Instance) Result {
+	started := time.Now().UTC()
+	if err := ctx.Err(); err != nil {
+		return Result{Instance: inst, Started: started, Error: err.Error()}
+	}
+
+	run := func() Result {
+		result, err := e.executor.Execute(ctx, inst)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	var result Result
+	if e.resources != nil {
+		result = withTempResources(ctx, e.resources, e.journal, inst, run)
+	} else {
+		result = run()
+	}
+	result.Instance = inst
+	result.Started = started
+	result.Duration = time.Since(started)
+	return result
+}
+
+// AnnotateThrottling attaches broker throttling intervals to the report and to every
+// result whose execution overlapped them
+func (r *Report) AnnotateThrottling(intervals []ThrottleInterval) {
+	r.Throttled = intervals
+	for i := range r.Results {
+		res := &r.Results[i]
+		end := res.Started.Add(res.Duration)
+		for _, iv := range intervals {
+			if iv.Start.Before(end) && iv.End.After(res.Started) {
+				res.Throttled = append(res.Throttled, iv)
+			}
+		}
+	}
+}
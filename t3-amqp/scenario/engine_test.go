@@ -0,0 +1,130 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"t3-amqp/payload"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandParameterizedInstances(t *testing.T) {
+	scenarios := []Scenario{
+		{ID: 1, Name: "orders", Params: map[string]string{"region": "eu", "size": "small"}},
+		{ID: 2, Name: "payments"},
+	}
+	sets := map[int][]map[string]string{1: {{"size": "small"}, {"size": "large"}}}
+
+	instances := Expand("run1", scenarios, sets)
+	assert.Len(t, instances, 3)
+	assert.Equal(t, "large", instances[1].Params["size"])
+	assert.Equal(t, "eu", instances[1].Params["region"])
+	assert.Equal(t, "payments", instances[2].Scenario.Name)
+
+	queues := map[string]bool{}
+	for _, inst := range instances {
+		queues[inst.TempQueue] = true
+	}
+	assert.Len(t, queues, 3, "every instance should get its own queue")
+}
+
+func TestScenarioValidate(t *testing.T) {
+	valid := Scenario{
+		Name: "orders", Topic: "orders.created", MessageCount: 10,
+		Schema: SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"},
+	}
+	assert.NoError(t, valid.Validate())
+
+	broken := map[string]func(s *Scenario){
+		"no topic":       func(s *Scenario) { s.Topic = "" },
+		"no version":     func(s *Scenario) { s.Schema.Version = "" },
+		"no messages":    func(s *Scenario) { s.MessageCount = 0 },
+		"negative rate":  func(s *Scenario) { s.Rate = -1 },
+		"unknown mode":   func(s *Scenario) { s.Mode = "fuzzy" },
+		"pooled invalid": func(s *Scenario) { s.Mode, s.PayloadPool = ModeInvalid, 10 },
+		"incomplete ack": func(s *Scenario) { s.Ack = &AckStrategy{Mode: AckBatch} },
+		"unknown size":   func(s *Scenario) { s.PayloadSize = &payload.SizeSpec{Kind: "huge"} },
+		"negative pool":  func(s *Scenario) { s.PayloadPool = -1 },
+		"probe of valid": func(s *Scenario) { s.Probe = &ProbeOptions{ReplyTopic: "orders.mirror"} },
+		"drop chance":    func(s *Scenario) { s.Chaos = &ChaosOptions{DropRate: 2} },
+		"wide window":    func(s *Scenario) { s.Chaos = &ChaosOptions{ReorderWindow: MaxReorderWindow + 1} },
+		"bad schedule":   func(s *Scenario) { s.Schedule = "0 25 * * *" },
+		"probe loop": func(s *Scenario) {
+			s.Mode, s.Probe = ModeLatency, &ProbeOptions{ReplyTopic: s.Topic}
+		},
+	}
+	for name, breakIt := range broken {
+		s := valid
+		breakIt(&s)
+		assert.Error(t, s.Validate(), name)
+	}
+}
+
+func TestEngineRunsConcurrentlyAndAggregates(t *testing.T) {
+	var running, peak int32
+	executor := ExecutorFunc(func(ctx context.Context, inst Instance) (Result, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if inst.Index == 3 {
+			return Result{MessagesSent: 5}, errors.New("broker unavailable")
+		}
+		latency := &Latency{Samples: 10, P50: time.Duration(inst.Index), P99: time.Duration(10 - inst.Index)}
+		return Result{MessagesSent: 10, MessagesValid: 10, Latency: latency}, nil
+	})
+
+	instances := Expand("run1", []Scenario{{ID: 1}}, map[int][]map[string]string{1: make([]map[string]string, 6)})
+	report := NewEngine(executor, 3).Run(context.Background(), "run1", instances)
+
+	assert.Equal(t, 6, report.Instances)
+	assert.Equal(t, 5, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 55, report.MessagesSent)
+	assert.Equal(t, "broker unavailable", report.Results[3].Error)
+	if assert.NotNil(t, report.Latency) {
+		assert.Equal(t, 50, report.Latency.Samples)
+		assert.Equal(t, time.Duration(5), report.Latency.P50)
+		assert.Equal(t, time.Duration(10), report.Latency.P99)
+	}
+	assert.LessOrEqual(t, peak, int32(3))
+	assert.Greater(t, peak, int32(1))
+}
+
+func TestEngineCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	executor := ExecutorFunc(func(ctx context.Context, inst Instance) (Result, error) {
+		t.Fatal("executor should not be called after cancellation")
+		return Result{}, nil
+	})
+	report := NewEngine(executor, 2).Run(ctx, "run1", Expand("run1", []Scenario{{ID: 1}, {ID: 2}}, nil))
+	assert.Equal(t, 2, report.Failed)
+}
+
+func TestAnnotateThrottling(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := Report{
+		Results: []Result{
+			{Started: start, Duration: 10 * time.Second},
+			{Started: start.Add(time.Minute), Duration: 10 * time.Second},
+		},
+	}
+	report.AnnotateThrottling(
+		[]ThrottleInterval{{Start: start.Add(5 * time.Second), End: start.Add(20 * time.Second), Reason: "connection flow"}},
+	)
+
+	assert.Len(t, report.Throttled, 1)
+	assert.Len(t, report.Results[0].Throttled, 1)
+	assert.Empty(t, report.Results[1].Throttled)
+}
@@ -0,0 +1,47 @@
+package scenario
+
+import (
+	"fmt"
+)
+
+// MaxGroupConsumers bounds the consumers of a consumer group
+const MaxGroupConsumers = 64
+
+// ConsumerGroup runs Consumers competing consumers on the instance queue instead of one,
+// to see how messages spread across them and get redelivered under contention.
+// Strategies are assigned to the consumers in turn, without any every consumer uses the
+// ack strategy of the scenario.
+type ConsumerGroup struct {
+	Consumers  int           `json:"consumers"`
+	Strategies []AckStrategy `json:"strategies,omitempty"`
+}
+
+// Validate checks the size of the group and its ack strategies
+func (g ConsumerGroup) Validate() error {
+	if g.Consumers <= 0 || g.Consumers > MaxGroupConsumers {
+		return fmt.Errorf("consumers must be between 1 and %d, got %d", MaxGroupConsumers, g.Consumers)
+	}
+	for i, strategy := range g.Strategies {
+		if err := strategy.Validate(); err != nil {
+			return fmt.Errorf("strategy %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Strategy returns the ack strategy of consumer i, fallback when the group assigns none
+func (g ConsumerGroup) Strategy(i int, fallback AckStrategy) AckStrategy {
+	if len(g.Strategies) == 0 {
+		return fallback
+	}
+	return g.Strategies[i%len(g.Strategies)]
+}
+
+// ConsumerStats reports how one consumer of a group behaved. Consumed shows how the
+// messages were distributed, Redelivered how many of them were deliveries of messages
+// that had been delivered before.
+type ConsumerStats struct {
+	Consumer int `json:"consumer"`
+	AckStats
+	Redelivered int `json:"redelivered"`
+}
@@ -0,0 +1,24 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerGroup(t *testing.T) {
+	assert.Error(t, ConsumerGroup{}.Validate())
+	assert.Error(t, ConsumerGroup{Consumers: MaxGroupConsumers + 1}.Validate())
+	assert.Error(t, ConsumerGroup{Consumers: 2, Strategies: []AckStrategy{{Mode: AckNack}}}.Validate())
+
+	group := ConsumerGroup{
+		Consumers:  3,
+		Strategies: []AckStrategy{{Mode: AckManual}, {Mode: AckReject, FailRate: 0.5}},
+	}
+	assert.NoError(t, group.Validate())
+	fallback := AckStrategy{Mode: AckBatch, BatchSize: 10}
+	assert.Equal(t, AckManual, group.Strategy(0, fallback).Mode)
+	assert.Equal(t, AckReject, group.Strategy(1, fallback).Mode)
+	assert.Equal(t, AckManual, group.Strategy(2, fallback).Mode, "strategies are assigned in turn")
+	assert.Equal(t, fallback, ConsumerGroup{Consumers: 3}.Strategy(2, fallback))
+}
@@ -0,0 +1,60 @@
+package scenario
+
+import (
+	"slices"
+	"time"
+)
+
+// Latency summarizes how long messages took, end to end from publishing a message until
+// it was consumed or from publishing until the broker confirmed it
+type Latency struct {
+	Samples int           `json:"samples"`
+	P50     time.Duration `json:"p50Ns"`
+	P95     time.Duration `json:"p95Ns"`
+	P99     time.Duration `json:"p99Ns"`
+	Max     time.Duration `json:"maxNs"`
+}
+
+// NewLatency computes the nearest rank percentiles of samples, nil without samples
+func NewLatency(samples []time.Duration) *Latency {
+	if len(samples) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	return &Latency{
+		Samples: len(sorted),
+		P50:     percentile(sorted, 50),
+		P95:     percentile(sorted, 95),
+		P99:     percentile(sorted, 99),
+		Max:     sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the smallest sample that at least p percent of sorted do not exceed
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// worstLatency combines the latencies of several instances into one that takes each
+// percentile from the slowest instance, the samples are summed
+func worstLatency(latencies []*Latency) *Latency {
+	var worst *Latency
+	for _, l := range latencies {
+		if l == nil {
+			continue
+		}
+		if worst == nil {
+			combined := *l
+			worst = &combined
+			continue
+		}
+		worst.Samples += l.Samples
+		worst.P50 = max(worst.P50, l.P50)
+		worst.P95 = max(worst.P95, l.P95)
+		worst.P99 = max(worst.P99, l.P99)
+		worst.Max = max(worst.Max, l.Max)
+	}
+	return worst
+}
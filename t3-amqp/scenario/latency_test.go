@@ -0,0 +1,38 @@
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLatency(t *testing.T) {
+	assert.Nil(t, NewLatency(nil))
+
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	l := NewLatency(samples)
+	assert.Equal(t, 100, l.Samples)
+	assert.Equal(t, 50*time.Millisecond, l.P50)
+	assert.Equal(t, 95*time.Millisecond, l.P95)
+	assert.Equal(t, 99*time.Millisecond, l.P99)
+	assert.Equal(t, 100*time.Millisecond, l.Max)
+	assert.Equal(t, 100*time.Millisecond, samples[0], "samples are not reordered")
+
+	one := NewLatency([]time.Duration{time.Second})
+	assert.Equal(t, time.Second, one.P50)
+	assert.Equal(t, time.Second, one.P99)
+}
+
+func TestWorstLatency(t *testing.T) {
+	assert.Nil(t, worstLatency([]*Latency{nil}))
+
+	fast := &Latency{Samples: 10, P50: 1, P95: 2, P99: 9, Max: 10}
+	slow := &Latency{Samples: 5, P50: 5, P95: 6, P99: 7, Max: 8}
+	worst := worstLatency([]*Latency{fast, nil, slow})
+	assert.Equal(t, &Latency{Samples: 15, P50: 5, P95: 6, P99: 9, Max: 10}, worst)
+	assert.Equal(t, 10, fast.Samples, "inputs are left alone")
+}
@@ -0,0 +1,64 @@
+package scenario
+
+import (
+	"fmt"
+	"t3-amqp/payload"
+	"time"
+)
+
+// DefaultLoadPayloadPool is the number of payloads pre-generated for a load test that
+// does not ask for a pool, so generation stays off the publishing hot path
+const DefaultLoadPayloadPool = 100
+
+// LoadTest publishes valid messages to a topic for Duration to measure the throughput and
+// latency the broker sustains. Rate is the total across all Concurrency publishers, 0
+// publishes as fast as the broker confirms. Each publisher waits for the confirm of a
+// message before sending the next, so concurrency bounds the messages in flight.
+type LoadTest struct {
+	Topic       string            `json:"topic"`
+	Schema      SchemaRef         `json:"schema"`
+	Rate        int               `json:"rate"`
+	Concurrency int               `json:"concurrency"`
+	Duration    time.Duration     `json:"durationNs"`
+	PayloadPool int               `json:"payloadPool,omitempty"`
+	PayloadSize *payload.SizeSpec `json:"payloadSize,omitempty"`
+}
+
+// Validate checks that the load test is complete, a zero concurrency is one publisher
+func (t LoadTest) Validate() error {
+	if t.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if t.Schema.Name == "" || t.Schema.Type == "" || t.Schema.Version == "" {
+		return fmt.Errorf("schema needs name, type and version")
+	}
+	if t.Rate < 0 {
+		return fmt.Errorf("rate must not be negative, got %d", t.Rate)
+	}
+	if t.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative, got %d", t.Concurrency)
+	}
+	if t.Duration <= 0 {
+		return fmt.Errorf("durationNs must be positive, got %s", t.Duration)
+	}
+	if t.PayloadPool < 0 {
+		return fmt.Errorf("payloadPool must not be negative, got %d", t.PayloadPool)
+	}
+	if t.PayloadSize != nil {
+		if _, err := t.PayloadSize.Distribution(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scenario returns the scenario whose messages the load test publishes
+func (t LoadTest) Scenario() Scenario {
+	pool := t.PayloadPool
+	if pool == 0 {
+		pool = DefaultLoadPayloadPool
+	}
+	return Scenario{
+		Topic: t.Topic, Schema: t.Schema, Rate: t.Rate, Mode: ModeValid, PayloadPool: pool, PayloadSize: t.PayloadSize,
+	}
+}
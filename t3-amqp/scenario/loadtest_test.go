@@ -0,0 +1,38 @@
+package scenario
+
+import (
+	"t3-amqp/payload"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTestValidate(t *testing.T) {
+	valid := LoadTest{
+		Topic: "orders.created", Schema: SchemaRef{Name: "orders", Type: "json", Version: "1.0.0"},
+		Rate: 500, Concurrency: 4, Duration: time.Minute,
+	}
+	assert.NoError(t, valid.Validate())
+
+	broken := map[string]func(t *LoadTest){
+		"no topic":             func(t *LoadTest) { t.Topic = "" },
+		"no version":           func(t *LoadTest) { t.Schema.Version = "" },
+		"negative rate":        func(t *LoadTest) { t.Rate = -1 },
+		"negative concurrency": func(t *LoadTest) { t.Concurrency = -1 },
+		"no duration":          func(t *LoadTest) { t.Duration = 0 },
+		"negative pool":        func(t *LoadTest) { t.PayloadPool = -1 },
+		"unknown size":         func(t *LoadTest) { t.PayloadSize = &payload.SizeSpec{Kind: "huge"} },
+	}
+	for name, breakIt := range broken {
+		test := valid
+		breakIt(&test)
+		assert.Error(t, test.Validate(), name)
+	}
+
+	s := valid.Scenario()
+	assert.Equal(t, ModeValid, s.Mode)
+	assert.Equal(t, DefaultLoadPayloadPool, s.PayloadPool, "load tests publish from a pool by default")
+	valid.PayloadPool = 5
+	assert.Equal(t, 5, valid.Scenario().PayloadPool)
+}
@@ -0,0 +1,67 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MQTTOptions tune how a scenario runs on an MQTT broker, other brokers ignore them.
+// Subscription is the topic filter messages are read back from, it defaults to the
+// scenario topic and may use the + and # wildcards.
+type MQTTOptions struct {
+	QoS          *int   `json:"qos,omitempty"`
+	Retain       bool   `json:"retain,omitempty"`
+	Subscription string `json:"subscription,omitempty"`
+}
+
+// Validate checks the options against the topic messages are published to
+func (o MQTTOptions) Validate(topic string) error {
+	if o.QoS != nil && (*o.QoS < 0 || *o.QoS > 2) {
+		return fmt.Errorf("qos must be 0, 1 or 2, got %d", *o.QoS)
+	}
+	if strings.ContainsAny(topic, "+#") {
+		return fmt.Errorf("topic %q must not contain wildcards, use subscription for them", topic)
+	}
+	if o.Subscription == "" {
+		return nil
+	}
+	if err := ValidFilter(o.Subscription); err != nil {
+		return err
+	}
+	if !MatchFilter(o.Subscription, topic) {
+		return fmt.Errorf("subscription %q does not match topic %q", o.Subscription, topic)
+	}
+	return nil
+}
+
+// ValidFilter checks that filter is a well formed MQTT topic filter, + must fill a whole
+// level and # must be the last one
+func ValidFilter(filter string) error {
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		switch {
+		case level == "#" && i == len(levels)-1, level == "+":
+		case strings.ContainsAny(level, "+#"):
+			return fmt.Errorf("invalid topic filter %q", filter)
+		}
+	}
+	return nil
+}
+
+// MatchFilter reports whether topic matches the MQTT topic filter. Wildcards at the
+// first level do not match topics starting with $, which are reserved for the broker.
+func MatchFilter(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	filters, levels := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, f := range filters {
+		if f == "#" {
+			return true
+		}
+		if i >= len(levels) || (f != "+" && f != levels[i]) {
+			return false
+		}
+	}
+	return len(filters) == len(levels)
+}
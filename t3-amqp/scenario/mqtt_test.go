@@ -0,0 +1,28 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchFilter(t *testing.T) {
+	assert.True(t, MatchFilter("devices/+/telemetry", "devices/d1/telemetry"))
+	assert.True(t, MatchFilter("devices/#", "devices/d1/telemetry"))
+	assert.True(t, MatchFilter("devices/#", "devices"))
+	assert.True(t, MatchFilter("#", "devices/d1"))
+	assert.False(t, MatchFilter("devices/+", "devices/d1/telemetry"))
+	assert.False(t, MatchFilter("devices/+/telemetry", "devices/d1/status"))
+	assert.False(t, MatchFilter("devices/d1/telemetry/raw", "devices/d1/telemetry"))
+	assert.False(t, MatchFilter("#", "$SYS/uptime"))
+}
+
+func TestMQTTOptionsValidate(t *testing.T) {
+	qos := 3
+	assert.NoError(t, MQTTOptions{}.Validate("devices/d1/telemetry"))
+	assert.NoError(t, MQTTOptions{Subscription: "devices/+/telemetry", Retain: true}.Validate("devices/d1/telemetry"))
+	assert.Error(t, MQTTOptions{QoS: &qos}.Validate("devices/d1/telemetry"))
+	assert.Error(t, MQTTOptions{}.Validate("devices/+/telemetry"), "messages cannot be published to a filter")
+	assert.Error(t, MQTTOptions{Subscription: "devices/#/telemetry"}.Validate("devices/d1/telemetry"))
+	assert.Error(t, MQTTOptions{Subscription: "devices/d2/#"}.Validate("devices/d1/telemetry"))
+}
@@ -0,0 +1,102 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCanaryInterval is how often a canary probes the broker when no interval is set
+const DefaultCanaryInterval = time.Minute
+
+// ProbeOptions tune a latency scenario. Probes are published to the scenario topic and
+// consumed from ReplyTopic, where a mirror or responder republishes them with their
+// headers intact, so the measured latency covers the whole round trip. Without a reply
+// topic probes are consumed from the topic itself.
+type ProbeOptions struct {
+	ReplyTopic string `json:"replyTopic,omitempty"`
+}
+
+// Validate checks the options against the topic probes are published to
+func (o ProbeOptions) Validate(topic string) error {
+	if o.ReplyTopic != "" && o.ReplyTopic == topic {
+		return fmt.Errorf("replyTopic must differ from topic, leave it empty to consume the topic itself")
+	}
+	return nil
+}
+
+// ReplyTopic returns the topic the messages of s are consumed from, the reply topic of
+// its probe or the scenario topic
+func (s Scenario) ReplyTopic() string {
+	if s.Probe != nil && s.Probe.ReplyTopic != "" {
+		return s.Probe.ReplyTopic
+	}
+	return s.Topic
+}
+
+// Canary runs a latency scenario every interval, so the probe latency metrics recorded
+// by the executor follow the health of the broker continuously
+type Canary struct {
+	engine   *Engine
+	scenario Scenario
+	interval time.Duration
+
+	mu   sync.RWMutex
+	last *Report
+}
+
+// NewCanary creates a canary running s on engine every interval
+func NewCanary(engine *Engine, s Scenario, interval time.Duration) *Canary {
+	if interval <= 0 {
+		interval = DefaultCanaryInterval
+	}
+	return &Canary{engine: engine, scenario: s, interval: interval}
+}
+
+// Run probes now and then every interval until ctx is done. A probe still running when
+// the next one is due delays it rather than overlapping it.
+func (c *Canary) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.Probe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Probe(ctx)
+		}
+	}
+}
+
+// Probe runs the scenario once and returns its report
+func (c *Canary) Probe(ctx context.Context) Report {
+	runID := fmt.Sprintf("canary-%d", time.Now().UnixNano())
+	report := c.engine.Run(ctx, runID, Expand(runID, []Scenario{c.scenario}, nil))
+	for _, r := range report.Results {
+		switch {
+		case r.Error != "" && ctx.Err() == nil:
+			log.Printf("Canary %s failed: %s", c.scenario.Name, r.Error)
+		case r.Failures > 0:
+			log.Printf("Canary %s lost %d of %d probes", c.scenario.Name, r.Failures, r.MessagesSent)
+		}
+	}
+
+	c.mu.Lock()
+	c.last = &report
+	c.mu.Unlock()
+	return report
+}
+
+// Last returns the report of the latest probe, false before the first one finished
+func (c *Canary) Last() (Report, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.last == nil {
+		return Report{}, false
+	}
+	return *c.last, true
+}
@@ -0,0 +1,48 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplyTopic(t *testing.T) {
+	s := Scenario{Topic: "probes", Mode: ModeLatency}
+	assert.Equal(t, "probes", s.ReplyTopic())
+	s.Probe = &ProbeOptions{}
+	assert.Equal(t, "probes", s.ReplyTopic())
+	s.Probe.ReplyTopic = "probes.mirror"
+	assert.Equal(t, "probes.mirror", s.ReplyTopic())
+}
+
+func TestCanaryProbes(t *testing.T) {
+	runs := make(chan Instance, 10)
+	executor := ExecutorFunc(func(ctx context.Context, inst Instance) (Result, error) {
+		runs <- inst
+		return Result{MessagesSent: 5, Failures: 1}, nil
+	})
+	canary := NewCanary(NewEngine(executor, 1), Scenario{Name: "canary", Mode: ModeLatency}, time.Hour)
+	_, ok := canary.Last()
+	assert.False(t, ok, "no report before the first probe")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		canary.Run(ctx)
+		close(done)
+	}()
+	inst := <-runs
+	cancel()
+	<-done
+
+	assert.True(t, strings.HasPrefix(inst.RunID, "canary-"))
+	assert.Equal(t, ModeLatency, inst.Scenario.Mode)
+	report, ok := canary.Last()
+	if assert.True(t, ok) {
+		assert.Equal(t, 1, report.Failures)
+		assert.Equal(t, 5, report.MessagesSent)
+	}
+}
@@ -0,0 +1,98 @@
+package scenario
+
+import (
+	"fmt"
+)
+
+const (
+	// AssertExpectRetries passes when every observed message was retried exactly Retries
+	// times from Queue
+	AssertExpectRetries = "expect_retries"
+	// AssertExpectFinalQueue passes when every observed message ended up in Queue
+	AssertExpectFinalQueue = "expect_final_queue"
+)
+
+// Death is one x-death entry, the broker's record of a message being dead lettered
+type Death struct {
+	Queue    string `json:"queue"`
+	Reason   string `json:"reason"`
+	Exchange string `json:"exchange,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// Delivery is the redelivery history of a consumed message
+type Delivery struct {
+	MessageID   string `json:"messageId,omitempty"`
+	Queue       string `json:"queue"`
+	Redelivered bool   `json:"redelivered"`
+	// DeliveryCount is the x-delivery-count quorum queues keep for requeued messages
+	DeliveryCount int     `json:"deliveryCount,omitempty"`
+	Deaths        []Death `json:"deaths,omitempty"`
+}
+
+// Retries returns how often the message was retried from queue: its dead letter count
+// there, or for messages requeued in place the quorum queue delivery count
+func (d Delivery) Retries(queue string) int {
+	n := 0
+	for _, death := range d.Deaths {
+		if death.Queue == queue {
+			n += death.Count
+		}
+	}
+	if n == 0 && d.Queue == queue {
+		n = d.DeliveryCount
+	}
+	return n
+}
+
+// CountRedelivered returns how many deliveries were redelivered or dead lettered at least once
+func CountRedelivered(deliveries []Delivery) int {
+	n := 0
+	for _, d := range deliveries {
+		if d.Redelivered || d.DeliveryCount > 0 || len(d.Deaths) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func describe(d Delivery) string {
+	if d.MessageID != "" {
+		return fmt.Sprintf("message %s", d.MessageID)
+	}
+	return "a message"
+}
+
+// CheckRedeliveryAssertions evaluates the retry and final destination assertions of s
+// against the deliveries observed at the end of the retry chain
+func CheckRedeliveryAssertions(s Scenario, deliveries []Delivery) []AssertionResult {
+	var results []AssertionResult
+	for _, a := range s.Assertions {
+		if a.Type != AssertExpectRetries && a.Type != AssertExpectFinalQueue {
+			continue
+		}
+
+		res := AssertionResult{Assertion: a, Passed: true}
+		if len(deliveries) == 0 {
+			res.Passed = false
+			res.Message = "no deliveries were observed"
+		}
+		for _, d := range deliveries {
+			if a.Type == AssertExpectRetries {
+				if got := d.Retries(a.Queue); got != a.Retries {
+					res.Passed = false
+					res.Message = fmt.Sprintf(
+						"%s was retried %d times from %q, expected %d", describe(d), got, a.Queue, a.Retries,
+					)
+					break
+				}
+			} else if d.Queue != a.Queue {
+				res.Passed = false
+				res.Message = fmt.Sprintf("%s ended in %q, expected %q", describe(d), d.Queue, a.Queue)
+				break
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,35 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRedeliveryAssertions(t *testing.T) {
+	s := Scenario{
+		Assertions: []Assertion{
+			{Type: AssertExpectRetries, Queue: "orders", Retries: 3},
+			{Type: AssertExpectFinalQueue, Queue: "orders.parked"},
+			{Type: AssertExpectRouted, RoutingKey: "ignored"},
+		},
+	}
+	parked := Delivery{
+		MessageID: "m-1", Queue: "orders.parked",
+		Deaths: []Death{{Queue: "orders", Reason: "rejected", Count: 3}},
+	}
+
+	results := CheckRedeliveryAssertions(s, []Delivery{parked})
+	assert.Len(t, results, 2)
+	assert.True(t, results[0].Passed)
+	assert.True(t, results[1].Passed)
+
+	early := Delivery{MessageID: "m-2", Queue: "orders.parked", Deaths: []Death{{Queue: "orders", Count: 1}}}
+	results = CheckRedeliveryAssertions(s, []Delivery{parked, early})
+	assert.False(t, results[0].Passed)
+	assert.Contains(t, results[0].Message, "m-2 was retried 1 times")
+
+	results = CheckRedeliveryAssertions(s, nil)
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, 1, CountRedelivered([]Delivery{parked, {Queue: "orders"}}))
+}
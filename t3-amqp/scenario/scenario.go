@@ -0,0 +1,236 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"t3-amqp/payload"
+	"time"
+)
+
+// Scenario modes, invalid scenarios publish messages that each break one rule of the
+// schema. Latency scenarios publish valid probes and only fail for probes that do not
+// come back, see ProbeOptions.
+const (
+	ModeValid   = "valid"
+	ModeInvalid = "invalid"
+	ModeLatency = "latency"
+)
+
+// SchemaRef identifies a registered schema
+type SchemaRef struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	// Tenant the schema is looked up in, empty is the default tenant
+	Tenant string `json:"-"`
+}
+
+// Scenario describes a topic test: what to publish, where, and how much. PayloadPool > 0
+// pre-generates that many payload variants before publishing starts. PayloadSize pads
+// payloads towards a size distribution to evaluate the broker across message size mixes.
+// Ack selects how the consumer acknowledges messages, by default one manual ack each.
+// Group replaces the consumer with several competing ones, Chaos injects faults.
+// Schedule is a cron expression the scenario runs on by itself, see ParseCron.
+type Scenario struct {
+	ID           int               `json:"id"`
+	Name         string            `json:"name"`
+	Topic        string            `json:"topic"`
+	Schema       SchemaRef         `json:"schema"`
+	MessageCount int               `json:"messageCount"`
+	Rate         int               `json:"rate"`
+	Mode         string            `json:"mode"`
+	PayloadPool  int               `json:"payloadPool,omitempty"`
+	PayloadSize  *payload.SizeSpec `json:"payloadSize,omitempty"`
+	Ack          *AckStrategy      `json:"ack,omitempty"`
+	Group        *ConsumerGroup    `json:"group,omitempty"`
+	Chaos        *ChaosOptions     `json:"chaos,omitempty"`
+	Schedule     string            `json:"schedule,omitempty"`
+	Assertions   []Assertion       `json:"assertions,omitempty"`
+	MQTT         *MQTTOptions      `json:"mqtt,omitempty"`
+	Probe        *ProbeOptions     `json:"probe,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+}
+
+// Validate checks that the scenario is complete, an empty mode is ModeValid
+func (s Scenario) Validate() error {
+	if s.Name == "" || s.Topic == "" {
+		return fmt.Errorf("name and topic are required")
+	}
+	if s.Schema.Name == "" || s.Schema.Type == "" || s.Schema.Version == "" {
+		return fmt.Errorf("schema needs name, type and version")
+	}
+	if s.MessageCount <= 0 {
+		return fmt.Errorf("messageCount must be positive, got %d", s.MessageCount)
+	}
+	if s.Rate < 0 {
+		return fmt.Errorf("rate must not be negative, got %d", s.Rate)
+	}
+	switch s.Mode {
+	case "", ModeValid, ModeLatency:
+	case ModeInvalid:
+		if s.PayloadPool > 0 || s.PayloadSize != nil {
+			return fmt.Errorf("payloadPool and payloadSize only apply to valid scenarios")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q, use valid, invalid or latency", s.Mode)
+	}
+	if s.Probe != nil {
+		if s.Mode != ModeLatency {
+			return fmt.Errorf("probe only applies to latency scenarios")
+		}
+		if err := s.Probe.Validate(s.Topic); err != nil {
+			return err
+		}
+	}
+	if s.PayloadPool < 0 {
+		return fmt.Errorf("payloadPool must not be negative, got %d", s.PayloadPool)
+	}
+	if s.PayloadSize != nil {
+		if _, err := s.PayloadSize.Distribution(); err != nil {
+			return err
+		}
+	}
+	if s.MQTT != nil {
+		if err := s.MQTT.Validate(s.Topic); err != nil {
+			return err
+		}
+	}
+	if s.Group != nil {
+		if err := s.Group.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.Chaos != nil {
+		if err := s.Chaos.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.Schedule != "" {
+		if _, err := ParseCron(s.Schedule); err != nil {
+			return err
+		}
+	}
+	if s.Ack != nil {
+		return s.Ack.Validate()
+	}
+	return nil
+}
+
+// Instance is one concrete execution of a scenario. Instances of the same scenario
+// differ only in their parameters and each gets its own temporary queue, which receives
+// the reply topic through its own temporary exchange.
+type Instance struct {
+	RunID        string            `json:"runId"`
+	Index        int               `json:"index"`
+	Scenario     Scenario          `json:"scenario"`
+	Params       map[string]string `json:"params,omitempty"`
+	TempQueue    string            `json:"tempQueue"`
+	TempExchange string            `json:"tempExchange,omitempty"`
+}
+
+// ThrottleInterval is a period during which the broker held back publishers, so low
+// throughput in that window is not a tool limitation
+type ThrottleInterval struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason"`
+}
+
+// Result is the outcome of a single instance
+type Result struct {
+	Instance      Instance      `json:"instance"`
+	Started       time.Time     `json:"started"`
+	Duration      time.Duration `json:"durationNs"`
+	MessagesSent  int           `json:"messagesSent"`
+	MessagesValid int           `json:"messagesValid"`
+	Failures      int           `json:"failures"`
+	// Redelivered counts consumed messages that were requeued or dead lettered
+	Redelivered int `json:"redelivered,omitempty"`
+	// Latency is the end to end latency of the consumed messages
+	Latency *Latency `json:"latency,omitempty"`
+	// ConfirmLatency is the time the broker took to confirm published messages
+	ConfirmLatency *Latency `json:"confirmLatency,omitempty"`
+	// Ack reports the unacked depth and throughput of the consumer's ack strategy
+	Ack *AckStats `json:"ack,omitempty"`
+	// Consumers reports each consumer of a consumer group instead of Ack
+	Consumers []ConsumerStats `json:"consumers,omitempty"`
+	// Chaos counts the faults injected by the scenario's chaos options
+	Chaos *ChaosStats `json:"chaos,omitempty"`
+	// MissedHeartbeats counts broker connection drops caused by missed heartbeats
+	MissedHeartbeats int `json:"missedHeartbeats,omitempty"`
+	// Throttled lists the intervals where the broker raised alarms or applied flow control
+	Throttled  []ThrottleInterval `json:"throttled,omitempty"`
+	Assertions []AssertionResult  `json:"assertions,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// Passed reports whether the instance ran without errors, validation failures or
+// failed assertions
+func (r Result) Passed() bool {
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			return false
+		}
+	}
+	return r.Error == "" && r.Failures == 0
+}
+
+// Executor runs one instance, typically by publishing to and consuming from a broker
+type Executor interface {
+	Execute(ctx context.Context, inst Instance) (Result, error)
+}
+
+// ExecutorFunc adapts a function to the Executor interface
+type ExecutorFunc func(ctx context.Context, inst Instance) (Result, error)
+
+func (f ExecutorFunc) Execute(ctx context.Context, inst Instance) (Result, error) {
+	return f(ctx, inst)
+}
+
+// TempQueueName is the isolated queue name for an instance
+func TempQueueName(runID string, index int) string {
+	return fmt.Sprintf("t3.%s.%d", runID, index)
+}
+
+// TempExchangeName is the isolated exchange name for an instance
+func TempExchangeName(runID string, index int) string {
+	return fmt.Sprintf("t3.%s.%d.x", runID, index)
+}
+
+// mergeParams overlays instance parameters on the scenario defaults
+func mergeParams(base, overlay map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Expand turns scenarios into instances for one run. A scenario with parameter sets is
+// expanded into one instance per set, otherwise it yields a single instance.
+func Expand(runID string, scenarios []Scenario, paramSets map[int][]map[string]string) []Instance {
+	var instances []Instance
+	for _, s := range scenarios {
+		sets := paramSets[s.ID]
+		if len(sets) == 0 {
+			sets = []map[string]string{nil}
+		}
+		for _, set := range sets {
+			idx := len(instances)
+			instances = append(
+				instances, Instance{
+					RunID:        runID,
+					Index:        idx,
+					Scenario:     s,
+					Params:       mergeParams(s.Params, set),
+					TempQueue:    TempQueueName(runID, idx),
+					TempExchange: TempExchangeName(runID, idx),
+				},
+			)
+		}
+	}
+	return instances
+}
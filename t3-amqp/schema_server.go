@@ -1,33 +1,751 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"t3-amqp/amqp"
+	"t3-amqp/auth"
+	"t3-amqp/auth/oidc"
+	"t3-amqp/blob"
+	"t3-amqp/broker"
 	"t3-amqp/db"
+	"t3-amqp/gitsync"
+	"t3-amqp/kafka"
+	"t3-amqp/lifecycle"
+	"t3-amqp/metrics"
+	"t3-amqp/mqtt"
+	"t3-amqp/nats"
+	"t3-amqp/quota"
+	"t3-amqp/redact"
 	"t3-amqp/rest"
+	"t3-amqp/scenario"
+	"t3-amqp/tracing"
+	"t3-amqp/validate"
+	"t3-amqp/webhook"
 )
 
+// registry is what the server needs from its store, the Postgres and the memory store
+// both provide it
+type registry interface {
+	db.SchemaStore
+	db.Upserter
+	db.AuditRecorder
+	db.APIKeyStore
+	db.ScenarioStore
+	db.RunStore
+	db.CaptureStore
+	db.WebhookStore
+	db.TenantStore
+}
+
 func main() {
+	// Scrub credentials from everything that goes through the standard logger
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
+	// Flags override the environment, which overrides the configuration file
+	if err := db.RegisterFlags(pflag.CommandLine); err != nil {
+		log.Fatalf("Failed to register flags: %v", err)
+	}
+	pflag.Parse()
+
 	// Load the database configuration
 	config, err := db.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	redact.SetSensitiveFields(config.Redact.Fields...)
+	redact.AddSecret(config.Auth.AdminKey)
+	db.SetMaxSchemaBytes(config.Limits.SchemaDataBytes)
+
+	// SIGINT and SIGTERM stop the background work and drain the HTTP server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export spans of requests, queries and broker messages once a collector is configured
+	tracingConfig, err := tracing.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load tracing config: %v", err)
+	}
+	shutdownTracing := tracing.Setup(*tracingConfig)
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), tracingConfig.Timeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("Failed to flush spans: %v", err)
+		}
+	}()
+
+	// Schema changes recorded in the audit trail are sent to the registered webhooks
+	webhookConfig, err := webhook.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load webhooks config: %v", err)
+	}
+
+	// The registry lives in Postgres unless --store=memory keeps it in process, where
+	// aliases, statistics, the audit endpoint, bulk deletes and lifecycle schedules are
+	// not available
+	var pool *pgxpool.Pool
+	var pinger db.Pinger
+	var store registry
+	switch config.Store.Kind {
+	case db.StorePostgres:
+		// Connect to the database, waiting for it when it is still starting up
+		pool, err = db.ConnectDB(config, tracing.QueryTracer{})
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer pool.Close()
+
+		// Bootstrap or upgrade the tables before anything reads them
+		if config.DB.Migrate {
+			applied, err := db.Migrate(ctx, pool)
+			if err != nil {
+				log.Fatalf("Failed to migrate database: %v", err)
+			}
+			log.Printf("Database is up to date, %d migrations applied", len(applied))
+			fingerprinted, err := db.BackfillFingerprints(ctx, pool, validate.SchemaFingerprints)
+			if err != nil {
+				log.Fatalf("Failed to fingerprint schemas: %v", err)
+			}
+			if fingerprinted > 0 {
+				log.Printf("Backfilled the fingerprints of %d schemas", fingerprinted)
+			}
+		}
+
+		postgresStore := db.NewPostgresStore(pool)
+		webhooks := webhook.NewDispatcher(postgresStore, *webhookConfig)
+		defer webhooks.Close()
+		pinger, store = pool, webhook.NewStore(postgresStore, webhooks)
+	case db.StoreMemory:
+		memoryStore, err := db.OpenMemoryStore(config.Store.SnapshotFile)
+		if err != nil {
+			log.Fatalf("Failed to restore memory store: %v", err)
+		}
+		if path := config.Store.SnapshotFile; path != "" {
+			go memoryStore.SnapshotEvery(ctx, path, config.Store.SnapshotInterval)
+			// Deferred before the dispatcher and the server drain so the final snapshot
+			// is written last
+			defer func() {
+				if err := memoryStore.SaveSnapshot(path); err != nil {
+					log.Printf("Failed to snapshot memory store: %v", err)
+				}
+			}()
+			log.Printf("Snapshotting the memory store to %s every %s", path, config.Store.SnapshotInterval)
+		}
+
+		webhooks := webhook.NewDispatcher(memoryStore, *webhookConfig)
+		defer webhooks.Close()
+		pinger, store = memoryStore, webhook.NewMemoryStore(memoryStore, webhooks)
+	default:
+		log.Fatalf("Unknown store %q, expected %s or %s", config.Store.Kind, db.StorePostgres, db.StoreMemory)
+	}
+
+	// Large schema documents spill out of schema_data into the blob store, which the
+	// stores read them back from
+	blobDir := config.Upload.BlobDir
+	if blobDir == "" {
+		blobDir = filepath.Join(os.TempDir(), "t3-blobs")
+	}
+	blobs, err := blob.NewDirStore(blobDir)
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+	db.SetBlobStore(blobs)
+
+	// Fixtures give tests and demo environments a known set of schemas and scenarios
+	if config.Store.Seed != "" {
+		if err := db.LoadFixturesInto(ctx, store, config.Store.Seed); err != nil {
+			log.Fatalf("Failed to load fixtures: %v", err)
+		}
+		log.Printf("Loaded fixtures from %s", config.Store.Seed)
+	}
+
+	// Connect to the broker, the registry keeps working without one. The broker kind
+	// selects whether topic tests run on AMQP, Kafka, MQTT or NATS.
+	brokerConfig, err := amqp.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load broker config: %v", err)
+	}
+	var conn *amqp.Conn
+	// Kafka, MQTT and NATS run topic tests through the broker abstraction only
+	var other broker.Broker
+	switch brokerConfig.Kind {
+	case broker.KindAMQP:
+		if brokerConfig.URL != "" {
+			conn, err = amqp.Dial(*brokerConfig)
+			if err != nil {
+				log.Printf("Broker unavailable, topic tests are disabled: %v", err)
+			} else {
+				defer conn.Close()
+			}
+		}
+	case broker.KindKafka:
+		kafkaConfig, err := kafka.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load kafka config: %v", err)
+		}
+		dialCtx, cancel := context.WithTimeout(ctx, kafkaConfig.DialTimeout)
+		kafkaBroker, err := kafka.Dial(dialCtx, *kafkaConfig)
+		cancel()
+		if err != nil {
+			log.Printf("Kafka unavailable, topic tests are disabled: %v", err)
+		} else {
+			defer kafkaBroker.Close()
+			other = kafkaBroker
+		}
+	case broker.KindMQTT:
+		mqttConfig, err := mqtt.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load mqtt config: %v", err)
+		}
+		dialCtx, cancel := context.WithTimeout(ctx, mqttConfig.ConnectTimeout)
+		mqttBroker, err := mqtt.Dial(dialCtx, *mqttConfig)
+		cancel()
+		if err != nil {
+			log.Printf("MQTT server unavailable, topic tests are disabled: %v", err)
+		} else {
+			defer mqttBroker.Close()
+			other = mqttBroker
+		}
+	case broker.KindNATS:
+		natsConfig, err := nats.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load nats config: %v", err)
+		}
+		natsBroker, err := nats.Dial(*natsConfig)
+		if err != nil {
+			log.Printf("NATS unavailable, topic tests are disabled: %v", err)
+		} else {
+			defer natsBroker.Close()
+			other = natsBroker
+		}
+	default:
+		log.Fatalf(
+			"Unknown broker kind %q, expected %s, %s, %s or %s",
+			brokerConfig.Kind, broker.KindAMQP, broker.KindKafka, broker.KindMQTT, broker.KindNATS,
+		)
+	}
+
+	// Declare the configured topology, publish test messages and verify consumed ones
+	var publisher rest.Publisher
+	var verifications *amqp.Verifications
+	var amqpPublisher *amqp.Publisher
+	var deadLetters rest.DeadLetterInspector
+	if conn != nil {
+		if err := amqp.DeclareTopology(conn, *brokerConfig); err != nil {
+			log.Printf("Failed to declare broker topology: %v", err)
+		}
+		amqpPublisher = amqp.NewPublisher(conn, store, brokerConfig.Exchange).WithHeaders(brokerConfig.Headers)
+		publisher = amqpPublisher
+		verifications = amqp.NewVerifications(amqp.NewVerifier(store).WithHeaders(brokerConfig.Headers), conn)
+		deadLetters = amqp.NewDeadLetters(conn, store).WithHeaders(brokerConfig.Headers)
+	}
+	if other != nil {
+		publisher = broker.NewPublisher(other, store).WithHeaders(brokerConfig.Headers)
+	}
+
+	// Remove temporary queues and exchanges left behind by runs that crashed
+	journal, err := scenario.OpenJournal(config.Scenarios.Journal)
+	if err != nil {
+		log.Fatalf("Failed to open scenario cleanup journal: %v", err)
+	}
+	if conn != nil {
+		removed, err := scenario.Sweep(ctx, amqp.NewTempResources(conn), journal)
+		if err != nil {
+			log.Printf("Failed to sweep leftover test resources: %v", err)
+		}
+		if len(removed) > 0 {
+			log.Printf("Removed %d leftover test resources", len(removed))
+		}
+	}
 
-	// Connect to the database
-	pool, err := db.ConnectDB(config)
+	// Runs started through the API and by the scheduler share the run slots of a tenant
+	quotas := quota.NewManager(config.Quota)
+
+	// Stored scenarios run on an engine publishing and consuming through temporary queues,
+	// load tests and captures share the cleanup journal. On the other brokers only
+	// scenarios run, they leave nothing behind to clean up.
+	var engine *scenario.Engine
+	var runner rest.ScenarioRunner
+	var loadRunner rest.LoadRunner
+	var capturer rest.TrafficCapturer
+	if conn != nil {
+		engine = scenario.NewEngine(amqp.NewExecutor(conn, amqpPublisher), config.Scenarios.Workers).
+			WithCleanup(amqp.NewTempResources(conn), journal)
+		loadRunner = amqp.NewLoadTester(conn, amqpPublisher, journal)
+		capturer = amqp.NewCapturer(conn, brokerConfig.Exchange, store, journal)
+		// Runs report when the broker throttled them if its management API is configured
+		if mgmt := brokerConfig.Management; mgmt.URL != "" {
+			rest.SetManagementClient(amqp.NewManagementClient(mgmt.URL, mgmt.User, mgmt.Password), mgmt.PollInterval)
+		}
+	}
+	if other != nil {
+		executor := broker.NewExecutor(other, store).WithHeaders(brokerConfig.Headers)
+		engine = scenario.NewEngine(executor, config.Scenarios.Workers)
+	}
+	if engine != nil {
+		runner = engine
+		// Scenarios with a cron schedule run by themselves
+		go rest.NewScenarioScheduler(store, store, runner, quotas).Run(ctx, config.Scenarios.ScheduleInterval)
+	}
+
+	// A canary keeps the probe latency metrics current by running a stored latency scenario
+	if id := config.Scenarios.Canary.Scenario; id > 0 && engine != nil {
+		s, err := rest.LoadScenario(store, id)
+		switch {
+		case err != nil:
+			log.Printf("Canary disabled, failed to load scenario %d: %v", id, err)
+		case s.Mode != scenario.ModeLatency:
+			log.Printf("Canary disabled, scenario %d is not a latency scenario", id)
+		default:
+			go scenario.NewCanary(engine, s, config.Scenarios.Canary.Interval).Run(ctx)
+		}
+	}
+
+	mode, err := rest.ParseMode(config.Server.Mode)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Invalid server mode: %v", err)
+	}
+	modes := rest.NewModeController(mode, config.Server.RetryAfter)
+
+	// Ping the database in the background, dropping broken connections when it goes away
+	health := db.NewHealth(pinger)
+	go health.Run(ctx, config.DB.PingInterval)
+
+	// Sample pool and runtime statistics in the background
+	sampler := metrics.NewSampler(pool)
+	go sampler.Run(ctx, metrics.DefaultSampleInterval)
+
+	// Apply scheduled deprecations and retirements in the background
+	if pool != nil {
+		scheduler := lifecycle.NewScheduler(
+			pool, lifecycle.Notifiers{lifecycle.LogNotifier{}, lifecycle.AuditNotifier{Pool: pool}},
+		)
+		go scheduler.Run(ctx, config.Lifecycle.Interval)
+	}
+
+	// Register the schema files of the configured Git repository, on request and on a timer
+	var syncer *rest.SchemaSyncer
+	if config.Sync.Repo != "" {
+		syncDir := config.Sync.Dir
+		if syncDir == "" {
+			syncDir = filepath.Join(os.TempDir(), "t3-schema-repo")
+		}
+		syncer = rest.NewSchemaSyncer(
+			store, quotas, gitsync.NewRepo(config.Sync.Repo, config.Sync.Branch, syncDir), config.Sync.Path,
+		)
+		if config.Sync.Interval > 0 {
+			go syncer.Run(ctx, config.Sync.Interval)
+		}
+	}
+
+	// Write the schemas to a directory or a Git repository for backups and review
+	var snapshotter *rest.SchemaSnapshotter
+	if config.Snapshot.Dir != "" {
+		var snapshotRepo *gitsync.Repo
+		if config.Snapshot.Repo != "" {
+			snapshotRepo = gitsync.NewRepo(config.Snapshot.Repo, config.Snapshot.Branch, config.Snapshot.Dir)
+		}
+		snapshotter = rest.NewSchemaSnapshotter(
+			store, snapshotRepo, config.Snapshot.Path, config.Snapshot.Push, config.Snapshot.Author,
+		)
+		if config.Snapshot.Interval > 0 {
+			go snapshotter.Run(ctx, config.Snapshot.Interval)
+		}
+	}
+
+	// JSON schemas may reference other registered schemas, resolved when they are compiled
+	validate.DefaultCache.UseStore(store)
+
+	// Validations run on a bounded pool so bursts are shed instead of piling up
+	validators := validate.NewPool(config.Validation.Workers, config.Validation.QueueSize)
+	defer validators.Close()
+
+	uploadLimits := rest.UploadLimits{MaxBytes: config.Upload.MaxBytes, InlineBytes: config.Upload.InlineBytes}
+
+	limits := rest.BodyLimits{
+		Schema:     config.Limits.SchemaBytes,
+		Validation: config.Limits.ValidationBytes,
+		Default:    config.Limits.DefaultBytes,
+		Max:        config.Limits.MaxBytes,
+	}.WithDefaults()
+
+	// Readiness covers every dependency requests rely on, the broker once one is configured
+	readiness := map[string]rest.ReadinessCheck{"database": health.Ready}
+	var brokerState func() amqp.ConnState
+	if pool != nil {
+		readiness["migrations"] = func(ctx context.Context) error { return db.MigrationsApplied(ctx, pool) }
+	}
+	if brokerConfig.Kind == broker.KindAMQP && brokerConfig.URL != "" {
+		readiness["amqp"] = func(ctx context.Context) error {
+			if conn == nil {
+				return errors.New("broker connection was never established")
+			}
+			return conn.Ready(ctx)
+		}
+		brokerState = func() amqp.ConnState { return conn.State() }
 	}
-	defer pool.Close()
 
-	http.HandleFunc("/health", rest.HealthCheckHandler(pool).ServeHTTP)
-	http.HandleFunc("/schema", rest.SchemaEndpointHandler(pool).ServeHTTP)
-	http.HandleFunc("/schemas", rest.GetAllSchemasHandler(pool).ServeHTTP)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", rest.HealthCheckHandler(pinger).ServeHTTP)
+	mux.HandleFunc("/health/details", rest.HealthDetailsHandler(health, sampler, brokerState))
+	mux.HandleFunc("/healthz", rest.LivenessHandler())
+	mux.HandleFunc("/readyz", rest.ReadinessHandler(readiness))
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/openapi.json", rest.OpenAPIHandler())
+	ui := rest.UIHandler()
+	mux.HandleFunc("GET /{$}", ui)
+	mux.HandleFunc("GET /ui/", ui)
+	mux.HandleFunc(
+		"/schema",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Schema, rest.ContentTypeMiddleware(
+					rest.StructuredMediaTypes,
+					rest.SchemaQuotaMiddleware(store, quotas, rest.SchemaEndpointHandler(store)),
+				),
+			),
+		),
+	)
+	mux.HandleFunc("/schema/versions", rest.SchemaVersionsHandler(store))
+	mux.HandleFunc("/schema/diff", rest.SchemaDiffHandler(store))
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	mux.HandleFunc("POST /schema/{id}/restore", rest.RestoreSchemaHandler(store))
+	mux.HandleFunc("GET /schema/{id}/references", rest.SchemaReferencesHandler(store))
+	mux.HandleFunc(
+		"/schema/upload",
+		rest.QuotaMiddleware(
+			quotas, rest.ContentTypeMiddleware(
+				rest.UploadMediaTypes,
+				rest.SchemaQuotaMiddleware(store, quotas, rest.UploadSchemaHandler(store, blobs, uploadLimits)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"POST /schema/{name}/match",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Validation,
+				rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.MatchHandler(store, validators)),
+			),
+		),
+	)
+	mux.HandleFunc("/schemas", rest.QuotaMiddleware(quotas, rest.SchemasEndpointHandler(store, pool)))
+	mux.HandleFunc("/schemas/count", rest.SchemaCountHandler(store))
+	mux.HandleFunc("/schemas/search", rest.SearchSchemasHandler(store))
+	mux.HandleFunc(
+		"/schemas/import",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Schema,
+				rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.ImportSchemasHandler(store, quotas)),
+			),
+		),
+	)
+	mux.HandleFunc("/schemas/export", rest.QuotaMiddleware(quotas, rest.ExportSchemasHandler(store)))
+	mux.HandleFunc("/sync", rest.SyncHandler(syncer))
+	mux.HandleFunc("/subjects", rest.ConfluentSubjectsHandler(store))
+	mux.HandleFunc(
+		"/subjects/{subject}",
+		rest.BodyLimitMiddleware(
+			limits.Schema, rest.ContentTypeMiddleware(rest.ConfluentMediaTypes, rest.ConfluentLookupHandler(store)),
+		),
+	)
+	mux.HandleFunc(
+		"/subjects/{subject}/versions",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Schema, rest.ContentTypeMiddleware(
+					rest.ConfluentMediaTypes,
+					rest.SchemaQuotaMiddleware(store, quotas, rest.ConfluentVersionsHandler(store)),
+				),
+			),
+		),
+	)
+	mux.HandleFunc("/subjects/{subject}/versions/{version}", rest.ConfluentVersionHandler(store))
+	mux.HandleFunc("/schemas/ids/{id}", rest.ConfluentSchemaByIdHandler(store))
+	mux.HandleFunc("/quota", rest.QuotaUsageHandler(quotas))
+	// Lifecycle schedules, aliases, statistics and the audit endpoint query Postgres directly
+	if pool != nil {
+		mux.HandleFunc(
+			"/schema/lifecycle",
+			rest.BodyLimitMiddleware(
+				limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.LifecycleHandler(pool)),
+			),
+		)
+		mux.HandleFunc(
+			"/aliases",
+			rest.BodyLimitMiddleware(
+				limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.AliasesHandler(pool)),
+			),
+		)
+		mux.HandleFunc("/stats", rest.StatsHandler(pool))
+		mux.HandleFunc("/audit", rest.AuditHandler(pool))
+	}
+	mux.HandleFunc(
+		"/validate",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Validation,
+				rest.ContentTypeMiddleware(rest.ValidationMediaTypes, rest.ValidateHandler(store, validators)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"/normalize",
+		rest.BodyLimitMiddleware(
+			limits.Schema, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.NormalizeHandler(store)),
+		),
+	)
+	mux.HandleFunc(
+		"/encode",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Validation,
+				rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.EncodeHandler(store)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"/decode",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Validation, rest.ContentTypeMiddleware(rest.CodecMediaTypes, rest.DecodeHandler(store)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"/publish",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Validation,
+				rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.PublishHandler(publisher)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"/generate",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Default,
+				rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.GenerateHandler(store, publisher)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"/verify",
+		rest.BodyLimitMiddleware(
+			limits.Default,
+			rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.VerificationsHandler(verifications)),
+		),
+	)
+	mux.HandleFunc("GET /verify/{id}", rest.VerificationHandler(verifications))
+	mux.HandleFunc(
+		"/deadletters",
+		rest.BodyLimitMiddleware(
+			limits.Default,
+			rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.DeadLettersHandler(deadLetters)),
+		),
+	)
+	mux.HandleFunc(
+		"/scenarios",
+		rest.BodyLimitMiddleware(
+			limits.Default,
+			rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.ScenariosHandler(store, store)),
+		),
+	)
+	mux.HandleFunc(
+		"/scenarios/{id}",
+		rest.BodyLimitMiddleware(
+			limits.Default,
+			rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.ScenarioHandler(store, store)),
+		),
+	)
+	mux.HandleFunc(
+		"POST /scenarios/{id}/run", rest.QuotaMiddleware(quotas, rest.RunScenarioHandler(store, store, runner, quotas)),
+	)
+	mux.HandleFunc("GET /scenarios/{id}/runs", rest.ScenarioRunsHandler(store, store))
+	mux.HandleFunc("GET /scenarios/{id}/schedule", rest.ScenarioScheduleHandler(store, store))
+	loadLimits := rest.LoadTestLimits{
+		MaxDuration: config.LoadTests.MaxDuration, MaxConcurrency: config.LoadTests.MaxConcurrency,
+	}
+	mux.HandleFunc(
+		"/loadtests",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Default,
+				rest.ContentTypeMiddleware(
+					rest.StructuredMediaTypes, rest.LoadTestHandler(store, store, loadRunner, loadLimits, quotas),
+				),
+			),
+		),
+	)
+	mux.HandleFunc("/runs", rest.RunsHandler(store))
+	mux.HandleFunc("GET /runs/{id}", rest.RunHandler(store))
+	captureLimits := rest.CaptureLimits{
+		MaxDuration: config.Captures.MaxDuration, MaxMessages: config.Captures.MaxMessages,
+	}
+	mux.HandleFunc(
+		"/captures",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Default,
+				rest.ContentTypeMiddleware(
+					rest.StructuredMediaTypes, rest.CapturesHandler(store, capturer, captureLimits, quotas),
+				),
+			),
+		),
+	)
+	mux.HandleFunc("/captures/{id}", rest.CaptureHandler(store))
+	mux.HandleFunc("GET /captures/{id}/messages", rest.CapturedMessagesHandler(store))
+	mux.HandleFunc(
+		"POST /captures/{id}/replay",
+		rest.QuotaMiddleware(
+			quotas, rest.BodyLimitMiddleware(
+				limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.ReplayHandler(store, capturer)),
+			),
+		),
+	)
+	mux.HandleFunc(
+		"/admin/mode",
+		rest.BodyLimitMiddleware(
+			limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.ModeHandler(modes)),
+		),
+	)
+	mux.HandleFunc(
+		"/admin/keys",
+		rest.BodyLimitMiddleware(
+			limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.APIKeysHandler(store)),
+		),
+	)
+	mux.HandleFunc(
+		"/admin/tenants",
+		rest.BodyLimitMiddleware(
+			limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.TenantsHandler(store)),
+		),
+	)
+	mux.HandleFunc("/admin/snapshot", rest.SnapshotHandler(snapshotter))
+	mux.HandleFunc(
+		"/admin/webhooks",
+		rest.BodyLimitMiddleware(
+			limits.Default, rest.ContentTypeMiddleware(rest.StructuredMediaTypes, rest.WebhooksHandler(store)),
+		),
+	)
 
 	// Start the HTTP server
-	log.Println("Starting server on localhost:8080")
-	if err := http.ListenAndServe("localhost:8080", nil); err != nil {
+	var tokens *oidc.Verifier
+	if config.Auth.OIDC.Issuer != "" {
+		tokens, err = oidc.NewVerifier(config.Auth.OIDC, nil)
+		if err != nil {
+			log.Fatalf("Failed to configure OIDC: %v", err)
+		}
+		log.Printf("Accepting bearer tokens issued by %s", config.Auth.OIDC.Issuer)
+	}
+	authenticator := auth.NewAuthenticator(config.Auth.Enabled, config.Auth.AdminKey, store, tokens)
+	handler := rest.RequestIDMiddleware(
+		rest.TracingMiddleware(
+			mux, rest.MetricsMiddleware(
+				mux, rest.CompressionMiddleware(
+					rest.ErrorMiddleware(
+						rest.BodyLimitMiddleware(
+							limits.Max, rest.RecoverMiddleware(
+								rest.AuthMiddleware(
+									authenticator, mux, rest.TenantMiddleware(
+										store, rest.WriteRateMiddleware(quotas, mux, rest.ModeMiddleware(modes, mux, mux)),
+									),
+								),
+							),
+						),
+					),
+				),
+			),
+		),
+	)
+	server := &http.Server{Addr: config.Server.Addr, Handler: handler}
+	scheme := "http"
+	if config.Server.TLS.Enabled() {
+		server.TLSConfig, err = config.Server.TLS.Server()
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+		scheme = "https"
+		if server.TLSConfig.ClientCAs != nil {
+			log.Printf("Verifying client certificates against %s", config.Server.TLS.ClientCAFile)
+		}
+	}
+	log.Printf("Starting server on %s://%s in %s mode", scheme, config.Server.Addr, mode)
+	served := make(chan error, 2)
+	go func() {
+		if server.TLSConfig != nil {
+			// The certificate comes from TLSConfig, which reloads it when the files change
+			served <- server.ListenAndServeTLS("", "")
+			return
+		}
+		served <- server.ListenAndServe()
+	}()
+
+	// Internal services may use the registry over gRPC, with the same credentials and TLS
+	var grpcServer *grpc.Server
+	if config.Server.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", config.Server.GRPCAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		var opts []grpc.ServerOption
+		if server.TLSConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(server.TLSConfig)))
+		}
+		grpcServer = rest.NewGRPCServer(
+			rest.NewGRPCRegistry(store, runner, validators, authenticator, quotas, modes), opts...,
+		)
+		log.Printf("Starting gRPC server on %s", config.Server.GRPCAddr)
+		go func() {
+			served <- grpcServer.Serve(listener)
+		}()
+	}
+
+	select {
+	case err := <-served:
 		log.Fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+
+	// A second signal kills the process without waiting for the drain
+	stop()
+	log.Printf("Shutting down, draining requests for up to %s", config.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
+	defer cancel()
+	grpcStopped := make(chan struct{})
+	if grpcServer != nil {
+		go func() {
+			grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to drain requests: %v", err)
+	}
+	if grpcServer != nil {
+		select {
+		case <-grpcStopped:
+		case <-shutdownCtx.Done():
+			// Calls still running when the drain times out are cut off
+			grpcServer.Stop()
+		}
 	}
+	// The deferred closes release the validators, the broker, the webhook dispatcher and
+	// the pool or the final memory snapshot in that order, then the last spans are flushed
+	log.Printf("Server stopped")
 }
@@ -0,0 +1,84 @@
+package t3client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"t3-amqp/db"
+	"t3-amqp/rest"
+	"t3-amqp/t3client"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientAgainstRegistry(t *testing.T) {
+	store := db.NewMemoryStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", rest.OpenAPIHandler())
+	mux.HandleFunc("/schema", rest.SchemaEndpointHandler(store))
+	mux.HandleFunc("/schema/versions", rest.SchemaVersionsHandler(store))
+	mux.HandleFunc("/schema/{id}", rest.GetSchemaByIdHandler(store))
+	server := httptest.NewServer(rest.ErrorMiddleware(mux))
+	defer server.Close()
+
+	client, err := t3client.NewClientWithResponses(server.URL)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	body := t3client.SchemaRequest{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: `{"type":"object"}`}
+	created, err := client.CreateSchemaWithResponse(ctx, body)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, created.JSON201) {
+		return
+	}
+
+	conflict, err := client.CreateSchemaWithResponse(ctx, body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, conflict.StatusCode())
+	if !assert.NotNil(t, conflict.JSON409) {
+		return
+	}
+	assert.Equal(t, created.JSON201.Id, conflict.JSON409.Id)
+
+	body.Version, body.SchemaData = "1.1.0", `{"type":"array"}`
+	_, err = client.CreateSchemaWithResponse(ctx, body)
+	assert.NoError(t, err)
+
+	byID, err := client.GetSchemaByIdWithResponse(ctx, created.JSON201.Id, nil)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, byID.JSON200) {
+		return
+	}
+	assert.Equal(t, "1.0.0", byID.JSON200.Version)
+	assert.Equal(t, t3client.Active, byID.JSON200.Status)
+
+	latest := "latest"
+	name, schemaType := "orders", "json"
+	found, err := client.GetSchemasWithResponse(
+		ctx, &t3client.GetSchemasParams{Name: &name, Type: &schemaType, Version: &latest},
+	)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, found.JSON200) {
+		return
+	}
+	schema, err := found.JSON200.AsSchema()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.0", schema.Version)
+
+	versions, err := client.ListSchemaVersionsWithResponse(
+		ctx, &t3client.ListSchemaVersionsParams{Name: name, Type: schemaType},
+	)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, versions.JSON200) {
+		return
+	}
+	assert.Len(t, versions.JSON200.Versions, 2)
+
+	missing, err := client.GetSchemaByIdWithResponse(ctx, 999, nil)
+	assert.NoError(t, err)
+	if !assert.NotNil(t, missing.ApplicationproblemJSON404) {
+		return
+	}
+	assert.Equal(t, http.StatusNotFound, missing.ApplicationproblemJSON404.Status)
+}
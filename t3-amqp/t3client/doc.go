@@ -0,0 +1,6 @@
+// Package t3client is a Go client for the schema registry REST API, generated from the
+// OpenAPI document the registry serves at /openapi.json. Run go generate after changing
+// rest/openapi.json.
+package t3client
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.5.0 -config oapi-codegen.yaml ../rest/openapi.json
@@ -0,0 +1,126 @@
+// Package tlsconfig builds the TLS settings of the HTTP server from the configuration,
+// including client certificate verification for mutual TLS
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Client certificate policies once ClientCAFile is set
+const (
+	ClientAuthRequire  = "require"
+	ClientAuthOptional = "optional"
+)
+
+// Config struct to hold the TLS settings of the server, it serves HTTPS when CertFile
+// and KeyFile are set
+type Config struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile turns on mutual TLS, client certificates must chain to one of its CAs
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ClientAuth is require, the default, or optional to also accept clients without a
+	// certificate while still verifying those that present one. Ignored without a CA.
+	ClientAuth string `mapstructure:"client_auth"`
+}
+
+// Enabled reports whether the server should serve HTTPS
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Server returns the tls.Config for the server. The certificate is read again when its
+// files change, so renewed certificates are picked up without a restart.
+func (c Config) Server() (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("tls cert_file and key_file must be given together")
+	}
+	cert := &certificate{certFile: c.CertFile, keyFile: c.KeyFile}
+	if _, err := cert.get(); err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return cert.get()
+		},
+	}
+
+	var clientAuth tls.ClientAuthType
+	switch c.ClientAuth {
+	case "", ClientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf(
+			"tls client_auth must be %s or %s, got %q", ClientAuthRequire, ClientAuthOptional, c.ClientAuth,
+		)
+	}
+	if c.ClientCAFile == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tls client CA: %w", err)
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("tls client CA %s holds no PEM certificates", c.ClientCAFile)
+	}
+	config.ClientAuth = clientAuth
+	return config, nil
+}
+
+// certificate caches the key pair and loads it again once either file was modified.
+// When the new files cannot be loaded the previous certificate stays in use.
+type certificate struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func (c *certificate) get() (*tls.Certificate, error) {
+	modified, err := latestModTime(c.certFile, c.keyFile)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cert != nil && (err != nil || !modified.After(c.modified)) {
+		return c.cert, nil
+	}
+
+	cert, loadErr := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if loadErr != nil {
+		if c.cert != nil {
+			// Retried once the files change again rather than on every handshake
+			c.modified = modified
+			return c.cert, nil
+		}
+		return nil, fmt.Errorf("error loading tls certificate: %w", loadErr)
+	}
+	c.cert, c.modified = &cert, modified
+	return c.cert, nil
+}
+
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
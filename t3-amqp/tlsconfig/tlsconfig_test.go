@@ -0,0 +1,225 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// issue creates a certificate for name signed by parent, self-signed when parent is nil
+func issue(
+	t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func writePair(t *testing.T, dir, name string, certPEM []byte, key *ecdsa.PrivateKey) (string, string) {
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	assert.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	return certFile, keyFile
+}
+
+// testPKI holds a CA, a server certificate and a client certificate it signed
+type testPKI struct {
+	dir                       string
+	ca                        *x509.Certificate
+	caKey                     *ecdsa.PrivateKey
+	caFile, certFile, keyFile string
+	client                    tls.Certificate
+	otherClient               tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	p := &testPKI{dir: t.TempDir()}
+	var caPEM []byte
+	p.ca, p.caKey, caPEM = issue(t, "t3 test CA", nil, nil)
+	p.caFile = filepath.Join(p.dir, "ca.crt")
+	assert.NoError(t, os.WriteFile(p.caFile, caPEM, 0o600))
+
+	_, serverKey, serverPEM := issue(t, "localhost", p.ca, p.caKey)
+	p.certFile, p.keyFile = writePair(t, p.dir, "server", serverPEM, serverKey)
+
+	_, clientKey, clientPEM := issue(t, "client", p.ca, p.caKey)
+	clientCert, clientKeyFile := writePair(t, p.dir, "client", clientPEM, clientKey)
+	client, err := tls.LoadX509KeyPair(clientCert, clientKeyFile)
+	assert.NoError(t, err)
+	p.client = client
+
+	// A client certificate from a CA the server does not trust
+	otherCA, otherKey, _ := issue(t, "other CA", nil, nil)
+	_, strangerKey, strangerPEM := issue(t, "stranger", otherCA, otherKey)
+	strangerCert, strangerKeyFile := writePair(t, p.dir, "stranger", strangerPEM, strangerKey)
+	p.otherClient, err = tls.LoadX509KeyPair(strangerCert, strangerKeyFile)
+	assert.NoError(t, err)
+	return p
+}
+
+// serve starts an HTTPS server with config and returns its URL. The listener is wrapped
+// directly as StartTLS would put its own certificate in front of config's.
+func serve(t *testing.T, config *tls.Config) string {
+	server := httptest.NewUnstartedServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	server.Listener = tls.NewListener(server.Listener, config)
+	server.Start()
+	t.Cleanup(server.Close)
+	return "https://" + server.Listener.Addr().String()
+}
+
+// get requests url presenting cert when given, even when the server does not list its CA
+func (p *testPKI) get(url string, cert ...tls.Certificate) (*http.Response, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(p.ca)
+	config := &tls.Config{RootCAs: roots}
+	if len(cert) > 0 {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &cert[0], nil
+		}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(url)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestServer(t *testing.T) {
+	p := newTestPKI(t)
+
+	config, err := Config{CertFile: p.certFile, KeyFile: p.keyFile}.Server()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+	url := serve(t, config)
+	resp, err := p.get(url)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	invalid := map[string]Config{
+		"no key":      {CertFile: p.certFile},
+		"no cert":     {KeyFile: p.keyFile},
+		"missing":     {CertFile: filepath.Join(p.dir, "none.crt"), KeyFile: p.keyFile},
+		"client auth": {CertFile: p.certFile, KeyFile: p.keyFile, ClientCAFile: p.caFile, ClientAuth: "sometimes"},
+		"ca missing":  {CertFile: p.certFile, KeyFile: p.keyFile, ClientCAFile: filepath.Join(p.dir, "none.crt")},
+		"ca not pem":  {CertFile: p.certFile, KeyFile: p.keyFile, ClientCAFile: p.keyFile},
+	}
+	for name, c := range invalid {
+		_, err := c.Server()
+		assert.Error(t, err, name)
+	}
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	p := newTestPKI(t)
+
+	config, err := Config{CertFile: p.certFile, KeyFile: p.keyFile, ClientCAFile: p.caFile}.Server()
+	if !assert.NoError(t, err) {
+		return
+	}
+	url := serve(t, config)
+	resp, err := p.get(url, p.client)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	_, err = p.get(url)
+	assert.Error(t, err)
+	_, err = p.get(url, p.otherClient)
+	assert.Error(t, err)
+
+	// Optional client auth lets clients without a certificate in but still checks the others
+	config, err = Config{
+		CertFile: p.certFile, KeyFile: p.keyFile, ClientCAFile: p.caFile, ClientAuth: ClientAuthOptional,
+	}.Server()
+	if !assert.NoError(t, err) {
+		return
+	}
+	url = serve(t, config)
+	resp, err = p.get(url)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	_, err = p.get(url, p.client)
+	assert.NoError(t, err)
+	_, err = p.get(url, p.otherClient)
+	assert.Error(t, err)
+}
+
+func TestServerReloadsCertificate(t *testing.T) {
+	p := newTestPKI(t)
+	config, err := Config{CertFile: p.certFile, KeyFile: p.keyFile}.Server()
+	if !assert.NoError(t, err) {
+		return
+	}
+	first, err := config.GetCertificate(nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	renewed, key, renewedPEM := issue(t, "localhost", p.ca, p.caKey)
+	writePair(t, p.dir, "server", renewedPEM, key)
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(p.certFile, later, later))
+	assert.NoError(t, os.Chtimes(p.keyFile, later, later))
+	got, err := config.GetCertificate(nil)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, first.Certificate[0], got.Certificate[0])
+		assert.Equal(t, renewed.Raw, got.Certificate[0])
+	}
+
+	// A broken renewal keeps the last good certificate
+	assert.NoError(t, os.WriteFile(p.keyFile, []byte("garbage"), 0o600))
+	later = later.Add(time.Minute)
+	assert.NoError(t, os.Chtimes(p.keyFile, later, later))
+	got, err = config.GetCertificate(nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, renewed.Raw, got.Certificate[0])
+	}
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"t3-amqp/db"
+	"time"
+)
+
+// Config selects where spans are exported to
+type Config struct {
+	// Endpoint is the base URL of an OTLP/HTTP collector, spans are posted to
+	// <endpoint>/v1/traces. Tracing is off without one.
+	Endpoint string `mapstructure:"endpoint"`
+	// Headers are sent with every export, for collectors that want an API key
+	Headers     map[string]string `mapstructure:"headers"`
+	ServiceName string            `mapstructure:"service_name"`
+	// SampleRatio is the share of new traces recorded, traces started upstream follow
+	// the sampling decision in their traceparent
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	// Timeout bounds a single export
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// LoadConfig reads the tracing section of the already loaded configuration, TRACING_*
+// environment variables override the file
+func LoadConfig() (*Config, error) {
+	if err := db.BindEnv("tracing", Config{}); err != nil {
+		return nil, err
+	}
+	var settings struct {
+		Tracing Config `mapstructure:"tracing"`
+	}
+	if err := viper.Unmarshal(&settings); err != nil {
+		return nil, fmt.Errorf("unable to decode tracing config: %w", err)
+	}
+	config := settings.Tracing
+	config.applyDefaults()
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.ServiceName == "" {
+		c.ServiceName = "t3-amqp"
+	}
+	if c.SampleRatio <= 0 || c.SampleRatio > 1 {
+		c.SampleRatio = 1
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+}
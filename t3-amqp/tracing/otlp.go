@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Exporter posts spans to an OTLP/HTTP collector using the JSON encoding of the OTLP
+// protocol, which every collector accepts next to protobuf
+type Exporter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu       sync.Mutex
+	shutdown bool
+}
+
+// NewExporter creates an exporter posting to the /v1/traces endpoint of config.Endpoint
+func NewExporter(config Config) *Exporter {
+	return &Exporter{
+		url:     strings.TrimSuffix(config.Endpoint, "/") + "/v1/traces",
+		headers: config.Headers,
+		client:  &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// ExportSpans sends spans in one request, grouped by resource and instrumentation scope
+func (e *Exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	shutdown := e.shutdown
+	e.mu.Unlock()
+	if shutdown || len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(encodeSpans(spans))
+	if err != nil {
+		return fmt.Errorf("error encoding spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error exporting spans: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector answered %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// Shutdown makes later exports no-ops
+func (e *Exporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown = true
+	return nil
+}
+
+// The types below are the JSON mapping of the OTLP ExportTraceServiceRequest. IDs are
+// hex encoded and 64 bit integers are strings, as the mapping asks for.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string     `json:"stringValue,omitempty"`
+		BoolValue   *bool       `json:"boolValue,omitempty"`
+		IntValue    *string     `json:"intValue,omitempty"`
+		DoubleValue *float64    `json:"doubleValue,omitempty"`
+		ArrayValue  *otlpValues `json:"arrayValue,omitempty"`
+	}
+	otlpValues struct {
+		Values []otlpValue `json:"values"`
+	}
+)
+
+func encodeSpans(spans []sdktrace.ReadOnlySpan) otlpRequest {
+	var request otlpRequest
+	resources := map[string]int{}
+	scopes := map[string]int{}
+	for _, span := range spans {
+		resource := span.Resource().Encoded(attribute.DefaultEncoder())
+		r, ok := resources[resource]
+		if !ok {
+			r = len(request.ResourceSpans)
+			resources[resource] = r
+			request.ResourceSpans = append(
+				request.ResourceSpans, otlpResourceSpans{
+					Resource: otlpResource{Attributes: encodeAttributes(span.Resource().Attributes())},
+				},
+			)
+		}
+
+		scope := span.InstrumentationScope()
+		key := resource + "\x00" + scope.Name + "\x00" + scope.Version
+		s, ok := scopes[key]
+		if !ok {
+			s = len(request.ResourceSpans[r].ScopeSpans)
+			scopes[key] = s
+			request.ResourceSpans[r].ScopeSpans = append(
+				request.ResourceSpans[r].ScopeSpans, otlpScopeSpans{
+					Scope: otlpScope{Name: scope.Name, Version: scope.Version},
+				},
+			)
+		}
+		scopeSpans := &request.ResourceSpans[r].ScopeSpans[s]
+		scopeSpans.Spans = append(scopeSpans.Spans, encodeSpan(span))
+	}
+	return request
+}
+
+func encodeSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	encoded := otlpSpan{
+		TraceID:           span.SpanContext().TraceID().String(),
+		SpanID:            span.SpanContext().SpanID().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+		Attributes:        encodeAttributes(span.Attributes()),
+	}
+	if span.Parent().IsValid() {
+		encoded.ParentSpanID = span.Parent().SpanID().String()
+	}
+	for _, event := range span.Events() {
+		encoded.Events = append(
+			encoded.Events, otlpEvent{
+				TimeUnixNano: strconv.FormatInt(event.Time.UnixNano(), 10),
+				Name:         event.Name,
+				Attributes:   encodeAttributes(event.Attributes),
+			},
+		)
+	}
+	// OTLP numbers the status codes unset, ok, error while the API has unset, error, ok
+	switch span.Status().Code {
+	case codes.Ok:
+		encoded.Status.Code = 1
+	case codes.Error:
+		encoded.Status = otlpStatus{Code: 2, Message: span.Status().Description}
+	}
+	return encoded
+}
+
+func encodeAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		encoded = append(encoded, otlpKeyValue{Key: string(kv.Key), Value: encodeValue(kv.Value)})
+	}
+	return encoded
+}
+
+func encodeValue(v attribute.Value) otlpValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return otlpValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return otlpValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return otlpValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		return arrayValue(v.AsBoolSlice(), attribute.BoolValue)
+	case attribute.INT64SLICE:
+		return arrayValue(v.AsInt64Slice(), attribute.Int64Value)
+	case attribute.FLOAT64SLICE:
+		return arrayValue(v.AsFloat64Slice(), attribute.Float64Value)
+	case attribute.STRINGSLICE:
+		return arrayValue(v.AsStringSlice(), attribute.StringValue)
+	default:
+		s := v.Emit()
+		return otlpValue{StringValue: &s}
+	}
+}
+
+func arrayValue[T any](items []T, value func(T) attribute.Value) otlpValue {
+	values := make([]otlpValue, len(items))
+	for i, item := range items {
+		values[i] = encodeValue(value(item))
+	}
+	return otlpValue{ArrayValue: &otlpValues{Values: values}}
+}
@@ -0,0 +1,100 @@
+// Package tracing sets up OpenTelemetry tracing and carries trace context through HTTP
+// requests, database queries and broker messages, so one test message can be followed
+// from the request publishing it to the verification consuming it.
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scope names the instrumentation in exported spans
+const scope = "t3-amqp"
+
+// Setup installs the global tracer provider exporting to config.Endpoint and the W3C
+// trace context propagator. Without an endpoint spans are not recorded, but incoming
+// trace context is still passed on to brokers. The returned function flushes pending
+// spans and must be called on shutdown.
+func Setup(config Config) func(context.Context) error {
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	)
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(NewExporter(config)),
+		sdktrace.WithResource(
+			resource.NewSchemaless(semconv.ServiceName(config.ServiceName)),
+		),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown
+}
+
+// Tracer returns the tracer of the registry from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(scope)
+}
+
+// Inject writes the trace context of ctx into headers
+func Inject(ctx context.Context, headers map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// Extract returns ctx continuing the trace context found in headers
+func Extract(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// ExtractHTTP returns ctx continuing the trace context of the traceparent header
+func ExtractHTTP(ctx context.Context, header map[string][]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// RecordError marks span as failed with err, nil errors leave it alone
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// StartPublish starts a producer span for a message sent to topic through system. The
+// caller writes the trace context of the returned ctx into the message headers with
+// Inject, so whoever consumes the message continues the trace.
+func StartPublish(ctx context.Context, system, topic string) (context.Context, trace.Span) {
+	return Tracer().Start(
+		ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(system, topic, semconv.MessagingOperationTypePublish)...),
+	)
+}
+
+// StartReceive starts a consumer span for a message received from topic through system,
+// continuing the trace its headers carry
+func StartReceive(ctx context.Context, system, topic string, headers map[string]string) (context.Context, trace.Span) {
+	return Tracer().Start(
+		Extract(ctx, headers), "receive "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes(system, topic, semconv.MessagingOperationTypeReceive)...),
+	)
+}
+
+func messagingAttributes(system, topic string, operation attribute.KeyValue) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.MessagingDestinationName(topic), operation}
+	if system != "" {
+		attrs = append(attrs, semconv.MessagingSystemKey.String(system))
+	}
+	return attrs
+}
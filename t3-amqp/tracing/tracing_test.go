@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestExporter(t *testing.T) {
+	var requests []otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		var request otlpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+	}))
+	defer collector.Close()
+
+	exporter := NewExporter(Config{Endpoint: collector.URL + "/", Headers: map[string]string{"Authorization": "secret"}})
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	Setup(Config{})
+
+	ctx, publish := StartPublish(context.Background(), "rabbitmq", "orders.created")
+	headers := map[string]string{}
+	Inject(ctx, headers)
+	publish.End()
+	assert.Contains(t, headers, "traceparent")
+
+	_, receive := StartReceive(context.Background(), "rabbitmq", "orders.created", headers)
+	RecordError(receive, errors.New("payload does not validate"))
+	receive.End()
+
+	if !assert.Len(t, requests, 2) {
+		return
+	}
+	spans := make([]otlpSpan, 0, 2)
+	for _, request := range requests {
+		if assert.Len(t, request.ResourceSpans, 1) && assert.Len(t, request.ResourceSpans[0].ScopeSpans, 1) {
+			spans = append(spans, request.ResourceSpans[0].ScopeSpans[0].Spans...)
+		}
+	}
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	assert.Equal(t, "publish orders.created", spans[0].Name)
+	assert.Equal(t, "receive orders.created", spans[1].Name)
+	assert.Len(t, spans[0].TraceID, 32)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID, "the consumer continues the producer's trace")
+	assert.Equal(t, spans[0].SpanID, spans[1].ParentSpanID)
+	assert.Equal(t, 2, spans[1].Status.Code)
+	assert.Equal(t, "payload does not validate", spans[1].Status.Message)
+
+	assert.NoError(t, exporter.Shutdown(context.Background()))
+	_, span := StartPublish(context.Background(), "rabbitmq", "orders.created")
+	span.End()
+	assert.Len(t, requests, 2, "a shut down exporter drops spans")
+}
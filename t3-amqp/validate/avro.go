@@ -0,0 +1,54 @@
+package validate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/linkedin/goavro/v2"
+	"strings"
+)
+
+// AvroCanonical parses schemaData according to the Avro specification and returns its
+// Parsing Canonical Form together with the hex SHA-256 fingerprint of that form
+func AvroCanonical(schemaData string) (canonical, fingerprint string, err error) {
+	codec, err := goavro.NewCodec(schemaData)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing avro schema: %w", err)
+	}
+	canonical = codec.CanonicalSchema()
+	sum := sha256.Sum256([]byte(canonical))
+	return canonical, hex.EncodeToString(sum[:]), nil
+}
+
+// SchemaFingerprint returns the hex SHA-256 fingerprint stored with schema_data, see
+// SchemaFingerprints
+func SchemaFingerprint(schemaType, schemaData string) (string, error) {
+	fingerprint, _, err := SchemaFingerprints(schemaType, schemaData)
+	return fingerprint, err
+}
+
+// SchemaFingerprints returns the fingerprints stored with schema_data: the hex SHA-256
+// of its canonical form, of the data without surrounding whitespace for types that have
+// none, and for Avro schemas the hex CRC-64-AVRO Rabin fingerprint of their Parsing
+// Canonical Form, empty for other types
+func SchemaFingerprints(schemaType, schemaData string) (fingerprint, rabin string, err error) {
+	if schemaType == "avro" {
+		codec, err := goavro.NewCodec(schemaData)
+		if err != nil {
+			return "", "", fmt.Errorf("error parsing avro schema: %w", err)
+		}
+		sum := sha256.Sum256([]byte(codec.CanonicalSchema()))
+		return hex.EncodeToString(sum[:]), fmt.Sprintf("%016x", codec.Rabin), nil
+	}
+
+	canonical, err := Canonical(schemaType, schemaData)
+	if errors.Is(err, ErrUnsupportedType) {
+		canonical, err = strings.TrimSpace(schemaData), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:]), "", nil
+}
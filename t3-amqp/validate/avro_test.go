@@ -0,0 +1,50 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvroCanonicalIgnoresFormatting(t *testing.T) {
+	compact := `{"type":"record","name":"Order","namespace":"t3","fields":[{"name":"id","type":"long"}]}`
+	reordered := `{
+		"fields": [ {"type": "long", "name": "id", "doc": "order number"} ],
+		"doc": "an order",
+		"name": "Order",
+		"namespace": "t3",
+		"type": "record"
+	}`
+
+	canonical, fp1, err := AvroCanonical(compact)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"t3.Order","type":"record","fields":[{"name":"id","type":"long"}]}`, canonical)
+
+	_, fp2, err := AvroCanonical(reordered)
+	assert.NoError(t, err)
+	assert.Equal(t, fp1, fp2)
+	assert.Len(t, fp1, 64)
+
+	_, fp3, err := AvroCanonical(`{"type":"record","name":"Order","namespace":"t3","fields":[{"name":"id","type":"int"}]}`)
+	assert.NoError(t, err)
+	assert.NotEqual(t, fp1, fp3)
+
+	fp, rabin, err := SchemaFingerprints("avro", compact)
+	assert.NoError(t, err)
+	assert.Equal(t, fp1, fp)
+	assert.Len(t, rabin, 16)
+
+	fp, rabin, err = SchemaFingerprints("json", `{"type":"object", "title": "Order"}`)
+	assert.NoError(t, err)
+	assert.Len(t, fp, 64)
+	assert.Empty(t, rabin)
+	same, err := SchemaFingerprint("json", `{"title":"Order","type":"object"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, fp, same, "json fingerprints ignore formatting and key order")
+
+	fp, err = SchemaFingerprint("xsd", " <xs:schema/>\n")
+	assert.NoError(t, err)
+	other, err := SchemaFingerprint("xsd", "<xs:schema/>")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, other)
+}
@@ -0,0 +1,155 @@
+package validate
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"golang.org/x/sync/singleflight"
+	"strings"
+	"sync"
+	"t3-amqp/db"
+)
+
+// DefaultCacheSize bounds the number of compiled validators held by DefaultCache
+const DefaultCacheSize = 1024
+
+// DefaultCache is shared by the REST and consumer validation paths
+var DefaultCache = NewCache(DefaultCacheSize)
+
+// Fingerprint identifies schema content independent of where it is stored
+func Fingerprint(schemaType, schemaData string) string {
+	sum := sha256.Sum256([]byte(schemaType + "\x00" + schemaData))
+	return hex.EncodeToString(sum[:])
+}
+
+type cacheEntry struct {
+	fingerprint string
+	validator   Validator
+}
+
+// cacheKey is the fingerprint schema is cached by. Validators of schemas with t3://
+// references embed the referenced schemas of their tenant, so the tenant is part of it.
+func cacheKey(schema db.Schema) string {
+	if !strings.Contains(schema.SchemaData, db.RefScheme) {
+		return Fingerprint(schema.Type, schema.SchemaData)
+	}
+	return Fingerprint(schema.Type, schema.Tenant+"\x00"+schema.SchemaData)
+}
+
+// Cache is a bounded LRU of compiled validators keyed by schema fingerprint. Concurrent
+// misses on the same fingerprint share one compile.
+type Cache struct {
+	size    int
+	compile func(schemaType, schemaData string, resolve Resolver) (Validator, error)
+	flight  singleflight.Group
+
+	mu      sync.Mutex
+	store   db.SchemaStore
+	order   *list.List
+	entries map[string]*list.Element
+	byID    map[int]string
+}
+
+// NewCache creates a validator cache holding at most size validators
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{
+		size:    size,
+		compile: CompileWith,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+		byID:    map[int]string{},
+	}
+}
+
+// UseStore resolves the t3:// references of the schemas compiled from now on to the
+// schemas of store in the tenant of the referencing schema
+func (c *Cache) UseStore(store db.SchemaStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = store
+}
+
+// Validator returns the compiled validator for schema, compiling it on a miss
+func (c *Cache) Validator(schema db.Schema) (Validator, error) {
+	fp := cacheKey(schema)
+
+	c.mu.Lock()
+	if el, ok := c.entries[fp]; ok {
+		c.order.MoveToFront(el)
+		c.byID[schema.ID] = fp
+		c.mu.Unlock()
+		return el.Value.(*cacheEntry).validator, nil
+	}
+	var resolve Resolver
+	if c.store != nil {
+		resolve = StoreResolver(db.ForTenant(c.store, schema.Tenant))
+	}
+	c.mu.Unlock()
+
+	// Compile outside the lock, callers missing on the same schema meanwhile wait for
+	// this compile instead of starting their own
+	compiled, err, _ := c.flight.Do(fp, func() (interface{}, error) {
+		return c.compile(schema.Type, schema.SchemaData, resolve)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v := compiled.(Validator)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[fp]; ok {
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[fp] = c.order.PushFront(&cacheEntry{fingerprint: fp, validator: v})
+		for c.order.Len() > c.size {
+			c.removeLocked(c.order.Back())
+		}
+	}
+	if schema.ID != 0 {
+		c.byID[schema.ID] = fp
+	}
+	return v, nil
+}
+
+// Invalidate drops the validator compiled from the given fingerprint
+func (c *Cache) Invalidate(fingerprint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[fingerprint]; ok {
+		c.removeLocked(el)
+	}
+}
+
+// InvalidateID drops the validator last used for the schema with the given id, called
+// when that schema is updated or deleted
+func (c *Cache) InvalidateID(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if fp, ok := c.byID[id]; ok {
+		delete(c.byID, id)
+		if el, ok := c.entries[fp]; ok {
+			c.removeLocked(el)
+		}
+	}
+}
+
+// Len returns the number of cached validators
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) removeLocked(el *list.Element) {
+	entry := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, entry.fingerprint)
+	for id, fp := range c.byID {
+		if fp == entry.fingerprint {
+			delete(c.byID, id)
+		}
+	}
+}
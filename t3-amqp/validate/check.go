@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"strings"
+)
+
+// jsonDrafts are the JSON Schema drafts accepted for stored schemas, keyed by $schema
+// without scheme or trailing fragment
+var jsonDrafts = map[string]bool{
+	"json-schema.org/draft-07/schema":      true,
+	"json-schema.org/draft/2019-09/schema": true,
+	"json-schema.org/draft/2020-12/schema": true,
+	"json-schema.org/schema":               true,
+}
+
+// SchemaError lists the problems that make schema_data unusable
+type SchemaError struct {
+	Type     string
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("invalid %s schema: %s", e.Type, strings.Join(e.Problems, "; "))
+}
+
+// CheckSchema validates schema_data before it is stored. JSON schemas must be draft-07
+// or later, defaulting to draft-07 without $schema, and valid against their metaschema.
+// Their t3:// references must resolve through resolve to registered JSON schemas. Avro
+// schemas must follow the Avro specification. Other types are accepted as is.
+func CheckSchema(schemaType, schemaData string, resolve Resolver) error {
+	switch schemaType {
+	case "json":
+	case "avro":
+		if _, _, err := AvroCanonical(schemaData); err != nil {
+			return &SchemaError{Type: schemaType, Problems: []string{err.Error()}}
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(schemaData))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return &SchemaError{Type: schemaType, Problems: []string{"schema is not valid json: " + err.Error()}}
+	}
+	switch d := doc.(type) {
+	case bool:
+	case map[string]interface{}:
+		if draft, ok := d["$schema"].(string); ok && !jsonDrafts[normalizeDraft(draft)] {
+			return &SchemaError{
+				Type: schemaType, Problems: []string{fmt.Sprintf("unsupported $schema %q, draft-07 or later is required", draft)},
+			}
+		}
+	default:
+		return &SchemaError{Type: schemaType, Problems: []string{"schema must be an object or a boolean"}}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	compiler.LoadURL = loadURL(resolve)
+	if err := compiler.AddResource("schema.json", bytes.NewReader([]byte(schemaData))); err != nil {
+		return &SchemaError{Type: schemaType, Problems: []string{err.Error()}}
+	}
+	if _, err := compiler.Compile("schema.json"); err != nil {
+		var problems []string
+		for _, p := range Problems(err) {
+			problems = append(problems, p.String())
+		}
+		return &SchemaError{Type: schemaType, Problems: problems}
+	}
+	return nil
+}
+
+func normalizeDraft(url string) string {
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		url = url[:i]
+	}
+	return url
+}
@@ -0,0 +1,41 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		schemaType string
+		data       string
+		problems   int
+	}{
+		{"draft-07 default", "json", `{"type":"object","properties":{"id":{"type":"integer"}}}`, 0},
+		{"explicit draft-07", "json", `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object"}`, 0},
+		{"draft 2020-12", "json", `{"$schema":"https://json-schema.org/draft/2020-12/schema"}`, 0},
+		{"boolean schema", "json", `true`, 0},
+		{"draft-04", "json", `{"$schema":"http://json-schema.org/draft-04/schema#"}`, 1},
+		{"not json", "json", `type: object`, 1},
+		{"not a schema", "json", `[1, 2]`, 1},
+		{"bad keywords", "json", `{"properties":{"id":{"type":5}},"required":"id"}`, 3},
+		{"avro record", "avro", `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`, 0},
+		{"invalid avro", "avro", `{"type":"record","name":"Order"}`, 1},
+		{"xsd is not checked", "xsd", `not xsd`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSchema(tt.schemaType, tt.data, nil)
+			if tt.problems == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var schemaErr *SchemaError
+			assert.True(t, errors.As(err, &schemaErr))
+			assert.Len(t, schemaErr.Problems, tt.problems)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package validate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/linkedin/goavro/v2"
+)
+
+// Framings of binary payloads. FramingConfluent prefixes the payload with a zero magic
+// byte and the big endian schema ID, the wire format of Confluent serializers.
+const (
+	FramingNone      = ""
+	FramingConfluent = "confluent"
+)
+
+var ErrInvalidFrame = errors.New("payload is not in the confluent wire format")
+
+// Codec converts payloads between their JSON form and the binary encoding of a schema
+type Codec interface {
+	Encode(payload []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// NewCodec builds the binary codec of schema_data of the given schema type. Avro schemas
+// encode to Avro binary, other types have no binary encoding the registry can produce.
+// Protobuf is among them: without a .proto parser there are no message descriptors to
+// encode with.
+func NewCodec(schemaType, schemaData string) (Codec, error) {
+	switch schemaType {
+	case "avro":
+		codec, err := goavro.NewCodec(schemaData)
+		if err != nil {
+			return nil, fmt.Errorf("error compiling avro schema: %w", err)
+		}
+		return &avroCodec{codec: codec}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s schemas have no binary codec", ErrUnsupportedType, schemaType)
+	}
+}
+
+type avroCodec struct {
+	codec *goavro.Codec
+}
+
+// Encode converts the Avro JSON encoding of a record to Avro binary
+func (c *avroCodec) Encode(payload []byte) ([]byte, error) {
+	native, _, err := c.codec.NativeFromTextual(payload)
+	if err != nil {
+		return nil, fmt.Errorf("payload does not match avro schema: %w", err)
+	}
+	data, err := c.codec.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("payload does not match avro schema: %w", err)
+	}
+	return data, nil
+}
+
+// Decode converts Avro binary to the Avro JSON encoding, trailing bytes are an error
+func (c *avroCodec) Decode(data []byte) ([]byte, error) {
+	native, rest, err := c.codec.NativeFromBinary(data)
+	if err != nil {
+		return nil, fmt.Errorf("data does not match avro schema: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("data does not match avro schema: %d trailing bytes", len(rest))
+	}
+	payload, err := c.codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding avro record as json: %w", err)
+	}
+	return payload, nil
+}
+
+// Frame wraps data encoded with the schema with id in framing
+func Frame(framing string, id int, data []byte) ([]byte, error) {
+	switch framing {
+	case FramingNone:
+		return data, nil
+	case FramingConfluent:
+		framed := make([]byte, 5, 5+len(data))
+		binary.BigEndian.PutUint32(framed[1:], uint32(id))
+		return append(framed, data...), nil
+	default:
+		return nil, fmt.Errorf("unknown framing %q, use %s or none", framing, FramingConfluent)
+	}
+}
+
+// Unframe strips framing from data, returning the schema ID the frame names or 0
+func Unframe(framing string, data []byte) (id int, payload []byte, err error) {
+	switch framing {
+	case FramingNone:
+		return 0, data, nil
+	case FramingConfluent:
+		if len(data) < 5 || data[0] != 0 {
+			return 0, nil, ErrInvalidFrame
+		}
+		return int(binary.BigEndian.Uint32(data[1:5])), data[5:], nil
+	default:
+		return 0, nil, fmt.Errorf("unknown framing %q, use %s or none", framing, FramingConfluent)
+	}
+}
@@ -0,0 +1,52 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderAvro = `{"type":"record","name":"Order","fields":[
+	{"name":"id","type":"long"},
+	{"name":"note","type":["null","string"]}
+]}`
+
+func TestAvroCodecRoundTrip(t *testing.T) {
+	codec, err := NewCodec("avro", orderAvro)
+	if !assert.NoError(t, err) {
+		return
+	}
+	data, err := codec.Encode([]byte(`{"id":3,"note":{"string":"x"}}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []byte{6, 2, 2, 'x'}, data, "zigzag long, union branch 1 and the string")
+
+	payload, err := codec.Decode(data)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":3,"note":{"string":"x"}}`, string(payload))
+
+	_, err = codec.Encode([]byte(`{"id":"x"}`))
+	assert.Error(t, err)
+	_, err = codec.Decode(append(data, 0))
+	assert.ErrorContains(t, err, "trailing")
+
+	_, err = NewCodec("xsd", `<xs:schema/>`)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestConfluentFraming(t *testing.T) {
+	framed, err := Frame(FramingConfluent, 258, []byte{6})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 1, 2, 6}, framed)
+
+	id, data, err := Unframe(FramingConfluent, framed)
+	assert.NoError(t, err)
+	assert.Equal(t, 258, id)
+	assert.Equal(t, []byte{6}, data)
+
+	_, _, err = Unframe(FramingConfluent, []byte{1, 0, 0, 0, 1})
+	assert.ErrorIs(t, err, ErrInvalidFrame)
+	_, err = Frame("snappy", 1, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formats schema_data can be answered in
+const (
+	FormatPretty    = "pretty"
+	FormatCanonical = "canonical"
+)
+
+// Format returns schema_data in format, pretty or canonical. Only JSON and Avro schemas
+// can be formatted, other types fail with ErrUnsupportedType.
+func Format(format, schemaType, schemaData string) (string, error) {
+	switch format {
+	case FormatPretty:
+		return Pretty(schemaType, schemaData)
+	case FormatCanonical:
+		return Canonical(schemaType, schemaData)
+	default:
+		return "", fmt.Errorf("unknown format %q, use %s or %s", format, FormatPretty, FormatCanonical)
+	}
+}
+
+// Pretty indents schema_data by two spaces, keeping the order of its keys
+func Pretty(schemaType, schemaData string) (string, error) {
+	if schemaType != "json" && schemaType != "avro" {
+		return "", fmt.Errorf("%w: %s schemas cannot be formatted", ErrUnsupportedType, schemaType)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(strings.TrimSpace(schemaData)), "", "  "); err != nil {
+		return "", &SchemaError{Type: schemaType, Problems: []string{"schema is not valid json: " + err.Error()}}
+	}
+	return out.String(), nil
+}
+
+// Canonical returns the form two equivalent schemas share whatever their whitespace and
+// key order: the Parsing Canonical Form of Avro schemas and compact JSON with sorted
+// keys for JSON schemas
+func Canonical(schemaType, schemaData string) (string, error) {
+	switch schemaType {
+	case "avro":
+		canonical, _, err := AvroCanonical(schemaData)
+		if err != nil {
+			return "", &SchemaError{Type: schemaType, Problems: []string{err.Error()}}
+		}
+		return canonical, nil
+	case "json":
+		var doc interface{}
+		decoder := json.NewDecoder(strings.NewReader(schemaData))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return "", &SchemaError{Type: schemaType, Problems: []string{"schema is not valid json: " + err.Error()}}
+		}
+		// Maps are encoded with sorted keys
+		var out bytes.Buffer
+		encoder := json.NewEncoder(&out)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(doc); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(out.String(), "\n"), nil
+	default:
+		return "", fmt.Errorf("%w: %s schemas have no canonical form", ErrUnsupportedType, schemaType)
+	}
+}
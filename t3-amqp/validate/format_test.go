@@ -0,0 +1,55 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	spaced := `{ "type": "object",
+	"properties": {"name": {"type": "string", "maxLength": 10}, "id": {"type": "integer", "description": "<id>"}} }`
+	reordered := `{"properties":{"id":{"description":"<id>","type":"integer"},` +
+		`"name":{"maxLength":10,"type":"string"}},"type":"object"}`
+
+	pretty, err := Format(FormatPretty, "json", spaced)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "type": "object",
+  "properties": {
+    "name": {
+      "type": "string",
+      "maxLength": 10
+    },
+    "id": {
+      "type": "integer",
+      "description": "<id>"
+    }
+  }
+}`, pretty)
+
+	canonical, err := Format(FormatCanonical, "json", spaced)
+	assert.NoError(t, err)
+	assert.Equal(t, reordered, canonical, "keys are sorted and HTML is not escaped")
+	again, err := Canonical("json", pretty)
+	assert.NoError(t, err)
+	assert.Equal(t, canonical, again)
+
+	canonical, err = Canonical("avro", `{"type": "record", "name": "Order", "fields": [{"name": "id", "type": "long"}]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Order","type":"record","fields":[{"name":"id","type":"long"}]}`, canonical)
+
+	_, err = Format(FormatPretty, "xsd", `<xs:schema/>`)
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+	_, err = Canonical("xsd", `<xs:schema/>`)
+	assert.True(t, errors.Is(err, ErrUnsupportedType))
+	_, err = Format("compact", "json", spaced)
+	assert.Error(t, err)
+
+	_, err = Pretty("json", `{"type":`)
+	var schemaErr *SchemaError
+	assert.True(t, errors.As(err, &schemaErr))
+	_, err = Canonical("avro", `{"type":"record"}`)
+	assert.True(t, errors.As(err, &schemaErr))
+}
@@ -0,0 +1,98 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrOverloaded is returned when the pool's queue is full
+	ErrOverloaded = errors.New("validation queue is full")
+	// ErrPoolClosed is returned once the pool has been shut down
+	ErrPoolClosed = errors.New("validation pool is closed")
+)
+
+type job struct {
+	ctx       context.Context
+	validator Validator
+	payload   []byte
+	result    chan error
+}
+
+// Pool runs validations on a fixed number of workers fed by a bounded queue, shedding
+// work instead of growing without limit when validations arrive faster than they finish
+type Pool struct {
+	jobs chan job
+	wg   sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPool starts workers goroutines with room for queueSize waiting validations
+func NewPool(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool{jobs: make(chan job, queueSize)}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for j := range p.jobs {
+		if err := j.ctx.Err(); err != nil {
+			j.result <- err
+			continue
+		}
+		j.result <- j.validator.Validate(j.payload)
+	}
+}
+
+// Validate queues payload for validation and waits for the result. It returns
+// ErrOverloaded immediately when the queue is full.
+func (p *Pool) Validate(ctx context.Context, v Validator, payload []byte) error {
+	j := job{ctx: ctx, validator: v, payload: payload, result: make(chan error, 1)}
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrPoolClosed
+	}
+	select {
+	case p.jobs <- j:
+		p.mu.RUnlock()
+	default:
+		p.mu.RUnlock()
+		return ErrOverloaded
+	}
+
+	select {
+	case err := <-j.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting work and waits for queued validations to finish
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
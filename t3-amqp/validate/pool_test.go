@@ -0,0 +1,61 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingValidator struct {
+	release chan struct{}
+}
+
+func (v blockingValidator) Validate(payload []byte) error {
+	<-v.release
+	if string(payload) == "bad" {
+		return errors.New("invalid")
+	}
+	return nil
+}
+
+func TestPoolValidates(t *testing.T) {
+	p := NewPool(2, 2)
+	defer p.Close()
+
+	v := blockingValidator{release: make(chan struct{})}
+	close(v.release)
+
+	assert.NoError(t, p.Validate(context.Background(), v, []byte("good")))
+	assert.EqualError(t, p.Validate(context.Background(), v, []byte("bad")), "invalid")
+}
+
+func TestPoolShedsWhenFull(t *testing.T) {
+	p := NewPool(1, 1)
+	v := blockingValidator{release: make(chan struct{})}
+
+	// At most one validation occupies the worker and one waits in the queue, the rest are
+	// shed immediately while the accepted ones block until released
+	results := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() { results <- p.Validate(context.Background(), v, []byte("good")) }()
+	}
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, <-results, ErrOverloaded)
+	}
+
+	close(v.release)
+	accepted := 0
+	for i := 0; i < 2; i++ {
+		if err := <-results; err == nil {
+			accepted++
+		} else {
+			assert.ErrorIs(t, err, ErrOverloaded)
+		}
+	}
+	assert.GreaterOrEqual(t, accepted, 1)
+
+	p.Close()
+	assert.ErrorIs(t, p.Validate(context.Background(), v, nil), ErrPoolClosed)
+}
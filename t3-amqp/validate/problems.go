@@ -0,0 +1,50 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Problem is one reason a payload or schema failed validation
+type Problem struct {
+	// Pointer is the JSON pointer of the failing value, / for the document itself. It
+	// is empty for errors without a location, such as Avro and XSD ones.
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Pointer == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("at %s: %s", p.Pointer, p.Message)
+}
+
+// Problems breaks a validation error into one problem per failed JSON schema keyword,
+// other errors become a single problem without a pointer
+func Problems(err error) []Problem {
+	if err == nil {
+		return nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return []Problem{{Message: err.Error()}}
+	}
+	return leaves(verr, nil)
+}
+
+// leaves collects the causes of verr that have no causes of their own
+func leaves(verr *jsonschema.ValidationError, problems []Problem) []Problem {
+	if len(verr.Causes) == 0 {
+		pointer := verr.InstanceLocation
+		if pointer == "" {
+			pointer = "/"
+		}
+		return append(problems, Problem{Pointer: pointer, Message: verr.Message})
+	}
+	for _, cause := range verr.Causes {
+		problems = leaves(cause, problems)
+	}
+	return problems
+}
@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblems(t *testing.T) {
+	v, err := Compile("json", `{
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"type": "integer"},
+			"items": {"type": "array", "items": {"type": "string"}}
+		}
+	}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Nil(t, Problems(v.Validate([]byte(`{"id": 1, "items": ["a"]}`))))
+
+	problems := Problems(v.Validate([]byte(`{"id": "x", "items": ["a", 2]}`)))
+	assert.ElementsMatch(t, []string{"/id", "/items/1"}, pointers(problems))
+
+	problems = Problems(v.Validate([]byte(`{}`)))
+	if assert.Len(t, problems, 1) {
+		assert.Equal(t, "/", problems[0].Pointer)
+		assert.Contains(t, problems[0].String(), "at /: ")
+	}
+
+	problems = Problems(errors.New("payload does not match avro schema"))
+	assert.Equal(t, []Problem{{Message: "payload does not match avro schema"}}, problems)
+	assert.Equal(t, "payload does not match avro schema", problems[0].String())
+}
+
+func pointers(problems []Problem) []string {
+	var out []string
+	for _, p := range problems {
+		out = append(out, p.Pointer)
+	}
+	return out
+}
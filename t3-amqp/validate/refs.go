@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+	"strings"
+	"t3-amqp/db"
+)
+
+// Resolver returns the registered schema a t3:// reference of a JSON schema points at
+type Resolver func(ref db.SchemaRef) (*db.Schema, error)
+
+// StoreResolver resolves references to the live schemas of store
+func StoreResolver(store db.SchemaStore) Resolver {
+	return func(ref db.SchemaRef) (*db.Schema, error) {
+		return db.ResolveReference(store, ref)
+	}
+}
+
+// loadURL loads the documents a JSON schema refers to, t3:// references through resolve
+// and everything else the way the jsonschema package does. Without a resolver t3://
+// references cannot be loaded.
+func loadURL(resolve Resolver) func(string) (io.ReadCloser, error) {
+	return func(url string) (io.ReadCloser, error) {
+		if !strings.HasPrefix(url, db.RefScheme) {
+			return jsonschema.LoadURL(url)
+		}
+		ref, err := db.ParseSchemaRef(url)
+		if err != nil {
+			return nil, err
+		}
+		if resolve == nil {
+			return nil, fmt.Errorf("%s cannot be resolved without the registry", ref)
+		}
+		if ref.Type != "json" {
+			return nil, fmt.Errorf("%s is not a json schema", ref)
+		}
+		schema, err := resolve(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not registered: %w", ref, err)
+		}
+		return io.NopCloser(strings.NewReader(schema.SchemaData)), nil
+	}
+}
@@ -0,0 +1,97 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/linkedin/goavro/v2"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var ErrUnsupportedType = errors.New("unsupported schema type")
+
+// Validator checks a message payload against a compiled schema
+type Validator interface {
+	Validate(payload []byte) error
+}
+
+// Compile builds a Validator for schema_data of the given schema type. JSON schemas
+// referencing registered schemas need CompileWith.
+func Compile(schemaType, schemaData string) (Validator, error) {
+	return CompileWith(schemaType, schemaData, nil)
+}
+
+// CompileWith is Compile resolving the t3:// references of JSON schemas with resolve
+func CompileWith(schemaType, schemaData string, resolve Resolver) (Validator, error) {
+	switch schemaType {
+	case "json":
+		return compileJSON(schemaData, resolve)
+	case "avro":
+		return compileAvro(schemaData)
+	case "xsd":
+		return compileXSD(schemaData)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, schemaType)
+	}
+}
+
+type jsonValidator struct {
+	schema *jsonschema.Schema
+}
+
+func compileJSON(schemaData string, resolve Resolver) (*jsonValidator, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = loadURL(resolve)
+	if err := compiler.AddResource("schema.json", bytes.NewReader([]byte(schemaData))); err != nil {
+		return nil, fmt.Errorf("error loading json schema: %w", err)
+	}
+	schema, err := compiler.Compile("schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("error compiling json schema: %w", err)
+	}
+	return &jsonValidator{schema: schema}, nil
+}
+
+func (v *jsonValidator) Validate(payload []byte) error {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("payload is not valid json: %w", err)
+	}
+	return v.schema.Validate(doc)
+}
+
+type avroValidator struct {
+	codec *goavro.Codec
+}
+
+func compileAvro(schemaData string) (*avroValidator, error) {
+	codec, err := goavro.NewCodec(schemaData)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling avro schema: %w", err)
+	}
+	return &avroValidator{codec: codec}, nil
+}
+
+// Validate accepts the Avro JSON encoding of a record
+func (v *avroValidator) Validate(payload []byte) error {
+	native, _, err := v.codec.NativeFromTextual(payload)
+	if err != nil {
+		return fmt.Errorf("payload does not match avro schema: %w", err)
+	}
+	if _, err := v.codec.BinaryFromNative(nil, native); err != nil {
+		return fmt.Errorf("payload does not match avro schema: %w", err)
+	}
+	return nil
+}
+
+// ContentType is the media type of payloads for schemaType, used when publishing and
+// checked when consuming
+func ContentType(schemaType string) string {
+	if schemaType == "xsd" {
+		return "application/xml"
+	}
+	return "application/json"
+}
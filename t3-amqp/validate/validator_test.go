@@ -0,0 +1,154 @@
+package validate
+
+import (
+	"sync"
+	"sync/atomic"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonSchema = `{"type": "object", "properties": {"example": {"type": "string"}}, "required": ["example"]}`
+
+const avroSchema = `{"type": "record", "name": "Example", "fields": [{"name": "example", "type": "string"}]}`
+
+func TestCompileJSON(t *testing.T) {
+	v, err := Compile("json", jsonSchema)
+	assert.NoError(t, err)
+	assert.NoError(t, v.Validate([]byte(`{"example": "hi"}`)))
+	assert.Error(t, v.Validate([]byte(`{"example": 1}`)))
+	assert.Error(t, v.Validate([]byte(`{}`)))
+	assert.Error(t, v.Validate([]byte(`not json`)))
+}
+
+func TestCompileAvro(t *testing.T) {
+	v, err := Compile("avro", avroSchema)
+	assert.NoError(t, err)
+	assert.NoError(t, v.Validate([]byte(`{"example": "hi"}`)))
+	assert.Error(t, v.Validate([]byte(`{"example": 1}`)))
+}
+
+func TestCompileUnsupported(t *testing.T) {
+	_, err := Compile("thrift", "")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestCacheReusesAndEvicts(t *testing.T) {
+	c := NewCache(1)
+	schema := db.Schema{ID: 1, Type: "json", SchemaData: jsonSchema}
+
+	v1, err := c.Validator(schema)
+	assert.NoError(t, err)
+	v2, err := c.Validator(schema)
+	assert.NoError(t, err)
+	assert.Same(t, v1, v2)
+
+	_, err = c.Validator(db.Schema{ID: 2, Type: "avro", SchemaData: avroSchema})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Len())
+
+	v3, err := c.Validator(schema)
+	assert.NoError(t, err)
+	assert.NotSame(t, v1, v3)
+}
+
+func TestCacheCompilesConcurrentMissesOnce(t *testing.T) {
+	c := NewCache(10)
+	var compiles atomic.Int32
+	release := make(chan struct{})
+	c.compile = func(schemaType, schemaData string, resolve Resolver) (Validator, error) {
+		compiles.Add(1)
+		<-release
+		return CompileWith(schemaType, schemaData, resolve)
+	}
+
+	schema := db.Schema{ID: 1, Type: "json", SchemaData: jsonSchema}
+	validators := make([]Validator, 50)
+	var started, done sync.WaitGroup
+	for i := range validators {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			v, err := c.Validator(schema)
+			assert.NoError(t, err)
+			validators[i] = v
+		}()
+	}
+	// Hold the first compile until every caller has missed and joined it
+	started.Wait()
+	assert.Eventually(t, func() bool { return compiles.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	assert.Equal(t, int32(1), compiles.Load())
+	for _, v := range validators {
+		assert.Same(t, validators[0], v)
+	}
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestCacheInvalidateID(t *testing.T) {
+	c := NewCache(10)
+	_, err := c.Validator(db.Schema{ID: 7, Type: "json", SchemaData: jsonSchema})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Len())
+
+	c.InvalidateID(7)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestCompileWithReferences(t *testing.T) {
+	address := db.Schema{
+		ID: 1, Name: "address", Type: "json", Version: "1.0.0",
+		SchemaData: `{"type":"object","properties":{"street":{"type":"string"}},"required":["street"],
+			"definitions":{"zip":{"type":"string","pattern":"^[0-9]{5}$"}}}`,
+	}
+	resolve := func(ref db.SchemaRef) (*db.Schema, error) {
+		if ref == (db.SchemaRef{Name: address.Name, Type: address.Type, Version: address.Version}) {
+			return &address, nil
+		}
+		return nil, db.ErrSchemaNotFound
+	}
+	order := `{"type":"object","properties":{
+		"to":{"$ref":"t3://address/json/1.0.0"},
+		"zip":{"$ref":"t3://address/json/1.0.0#/definitions/zip"}}}`
+
+	v, err := CompileWith("json", order, resolve)
+	assert.NoError(t, err)
+	assert.NoError(t, v.Validate([]byte(`{"to":{"street":"Main St"},"zip":"12345"}`)))
+	assert.Error(t, v.Validate([]byte(`{"to":{}}`)))
+	assert.Error(t, v.Validate([]byte(`{"zip":"123"}`)))
+
+	_, err = Compile("json", order)
+	assert.ErrorContains(t, err, "without the registry")
+	_, err = CompileWith("json", `{"$ref":"t3://missing/json/1.0.0"}`, resolve)
+	assert.ErrorContains(t, err, "not registered")
+	_, err = CompileWith("json", `{"$ref":"t3://address/avro/1.0.0"}`, resolve)
+	assert.ErrorContains(t, err, "not a json schema")
+
+	var schemaErr *SchemaError
+	assert.ErrorAs(t, CheckSchema("json", `{"$ref":"t3://missing/json/1.0.0"}`, resolve), &schemaErr)
+	assert.NoError(t, CheckSchema("json", order, resolve))
+}
+
+func TestCacheResolvesThroughStore(t *testing.T) {
+	store := db.NewMemoryStore()
+	_, err := store.Insert(db.QueryArgs{Name: "id", Type: "json", Version: "1", SchemaData: `{"type":"integer"}`})
+	assert.NoError(t, err)
+
+	c := NewCache(10)
+	c.UseStore(store)
+	v, err := c.Validator(db.Schema{ID: 2, Type: "json", SchemaData: `{"$ref":"t3://id/json/1"}`})
+	assert.NoError(t, err)
+	assert.NoError(t, v.Validate([]byte(`7`)))
+	assert.Error(t, v.Validate([]byte(`"7"`)))
+
+	// The reference resolves in the tenant of the schema
+	_, err = c.Validator(db.Schema{ID: 3, Type: "json", SchemaData: `{"$ref":"t3://id/json/1"}`, Tenant: "acme"})
+	assert.Error(t, err)
+}
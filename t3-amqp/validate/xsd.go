@@ -0,0 +1,887 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The XSD support covers the subset of XML Schema used by message contracts: global
+// elements, named and anonymous complex and simple types, sequence, choice and all with
+// occurrence bounds, attributes, simple content extensions, restrictions with the common
+// facets and the built-in primitive types. Elements are matched by local name, imports,
+// includes, substitution groups and identity constraints are not supported.
+
+const unbounded = -1
+
+// xmlNode is a generic XML element used for both schema and instance documents
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []xmlNode  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name && a.Name.Space == "" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parseXML(data []byte) (*xmlNode, error) {
+	var root xmlNode
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&root); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// localName strips the namespace prefix from a QName
+func localName(qname string) string {
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+type xsdFacets struct {
+	enumeration      []string
+	patterns         []*regexp.Regexp
+	length           *int
+	minLength        *int
+	maxLength        *int
+	minInclusive     *float64
+	maxInclusive     *float64
+	minExclusive     *float64
+	maxExclusive     *float64
+	hasNumericFacets bool
+}
+
+type xsdSimpleType struct {
+	name   string
+	base   string
+	facets xsdFacets
+}
+
+type xsdAttribute struct {
+	name     string
+	required bool
+	typ      *xsdSimpleType
+}
+
+type xsdParticle struct {
+	kind      string // element, sequence, choice or all
+	element   *xsdElement
+	particles []*xsdParticle
+	min, max  int
+}
+
+type xsdComplexType struct {
+	name       string
+	attributes []*xsdAttribute
+	content    *xsdParticle
+	simple     *xsdSimpleType
+	mixed      bool
+}
+
+type xsdElement struct {
+	name    string
+	typeRef string
+	complex *xsdComplexType
+	simple  *xsdSimpleType
+}
+
+type xsdValidator struct {
+	elements     map[string]*xsdElement
+	complexTypes map[string]*xsdComplexType
+	simpleTypes  map[string]*xsdSimpleType
+}
+
+// unquoteXML unwraps an XML document stored as a JSON string
+func unquoteXML(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '"' {
+		return trimmed, nil
+	}
+	var s string
+	if err := json.Unmarshal(trimmed, &s); err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func compileXSD(schemaData string) (*xsdValidator, error) {
+	data, err := unquoteXML([]byte(schemaData))
+	if err != nil {
+		return nil, fmt.Errorf("error loading xsd schema: %w", err)
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing xsd schema: %w", err)
+	}
+	if root.XMLName.Local != "schema" {
+		return nil, fmt.Errorf("error compiling xsd schema: root element is %q, not schema", root.XMLName.Local)
+	}
+
+	v := &xsdValidator{
+		elements:     map[string]*xsdElement{},
+		complexTypes: map[string]*xsdComplexType{},
+		simpleTypes:  map[string]*xsdSimpleType{},
+	}
+	for i := range root.Children {
+		child := &root.Children[i]
+		var err error
+		switch child.XMLName.Local {
+		case "element":
+			var el *xsdElement
+			if el, err = compileElement(child); err == nil {
+				v.elements[el.name] = el
+			}
+		case "complexType":
+			var ct *xsdComplexType
+			if ct, err = compileComplexType(child); err == nil {
+				v.complexTypes[ct.name] = ct
+			}
+		case "simpleType":
+			var st *xsdSimpleType
+			if st, err = compileSimpleType(child); err == nil {
+				v.simpleTypes[st.name] = st
+			}
+		case "annotation":
+		default:
+			err = fmt.Errorf("unsupported top level declaration %s", child.XMLName.Local)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error compiling xsd schema: %w", err)
+		}
+	}
+	if len(v.elements) == 0 {
+		return nil, fmt.Errorf("error compiling xsd schema: no global element declared")
+	}
+	if err := v.resolve(); err != nil {
+		return nil, fmt.Errorf("error compiling xsd schema: %w", err)
+	}
+	return v, nil
+}
+
+// resolve makes sure every referenced type exists so validation never fails on the schema
+func (v *xsdValidator) resolve() error {
+	var check func(p *xsdParticle) error
+	checkType := func(name string) error {
+		name = localName(name)
+		if name == "" || isBuiltin(name) || v.complexTypes[name] != nil || v.simpleTypes[name] != nil {
+			return nil
+		}
+		return fmt.Errorf("unknown type %q", name)
+	}
+	checkComplex := func(ct *xsdComplexType) error {
+		for _, a := range ct.attributes {
+			if err := checkType(a.typ.base); err != nil {
+				return err
+			}
+		}
+		if ct.simple != nil {
+			if err := checkType(ct.simple.base); err != nil {
+				return err
+			}
+		}
+		if ct.content != nil {
+			return check(ct.content)
+		}
+		return nil
+	}
+	check = func(p *xsdParticle) error {
+		if p.element != nil {
+			el := p.element
+			if el.typeRef != "" && el.complex == nil && el.simple == nil {
+				if strings.HasPrefix(el.typeRef, "ref:") {
+					if v.elements[el.typeRef[4:]] == nil {
+						return fmt.Errorf("unknown element reference %q", el.typeRef[4:])
+					}
+					return nil
+				}
+				return checkType(el.typeRef)
+			}
+			if el.complex != nil {
+				return checkComplex(el.complex)
+			}
+			return nil
+		}
+		for _, child := range p.particles {
+			if err := check(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, el := range v.elements {
+		if err := check(&xsdParticle{kind: "element", element: el}); err != nil {
+			return err
+		}
+	}
+	for _, ct := range v.complexTypes {
+		if err := checkComplex(ct); err != nil {
+			return err
+		}
+	}
+	for _, st := range v.simpleTypes {
+		if err := checkType(st.base); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseOccurs(n *xmlNode) (int, int, error) {
+	minOccurs, maxOccurs := 1, 1
+	if s := n.attr("minOccurs"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid minOccurs %q", s)
+		}
+		minOccurs = v
+	}
+	if s := n.attr("maxOccurs"); s == "unbounded" {
+		maxOccurs = unbounded
+	} else if s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid maxOccurs %q", s)
+		}
+		maxOccurs = v
+	}
+	return minOccurs, maxOccurs, nil
+}
+
+func compileElement(n *xmlNode) (*xsdElement, error) {
+	el := &xsdElement{name: n.attr("name"), typeRef: localName(n.attr("type"))}
+	if ref := n.attr("ref"); ref != "" {
+		el.name = localName(ref)
+		el.typeRef = "ref:" + el.name
+		return el, nil
+	}
+	if el.name == "" {
+		return nil, fmt.Errorf("element without a name")
+	}
+	for i := range n.Children {
+		child := &n.Children[i]
+		var err error
+		switch child.XMLName.Local {
+		case "complexType":
+			el.complex, err = compileComplexType(child)
+		case "simpleType":
+			el.simple, err = compileSimpleType(child)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("element %s: %w", el.name, err)
+		}
+	}
+	return el, nil
+}
+
+func compileParticle(n *xmlNode) (*xsdParticle, error) {
+	minOccurs, maxOccurs, err := parseOccurs(n)
+	if err != nil {
+		return nil, err
+	}
+	p := &xsdParticle{kind: n.XMLName.Local, min: minOccurs, max: maxOccurs}
+	switch p.kind {
+	case "element":
+		if p.element, err = compileElement(n); err != nil {
+			return nil, err
+		}
+		return p, nil
+	case "sequence", "choice", "all":
+	case "any":
+		return nil, fmt.Errorf("xs:any wildcards are not supported")
+	default:
+		return nil, fmt.Errorf("unsupported particle %s", p.kind)
+	}
+
+	for i := range n.Children {
+		child := &n.Children[i]
+		if child.XMLName.Local == "annotation" {
+			continue
+		}
+		cp, err := compileParticle(child)
+		if err != nil {
+			return nil, err
+		}
+		p.particles = append(p.particles, cp)
+	}
+	return p, nil
+}
+
+func compileAttribute(n *xmlNode) (*xsdAttribute, error) {
+	a := &xsdAttribute{name: n.attr("name"), required: n.attr("use") == "required"}
+	if a.name == "" {
+		return nil, fmt.Errorf("attribute without a name")
+	}
+	a.typ = &xsdSimpleType{base: localName(n.attr("type"))}
+	for i := range n.Children {
+		if n.Children[i].XMLName.Local == "simpleType" {
+			st, err := compileSimpleType(&n.Children[i])
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", a.name, err)
+			}
+			a.typ = st
+		}
+	}
+	return a, nil
+}
+
+func compileComplexType(n *xmlNode) (*xsdComplexType, error) {
+	ct := &xsdComplexType{name: n.attr("name"), mixed: n.attr("mixed") == "true"}
+	for i := range n.Children {
+		child := &n.Children[i]
+		switch child.XMLName.Local {
+		case "sequence", "choice", "all":
+			p, err := compileParticle(child)
+			if err != nil {
+				return nil, err
+			}
+			ct.content = p
+		case "attribute":
+			a, err := compileAttribute(child)
+			if err != nil {
+				return nil, err
+			}
+			ct.attributes = append(ct.attributes, a)
+		case "simpleContent":
+			for j := range child.Children {
+				ext := &child.Children[j]
+				if ext.XMLName.Local != "extension" {
+					continue
+				}
+				ct.simple = &xsdSimpleType{base: localName(ext.attr("base"))}
+				for k := range ext.Children {
+					if ext.Children[k].XMLName.Local != "attribute" {
+						continue
+					}
+					a, err := compileAttribute(&ext.Children[k])
+					if err != nil {
+						return nil, err
+					}
+					ct.attributes = append(ct.attributes, a)
+				}
+			}
+			if ct.simple == nil {
+				return nil, fmt.Errorf("simpleContent is only supported as an extension")
+			}
+		case "complexContent":
+			return nil, fmt.Errorf("complexContent derivation is not supported")
+		case "annotation", "anyAttribute":
+		default:
+			return nil, fmt.Errorf("unsupported complexType content %s", child.XMLName.Local)
+		}
+	}
+	return ct, nil
+}
+
+func compileSimpleType(n *xmlNode) (*xsdSimpleType, error) {
+	st := &xsdSimpleType{name: n.attr("name")}
+	for i := range n.Children {
+		child := &n.Children[i]
+		switch child.XMLName.Local {
+		case "restriction":
+			st.base = localName(child.attr("base"))
+			for j := range child.Children {
+				if err := st.facets.add(&child.Children[j]); err != nil {
+					return nil, err
+				}
+			}
+		case "list", "union":
+			// Lists and unions are accepted without checking their items
+			st.base = "string"
+		}
+	}
+	return st, nil
+}
+
+func (f *xsdFacets) add(n *xmlNode) error {
+	value := n.attr("value")
+	intValue := func() (*int, error) {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", n.XMLName.Local, value)
+		}
+		return &v, nil
+	}
+	floatValue := func() (*float64, error) {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", n.XMLName.Local, value)
+		}
+		f.hasNumericFacets = true
+		return &v, nil
+	}
+
+	var err error
+	switch n.XMLName.Local {
+	case "enumeration":
+		f.enumeration = append(f.enumeration, value)
+	case "pattern":
+		// XSD patterns always match the whole value
+		re, rerr := regexp.Compile("^(?:" + value + ")$")
+		if rerr != nil {
+			return fmt.Errorf("invalid pattern %q: %w", value, rerr)
+		}
+		f.patterns = append(f.patterns, re)
+	case "length":
+		f.length, err = intValue()
+	case "minLength":
+		f.minLength, err = intValue()
+	case "maxLength":
+		f.maxLength, err = intValue()
+	case "minInclusive":
+		f.minInclusive, err = floatValue()
+	case "maxInclusive":
+		f.maxInclusive, err = floatValue()
+	case "minExclusive":
+		f.minExclusive, err = floatValue()
+	case "maxExclusive":
+		f.maxExclusive, err = floatValue()
+	}
+	return err
+}
+
+var integerTypes = map[string]bool{
+	"integer": true, "int": true, "long": true, "short": true, "byte": true,
+	"nonNegativeInteger": true, "positiveInteger": true, "nonPositiveInteger": true, "negativeInteger": true,
+	"unsignedLong": true, "unsignedInt": true, "unsignedShort": true, "unsignedByte": true,
+}
+
+var stringTypes = map[string]bool{
+	"string": true, "normalizedString": true, "token": true, "anyURI": true, "language": true, "Name": true,
+	"NCName": true, "QName": true, "ID": true, "IDREF": true, "NMTOKEN": true, "anySimpleType": true,
+}
+
+func isBuiltin(name string) bool {
+	switch name {
+	case "anyType", "boolean", "decimal", "float", "double", "date", "dateTime", "time", "base64Binary",
+		"hexBinary", "duration":
+		return true
+	}
+	return integerTypes[name] || stringTypes[name]
+}
+
+// checkBuiltin validates value against a built-in type and returns its numeric value
+// for range facets when the type is numeric
+func checkBuiltin(name, value string) (float64, bool, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case stringTypes[name], name == "anyType", name == "", name == "duration":
+		return 0, false, nil
+	case integerTypes[name]:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%q is not a valid %s", value, name)
+		}
+		var ok bool
+		switch name {
+		case "nonNegativeInteger", "unsignedLong", "unsignedInt", "unsignedShort", "unsignedByte":
+			ok = n >= 0
+		case "positiveInteger":
+			ok = n > 0
+		case "nonPositiveInteger":
+			ok = n <= 0
+		case "negativeInteger":
+			ok = n < 0
+		case "int":
+			ok = n >= math.MinInt32 && n <= math.MaxInt32
+		case "short":
+			ok = n >= math.MinInt16 && n <= math.MaxInt16
+		case "byte":
+			ok = n >= math.MinInt8 && n <= math.MaxInt8
+		default:
+			ok = true
+		}
+		if ok && name == "unsignedInt" {
+			ok = n <= math.MaxUint32
+		}
+		if ok && name == "unsignedShort" {
+			ok = n <= math.MaxUint16
+		}
+		if ok && name == "unsignedByte" {
+			ok = n <= math.MaxUint8
+		}
+		if !ok {
+			return 0, false, fmt.Errorf("%d is out of range for %s", n, name)
+		}
+		return float64(n), true, nil
+	case name == "decimal":
+		if strings.ContainsAny(value, "eEIN") {
+			return 0, false, fmt.Errorf("%q is not a valid decimal", value)
+		}
+		fallthrough
+	case name == "float", name == "double":
+		switch value {
+		case "INF":
+			return math.Inf(1), true, nil
+		case "-INF":
+			return math.Inf(-1), true, nil
+		case "NaN":
+			return math.NaN(), true, nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%q is not a valid %s", value, name)
+		}
+		return f, true, nil
+	case name == "boolean":
+		switch value {
+		case "true", "false", "1", "0":
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("%q is not a valid boolean", value)
+	case name == "date":
+		if _, err := time.Parse("2006-01-02", strings.TrimSuffix(value, "Z")); err != nil {
+			return 0, false, fmt.Errorf("%q is not a valid date", value)
+		}
+	case name == "dateTime":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			if _, err := time.Parse("2006-01-02T15:04:05.999999999", value); err != nil {
+				return 0, false, fmt.Errorf("%q is not a valid dateTime", value)
+			}
+		}
+	case name == "time":
+		if _, err := time.Parse("15:04:05.999999999", strings.TrimSuffix(value, "Z")); err != nil {
+			return 0, false, fmt.Errorf("%q is not a valid time", value)
+		}
+	case name == "base64Binary":
+		if _, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+			return 0, false, fmt.Errorf("value is not valid base64Binary")
+		}
+	case name == "hexBinary":
+		if len(value)%2 != 0 || strings.Trim(value, "0123456789abcdefABCDEF") != "" {
+			return 0, false, fmt.Errorf("value is not valid hexBinary")
+		}
+	}
+	return 0, false, nil
+}
+
+// checkSimple validates value against st, following named base types down to a built-in
+func (v *xsdValidator) checkSimple(st *xsdSimpleType, value string) error {
+	for depth := 0; st != nil; depth++ {
+		if depth > 32 {
+			return fmt.Errorf("simple type derivation is too deep")
+		}
+
+		f := st.facets
+		if len(f.enumeration) > 0 {
+			found := false
+			for _, e := range f.enumeration {
+				if e == value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%q is not one of %s", value, strings.Join(f.enumeration, ", "))
+			}
+		}
+		for _, re := range f.patterns {
+			if !re.MatchString(value) {
+				return fmt.Errorf("%q does not match pattern %s", value, re.String())
+			}
+		}
+		n := utf8.RuneCountInString(value)
+		if f.length != nil && n != *f.length {
+			return fmt.Errorf("length %d is not %d", n, *f.length)
+		}
+		if f.minLength != nil && n < *f.minLength {
+			return fmt.Errorf("length %d is shorter than %d", n, *f.minLength)
+		}
+		if f.maxLength != nil && n > *f.maxLength {
+			return fmt.Errorf("length %d is longer than %d", n, *f.maxLength)
+		}
+
+		base := localName(st.base)
+		if named, ok := v.simpleTypes[base]; ok {
+			if f.hasNumericFacets {
+				if err := checkRange(f, value); err != nil {
+					return err
+				}
+			}
+			st = named
+			continue
+		}
+
+		num, numeric, err := checkBuiltin(base, value)
+		if err != nil {
+			return err
+		}
+		if f.hasNumericFacets {
+			if !numeric {
+				return checkRange(f, value)
+			}
+			return checkRangeValue(f, num)
+		}
+		return nil
+	}
+	return nil
+}
+
+func checkRange(f xsdFacets, value string) error {
+	num, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return fmt.Errorf("%q is not numeric", value)
+	}
+	return checkRangeValue(f, num)
+}
+
+func checkRangeValue(f xsdFacets, num float64) error {
+	switch {
+	case f.minInclusive != nil && num < *f.minInclusive:
+		return fmt.Errorf("%g is less than %g", num, *f.minInclusive)
+	case f.maxInclusive != nil && num > *f.maxInclusive:
+		return fmt.Errorf("%g is greater than %g", num, *f.maxInclusive)
+	case f.minExclusive != nil && num <= *f.minExclusive:
+		return fmt.Errorf("%g is not greater than %g", num, *f.minExclusive)
+	case f.maxExclusive != nil && num >= *f.maxExclusive:
+		return fmt.Errorf("%g is not less than %g", num, *f.maxExclusive)
+	}
+	return nil
+}
+
+// Validate accepts an XML document, or an XML document encoded as a JSON string
+func (v *xsdValidator) Validate(payload []byte) error {
+	data, err := unquoteXML(payload)
+	if err != nil {
+		return fmt.Errorf("payload is not valid xml: %w", err)
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return fmt.Errorf("payload is not valid xml: %w", err)
+	}
+
+	decl, ok := v.elements[root.XMLName.Local]
+	if !ok {
+		return fmt.Errorf("root element %q is not declared in the schema", root.XMLName.Local)
+	}
+	return v.checkElement(root, decl, "/"+root.XMLName.Local)
+}
+
+func (v *xsdValidator) checkElement(n *xmlNode, decl *xsdElement, path string) error {
+	if strings.HasPrefix(decl.typeRef, "ref:") {
+		decl = v.elements[decl.typeRef[4:]]
+	}
+
+	complexType, simpleType := decl.complex, decl.simple
+	if complexType == nil && simpleType == nil && decl.typeRef != "" {
+		if ct, ok := v.complexTypes[decl.typeRef]; ok {
+			complexType = ct
+		} else if st, ok := v.simpleTypes[decl.typeRef]; ok {
+			simpleType = st
+		} else {
+			simpleType = &xsdSimpleType{base: decl.typeRef}
+		}
+	}
+
+	switch {
+	case complexType != nil:
+		return v.checkComplex(n, complexType, path)
+	case simpleType != nil:
+		if len(n.Children) > 0 {
+			return fmt.Errorf("%s: element must not have child elements", path)
+		}
+		if err := v.checkSimple(simpleType, n.Text); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	default:
+		// No type means xs:anyType
+		return nil
+	}
+}
+
+func (v *xsdValidator) checkComplex(n *xmlNode, ct *xsdComplexType, path string) error {
+	declared := make(map[string]*xsdAttribute, len(ct.attributes))
+	for _, a := range ct.attributes {
+		declared[a.name] = a
+	}
+	seen := map[string]bool{}
+	for _, a := range n.Attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" || a.Name.Space == "http://www.w3.org/2001/XMLSchema-instance" {
+			continue
+		}
+		decl, ok := declared[a.Name.Local]
+		if !ok {
+			return fmt.Errorf("%s: attribute %q is not declared", path, a.Name.Local)
+		}
+		if err := v.checkSimple(decl.typ, a.Value); err != nil {
+			return fmt.Errorf("%s/@%s: %w", path, a.Name.Local, err)
+		}
+		seen[a.Name.Local] = true
+	}
+	for _, a := range ct.attributes {
+		if a.required && !seen[a.name] {
+			return fmt.Errorf("%s: required attribute %q is missing", path, a.name)
+		}
+	}
+
+	if ct.simple != nil {
+		if len(n.Children) > 0 {
+			return fmt.Errorf("%s: element must not have child elements", path)
+		}
+		if err := v.checkSimple(ct.simple, n.Text); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+
+	if !ct.mixed && strings.TrimSpace(n.Text) != "" {
+		return fmt.Errorf("%s: element must not contain text", path)
+	}
+	if ct.content == nil {
+		if len(n.Children) > 0 {
+			return fmt.Errorf("%s: element must be empty", path)
+		}
+		return nil
+	}
+
+	pos, err := v.matchParticle(ct.content, n.Children, 0, path)
+	if err != nil {
+		return err
+	}
+	if pos < len(n.Children) {
+		return fmt.Errorf("%s: unexpected element %q", path, n.Children[pos].XMLName.Local)
+	}
+	return nil
+}
+
+// matchParticle greedily matches p against children starting at pos and returns the
+// position after the last matched child
+func (v *xsdValidator) matchParticle(p *xsdParticle, children []xmlNode, pos int, path string) (int, error) {
+	count := 0
+	for p.max == unbounded || count < p.max {
+		next, matched, err := v.matchOnce(p, children, pos, path)
+		if err != nil {
+			return pos, err
+		}
+		if !matched || next == pos {
+			break
+		}
+		pos = next
+		count++
+	}
+	if count < p.min {
+		return pos, &missingError{fmt.Sprintf("%s: expected %s", path, p.describe())}
+	}
+	return pos, nil
+}
+
+// missingError reports a required particle that did not occur, as opposed to an element
+// that occurred but is invalid
+type missingError struct {
+	msg string
+}
+
+func (e *missingError) Error() string {
+	return e.msg
+}
+
+func isMissing(err error) bool {
+	var m *missingError
+	return errors.As(err, &m)
+}
+
+// matchOnce matches a single occurrence of p, reporting whether anything matched
+func (v *xsdValidator) matchOnce(p *xsdParticle, children []xmlNode, pos int, path string) (int, bool, error) {
+	switch p.kind {
+	case "element":
+		if pos >= len(children) || children[pos].XMLName.Local != p.element.name {
+			return pos, false, nil
+		}
+		child := &children[pos]
+		if err := v.checkElement(child, p.element, path+"/"+child.XMLName.Local); err != nil {
+			return pos, false, err
+		}
+		return pos + 1, true, nil
+
+	case "sequence":
+		start := pos
+		for _, item := range p.particles {
+			var err error
+			if pos, err = v.matchParticle(item, children, pos, path); err != nil {
+				if pos == start && isMissing(err) {
+					// Nothing of this occurrence matched, leave it to the occurrence bounds
+					return start, false, nil
+				}
+				return pos, false, err
+			}
+		}
+		return pos, pos > start || len(p.particles) == 0, nil
+
+	case "choice":
+		for _, item := range p.particles {
+			next, err := v.matchParticle(item, children, pos, path)
+			if err == nil && next > pos {
+				return next, true, nil
+			}
+			if err != nil && !(isMissing(err) && next == pos) {
+				return next, false, err
+			}
+		}
+		return pos, false, nil
+
+	case "all":
+		start := pos
+		matched := map[*xsdParticle]bool{}
+		for pos < len(children) {
+			progressed := false
+			for _, item := range p.particles {
+				if matched[item] {
+					continue
+				}
+				next, ok, err := v.matchOnce(item, children, pos, path)
+				if err != nil {
+					return pos, false, err
+				}
+				if ok {
+					matched[item] = true
+					pos = next
+					progressed = true
+					break
+				}
+			}
+			if !progressed {
+				break
+			}
+		}
+		for _, item := range p.particles {
+			if !matched[item] && item.min > 0 {
+				if pos == start {
+					return start, false, nil
+				}
+				return pos, false, &missingError{fmt.Sprintf("%s: expected %s", path, item.describe())}
+			}
+		}
+		return pos, pos > start, nil
+	}
+	return pos, false, nil
+}
+
+func (p *xsdParticle) describe() string {
+	if p.element != nil {
+		return fmt.Sprintf("element %q", p.element.name)
+	}
+	names := make([]string, 0, len(p.particles))
+	for _, item := range p.particles {
+		names = append(names, item.describe())
+	}
+	return fmt.Sprintf("%s of %s", p.kind, strings.Join(names, ", "))
+}
@@ -0,0 +1,117 @@
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderXSD = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Currency">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="EUR"/>
+      <xs:enumeration value="USD"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Amount">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal">
+        <xs:attribute name="currency" type="Currency" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="id" type="xs:positiveInteger"/>
+        <xs:element name="created" type="xs:dateTime"/>
+        <xs:element name="total" type="Amount"/>
+        <xs:element name="item" minOccurs="1" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="sku">
+                <xs:simpleType>
+                  <xs:restriction base="xs:string">
+                    <xs:pattern value="[A-Z]{3}-[0-9]+"/>
+                  </xs:restriction>
+                </xs:simpleType>
+              </xs:element>
+              <xs:element name="quantity">
+                <xs:simpleType>
+                  <xs:restriction base="xs:int">
+                    <xs:minInclusive value="1"/>
+                    <xs:maxInclusive value="100"/>
+                  </xs:restriction>
+                </xs:simpleType>
+              </xs:element>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+        <xs:choice minOccurs="0">
+          <xs:element name="pickup" type="xs:string"/>
+          <xs:element name="address" type="xs:string"/>
+        </xs:choice>
+        <xs:element name="note" type="xs:string" minOccurs="0"/>
+      </xs:sequence>
+      <xs:attribute name="version" type="xs:string"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+
+const validOrder = `<order version="2">
+  <id>42</id>
+  <created>2024-05-01T10:00:00Z</created>
+  <total currency="EUR">19.90</total>
+  <item><sku>ABC-1</sku><quantity>2</quantity></item>
+  <item><sku>XYZ-22</sku><quantity>1</quantity></item>
+  <address>Main Street 1</address>
+</order>`
+
+func TestCompileXSD(t *testing.T) {
+	v, err := Compile("xsd", orderXSD)
+	assert.NoError(t, err)
+	assert.NoError(t, v.Validate([]byte(validOrder)))
+
+	// XSD documents and payloads stored as JSON strings are unwrapped
+	quoted, _ := json.Marshal(orderXSD)
+	v, err = Compile("xsd", string(quoted))
+	assert.NoError(t, err)
+	quoted, _ = json.Marshal(validOrder)
+	assert.NoError(t, v.Validate(quoted))
+}
+
+func TestXSDRejectsInvalidPayloads(t *testing.T) {
+	v, err := Compile("xsd", orderXSD)
+	assert.NoError(t, err)
+
+	for payload, msg := range map[string]string{
+		`<order><id>0</id></order>`:                             "out of range for positiveInteger",
+		`<order><id>1</id><created>yesterday</created></order>`: "not a valid dateTime",
+		`<order><id>1</id><created>2024-05-01T10:00:00Z</created><total currency="GBP">1</total></order>`: "not one of EUR, USD",
+		`<order><id>1</id><created>2024-05-01T10:00:00Z</created><total currency="EUR">1</total></order>`: `expected element "item"`,
+		`<order><id>1</id><created>2024-05-01T10:00:00Z</created><total currency="EUR">1</total>
+			<item><sku>abc</sku><quantity>1</quantity></item></order>`: "does not match pattern",
+		`<order><id>1</id><created>2024-05-01T10:00:00Z</created><total currency="EUR">1</total>
+			<item><sku>ABC-1</sku><quantity>101</quantity></item></order>`: "greater than 100",
+		`<order><id>1</id><created>2024-05-01T10:00:00Z</created><total currency="EUR">1</total>
+			<item><sku>ABC-1</sku><quantity>1</quantity></item><pickup>x</pickup><address>y</address></order>`: `unexpected element "address"`,
+		`<order foo="1"/>`: `attribute "foo" is not declared`,
+		`<invoice/>`:       "not declared in the schema",
+		`not xml`:          "not valid xml",
+	} {
+		err := v.Validate([]byte(payload))
+		if assert.Error(t, err, payload) {
+			assert.Contains(t, err.Error(), msg, payload)
+		}
+	}
+}
+
+func TestCompileXSDErrors(t *testing.T) {
+	_, err := Compile("xsd", `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a" type="Missing"/></xs:schema>`)
+	assert.ErrorContains(t, err, `unknown type "Missing"`)
+
+	_, err = Compile("xsd", `<root/>`)
+	assert.Error(t, err)
+}
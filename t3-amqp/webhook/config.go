@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"t3-amqp/db"
+	"time"
+)
+
+// Config tunes the delivery of callbacks
+type Config struct {
+	// Workers deliver callbacks concurrently, QueueSize bounds the deliveries waiting for one
+	Workers   int `mapstructure:"workers"`
+	QueueSize int `mapstructure:"queue_size"`
+	// MaxAttempts bounds the deliveries of one callback. Retries wait Backoff, doubling
+	// after every attempt up to MaxBackoff.
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	Backoff     time.Duration `mapstructure:"backoff"`
+	MaxBackoff  time.Duration `mapstructure:"max_backoff"`
+	// Timeout bounds a single delivery attempt
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// LoadConfig reads the webhooks section of the already loaded configuration, WEBHOOKS_*
+// environment variables override the file
+func LoadConfig() (*Config, error) {
+	if err := db.BindEnv("webhooks", Config{}); err != nil {
+		return nil, err
+	}
+	var settings struct {
+		Webhooks Config `mapstructure:"webhooks"`
+	}
+	if err := viper.Unmarshal(&settings); err != nil {
+		return nil, fmt.Errorf("unable to decode webhooks config: %w", err)
+	}
+	config := settings.Webhooks
+	config.applyDefaults()
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.Workers <= 0 {
+		c.Workers = 2
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 256
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 5 * time.Minute
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+}
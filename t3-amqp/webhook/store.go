@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"t3-amqp/db"
+)
+
+// Store is a PostgresStore whose audit trail also notifies a Dispatcher, so every schema
+// change the REST layer records reaches the webhooks
+type Store struct {
+	*db.PostgresStore
+	dispatcher *Dispatcher
+}
+
+// NewStore wraps store to notify dispatcher
+func NewStore(store *db.PostgresStore, dispatcher *Dispatcher) *Store {
+	return &Store{PostgresStore: store, dispatcher: dispatcher}
+}
+
+// ForTenant scopes the store to tenant, keeping its dispatcher
+func (s *Store) ForTenant(tenant string) db.SchemaStore {
+	return &Store{PostgresStore: s.PostgresStore.WithTenant(tenant), dispatcher: s.dispatcher}
+}
+
+func (s *Store) RecordAudit(entry db.AuditEntry) error {
+	err := s.PostgresStore.RecordAudit(entry)
+	s.dispatcher.Audited(s.PostgresStore, entry)
+	return err
+}
+
+// MemoryStore is Store for the in-memory registry
+type MemoryStore struct {
+	*db.MemoryStore
+	dispatcher *Dispatcher
+}
+
+// NewMemoryStore wraps store to notify dispatcher
+func NewMemoryStore(store *db.MemoryStore, dispatcher *Dispatcher) *MemoryStore {
+	return &MemoryStore{MemoryStore: store, dispatcher: dispatcher}
+}
+
+// ForTenant scopes the store to tenant, keeping its dispatcher
+func (s *MemoryStore) ForTenant(tenant string) db.SchemaStore {
+	return &MemoryStore{MemoryStore: s.MemoryStore.WithTenant(tenant), dispatcher: s.dispatcher}
+}
+
+func (s *MemoryStore) RecordAudit(entry db.AuditEntry) error {
+	err := s.MemoryStore.RecordAudit(entry)
+	s.dispatcher.Audited(s.MemoryStore, entry)
+	return err
+}
@@ -0,0 +1,346 @@
+// Package webhook tells registered HTTP endpoints about schema changes with signed POST
+// callbacks, so downstream caches and CI jobs can react to them. Failed deliveries are
+// retried with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"t3-amqp/db"
+	"time"
+)
+
+// Event types, one per kind of schema change
+const (
+	EventCreated  = "schema.created"
+	EventUpdated  = "schema.updated"
+	EventDeleted  = "schema.deleted"
+	EventRestored = "schema.restored"
+)
+
+// EventTypes lists every event type a webhook can subscribe to
+var EventTypes = []string{EventCreated, EventUpdated, EventDeleted, EventRestored}
+
+// Headers of every callback. The signature is the hex HMAC-SHA256 of the timestamp, a
+// dot and the body keyed with the webhook secret, prefixed with sha256=.
+const (
+	HeaderEvent     = "X-T3-Event"
+	HeaderDelivery  = "X-T3-Delivery"
+	HeaderTimestamp = "X-T3-Timestamp"
+	HeaderSignature = "X-T3-Signature"
+)
+
+// ErrClosed is returned once the dispatcher has been shut down
+var ErrClosed = errors.New("webhook dispatcher is closed")
+
+// Event is the body of a callback. ID identifies the event across delivery attempts so
+// receivers can drop duplicates.
+type Event struct {
+	ID     string    `json:"id"`
+	Type   string    `json:"type"`
+	Actor  string    `json:"actor,omitempty"`
+	At     time.Time `json:"at"`
+	Schema Schema    `json:"schema"`
+	// Tenant owns the schema, empty for events raised outside a request
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Schema identifies the schema an event is about, the document itself is fetched from
+// the registry when needed
+type Schema struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// Sign returns the signature header value of body sent at timestamp with secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery is one callback to one webhook, or an event still to be fanned out when hook
+// is nil
+type delivery struct {
+	hook    *db.Webhook
+	event   Event
+	attempt int
+}
+
+// Dispatcher delivers events to the webhooks of a store on a fixed number of workers.
+// Events arriving while the queue is full are dropped and logged rather than slowing
+// down the requests that caused them.
+type Dispatcher struct {
+	hooks  db.WebhookStore
+	config Config
+	client *http.Client
+	jobs   chan delivery
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	// retries holds the timers of the deliveries waiting to be retried
+	retries map[*time.Timer]struct{}
+}
+
+// NewDispatcher starts the workers delivering events to the webhooks in hooks
+func NewDispatcher(hooks db.WebhookStore, config Config) *Dispatcher {
+	config.applyDefaults()
+	d := &Dispatcher{
+		hooks:   hooks,
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		jobs:    make(chan delivery, config.QueueSize),
+		retries: map[*time.Timer]struct{}{},
+	}
+	for i := 0; i < config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Notify queues event for every webhook subscribed to its type, assigning its ID and
+// time when they are unset
+func (d *Dispatcher) Notify(event Event) error {
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+	return d.enqueue(delivery{event: event})
+}
+
+// Audited notifies the webhooks about the schema change entry records. Entries not about
+// a schema, like alias changes, are ignored. The schema is taken from the snapshots of
+// entry, or else looked up in store, deleted ones included.
+func (d *Dispatcher) Audited(store db.SchemaStore, entry db.AuditEntry) {
+	eventType, ok := map[string]string{
+		db.AuditActionInsert:  EventCreated,
+		db.AuditActionUpdate:  EventUpdated,
+		db.AuditActionDelete:  EventDeleted,
+		db.AuditActionRestore: EventRestored,
+	}[entry.Action]
+	if !ok || entry.SchemaID == 0 {
+		return
+	}
+
+	event := Event{
+		Type: eventType, Actor: entry.Actor, Schema: Schema{ID: entry.SchemaID, Name: entry.SchemaName},
+		Tenant: entry.Tenant,
+	}
+	snapshot := entry.After
+	if snapshot == nil {
+		snapshot = entry.Before
+	}
+	if snapshot != nil {
+		event.Schema = Schema{
+			ID: snapshot.ID, Name: snapshot.Name, Type: snapshot.Type, Version: snapshot.Version, Status: snapshot.Status,
+		}
+	} else if schema := lookup(store, entry); schema != nil {
+		event.Schema = Schema{
+			ID: schema.ID, Name: schema.Name, Type: schema.Type, Version: schema.Version, Status: schema.Status,
+		}
+	}
+	if err := d.Notify(event); err != nil {
+		log.Printf("Failed to notify webhooks of %s of schema %d: %v", eventType, entry.SchemaID, err)
+	}
+}
+
+// lookup finds the schema of entry, which is no longer live once deleted
+func lookup(store db.SchemaStore, entry db.AuditEntry) *db.Schema {
+	if schema, err := store.GetByID(entry.SchemaID); err == nil {
+		return schema
+	}
+	schemas, err := store.Filter(db.QueryArgs{Name: entry.SchemaName, IncludeDeleted: true})
+	if err != nil {
+		return nil
+	}
+	i := slices.IndexFunc(schemas, func(s db.Schema) bool { return s.ID == entry.SchemaID })
+	if i < 0 {
+		return nil
+	}
+	return &schemas[i]
+}
+
+func (d *Dispatcher) enqueue(job delivery) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	select {
+	case d.jobs <- job:
+		return nil
+	default:
+		return fmt.Errorf("webhook queue is full, dropped %s", job.event.Type)
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for job := range d.jobs {
+		if job.hook != nil {
+			d.deliver(job)
+			continue
+		}
+		// Hooks only hear about the schemas of their own tenant
+		hooks, err := d.hooks.Webhooks(cmp.Or(job.event.Tenant, db.DefaultTenant))
+		if err != nil {
+			log.Printf("Failed to list webhooks for %s %s: %v", job.event.Type, job.event.ID, err)
+			continue
+		}
+		for _, hook := range hooks {
+			if len(hook.Events) == 0 || slices.Contains(hook.Events, job.event.Type) {
+				d.deliver(delivery{hook: &hook, event: job.event, attempt: 1})
+			}
+		}
+	}
+}
+
+// deliver makes one attempt at job, records its outcome and schedules a retry when the
+// endpoint could not be reached or answered with 429 or a server error
+func (d *Dispatcher) deliver(job delivery) {
+	status, err := d.post(*job.hook, job.event)
+	deliveryErr := ""
+	if err != nil {
+		deliveryErr = err.Error()
+	}
+	if err := d.hooks.RecordWebhookDelivery(job.hook.ID, time.Now().UTC(), status, deliveryErr); err != nil {
+		if errors.Is(err, db.ErrWebhookNotFound) {
+			return
+		}
+		log.Printf("Failed to record delivery to webhook %d: %v", job.hook.ID, err)
+	}
+
+	retryable := (err != nil && status == 0) || status == http.StatusTooManyRequests || status >= 500
+	if !retryable {
+		if err != nil {
+			log.Printf("Webhook %d refused %s %s: %v", job.hook.ID, job.event.Type, job.event.ID, err)
+		}
+		return
+	}
+	if job.attempt >= d.config.MaxAttempts {
+		log.Printf(
+			"Giving up on %s %s for webhook %d after %d attempts: %v",
+			job.event.Type, job.event.ID, job.hook.ID, job.attempt, err,
+		)
+		return
+	}
+	job.attempt++
+	d.retry(job, d.backoff(job.attempt-1))
+}
+
+// post sends event to hook and returns the response status, with an error unless it is 2xx
+func (d *Dispatcher) post(hook db.Webhook, event Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is the delay before retry n, the first retry being 1
+func (d *Dispatcher) backoff(n int) time.Duration {
+	delay := d.config.Backoff
+	for i := 1; i < n && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.config.MaxBackoff)
+}
+
+// retry queues job again after delay unless the dispatcher closed in the meantime
+func (d *Dispatcher) retry(job delivery, delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		delete(d.retries, timer)
+		d.mu.Unlock()
+		if err := d.enqueue(job); err != nil && !errors.Is(err, ErrClosed) {
+			log.Printf("Failed to retry %s %s for webhook %d: %v", job.event.Type, job.event.ID, job.hook.ID, err)
+		}
+	})
+	d.retries[timer] = struct{}{}
+}
+
+// Close stops accepting events, drops the pending retries and waits for the queued
+// deliveries to finish
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	for timer := range d.retries {
+		timer.Stop()
+	}
+	if len(d.retries) > 0 {
+		log.Printf("Dropped %d webhook deliveries waiting to be retried", len(d.retries))
+	}
+	close(d.jobs)
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// NewSecret returns a random secret for signing the callbacks of a webhook
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newEventID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
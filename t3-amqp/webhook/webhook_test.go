@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"t3-amqp/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver records the callbacks it gets and answers them with the statuses in order,
+// 200 once they ran out
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newReceiver(statuses ...int) (*receiver, *httptest.Server) {
+	rec := &receiver{statuses: statuses, received: make(chan struct{}, 16)}
+	return rec, httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		status := http.StatusOK
+		if len(rec.statuses) > 0 {
+			status, rec.statuses = rec.statuses[0], rec.statuses[1:]
+		}
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+		rec.received <- struct{}{}
+	}))
+}
+
+// wait blocks until n more callbacks arrived
+func (rec *receiver) wait(t *testing.T, n int) bool {
+	for i := 0; i < n; i++ {
+		select {
+		case <-rec.received:
+		case <-time.After(5 * time.Second):
+			t.Errorf("only %d of %d callbacks arrived", i, n)
+			return false
+		}
+	}
+	return true
+}
+
+func testConfig() Config {
+	return Config{Workers: 1, MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestDispatcherSignsCallbacks(t *testing.T) {
+	rec, server := newReceiver()
+	defer server.Close()
+	store := db.NewMemoryStore()
+	hook, err := store.CreateWebhook(db.Webhook{URL: server.URL, Secret: "s3cret", Events: []string{EventCreated}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	d := NewDispatcher(store, testConfig())
+	defer d.Close()
+
+	assert.NoError(t, d.Notify(Event{Type: EventDeleted, Schema: Schema{ID: 1}}))
+	assert.NoError(t, d.Notify(Event{Type: EventCreated, Schema: Schema{ID: 2, Name: "orders"}}))
+	if !rec.wait(t, 1) {
+		return
+	}
+	d.Close()
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if !assert.Len(t, rec.requests, 1, "the webhook only subscribed to created events") {
+		return
+	}
+	req, body := rec.requests[0], rec.bodies[0]
+	assert.Equal(t, EventCreated, req.Header.Get(HeaderEvent))
+	assert.Equal(t, Sign("s3cret", req.Header.Get(HeaderTimestamp), body), req.Header.Get(HeaderSignature))
+	var event Event
+	assert.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, req.Header.Get(HeaderDelivery), event.ID)
+	assert.Equal(t, Schema{ID: 2, Name: "orders"}, event.Schema)
+
+	hooks, _ := store.Webhooks("")
+	assert.Equal(t, http.StatusOK, hooks[0].LastStatus)
+	assert.Empty(t, hooks[0].LastError)
+	assert.Equal(t, hook.ID, hooks[0].ID)
+}
+
+func TestDispatcherKeepsTenantsApart(t *testing.T) {
+	acme, acmeServer := newReceiver()
+	defer acmeServer.Close()
+	other, otherServer := newReceiver()
+	defer otherServer.Close()
+	store := db.NewMemoryStore()
+	_, _ = store.CreateWebhook(db.Webhook{URL: acmeServer.URL, Secret: "s", Tenant: "acme"})
+	_, _ = store.CreateWebhook(db.Webhook{URL: otherServer.URL, Secret: "s"})
+	d := NewDispatcher(store, testConfig())
+	defer d.Close()
+
+	assert.NoError(t, d.Notify(Event{Type: EventCreated, Tenant: "acme", Schema: Schema{ID: 1}}))
+	assert.NoError(t, d.Notify(Event{Type: EventCreated, Schema: Schema{ID: 2}}))
+	if !acme.wait(t, 1) || !other.wait(t, 1) {
+		return
+	}
+	d.Close()
+
+	for _, rec := range []*receiver{acme, other} {
+		rec.mu.Lock()
+		assert.Len(t, rec.requests, 1, "each webhook only hears about its own tenant")
+		rec.mu.Unlock()
+	}
+	var event Event
+	acme.mu.Lock()
+	defer acme.mu.Unlock()
+	assert.NoError(t, json.Unmarshal(acme.bodies[0], &event))
+	assert.Equal(t, "acme", event.Tenant)
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	rec, server := newReceiver(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	defer server.Close()
+	store := db.NewMemoryStore()
+	_, _ = store.CreateWebhook(db.Webhook{URL: server.URL, Secret: "s"})
+	d := NewDispatcher(store, testConfig())
+	defer d.Close()
+
+	assert.NoError(t, d.Notify(Event{Type: EventUpdated}))
+	if !rec.wait(t, 3) {
+		return
+	}
+	d.Close()
+	rec.mu.Lock()
+	ids := []string{}
+	for _, req := range rec.requests {
+		ids = append(ids, req.Header.Get(HeaderDelivery))
+	}
+	rec.mu.Unlock()
+	assert.Equal(t, []string{ids[0], ids[0], ids[0]}, ids, "retries keep the event ID")
+	hooks, _ := store.Webhooks("")
+	assert.Equal(t, http.StatusOK, hooks[0].LastStatus)
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	rec, server := newReceiver(500, 500, 500)
+	defer server.Close()
+	store := db.NewMemoryStore()
+	_, _ = store.CreateWebhook(db.Webhook{URL: server.URL, Secret: "s"})
+	d := NewDispatcher(store, testConfig())
+	defer d.Close()
+
+	assert.NoError(t, d.Notify(Event{Type: EventUpdated}))
+	if !rec.wait(t, 3) {
+		return
+	}
+	// Wait until a further attempt would have arrived
+	select {
+	case <-rec.received:
+		t.Error("the delivery was attempted more than MaxAttempts times")
+	case <-time.After(50 * time.Millisecond):
+	}
+	hooks, _ := store.Webhooks("")
+	assert.Equal(t, 500, hooks[0].LastStatus)
+	assert.Contains(t, hooks[0].LastError, "500")
+
+	// Client errors are not retried
+	rec.mu.Lock()
+	rec.statuses = []int{http.StatusBadRequest}
+	rec.mu.Unlock()
+	assert.NoError(t, d.Notify(Event{Type: EventUpdated}))
+	rec.wait(t, 1)
+	select {
+	case <-rec.received:
+		t.Error("a 400 was retried")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := &Dispatcher{config: Config{Backoff: time.Second, MaxBackoff: 10 * time.Second}}
+	var delays []time.Duration
+	for n := 1; n <= 6; n++ {
+		delays = append(delays, d.backoff(n))
+	}
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second,
+	}, delays)
+}
+
+func TestAudited(t *testing.T) {
+	rec, server := newReceiver()
+	defer server.Close()
+	store := db.NewMemoryStore()
+	_, _ = store.CreateWebhook(db.Webhook{URL: server.URL, Secret: "s"})
+	id, err := store.Insert(db.QueryArgs{Name: "orders", Type: "json", Version: "1.0.0", SchemaData: "{}"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, store.Delete(id))
+	d := NewDispatcher(store, testConfig())
+	defer d.Close()
+
+	d.Audited(store, db.AuditEntry{Action: db.AuditActionAlias, SchemaName: "orders"})
+	d.Audited(store, db.AuditEntry{Actor: "key:abc", Action: db.AuditActionDelete, SchemaID: id, SchemaName: "orders"})
+	if !rec.wait(t, 1) {
+		return
+	}
+	d.Close()
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if !assert.Len(t, rec.bodies, 1, "alias changes are not schema events") {
+		return
+	}
+	var event Event
+	assert.NoError(t, json.Unmarshal(rec.bodies[0], &event))
+	assert.Equal(t, EventDeleted, event.Type)
+	assert.Equal(t, "key:abc", event.Actor)
+	assert.Equal(
+		t, Schema{ID: id, Name: "orders", Type: "json", Version: "1.0.0", Status: db.StatusActive}, event.Schema,
+		"deleted schemas are still looked up",
+	)
+}
+
+func TestDispatcherClosed(t *testing.T) {
+	d := NewDispatcher(db.NewMemoryStore(), testConfig())
+	d.Close()
+	assert.ErrorIs(t, d.Notify(Event{Type: EventCreated}), ErrClosed)
+	d.Close()
+}